@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/selfcheck"
+)
+
+// runCheck validates the release without binding the HTTP port. The
+// report goes to stdout as JSON; the exit code is 1 if any check failed.
+func runCheck(timeout time.Duration) int {
+	cfg, err := config.ReadConfig()
+	checks, cleanup := selfcheck.ServiceChecks(cfg, err)
+	defer cleanup()
+
+	report := selfcheck.Run(context.Background(), timeout, checks...)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+	if !report.OK {
+		fmt.Fprintf(os.Stderr, "Self-check failed: %s\n", strings.Join(report.Failed(), ", "))
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/demo"
+	"github.com/jesuloba-world/leeta-task/internal/repository"
+)
+
+// runRemoveDemo deletes the locations DEMO_MODE loaded from the configured
+// backend. A memory backend starts empty, so this matters only for a
+// database.
+func runRemoveDemo() int {
+	cfg := config.LoadConfig()
+	repos, cleanup, err := repository.NewRepositoryFromConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open storage: %v\n", err)
+		return 1
+	}
+	defer cleanup()
+
+	removed, err := demo.Remove(context.Background(), repos.Locations)
+	fmt.Printf("Removed %d demo locations\n", removed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove demo locations: %v\n", err)
+		return 1
+	}
+	return 0
+}
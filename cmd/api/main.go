@@ -2,24 +2,46 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
-
-	"github.com/danielgtaylor/huma/v2"
-	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	// Opening hours name IANA timezones; the runtime image has no zoneinfo
+	_ "time/tzdata"
 
 	"github.com/jesuloba-world/leeta-task/internal/config"
-	"github.com/jesuloba-world/leeta-task/internal/handlers"
-	"github.com/jesuloba-world/leeta-task/internal/repository"
-	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/selfcheck"
+	"github.com/jesuloba-world/leeta-task/pkg/server"
 )
 
 func main() {
+	check := flag.Bool("check", false, "validate the configuration, database, PostGIS and migrations, print a report and exit without serving")
+	checkTimeout := flag.Duration("check-timeout", selfcheck.DefaultTimeout, "deadline for all of --check")
+	migrateStorage := flag.Bool("migrate-storage", false, "copy every location from the SOURCE_* backend to the DEST_* backend, print a report and exit without serving")
+	var migration domain.MigrationOptions
+	flag.BoolVar(&migration.DryRun, "dry-run", false, "with --migrate-storage, report what would be copied without writing")
+	flag.BoolVar(&migration.ContinueOnConflict, "continue-on-conflict", false, "with --migrate-storage, skip locations whose ID or name the destination already holds")
+	flag.BoolVar(&migration.PreserveIDs, "preserve-ids", true, "with --migrate-storage, keep the source IDs instead of numbering after the destination's")
+	flag.IntVar(&migration.BatchSize, "batch-size", 500, "with --migrate-storage, locations restored per transaction")
+	flag.IntVar(&migration.SpotChecks, "spot-checks", 20, "with --migrate-storage, migrated locations read back and searched for")
+	removeDemo := flag.Bool("remove-demo", false, "delete every location DEMO_MODE loaded (region demo), print how many and exit without serving")
+	flag.Parse()
+	if *check {
+		os.Exit(runCheck(*checkTimeout))
+	}
+	if *migrateStorage {
+		os.Exit(runMigrateStorage(migration))
+	}
+	if *removeDemo {
+		os.Exit(runRemoveDemo())
+	}
+
 	// Load configuration from environment
 	cfg := config.LoadConfig()
 
@@ -28,78 +50,55 @@ func main() {
 	}))
 	slog.SetDefault(logger)
 
-	// Initialize repository
-	locationRepo, cleanup, err := repository.NewRepositoryFromConfig(cfg)
+	handler, application, err := server.New(cfg, server.WithLogger(logger))
 	if err != nil {
-		slog.Error("Failed to initialize repository", "error", err)
+		slog.Error("Failed to build the API", "error", err)
 		os.Exit(1)
 	}
 
-	slog.Info("Repository initialized", "type", cfg.Storage)
-
-	// Initialize service
-	locationService := service.NewLocationService(locationRepo)
-
-	// Initialize handlers
-	locationHandler := handlers.NewLocationHandler(locationService)
-	healthHandler := handlers.NewHealthHandler()
-
-	// Create ServeMux
-	mux := http.NewServeMux()
-
-	// Create Huma API configuration
-	config := huma.DefaultConfig("Leeta Location API", "1.0.0")
-	config.Info.Description = "A RESTful API for managing geolocated stations with nearest location search capabilities"
-	config.Info.Contact = &huma.Contact{
-		Name:  "Jesuloba John Abere",
-		Email: "jesulobajohn@gmail.com",
-	}
-	config.Servers = []*huma.Server{
-		{URL: fmt.Sprintf("http://localhost:%d", cfg.Server.Port), Description: "Development server"},
-	}
-
-	// Create Huma API with humago adapter
-	api := humago.New(mux, config)
-
-	// Register all routes with Huma
-	healthHandler.RegisterRoutes(api)
-	locationHandler.RegisterRoutes(api)
-
-	server := &http.Server{
+	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      mux,
+		Handler:      handler,
 		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
 	}
 
-	go func() {
-		slog.Info("Starting server", "port", cfg.Server.Port)
-		slog.Info("API Documentation available", "url", fmt.Sprintf("http://localhost:%d/docs", cfg.Server.Port))
-		slog.Info("OpenAPI JSON available", "url", fmt.Sprintf("http://localhost:%d/openapi.json", cfg.Server.Port))
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("Server failed to start", "error", err)
-			os.Exit(1)
-		}
-	}()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	slog.Info("Shutting down server...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		slog.Error("Server forced to shutdown", "error", err)
-	}
-
-	// Cleanup database connection
-	if err := cleanup(); err != nil {
-		slog.Error("Failed to cleanup database connection", "error", err)
+	// The root context is cancelled on SIGINT or SIGTERM, or if the server
+	// stops serving on its own
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// The server is added last, so it drains in-flight requests before
+	// background work stops and the database closes
+	application.Add(server.Component{
+		Name: "http",
+		Start: func(context.Context) error {
+			listener, err := net.Listen("tcp", httpServer.Addr)
+			if err != nil {
+				return err
+			}
+			slog.Info("Starting server", "port", cfg.Server.Port)
+			if cfg.Docs.Enabled {
+				slog.Info("API Documentation available", "url", fmt.Sprintf("http://localhost:%d/docs", cfg.Server.Port))
+			}
+			if cfg.Docs.OpenAPIEnabled {
+				slog.Info("OpenAPI JSON available", "url", fmt.Sprintf("http://localhost:%d/openapi.json", cfg.Server.Port))
+			}
+			go func() {
+				if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+					slog.Error("Server failed", "error", err)
+					stop()
+				}
+			}()
+			return nil
+		},
+		Stop: httpServer.Shutdown,
+	})
+
+	if err := application.Run(ctx); err != nil {
+		slog.Error("Server shutdown with errors", "error", err)
+		os.Exit(1)
 	}
-
 	slog.Info("Server shutdown complete")
 }
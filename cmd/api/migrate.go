@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// runMigrateStorage copies every location from the SOURCE_* backend to the
+// DEST_* backend. The report goes to stdout as JSON; the exit code is 1 if
+// the migration failed, conflicted or did not verify.
+func runMigrateStorage(opts domain.MigrationOptions) int {
+	cfg, err := config.ReadStorageMigrationConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return 1
+	}
+
+	source, closeSource, err := openMigrationTarget(cfg.Source, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open the source: %v\n", err)
+		return 1
+	}
+	defer closeSource()
+	dest, closeDest, err := openMigrationTarget(cfg.Dest, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open the destination: %v\n", err)
+		return 1
+	}
+	defer closeDest()
+
+	result, err := service.NewStorageMigrator(source, dest).Migrate(context.Background(), opts)
+	if result != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			return 1
+		}
+	}
+	// A memory destination is written back even after a failed batch, so
+	// the file matches what a database would have kept
+	if cfg.Dest.Type == repository.MemoryRepository && result != nil && result.Migrated > 0 {
+		if err := writeSnapshotFile(cfg.Dest.SnapshotFile, dest); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", cfg.Dest.SnapshotFile, err)
+			return 1
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// openMigrationTarget opens one side of the migration, loading a memory
+// store from its snapshot file. The file must exist for the source.
+func openMigrationTarget(cfg config.StorageEndpointConfig, source bool) (domain.MigrationTarget, func() error, error) {
+	target, closeTarget, err := repository.NewMigrationTarget(cfg)
+	if err != nil || cfg.Type != repository.MemoryRepository {
+		return target, closeTarget, err
+	}
+
+	data, err := os.ReadFile(cfg.SnapshotFile)
+	if errors.Is(err, fs.ErrNotExist) && !source {
+		return target, closeTarget, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var snapshot dto.Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", cfg.SnapshotFile, err)
+	}
+	result, err := target.RestoreLocations(snapshot.ToDomain())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(result.Conflicts) > 0 {
+		c := result.Conflicts[0]
+		return nil, nil, fmt.Errorf("%s holds %d invalid or repeated locations, the first at index %d (%s)",
+			cfg.SnapshotFile, len(result.Conflicts), c.Index, c.Reason)
+	}
+	return target, closeTarget, nil
+}
+
+// writeSnapshotFile replaces path with a snapshot of target, writing a
+// temporary file first so a failure leaves the old file intact
+func writeSnapshotFile(path string, target domain.MigrationTarget) error {
+	var locations []*domain.Location
+	for location, err := range target.StreamLocations(context.Background(), 0) {
+		if err != nil {
+			return err
+		}
+		locations = append(locations, location)
+	}
+	data, err := json.Marshal(dto.ToSnapshot(locations, time.Now()))
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
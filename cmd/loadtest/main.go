@@ -0,0 +1,84 @@
+// Command loadtest seeds an in-process location API with generated
+// locations and replays a mix of nearest, list and create requests
+// against it, printing latency percentiles for each. See package
+// loadtest for how requests are paced and timed.
+//
+//	go run ./cmd/loadtest -locations 100000 -requests 50000 -rps 2000
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/jesuloba-world/leeta-task/internal/loadtest"
+	"github.com/jesuloba-world/leeta-task/internal/locationgen"
+)
+
+func main() {
+	var cfg loadtest.Config
+	flag.Uint64Var(&cfg.Data.Seed, "seed", 1, "seed for the locations and the requests")
+	flag.IntVar(&cfg.Data.Clusters, "clusters", 40, "number of cluster centres locations are drawn around")
+	flag.Float64Var(&cfg.Data.SpreadKm, "spread-km", 10, "standard deviation of a location's distance from its cluster centre")
+	flag.Float64Var(&cfg.Data.Background, "background", 0.05, "share of locations spread evenly instead of clustered")
+	flag.IntVar(&cfg.Locations, "locations", 100_000, "locations seeded before the run")
+	flag.IntVar(&cfg.Requests, "requests", 20_000, "requests sent")
+	flag.IntVar(&cfg.RPS, "rps", 0, "requests per second; 0 sends as fast as the workers allow")
+	flag.IntVar(&cfg.Workers, "workers", 0, "concurrent senders; 0 means GOMAXPROCS")
+	flag.IntVar(&cfg.PageSize, "page-size", 20, "limit list requests ask for")
+	mix := flag.String("mix", "nearest=90,list=8,create=2", "relative weights of the kinds of request")
+	flag.Parse()
+
+	var err error
+	if cfg.Mix, err = parseMix(*mix); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -mix: %v\n", err)
+		os.Exit(2)
+	}
+	cfg.Data.Bounds = locationgen.Nigeria
+
+	// The service logs every create; that would drown the report and
+	// slow the run
+	log.SetOutput(io.Discard)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := loadtest.Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load test failed: %v\n", err)
+		os.Exit(1)
+	}
+	report.Write(os.Stdout)
+}
+
+// parseMix reads weights such as "nearest=90,list=8,create=2"; kinds left
+// out weigh 0
+func parseMix(value string) (loadtest.Mix, error) {
+	var mix loadtest.Mix
+	for _, part := range strings.Split(value, ",") {
+		op, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		n, err := strconv.Atoi(weight)
+		if !ok || err != nil || n < 0 {
+			return mix, fmt.Errorf("expected kind=weight, got %q", part)
+		}
+		switch op {
+		case loadtest.OpNearest:
+			mix.Nearest = n
+		case loadtest.OpList:
+			mix.List = n
+		case loadtest.OpCreate:
+			mix.Create = n
+		default:
+			return mix, fmt.Errorf("unknown kind %q", op)
+		}
+	}
+	if mix == (loadtest.Mix{}) {
+		return mix, fmt.Errorf("every weight is 0")
+	}
+	return mix, nil
+}
@@ -0,0 +1,142 @@
+// Package app runs the service's components with an ordered lifecycle.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds shutdown when no timeout is configured
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ErrShutdownTimeout is reported for a component still stopping when the
+// shutdown timeout expires
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// Component is a part of the service with a lifecycle. Either function may
+// be nil.
+type Component struct {
+	Name string
+	// Start must return once the component is running; ctx is cancelled
+	// when the service begins shutting down
+	Start func(ctx context.Context) error
+	// Stop releases the component; ctx expires at the shutdown deadline
+	Stop func(ctx context.Context) error
+}
+
+// App starts components in the order they were added and stops them in
+// reverse, so each component can rely on those added before it until it
+// has stopped itself.
+type App struct {
+	components      []Component
+	shutdownTimeout time.Duration
+
+	mu      sync.Mutex
+	started int
+	cancel  context.CancelFunc
+}
+
+// New creates an app; a non-positive timeout means DefaultShutdownTimeout
+func New(shutdownTimeout time.Duration) *App {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+	return &App{shutdownTimeout: shutdownTimeout}
+}
+
+// Add appends a component
+func (a *App) Add(component Component) {
+	a.components = append(a.components, component)
+}
+
+// Run starts every component, waits for ctx to be cancelled and then stops
+// them. If a component fails to start, those already started are stopped.
+//
+// All stops share one shutdown deadline. A component still stopping when it
+// expires is abandoned and the rest are stopped with the expired context.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+	slog.Info("Shutting down", "timeout", a.shutdownTimeout.String())
+	return a.Shutdown(context.Background())
+}
+
+// Start starts every component in order and returns. The context the
+// components see is cancelled when ctx is or when Shutdown begins. If a
+// component fails to start, those already started are stopped.
+func (a *App) Start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ctx, a.cancel = context.WithCancel(ctx)
+	for i, component := range a.components {
+		if component.Start == nil {
+			continue
+		}
+		if err := component.Start(ctx); err != nil {
+			a.cancel()
+			err = fmt.Errorf("start %s: %w", component.Name, err)
+			return errors.Join(err, a.stop(context.Background(), a.components[:i]))
+		}
+	}
+	a.started = len(a.components)
+	return nil
+}
+
+// Shutdown stops the started components in reverse order within the
+// shutdown timeout, or by ctx's deadline if that is sooner. Stopping an
+// app that was never started, or twice, does nothing.
+func (a *App) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
+	}
+	components := a.components[:a.started]
+	a.started = 0
+	return a.stop(ctx, components)
+}
+
+func (a *App) stop(parent context.Context, components []Component) error {
+	ctx, cancel := context.WithTimeout(parent, a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		component := components[i]
+		if component.Stop == nil {
+			continue
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- component.Stop(ctx)
+		}()
+
+		var err error
+		if ctx.Err() != nil {
+			// Past the deadline; the remaining components get the expired
+			// context and are expected to return promptly
+			err = <-done
+		} else {
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = ErrShutdownTimeout
+			}
+		}
+		if err != nil {
+			slog.Error("Failed to stop component", "component", component.Name, "error", err)
+			errs = append(errs, fmt.Errorf("stop %s: %w", component.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder logs lifecycle calls across components
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func (r *recorder) component(name string) Component {
+	return Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			r.record("start " + name)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestRunStartsInOrderAndStopsInReverse(t *testing.T) {
+	rec := &recorder{}
+	a := New(time.Second)
+	a.Add(rec.component("repositories"))
+	a.Add(rec.component("scheduler"))
+	a.Add(Component{Name: "stateless"})
+	a.Add(rec.component("http"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- a.Run(ctx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for len(rec.Calls()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatalf("Expected a clean shutdown, got %v", err)
+	}
+
+	want := []string{
+		"start repositories", "start scheduler", "start http",
+		"stop http", "stop scheduler", "stop repositories",
+	}
+	if got := rec.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestRunCutsOffSlowComponent(t *testing.T) {
+	rec := &recorder{}
+	a := New(50 * time.Millisecond)
+	a.Add(rec.component("repositories"))
+	release := make(chan struct{})
+	defer close(release)
+	a.Add(Component{
+		Name: "stuck",
+		Stop: func(ctx context.Context) error {
+			rec.record("stop stuck")
+			<-release // ignores ctx
+			return nil
+		},
+	})
+	a.Add(rec.component("http"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	err := a.Run(ctx)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrShutdownTimeout) {
+		t.Errorf("Expected ErrShutdownTimeout, got %v", err)
+	}
+	if elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected shutdown to end at the timeout, took %v", elapsed)
+	}
+	want := []string{"start repositories", "start http", "stop http", "stop stuck", "stop repositories"}
+	if got := rec.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the components after the slow one still to stop, got %v", got)
+	}
+}
+
+func TestRunStopsStartedComponentsWhenStartFails(t *testing.T) {
+	rec := &recorder{}
+	a := New(time.Second)
+	a.Add(rec.component("repositories"))
+	a.Add(Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			return errors.New("address in use")
+		},
+		Stop: func(ctx context.Context) error {
+			rec.record("stop http")
+			return nil
+		},
+	})
+	a.Add(rec.component("never"))
+
+	err := a.Run(context.Background())
+	if err == nil || err.Error() != "start http: address in use" {
+		t.Errorf("Expected the start error, got %v", err)
+	}
+	want := []string{"start repositories", "stop repositories"}
+	if got := rec.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected only started components to stop, got %v", got)
+	}
+}
+
+func TestStartThenShutdown(t *testing.T) {
+	rec := &recorder{}
+	a := New(time.Second)
+	a.Add(rec.component("repositories"))
+	var jobs context.Context
+	a.Add(Component{
+		Name: "scheduler",
+		Start: func(ctx context.Context) error {
+			jobs = ctx
+			return nil
+		},
+	})
+	a.Add(rec.component("http"))
+
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	if jobs.Err() != nil {
+		t.Fatal("Expected the components' context to live until shutdown")
+	}
+	if err := a.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected a clean shutdown, got %v", err)
+	}
+	if jobs.Err() == nil {
+		t.Error("Expected shutdown to cancel the components' context")
+	}
+	if err := a.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected a second shutdown to do nothing, got %v", err)
+	}
+
+	want := []string{"start repositories", "start http", "stop http", "stop repositories"}
+	if got := rec.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrUnknownKey = errors.New("signing key not found in JWKS")
+
+// JWKSCache fetches and caches the public keys published at a JWKS URL.
+// Keys are refreshed after refreshInterval, and a lookup for an unknown kid
+// triggers an early refetch. Fetches, failed or not, are at least
+// minRefetchInterval apart, and lookups that need one at the same time
+// share it.
+type JWKSCache struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefetchInterval time.Duration
+	now                func() time.Time
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+	inflight    *jwksFetch
+}
+
+// jwksFetch is a fetch in progress, which lookups arriving meanwhile wait
+// on instead of starting their own
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWKSCache creates a cache for the given JWKS URL
+func NewJWKSCache(url string, refreshInterval time.Duration) *JWKSCache {
+	return &JWKSCache{
+		url:                url,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		refreshInterval:    refreshInterval,
+		minRefetchInterval: 10 * time.Second,
+		now:                time.Now,
+		keys:               map[string]crypto.PublicKey{},
+	}
+}
+
+// Key returns the public key for kid, fetching the JWKS when stale or when
+// the kid is unknown (for example right after the issuer rotates keys)
+func (c *JWKSCache) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	fetchedAt := c.fetchedAt
+	c.mu.RUnlock()
+
+	stale := fetchedAt.IsZero() || c.now().Sub(fetchedAt) > c.refreshInterval
+	if ok && !stale {
+		return key, nil
+	}
+
+	err := c.refresh(ctx)
+
+	// A failed fetch leaves the keys as they were, so a previously known
+	// key is still served while the JWKS endpoint is down
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, ErrUnknownKey
+}
+
+// refresh fetches the JWKS unless the last attempt was under
+// minRefetchInterval ago, in which case it returns that attempt's error.
+// A caller arriving during a fetch waits for it rather than making another.
+func (c *JWKSCache) refresh(ctx context.Context) error {
+	c.mu.Lock()
+	if call := c.inflight; call != nil {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if !c.attemptedAt.IsZero() && c.now().Sub(c.attemptedAt) < c.minRefetchInterval {
+		err := c.lastErr
+		c.mu.Unlock()
+		return err
+	}
+	call := &jwksFetch{done: make(chan struct{})}
+	c.inflight = call
+	c.mu.Unlock()
+
+	// The fetch is shared, so the caller that started it going away must
+	// not cancel it for the others; the client timeout bounds it instead
+	keys, err := c.fetch(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	c.attemptedAt = c.now()
+	c.lastErr = err
+	if err == nil {
+		c.keys = keys
+		c.fetchedAt = c.attemptedAt
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+
+	call.err = err
+	close(call.done)
+	return err
+}
+
+func (c *JWKSCache) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip keys we cannot use rather than rejecting the whole set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	if k.Use != "" && k.Use != "sig" {
+		return nil, fmt.Errorf("unsupported key use %q", k.Use)
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if x.BitLen() > 256 || y.BitLen() > 256 {
+			return nil, errors.New("EC coordinate too large for P-256")
+		}
+		point := make([]byte, 65)
+		point[0] = 4
+		x.FillBytes(point[1:33])
+		y.FillBytes(point[33:])
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid EC point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
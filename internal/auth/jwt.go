@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// JWTConfig configures bearer token validation
+type JWTConfig struct {
+	Issuer      string
+	Audience    string
+	ScopesClaim string
+	TenantClaim string
+	ClockSkew   time.Duration
+	// Profiles holds preferences by tenant; callers of other tenants
+	// prefer nothing
+	Profiles map[string]Profile
+}
+
+// JWTAuthenticator validates RS256/ES256 bearer tokens against a JWKS
+type JWTAuthenticator struct {
+	keys   *JWKSCache
+	config JWTConfig
+	now    func() time.Time
+}
+
+// NewJWTAuthenticator creates an authenticator backed by the given key cache
+func NewJWTAuthenticator(keys *JWKSCache, config JWTConfig) *JWTAuthenticator {
+	if config.ScopesClaim == "" {
+		config.ScopesClaim = "scope"
+	}
+	if config.TenantClaim == "" {
+		config.TenantClaim = "tenant"
+	}
+	return &JWTAuthenticator{keys: keys, config: config, now: time.Now}
+}
+
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	authz := header.Get("Authorization")
+	if authz == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	scheme, token, ok := strings.Cut(authz, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrInvalidCredentials
+	}
+
+	claims, err := a.verify(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	return a.principal(claims), nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (a *JWTAuthenticator) verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+
+	key, err := a.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed claims: %w", err)
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// verifySignature checks the signature with the algorithm the key type
+// requires, so a token cannot pick a weaker algorithm for a given key
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			return fmt.Errorf("unexpected algorithm %q for RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature); err != nil {
+			return errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if alg != "ES256" {
+			return fmt.Errorf("unexpected algorithm %q for EC key", alg)
+		}
+		if len(signature) != 64 {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return errors.New("unsupported key type")
+	}
+	return nil
+}
+
+func (a *JWTAuthenticator) validateClaims(claims map[string]any) error {
+	now := a.now()
+	skew := a.config.ClockSkew
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	if now.After(time.Unix(exp, 0).Add(skew)) {
+		return errors.New("token expired")
+	}
+
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(skew).Before(time.Unix(nbf, 0)) {
+		return errors.New("token not yet valid")
+	}
+
+	if a.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.config.Issuer {
+			return errors.New("unexpected issuer")
+		}
+	}
+
+	if a.config.Audience != "" && !containsString(stringsClaim(claims["aud"]), a.config.Audience) {
+		return errors.New("unexpected audience")
+	}
+
+	return nil
+}
+
+func (a *JWTAuthenticator) principal(claims map[string]any) *Principal {
+	p := &Principal{}
+	p.ID, _ = claims["sub"].(string)
+	p.Tenant, _ = claims[a.config.TenantClaim].(string)
+	p.Profile = a.config.Profiles[p.Tenant]
+	for _, scope := range stringsClaim(claims[a.config.ScopesClaim]) {
+		p.Scopes = append(p.Scopes, Scope(scope))
+	}
+	return p
+}
+
+// BearerSecurityScheme describes JWT bearer authentication for OpenAPI
+func BearerSecurityScheme() *huma.SecurityScheme {
+	return &huma.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "JWT issued by the company SSO. Scopes are read from the configured claim.",
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func numericClaim(claims map[string]any, name string) (int64, bool) {
+	switch v := claims[name].(type) {
+	case float64:
+		return int64(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// stringsClaim accepts either a space-delimited string or an array of strings
+func stringsClaim(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
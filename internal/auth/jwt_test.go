@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testJWKS struct {
+	mu      sync.Mutex
+	keys    []map[string]string
+	fetches int
+}
+
+func (j *testJWKS) set(keys ...map[string]string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = keys
+}
+
+func (j *testJWKS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.fetches++
+	json.NewEncoder(w).Encode(map[string]any{"keys": j.keys})
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(key.N.Bytes()),
+		"e": b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(x), "y": b64(y)}
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		sig = s
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+
+	return signingInput + "." + b64(sig)
+}
+
+func bearer(token string) http.Header {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer "+token)
+	return h
+}
+
+func validClaims(now time.Time) map[string]any {
+	return map[string]any{
+		"sub":    "user-123",
+		"iss":    "https://sso.example.com",
+		"aud":    []string{"geolocation-api"},
+		"exp":    now.Add(time.Hour).Unix(),
+		"scope":  "read write",
+		"tenant": "lagos-ops",
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	jwks := &testJWKS{}
+	jwks.set(rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	now := time.Now()
+	authn := NewJWTAuthenticator(NewJWKSCache(server.URL, time.Minute), JWTConfig{
+		Issuer:    "https://sso.example.com",
+		Audience:  "geolocation-api",
+		ClockSkew: 30 * time.Second,
+	})
+	authn.now = func() time.Time { return now }
+
+	withClaims := func(mutate func(map[string]any)) map[string]any {
+		c := validClaims(now)
+		mutate(c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid RS256", signToken(t, "RS256", "rsa-1", rsaKey, validClaims(now)), false},
+		{"valid ES256", signToken(t, "ES256", "ec-1", ecKey, validClaims(now)), false},
+		{"expired", signToken(t, "RS256", "rsa-1", rsaKey, withClaims(func(c map[string]any) {
+			c["exp"] = now.Add(-time.Minute).Unix()
+		})), true},
+		{"expired within skew", signToken(t, "RS256", "rsa-1", rsaKey, withClaims(func(c map[string]any) {
+			c["exp"] = now.Add(-10 * time.Second).Unix()
+		})), false},
+		{"not yet valid", signToken(t, "RS256", "rsa-1", rsaKey, withClaims(func(c map[string]any) {
+			c["nbf"] = now.Add(time.Minute).Unix()
+		})), true},
+		{"wrong audience", signToken(t, "RS256", "rsa-1", rsaKey, withClaims(func(c map[string]any) {
+			c["aud"] = "another-api"
+		})), true},
+		{"wrong issuer", signToken(t, "RS256", "rsa-1", rsaKey, withClaims(func(c map[string]any) {
+			c["iss"] = "https://evil.example.com"
+		})), true},
+		{"unknown kid", signToken(t, "RS256", "missing", rsaKey, validClaims(now)), true},
+		{"algorithm mismatch", signToken(t, "ES256", "rsa-1", ecKey, validClaims(now)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authn.Authenticate(context.Background(), bearer(tt.token))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorClaimsMapping(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwks := &testJWKS{}
+	jwks.set(rsaJWK("rsa-1", rsaKey))
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	authn := NewJWTAuthenticator(NewJWKSCache(server.URL, time.Minute), JWTConfig{
+		ScopesClaim: "roles",
+		TenantClaim: "org",
+		Profiles:    map[string]Profile{"acme": {Unit: "mi"}},
+	})
+
+	claims := validClaims(time.Now())
+	claims["roles"] = []string{"read", "admin"}
+	claims["org"] = "acme"
+
+	principal, err := authn.Authenticate(context.Background(), bearer(signToken(t, "RS256", "rsa-1", rsaKey, claims)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if principal.ID != "user-123" || principal.Tenant != "acme" {
+		t.Errorf("Unexpected principal: %+v", principal)
+	}
+	if !principal.HasScope(ScopeAdmin) || principal.HasScope(ScopeWrite) {
+		t.Errorf("Expected scopes [read admin], got %v", principal.Scopes)
+	}
+	if principal.Profile.Unit != "mi" {
+		t.Errorf("Expected the acme profile's unit mi, got %q", principal.Profile.Unit)
+	}
+}
+
+func TestJWTAuthenticatorKeyRotation(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	jwks := &testJWKS{}
+	jwks.set(rsaJWK("old", oldKey))
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	cache := NewJWKSCache(server.URL, time.Hour)
+	cache.minRefetchInterval = 0
+	authn := NewJWTAuthenticator(cache, JWTConfig{})
+
+	claims := validClaims(time.Now())
+	if _, err := authn.Authenticate(context.Background(), bearer(signToken(t, "RS256", "old", oldKey, claims))); err != nil {
+		t.Fatalf("Expected old key to validate, got %v", err)
+	}
+
+	// The issuer rotates; the cached set does not yet know the new kid
+	jwks.set(rsaJWK("new", newKey))
+	if _, err := authn.Authenticate(context.Background(), bearer(signToken(t, "RS256", "new", newKey, claims))); err != nil {
+		t.Fatalf("Expected rotated key to validate after refetch, got %v", err)
+	}
+	if jwks.fetches != 2 {
+		t.Errorf("Expected 2 JWKS fetches, got %d", jwks.fetches)
+	}
+}
+
+func TestJWTAuthenticatorMissingToken(t *testing.T) {
+	authn := NewJWTAuthenticator(NewJWKSCache("http://127.0.0.1:0", time.Minute), JWTConfig{})
+
+	if _, err := authn.Authenticate(context.Background(), http.Header{}); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("Expected ErrMissingCredentials, got %v", err)
+	}
+
+	h := http.Header{}
+	h.Set("Authorization", "Basic dXNlcjpwYXNz")
+	if _, err := authn.Authenticate(context.Background(), h); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+}
+
+func TestJWKSCacheBacksOffAfterFailure(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	var mu sync.Mutex
+	fetches := 0
+	down := true
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{rsaJWK("k1", key)}})
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	cache := NewJWKSCache(server.URL, time.Hour)
+	cache.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	// Lookups arriving while the endpoint hangs share its one fetch, and
+	// any arriving after it failed wait out the backoff instead
+	const lookups = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, lookups)
+	for range lookups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Key(context.Background(), "k1")
+			errs <- err
+		}()
+	}
+	<-arrived
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err == nil || errors.Is(err, ErrUnknownKey) {
+			t.Errorf("Expected the fetch failure reported, got %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected concurrent lookups to share 1 fetch, got %d", fetches)
+	}
+
+	if _, err := cache.Key(context.Background(), "k1"); err == nil {
+		t.Error("Expected a lookup during the backoff to fail")
+	}
+	if fetches != 1 {
+		t.Errorf("Expected no fetch during the backoff, got %d", fetches)
+	}
+
+	mu.Lock()
+	down = false
+	now = now.Add(cache.minRefetchInterval)
+	mu.Unlock()
+	if _, err := cache.Key(context.Background(), "k1"); err != nil {
+		t.Errorf("Expected the key once the endpoint is back, got %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected 1 more fetch after the backoff, got %d", fetches)
+	}
+}
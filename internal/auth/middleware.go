@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// MetadataScope is the huma.Operation metadata key for an explicit scope
+const MetadataScope = "scope"
+
+// TagScopes maps operation tags to the scope they require. Operations without
+// a mapped tag or explicit metadata need read for safe methods and write otherwise.
+var TagScopes = map[string]Scope{
+	"Health": ScopePublic,
+	"Admin":  ScopeAdmin,
+}
+
+// RequiredScope returns the scope a caller needs to invoke the operation
+func RequiredScope(op *huma.Operation) Scope {
+	if op == nil {
+		return ScopePublic
+	}
+
+	if v, ok := op.Metadata[MetadataScope]; ok {
+		switch scope := v.(type) {
+		case Scope:
+			return scope
+		case string:
+			return Scope(scope)
+		}
+	}
+
+	for _, tag := range op.Tags {
+		if scope, ok := TagScopes[tag]; ok {
+			return scope
+		}
+	}
+
+	switch op.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead
+	default:
+		return ScopeWrite
+	}
+}
+
+// Middleware authenticates each request and enforces the operation's scope.
+// A nil authenticator disables authentication entirely.
+func Middleware(authn Authenticator) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if authn == nil {
+			next(ctx)
+			return
+		}
+
+		header := http.Header{}
+		ctx.EachHeader(func(name, value string) {
+			header.Add(name, value)
+		})
+
+		scope := RequiredScope(ctx.Operation())
+		principal, err := authn.Authenticate(ctx.Context(), header)
+		if scope != ScopePublic {
+			if err != nil && !errors.Is(err, ErrMissingCredentials) {
+				apierrors.RespondWithHumaError(ctx, apierrors.Unauthorized("Invalid credentials"))
+				return
+			}
+			if principal == nil {
+				apierrors.RespondWithHumaError(ctx, apierrors.Unauthorized("Authentication required"))
+				return
+			}
+			if !principal.HasScope(scope) {
+				apierrors.RespondWithHumaError(ctx, apierrors.InsufficientScope("This operation requires the "+string(scope)+" scope").With("scope", string(scope)))
+				return
+			}
+		}
+
+		if principal != nil {
+			c := WithPrincipal(ctx.Context(), principal)
+			c = telemetry.With(c, telemetry.KeyTenant, principal.Tenant)
+			c = telemetry.With(c, telemetry.KeyPrincipal, principal.ID)
+			ctx = huma.WithContext(ctx, c)
+		}
+		next(ctx)
+	}
+}
+
+// DocumentSecurity registers the security scheme and annotates every
+// operation with the scope it requires. Call it after all routes are registered.
+func DocumentSecurity(api huma.API, name string, scheme *huma.SecurityScheme) {
+	oapi := api.OpenAPI()
+	if oapi.Components.SecuritySchemes == nil {
+		oapi.Components.SecuritySchemes = map[string]*huma.SecurityScheme{}
+	}
+	oapi.Components.SecuritySchemes[name] = scheme
+
+	for _, item := range oapi.Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch, item.Head, item.Options} {
+			if op == nil {
+				continue
+			}
+			scope := RequiredScope(op)
+			if scope == ScopePublic {
+				op.Security = []map[string][]string{}
+				continue
+			}
+			op.Security = []map[string][]string{{name: {string(scope)}}}
+		}
+	}
+}
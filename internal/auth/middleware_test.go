@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+type okResponse struct {
+	Body struct {
+		Principal string `json:"principal"`
+	}
+}
+
+func okHandler(ctx context.Context, _ *struct{}) (*okResponse, error) {
+	resp := &okResponse{}
+	if p := PrincipalFromContext(ctx); p != nil {
+		resp.Body.Principal = p.ID
+	}
+	return resp, nil
+}
+
+func setupAuthAPI(t *testing.T) humatest.TestAPI {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	api.UseMiddleware(Middleware(NewAPIKeyAuthenticator([]APIKey{
+		{Name: "mobile", Key: "mobile-key", Scopes: []Scope{ScopeRead}},
+		{Name: "tooling", Key: "tooling-key", Scopes: []Scope{ScopeRead, ScopeWrite}},
+		{Name: "ops", Key: "ops-key", Scopes: []Scope{ScopeRead, ScopeWrite, ScopeAdmin}},
+	})))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "health", Method: http.MethodGet, Path: "/health", Tags: []string{"Health"},
+	}, okHandler)
+	huma.Register(api, huma.Operation{
+		OperationID: "list", Method: http.MethodGet, Path: "/locations", Tags: []string{"Locations"},
+	}, okHandler)
+	huma.Register(api, huma.Operation{
+		OperationID: "create", Method: http.MethodPost, Path: "/locations", Tags: []string{"Locations"},
+	}, okHandler)
+	huma.Register(api, huma.Operation{
+		OperationID: "admin", Method: http.MethodGet, Path: "/admin/stats", Tags: []string{"Admin"},
+	}, okHandler)
+	huma.Register(api, huma.Operation{
+		OperationID: "explicit", Method: http.MethodGet, Path: "/explicit",
+		Metadata: map[string]any{MetadataScope: ScopeWrite},
+	}, okHandler)
+
+	return api
+}
+
+func TestScopeBoundaries(t *testing.T) {
+	api := setupAuthAPI(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		expected int
+	}{
+		{"anonymous health", http.MethodGet, "/health", "", http.StatusOK},
+		{"anonymous read", http.MethodGet, "/locations", "", http.StatusUnauthorized},
+		{"invalid key", http.MethodGet, "/locations", "nope", http.StatusUnauthorized},
+		{"read key reads", http.MethodGet, "/locations", "mobile-key", http.StatusOK},
+		{"read key cannot write", http.MethodPost, "/locations", "mobile-key", http.StatusForbidden},
+		{"write key writes", http.MethodPost, "/locations", "tooling-key", http.StatusOK},
+		{"write key cannot admin", http.MethodGet, "/admin/stats", "tooling-key", http.StatusForbidden},
+		{"admin key admins", http.MethodGet, "/admin/stats", "ops-key", http.StatusOK},
+		{"explicit scope overrides method", http.MethodGet, "/explicit", "mobile-key", http.StatusForbidden},
+		{"explicit scope granted", http.MethodGet, "/explicit", "tooling-key", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []any{}
+			if tt.key != "" {
+				args = append(args, APIKeyHeader+": "+tt.key)
+			}
+			resp := api.Do(tt.method, tt.path, args...)
+			if resp.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, resp.Code)
+			}
+		})
+	}
+}
+
+func TestInsufficientScopeErrorCode(t *testing.T) {
+	api := setupAuthAPI(t)
+
+	resp := api.Post("/locations", APIKeyHeader+": mobile-key")
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, resp.Code)
+	}
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if body.Error.Code != "INSUFFICIENT_SCOPE" {
+		t.Errorf("Expected code INSUFFICIENT_SCOPE, got %s", body.Error.Code)
+	}
+}
+
+func TestPrincipalInContext(t *testing.T) {
+	api := setupAuthAPI(t)
+
+	resp := api.Get("/locations", APIKeyHeader+": tooling-key")
+	var body struct {
+		Principal string `json:"principal"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if body.Principal != "tooling" {
+		t.Errorf("Expected principal 'tooling', got %q", body.Principal)
+	}
+}
+
+func TestDocumentSecurity(t *testing.T) {
+	api := setupAuthAPI(t)
+	DocumentSecurity(api, "apiKey", APIKeySecurityScheme())
+
+	paths := api.OpenAPI().Paths
+	expected := map[*huma.Operation]string{
+		paths["/locations"].Get:   "read",
+		paths["/locations"].Post:  "write",
+		paths["/admin/stats"].Get: "admin",
+	}
+	for op, scope := range expected {
+		if len(op.Security) != 1 || len(op.Security[0]["apiKey"]) != 1 || op.Security[0]["apiKey"][0] != scope {
+			t.Errorf("Expected %s to require %s, got %v", op.OperationID, scope, op.Security)
+		}
+	}
+
+	if health := paths["/health"].Get; len(health.Security) != 0 {
+		t.Errorf("Expected health to be public, got %v", health.Security)
+	}
+}
+
+func TestMiddlewareDisabled(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(Middleware(nil))
+	huma.Register(api, huma.Operation{
+		OperationID: "create", Method: http.MethodPost, Path: "/locations",
+	}, okHandler)
+
+	if resp := api.Post("/locations"); resp.Code != http.StatusOK {
+		t.Errorf("Expected status %d with auth disabled, got %d", http.StatusOK, resp.Code)
+	}
+}
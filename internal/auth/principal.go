@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Scope is a permission carried by an authenticated caller
+type Scope string
+
+const (
+	ScopePublic Scope = "public"
+	ScopeRead   Scope = "read"
+	ScopeWrite  Scope = "write"
+	ScopeAdmin  Scope = "admin"
+	// ScopeExact sees exact coordinates when coordinate privacy is on
+	ScopeExact Scope = "exact"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID      string
+	Tenant  string
+	Scopes  []Scope
+	Profile Profile
+}
+
+// Profile holds a caller's response preferences; the zero value prefers
+// nothing, so the server's defaults apply
+type Profile struct {
+	// Unit is the distance unit responses use when the request names none
+	Unit string
+}
+
+// HasScope reports whether the principal was granted the scope
+func (p *Principal) HasScope(scope Scope) bool {
+	if scope == ScopePublic {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator resolves the principal for a request. It returns
+// ErrMissingCredentials when the request carries none.
+type Authenticator interface {
+	Authenticate(ctx context.Context, header http.Header) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated principal, or nil for anonymous requests
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CacheControlConfig sets the Cache-Control header of GET responses per
+// operation ID. Each policy is a directive list such as "max-age=30" or
+// "public, max-age=30, s-maxage=120", or just "no-store". Mutations,
+// errors and operations without a policy are always sent no-store.
+type CacheControlConfig struct {
+	Policies map[string]string `json:"policies"`
+}
+
+// DefaultCachePolicies keeps listings and nearest answers briefly, single
+// locations longer, and health checks and the duplicate check at a point
+// out of every cache. Reads need
+// credentials when authentication is on, so none is marked public: add
+// public or s-maxage only when the CDN keys its cache on them. With
+// authentication or privacy on, the others are sent private.
+func DefaultCachePolicies() map[string]string {
+	return map[string]string{
+		"get-locations": "max-age=30",
+		"find-nearest":  "max-age=30",
+		// Sent with an ETag, so a stale copy is revalidated cheaply
+		"get-location": "max-age=300",
+		// Only a restart with new configuration changes it
+		"get-capabilities": "max-age=86400",
+		"health-check":     "no-store",
+		"readiness-check":  "no-store",
+	}
+}
+
+// loadCachePolicies overrides the defaults from CACHE_CONTROL_<OPERATION>
+// variables, e.g. CACHE_CONTROL_GET_LOCATIONS for get-locations
+func loadCachePolicies() map[string]string {
+	policies := DefaultCachePolicies()
+	for _, id := range OperationIDs {
+		if value := getEnv(CachePolicyEnv(id), ""); value != "" {
+			policies[id] = value
+		}
+	}
+	return policies
+}
+
+// CachePolicyEnv names the variable that sets an operation's policy
+func CachePolicyEnv(operationID string) string {
+	return "CACHE_CONTROL_" + strings.ToUpper(strings.ReplaceAll(operationID, "-", "_"))
+}
+
+// Compile checks every policy and returns the header value to send for
+// each operation, with directives normalised
+func (c CacheControlConfig) Compile() (map[string]string, error) {
+	headers := make(map[string]string, len(c.Policies))
+	for id, policy := range c.Policies {
+		if !slices.Contains(OperationIDs, id) {
+			return nil, fmt.Errorf("unknown operation %q in cache policies", id)
+		}
+		header, err := ParseCachePolicy(policy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", CachePolicyEnv(id), err)
+		}
+		headers[id] = header
+	}
+	return headers, nil
+}
+
+// ParseCachePolicy validates a directive list and returns it normalised.
+// It accepts no-store on its own, or any of public, private, max-age=N
+// and s-maxage=N.
+func ParseCachePolicy(policy string) (string, error) {
+	var directives []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(policy, ",") {
+		directive := strings.ToLower(strings.TrimSpace(part))
+		name, value, hasValue := strings.Cut(directive, "=")
+		switch name {
+		case "no-store", "public", "private":
+			if hasValue {
+				return "", fmt.Errorf("%s takes no value", name)
+			}
+		case "max-age", "s-maxage":
+			if seconds, err := strconv.Atoi(value); err != nil || seconds < 0 {
+				return "", fmt.Errorf("%s needs a number of seconds", name)
+			}
+		case "":
+			return "", fmt.Errorf("empty directive in %q", policy)
+		default:
+			return "", fmt.Errorf("unsupported directive %q", name)
+		}
+		if seen[name] {
+			return "", fmt.Errorf("%s is repeated", name)
+		}
+		seen[name] = true
+		directives = append(directives, directive)
+	}
+	if seen["no-store"] && len(directives) > 1 {
+		return "", fmt.Errorf("no-store cannot be combined with other directives")
+	}
+	if seen["public"] && seen["private"] {
+		return "", fmt.Errorf("public and private cannot be combined")
+	}
+	return strings.Join(directives, ", "), nil
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -124,6 +128,160 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid trusted proxies",
+			config: Config{
+				Server: ServerConfig{
+					Port:           8080,
+					ReadTimeout:    10,
+					WriteTimeout:   10,
+					IdleTimeout:    120,
+					TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"},
+				},
+				Storage: "memory",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid trusted proxy",
+			config: Config{
+				Server: ServerConfig{
+					Port:           8080,
+					ReadTimeout:    10,
+					WriteTimeout:   10,
+					IdleTimeout:    120,
+					TrustedProxies: []string{"10.0.0.0/33"},
+				},
+				Storage: "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "known disabled endpoints",
+			config: Config{
+				Server: ServerConfig{
+					Port:              8080,
+					ReadTimeout:       10,
+					WriteTimeout:      10,
+					IdleTimeout:       120,
+					EndpointsDisabled: []string{"delete-location", "restore-locations"},
+				},
+				Storage: "memory",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown disabled endpoint",
+			config: Config{
+				Server: ServerConfig{
+					Port:              8080,
+					ReadTimeout:       10,
+					WriteTimeout:      10,
+					IdleTimeout:       120,
+					EndpointsDisabled: []string{"delete-locaton"},
+				},
+				Storage: "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid name block pattern",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Names:   NamesConfig{BlockPatterns: []string{"test(\\d+"}},
+				Storage: "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing name blocklist file",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Names:   NamesConfig{BlocklistFile: "testdata/does-not-exist.txt"},
+				Storage: "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid name collation",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Names:   NamesConfig{Collation: "not a tag"},
+				Storage: "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "hard scan limit below soft",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				NearestScan: NearestScanConfig{SoftLimit: 1000, HardLimit: 500},
+				Storage:     "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown integrity fix",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Integrity: IntegrityConfig{Fix: "delete"},
+				Storage:   "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "nats events without url",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Events:  EventsConfig{Backend: "nats"},
+				Storage: "memory",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative default speed",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage:         "memory",
+				DefaultSpeedKmh: -30,
+			},
+			wantErr: true,
+		},
 		{
 			name: "postgres config missing host",
 			config: Config{
@@ -141,6 +299,188 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "default limits",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Limits:  DefaultLimits(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero limit",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Limits:  LimitsConfig{DefaultPageSize: 0, MaxPageSize: 100, DefaultBatchSize: 1, MaxBatchSize: 1, MaxBodyBytes: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max page size below default",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Limits:  LimitsConfig{DefaultPageSize: 50, MaxPageSize: 10, DefaultBatchSize: 1, MaxBatchSize: 1, MaxBodyBytes: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown distance strategy",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage:          "memory",
+				DistanceStrategy: "manhattan",
+			},
+			wantErr: true,
+		},
+		{
+			name: "coordinate precision out of range",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage:             "memory",
+				CoordinatePrecision: 10,
+			},
+			wantErr: true,
+		},
+		{
+			name: "max batch size below default",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Limits:  LimitsConfig{DefaultPageSize: 1, MaxPageSize: 1, DefaultBatchSize: 10, MaxBatchSize: 5, MaxBodyBytes: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cache policy",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage:      "memory",
+				CacheControl: CacheControlConfig{Policies: map[string]string{"get-locations": "max-age=soon"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid deprecation",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage:      "memory",
+				Deprecations: DeprecationConfig{Operations: map[string]string{"get-locations": "sunset=someday"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "region name too long",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Regions: RegionsConfig{Names: []string{"Lagos", strings.Repeat("x", 65)}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "jitter without secret",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Privacy: PrivacyConfig{Mode: "jitter", Meters: 500},
+			},
+			wantErr: true,
+		},
+		{
+			name: "short external ID key",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage:     "memory",
+				ExternalIDs: ExternalIDsConfig{Enabled: true, Key: "short"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "docs without the OpenAPI document",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Docs:    DocsConfig{Enabled: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid contact email",
+			config: Config{
+				Server: ServerConfig{
+					Port:         8080,
+					ReadTimeout:  10,
+					WriteTimeout: 10,
+					IdleTimeout:  120,
+				},
+				Storage: "memory",
+				Docs:    DocsConfig{OpenAPIEnabled: true, ContactEmail: "api-team"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,6 +493,146 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestParseCachePolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		want    string
+		wantErr bool
+	}{
+		{policy: "no-store", want: "no-store"},
+		{policy: "max-age=30", want: "max-age=30"},
+		{policy: " Public ,max-age=30,  s-maxage=120", want: "public, max-age=30, s-maxage=120"},
+		{policy: "private, max-age=0", want: "private, max-age=0"},
+		{policy: "", wantErr: true},
+		{policy: "max-age=30,", wantErr: true},
+		{policy: "max-age", wantErr: true},
+		{policy: "max-age=-1", wantErr: true},
+		{policy: "max-age=30, max-age=60", wantErr: true},
+		{policy: "no-store, max-age=30", wantErr: true},
+		{policy: "public, private", wantErr: true},
+		{policy: "public=1", wantErr: true},
+		{policy: "immutable", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCachePolicy(tt.policy)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCachePolicy(%q) error = %v, wantErr %v", tt.policy, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCachePolicy(%q) = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestLoadCachePolicies(t *testing.T) {
+	t.Setenv("CACHE_CONTROL_GET_LOCATIONS", "public, max-age=60")
+	t.Setenv("CACHE_CONTROL_CREATE_LOCATION", "no-store")
+
+	policies, err := LoadConfig().CacheControl.Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if policies["get-locations"] != "public, max-age=60" {
+		t.Errorf("Expected the get-locations override, got %q", policies["get-locations"])
+	}
+	if policies["find-nearest"] != "max-age=30" {
+		t.Errorf("Expected the find-nearest default, got %q", policies["find-nearest"])
+	}
+	if policies["get-location"] != "max-age=300" {
+		t.Errorf("Expected single locations kept longer, got %q", policies["get-location"])
+	}
+	if _, ok := policies["get-location-at"]; ok {
+		t.Errorf("Expected the duplicate check left uncached, got %q", policies["get-location-at"])
+	}
+	if policies["create-location"] != "no-store" {
+		t.Errorf("Expected create-location read from the environment, got %q", policies["create-location"])
+	}
+
+	if _, err := (CacheControlConfig{Policies: map[string]string{"get-everything": "max-age=30"}}).Compile(); err == nil {
+		t.Error("Expected an unknown operation to be rejected")
+	}
+}
+
+func TestParseDeprecation(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Deprecation
+		wantErr bool
+	}{
+		{value: "true", want: Deprecation{}},
+		{value: "sunset=2027-03-31", want: Deprecation{Sunset: time.Date(2027, time.March, 31, 0, 0, 0, 0, time.UTC)}},
+		{value: "sunset=2027-03-31T12:00:00+01:00; successor=https://api.example.com/v2/locations", want: Deprecation{
+			Sunset:    time.Date(2027, time.March, 31, 11, 0, 0, 0, time.UTC),
+			Successor: "https://api.example.com/v2/locations",
+		}},
+		{value: "successor=/v2/locations", want: Deprecation{Successor: "/v2/locations"}},
+		{value: "sunset=next year", wantErr: true},
+		{value: "successor=", wantErr: true},
+		{value: "successor=/a>; rel=x", wantErr: true},
+		{value: "sunset=2027-03-31; sunset=2027-04-01", wantErr: true},
+		{value: "replacement=/v2", wantErr: true},
+		{value: "yes", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDeprecation(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDeprecation(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (!got.Sunset.Equal(tt.want.Sunset) || got.Successor != tt.want.Successor) {
+			t.Errorf("ParseDeprecation(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLoadDeprecations(t *testing.T) {
+	t.Setenv("DEPRECATE_GET_LOCATIONS", "sunset=2027-03-31; successor=/v2/locations")
+
+	deprecations, err := LoadConfig().Deprecations.Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(deprecations) != 1 || deprecations["get-locations"].Successor != "/v2/locations" {
+		t.Errorf("Expected get-locations deprecated, got %+v", deprecations)
+	}
+
+	if _, err := (DeprecationConfig{Operations: map[string]string{"get-everything": "true"}}).Compile(); err == nil {
+		t.Error("Expected an unknown operation to be rejected")
+	}
+}
+
+func TestLoadRateLimits(t *testing.T) {
+	t.Setenv("RATE_LIMITS", "nearest:rps=20,burst=40; bulk:rps=0.5")
+	t.Setenv("RATE_LIMIT_OPERATIONS", "verify-spatial:bulk")
+
+	limits, err := LoadConfig().RateLimits.Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if limits.Classes["nearest"] != (RateLimit{RPS: 20, Burst: 40}) || limits.Classes["bulk"] != (RateLimit{RPS: 0.5, Burst: 1}) {
+		t.Errorf("Unexpected classes: %+v", limits.Classes)
+	}
+	if limits.Operations["verify-spatial"] != "bulk" || limits.Operations["find-nearest"] != "nearest" {
+		t.Errorf("Expected the override alongside the defaults, got %v", limits.Operations)
+	}
+
+	invalid := []RateLimitConfig{
+		{Classes: map[string]string{"exports": "rps=1"}},
+		{Classes: map[string]string{"none": "rps=1"}},
+		{Classes: map[string]string{"read": "burst=5"}},
+		{Classes: map[string]string{"read": "rps=-1"}},
+		{Classes: map[string]string{"read": "rps=1,window=60"}},
+		{Operations: map[string]string{"find-nearest": "lookups"}},
+		{Operations: map[string]string{"find-everything": "read"}},
+	}
+	for _, cfg := range invalid {
+		if _, err := cfg.Compile(); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
+
 func TestGetEnv(t *testing.T) {
 	// Test with existing environment variable
 	os.Setenv("TEST_VAR", "test_value")
@@ -203,4 +683,110 @@ func TestGetEnvAsInt(t *testing.T) {
 	if result != 10 {
 		t.Errorf("Expected default value 10, got %d", result)
 	}
-}
\ No newline at end of file
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys := parseAPIKeys("mobile:abc:read; tooling:def:read,write ;;ops:ghi:read,write,admin: acme ")
+
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 keys, got %d", len(keys))
+	}
+	if keys[0].Name != "mobile" || keys[0].Key != "abc" || len(keys[0].Scopes) != 1 {
+		t.Errorf("Unexpected first key: %+v", keys[0])
+	}
+	if len(keys[2].Scopes) != 3 || keys[2].Scopes[2] != "admin" {
+		t.Errorf("Expected ops key to carry admin scope, got %v", keys[2].Scopes)
+	}
+	if keys[0].Tenant != "" || keys[2].Tenant != "acme" {
+		t.Errorf("Expected only the ops key to name a tenant, got %q and %q", keys[0].Tenant, keys[2].Tenant)
+	}
+
+	cfg := Config{
+		Server:  ServerConfig{Port: 8080, ReadTimeout: 10, WriteTimeout: 10, IdleTimeout: 120},
+		Storage: "memory",
+		Auth:    AuthConfig{Mode: "apikey", APIKeys: parseAPIKeys("bad:key:superuser")},
+	}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for unknown scope, got nil")
+	}
+}
+
+func TestParseWebhookKeys(t *testing.T) {
+	keys := parseWebhookKeys("2024-10:new:secret; 2024-04 : old ;;")
+
+	want := []WebhookKeyConfig{{ID: "2024-10", Secret: "new:secret"}, {ID: "2024-04", Secret: "old"}}
+	if !slices.Equal(keys, want) {
+		t.Errorf("Expected %v, got %v", want, keys)
+	}
+
+	cfg := Config{
+		Server:  ServerConfig{Port: 8080, ReadTimeout: 10, WriteTimeout: 10, IdleTimeout: 120},
+		Storage: "memory",
+		Events:  EventsConfig{Backend: "webhook", Webhook: WebhookConfig{URL: "https://hooks.example.com", Keys: parseWebhookKeys("a:1;b:2;c:3")}},
+	}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for three webhook keys, got nil")
+	}
+	cfg.Events.Webhook.Keys = nil
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for a webhook without keys, got nil")
+	}
+}
+
+func TestParseProfiles(t *testing.T) {
+	profiles := parseProfiles("us-partner:unit=mi; acme: unit = nmi ,locale=fr;;broken")
+
+	want := map[string]ProfileConfig{"us-partner": {Unit: "mi"}, "acme": {Unit: "nmi"}}
+	if !maps.Equal(profiles, want) {
+		t.Errorf("Expected %v, got %v", want, profiles)
+	}
+
+	cfg := Config{
+		Server:  ServerConfig{Port: 8080, ReadTimeout: 10, WriteTimeout: 10, IdleTimeout: 120},
+		Storage: "memory",
+		Auth:    AuthConfig{Profiles: parseProfiles("mobile:unit=furlong")},
+	}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for unknown unit, got nil")
+	}
+}
+
+func TestReadStorageMigrationConfig(t *testing.T) {
+	t.Setenv("SOURCE_STORAGE_TYPE", "memory")
+	t.Setenv("SOURCE_SNAPSHOT_FILE", "locations.json")
+	t.Setenv("DEST_STORAGE_TYPE", "postgres")
+	t.Setenv("DEST_DB_HOST", "db.internal")
+	t.Setenv("DEST_DB_PORT", "6432")
+
+	cfg, err := ReadStorageMigrationConfig()
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if cfg.Source.Type != "memory" || cfg.Source.SnapshotFile != "locations.json" {
+		t.Errorf("Expected a memory source read from locations.json, got %+v", cfg.Source)
+	}
+	if cfg.Dest.Type != "postgres" || cfg.Dest.Database.Host != "db.internal" || cfg.Dest.Database.Port != 6432 || cfg.Dest.Database.DBName != "geolocation" {
+		t.Errorf("Expected the DEST_ database with defaults, got %+v", cfg.Dest.Database)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"missing type", map[string]string{"DEST_STORAGE_TYPE": ""}, "DEST_"},
+		{"memory without file", map[string]string{"SOURCE_SNAPSHOT_FILE": ""}, "SOURCE_"},
+		{"same file", map[string]string{"DEST_STORAGE_TYPE": "memory", "DEST_SNAPSHOT_FILE": "locations.json"}, "same file"},
+		{"same database", map[string]string{"SOURCE_STORAGE_TYPE": "postgres", "SOURCE_DB_HOST": "db.internal", "SOURCE_DB_PORT": "6432"}, "same database"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, err := ReadStorageMigrationConfig(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DeprecationConfig marks operations deprecated, keyed by operation ID.
+// Each value is "true", or a list such as
+// "sunset=2027-03-31; successor=/v1/locations" giving the date the
+// operation goes away and the route replacing it; either may be left out.
+type DeprecationConfig struct {
+	Operations map[string]string `json:"operations"`
+}
+
+// Deprecation describes one deprecated operation
+type Deprecation struct {
+	// Sunset is when the operation stops being served; zero if not yet
+	// decided
+	Sunset time.Time
+	// Successor is the URL of the operation replacing it, if any
+	Successor string
+}
+
+// loadDeprecations reads DEPRECATE_<OPERATION> variables, e.g.
+// DEPRECATE_GET_LOCATIONS for get-locations
+func loadDeprecations() map[string]string {
+	deprecations := map[string]string{}
+	for _, id := range OperationIDs {
+		if value := getEnv(DeprecationEnv(id), ""); value != "" {
+			deprecations[id] = value
+		}
+	}
+	return deprecations
+}
+
+// DeprecationEnv names the variable that deprecates an operation
+func DeprecationEnv(operationID string) string {
+	return "DEPRECATE_" + strings.ToUpper(strings.ReplaceAll(operationID, "-", "_"))
+}
+
+// Compile checks every entry and returns the deprecations by operation ID
+func (c DeprecationConfig) Compile() (map[string]Deprecation, error) {
+	deprecations := make(map[string]Deprecation, len(c.Operations))
+	for id, value := range c.Operations {
+		if !slices.Contains(OperationIDs, id) {
+			return nil, fmt.Errorf("unknown operation %q in deprecations", id)
+		}
+		deprecation, err := ParseDeprecation(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", DeprecationEnv(id), err)
+		}
+		deprecations[id] = deprecation
+	}
+	return deprecations, nil
+}
+
+// ParseDeprecation reads "true" or a semicolon-separated list of
+// sunset=DATE and successor=URL. The date is YYYY-MM-DD, meaning midnight
+// UTC, or RFC 3339.
+func ParseDeprecation(value string) (Deprecation, error) {
+	var deprecation Deprecation
+	if strings.EqualFold(strings.TrimSpace(value), "true") {
+		return deprecation, nil
+	}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		name, arg = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(arg)
+		if seen[name] {
+			return deprecation, fmt.Errorf("%s is repeated", name)
+		}
+		seen[name] = true
+		switch name {
+		case "sunset":
+			sunset, err := time.Parse(time.DateOnly, arg)
+			if err != nil {
+				if sunset, err = time.Parse(time.RFC3339, arg); err != nil {
+					return deprecation, fmt.Errorf("sunset needs a date such as 2027-03-31, got %q", arg)
+				}
+			}
+			deprecation.Sunset = sunset.UTC()
+		case "successor":
+			if arg == "" || strings.ContainsAny(arg, "<>\" ") {
+				return deprecation, fmt.Errorf("successor needs a URL, got %q", arg)
+			}
+			deprecation.Successor = arg
+		case "":
+			return deprecation, fmt.Errorf("empty entry in %q", value)
+		default:
+			return deprecation, fmt.Errorf("unsupported entry %q", name)
+		}
+	}
+	return deprecation, nil
+}
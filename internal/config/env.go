@@ -3,18 +3,103 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/netip"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/jesuloba-world/leeta-task/internal/text"
 	"github.com/jesuloba-world/leeta-task/pkg/validator"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig   `json:"server" validate:"required"`
-	Database DatabaseConfig `json:"database"`
-	Storage  string         `json:"storage" validate:"required,oneof=memory postgres"`
+	Server      ServerConfig      `json:"server" validate:"required"`
+	Database    DatabaseConfig    `json:"database"`
+	Storage     string            `json:"storage" validate:"required,oneof=memory postgres"`
+	Auth        AuthConfig        `json:"auth"`
+	Usage       UsageConfig       `json:"usage"`
+	Metrics     MetricsConfig     `json:"metrics"`
+	Outbox      OutboxConfig      `json:"outbox"`
+	Changes     ChangesConfig     `json:"changes"`
+	Compaction  CompactionConfig  `json:"compaction"`
+	Cache       CacheConfig       `json:"cache"`
+	UI          UIConfig          `json:"ui"`
+	Docs        DocsConfig        `json:"docs"`
+	Fallback    FallbackConfig    `json:"fallback"`
+	NearestScan NearestScanConfig `json:"nearest_scan"`
+	Names       NamesConfig       `json:"names"`
+	Regions     RegionsConfig     `json:"regions"`
+	Attachments AttachmentsConfig `json:"attachments"`
+	Privacy     PrivacyConfig     `json:"privacy"`
+	ExternalIDs ExternalIDsConfig `json:"external_ids"`
+	Sync        SyncConfig        `json:"sync"`
+	Events      EventsConfig      `json:"events"`
+	Integrity   IntegrityConfig   `json:"integrity"`
+	Imports     ImportsConfig     `json:"imports"`
+	// FaultInjection lets operators inject repository errors and latency
+	// at runtime, for rehearsing incidents
+	FaultInjection FaultInjectionConfig `json:"fault_injection"`
+	// AdaptiveTimeout limits location reads to a multiple of their recent
+	// latency
+	AdaptiveTimeout AdaptiveTimeoutConfig `json:"adaptive_timeout"`
+	// Readiness decides when GET /ready reports the instance unready
+	Readiness ReadinessConfig `json:"readiness"`
+	// CacheControl sets response Cache-Control headers per operation
+	CacheControl CacheControlConfig `json:"cache_control"`
+	// Deprecations marks operations deprecated per operation ID
+	Deprecations DeprecationConfig `json:"deprecations"`
+	// RateLimits limits each client per class of operation
+	RateLimits RateLimitConfig `json:"rate_limits"`
+	// Limits is validated separately; the zero value means DefaultLimits
+	Limits LimitsConfig `json:"limits" validate:"-"`
+	// DistanceStrategy selects how the memory store ranks nearest locations
+	DistanceStrategy string `json:"distance_strategy" validate:"omitempty,oneof=exact fast auto"`
+	// EarthRadiusKm overrides the sphere used for computed distances; 0 means
+	// the WGS-84 mean radius
+	EarthRadiusKm float64 `json:"earth_radius_km" validate:"min=0"`
+	// CoordinatePrecision is the decimal places coordinates are stored and
+	// returned with; 0 means the default of 6
+	CoordinatePrecision int `json:"coordinate_precision" validate:"omitempty,min=4,max=9"`
+	// DistanceUnit is the unit responses give distances in when neither the
+	// request nor the caller's profile names one; empty means km
+	DistanceUnit string `json:"distance_unit" validate:"omitempty,oneof=km m mi nmi"`
+	// DefaultSpeedKmh is the speed nearest responses estimate eta_minutes
+	// with when the request gives none; 0 leaves ETAs off by default
+	DefaultSpeedKmh float64 `json:"default_speed_kmh" validate:"min=0"`
+	// NearestMaxDistanceKm is the furthest a nearest location may be when
+	// the request gives no limit; 0 means no limit. Both this and
+	// DefaultSpeedKmh apply until search settings are stored.
+	NearestMaxDistanceKm float64 `json:"nearest_max_distance_km" validate:"min=0"`
+	// SuggestDistanceWeight is the share of a suggestion's score given to
+	// nearness, from 0 (names alone) to 1
+	SuggestDistanceWeight float64 `json:"suggest_distance_weight" validate:"min=0,max=1"`
+	// UnknownHoursOpen decides whether stations without opening hours pass
+	// open_at and open_now filters
+	UnknownHoursOpen bool `json:"unknown_hours_open"`
+	// NearestOpenCandidates is how many of the nearest stations an open
+	// nearest search checks before giving up; 0 means the default of 25
+	NearestOpenCandidates int `json:"nearest_open_candidates" validate:"min=0"`
+	// StrictBodies answers unknown request body fields with UNKNOWN_FIELDS
+	// and the known field each most likely meant
+	StrictBodies bool `json:"strict_bodies"`
+	// RequireConditionalWrites refuses changes to a single location sent
+	// without If-Match
+	RequireConditionalWrites bool `json:"require_conditional_writes"`
+	// OwnershipEnforcement refuses deletes and changes of a location to
+	// callers that neither created it nor hold the admin scope
+	OwnershipEnforcement bool `json:"ownership_enforcement"`
+	// DemoMode loads the demo dataset of world cities at startup, skipping
+	// cities already stored
+	DemoMode bool `json:"demo_mode"`
+	// DevMode marks a development instance, whose OpenAPI document also
+	// names a localhost server
+	DevMode bool `json:"dev_mode"`
+	// EnvironmentName names the deployment, such as qa or production. The
+	// admin truncate is available only when it is set, and must be sent
+	// back as its confirmation.
+	EnvironmentName string `json:"environment_name"`
 }
 
 type ServerConfig struct {
@@ -22,6 +107,21 @@ type ServerConfig struct {
 	ReadTimeout  int `json:"read_timeout" validate:"required,min=1"`
 	WriteTimeout int `json:"write_timeout" validate:"required,min=1"`
 	IdleTimeout  int `json:"idle_timeout" validate:"required,min=1"`
+	// ExternalBaseURL overrides the scheme and host used in generated links
+	ExternalBaseURL string `json:"external_base_url" validate:"omitempty,url"`
+	// TrustedProxies lists the CIDRs allowed to set forwarding headers
+	TrustedProxies []string `json:"trusted_proxies"`
+	// ShutdownTimeout bounds graceful shutdown, in seconds; 0 means 30
+	ShutdownTimeout int `json:"shutdown_timeout" validate:"min=0"`
+	// EndpointsDisabled lists operation IDs answered with 403 and left out
+	// of the OpenAPI document
+	EndpointsDisabled []string `json:"endpoints_disabled"`
+	// ServerTiming adds a Server-Timing header to every response that is
+	// not streamed
+	ServerTiming bool `json:"server_timing"`
+	// Router is the HTTP router the API is registered on: stdlib, the
+	// standard library's ServeMux, or chi
+	Router string `json:"router" validate:"omitempty,oneof=stdlib chi"`
 }
 
 type DatabaseConfig struct {
@@ -31,9 +131,460 @@ type DatabaseConfig struct {
 	Password string `json:"password"`
 	DBName   string `json:"dbname"`
 	SSLMode  string `json:"sslmode"`
+	// SQLComments tags each statement run for a request with its
+	// operation, tenant, principal and trace context
+	SQLComments bool `json:"sql_comments"`
+	// MigrationsDir holds the goose migrations, which --check compares
+	// with the database and AutoMigrate applies
+	MigrationsDir string `json:"migrations_dir"`
+	// AutoMigrate applies pending migrations at startup, one instance at
+	// a time
+	AutoMigrate bool `json:"auto_migrate"`
+	// MigrationLockWait is how long, in seconds, an instance waits for
+	// another's migrations before checking the schema itself
+	MigrationLockWait int `json:"migration_lock_wait" validate:"min=0"`
+	// Replica is an optional read replica for location reads
+	Replica ReplicaConfig `json:"replica"`
 }
 
+// ReplicaConfig points location reads at a streaming replica of the
+// database. Without a host every query goes to the primary.
+type ReplicaConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"-"`
+	DBName   string `json:"dbname"`
+	SSLMode  string `json:"sslmode"`
+	// MaxLag is how far behind the primary, in seconds, the replica may
+	// fall before reads move to the primary
+	MaxLag int `json:"max_lag" validate:"min=0"`
+	// CheckInterval is how often the lag is measured, in seconds
+	CheckInterval int `json:"check_interval" validate:"min=0"`
+}
+
+type AuthConfig struct {
+	Mode    string         `json:"mode" validate:"omitempty,oneof=none apikey jwt"`
+	APIKeys []APIKeyConfig `json:"api_keys" validate:"dive"`
+	JWT     JWTConfig      `json:"jwt"`
+	// Profiles holds caller preferences keyed by API key name, or by tenant
+	// with JWT auth
+	Profiles map[string]ProfileConfig `json:"profiles" validate:"dive"`
+}
+
+// ProfileConfig is one caller's preferences; empty fields prefer nothing
+type ProfileConfig struct {
+	Unit string `json:"unit,omitempty" validate:"omitempty,oneof=km m mi nmi"`
+}
+
+type JWTConfig struct {
+	JWKSURL     string `json:"jwks_url" validate:"omitempty,url"`
+	Issuer      string `json:"issuer"`
+	Audience    string `json:"audience"`
+	ScopesClaim string `json:"scopes_claim"`
+	TenantClaim string `json:"tenant_claim"`
+	// ClockSkew and JWKSRefresh are in seconds
+	ClockSkew   int `json:"clock_skew" validate:"min=0"`
+	JWKSRefresh int `json:"jwks_refresh" validate:"min=0"`
+}
+
+type APIKeyConfig struct {
+	Name   string   `json:"name" validate:"required"`
+	Key    string   `json:"-" validate:"required"`
+	Scopes []string `json:"scopes" validate:"dive,oneof=read write admin exact"`
+	// Tenant is the organisation the key's usage and traces are recorded
+	// under, as the tenant claim is with JWT auth
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type UsageConfig struct {
+	// MonthlyQuota applies to every key without an override; 0 disables quotas
+	MonthlyQuota int            `json:"monthly_quota" validate:"min=0"`
+	Quotas       map[string]int `json:"quotas"`
+	// FlushInterval is in seconds
+	FlushInterval int `json:"flush_interval" validate:"min=0"`
+}
+
+type MetricsConfig struct {
+	Enabled bool `json:"enabled"`
+	// StatsInterval is in seconds
+	StatsInterval int `json:"stats_interval" validate:"min=0"`
+}
+
+type OutboxConfig struct {
+	// PollInterval is in milliseconds
+	PollInterval int `json:"poll_interval" validate:"min=0"`
+	BatchSize    int `json:"batch_size" validate:"min=0"`
+	MaxAttempts  int `json:"max_attempts" validate:"min=0"`
+}
+
+// ChangesConfig controls compaction of the change log
+type ChangesConfig struct {
+	// Retain is how many of the newest changes are never compacted away;
+	// 0 keeps only the latest change to each name
+	Retain int `json:"retain" validate:"min=0"`
+	// CompactInterval is in seconds; PostgreSQL compacts on this schedule,
+	// memory as it writes
+	CompactInterval int `json:"compact_interval" validate:"min=0"`
+}
+
+// CompactionConfig controls rebuilding the memory store's indexes, which
+// keep the room of removed locations until rebuilt
+type CompactionConfig struct {
+	// Interval is in seconds between checks; 0 disables compaction
+	Interval int `json:"interval" validate:"min=0"`
+	// MinRemovedRatio is the share of removed locations, among those and
+	// the live ones, at which a check rebuilds the indexes
+	MinRemovedRatio float64 `json:"min_removed_ratio" validate:"min=0,max=1"`
+}
+
+// EventsConfig selects where change events are exported
+type EventsConfig struct {
+	// Backend is none, nats or webhook
+	Backend string        `json:"backend" validate:"omitempty,oneof=none nats webhook"`
+	NATS    NATSConfig    `json:"nats"`
+	Webhook WebhookConfig `json:"webhook"`
+}
+
+type NATSConfig struct {
+	URL string `json:"url"`
+	// SubjectPrefix is prepended to the event type, e.g. leeta.location.created
+	SubjectPrefix string `json:"subject_prefix"`
+	// ConnectTimeout is in seconds
+	ConnectTimeout int `json:"connect_timeout" validate:"min=0"`
+}
+
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Keys sign every delivery; a second key keeps consumers holding the
+	// previous secret verifying while it is rotated out
+	Keys []WebhookKeyConfig `json:"keys" validate:"max=2,dive"`
+	// Timeout is in seconds
+	Timeout int `json:"timeout" validate:"min=0"`
+	// QueueSize and MaxAttempts bound the queue deliveries wait in, and
+	// their retries, when events are published directly rather than from
+	// an outbox
+	QueueSize   int `json:"queue_size" validate:"min=0"`
+	MaxAttempts int `json:"max_attempts" validate:"min=0"`
+}
+
+type WebhookKeyConfig struct {
+	ID     string `json:"id" validate:"required"`
+	Secret string `json:"-" validate:"required"`
+}
+
+type CacheConfig struct {
+	// TTL is in seconds; 0 disables the location cache
+	TTL int `json:"ttl" validate:"min=0"`
+	// StaleTTL is how many seconds past TTL an entry is still served while
+	// it is refreshed in the background; 0 never serves expired entries
+	StaleTTL int `json:"stale_ttl" validate:"min=0"`
+	// LastKnownGood is how many seconds old a listing may be and still
+	// answer GET /locations when the repository fails; 0 never does
+	LastKnownGood int `json:"last_known_good" validate:"min=0"`
+	// PrimeOnStart fills the cache in the background at startup; serving
+	// waits up to PrimeBudget milliseconds for it
+	PrimeOnStart bool `json:"prime_on_start"`
+	PrimeBudget  int  `json:"prime_budget" validate:"min=0"`
+	// PrimeNames is how many of the most read names are saved to
+	// PrimeStateFile on shutdown and primed by the next run; without a
+	// file only the listing is primed
+	PrimeNames     int    `json:"prime_names" validate:"min=0"`
+	PrimeStateFile string `json:"prime_state_file"`
+}
+
+// FallbackConfig controls the snapshot /nearest falls back to when the
+// repository fails or is slow
+type FallbackConfig struct {
+	Enabled bool `json:"enabled"`
+	// RefreshInterval and MaxStaleness are in seconds; a snapshot older than
+	// MaxStaleness is not served, and 0 means no limit
+	RefreshInterval int `json:"refresh_interval" validate:"min=0"`
+	MaxStaleness    int `json:"max_staleness" validate:"min=0"`
+	// LatencyBudget is in milliseconds; 0 falls back on errors only
+	LatencyBudget int `json:"latency_budget" validate:"min=0"`
+}
+
+// NearestScanConfig bounds the locations one nearest search examines in the
+// memory store. Past SoftLimit the answer comes from the grid cells around
+// the query point and is marked approximate; past HardLimit the search
+// fails. HardLimit also bounds stocked searches in every store. 0 turns a
+// limit off.
+type NearestScanConfig struct {
+	SoftLimit int `json:"soft_limit" validate:"min=0"`
+	HardLimit int `json:"hard_limit" validate:"min=0"`
+}
+
+// IntegrityConfig controls the data integrity scan run at startup
+type IntegrityConfig struct {
+	// CheckOnStart scans in the background once the server is up
+	CheckOnStart bool `json:"check_on_start"`
+	// Fix is the normalisation the startup scan applies; empty applies none
+	Fix string `json:"fix" validate:"omitempty,oneof=trim"`
+}
+
+// ImportsConfig controls CSV imports. A zero value means the default.
+type ImportsConfig struct {
+	// MaxBytes caps an uploaded file
+	MaxBytes int `json:"max_bytes" validate:"min=0"`
+	// BatchSize is the rows an asynchronous import creates between
+	// progress updates and cancellation checks
+	BatchSize int `json:"batch_size" validate:"min=0"`
+	// StaleAfter is the seconds a running import may go without progress
+	// before it is marked failed, as its instance has stopped
+	StaleAfter int `json:"stale_after_seconds" validate:"min=0"`
+}
+
+// NamesConfig restricts the names new locations may take
+type NamesConfig struct {
+	// Blocklist and BlockPatterns are exact names and regular expressions,
+	// both matched ignoring case
+	Blocklist     []string `json:"blocklist"`
+	BlockPatterns []string `json:"block_patterns"`
+	// BlocklistFile adds rules from a file in the text.ParseBlocklist format
+	BlocklistFile string `json:"blocklist_file"`
+	// ReservedPrefixes may only start names created with the admin scope
+	ReservedPrefixes []string `json:"reserved_prefixes"`
+	// Collation orders names: a BCP 47 tag such as "en", "yo" or "und" for
+	// the Unicode default, or "binary" for byte order
+	Collation string `json:"collation"`
+}
+
+// CompileBlocklist builds the blocklist from the configured rules and file
+func (c NamesConfig) CompileBlocklist() (*text.Blocklist, error) {
+	names, patterns := c.Blocklist, c.BlockPatterns
+	if c.BlocklistFile != "" {
+		f, err := os.Open(c.BlocklistFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open name blocklist: %w", err)
+		}
+		defer f.Close()
+		fileNames, filePatterns, err := text.ParseBlocklist(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read name blocklist: %w", err)
+		}
+		names = append(slices.Clone(names), fileNames...)
+		patterns = append(slices.Clone(patterns), filePatterns...)
+	}
+	return text.NewBlocklist(names, patterns)
+}
+
+// RegionsConfig partitions locations by operating region
+type RegionsConfig struct {
+	// Names lists the regions locations may belong to; empty accepts any
+	Names []string `json:"names" validate:"dive,max=64"`
+	// Required makes a region mandatory on create and on nearest searches
+	Required bool `json:"required"`
+}
+
+// AttachmentsConfig controls which photo and document URLs locations may
+// reference
+type AttachmentsConfig struct {
+	// AllowedHosts limits attachment URLs to these hosts; an entry starting
+	// with a dot also allows subdomains. Empty allows any host.
+	AllowedHosts []string `json:"allowed_hosts"`
+	// MaxURLLength caps attachment URLs, in bytes
+	MaxURLLength int `json:"max_url_length" validate:"min=0"`
+	// CheckReachable sends a HEAD request to every attachment on create
+	CheckReachable bool `json:"check_reachable"`
+	// CheckTimeout bounds each reachability check, in milliseconds
+	CheckTimeout int `json:"check_timeout" validate:"min=0"`
+}
+
+// PrivacyConfig obscures the coordinates shown to callers without the
+// exact scope
+type PrivacyConfig struct {
+	Mode string `json:"mode" validate:"omitempty,oneof=off grid jitter"`
+	// Meters is the grid cell size, or the largest jitter offset
+	Meters float64 `json:"meters" validate:"min=0"`
+	// Secret keys the jitter; required in jitter mode so the offsets
+	// cannot be recomputed from location IDs
+	Secret string `json:"-" validate:"required_if=Mode jitter"`
+}
+
+// ExternalIDsConfig hides the IDs the store assigns behind opaque ones in
+// responses
+type ExternalIDsConfig struct {
+	// Enabled turns the encoding off when false even with a key, for
+	// clients that still hold real IDs
+	Enabled bool `json:"enabled"`
+	// Key keys the encoding; responses show real IDs without one.
+	// Deployments with different keys show different IDs for a location.
+	Key string `json:"-" validate:"omitempty,min=16"`
+}
+
+// Active reports whether responses show external IDs
+func (c ExternalIDsConfig) Active() bool {
+	return c.Enabled && c.Key != ""
+}
+
+// SyncConfig controls copying locations from another instance
+type SyncConfig struct {
+	// Enabled registers POST /admin/sync; it is off unless asked for, as
+	// a sync can delete every location
+	Enabled bool `json:"enabled"`
+	// AllowedSources lists the base URLs a sync may pull from. Empty
+	// allows none, so a caller cannot make the server fetch arbitrary
+	// hosts.
+	AllowedSources []string `json:"allowed_sources"`
+	// Timeout bounds pulling the source's locations, in seconds
+	Timeout int `json:"timeout" validate:"min=0"`
+	// PageSize is the page size used to read the source. Zero uses the
+	// largest the source advertises at GET /capabilities.
+	PageSize int `json:"page_size" validate:"min=0"`
+}
+
+// FaultInjectionConfig controls injecting faults into the location
+// repository through /admin/faults
+type FaultInjectionConfig struct {
+	// Enabled registers the /admin/faults endpoints; without it no fault
+	// can be injected
+	Enabled bool `json:"enabled"`
+	// MaxDuration is the longest faults stay in effect before they are
+	// cleared on their own, in seconds; 0 means five minutes
+	MaxDuration int `json:"max_duration" validate:"min=0"`
+}
+
+// AdaptiveTimeoutConfig limits each location repository read to the
+// larger of Floor and Multiplier times its p99 over the last Window
+type AdaptiveTimeoutConfig struct {
+	Enabled bool `json:"enabled"`
+	// Floor is in milliseconds, Window in seconds; 0 takes the defaults
+	// of 500ms, 3 and a minute
+	Floor      int     `json:"floor" validate:"min=0"`
+	Multiplier float64 `json:"multiplier" validate:"omitempty,min=1"`
+	Window     int     `json:"window" validate:"min=0"`
+}
+
+// ReadinessConfig checks the database every CheckInterval seconds,
+// reporting unready after FailureThreshold failed checks in a row and ready
+// again after SuccessThreshold passed ones; 0 takes the defaults of 3, 2,
+// 5 seconds and a 1000ms CheckTimeout
+type ReadinessConfig struct {
+	FailureThreshold int `json:"failure_threshold" validate:"min=0"`
+	SuccessThreshold int `json:"success_threshold" validate:"min=0"`
+	CheckInterval    int `json:"check_interval" validate:"min=0"`
+	CheckTimeout     int `json:"check_timeout" validate:"min=0"`
+}
+
+type UIConfig struct {
+	Enabled bool `json:"enabled"`
+	// APIBasePath is the path prefix the UI uses to reach the JSON API
+	APIBasePath string `json:"api_base_path"`
+}
+
+// DocsConfig controls the API documentation page and the OpenAPI document
+// it loads
+type DocsConfig struct {
+	// Enabled serves the documentation page at /docs
+	Enabled bool `json:"enabled"`
+	// OpenAPIEnabled serves the OpenAPI document at /openapi.json and its
+	// other formats; the documentation page needs it
+	OpenAPIEnabled bool `json:"openapi_enabled"`
+	// RateLimit is how many requests a client may make to these paths per
+	// minute, in bursts of up to as many; 0 means no limit
+	RateLimit int `json:"rate_limit" validate:"min=0"`
+	// Contact details and the description shown in the document; the
+	// contact is left out when both are empty
+	ContactName  string `json:"contact_name"`
+	ContactEmail string `json:"contact_email" validate:"omitempty,email"`
+	Description  string `json:"description"`
+	// PublicURL is the server URL the document names, such as
+	// https://geo.leeta.ng
+	PublicURL string `json:"public_url" validate:"omitempty,url"`
+}
+
+// LimitsConfig gathers the request size limits enforced by handlers
+type LimitsConfig struct {
+	DefaultPageSize int `json:"default_page_size" validate:"min=1"`
+	MaxPageSize     int `json:"max_page_size" validate:"min=1,gtefield=DefaultPageSize"`
+	// Batch sizes apply to batch operations such as spatial verification
+	DefaultBatchSize int `json:"default_batch_size" validate:"min=1"`
+	MaxBatchSize     int `json:"max_batch_size" validate:"min=1,gtefield=DefaultBatchSize"`
+	MaxBodyBytes     int `json:"max_body_bytes" validate:"min=1"`
+	// MaxDescriptionLength caps location descriptions, in characters
+	MaxDescriptionLength int `json:"max_description_length" validate:"min=1"`
+	// MaxExportRows caps the entries in one event log export response
+	MaxExportRows int `json:"max_export_rows" validate:"min=1"`
+	// MaxMatrixCells caps origins times destinations in one distance matrix
+	MaxMatrixCells int `json:"max_matrix_cells" validate:"min=1"`
+}
+
+// OperationIDs lists every operation the API can register, so that
+// ENDPOINTS_DISABLED can reject typos at startup
+var OperationIDs = []string{
+	"health-check",
+	"readiness-check",
+	"create-location",
+	"apply-location-transaction",
+	"get-locations",
+	"delete-location",
+	"add-location-alias",
+	"remove-location-alias",
+	"update-location-stock",
+	"find-nearest",
+	"get-location-at",
+	"suggest-locations",
+	"get-location",
+	"distance-matrix",
+	"list-location-changes",
+	"get-search-settings",
+	"update-search-settings",
+	"sync-locations",
+	"get-faults",
+	"inject-faults",
+	"clear-faults",
+	"create-saved-query",
+	"list-saved-queries",
+	"get-saved-query",
+	"update-saved-query",
+	"delete-saved-query",
+	"run-saved-query",
+	"get-usage",
+	"get-all-usage",
+	"verify-spatial",
+	"find-duplicate-locations",
+	"merge-locations",
+	"export-locations",
+	"restore-locations",
+	"get-integrity-report",
+	"start-integrity-check",
+	"list-outbox-events",
+	"requeue-outbox-event",
+	"export-audit-log",
+	"import-locations",
+	"get-import",
+	"cancel-import",
+	"truncate-locations",
+	"get-capabilities",
+}
+
+// DefaultLimits returns the limits used when none are configured
+func DefaultLimits() LimitsConfig {
+	return LimitsConfig{
+		DefaultPageSize:      20,
+		MaxPageSize:          100,
+		DefaultBatchSize:     500,
+		MaxBatchSize:         5000,
+		MaxBodyBytes:         1 << 20,
+		MaxDescriptionLength: 2000,
+		MaxExportRows:        10000,
+		MaxMatrixCells:       10000,
+	}
+}
+
+// LoadConfig reads the configuration and exits if it is invalid
 func LoadConfig() Config {
+	config, err := ReadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	return config
+}
+
+// ReadConfig reads the configuration from the environment and a .env file,
+// returning it along with any validation error
+func ReadConfig() (Config, error) {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
 		log.Printf("No .env file found or error loading it: %v", err)
@@ -41,27 +592,223 @@ func LoadConfig() Config {
 
 	config := Config{
 		Server: ServerConfig{
-			Port:         getEnvAsInt("SERVER_PORT", 8080),
-			ReadTimeout:  getEnvAsInt("SERVER_READ_TIMEOUT", 10),
-			WriteTimeout: getEnvAsInt("SERVER_WRITE_TIMEOUT", 10),
-			IdleTimeout:  getEnvAsInt("SERVER_IDLE_TIMEOUT", 120),
+			Port:              getEnvAsInt("SERVER_PORT", 8080),
+			ReadTimeout:       getEnvAsInt("SERVER_READ_TIMEOUT", 10),
+			WriteTimeout:      getEnvAsInt("SERVER_WRITE_TIMEOUT", 10),
+			IdleTimeout:       getEnvAsInt("SERVER_IDLE_TIMEOUT", 120),
+			ExternalBaseURL:   getEnv("EXTERNAL_BASE_URL", ""),
+			TrustedProxies:    getEnvAsSlice("TRUSTED_PROXIES", nil),
+			ShutdownTimeout:   getEnvAsInt("SHUTDOWN_TIMEOUT", 30),
+			EndpointsDisabled: getEnvAsSlice("ENDPOINTS_DISABLED", nil),
+			ServerTiming:      getEnvAsBool("SERVER_TIMING", false),
+			Router:            getEnv("SERVER_ROUTER", "stdlib"),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "geolocation"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:              getEnv("DB_HOST", "localhost"),
+			Port:              getEnvAsInt("DB_PORT", 5432),
+			User:              getEnv("DB_USER", "postgres"),
+			Password:          getEnv("DB_PASSWORD", "postgres"),
+			DBName:            getEnv("DB_NAME", "geolocation"),
+			SSLMode:           getEnv("DB_SSLMODE", "disable"),
+			SQLComments:       getEnvAsBool("DB_SQL_COMMENTS", false),
+			MigrationsDir:     getEnv("MIGRATIONS_DIR", "scripts/migrations"),
+			AutoMigrate:       getEnvAsBool("DB_AUTO_MIGRATE", false),
+			MigrationLockWait: getEnvAsInt("DB_MIGRATION_LOCK_WAIT_SECONDS", 120),
+			Replica: ReplicaConfig{
+				Host:          getEnv("DB_REPLICA_HOST", ""),
+				Port:          getEnvAsInt("DB_REPLICA_PORT", getEnvAsInt("DB_PORT", 5432)),
+				User:          getEnv("DB_REPLICA_USER", getEnv("DB_USER", "postgres")),
+				Password:      getEnv("DB_REPLICA_PASSWORD", getEnv("DB_PASSWORD", "postgres")),
+				DBName:        getEnv("DB_REPLICA_NAME", getEnv("DB_NAME", "geolocation")),
+				SSLMode:       getEnv("DB_REPLICA_SSLMODE", getEnv("DB_SSLMODE", "disable")),
+				MaxLag:        getEnvAsInt("DB_REPLICA_MAX_LAG_SECONDS", 10),
+				CheckInterval: getEnvAsInt("DB_REPLICA_CHECK_INTERVAL_SECONDS", 5),
+			},
 		},
 		Storage: getEnv("STORAGE_TYPE", "memory"),
+		Auth: AuthConfig{
+			Mode:    getEnv("AUTH_MODE", "none"),
+			APIKeys: parseAPIKeys(getEnv("API_KEYS", "")),
+			JWT: JWTConfig{
+				JWKSURL:     getEnv("JWT_JWKS_URL", ""),
+				Issuer:      getEnv("JWT_ISSUER", ""),
+				Audience:    getEnv("JWT_AUDIENCE", ""),
+				ScopesClaim: getEnv("JWT_SCOPES_CLAIM", "scope"),
+				TenantClaim: getEnv("JWT_TENANT_CLAIM", "tenant"),
+				ClockSkew:   getEnvAsInt("JWT_CLOCK_SKEW", 60),
+				JWKSRefresh: getEnvAsInt("JWT_JWKS_REFRESH", 300),
+			},
+			Profiles: parseProfiles(getEnv("AUTH_PROFILES", "")),
+		},
+		Usage: UsageConfig{
+			MonthlyQuota:  getEnvAsInt("USAGE_MONTHLY_QUOTA", 0),
+			Quotas:        parseQuotas(getEnv("USAGE_QUOTAS", "")),
+			FlushInterval: getEnvAsInt("USAGE_FLUSH_INTERVAL", 5),
+		},
+		Metrics: MetricsConfig{
+			Enabled:       getEnvAsBool("METRICS_ENABLED", true),
+			StatsInterval: getEnvAsInt("METRICS_STATS_INTERVAL", 60),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvAsInt("OUTBOX_POLL_INTERVAL", 1000),
+			BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+		},
+		Changes: ChangesConfig{
+			Retain:          getEnvAsInt("CHANGES_RETAIN", 10000),
+			CompactInterval: getEnvAsInt("CHANGES_COMPACT_INTERVAL", 300),
+		},
+		Compaction: CompactionConfig{
+			Interval:        getEnvAsInt("MEMORY_COMPACT_INTERVAL", 0),
+			MinRemovedRatio: getEnvAsFloat("MEMORY_COMPACT_REMOVED_RATIO", 0.5),
+		},
+		Cache: CacheConfig{
+			TTL:            getEnvAsInt("CACHE_TTL", 0),
+			StaleTTL:       getEnvAsInt("CACHE_STALE_TTL_SECONDS", 0),
+			LastKnownGood:  getEnvAsInt("CACHE_LAST_KNOWN_GOOD_SECONDS", 0),
+			PrimeOnStart:   getEnvAsBool("CACHE_PRIME_ON_START", false),
+			PrimeBudget:    getEnvAsInt("CACHE_PRIME_BUDGET_MS", 2000),
+			PrimeNames:     getEnvAsInt("CACHE_PRIME_NAMES", 100),
+			PrimeStateFile: getEnv("CACHE_PRIME_STATE_FILE", ""),
+		},
+		Limits: loadLimits(),
+		UI: UIConfig{
+			Enabled:     getEnvAsBool("UI_ENABLED", false),
+			APIBasePath: getEnv("UI_API_BASE_PATH", ""),
+		},
+		Docs: DocsConfig{
+			Enabled:        getEnvAsBool("DOCS_ENABLED", true),
+			OpenAPIEnabled: getEnvAsBool("OPENAPI_ENABLED", true),
+			RateLimit:      getEnvAsInt("DOCS_RATE_LIMIT", 60),
+			ContactName:    getEnv("API_CONTACT_NAME", ""),
+			ContactEmail:   getEnv("API_CONTACT_EMAIL", ""),
+			Description:    getEnv("API_DESCRIPTION", "A RESTful API for managing geolocated stations with nearest location search capabilities"),
+			PublicURL:      getEnv("API_PUBLIC_URL", ""),
+		},
+		Fallback: FallbackConfig{
+			Enabled:         getEnvAsBool("NEAREST_FALLBACK_ENABLED", false),
+			RefreshInterval: getEnvAsInt("NEAREST_FALLBACK_REFRESH_INTERVAL", 60),
+			MaxStaleness:    getEnvAsInt("NEAREST_FALLBACK_MAX_STALENESS", 600),
+			LatencyBudget:   getEnvAsInt("NEAREST_FALLBACK_LATENCY_BUDGET_MS", 500),
+		},
+		NearestScan: NearestScanConfig{
+			SoftLimit: getEnvAsInt("NEAREST_SCAN_SOFT_LIMIT", 0),
+			HardLimit: getEnvAsInt("NEAREST_SCAN_HARD_LIMIT", 0),
+		},
+		Names: NamesConfig{
+			Blocklist:        getEnvAsSlice("NAME_BLOCKLIST", nil),
+			BlockPatterns:    getEnvAsSlice("NAME_BLOCK_PATTERNS", nil),
+			BlocklistFile:    getEnv("NAME_BLOCKLIST_FILE", ""),
+			ReservedPrefixes: getEnvAsSlice("NAME_RESERVED_PREFIXES", nil),
+			Collation:        getEnv("NAME_COLLATION", "und"),
+		},
+		Regions: RegionsConfig{
+			Names:    getEnvAsSlice("REGIONS", nil),
+			Required: getEnvAsBool("REGION_REQUIRED", false),
+		},
+		Privacy: PrivacyConfig{
+			Mode:   getEnv("COORDINATE_PRIVACY", "off"),
+			Meters: getEnvAsFloat("COORDINATE_PRIVACY_METERS", 500),
+			Secret: getEnv("COORDINATE_PRIVACY_SECRET", ""),
+		},
+		ExternalIDs: ExternalIDsConfig{
+			Enabled: getEnvAsBool("EXTERNAL_IDS_ENABLED", true),
+			Key:     getEnv("EXTERNAL_ID_KEY", ""),
+		},
+		Sync: SyncConfig{
+			Enabled:        getEnvAsBool("SYNC_ENABLED", false),
+			AllowedSources: getEnvAsSlice("SYNC_ALLOWED_SOURCES", nil),
+			Timeout:        getEnvAsInt("SYNC_TIMEOUT_SECONDS", 300),
+			PageSize:       getEnvAsInt("SYNC_PAGE_SIZE", 0),
+		},
+		Attachments: AttachmentsConfig{
+			AllowedHosts:   getEnvAsSlice("ATTACHMENT_ALLOWED_HOSTS", nil),
+			MaxURLLength:   getEnvAsInt("ATTACHMENT_MAX_URL_LENGTH", 2048),
+			CheckReachable: getEnvAsBool("ATTACHMENT_CHECK_REACHABLE", false),
+			CheckTimeout:   getEnvAsInt("ATTACHMENT_CHECK_TIMEOUT_MS", 2000),
+		},
+		Events: EventsConfig{
+			Backend: getEnv("EVENTS_BACKEND", "none"),
+			NATS: NATSConfig{
+				URL:            getEnv("NATS_URL", "nats://localhost:4222"),
+				SubjectPrefix:  getEnv("NATS_SUBJECT_PREFIX", "leeta"),
+				ConnectTimeout: getEnvAsInt("NATS_CONNECT_TIMEOUT", 5),
+			},
+			Webhook: WebhookConfig{
+				URL:         getEnv("WEBHOOK_URL", ""),
+				Keys:        parseWebhookKeys(getEnv("WEBHOOK_SECRETS", "")),
+				Timeout:     getEnvAsInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+				QueueSize:   getEnvAsInt("WEBHOOK_QUEUE_SIZE", 1000),
+				MaxAttempts: getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			},
+		},
+		Integrity: IntegrityConfig{
+			CheckOnStart: getEnvAsBool("INTEGRITY_CHECK_ON_START", false),
+			Fix:          getEnv("INTEGRITY_CHECK_FIX", ""),
+		},
+		Imports: ImportsConfig{
+			MaxBytes:   getEnvAsInt("IMPORT_MAX_BYTES", 64<<20),
+			BatchSize:  getEnvAsInt("IMPORT_BATCH_SIZE", 500),
+			StaleAfter: getEnvAsInt("IMPORT_STALE_AFTER_SECONDS", 300),
+		},
+		FaultInjection: FaultInjectionConfig{
+			Enabled:     getEnvAsBool("FAULT_INJECTION_ENABLED", false),
+			MaxDuration: getEnvAsInt("FAULT_INJECTION_MAX_DURATION_SECONDS", 300),
+		},
+		AdaptiveTimeout: AdaptiveTimeoutConfig{
+			Enabled:    getEnvAsBool("ADAPTIVE_TIMEOUT_ENABLED", false),
+			Floor:      getEnvAsInt("ADAPTIVE_TIMEOUT_FLOOR_MS", 500),
+			Multiplier: getEnvAsFloat("ADAPTIVE_TIMEOUT_MULTIPLIER", 3),
+			Window:     getEnvAsInt("ADAPTIVE_TIMEOUT_WINDOW_SECONDS", 60),
+		},
+		Readiness: ReadinessConfig{
+			FailureThreshold: getEnvAsInt("READINESS_FAILURE_THRESHOLD", 3),
+			SuccessThreshold: getEnvAsInt("READINESS_SUCCESS_THRESHOLD", 2),
+			CheckInterval:    getEnvAsInt("READINESS_CHECK_INTERVAL_SECONDS", 5),
+			CheckTimeout:     getEnvAsInt("READINESS_CHECK_TIMEOUT_MS", 1000),
+		},
+		CacheControl: CacheControlConfig{
+			Policies: loadCachePolicies(),
+		},
+		RateLimits: RateLimitConfig{
+			Classes:    parseEntries(getEnv("RATE_LIMITS", "")),
+			Operations: parseEntries(getEnv("RATE_LIMIT_OPERATIONS", "")),
+		},
+		Deprecations: DeprecationConfig{
+			Operations: loadDeprecations(),
+		},
+		DistanceStrategy:         getEnv("DISTANCE_STRATEGY", "exact"),
+		EarthRadiusKm:            getEnvAsFloat("EARTH_RADIUS_KM", 0),
+		CoordinatePrecision:      getEnvAsInt("COORDINATE_PRECISION", 6),
+		DistanceUnit:             getEnv("DISTANCE_UNIT", "km"),
+		DefaultSpeedKmh:          getEnvAsFloat("ETA_DEFAULT_SPEED_KMH", 0),
+		NearestMaxDistanceKm:     getEnvAsFloat("NEAREST_MAX_DISTANCE_KM", 0),
+		SuggestDistanceWeight:    getEnvAsFloat("SUGGEST_DISTANCE_WEIGHT", 0.3),
+		UnknownHoursOpen:         getEnvAsBool("OPENING_HOURS_DEFAULT_OPEN", true),
+		NearestOpenCandidates:    getEnvAsInt("NEAREST_OPEN_CANDIDATES", 25),
+		StrictBodies:             getEnvAsBool("STRICT_BODIES", true),
+		RequireConditionalWrites: getEnvAsBool("REQUIRE_CONDITIONAL_WRITES", false),
+		OwnershipEnforcement:     getEnvAsBool("OWNERSHIP_ENFORCEMENT", false),
+		DemoMode:                 getEnvAsBool("DEMO_MODE", false),
+		DevMode:                  getEnvAsBool("DEV_MODE", false),
+		EnvironmentName:          getEnv("ENVIRONMENT_NAME", ""),
 	}
 
-	if err := ValidateConfig(config); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
-	}
+	return config, ValidateConfig(config)
+}
 
-	return config
+func loadLimits() LimitsConfig {
+	defaults := DefaultLimits()
+	return LimitsConfig{
+		DefaultPageSize:      getEnvAsInt("LIMITS_DEFAULT_PAGE_SIZE", defaults.DefaultPageSize),
+		MaxPageSize:          getEnvAsInt("LIMITS_MAX_PAGE_SIZE", defaults.MaxPageSize),
+		DefaultBatchSize:     getEnvAsInt("LIMITS_DEFAULT_BATCH_SIZE", defaults.DefaultBatchSize),
+		MaxBatchSize:         getEnvAsInt("LIMITS_MAX_BATCH_SIZE", defaults.MaxBatchSize),
+		MaxBodyBytes:         getEnvAsInt("LIMITS_MAX_BODY_BYTES", defaults.MaxBodyBytes),
+		MaxDescriptionLength: getEnvAsInt("LIMITS_MAX_DESCRIPTION_LENGTH", defaults.MaxDescriptionLength),
+		MaxExportRows:        getEnvAsInt("LIMITS_MAX_EXPORT_ROWS", defaults.MaxExportRows),
+		MaxMatrixCells:       getEnvAsInt("LIMITS_MAX_MATRIX_CELLS", defaults.MaxMatrixCells),
+	}
 }
 
 func ValidateConfig(cfg Config) error {
@@ -69,6 +816,68 @@ func ValidateConfig(cfg Config) error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	if cfg.Limits != (LimitsConfig{}) {
+		if err := validator.ValidateStruct(cfg.Limits); err != nil {
+			return fmt.Errorf("invalid limits: %w", err)
+		}
+	}
+
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if _, err := ParseTrustedProxy(proxy); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range cfg.Server.EndpointsDisabled {
+		if !slices.Contains(OperationIDs, id) {
+			return fmt.Errorf("unknown operation %q in ENDPOINTS_DISABLED", id)
+		}
+	}
+
+	if cfg.Docs.Enabled && !cfg.Docs.OpenAPIEnabled {
+		return fmt.Errorf("DOCS_ENABLED requires OPENAPI_ENABLED, as the documentation page loads the OpenAPI document")
+	}
+
+	if _, err := cfg.Names.CompileBlocklist(); err != nil {
+		return err
+	}
+
+	if scan := cfg.NearestScan; scan.SoftLimit > 0 && scan.HardLimit > 0 && scan.HardLimit < scan.SoftLimit {
+		return fmt.Errorf("NEAREST_SCAN_HARD_LIMIT (%d) must be at least NEAREST_SCAN_SOFT_LIMIT (%d)", scan.HardLimit, scan.SoftLimit)
+	}
+
+	if _, err := text.NewCollation(cfg.Names.Collation); err != nil {
+		return fmt.Errorf("invalid NAME_COLLATION: %w", err)
+	}
+
+	if _, err := cfg.CacheControl.Compile(); err != nil {
+		return err
+	}
+	if _, err := cfg.Deprecations.Compile(); err != nil {
+		return err
+	}
+	if _, err := cfg.RateLimits.Compile(); err != nil {
+		return err
+	}
+
+	if cfg.Events.Backend == "nats" && cfg.Events.NATS.URL == "" {
+		return fmt.Errorf("NATS_URL is required when EVENTS_BACKEND=nats")
+	}
+	if cfg.Events.Backend == "webhook" && cfg.Events.Webhook.URL == "" {
+		return fmt.Errorf("WEBHOOK_URL is required when EVENTS_BACKEND=webhook")
+	}
+	if cfg.Events.Backend == "webhook" && len(cfg.Events.Webhook.Keys) == 0 {
+		return fmt.Errorf("WEBHOOK_SECRETS is required when EVENTS_BACKEND=webhook")
+	}
+
+	if cfg.Auth.Mode == "apikey" && len(cfg.Auth.APIKeys) == 0 {
+		return fmt.Errorf("at least one API key is required when AUTH_MODE=apikey")
+	}
+
+	if cfg.Auth.Mode == "jwt" && cfg.Auth.JWT.JWKSURL == "" {
+		return fmt.Errorf("JWT_JWKS_URL is required when AUTH_MODE=jwt")
+	}
+
 	if cfg.Storage == "postgres" {
 		if cfg.Database.Host == "" {
 			return fmt.Errorf("database host is required when using postgres storage")
@@ -108,3 +917,154 @@ func getEnvAsInt(key string, defaultValue int) int {
 
 	return value
 }
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	values := []string{}
+	for _, part := range strings.Split(valueStr, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+
+	return values
+}
+
+// parseAPIKeys parses entries of the form name:key:scope,scope separated
+// by ';', each optionally followed by :tenant
+func parseAPIKeys(value string) []APIKeyConfig {
+	keys := []APIKeyConfig{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 4)
+		key := APIKeyConfig{Name: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 {
+			key.Key = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			for _, scope := range strings.Split(parts[2], ",") {
+				if scope = strings.TrimSpace(scope); scope != "" {
+					key.Scopes = append(key.Scopes, scope)
+				}
+			}
+		}
+		if len(parts) > 3 {
+			key.Tenant = strings.TrimSpace(parts[3])
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// parseWebhookKeys parses entries of the form id:secret separated by ';',
+// the key signing new deliveries first
+func parseWebhookKeys(value string) []WebhookKeyConfig {
+	keys := []WebhookKeyConfig{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, secret, _ := strings.Cut(entry, ":")
+		keys = append(keys, WebhookKeyConfig{ID: strings.TrimSpace(id), Secret: strings.TrimSpace(secret)})
+	}
+
+	return keys
+}
+
+// parseProfiles parses entries of the form name:setting=value,setting=value
+// separated by ';'. The only setting is unit; others are skipped.
+func parseProfiles(value string) map[string]ProfileConfig {
+	profiles := map[string]ProfileConfig{}
+	for _, entry := range strings.Split(value, ";") {
+		name, settings, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			continue
+		}
+		var profile ProfileConfig
+		for _, setting := range strings.Split(settings, ",") {
+			key, value, _ := strings.Cut(setting, "=")
+			if strings.TrimSpace(key) == "unit" {
+				profile.Unit = strings.TrimSpace(value)
+			}
+		}
+		profiles[strings.TrimSpace(name)] = profile
+	}
+
+	return profiles
+}
+
+// parseQuotas parses entries of the form key:quota separated by ';'.
+// Entries with a malformed quota are skipped.
+func parseQuotas(value string) map[string]int {
+	quotas := map[string]int{}
+	for _, entry := range strings.Split(value, ";") {
+		key, quota, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(quota))
+		if err != nil || n < 0 {
+			continue
+		}
+		quotas[strings.TrimSpace(key)] = n
+	}
+
+	return quotas
+}
+
+// ParseTrustedProxy parses a CIDR or a bare IP address into a prefix
+func ParseTrustedProxy(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/jesuloba-world/leeta-task/pkg/validator"
+)
+
+// StorageMigrationConfig names the backends --migrate-storage copies
+// locations between
+type StorageMigrationConfig struct {
+	Source StorageEndpointConfig `json:"source"`
+	Dest   StorageEndpointConfig `json:"dest"`
+}
+
+// StorageEndpointConfig describes one side of a storage migration
+type StorageEndpointConfig struct {
+	Type     string         `json:"type" validate:"required,oneof=memory postgres"`
+	Database DatabaseConfig `json:"database"`
+	// SnapshotFile holds the memory backend's locations in the
+	// GET /admin/export format. A memory source is read from it; a memory
+	// destination starts from it when it exists and is written back to it.
+	SnapshotFile string `json:"snapshot_file" validate:"required_if=Type memory"`
+}
+
+// ReadStorageMigrationConfig reads the SOURCE_* and DEST_* sections. Each
+// takes the same variables as the server's storage, prefixed: for example
+// SOURCE_STORAGE_TYPE, SOURCE_SNAPSHOT_FILE and DEST_DB_HOST.
+func ReadStorageMigrationConfig() (StorageMigrationConfig, error) {
+	cfg := StorageMigrationConfig{
+		Source: readStorageEndpoint("SOURCE_"),
+		Dest:   readStorageEndpoint("DEST_"),
+	}
+	return cfg, validateStorageMigration(cfg)
+}
+
+func readStorageEndpoint(prefix string) StorageEndpointConfig {
+	return StorageEndpointConfig{
+		Type: getEnv(prefix+"STORAGE_TYPE", ""),
+		Database: DatabaseConfig{
+			Host:     getEnv(prefix+"DB_HOST", "localhost"),
+			Port:     getEnvAsInt(prefix+"DB_PORT", 5432),
+			User:     getEnv(prefix+"DB_USER", "postgres"),
+			Password: getEnv(prefix+"DB_PASSWORD", "postgres"),
+			DBName:   getEnv(prefix+"DB_NAME", "geolocation"),
+			SSLMode:  getEnv(prefix+"DB_SSLMODE", "disable"),
+		},
+		SnapshotFile: getEnv(prefix+"SNAPSHOT_FILE", ""),
+	}
+}
+
+func validateStorageMigration(cfg StorageMigrationConfig) error {
+	for _, side := range []struct {
+		prefix   string
+		endpoint StorageEndpointConfig
+	}{
+		{"SOURCE_", cfg.Source},
+		{"DEST_", cfg.Dest},
+	} {
+		if err := validator.ValidateStruct(side.endpoint); err != nil {
+			return fmt.Errorf("invalid %s* storage: %w", side.prefix, err)
+		}
+	}
+
+	if cfg.Source.Type != cfg.Dest.Type {
+		return nil
+	}
+	switch cfg.Source.Type {
+	case "memory":
+		if cfg.Source.SnapshotFile == cfg.Dest.SnapshotFile {
+			return fmt.Errorf("SOURCE_SNAPSHOT_FILE and DEST_SNAPSHOT_FILE are the same file")
+		}
+	case "postgres":
+		src, dst := cfg.Source.Database, cfg.Dest.Database
+		if src.Host == dst.Host && src.Port == dst.Port && src.DBName == dst.DBName {
+			return fmt.Errorf("the source and destination are the same database")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Rate limit classes. Each class has buckets of its own, so a client
+// exhausting one, say with exports, can still make requests in another.
+const (
+	RateLimitRead     = "read"
+	RateLimitNearest  = "nearest"
+	RateLimitMutation = "mutation"
+	RateLimitBulk     = "bulk"
+	// RateLimitNone is never limited; it can be assigned to operations but
+	// not given a limit
+	RateLimitNone = "none"
+)
+
+// RateLimitClasses lists the classes a limit can be set for
+var RateLimitClasses = []string{RateLimitRead, RateLimitNearest, RateLimitMutation, RateLimitBulk}
+
+// RateLimitConfig limits each client per class of operation. Classes holds
+// each class's limit as "rps=10,burst=20"; a class without one is not
+// limited, so nothing is by default. Operations moves operations to another
+// class, keyed by operation ID. Operations in neither
+// DefaultRateLimitOperations nor Operations are read when they are GETs and
+// mutation otherwise.
+type RateLimitConfig struct {
+	Classes    map[string]string `json:"classes"`
+	Operations map[string]string `json:"operations"`
+}
+
+// RateLimit is one class's limit: RPS requests a second on average, in
+// bursts of up to Burst
+type RateLimit struct {
+	RPS   float64 `json:"rps"`
+	Burst int     `json:"burst"`
+}
+
+// RateLimits is the checked form of RateLimitConfig
+type RateLimits struct {
+	// Classes holds the limited classes
+	Classes map[string]RateLimit `json:"classes"`
+	// Operations maps operation IDs to their class, defaults included
+	Operations map[string]string `json:"operations"`
+}
+
+// DefaultRateLimitOperations assigns the operations whose class their
+// method does not tell
+func DefaultRateLimitOperations() map[string]string {
+	return map[string]string{
+		"find-nearest":      RateLimitNearest,
+		"get-location-at":   RateLimitNearest,
+		"suggest-locations": RateLimitNearest,
+		"distance-matrix":   RateLimitNearest,
+		"run-saved-query":   RateLimitNearest,
+		"export-locations":  RateLimitBulk,
+		"restore-locations": RateLimitBulk,
+		"import-locations":  RateLimitBulk,
+		"export-audit-log":  RateLimitBulk,
+		"sync-locations":    RateLimitBulk,
+		// Probes and discovery must keep answering under load
+		"health-check":     RateLimitNone,
+		"readiness-check":  RateLimitNone,
+		"get-capabilities": RateLimitNone,
+	}
+}
+
+// Compile checks every class name, operation ID and limit
+func (c RateLimitConfig) Compile() (RateLimits, error) {
+	limits := RateLimits{Classes: make(map[string]RateLimit, len(c.Classes)), Operations: DefaultRateLimitOperations()}
+	for class, value := range c.Classes {
+		if !slices.Contains(RateLimitClasses, class) {
+			return RateLimits{}, fmt.Errorf("unknown rate limit class %q; expected one of %s", class, strings.Join(RateLimitClasses, ", "))
+		}
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return RateLimits{}, fmt.Errorf("invalid rate limit for %s: %w", class, err)
+		}
+		limits.Classes[class] = limit
+	}
+	for id, class := range c.Operations {
+		if !slices.Contains(OperationIDs, id) {
+			return RateLimits{}, fmt.Errorf("unknown operation %q in rate limit classes", id)
+		}
+		if class != RateLimitNone && !slices.Contains(RateLimitClasses, class) {
+			return RateLimits{}, fmt.Errorf("unknown rate limit class %q for %s", class, id)
+		}
+		limits.Operations[id] = class
+	}
+	return limits, nil
+}
+
+// ParseRateLimit reads a comma-separated list of rps=N and burst=N. rps is
+// required and may be fractional; burst defaults to rps rounded up.
+func ParseRateLimit(value string) (RateLimit, error) {
+	var limit RateLimit
+	for _, part := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		name, arg = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(arg)
+		switch name {
+		case "rps":
+			rps, err := strconv.ParseFloat(arg, 64)
+			if err != nil || rps <= 0 || math.IsInf(rps, 0) {
+				return limit, fmt.Errorf("rps must be a positive number, got %q", arg)
+			}
+			limit.RPS = rps
+		case "burst":
+			burst, err := strconv.Atoi(arg)
+			if err != nil || burst < 1 {
+				return limit, fmt.Errorf("burst must be a positive integer, got %q", arg)
+			}
+			limit.Burst = burst
+		default:
+			return limit, fmt.Errorf("unknown setting %q", name)
+		}
+	}
+	if limit.RPS == 0 {
+		return limit, fmt.Errorf("rps is required")
+	}
+	if limit.Burst == 0 {
+		limit.Burst = int(math.Ceil(limit.RPS))
+	}
+	return limit, nil
+}
+
+// parseEntries parses entries of the form name:value separated by ';'
+func parseEntries(value string) map[string]string {
+	entries := map[string]string{}
+	for _, entry := range strings.Split(value, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			continue
+		}
+		entries[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return entries
+}
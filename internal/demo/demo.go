@@ -0,0 +1,122 @@
+// Package demo holds a reference dataset of world cities that DEMO_MODE
+// loads, so a fresh deployment has something to search.
+//
+// cities.csv.gz lists up to 20 cities per country, named "City, Country",
+// taken from github.com/tidwall/cities, which is in the public domain.
+package demo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// Region marks the demo locations: GET /locations?region=demo lists them,
+// and Remove deletes them
+const Region = "demo"
+
+//go:embed cities.csv.gz
+var citiesCSV []byte
+
+// Cities decodes the embedded dataset. The locations carry Region and no
+// ID.
+func Cities() ([]domain.Location, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(citiesCSV))
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(gz)
+	reader.FieldsPerRecord = 3
+	// The first record is the header
+	if _, err := reader.Read(); err != nil {
+		return nil, err
+	}
+
+	var cities []domain.Location
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return cities, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		latitude, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("latitude of %s: %w", record[0], err)
+		}
+		longitude, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("longitude of %s: %w", record[0], err)
+		}
+		cities = append(cities, domain.Location{Name: record[0], Latitude: latitude, Longitude: longitude, Region: Region})
+	}
+}
+
+// source streams the dataset to a StorageMigrator
+type source []domain.Location
+
+func (s source) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	return func(yield func(*domain.Location, error) bool) {
+		for i := range s {
+			if !yield(&s[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+// Load copies the cities into target in batches through its restore path,
+// numbered after its largest ID. Cities whose name target already holds
+// are skipped, so loading again at every start adds nothing the second
+// time.
+func Load(ctx context.Context, target domain.MigrationTarget) (*domain.MigrationResult, error) {
+	cities, err := Cities()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the demo dataset: %w", err)
+	}
+	return service.NewStorageMigrator(source(cities), target).Migrate(ctx, domain.MigrationOptions{ContinueOnConflict: true})
+}
+
+// Remove deletes every location in Region, a transaction of at most
+// domain.MaxTransactionOperations at a time, and returns how many it
+// deleted
+func Remove(ctx context.Context, repo domain.LocationRepository) (int, error) {
+	transactor, ok := repo.(domain.LocationTransactor)
+	if !ok {
+		return 0, domain.ErrTransactionsUnsupported
+	}
+	filter := domain.LocationFilter{Region: Region}
+	page := domain.Page{Limit: domain.MaxTransactionOperations}
+	removed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		locations, err := repo.Find(filter, page, domain.LocationSort{})
+		if err != nil {
+			return removed, err
+		}
+		if len(locations) == 0 {
+			return removed, nil
+		}
+		ops := make([]domain.LocationOperation, len(locations))
+		for i, location := range locations {
+			ops[i] = domain.LocationOperation{Type: domain.OperationDelete, Name: location.Name}
+		}
+		results, err := transactor.ApplyOperations(ops)
+		if err != nil {
+			return removed, fmt.Errorf("failed to delete demo locations: %w", err)
+		}
+		removed += len(results)
+	}
+}
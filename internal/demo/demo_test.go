@@ -0,0 +1,77 @@
+package demo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/demo"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+func TestCities(t *testing.T) {
+	t.Parallel()
+	cities, err := demo.Cities()
+	if err != nil {
+		t.Fatalf("Failed to read the dataset: %v", err)
+	}
+	if len(cities) < 2000 {
+		t.Errorf("Expected a few thousand cities, got %d", len(cities))
+	}
+	names := make(map[string]bool, len(cities))
+	for _, city := range cities {
+		if err := city.Validate(); err != nil {
+			t.Errorf("Invalid city %s: %v", city.Name, err)
+		}
+		if names[city.Name] {
+			t.Errorf("Repeated city %s", city.Name)
+		}
+		names[city.Name] = true
+		if city.Region != demo.Region {
+			t.Errorf("Expected %s in region %q, got %q", city.Name, demo.Region, city.Region)
+		}
+	}
+}
+
+func TestLoadAndRemove(t *testing.T) {
+	t.Parallel()
+	cities, _ := demo.Cities()
+	repo := memory.NewInMemoryLocationRepository()
+	// An operator's own location that happens to share a city's name
+	own, _ := domain.NewLocation(cities[0].Name, 1, 1)
+	repo.Save(own)
+
+	result, err := demo.Load(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if result.Migrated != len(cities)-1 || len(result.Conflicts) != 1 {
+		t.Errorf("Expected %d loaded and the taken name skipped, got %d and %d conflicts", len(cities)-1, result.Migrated, len(result.Conflicts))
+	}
+	if count, _ := repo.Count(); count != len(cities) {
+		t.Errorf("Expected %d locations, got %d", len(cities), count)
+	}
+
+	// A second start adds nothing
+	result, err = demo.Load(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Failed to load again: %v", err)
+	}
+	if result.Migrated != 0 {
+		t.Errorf("Expected nothing loaded the second time, got %d", result.Migrated)
+	}
+	if count, _ := repo.Count(); count != len(cities) {
+		t.Errorf("Expected still %d locations, got %d", len(cities), count)
+	}
+
+	removed, err := demo.Remove(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if removed != len(cities)-1 {
+		t.Errorf("Expected %d removed, got %d", len(cities)-1, removed)
+	}
+	if remaining, _ := repo.FindAll(); len(remaining) != 1 || remaining[0].ID != own.ID {
+		t.Errorf("Expected only the operator's location left, got %v", remaining)
+	}
+}
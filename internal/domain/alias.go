@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrAliasNotFound is returned when removing an alias the location
+	// does not have
+	ErrAliasNotFound = errors.New("alias not found")
+	// ErrInvalidAliases is returned for a location whose aliases are
+	// blank, repeated or repeat its name
+	ErrInvalidAliases = errors.New("aliases must be distinct, non-blank and differ from the name")
+)
+
+// NameTakenError is returned when a name or alias is already an alias of
+// another location, or a new alias is already some location's name. Owner
+// is the canonical name of the location holding it.
+type NameTakenError struct {
+	Name  string
+	Owner string
+}
+
+func (e *NameTakenError) Error() string {
+	return "name " + e.Name + " is already used by " + e.Owner
+}
+
+func (e *NameTakenError) Unwrap() error {
+	return ErrLocationExists
+}
+
+// NameConflict returns the error for claiming name when owner already
+// holds it: ErrLocationExists when it is owner's own name, so creating a
+// duplicate behaves as before aliases, and a NameTakenError otherwise
+func NameConflict(name string, owner *Location) error {
+	if owner.Name == name {
+		return ErrLocationExists
+	}
+	return &NameTakenError{Name: name, Owner: owner.Name}
+}
+
+// validateAliases checks a location's own aliases; whether other
+// locations hold them is up to the store
+func (l *Location) validateAliases() error {
+	seen := map[string]bool{l.Name: true}
+	for _, alias := range l.Aliases {
+		if strings.TrimSpace(alias) != alias || alias == "" || seen[alias] {
+			return ErrInvalidAliases
+		}
+		seen[alias] = true
+	}
+	return nil
+}
+
+// HasName reports whether name is the location's name or one of its aliases
+func (l *Location) HasName(name string) bool {
+	return l.Name == name || slices.Contains(l.Aliases, name)
+}
+
+// LocationAliaser manages the alternate names a location is also found by.
+// Names and aliases share one namespace: no alias may equal any location's
+// name or another alias. Both methods accept the location's name or any of
+// its aliases and return the location as updated, making the change only
+// if the location passes checks.
+type LocationAliaser interface {
+	AddAlias(name, alias string, checks ...Precondition) (*Location, error)
+	RemoveAlias(name, alias string, checks ...Precondition) (*Location, error)
+}
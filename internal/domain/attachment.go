@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultAttachmentURLMaxLength is the longest attachment URL accepted
+	// when no limit is configured
+	DefaultAttachmentURLMaxLength = 2048
+	// MaxAttachments is the most attachments one location may carry
+	MaxAttachments = 10
+)
+
+var (
+	// ErrInvalidAttachment wraps every attachment validation failure
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentUnreachable wraps a failed liveness check
+	ErrAttachmentUnreachable = errors.New("attachment unreachable")
+)
+
+// AttachmentKind says what an attachment shows
+type AttachmentKind string
+
+const (
+	AttachmentPhoto    AttachmentKind = "photo"
+	AttachmentDocument AttachmentKind = "document"
+)
+
+// Attachment is a reference to a file about a station, such as a photo,
+// stored elsewhere; only the URL is kept
+type Attachment struct {
+	URL  string         `json:"url" example:"https://cdn.leeta.ng/stations/lekki-forecourt.jpg" doc:"https URL of the file, on an allowed host when the server restricts them"`
+	Kind AttachmentKind `json:"kind" enum:"photo,document" example:"photo" doc:"What the file is"`
+}
+
+// AttachmentError reports which attachment failed and why
+type AttachmentError struct {
+	Index  int
+	Reason string
+	// Err is ErrInvalidAttachment or ErrAttachmentUnreachable
+	Err error
+}
+
+func (e *AttachmentError) Error() string {
+	return fmt.Sprintf("attachment %d: %s", e.Index, e.Reason)
+}
+
+func (e *AttachmentError) Unwrap() error {
+	return e.Err
+}
+
+// AttachmentPolicy decides which attachment URLs are accepted. The zero
+// value accepts any https URL up to DefaultAttachmentURLMaxLength.
+type AttachmentPolicy struct {
+	// AllowedHosts limits URLs to these hosts, ignoring case; an entry
+	// starting with a dot also allows every subdomain. Empty allows any host.
+	AllowedHosts []string
+	// MaxURLLength is the longest URL accepted, in bytes; 0 means
+	// DefaultAttachmentURLMaxLength
+	MaxURLLength int
+}
+
+// Validate checks each attachment's kind and URL: https only, with a host,
+// no credentials, within the length limit and on an allowed host
+func (p AttachmentPolicy) Validate(attachments []Attachment) error {
+	if len(attachments) > MaxAttachments {
+		return &AttachmentError{Index: MaxAttachments, Reason: fmt.Sprintf("at most %d attachments are allowed", MaxAttachments), Err: ErrInvalidAttachment}
+	}
+	maxLength := p.MaxURLLength
+	if maxLength <= 0 {
+		maxLength = DefaultAttachmentURLMaxLength
+	}
+	for i, attachment := range attachments {
+		if reason := p.check(attachment, maxLength); reason != "" {
+			return &AttachmentError{Index: i, Reason: reason, Err: ErrInvalidAttachment}
+		}
+	}
+	return nil
+}
+
+func (p AttachmentPolicy) check(attachment Attachment, maxLength int) string {
+	switch attachment.Kind {
+	case AttachmentPhoto, AttachmentDocument:
+	default:
+		return fmt.Sprintf("kind %q is not photo or document", attachment.Kind)
+	}
+	if len(attachment.URL) > maxLength {
+		return fmt.Sprintf("url is longer than %d characters", maxLength)
+	}
+	u, err := url.Parse(attachment.URL)
+	if err != nil {
+		return "url is not a valid URL"
+	}
+	if u.Scheme != "https" {
+		return "url must use https"
+	}
+	if u.Hostname() == "" {
+		return "url has no host"
+	}
+	if u.User != nil {
+		return "url must not carry credentials"
+	}
+	if !p.hostAllowed(u.Hostname()) {
+		return fmt.Sprintf("host %s is not allowed", u.Hostname())
+	}
+	return ""
+}
+
+func (p AttachmentPolicy) hostAllowed(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if host == allowed || (strings.HasPrefix(allowed, ".") && (strings.HasSuffix(host, allowed) || host == allowed[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachmentChecker verifies that an attachment URL resolves
+type AttachmentChecker interface {
+	CheckAttachment(url string) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "errors"
+
+// ErrScanBudgetExceeded is returned when a nearest search would examine
+// more locations than the hard limit allows
+var ErrScanBudgetExceeded = errors.New("nearest search exceeded its scan budget")
+
+// ScanBudget bounds the locations one nearest search may examine in a store
+// that scans them. Past Soft the search examines only locations near the
+// query point and the answer is approximate; past Hard it fails with
+// ErrScanBudgetExceeded. Zero turns a limit off.
+type ScanBudget struct {
+	Soft int
+	Hard int
+}
+
+// BudgetedNearestFinder is implemented by repositories that may answer a
+// nearest search approximately to stay within a scan budget
+type BudgetedNearestFinder interface {
+	// FindNearestBudgeted is FindNearestInRegion, or FindNearest when
+	// region is empty, setting Approximate on answers past the soft limit
+	FindNearestBudgeted(region string, latitude, longitude float64, exclude ...string) (*NearestResult, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Cache priming states
+const (
+	CachePrimeWarming = "warming"
+	CachePrimePrimed  = "primed"
+	CachePrimeFailed  = "failed"
+)
+
+// CachePrimeStatus describes the startup priming of the location cache
+type CachePrimeStatus struct {
+	State string
+	// Names is how many names saved by the previous run were asked for,
+	// Locations how many locations priming read
+	Names      int
+	Locations  int
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Error      string
+}
+
+// CachePrimer is implemented by caches that can be filled ahead of requests
+type CachePrimer interface {
+	// Prime caches the full listing and the locations with these names or
+	// aliases, returning how many locations it read
+	Prime(ctx context.Context, names []string) (int, error)
+	// HotNames returns up to n of the names read most often, most read
+	// first
+	HotNames(n int) []string
+}
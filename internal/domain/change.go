@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+type ChangeAction string
+
+const (
+	ChangeCreated ChangeAction = "created"
+	ChangeUpdated ChangeAction = "updated"
+	ChangeDeleted ChangeAction = "deleted"
+)
+
+// Change is one entry in the change log. Sequences increase strictly in
+// the order changes commit but may skip values.
+type Change struct {
+	Sequence int64
+	Action   ChangeAction
+	// Name is the name the change applies to. A rename is recorded as a
+	// deletion of the old name and an update of the new one.
+	Name string
+	// Location is the state after the change, or the last state before a
+	// deletion
+	Location  Location
+	ChangedAt time.Time
+}
+
+// ChangeFeed is a page of the change log
+type ChangeFeed struct {
+	Changes []Change
+	// Latest is the largest sequence recorded, whether or not it is in
+	// Changes; 0 when the log is empty
+	Latest int64
+}
+
+// ChangeLog records every location mutation so clients can sync
+// incrementally, deletions included
+type ChangeLog interface {
+	// ChangesSince returns up to limit changes with a sequence above since,
+	// in sequence order
+	ChangesSince(since int64, limit int) (*ChangeFeed, error)
+}
+
+// ChangeCompactor drops superseded changes from a change log
+type ChangeCompactor interface {
+	// CompactChanges applies CompactChanges to the stored log and returns
+	// how many changes it dropped
+	CompactChanges(retain int) (int, error)
+}
+
+// CompactChanges drops every change that a later change to the same name
+// supersedes, except among the newest retain changes, which are all kept
+// so clients that are only slightly behind still see each step. The latest
+// change to every name always survives, so replaying the compacted log
+// from any sequence still ends at the current state. changes must be in
+// sequence order; it is compacted in place.
+func CompactChanges(changes []Change, retain int) []Change {
+	cutoff := len(changes) - retain
+	if cutoff <= 0 {
+		return changes
+	}
+	latest := make(map[string]int64, len(changes))
+	for _, change := range changes {
+		latest[change.Name] = change.Sequence
+	}
+	kept := changes[:0]
+	for i, change := range changes {
+		if i >= cutoff || latest[change.Name] == change.Sequence {
+			kept = append(kept, change)
+		}
+	}
+	clear(changes[len(kept):])
+	return kept
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultDescriptionMaxLength is the description limit, in characters,
+// when none is configured
+const DefaultDescriptionMaxLength = 2000
+
+// ErrDescriptionTooLong matches every DescriptionTooLongError
+var ErrDescriptionTooLong = errors.New("description too long")
+
+// DescriptionTooLongError reports a description over the configured limit
+type DescriptionTooLongError struct {
+	Length int
+	Max    int
+}
+
+func (e *DescriptionTooLongError) Error() string {
+	return fmt.Sprintf("description has %d characters, more than the maximum of %d", e.Length, e.Max)
+}
+
+func (e *DescriptionTooLongError) Is(target error) bool {
+	return target == ErrDescriptionTooLong
+}
+
+// CleanDescription strips control characters other than newlines and tabs,
+// so pasted notes cannot smuggle terminal escapes or NULs into the store,
+// and trims surrounding whitespace. A CRLF line break becomes a newline.
+func CleanDescription(description string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r != '\n' && r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, description)
+	return strings.TrimSpace(cleaned)
+}
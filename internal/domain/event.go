@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"iter"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventLocationCreated EventType = "location.created"
+	EventLocationUpdated EventType = "location.updated"
+	EventLocationDeleted EventType = "location.deleted"
+)
+
+var ErrOutboxEventNotFound = errors.New("outbox event not found")
+
+// ErrInvalidEventRange is returned for an event range whose start is not
+// before its end
+var ErrInvalidEventRange = errors.New("from must be before to")
+
+// Event describes a change to a location. Its ID is minted once, when the
+// change is made, and every transport and every redelivery carries it, so
+// consumers can discard duplicates by ID.
+type Event struct {
+	ID   string    `json:"id"`
+	Type EventType `json:"type"`
+	// Sequence orders the events of one location: each is numbered one
+	// higher than the location's previous event. It is assigned when the
+	// event is recorded in an outbox or published directly, and is 0
+	// until then.
+	Sequence   int64     `json:"sequence"`
+	Location   Location  `json:"location"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// NewEvent creates an event with a new ID. It is the one place events are
+// made, whichever path publishes them.
+func NewEvent(eventType EventType, location Location) Event {
+	return Event{
+		ID:         NewEventID(),
+		Type:       eventType,
+		Location:   location,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// crockford is the base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulids keeps the last ULID issued, so IDs from one process sort in the
+// order they were minted even within a millisecond
+var ulids struct {
+	sync.Mutex
+	ms      uint64
+	entropy [10]byte
+}
+
+// NewEventID returns a ULID: 26 characters that sort by the millisecond
+// they were minted in, followed by 80 random bits. Within a millisecond
+// the random part is incremented, so IDs from one process strictly
+// increase.
+func NewEventID() string {
+	ulids.Lock()
+	ms := uint64(time.Now().UnixMilli())
+	if ms <= ulids.ms {
+		ms = ulids.ms
+		for i := len(ulids.entropy) - 1; i >= 0; i-- {
+			ulids.entropy[i]++
+			if ulids.entropy[i] != 0 {
+				break
+			}
+		}
+	} else {
+		ulids.ms = ms
+		rand.Read(ulids.entropy[:])
+	}
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], ulids.entropy[:])
+	ulids.Unlock()
+
+	// 128 bits in 26 characters of 5 bits, the first holding only 3
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+type EventPublisher interface {
+	Publish(event Event) error
+}
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	OutboxFailed    OutboxStatus = "failed"
+)
+
+// OutboxEvent is an event persisted alongside the change that produced it
+type OutboxEvent struct {
+	Sequence      int64        `json:"sequence"`
+	Event         Event        `json:"event"`
+	Status        OutboxStatus `json:"status"`
+	Attempts      int          `json:"attempts"`
+	LastError     string       `json:"last_error,omitempty"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	DeliveredAt   *time.Time   `json:"delivered_at,omitempty"`
+}
+
+// OutboxStats summarises undelivered outbox events
+type OutboxStats struct {
+	Pending int
+	Failed  int
+	// OldestPending is the age of the oldest undelivered event
+	OldestPending time.Duration
+}
+
+type OutboxRepository interface {
+	// DispatchPending publishes up to limit due events, marking each delivered
+	// or scheduling a retry. Events that fail maxAttempts times are marked failed.
+	DispatchPending(limit, maxAttempts int, publish func(Event) error) (int, error)
+	ListOutboxEvents(status OutboxStatus, limit int) ([]OutboxEvent, error)
+	RequeueOutboxEvent(sequence int64) error
+	OutboxStats() (OutboxStats, error)
+}
+
+// LoggedEvent is an event as recorded in the event log
+type LoggedEvent struct {
+	Sequence int64
+	// LoggedAt is when the event was recorded, as stored
+	LoggedAt time.Time
+	Event    Event
+}
+
+// Cursor returns the position just after the event
+func (e LoggedEvent) Cursor() EventCursor {
+	return EventCursor{LoggedAt: e.LoggedAt, Sequence: e.Sequence}
+}
+
+// EventCursor is a position in the event log, which is ordered by the time
+// events were recorded and then by sequence
+type EventCursor struct {
+	LoggedAt time.Time
+	Sequence int64
+}
+
+// EventRange selects the events recorded at or after From and before To,
+// resuming after After when it is set
+type EventRange struct {
+	From  time.Time
+	To    time.Time
+	After *EventCursor
+}
+
+// Validate rejects a range that selects nothing
+func (r EventRange) Validate() error {
+	if !r.From.Before(r.To) {
+		return ErrInvalidEventRange
+	}
+	return nil
+}
+
+// EventLog yields recorded events in log order, applying the range in the
+// store so events outside it are never read
+type EventLog interface {
+	StreamEvents(ctx context.Context, r EventRange, batchSize int) iter.Seq2[LoggedEvent, error]
+}
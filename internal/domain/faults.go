@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// FaultKind is the error a call failed by fault injection returns
+type FaultKind string
+
+const (
+	// FaultInternal fails the call as an unexpected storage error
+	FaultInternal FaultKind = "internal"
+	// FaultTimeout fails the call as a storage timeout
+	FaultTimeout FaultKind = "timeout"
+	// FaultNotFound fails the call as if the location did not exist
+	FaultNotFound FaultKind = "not_found"
+)
+
+// FaultMethods are the location repository methods faults can be
+// injected into. FindNearest covers every nearest search, in a region,
+// with a minimum stock or not.
+var FaultMethods = []string{
+	"Save", "FindByName", "FindByID", "FindAll", "Find", "Delete", "FindNearest", "Count",
+	"SuggestLocations", "AddAlias", "RemoveAlias", "UpdateStock", "ApplyOperations",
+	"MergeLocations", "RestoreLocations", "TruncateLocations", "RenameLocation", "StreamLocations",
+}
+
+// ErrUnknownFaultMethod is returned for a fault naming a method not in
+// FaultMethods
+var ErrUnknownFaultMethod = errors.New("unknown repository method")
+
+// Fault describes what to inject into calls of one repository method
+type Fault struct {
+	// ErrorRate is the share of calls, from 0 to 1, that fail
+	ErrorRate float64
+	// Kind is the error failing calls return
+	Kind FaultKind
+	// Latency is added to every call, failing or not
+	Latency time.Duration
+}
+
+// FaultPlan is the faults in effect, by method
+type FaultPlan struct {
+	Faults map[string]Fault
+	// ExpiresAt is when the faults are cleared on their own; zero when
+	// there are none
+	ExpiresAt time.Time
+}
+
+// FaultInjector changes the faults injected into the location repository
+// at runtime. Faults are always cleared after a bounded time, so a
+// forgotten rehearsal cannot outlive it.
+type FaultInjector interface {
+	Faults() FaultPlan
+	// InjectFaults replaces the faults in effect for duration, or the
+	// injector's maximum when that is shorter or duration is zero
+	InjectFaults(faults map[string]Fault, duration time.Duration) (FaultPlan, error)
+	ClearFaults()
+}
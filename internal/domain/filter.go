@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidCreatedRange is returned when a filter's created_at bounds
+	// are out of order
+	ErrInvalidCreatedRange = errors.New("created_after must be before created_before")
+	// ErrInvalidBoundingBox is returned when a filter's bounding box has
+	// out-of-range coordinates or its latitudes are out of order
+	ErrInvalidBoundingBox = errors.New("invalid bounding box")
+)
+
+// LocationFilter narrows a location listing. Zero fields match everything,
+// so the zero filter lists all locations. Set fields combine with AND.
+type LocationFilter struct {
+	// CreatedAfter and CreatedBefore bound created_at. Both bounds are
+	// inclusive, matching SQL BETWEEN.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// NameContains matches names containing it, ignoring case
+	NameContains string
+	// SearchDescriptions widens NameContains to also match descriptions
+	SearchDescriptions bool
+	// BBox matches locations inside the box, edges included
+	BBox *BoundingBox
+	// Region matches locations in this region
+	Region string
+	// AfterID matches locations whose ID is greater, compared as numbers.
+	// With the default order it resumes a listing after the last location
+	// seen, even if earlier ones were deleted since.
+	AfterID string
+}
+
+// BoundingBox is an area between two latitudes and two longitudes. A box
+// whose MinLongitude is greater than its MaxLongitude crosses the
+// antimeridian.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// CrossesAntimeridian reports whether the box wraps from 180 to -180
+func (b BoundingBox) CrossesAntimeridian() bool {
+	return b.MinLongitude > b.MaxLongitude
+}
+
+// Contains reports whether the point lies inside the box or on its edge
+func (b BoundingBox) Contains(latitude, longitude float64) bool {
+	if latitude < b.MinLatitude || latitude > b.MaxLatitude {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return longitude >= b.MinLongitude || longitude <= b.MaxLongitude
+	}
+	return longitude >= b.MinLongitude && longitude <= b.MaxLongitude
+}
+
+// Validate rejects coordinates out of range and latitudes out of order
+func (b BoundingBox) Validate() error {
+	for _, lat := range []float64{b.MinLatitude, b.MaxLatitude} {
+		if lat < -90 || lat > 90 {
+			return ErrInvalidBoundingBox
+		}
+	}
+	for _, lng := range []float64{b.MinLongitude, b.MaxLongitude} {
+		if lng < -180 || lng > 180 {
+			return ErrInvalidBoundingBox
+		}
+	}
+	if b.MinLatitude > b.MaxLatitude {
+		return ErrInvalidBoundingBox
+	}
+	return nil
+}
+
+// IsZero reports whether the filter matches every location
+func (f LocationFilter) IsZero() bool {
+	return f.CreatedAfter.IsZero() && f.CreatedBefore.IsZero() && f.NameContains == "" && f.BBox == nil && f.Region == "" && f.AfterID == ""
+}
+
+// Validate rejects bounds that are out of order and invalid bounding boxes
+func (f LocationFilter) Validate() error {
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedAfter.Before(f.CreatedBefore) {
+		return ErrInvalidCreatedRange
+	}
+	if f.BBox != nil {
+		return f.BBox.Validate()
+	}
+	return nil
+}
+
+// SortField names the attribute a listing is ordered by
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByName      SortField = "name"
+	SortByCreatedAt SortField = "created_at"
+)
+
+// LocationSort orders a listing. The zero value orders by ascending id,
+// and ties on other fields are broken by id.
+type LocationSort struct {
+	Field      SortField
+	Descending bool
+}
+
+// Page selects a window of a listing. A Limit of 0 returns every location
+// after Offset.
+type Page struct {
+	Offset int
+	Limit  int
+}
+
+// PagedFinder is implemented by repositories that can cut a page of a
+// listing themselves, so a listing reads only the page and a count rather
+// than every match
+type PagedFinder interface {
+	// PagesSort reports whether Find cuts pages in this order exactly as
+	// from the whole sorted listing. It is false for an order the store
+	// cannot index and Find sorts after reading.
+	PagesSort(order LocationSort) bool
+	// CountMatching counts the locations passing filter
+	CountMatching(filter LocationFilter) (int, error)
+}
@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidOpeningHours wraps every opening hours validation failure
+	ErrInvalidOpeningHours = errors.New("invalid opening hours")
+	// ErrNoOpenLocation is returned by an open filter that every candidate
+	// location fails
+	ErrNoOpenLocation = errors.New("no open location")
+	// ErrOpenFilterConflict is returned by an open filter given both a time
+	// and now
+	ErrOpenFilterConflict = errors.New("open_at and open_now cannot be combined")
+)
+
+// DefaultOpenCandidates is how many of the nearest stations an open
+// nearest search checks before giving up
+const DefaultOpenCandidates = 25
+
+// NoOpenWithinError is returned by an open nearest search whose nearest
+// Candidates stations are all closed. Stations further away are not
+// checked, so one of them may be open. It matches ErrNoOpenLocation.
+type NoOpenWithinError struct {
+	Candidates int
+}
+
+func (e *NoOpenWithinError) Error() string {
+	return fmt.Sprintf("no open location among the nearest %d", e.Candidates)
+}
+
+func (e *NoOpenWithinError) Unwrap() error {
+	return ErrNoOpenLocation
+}
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// weekdays maps the day names opening hours are keyed by to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// OpeningHours is a station's weekly schedule in its own timezone. Days are
+// keyed by lower-case English weekday name; a day that is missing or empty
+// is closed. An interval whose close is not after its open runs past
+// midnight into the next day, and a close of 24:00 ends at midnight, so a
+// 24/7 station lists 00:00-24:00 on every day.
+type OpeningHours struct {
+	Timezone string                       `json:"timezone" example:"Africa/Lagos" doc:"IANA timezone the intervals are in"`
+	Days     map[string][]OpeningInterval `json:"days,omitempty" doc:"Intervals per lower-case weekday name, e.g. monday; a missing day is closed"`
+}
+
+// OpeningInterval is one opening on a day, as HH:MM local times
+type OpeningInterval struct {
+	Open  string `json:"open" pattern:"^([01][0-9]|2[0-3]):[0-5][0-9]$" example:"06:00" doc:"Opening time, HH:MM"`
+	Close string `json:"close" pattern:"^(([01][0-9]|2[0-3]):[0-5][0-9]|24:00)$" example:"22:00" doc:"Closing time, HH:MM; 24:00 is midnight, and a time not after open runs past midnight"`
+}
+
+// span is an interval as minutes since Sunday 00:00, end exclusive
+type span struct {
+	start, end int
+}
+
+// Validate checks the timezone, the day names and times, and that no two
+// intervals overlap, including intervals running past midnight
+func (h *OpeningHours) Validate() error {
+	if _, err := loadTimezone(h.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidOpeningHours, h.Timezone)
+	}
+	spans, err := h.spans()
+	if err != nil {
+		return err
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start < spans[i-1].end {
+			return fmt.Errorf("%w: intervals overlap", ErrInvalidOpeningHours)
+		}
+	}
+	return nil
+}
+
+// IsOpenAt reports whether the station is open at t, read as wall-clock
+// time in the station's timezone. Hours that do not validate are closed.
+func (h *OpeningHours) IsOpenAt(t time.Time) bool {
+	tz, err := loadTimezone(h.Timezone)
+	if err != nil {
+		return false
+	}
+	spans, err := h.spans()
+	if err != nil {
+		return false
+	}
+	local := t.In(tz)
+	minute := int(local.Weekday())*minutesPerDay + local.Hour()*60 + local.Minute()
+	for _, s := range spans {
+		if minute >= s.start && minute < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// spans converts the intervals to minutes of the week, splitting the one
+// that runs from Saturday night into Sunday at the end of the week
+func (h *OpeningHours) spans() ([]span, error) {
+	var spans []span
+	for day, intervals := range h.Days {
+		weekday, ok := weekdays[day]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown day %q", ErrInvalidOpeningHours, day)
+		}
+		for _, interval := range intervals {
+			opens, err := parseClock(interval.Open, false)
+			if err != nil {
+				return nil, err
+			}
+			closes, err := parseClock(interval.Close, true)
+			if err != nil {
+				return nil, err
+			}
+			if closes <= opens {
+				closes += minutesPerDay
+			}
+			start := int(weekday)*minutesPerDay + opens
+			end := int(weekday)*minutesPerDay + closes
+			if end > minutesPerWeek {
+				spans = append(spans, span{start, minutesPerWeek}, span{0, end - minutesPerWeek})
+			} else {
+				spans = append(spans, span{start, end})
+			}
+		}
+	}
+	return spans, nil
+}
+
+// parseClock reads HH:MM as minutes after midnight; 24:00 is accepted as
+// a closing time only
+func parseClock(s string, closing bool) (int, error) {
+	if len(s) != 5 || s[2] != ':' || !isDigits(s[:2]) || !isDigits(s[3:]) {
+		return 0, fmt.Errorf("%w: time %q is not HH:MM", ErrInvalidOpeningHours, s)
+	}
+	hour := int(s[0]-'0')*10 + int(s[1]-'0')
+	minute := int(s[3]-'0')*10 + int(s[4]-'0')
+	if closing && hour == 24 && minute == 0 {
+		return minutesPerDay, nil
+	}
+	if hour > 23 || minute > 59 {
+		return 0, fmt.Errorf("%w: time %q is out of range", ErrInvalidOpeningHours, s)
+	}
+	return hour*60 + minute, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// timezones caches loaded locations, as loading reads the zone database
+var timezones sync.Map
+
+func loadTimezone(name string) (*time.Location, error) {
+	// An empty name is UTC and Local is the server's zone; neither names
+	// where a station is
+	if name == "" || name == "Local" {
+		return nil, errors.New("not an IANA timezone")
+	}
+	if tz, ok := timezones.Load(name); ok {
+		return tz.(*time.Location), nil
+	}
+	tz, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	timezones.Store(name, tz)
+	return tz, nil
+}
+
+// OpenAt selects locations open at a time. The zero value selects every
+// location; with Now set the service's clock supplies the time.
+type OpenAt struct {
+	Time time.Time
+	Now  bool
+}
+
+// IsZero reports whether the filter selects every location
+func (o OpenAt) IsZero() bool {
+	return o.Time.IsZero() && !o.Now
+}
+
+// Validate rejects a filter asking about a time and now at once
+func (o OpenAt) Validate() error {
+	if o.Now && !o.Time.IsZero() {
+		return ErrOpenFilterConflict
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrImportNotFound is returned for an import job ID that is not stored
+	ErrImportNotFound = errors.New("import not found")
+	// ErrInvalidImportFile is returned for a file that cannot be imported
+	// at all, such as one without the required columns
+	ErrInvalidImportFile = errors.New("invalid import file")
+)
+
+// ImportStatus is where an import job is in its life
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+	ImportCancelled ImportStatus = "cancelled"
+)
+
+// Finished reports whether a job in this status will not change again
+func (s ImportStatus) Finished() bool {
+	return s == ImportCompleted || s == ImportFailed || s == ImportCancelled
+}
+
+// ImportRowError reports a CSV row that was not imported
+type ImportRowError struct {
+	// Row is the row's line in the file, the header being line 1
+	Row   int    `json:"row"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportJob is a CSV import and its progress. Imported and Failed add up
+// to Processed; Errors lists the failed rows, up to a cap.
+type ImportJob struct {
+	ID        string
+	Status    ImportStatus
+	Total     int
+	Processed int
+	Imported  int
+	Failed    int
+	Errors    []ImportRowError
+	// Error says why a failed job stopped
+	Error string
+	// CancelRequested is set when a running job is asked to stop; the
+	// worker running it stops after its current batch
+	CancelRequested bool
+	CreatedAt       time.Time
+	// UpdatedAt is when the job last changed; a running job's worker
+	// updates it after every batch
+	UpdatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// JobRepository stores import jobs with their uploads, so any instance can
+// report on or cancel a job and no job is lost when an instance stops
+type JobRepository interface {
+	// CreateImport stores a pending job and the file it imports
+	CreateImport(job *ImportJob, data []byte) error
+	FindImport(id string) (*ImportJob, error)
+	// ClaimImport marks the oldest pending job running and returns it with
+	// its file, or nil when none is pending. Each job is claimed once.
+	ClaimImport(now time.Time) (*ImportJob, []byte, error)
+	// UpdateImport records a job's status, progress and errors. It sets
+	// job.CancelRequested from the store, where CancelImport sets it. A
+	// finished job's file is dropped.
+	UpdateImport(job *ImportJob) error
+	// CancelImport cancels a pending job at once and asks a running one to
+	// stop. A finished job is returned unchanged.
+	CancelImport(id string, now time.Time) (*ImportJob, error)
+	// FailStaleImports marks failed every running job last updated before
+	// before, as its worker has stopped, and returns how many it marked
+	FailStaleImports(before time.Time, reason string) (int, error)
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrIntegrityCheckRunning is returned when an integrity scan is started
+// while another is running
+var ErrIntegrityCheckRunning = errors.New("integrity check already running")
+
+// Integrity problems reported by an integrity scan
+const (
+	// IntegrityInvalidCoordinates marks coordinates the domain rules reject
+	IntegrityInvalidCoordinates = "invalid_coordinates"
+	// IntegrityNullIsland marks 0,0, which a failed geocode or a missing
+	// column usually produces
+	IntegrityNullIsland = "null_island"
+	// IntegrityBlankName marks a name that is empty once trimmed
+	IntegrityBlankName = "blank_name"
+	// IntegrityUntrimmedName marks a name with leading or trailing whitespace
+	IntegrityUntrimmedName = "untrimmed_name"
+	// IntegrityMissingCreatedAt marks a location stored without a creation
+	// time, as legacy imports did; it is read with a zero time
+	IntegrityMissingCreatedAt = "missing_created_at"
+)
+
+// IntegrityFixTrim trims untrimmed names. It is the only fix, as it cannot
+// lose information; a trimmed name already taken is left for an operator.
+const IntegrityFixTrim = "trim"
+
+// Integrity scan states
+const (
+	IntegrityIdle     = "idle"
+	IntegrityRunning  = "running"
+	IntegrityComplete = "complete"
+	IntegrityFailed   = "failed"
+)
+
+// IntegrityProblems checks a stored location against the current domain
+// rules, which it may predate
+func IntegrityProblems(l *Location) []string {
+	var problems []string
+	trimmed := strings.TrimSpace(l.Name)
+	switch {
+	case trimmed == "":
+		problems = append(problems, IntegrityBlankName)
+	case trimmed != l.Name:
+		problems = append(problems, IntegrityUntrimmedName)
+	}
+	if math.IsNaN(l.Latitude) || math.IsNaN(l.Longitude) ||
+		l.Latitude < -90 || l.Latitude > 90 || l.Longitude < -180 || l.Longitude > 180 {
+		problems = append(problems, IntegrityInvalidCoordinates)
+	} else if l.Latitude == 0 && l.Longitude == 0 {
+		problems = append(problems, IntegrityNullIsland)
+	}
+	if l.CreatedAt.IsZero() {
+		problems = append(problems, IntegrityMissingCreatedAt)
+	}
+	return problems
+}
+
+// IntegrityIssue is one problem found on a stored location
+type IntegrityIssue struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Problem string `json:"problem"`
+	Fixed   bool   `json:"fixed"`
+}
+
+// IntegrityReport describes the latest integrity scan
+type IntegrityReport struct {
+	Status     string     `json:"status" enum:"idle,running,complete,failed"`
+	Fix        string     `json:"fix,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Scanned    int        `json:"scanned"`
+	// Counts holds the number of locations with each problem
+	Counts map[string]int   `json:"counts"`
+	Issues []IntegrityIssue `json:"issues"`
+	// Truncated is set when there were more issues than the report keeps
+	Truncated bool   `json:"truncated"`
+	Error     string `json:"error,omitempty"`
+}
+
+// MapLocations returns a copy of the report with fn applied to the ID of
+// each issue, so responses show the IDs as they show those of locations
+func (r IntegrityReport) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	r.Issues = slices.Clone(r.Issues)
+	for i := range r.Issues {
+		fn(&r.Issues[i].ID, nil, nil)
+	}
+	return r
+}
+
+// LocationStreamer yields every stored location with its aliases in id
+// order, reading
+// batchSize at a time so a scan never holds the whole store in memory or
+// locks it for long
+type LocationStreamer interface {
+	StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*Location, error]
+}
+
+// LocationRenamer changes a location's name in place, keeping its ID and
+// coordinates. It returns ErrLocationExists when the name is taken.
+type LocationRenamer interface {
+	RenameLocation(id, name string) error
+}
+
+// IntegrityStore is what an integrity scan reads and repairs
+type IntegrityStore interface {
+	LocationStreamer
+	LocationRenamer
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidListOptions wraps every ListOptions validation failure
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// ListOptions selects one page of a location listing
+type ListOptions struct {
+	Filter LocationFilter
+	// Open passes only stations open at the time it selects
+	Open OpenAt
+	Sort LocationSort
+	// Offset skips that many matching locations and Limit caps the page;
+	// a Limit of 0 returns every location after Offset. For cursor
+	// pagination set Filter.AfterID instead of Offset.
+	Offset int
+	Limit  int
+	// Fresh refuses an answer from a ListFallback, as a conditional
+	// request must be answered from the repository or not at all
+	Fresh bool
+}
+
+// Validate checks the filter, the sort field and the window. A cursor
+// (Filter.AfterID) must be a number and only resumes the default order,
+// so it cannot be combined with Offset or another sort.
+func (o ListOptions) Validate() error {
+	if err := o.Filter.Validate(); err != nil {
+		return err
+	}
+	if err := o.Open.Validate(); err != nil {
+		return err
+	}
+	switch o.Sort.Field {
+	case "", SortByID, SortByName, SortByCreatedAt:
+	default:
+		return fmt.Errorf("%w: unknown sort field %q", ErrInvalidListOptions, o.Sort.Field)
+	}
+	if o.Offset < 0 || o.Limit < 0 {
+		return fmt.Errorf("%w: offset and limit cannot be negative", ErrInvalidListOptions)
+	}
+	if o.Filter.AfterID != "" {
+		if n, err := strconv.Atoi(o.Filter.AfterID); err != nil || n < 0 {
+			return fmt.Errorf("%w: after id %q is not a number", ErrInvalidListOptions, o.Filter.AfterID)
+		}
+		if o.Offset > 0 {
+			return fmt.Errorf("%w: a cursor cannot be combined with an offset", ErrInvalidListOptions)
+		}
+		if o.Sort != (LocationSort{}) && o.Sort != (LocationSort{Field: SortByID}) {
+			return fmt.Errorf("%w: a cursor only resumes the ascending id order", ErrInvalidListOptions)
+		}
+	}
+	return nil
+}
+
+// ListPage is one page of a listing
+type ListPage[T any] struct {
+	Items []T
+	// Total counts the items passing the filter across every page
+	Total int
+	// More is set when items remain after this page
+	More bool
+	// Stale is set when the repository failed and the page was cut from
+	// the last listing it returned, at AsOf
+	Stale bool
+	AsOf  time.Time
+}
+
+// ListFallback keeps the last page each listing query returned, so a
+// listing can still be answered, marked stale, when the repository fails
+type ListFallback interface {
+	RememberList(key string, page ListPage[*Location], at time.Time)
+	// RecallList returns the page remembered under key and when
+	RecallList(key string) (ListPage[*Location], time.Time, bool)
+}
+
+// NearestQuery describes a nearest search
+type NearestQuery struct {
+	Latitude  float64
+	Longitude float64
+	Filter    NearestFilter
+	// Exclude lists names of locations to pass over
+	Exclude []string
+}
+
+// Validate rejects coordinates out of range and a conflicting open filter
+func (q NearestQuery) Validate() error {
+	if err := Invalid(LatitudeError(q.Latitude), LongitudeError(q.Longitude)); err != nil {
+		return err
+	}
+	return q.Filter.Open.Validate()
+}
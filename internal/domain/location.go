@@ -1,20 +1,41 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 	"github.com/jesuloba-world/leeta-task/pkg/validator"
 )
 
 type Location struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name" validate:"required,min=1"`
-	Latitude  float64   `json:"latitude" validate:"required,min=-90,max=90"`
-	Longitude float64   `json:"longitude" validate:"required,min=-180,max=180"`
+	Latitude  float64   `json:"latitude" validate:"min=-90,max=90"`
+	Longitude float64   `json:"longitude" validate:"min=-180,max=180"`
 	CreatedAt time.Time `json:"created_at"`
+	// OpeningHours is nil for a station whose hours are not known
+	OpeningHours *OpeningHours `json:"opening_hours,omitempty"`
+	// Description holds free-text notes for operators and drivers
+	Description string `json:"description,omitempty"`
+	// Aliases are alternate names the location is also found by, sorted
+	Aliases []string `json:"aliases,omitempty"`
+	// Region is the operating region the location belongs to, empty when
+	// it has none
+	Region string `json:"region,omitempty"`
+	// Attachments reference photos and documents of the station stored
+	// elsewhere
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// CapacityLitres and CurrentStockLitres describe the station's fuel,
+	// nil when not known
+	CapacityLitres     *float64 `json:"capacity_litres,omitempty"`
+	CurrentStockLitres *float64 `json:"current_stock_litres,omitempty"`
+	// CreatedBy identifies the principal that created the location, empty
+	// when it was created anonymously
+	CreatedBy string `json:"created_by,omitempty"`
 }
 
 var (
@@ -40,8 +61,23 @@ func NewLocation(name string, latitude, longitude float64) (*Location, error) {
 	return location, nil
 }
 
+// Validate checks the location's fields, reporting every invalid name and
+// coordinate together as a *ValidationError, then its aliases, stock and
+// hours
 func (l *Location) Validate() error {
-	return validator.ValidateStruct(l)
+	if err := validator.ValidateStruct(l); err != nil {
+		return structErrors(err)
+	}
+	if err := l.validateAliases(); err != nil {
+		return err
+	}
+	if err := checkStock(l.CapacityLitres, l.CurrentStockLitres); err != nil {
+		return err
+	}
+	if l.OpeningHours != nil {
+		return l.OpeningHours.Validate()
+	}
+	return nil
 }
 
 func (l *Location) String() string {
@@ -53,19 +89,93 @@ func formatCoordinate(coord float64) string {
 }
 
 type LocationRepository interface {
+	// Save stores a new location without aliases; add them with
+	// LocationAliaser
 	Save(location *Location) error
+	// FindByName finds a location by its name or any of its aliases
 	FindByName(name string) (*Location, error)
 	FindByID(id string) (*Location, error)
 	FindAll() ([]*Location, error)
-	Delete(name string) error
-	FindNearest(latitude, longitude float64) (*Location, float64, error)
+	// Find lists the window page of the locations passing filter, ordered
+	// by sort
+	Find(filter LocationFilter, page Page, sort LocationSort) ([]*Location, error)
+	// Delete deletes a location by its name or any of its aliases, once it
+	// passes checks
+	Delete(name string, checks ...Precondition) error
+	// FindNearest skips locations whose names are listed in exclude
+	FindNearest(latitude, longitude float64, exclude ...string) (*Location, geospatial.Distance, error)
+	Count() (int, error)
+}
+
+// Precondition is checked against a location as stored, in the same step
+// as the change it guards, so nothing can change the location in between.
+// An error stops the change and is returned as it is.
+type Precondition func(current *Location) error
+
+// CheckPreconditions runs checks against location in turn and returns the
+// first error
+func CheckPreconditions(location *Location, checks ...Precondition) error {
+	for _, check := range checks {
+		if err := check(location); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type LocationService interface {
-	CreateLocation(name string, latitude, longitude float64) (*Location, error)
+	CreateLocation(name string, latitude, longitude float64, opts ...CreateOption) (*Location, error)
 	GetLocation(name string) (*Location, error)
 	GetLocationByID(id string) (*Location, error)
 	GetAllLocations() ([]*Location, error)
-	DeleteLocation(name string) error
-	FindNearest(latitude, longitude float64) (*Location, float64, error)
+	ListLocations(ctx context.Context, opts ListOptions) (ListPage[*Location], error)
+	// DeleteLocation, AddAlias, RemoveAlias and UpdateStock make their
+	// change only if the location passes checks
+	DeleteLocation(name string, checks ...Precondition) error
+	FindNearest(ctx context.Context, query NearestQuery) (*NearestResult, error)
+	// FindNearestMatching is FindNearest with positional arguments.
+	//
+	// Deprecated: use FindNearest.
+	FindNearestMatching(latitude, longitude float64, filter NearestFilter, exclude ...string) (*NearestResult, error)
+	LocationsAt(latitude, longitude, toleranceM float64) ([]*Location, error)
+	// AddAlias and RemoveAlias change the aliases of the location found by
+	// name; privileged callers may add aliases with reserved prefixes
+	AddAlias(name, alias string, privileged bool, checks ...Precondition) (*Location, error)
+	RemoveAlias(name, alias string, checks ...Precondition) (*Location, error)
+	// UpdateStock changes a location's capacity and stock alone; see
+	// StockUpdater
+	UpdateStock(name string, update StockUpdate, checks ...Precondition) (*Location, error)
+	// ApplyOperations applies creates, updates and deletes all or none;
+	// see LocationTransactor
+	ApplyOperations(ops []LocationOperation, privileged bool) ([]OperationResult, error)
+	// SuggestLocations ranks locations by how well their names match a
+	// partial name and how near they are; see LocationSuggester
+	SuggestLocations(ctx context.Context, query SuggestQuery) ([]Suggestion, error)
+}
+
+// NearestResult is the answer to a nearest search
+type NearestResult struct {
+	Location *Location
+	Distance geospatial.Distance
+	// Stale is set when the answer came from a fallback snapshot taken at
+	// AsOf, because the repository failed or was too slow
+	Stale bool
+	AsOf  time.Time
+	// Approximate is set when the repository searched only near the query
+	// point to stay within its scan budget
+	Approximate bool
+}
+
+// RadiusFinder is implemented by repositories that can return every
+// location near a point in one search
+type RadiusFinder interface {
+	// FindWithinRadius returns up to limit locations at most radius from
+	// the point, nearest first, breaking ties as FindNearest does
+	FindWithinRadius(latitude, longitude float64, radius geospatial.Distance, limit int) ([]NearestResult, error)
+}
+
+// NearestFallback answers nearest searches from a possibly stale copy of
+// the locations, returning the time the copy was taken
+type NearestFallback interface {
+	FindNearest(latitude, longitude float64, exclude ...string) (*Location, geospatial.Distance, time.Time, error)
 }
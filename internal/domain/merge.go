@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// ErrInvalidMerge is returned when a merge names the winner among the
+// losers or has no losers
+var ErrInvalidMerge = errors.New("invalid merge")
+
+// DuplicatePair is two locations suspected of being the same station
+type DuplicatePair struct {
+	A              string              `json:"a"`
+	B              string              `json:"b"`
+	Distance       geospatial.Distance `json:"distance_km"`
+	NameSimilarity float64             `json:"name_similarity"`
+}
+
+// DuplicateCluster groups locations linked by suspected duplicate pairs
+type DuplicateCluster struct {
+	Locations []*Location
+	Pairs     []DuplicatePair
+}
+
+// MergeAudit records a merge of duplicate locations into a winner
+type MergeAudit struct {
+	ID       string     `json:"id"`
+	Winner   string     `json:"winner"`
+	Losers   []Location `json:"losers"`
+	Actor    string     `json:"actor,omitempty"`
+	MergedAt time.Time  `json:"merged_at"`
+}
+
+// LocationMerger merges duplicate locations. The losers are deleted and an
+// audit entry is written atomically: if the winner or any loser does not
+// exist nothing changes and ErrLocationNotFound is returned.
+type LocationMerger interface {
+	MergeLocations(winner string, losers []string, actor string) (*MergeAudit, error)
+}
+
+// ValidateMerge checks the names of a merge before any store is touched
+func ValidateMerge(winner string, losers []string) error {
+	if winner == "" || len(losers) == 0 {
+		return ErrInvalidMerge
+	}
+	seen := map[string]bool{winner: true}
+	for _, loser := range losers {
+		if seen[loser] {
+			return ErrInvalidMerge
+		}
+		seen[loser] = true
+	}
+	return nil
+}
+
+// MergeNotFoundError names the location a merge could not find
+type MergeNotFoundError struct {
+	Name string
+}
+
+func (e *MergeNotFoundError) Error() string {
+	return "location not found: " + e.Name
+}
+
+func (e *MergeNotFoundError) Unwrap() error {
+	return ErrLocationNotFound
+}
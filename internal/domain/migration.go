@@ -0,0 +1,55 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrMigrationConflicts is returned when source locations collide with
+	// the destination's and conflicts are not allowed
+	ErrMigrationConflicts = errors.New("source locations conflict with the destination")
+	// ErrMigrationVerification is returned when the destination does not
+	// hold what was migrated
+	ErrMigrationVerification = errors.New("migrated locations failed verification")
+)
+
+// MigrationOptions controls a storage migration
+type MigrationOptions struct {
+	// DryRun plans the migration and reports conflicts without writing
+	DryRun bool
+	// ContinueOnConflict skips locations whose ID or name the destination
+	// already holds; otherwise any conflict stops the migration
+	ContinueOnConflict bool
+	// PreserveIDs keeps the source IDs. Otherwise locations are numbered
+	// after the destination's largest ID, in source order.
+	PreserveIDs bool
+	// BatchSize is the number of locations read and restored at a time;
+	// 0 means 500
+	BatchSize int
+	// SpotChecks is the number of migrated locations read back and searched
+	// for afterwards
+	SpotChecks int
+}
+
+// MigrationResult reports a storage migration
+type MigrationResult struct {
+	DryRun bool `json:"dry_run"`
+	// Source counts the locations read from the source
+	Source int `json:"source"`
+	// Planned counts the locations without conflicts, which a dry run
+	// would have migrated
+	Planned   int               `json:"planned"`
+	Migrated  int               `json:"migrated"`
+	Conflicts []RestoreConflict `json:"conflicts"`
+	// SpotChecked counts the migrated locations read back and searched for
+	SpotChecked int `json:"spot_checked"`
+	// Mismatches describes every verification failure
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+// MigrationTarget is a store a migration reads or writes: it is streamed to
+// collect the IDs and names already taken, restored into in batches and
+// read back to verify the copy
+type MigrationTarget interface {
+	LocationRepository
+	LocationStreamer
+	LocationRestorer
+}
@@ -0,0 +1,84 @@
+package domain
+
+import "errors"
+
+// ErrNameNotAllowed is returned when a location name is blocklisted, or
+// uses a reserved prefix without the privilege to
+var ErrNameNotAllowed = errors.New("name not allowed")
+
+// routeNames are the fixed path segments under /locations/. A location or
+// alias named after one could not be read at /locations/{name}, which the
+// fixed route answers instead.
+var routeNames = map[string]bool{
+	"at": true, "changes": true, "duplicates": true, "import": true,
+	"merge": true, "suggest": true, "transaction": true,
+}
+
+// IsRouteName reports whether name is a fixed path segment under
+// /locations/, which no location or alias may take
+func IsRouteName(name string) bool {
+	return routeNames[name]
+}
+
+// CreateOptions carries per-call settings for LocationService.CreateLocation
+type CreateOptions struct {
+	// Privileged callers may use reserved name prefixes
+	Privileged bool
+	// OpeningHours are stored with the location when set
+	OpeningHours *OpeningHours
+	// Description is cleaned and length checked before it is stored
+	Description string
+	// Region is checked against the configured regions before it is stored
+	Region string
+	// Attachments are checked against the attachment policy before they
+	// are stored
+	Attachments []Attachment
+	// CreatedBy is stored as the location's owner
+	CreatedBy string
+}
+
+// CreateOption sets a CreateOptions field
+type CreateOption func(*CreateOptions)
+
+// Privileged marks the caller as allowed to use reserved name prefixes
+func Privileged(privileged bool) CreateOption {
+	return func(o *CreateOptions) {
+		o.Privileged = privileged
+	}
+}
+
+// WithOpeningHours stores the station's opening hours with it
+func WithOpeningHours(hours *OpeningHours) CreateOption {
+	return func(o *CreateOptions) {
+		o.OpeningHours = hours
+	}
+}
+
+// WithDescription stores free-text notes with the location
+func WithDescription(description string) CreateOption {
+	return func(o *CreateOptions) {
+		o.Description = description
+	}
+}
+
+// WithRegion places the location in an operating region
+func WithRegion(region string) CreateOption {
+	return func(o *CreateOptions) {
+		o.Region = region
+	}
+}
+
+// WithAttachments stores references to photos and documents with the
+// location
+func WithAttachments(attachments []Attachment) CreateOption {
+	return func(o *CreateOptions) {
+		o.Attachments = attachments
+	}
+}
+
+// WithCreatedBy records the principal creating the location as its owner
+func WithCreatedBy(principal string) CreateOption {
+	return func(o *CreateOptions) {
+		o.CreatedBy = principal
+	}
+}
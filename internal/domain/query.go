@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrQueryNotFound is returned for a saved query name that is not stored
+	ErrQueryNotFound = errors.New("saved query not found")
+	// ErrQueryExists is returned when creating a saved query whose name is taken
+	ErrQueryExists = errors.New("saved query already exists")
+)
+
+// SavedQuery is a named location filter kept so dashboards can run the same
+// listing again without repeating its parameters
+type SavedQuery struct {
+	Name string
+	// Filter is the filter document as the API accepted it. It is decoded
+	// again on every run, so a document the current filter schema no
+	// longer accepts fails then rather than silently matching more.
+	Filter    json.RawMessage
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// QueryRepository stores saved queries by name
+type QueryRepository interface {
+	// CreateQuery stores a new query, or returns ErrQueryExists
+	CreateQuery(query *SavedQuery) error
+	// UpdateQuery replaces the filter of a stored query, keeping its
+	// CreatedAt, or returns ErrQueryNotFound
+	UpdateQuery(query *SavedQuery) error
+	FindQuery(name string) (*SavedQuery, error)
+	// ListQueries returns every stored query ordered by name
+	ListQueries() ([]*SavedQuery, error)
+	DeleteQuery(name string) error
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Pinger is implemented by stores the service cannot serve without, such as
+// the *sql.DB behind PostgreSQL storage
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// ReadinessCheck is the result of one dependency check
+type ReadinessCheck struct {
+	At       time.Time
+	Duration time.Duration
+	// Error is empty when the check passed
+	Error string
+}
+
+// ReadinessTransition records when readiness changed
+type ReadinessTransition struct {
+	At    time.Time
+	Ready bool
+}
+
+// ReadinessStatus describes readiness and the checks that led to it
+type ReadinessStatus struct {
+	Ready bool
+	// ConsecutiveFailures and ConsecutiveSuccesses count the latest run
+	// of checks; one of them is always 0
+	ConsecutiveFailures  int
+	ConsecutiveSuccesses int
+	// History holds the most recent checks, oldest first
+	History []ReadinessCheck
+	// Transitions holds the most recent changes of Ready, oldest first
+	Transitions []ReadinessTransition
+}
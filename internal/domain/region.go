@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+var (
+	// ErrInvalidRegion is returned for a region outside the configured list
+	ErrInvalidRegion = errors.New("invalid region")
+	// ErrRegionRequired is returned when regions are mandatory and a create
+	// or nearest search names none
+	ErrRegionRequired = errors.New("region required")
+)
+
+// NearestFilter narrows a nearest search. The zero value searches every
+// location.
+type NearestFilter struct {
+	// Open passes over stations closed at the time it selects
+	Open OpenAt
+	// Region searches only the locations in this region
+	Region string
+	// MinStockLitres passes over stations known to hold less, or whose
+	// stock is not known; 0 applies no minimum
+	MinStockLitres float64
+}
+
+// RegionalNearestFinder is implemented by repositories that can search one
+// region without scanning the others
+type RegionalNearestFinder interface {
+	// FindNearestInRegion is FindNearest over the locations in region
+	FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*Location, geospatial.Distance, error)
+}
+
+// RegionalNearestFallback is implemented by nearest fallbacks that can
+// answer region-scoped searches
+type RegionalNearestFallback interface {
+	FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*Location, geospatial.Distance, time.Time, error)
+}
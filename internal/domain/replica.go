@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ReplicaStatus describes where a repository sends its reads
+type ReplicaStatus struct {
+	// Configured is set when the repository has a read replica
+	Configured bool
+	// Usable is set while reads go to the replica
+	Usable bool
+	// Lag is how far the replica was behind at the last check
+	Lag time.Duration
+}
+
+// ReplicaChecker is implemented by repositories that send reads to a
+// replica while it keeps up with the primary. CheckReplica measures the
+// replica and routes reads accordingly; it returns an error when the
+// replica could not be checked, and reads then go to the primary.
+type ReplicaChecker interface {
+	CheckReplica(ctx context.Context) (ReplicaStatus, error)
+}
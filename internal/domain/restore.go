@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"slices"
+	"strconv"
+)
+
+// Reasons a snapshot record is not restored
+const (
+	RestoreIDExists   = "id_exists"
+	RestoreNameExists = "name_exists"
+	RestoreInvalid    = "invalid"
+)
+
+// RestoreConflict reports a snapshot record that was skipped
+type RestoreConflict struct {
+	// Index is the record's position in the snapshot
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// RestoreResult summarizes a restore
+type RestoreResult struct {
+	Restored  int               `json:"restored"`
+	Conflicts []RestoreConflict `json:"conflicts"`
+}
+
+// LocationRestorer restores locations from a snapshot, keeping their IDs and
+// creation times so references held elsewhere stay valid. A restore merges
+// into the existing data: records whose ID or name is already taken are
+// skipped and reported, and the rest are inserted. Later creates are
+// assigned IDs above every restored ID.
+//
+// This is the only path that stores a caller-chosen ID; Save always assigns one.
+type LocationRestorer interface {
+	RestoreLocations(locations []Location) (*RestoreResult, error)
+}
+
+// PlanRestore splits snapshot records into those to insert and those to
+// report. idTaken and nameTaken say whether the store already holds an ID or
+// a name, either as a location's name or as an alias; records repeating an
+// earlier record's ID, name or alias also conflict.
+// Restored IDs must be positive integers so every backend can keep them.
+func PlanRestore(locations []Location, idTaken, nameTaken func(string) bool) ([]Location, []RestoreConflict) {
+	var accepted []Location
+	conflicts := []RestoreConflict{}
+	ids := map[string]bool{}
+	names := map[string]bool{}
+
+	for i, location := range locations {
+		allNames := append([]string{location.Name}, location.Aliases...)
+		reason := ""
+		switch {
+		case !validRestoreID(location.ID) || location.Validate() != nil:
+			reason = RestoreInvalid
+		case ids[location.ID] || idTaken(location.ID):
+			reason = RestoreIDExists
+		case slices.ContainsFunc(allNames, func(name string) bool { return names[name] || nameTaken(name) }):
+			reason = RestoreNameExists
+		}
+		if reason != "" {
+			conflicts = append(conflicts, RestoreConflict{Index: i, ID: location.ID, Name: location.Name, Reason: reason})
+			continue
+		}
+		ids[location.ID] = true
+		for _, name := range allNames {
+			names[name] = true
+		}
+		accepted = append(accepted, location)
+	}
+	return accepted, conflicts
+}
+
+func validRestoreID(id string) bool {
+	n, err := strconv.ParseInt(id, 10, 32)
+	return err == nil && n > 0 && strconv.FormatInt(n, 10) == id
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrSettingsNotFound is returned when no settings have been stored
+	ErrSettingsNotFound = errors.New("settings not found")
+	// ErrInvalidSettings wraps every settings validation failure
+	ErrInvalidSettings = errors.New("invalid settings")
+)
+
+// SearchSettings are the defaults nearest searches use for parameters the
+// request leaves out. Operators change them at runtime; the configured
+// values apply until some are stored.
+type SearchSettings struct {
+	// SpeedKmh estimates eta_minutes; 0 leaves ETAs off
+	SpeedKmh float64
+	// MaxDistanceKm is the furthest a nearest location may be; 0 means no
+	// limit
+	MaxDistanceKm float64
+	UpdatedAt     time.Time
+}
+
+// Validate checks the values are finite and not negative
+func (s SearchSettings) Validate() error {
+	for _, field := range []struct {
+		name  string
+		value float64
+	}{{"speed_kmh", s.SpeedKmh}, {"max_distance_km", s.MaxDistanceKm}} {
+		if math.IsNaN(field.value) || math.IsInf(field.value, 0) || field.value < 0 {
+			return fmt.Errorf("%w: %s must be a non-negative number", ErrInvalidSettings, field.name)
+		}
+	}
+	return nil
+}
+
+// SettingsRepository stores the single set of search settings
+type SettingsRepository interface {
+	// FindSearchSettings returns the stored settings, or ErrSettingsNotFound
+	FindSearchSettings() (*SearchSettings, error)
+	// SaveSearchSettings replaces any stored settings
+	SaveSearchSettings(settings *SearchSettings) error
+}
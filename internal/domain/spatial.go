@@ -0,0 +1,31 @@
+package domain
+
+// Spatial consistency problems reported by VerifySpatial
+const (
+	SpatialGeomMissing        = "geom_missing"
+	SpatialGeomMismatch       = "geom_mismatch"
+	SpatialInvalidCoordinates = "invalid_coordinates"
+	SpatialIDIndexMissing     = "id_index_missing"
+	SpatialIDIndexStale       = "id_index_stale"
+)
+
+// SpatialIssue is one inconsistency found while verifying stored locations
+type SpatialIssue struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Problem  string `json:"problem"`
+	Repaired bool   `json:"repaired"`
+}
+
+// SpatialBatch reports the rows checked in one batch and the issues among them
+type SpatialBatch struct {
+	Scanned int            `json:"scanned"`
+	Issues  []SpatialIssue `json:"issues"`
+}
+
+// SpatialVerifier checks that stored coordinates and their derived indexes
+// agree. Work is done in batches of batchSize so locks stay short; onBatch is
+// called after each batch and may stop the scan by returning an error.
+type SpatialVerifier interface {
+	VerifySpatial(batchSize int, fix bool, onBatch func(SpatialBatch) error) error
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidStock wraps every stock validation failure
+var ErrInvalidStock = errors.New("invalid stock")
+
+// StockError reports why a capacity or stock was refused
+type StockError struct {
+	Reason string
+}
+
+func (e *StockError) Error() string {
+	return "invalid stock: " + e.Reason
+}
+
+func (e *StockError) Unwrap() error {
+	return ErrInvalidStock
+}
+
+// StockUpdate changes a station's fuel capacity and stock without touching
+// the rest of the location. Nil fields are left as they are. AdjustLitres
+// is added to the stock after StockLitres is applied, so deliveries and
+// sales reported at the same moment do not overwrite each other.
+type StockUpdate struct {
+	CapacityLitres *float64
+	StockLitres    *float64
+	AdjustLitres   float64
+}
+
+// Apply returns the capacity and stock after the update, refusing a
+// negative value, stock above the capacity and an adjustment to a stock
+// that is not known
+func (u StockUpdate) Apply(capacity, stock *float64) (*float64, *float64, error) {
+	if u.CapacityLitres != nil {
+		capacity = u.CapacityLitres
+	}
+	if u.StockLitres != nil {
+		stock = u.StockLitres
+	}
+	if u.AdjustLitres != 0 {
+		if stock == nil {
+			return nil, nil, &StockError{Reason: "the current stock is not known, so it cannot be adjusted"}
+		}
+		adjusted := *stock + u.AdjustLitres
+		stock = &adjusted
+	}
+	if err := checkStock(capacity, stock); err != nil {
+		return nil, nil, err
+	}
+	return capacity, stock, nil
+}
+
+// checkStock refuses negative values and stock above a known capacity
+func checkStock(capacity, stock *float64) error {
+	switch {
+	case capacity != nil && *capacity < 0:
+		return &StockError{Reason: "capacity_litres cannot be negative"}
+	case stock != nil && *stock < 0:
+		return &StockError{Reason: "current_stock_litres cannot be negative"}
+	case capacity != nil && stock != nil && *stock > *capacity:
+		return &StockError{Reason: fmt.Sprintf("current_stock_litres cannot exceed capacity_litres (%g)", *capacity)}
+	}
+	return nil
+}
+
+// HasStock reports whether the station is known to hold at least
+// minLitres; a minimum of 0 or less is met by every station
+func (l *Location) HasStock(minLitres float64) bool {
+	if minLitres <= 0 {
+		return true
+	}
+	return l.CurrentStockLitres != nil && *l.CurrentStockLitres >= minLitres
+}
+
+// StockUpdater is implemented by repositories that change a location's
+// stock on its own, checking the update against the stored values in the
+// same step so that concurrent updates are not lost. It accepts the
+// location's name or any of its aliases and returns the location as
+// updated, making the change only if the location passes checks.
+type StockUpdater interface {
+	UpdateStock(name string, update StockUpdate, checks ...Precondition) (*Location, error)
+}
+
+// StockedNearestFinder is implemented by repositories that can pass over
+// stations holding too little stock inside the search, rather than one
+// nearest location at a time
+type StockedNearestFinder interface {
+	// FindNearestStocked is FindNearestInRegion, or FindNearest when
+	// region is empty, over the locations holding at least minLitres. A
+	// store with a scan budget sets Approximate as FindNearestBudgeted does.
+	FindNearestStocked(region string, minLitres, latitude, longitude float64, exclude ...string) (*NearestResult, error)
+}
+
+// BudgetedStockedFinder is implemented by repositories that can hold a
+// stocked search to a scan budget given with the search. Stations holding
+// too little still count against the budget, so a search where few hold
+// enough cannot walk the whole store.
+type BudgetedStockedFinder interface {
+	// FindNearestStockedWithin is FindNearestStocked within budget, failing
+	// with ErrScanBudgetExceeded past budget.Hard
+	FindNearestStockedWithin(budget ScanBudget, region string, minLitres, latitude, longitude float64, exclude ...string) (*NearestResult, error)
+}
@@ -0,0 +1,99 @@
+package domain
+
+import "time"
+
+// StoreStats describes an in-process store for monitoring
+type StoreStats struct {
+	Locations int
+	// EstimatedBytes approximates the memory the locations and their
+	// primary indexes take
+	EstimatedBytes int64
+	// Indexes describes each bucketed index
+	Indexes []IndexStats
+	// Saves and Deletes time every write; ReadLockWaits and WriteLockWaits
+	// time a sample of lock acquisitions
+	Saves          OperationStats
+	Deletes        OperationStats
+	ReadLockWaits  OperationStats
+	WriteLockWaits OperationStats
+	// Removed counts locations removed or renamed since the indexes were
+	// last rebuilt, each leaving room behind in them
+	Removed int
+	// Compactions times every rebuild of the indexes, and Reclaimed sums
+	// the Removed each rebuild cleared
+	Compactions OperationStats
+	Reclaimed   uint64
+}
+
+// IndexStats describes the buckets of one index
+type IndexStats struct {
+	Name string
+	// Sizes holds the number of locations in each bucket
+	Sizes []int
+}
+
+// Largest returns the size of the fullest bucket
+func (s IndexStats) Largest() int {
+	largest := 0
+	for _, size := range s.Sizes {
+		largest = max(largest, size)
+	}
+	return largest
+}
+
+// OperationStats counts operations and their total duration
+type OperationStats struct {
+	Count uint64
+	Total time.Duration
+}
+
+// Mean returns the average duration, or 0 before any operation
+func (s OperationStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// StoreStatsReporter is implemented by stores that report StoreStats
+type StoreStatsReporter interface {
+	StoreStats() StoreStats
+}
+
+// CompactionResult reports one StoreCompactor.CompactStore call
+type CompactionResult struct {
+	// Compacted is false when too few entries had been removed to rebuild
+	Compacted bool
+	// Reclaimed is how many removed entries the rebuild cleared
+	Reclaimed int
+	Duration  time.Duration
+}
+
+// StoreCompactor is implemented by stores whose indexes keep the room
+// removed entries took, as Go maps do, so that they can be rebuilt at the
+// size of what they hold
+type StoreCompactor interface {
+	// CompactStore rebuilds the indexes if the locations removed since
+	// the last rebuild are at least minRemovedRatio of those plus the
+	// live locations
+	CompactStore(minRemovedRatio float64) (CompactionResult, error)
+}
+
+// CacheStats counts how a read-through cache answered lookups
+type CacheStats struct {
+	// FreshHits were answered from entries within their TTL, StaleHits from
+	// expired entries served while a refresh ran, and Misses by the
+	// repository behind the cache
+	FreshHits uint64
+	StaleHits uint64
+	Misses    uint64
+	// Refreshes counts background refreshes that reloaded their entry,
+	// RefreshErrors those that failed and left the stale entry in place
+	Refreshes     uint64
+	RefreshErrors uint64
+}
+
+// CacheStatsReporter is implemented by caches that report CacheStats
+type CacheStatsReporter interface {
+	CacheStats() CacheStats
+}
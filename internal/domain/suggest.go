@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// Suggestion scoring. A name that starts with the query scores
+// SuggestPrefixScore, one with a word that starts with it
+// SuggestWordPrefixScore, and any other name its trigram similarity to the
+// query, if that is at least SuggestMinSimilarity. Nearness is
+// 1 / (1 + km / SuggestDistanceScaleKm). Both stores score this way.
+const (
+	SuggestPrefixScore     = 1.0
+	SuggestWordPrefixScore = 0.9
+	// SuggestMinSimilarity is pg_trgm's default similarity threshold
+	SuggestMinSimilarity   = 0.3
+	SuggestDistanceScaleKm = 10.0
+
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 50
+	// DefaultSuggestDistanceWeight is the share of the score given to
+	// nearness unless configured otherwise
+	DefaultSuggestDistanceWeight = 0.3
+)
+
+// ErrSuggestionsUnsupported is returned when the repository cannot
+// suggest names
+var ErrSuggestionsUnsupported = errors.New("suggestions not supported by this repository")
+
+// SuggestQuery asks for the locations best matching a partial name,
+// favouring those near a point
+type SuggestQuery struct {
+	// Query is the partial name typed so far, trimmed and not empty
+	Query string
+	// Near is the point nearness is measured from; without it names are
+	// ranked on how well they match alone
+	Near  *geospatial.Coordinate
+	Limit int
+	// DistanceWeight is the share of the score given to nearness, from 0
+	// to 1; the rest is given to the name match
+	DistanceWeight float64
+}
+
+// Suggestion is one location matching a SuggestQuery
+type Suggestion struct {
+	Location *Location
+	// Distance from the query point; zero without one
+	Distance geospatial.Distance
+	// Score ranks suggestions, highest first, from 0 to 1
+	Score float64
+}
+
+// LocationSuggester is implemented by repositories that can rank
+// locations by how well their names match a partial name and how near
+// they are. Suggestions are ordered by score, then distance, then name.
+type LocationSuggester interface {
+	SuggestLocations(query SuggestQuery) ([]Suggestion, error)
+}
+
+// SuggestScore combines a name score with the distance from the query
+// point, if the query has one
+func SuggestScore(query SuggestQuery, nameScore float64, distance geospatial.Distance) float64 {
+	if query.Near == nil {
+		return nameScore
+	}
+	nearness := 1 / (1 + distance.Kilometers()/SuggestDistanceScaleKm)
+	return (1-query.DistanceWeight)*nameScore + query.DistanceWeight*nearness
+}
+
+// MatchRange is the part of a name that matched a query, as rune offsets:
+// Start is the first rune matched and End the one after the last
+type MatchRange struct {
+	Start int
+	End   int
+}
+
+// SuggestMatch finds query in name, ignoring case, preferring the start of
+// a word. It reports false for names that only resemble the query.
+func SuggestMatch(name, query string) (MatchRange, bool) {
+	// Lowercasing maps runes one to one, so rune offsets carry over
+	lowerName, lowerQuery := strings.ToLower(name), strings.ToLower(query)
+	if lowerQuery == "" {
+		return MatchRange{}, false
+	}
+	at := -1
+	if strings.HasPrefix(lowerName, lowerQuery) {
+		at = 0
+	} else if i := strings.Index(lowerName, " "+lowerQuery); i >= 0 {
+		at = i + 1
+	} else {
+		at = strings.Index(lowerName, lowerQuery)
+	}
+	if at < 0 {
+		return MatchRange{}, false
+	}
+	start := utf8.RuneCountInString(lowerName[:at])
+	return MatchRange{Start: start, End: start + utf8.RuneCountInString(lowerQuery)}, true
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"slices"
+	"sort"
+)
+
+// Actions that converge local locations on a sync source
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+	// SyncAlias is reported for an alias that could not be added to a
+	// created location
+	SyncAlias = "alias"
+)
+
+// Reasons a sync change is not applied
+const (
+	SyncDuplicateName = "duplicate_name"
+	SyncRejected      = "rejected"
+)
+
+// SyncChange is one step of a sync. Location is the source's version, nil
+// for a delete; Current is the local version, nil for a create.
+type SyncChange struct {
+	Action   string
+	Name     string
+	Location *Location
+	Current  *Location
+}
+
+// SyncConflict reports a source location, or a change to one, that was
+// not applied
+type SyncConflict struct {
+	Name   string `json:"name"`
+	Action string `json:"action,omitempty"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SyncResult summarizes a sync. In a dry run the counts are of planned
+// changes; otherwise they are of applied ones.
+type SyncResult struct {
+	DryRun    bool
+	Source    int
+	Created   int
+	Updated   int
+	Deleted   int
+	Unchanged int
+	Changes   []SyncChange
+	Conflicts []SyncConflict
+}
+
+// PlanSync diffs the source's locations against the local ones by name.
+// Local locations the source lacks are deleted, those whose fields differ
+// are updated and the source's new ones are created; IDs and creation
+// times are not compared. Deletes come first, then updates, then creates,
+// each by name, so a name or alias freed by one change is free for the
+// next. A name the source lists twice is reported and left alone.
+func PlanSync(local []*Location, source []Location) (changes []SyncChange, unchanged int, conflicts []SyncConflict) {
+	conflicts = []SyncConflict{}
+	wanted := make(map[string]*Location, len(source))
+	duplicates := map[string]bool{}
+	for i := range source {
+		name := source[i].Name
+		if _, ok := wanted[name]; ok {
+			duplicates[name] = true
+			continue
+		}
+		wanted[name] = &source[i]
+	}
+	for name := range duplicates {
+		delete(wanted, name)
+		conflicts = append(conflicts, SyncConflict{Name: name, Reason: SyncDuplicateName})
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Name < conflicts[j].Name })
+
+	var deletes, updates, creates []SyncChange
+	existing := make(map[string]bool, len(local))
+	for _, current := range local {
+		existing[current.Name] = true
+		if duplicates[current.Name] {
+			continue
+		}
+		location, ok := wanted[current.Name]
+		switch {
+		case !ok:
+			deletes = append(deletes, SyncChange{Action: SyncDelete, Name: current.Name, Current: current})
+		case sameContents(current, location):
+			unchanged++
+		default:
+			updates = append(updates, SyncChange{Action: SyncUpdate, Name: current.Name, Location: location, Current: current})
+		}
+	}
+	for name, location := range wanted {
+		if !existing[name] {
+			creates = append(creates, SyncChange{Action: SyncCreate, Name: name, Location: location})
+		}
+	}
+
+	for _, group := range [][]SyncChange{deletes, updates, creates} {
+		sort.Slice(group, func(i, j int) bool { return group[i].Name < group[j].Name })
+		changes = append(changes, group...)
+	}
+	return changes, unchanged, conflicts
+}
+
+// sameContents compares the fields a sync copies
+func sameContents(a, b *Location) bool {
+	return a.Latitude == b.Latitude &&
+		a.Longitude == b.Longitude &&
+		a.Description == b.Description &&
+		a.Region == b.Region &&
+		sameHours(a.OpeningHours, b.OpeningHours) &&
+		slices.Equal(sortedAliases(a.Aliases), sortedAliases(b.Aliases)) &&
+		slices.Equal(a.Attachments, b.Attachments)
+}
+
+func sameHours(a, b *OpeningHours) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Timezone != b.Timezone || len(a.Days) != len(b.Days) {
+		return false
+	}
+	for day, intervals := range a.Days {
+		if !slices.Equal(intervals, b.Days[day]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedAliases(aliases []string) []string {
+	return slices.Sorted(slices.Values(aliases))
+}
+
+// Record counts a change made, or planned in a dry run
+func (r *SyncResult) Record(action string) {
+	switch action {
+	case SyncCreate:
+		r.Created++
+	case SyncUpdate:
+		r.Updated++
+	case SyncDelete:
+		r.Deleted++
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrStorageTimeout is returned by a repository call that ran past its
+// adaptive timeout. The call itself may still finish in the background.
+var ErrStorageTimeout = errors.New("storage call timed out")
+
+// TimeoutStats describes the adaptive timeout of one repository method
+type TimeoutStats struct {
+	Method string
+	// Timeout is what the next call is allowed, P99 the latency it is
+	// derived from and Samples the calls in the window behind it
+	Timeout time.Duration
+	P99     time.Duration
+	Samples int
+	// TimedOut counts the calls cut off since startup
+	TimedOut uint64
+}
+
+// TimeoutReporter is implemented by repositories with adaptive timeouts
+type TimeoutReporter interface {
+	TimeoutStats() []TimeoutStats
+}
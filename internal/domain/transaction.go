@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxTransactionOperations caps the operations in one transaction
+const MaxTransactionOperations = 100
+
+var (
+	// ErrEmptyTransaction is returned for a transaction without operations
+	ErrEmptyTransaction = errors.New("transaction has no operations")
+	// ErrEmptyUpdate is returned for an update that changes no field
+	ErrEmptyUpdate = errors.New("update changes no field")
+	// ErrTransactionsUnsupported is returned when the repository cannot
+	// apply operations atomically
+	ErrTransactionsUnsupported = errors.New("repository does not support transactions")
+)
+
+// OperationType is what one operation of a transaction does
+type OperationType string
+
+const (
+	OperationCreate OperationType = "create"
+	OperationUpdate OperationType = "update"
+	OperationDelete OperationType = "delete"
+)
+
+// LocationChanges lists the fields an update sets; nil fields keep their
+// value. Aliases and attachments, when set, replace the whole list. The
+// ID, creation time, owner and stock cannot be changed.
+type LocationChanges struct {
+	Name         *string
+	Latitude     *float64
+	Longitude    *float64
+	Description  *string
+	Region       *string
+	OpeningHours *OpeningHours
+	// ClearOpeningHours removes the opening hours; it is ignored when
+	// OpeningHours is set
+	ClearOpeningHours bool
+	Aliases           *[]string
+	Attachments       *[]Attachment
+}
+
+// IsEmpty reports whether the changes set no field
+func (c LocationChanges) IsEmpty() bool {
+	return c == LocationChanges{}
+}
+
+// Apply returns a copy of location with the changes made
+func (c LocationChanges) Apply(location Location) Location {
+	location.Aliases = slices.Clone(location.Aliases)
+	if c.Name != nil {
+		location.Name = *c.Name
+	}
+	if c.Latitude != nil {
+		location.Latitude = *c.Latitude
+	}
+	if c.Longitude != nil {
+		location.Longitude = *c.Longitude
+	}
+	if c.Description != nil {
+		location.Description = *c.Description
+	}
+	if c.Region != nil {
+		location.Region = *c.Region
+	}
+	if c.OpeningHours != nil {
+		location.OpeningHours = c.OpeningHours
+	} else if c.ClearOpeningHours {
+		location.OpeningHours = nil
+	}
+	if c.Aliases != nil {
+		location.Aliases = nil
+		if len(*c.Aliases) > 0 {
+			location.Aliases = slices.Sorted(slices.Values(*c.Aliases))
+		}
+	}
+	if c.Attachments != nil {
+		location.Attachments = nil
+		if len(*c.Attachments) > 0 {
+			location.Attachments = slices.Clone(*c.Attachments)
+		}
+	}
+	return location
+}
+
+// LocationOperation is one step of a transaction
+type LocationOperation struct {
+	Type OperationType
+	// Location is the location to create
+	Location *Location
+	// Name finds the location to update or delete, by its name or an alias
+	Name string
+	// Changes are the fields an update sets
+	Changes LocationChanges
+	// Checks must pass, against the location as the transaction finds
+	// it, for an update or delete to apply
+	Checks []Precondition
+}
+
+// OperationResult reports one applied operation: the location as created
+// or updated, or as it was when deleted
+type OperationResult struct {
+	Type     OperationType
+	Location Location
+}
+
+// OperationError names the operation a transaction stopped at. Nothing
+// the transaction did is kept.
+type OperationError struct {
+	Index int
+	Err   error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// LocationTransactor applies a list of operations in order, all or none.
+// Creates, updates and deletes behave as Save, an update in place and
+// Delete would; the first that fails is returned as an *OperationError and
+// the store is left as it was. Renaming a location records it as deleted
+// under the old name and updated under the new one, as a rename does.
+type LocationTransactor interface {
+	ApplyOperations(ops []LocationOperation) ([]OperationResult, error)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// TruncateAudit records the removal of every location at once
+type TruncateAudit struct {
+	ID          string    `json:"id"`
+	Count       int       `json:"count"`
+	Actor       string    `json:"actor,omitempty"`
+	TruncatedAt time.Time `json:"truncated_at"`
+	// Removed lists the locations removed, in ID order, for publishing
+	// their deletions. It is not kept in the audit log.
+	Removed []Location `json:"-"`
+}
+
+// LocationTruncator empties a location store. Every location and alias is
+// removed and one audit entry is written with the count, atomically. IDs
+// are not reused. The change log keeps its sequence and records a deletion
+// for each location removed, so clients syncing from it converge on the
+// empty store, and a store with an outbox writes a location.deleted event
+// for each in the same transaction.
+type LocationTruncator interface {
+	TruncateLocations(actor string) (*TruncateAudit, error)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("monthly request quota exceeded")
+
+// UsageRecord is the number of requests a key made to one operation on one day
+type UsageRecord struct {
+	Key       string    `json:"key"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Operation string    `json:"operation"`
+	Day       time.Time `json:"day"`
+	Count     int64     `json:"count"`
+}
+
+type UsageRepository interface {
+	// AddUsage adds each record's count to the stored daily counter
+	AddUsage(records []UsageRecord) error
+	// ListUsage returns daily counters between from and to inclusive. An empty key lists all keys.
+	ListUsage(key string, from, to time.Time) ([]UsageRecord, error)
+	// TotalUsage returns the summed count for a key between from and to inclusive
+	TotalUsage(key string, from, to time.Time) (int64, error)
+}
+
+// UsageDay truncates t to the UTC day it falls in
+func UsageDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// UsageMonth returns the first and last UTC day of the month t falls in
+func UsageMonth(t time.Time) (time.Time, time.Time) {
+	y, m, _ := t.UTC().Date()
+	first := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	return first, first.AddDate(0, 1, -1)
+}
+
+type UsageService interface {
+	Record(key, tenant, operation string) error
+	Usage(key string, from, to time.Time) ([]UsageRecord, error)
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	playground "github.com/go-playground/validator/v10"
+)
+
+// FieldError reports one field of a location whose value is not valid,
+// in the terms of a validation rule so it can be described in any
+// language. It matches the field's sentinel, such as ErrInvalidLatitude,
+// with errors.Is.
+type FieldError struct {
+	// Field is the field's JSON name
+	Field string
+	// Rule is the rule the value broke: required, min or max
+	Rule string
+	// Param is the rule's bound, empty for required
+	Param string
+	// Value is the value given
+	Value any
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every invalid field of a location, so a caller can
+// fix them all at once. It matches each field's sentinel with errors.Is.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, field := range e.Fields {
+		errs[i] = field
+	}
+	return errs
+}
+
+// Invalid returns a ValidationError listing the fields that are not nil,
+// or nil when all are
+func Invalid(fields ...*FieldError) error {
+	var invalid []*FieldError
+	for _, field := range fields {
+		if field != nil {
+			invalid = append(invalid, field)
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: invalid}
+}
+
+// EmptyNameError reports a name left blank
+func EmptyNameError(name string) *FieldError {
+	return &FieldError{Field: "name", Rule: "required", Value: name, Err: ErrEmptyName}
+}
+
+// LatitudeError reports a latitude out of range, and returns nil for one
+// in range
+func LatitudeError(latitude float64) *FieldError {
+	return rangeError("latitude", latitude, 90, ErrInvalidLatitude)
+}
+
+// LongitudeError reports a longitude out of range, and returns nil for one
+// in range
+func LongitudeError(longitude float64) *FieldError {
+	return rangeError("longitude", longitude, 180, ErrInvalidLongitude)
+}
+
+func rangeError(field string, value, limit float64, err error) *FieldError {
+	switch {
+	case value < -limit:
+		return &FieldError{Field: field, Rule: "min", Param: strconv.FormatFloat(-limit, 'f', -1, 64), Value: value, Err: err}
+	case value > limit:
+		return &FieldError{Field: field, Rule: "max", Param: strconv.FormatFloat(limit, 'f', -1, 64), Value: value, Err: err}
+	}
+	return nil
+}
+
+// fieldSentinels are the errors the validated fields of a Location match
+var fieldSentinels = map[string]error{
+	"name":      ErrEmptyName,
+	"latitude":  ErrInvalidLatitude,
+	"longitude": ErrInvalidLongitude,
+}
+
+// structErrors converts the struct tag failures of a Location into a
+// ValidationError; other errors are returned as they are
+func structErrors(err error) error {
+	var failures playground.ValidationErrors
+	if !errors.As(err, &failures) {
+		return err
+	}
+	invalid := &ValidationError{Fields: make([]*FieldError, 0, len(failures))}
+	for _, failure := range failures {
+		field := strings.ToLower(failure.Field()[:1]) + failure.Field()[1:]
+		sentinel, ok := fieldSentinels[field]
+		if !ok {
+			sentinel = errors.New(field + " is invalid")
+		}
+		invalid.Fields = append(invalid.Fields, &FieldError{
+			Field: field,
+			Rule:  failure.Tag(),
+			Param: failure.Param(),
+			Value: failure.Value(),
+			Err:   sentinel,
+		})
+	}
+	return invalid
+}
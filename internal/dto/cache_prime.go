@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/jesuloba-world/leeta-task/internal/domain"
+
+// CachePrimeResponse describes startup cache priming on verbose health checks
+type CachePrimeResponse struct {
+	State      string `json:"state" enum:"warming,primed,failed" example:"primed" doc:"warming while priming runs, then primed or failed"`
+	Names      int    `json:"names" example:"100" doc:"Names saved by the previous run that priming loads"`
+	Locations  int    `json:"locations" example:"1200" doc:"Locations priming read"`
+	StartedAt  string `json:"started_at" example:"2025-09-08T09:00:00Z" doc:"When priming started"`
+	FinishedAt string `json:"finished_at,omitempty" example:"2025-09-08T09:00:01Z" doc:"When priming finished"`
+	Error      string `json:"error,omitempty" doc:"Why priming failed; the cache then fills from requests"`
+}
+
+func FromCachePrimeStatus(status domain.CachePrimeStatus) *CachePrimeResponse {
+	response := &CachePrimeResponse{
+		State:     status.State,
+		Names:     status.Names,
+		Locations: status.Locations,
+		StartedAt: formatOptionalTime(status.StartedAt),
+		Error:     status.Error,
+	}
+	if status.FinishedAt != nil {
+		response.FinishedAt = formatOptionalTime(*status.FinishedAt)
+	}
+	return response
+}
@@ -0,0 +1,10 @@
+package dto
+
+// CapabilitiesSchemaVersion is raised whenever a change to the capability
+// document could break a client reading it
+const CapabilitiesSchemaVersion = 1
+
+type CapabilitiesResponse struct {
+	SchemaVersion int            `json:"schema_version" example:"1" doc:"Version of this document's layout"`
+	Features      map[string]any `json:"features" doc:"Enabled optional features by name, each with its settings. Features that are off are left out."`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type ChangeResponse struct {
+	Sequence  int64            `json:"seq" example:"48214" doc:"Position in the change log; strictly increasing, with gaps"`
+	Action    string           `json:"action" enum:"created,updated,deleted" example:"updated" doc:"What happened to the name"`
+	Name      string           `json:"name" example:"Leeta Lekki Phase 1" doc:"Name the change applies to; a rename deletes the old name and updates the new one"`
+	Location  LocationResponse `json:"location" doc:"State after the change, or the last state before a deletion"`
+	ChangedAt time.Time        `json:"changed_at" example:"2025-08-30T09:30:00Z" doc:"Time the change was recorded"`
+}
+
+type ChangeFeedResponse struct {
+	Changes   []ChangeResponse `json:"changes"`
+	LatestSeq int64            `json:"latest_seq" example:"48290" doc:"Largest sequence recorded; pass it as since_seq once has_more is false"`
+	HasMore   bool             `json:"has_more" doc:"Set when further changes follow the last one returned"`
+}
+
+func FromChangeFeed(feed *domain.ChangeFeed, hasMore bool) ChangeFeedResponse {
+	changes := make([]ChangeResponse, len(feed.Changes))
+	for i, change := range feed.Changes {
+		changes[i] = ChangeResponse{
+			Sequence:  change.Sequence,
+			Action:    string(change.Action),
+			Name:      change.Name,
+			Location:  FromDomain(&change.Location),
+			ChangedAt: change.ChangedAt,
+		}
+	}
+	return ChangeFeedResponse{Changes: changes, LatestSeq: feed.Latest, HasMore: hasMore}
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"sort"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial/projection"
+)
+
+// Reasons coordinates are rejected for their crs
+const (
+	CRSOutsideArea = "outside_area"
+	// CRSMissingXY marks a projected crs without both x and y
+	CRSMissingXY = "missing_xy"
+	// CRSUnprojectedXY marks x and y sent without a projected crs
+	CRSUnprojectedXY = "xy_without_projected_crs"
+	// CRSMixedCoordinates marks latitude or longitude sent with a
+	// projected crs
+	CRSMixedCoordinates = "latitude_longitude_with_projected_crs"
+)
+
+// CRSError reports coordinates that cannot be read in the request's crs
+type CRSError struct {
+	CRS    int
+	Reason string
+}
+
+func (e *CRSError) Error() string {
+	return "invalid coordinates for the crs: " + e.Reason
+}
+
+func projected(crs int) bool {
+	return crs != 0 && crs != projection.WGS84
+}
+
+// Project converts x and y in a projected crs to latitude and longitude, so
+// the rest of the request is handled as WGS-84. Without a projected crs it
+// only checks x and y are absent.
+func (req *LocationRequest) Project() error {
+	if !projected(req.CRS) {
+		if req.X != nil || req.Y != nil {
+			return &CRSError{CRS: projection.WGS84, Reason: CRSUnprojectedXY}
+		}
+		return nil
+	}
+	if req.Latitude != nil || req.Longitude != nil {
+		return &CRSError{CRS: req.CRS, Reason: CRSMixedCoordinates}
+	}
+	if req.X == nil || req.Y == nil {
+		return &CRSError{CRS: req.CRS, Reason: CRSMissingXY}
+	}
+	c, err := projection.ToWGS84(req.CRS, *req.X, *req.Y)
+	if err != nil {
+		return &CRSError{CRS: req.CRS, Reason: CRSOutsideArea}
+	}
+	req.Latitude, req.Longitude = &c.Latitude, &c.Longitude
+	req.X, req.Y = nil, nil
+	return nil
+}
+
+// Project converts the records for a restore. With a projected crs each
+// record's longitude and latitude hold its x and y; records that do not
+// transform are left out and returned as invalid, and positions gives the
+// snapshot index of each location returned.
+func (s Snapshot) Project() (locations []domain.Location, positions []int, invalid []domain.RestoreConflict) {
+	for i, location := range s.ToDomain() {
+		if projected(s.CRS) {
+			c, err := projection.ToWGS84(s.CRS, location.Longitude, location.Latitude)
+			if err != nil {
+				invalid = append(invalid, domain.RestoreConflict{Index: i, ID: location.ID, Name: location.Name, Reason: domain.RestoreInvalid})
+				continue
+			}
+			location.Latitude, location.Longitude = c.Latitude, c.Longitude
+		}
+		locations = append(locations, location)
+		positions = append(positions, i)
+	}
+	return locations, positions, invalid
+}
+
+// MergeConflicts adds the records Project rejected to a restore of the rest,
+// moving the restore's conflicts back to snapshot indexes
+func MergeConflicts(result *domain.RestoreResult, positions []int, invalid []domain.RestoreConflict) {
+	for i := range result.Conflicts {
+		result.Conflicts[i].Index = positions[result.Conflicts[i].Index]
+	}
+	result.Conflicts = append(result.Conflicts, invalid...)
+	sort.Slice(result.Conflicts, func(i, j int) bool { return result.Conflicts[i].Index < result.Conflicts[j].Index })
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type DuplicatePairResponse struct {
+	A              string  `json:"a" example:"Leeta Lekki Phase 1" doc:"First location name"`
+	B              string  `json:"b" example:"Leeta Lekki Phase I" doc:"Second location name"`
+	DistanceM      float64 `json:"distance_m" example:"12.4" doc:"Great-circle distance between the two locations in metres"`
+	NameSimilarity float64 `json:"name_similarity" example:"0.95" doc:"Name similarity from 0 (unrelated) to 1 (identical after normalization)"`
+}
+
+type DuplicateClusterResponse struct {
+	Locations []LocationResponse      `json:"locations" doc:"Locations in the cluster, by name"`
+	Pairs     []DuplicatePairResponse `json:"pairs" doc:"The matching pairs that link the cluster"`
+}
+
+type DuplicateReportResponse struct {
+	Clusters []DuplicateClusterResponse `json:"clusters"`
+	Count    int                        `json:"count" example:"1" doc:"Number of clusters"`
+}
+
+type MergeRequest struct {
+	Winner string   `json:"winner" minLength:"1" example:"Leeta Lekki Phase 1" doc:"Location to keep"`
+	Losers []string `json:"losers" minItems:"1" example:"[\"Leeta Lekki Phase I\"]" doc:"Locations to delete in favour of the winner"`
+}
+
+type MergeResponse struct {
+	ID       string             `json:"id" example:"7" doc:"Audit entry identifier"`
+	Winner   string             `json:"winner" example:"Leeta Lekki Phase 1" doc:"Location kept"`
+	Losers   []LocationResponse `json:"losers" doc:"Locations deleted, as they were before the merge"`
+	Actor    string             `json:"actor,omitempty" example:"ops" doc:"Caller that performed the merge"`
+	MergedAt time.Time          `json:"merged_at" example:"2025-08-18T10:00:00Z" doc:"Time of the merge"`
+}
+
+func FromDuplicateClusters(clusters []domain.DuplicateCluster) DuplicateReportResponse {
+	responses := make([]DuplicateClusterResponse, len(clusters))
+	for i, cluster := range clusters {
+		locations := make([]LocationResponse, len(cluster.Locations))
+		for j, location := range cluster.Locations {
+			locations[j] = FromDomain(location)
+		}
+		pairs := make([]DuplicatePairResponse, len(cluster.Pairs))
+		for j, pair := range cluster.Pairs {
+			pairs[j] = DuplicatePairResponse{
+				A:              pair.A,
+				B:              pair.B,
+				DistanceM:      pair.Distance.Meters(),
+				NameSimilarity: pair.NameSimilarity,
+			}
+		}
+		responses[i] = DuplicateClusterResponse{Locations: locations, Pairs: pairs}
+	}
+
+	return DuplicateReportResponse{
+		Clusters: responses,
+		Count:    len(responses),
+	}
+}
+
+func FromMergeAudit(audit *domain.MergeAudit) MergeResponse {
+	losers := make([]LocationResponse, len(audit.Losers))
+	for i := range audit.Losers {
+		losers[i] = FromDomain(&audit.Losers[i])
+	}
+
+	return MergeResponse{
+		ID:       audit.ID,
+		Winner:   audit.Winner,
+		Losers:   losers,
+		Actor:    audit.Actor,
+		MergedAt: audit.MergedAt,
+	}
+}
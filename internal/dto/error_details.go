@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// mapErrorDetails returns a copy of an error body with fn applied to the
+// value of each of its details, such as the candidates an ambiguous
+// lookup lists, and false for bodies that are not errors
+func mapErrorDetails(body any, fn func(any) any) (any, bool) {
+	switch b := body.(type) {
+	case *apierrors.CodedError:
+		copied := *b
+		copied.Errors = mapDetails(b.Errors, fn)
+		return &copied, true
+	case *huma.ErrorModel:
+		copied := *b
+		copied.Errors = mapDetails(b.Errors, fn)
+		return &copied, true
+	}
+	return body, false
+}
+
+func mapDetails(details []*huma.ErrorDetail, fn func(any) any) []*huma.ErrorDetail {
+	if len(details) == 0 {
+		return details
+	}
+	mapped := make([]*huma.ErrorDetail, len(details))
+	for i, detail := range details {
+		if detail == nil {
+			continue
+		}
+		copied := *detail
+		copied.Value = fn(detail.Value)
+		mapped[i] = &copied
+	}
+	return mapped
+}
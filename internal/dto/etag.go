@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ETag returns a strong entity tag for the representation of a location a
+// request with ctx receives. It is computed after rounding and coordinate
+// privacy, so the tag of an obscured location reveals nothing about its
+// exact position.
+func ETag(ctx context.Context, location LocationResponse) string {
+	shown, _ := ObscureCoordinates(ctx, RoundCoordinates(ctx, location)).(LocationResponse)
+	data, _ := json.Marshal(shown)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+)
+
+// ExternalIDs turns the IDs the repositories assign into the opaque IDs
+// responses show, and back. An external ID is the internal one, a decimal
+// number, encrypted with AES alongside eight zero bytes as one block, so
+// it reveals neither the ID nor how many locations came before it, and a
+// forged or altered one decrypts to nonzero padding and is rejected. The
+// same key always gives a row the same external ID. A nil *ExternalIDs
+// shows IDs as they are.
+type ExternalIDs struct {
+	block cipher.Block
+}
+
+// NewExternalIDs keys the encoding with a SHA-256 digest of key
+func NewExternalIDs(key []byte) *ExternalIDs {
+	sum := sha256.Sum256(key)
+	block, _ := aes.NewCipher(sum[:])
+	return &ExternalIDs{block: block}
+}
+
+// Encode returns the external ID for id. IDs that are not decimal
+// numbers are returned as they are.
+func (e *ExternalIDs) Encode(id string) string {
+	if e == nil {
+		return id
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return id
+	}
+	var block [aes.BlockSize]byte
+	binary.BigEndian.PutUint64(block[:8], n)
+	e.block.Encrypt(block[:], block[:])
+	return base64.RawURLEncoding.EncodeToString(block[:])
+}
+
+// Decode returns the internal ID an external ID stands for, and false for
+// one this key did not produce
+func (e *ExternalIDs) Decode(external string) (string, bool) {
+	if e == nil {
+		return external, true
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(external)
+	if err != nil || len(raw) != aes.BlockSize {
+		return "", false
+	}
+	var block [aes.BlockSize]byte
+	e.block.Decrypt(block[:], raw)
+	var zero [8]byte
+	if subtle.ConstantTimeCompare(block[8:], zero[:]) != 1 {
+		return "", false
+	}
+	return strconv.FormatUint(binary.BigEndian.Uint64(block[:8]), 10), true
+}
+
+type externalIDsKey struct{}
+
+// WithExternalIDs returns a copy of ctx whose responses show IDs encoded
+// by ids
+func WithExternalIDs(ctx context.Context, ids *ExternalIDs) context.Context {
+	return context.WithValue(ctx, externalIDsKey{}, ids)
+}
+
+// ExternalIDsFromContext returns the encoding for a request, or nil, which
+// shows IDs as they are
+func ExternalIDsFromContext(ctx context.Context) *ExternalIDs {
+	ids, _ := ctx.Value(externalIDsKey{}).(*ExternalIDs)
+	return ids
+}
+
+// EncodeIDs returns body with the ID of every location in it replaced by
+// its external ID under the encoding in ctx, through LocationBody. Backups
+// from GET /admin/export, and the conflicts a restore of one reports, keep
+// the real IDs, as a restore needs them. body is not modified.
+func EncodeIDs(ctx context.Context, body any) any {
+	e := ExternalIDsFromContext(ctx)
+	if e == nil {
+		return body
+	}
+	return MapLocations(body, func(id *string, _, _ *float64) {
+		*id = e.Encode(*id)
+	})
+}
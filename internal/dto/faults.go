@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// FaultRequest is a fault to inject into one repository method
+type FaultRequest struct {
+	Method    string  `json:"method" enum:"Save,FindByName,FindByID,FindAll,Find,Delete,FindNearest,Count,SuggestLocations,AddAlias,RemoveAlias,ApplyOperations,MergeLocations,RestoreLocations,RenameLocation,StreamLocations" example:"FindNearest" doc:"Location repository method; FindNearest covers every nearest search"`
+	ErrorRate float64 `json:"error_rate,omitempty" minimum:"0" maximum:"1" example:"0.5" doc:"Share of calls that fail, from 0 to 1"`
+	Error     string  `json:"error,omitempty" enum:"internal,timeout,not_found" example:"internal" doc:"Error failing calls return: an unexpected storage error, a storage timeout, or the location not existing. Defaults to internal"`
+	LatencyMs int     `json:"latency_ms,omitempty" minimum:"0" maximum:"60000" example:"250" doc:"Milliseconds added to every call, failing or not"`
+}
+
+// InjectFaultsRequest replaces the faults in effect
+type InjectFaultsRequest struct {
+	Faults          []FaultRequest `json:"faults" maxItems:"16" doc:"Faults by method; an empty list clears them"`
+	DurationSeconds int            `json:"duration_seconds,omitempty" minimum:"0" example:"120" doc:"Seconds until the faults are cleared on their own; 0, or more than the configured maximum, means the maximum"`
+}
+
+// ToDomain returns the faults by method
+func (req InjectFaultsRequest) ToDomain() map[string]domain.Fault {
+	faults := make(map[string]domain.Fault, len(req.Faults))
+	for _, fault := range req.Faults {
+		kind := domain.FaultKind(fault.Error)
+		if kind == "" {
+			kind = domain.FaultInternal
+		}
+		faults[fault.Method] = domain.Fault{
+			ErrorRate: fault.ErrorRate,
+			Kind:      kind,
+			Latency:   time.Duration(fault.LatencyMs) * time.Millisecond,
+		}
+	}
+	return faults
+}
+
+// FaultPlanResponse is the faults in effect
+type FaultPlanResponse struct {
+	Faults    []FaultRequest `json:"faults" doc:"Faults by method, sorted; empty when none are in effect"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty" doc:"When the faults are cleared on their own, absent when there are none"`
+}
+
+// FromFaultPlan converts the faults in effect
+func FromFaultPlan(plan domain.FaultPlan) FaultPlanResponse {
+	resp := FaultPlanResponse{Faults: make([]FaultRequest, 0, len(plan.Faults))}
+	for method, fault := range plan.Faults {
+		resp.Faults = append(resp.Faults, FaultRequest{
+			Method:    method,
+			ErrorRate: fault.ErrorRate,
+			Error:     string(fault.Kind),
+			LatencyMs: int(fault.Latency / time.Millisecond),
+		})
+	}
+	slices.SortFunc(resp.Faults, func(a, b FaultRequest) int {
+		return slices.Index(domain.FaultMethods, a.Method) - slices.Index(domain.FaultMethods, b.Method)
+	})
+	if !plan.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &plan.ExpiresAt
+	}
+	return resp
+}
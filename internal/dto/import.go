@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type ImportRowErrorResponse struct {
+	Row   int    `json:"row" example:"42" doc:"Line of the row in the file, the header being line 1"`
+	Name  string `json:"name,omitempty" example:"Leeta Ikeja"`
+	Error string `json:"error" example:"invalid latitude \"6,45\"" doc:"Why the row was not imported"`
+}
+
+type ImportJobResponse struct {
+	ID              string                   `json:"id,omitempty" example:"01K4EJ2Z8S3Q9V6W0X1Y2Z3A4B" doc:"Job ID, absent for a synchronous import"`
+	Status          string                   `json:"status" enum:"pending,running,completed,failed,cancelled"`
+	Total           int                      `json:"total" example:"500000" doc:"Rows in the file"`
+	Processed       int                      `json:"processed" example:"120000" doc:"Rows handled so far"`
+	Imported        int                      `json:"imported" example:"119870" doc:"Rows created as locations"`
+	Failed          int                      `json:"failed" example:"130" doc:"Rows not imported"`
+	Errors          []ImportRowErrorResponse `json:"errors" doc:"Rows not imported, in file order; complete once the job finishes, up to the first 1000"`
+	Error           string                   `json:"error,omitempty" doc:"Why a failed job stopped"`
+	CancelRequested bool                     `json:"cancel_requested,omitempty" doc:"Whether a running job has been asked to stop after its current batch"`
+	CreatedAt       string                   `json:"created_at" example:"2025-09-06T09:00:00Z"`
+	StartedAt       string                   `json:"started_at,omitempty" example:"2025-09-06T09:00:01Z"`
+	FinishedAt      string                   `json:"finished_at,omitempty" example:"2025-09-06T09:04:12Z"`
+}
+
+func FromImportJob(job *domain.ImportJob) ImportJobResponse {
+	errs := make([]ImportRowErrorResponse, len(job.Errors))
+	for i, e := range job.Errors {
+		errs[i] = ImportRowErrorResponse{Row: e.Row, Name: e.Name, Error: e.Error}
+	}
+	return ImportJobResponse{
+		ID:              job.ID,
+		Status:          string(job.Status),
+		Total:           job.Total,
+		Processed:       job.Processed,
+		Imported:        job.Imported,
+		Failed:          job.Failed,
+		Errors:          errs,
+		Error:           job.Error,
+		CancelRequested: job.CancelRequested && !job.Status.Finished(),
+		CreatedAt:       job.CreatedAt.UTC().Format(time.RFC3339),
+		StartedAt:       formatOptionalTimePtr(job.StartedAt),
+		FinishedAt:      formatOptionalTimePtr(job.FinishedAt),
+	}
+}
+
+func formatOptionalTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatOptionalTime(*t)
+}
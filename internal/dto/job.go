@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/scheduler"
+)
+
+type JobStatusResponse struct {
+	Name                string  `json:"name" example:"location-stats" doc:"Job name"`
+	IntervalSeconds     float64 `json:"interval_seconds" example:"60" doc:"Pause between runs"`
+	Running             bool    `json:"running" doc:"Whether a run is in progress"`
+	Runs                int     `json:"runs" example:"42" doc:"Runs since startup"`
+	Failures            int     `json:"failures" example:"0" doc:"Failed or timed-out runs since startup"`
+	LastStart           string  `json:"last_start,omitempty" example:"2025-08-18T10:00:00Z" doc:"Start of the most recent run"`
+	LastDurationSeconds float64 `json:"last_duration_seconds" example:"0.02" doc:"Duration of the most recent completed run"`
+	LastError           string  `json:"last_error,omitempty" doc:"Error of the most recent run, if it failed"`
+	LastSuccess         string  `json:"last_success,omitempty" example:"2025-08-18T10:00:00Z" doc:"End of the most recent successful run"`
+}
+
+func FromJobStatuses(statuses []scheduler.JobStatus) []JobStatusResponse {
+	responses := make([]JobStatusResponse, len(statuses))
+	for i, s := range statuses {
+		responses[i] = JobStatusResponse{
+			Name:                s.Name,
+			IntervalSeconds:     s.Interval.Seconds(),
+			Running:             s.Running,
+			Runs:                s.Runs,
+			Failures:            s.Failures,
+			LastStart:           formatOptionalTime(s.LastStart),
+			LastDurationSeconds: s.LastDuration.Seconds(),
+			LastError:           s.LastError,
+			LastSuccess:         formatOptionalTime(s.LastSuccess),
+		}
+	}
+	return responses
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
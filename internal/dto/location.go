@@ -1,34 +1,69 @@
 package dto
 
 import (
+	"context"
+	"math"
 	"time"
 
 	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 	"github.com/jesuloba-world/leeta-task/pkg/validator"
 )
 
+// LocationRequest is the body for creating a location. Coordinates are
+// pointers so an absent field can be told apart from a genuine 0; the schema
+// leaves them optional so absence is reported by Validate as a field error.
+// Call Project first to convert projected x and y.
 type LocationRequest struct {
-	Name      string  `json:"name" validate:"required,min=1"`
-	Latitude  float64 `json:"latitude" validate:"required,min=-90,max=90"`
-	Longitude float64 `json:"longitude" validate:"required,min=-180,max=180"`
+	Name         string               `json:"name" validate:"required,min=1" example:"Leeta Lekki Phase 1" doc:"Unique station name"`
+	Latitude     *float64             `json:"latitude" required:"false" validate:"required,min=-90,max=90" example:"6.4474" doc:"Required. Latitude in decimal degrees, stored rounded to the configured precision"`
+	Longitude    *float64             `json:"longitude" required:"false" validate:"required,min=-180,max=180" example:"3.4723" doc:"Required. Longitude in decimal degrees, stored rounded to the configured precision"`
+	OpeningHours *domain.OpeningHours `json:"opening_hours,omitempty" doc:"Weekly opening hours in the station's timezone; without them the station counts as open unless the server is configured otherwise"`
+	Description  string               `json:"description,omitempty" example:"Entrance on the service road; closes early on Sundays" doc:"Free-text notes, up to the configured maximum (2000 characters by default). Control characters other than newlines and tabs are removed"`
+	Region       string               `json:"region,omitempty" maxLength:"64" example:"Lagos" doc:"Operating region, one of the configured regions when a list is set; required when the server is configured so"`
+	Attachments  []domain.Attachment  `json:"attachments,omitempty" maxItems:"10" doc:"References to photos or documents of the station stored elsewhere; only the URLs are kept"`
+	CRS          int                  `json:"crs,omitempty" enum:"4326,3857,32631,32632,32633" example:"32631" doc:"EPSG code of the coordinates. Without it, or with 4326, give latitude and longitude; with a projected code (3857 web Mercator, 32631 to 32633 UTM zones 31N to 33N) give x and y instead. Locations are always stored in WGS-84"`
+	X            *float64             `json:"x,omitempty" required:"false" example:"541924.3" doc:"Easting in metres, with a projected crs"`
+	Y            *float64             `json:"y,omitempty" required:"false" example:"721189.2" doc:"Northing in metres, with a projected crs"`
 }
 
 type LocationResponse struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Latitude  float64   `json:"latitude"`
-	Longitude float64   `json:"longitude"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             string               `json:"id" example:"42" doc:"Server-assigned identifier"`
+	Name           string               `json:"name" example:"Leeta Lekki Phase 1" doc:"Unique station name"`
+	Latitude       float64              `json:"latitude" example:"6.4474" doc:"Latitude in decimal degrees"`
+	Longitude      float64              `json:"longitude" example:"3.4723" doc:"Longitude in decimal degrees"`
+	CreatedAt      time.Time            `json:"created_at" example:"2025-08-01T09:30:00Z" doc:"Creation time"`
+	Distance       *geospatial.Distance `json:"distance_km,omitempty" example:"2.37" doc:"Great-circle distance from the request's reference point in kilometres, present only when one is given"`
+	DistanceInUnit *float64             `json:"distance,omitempty" example:"1.47" doc:"distance_km in the list's unit, present only when a reference point is given"`
+	OpeningHours   *domain.OpeningHours `json:"opening_hours,omitempty" doc:"Weekly opening hours, absent when unknown"`
+	Description    string               `json:"description,omitempty" example:"Entrance on the service road; closes early on Sundays" doc:"Free-text notes, absent when empty"`
+	Aliases        []string             `json:"aliases" example:"[\"Leeta Admiralty Way\"]" doc:"Alternate names the location is also found by, sorted; empty when it has none"`
+	Region         string               `json:"region,omitempty" example:"Lagos" doc:"Operating region, absent when the location has none"`
+	Attachments    []domain.Attachment  `json:"attachments,omitempty" doc:"Photos and documents of the station, absent when it has none"`
+	CapacityLitres *float64             `json:"capacity_litres,omitempty" example:"33000" doc:"Fuel storage capacity in litres, absent when unknown"`
+	StockLitres    *float64             `json:"current_stock_litres,omitempty" example:"12500" doc:"Fuel the station holds in litres, absent when unknown"`
+	CreatedBy      string               `json:"created_by,omitempty" example:"fleet-app" doc:"API key name or token subject that created the location, absent when it was created anonymously"`
 }
 
 type LocationListResponse struct {
-	Locations []LocationResponse `json:"locations"`
-	Count     int                `json:"count"`
+	Locations  []LocationResponse `json:"locations"`
+	Count      int                `json:"count" example:"1" doc:"Number of locations in this response"`
+	Total      int                `json:"total,omitempty" example:"57" doc:"Total number of locations, for offset pagination"`
+	Page       int                `json:"page,omitempty" example:"1" doc:"Current page, for offset pagination"`
+	PageSize   int                `json:"page_size,omitempty" example:"20" doc:"Page size, for offset pagination"`
+	NextCursor string             `json:"next_cursor,omitempty" example:"NDI" doc:"Cursor for the next page, absent on the last page"`
+	Unit       string             `json:"unit,omitempty" enum:"km,m,mi,nmi" example:"mi" doc:"Unit of each location's distance, present only when a reference point is given"`
 }
 
 type NearestLocationResponse struct {
-	Location LocationResponse `json:"location"`
-	Distance float64          `json:"distance_km"`
+	Location       LocationResponse    `json:"location"`
+	Distance       geospatial.Distance `json:"distance_km" example:"2.37" doc:"Great-circle distance from the query point in kilometres"`
+	DistanceInUnit float64             `json:"distance" example:"1.47" doc:"distance_km in the response's unit"`
+	Unit           string              `json:"unit" enum:"km,m,mi,nmi" example:"mi" doc:"Unit of distance: the request's unit parameter, else the caller's preferred unit, else the server's"`
+	ETA            *int                `json:"eta_minutes,omitempty" example:"5" doc:"Straight-line travel estimate in whole minutes at the requested speed; ignores roads and traffic, present only when a speed applies"`
+	Stale          bool                `json:"stale,omitempty" doc:"Set when the answer came from a fallback snapshot because the primary store failed or was too slow"`
+	AsOf           *time.Time          `json:"as_of,omitempty" doc:"Time the fallback snapshot was taken, for stale answers"`
+	Approximate    bool                `json:"approximate,omitempty" doc:"Set when only locations near the query point were searched, so a slightly nearer one may have been missed"`
 }
 
 func (req *LocationRequest) Validate() error {
@@ -40,16 +75,61 @@ func (req *LocationRequest) ToDomain() (*domain.Location, error) {
 		return nil, err
 	}
 
-	return domain.NewLocation(req.Name, req.Latitude, req.Longitude)
+	return domain.NewLocation(req.Name, *req.Latitude, *req.Longitude)
 }
 
+type coordinatePrecisionKey struct{}
+
+// WithCoordinatePrecision returns a copy of ctx whose responses round
+// coordinates to places decimal places; values outside the supported range
+// are ignored
+func WithCoordinatePrecision(ctx context.Context, places int) context.Context {
+	if places < geospatial.MinCoordinatePrecision || places > geospatial.MaxCoordinatePrecision {
+		return ctx
+	}
+	return context.WithValue(ctx, coordinatePrecisionKey{}, places)
+}
+
+// CoordinatePrecision returns the decimal places responses round
+// coordinates to: those in ctx, else geospatial.DefaultCoordinatePrecision
+func CoordinatePrecision(ctx context.Context) int {
+	if places, ok := ctx.Value(coordinatePrecisionKey{}).(int); ok {
+		return places
+	}
+	return geospatial.DefaultCoordinatePrecision
+}
+
+// RoundCoordinates returns body with the coordinates of every location in
+// it rounded to the precision in ctx, through LocationBody. body is not
+// modified.
+func RoundCoordinates(ctx context.Context, body any) any {
+	places := CoordinatePrecision(ctx)
+	return MapLocations(body, func(id *string, latitude, longitude *float64) {
+		if latitude != nil && longitude != nil {
+			*latitude = geospatial.RoundCoordinate(*latitude, places)
+			*longitude = geospatial.RoundCoordinate(*longitude, places)
+		}
+	})
+}
+
+// FromDomain converts a location to its response. Coordinates are shown as
+// stored; RoundCoordinates rounds them to the request's precision.
 func FromDomain(location *domain.Location) LocationResponse {
 	return LocationResponse{
-		ID:        location.ID,
-		Name:      location.Name,
-		Latitude:  location.Latitude,
-		Longitude: location.Longitude,
-		CreatedAt: location.CreatedAt,
+		ID:           location.ID,
+		Name:         location.Name,
+		Latitude:     location.Latitude,
+		Longitude:    location.Longitude,
+		CreatedAt:    location.CreatedAt,
+		OpeningHours: location.OpeningHours,
+		Description:  location.Description,
+		Aliases:      append([]string{}, location.Aliases...),
+		Region:       location.Region,
+		Attachments:  location.Attachments,
+
+		CapacityLitres: location.CapacityLitres,
+		StockLitres:    location.CurrentStockLitres,
+		CreatedBy:      location.CreatedBy,
 	}
 }
 
@@ -65,9 +145,44 @@ func FromDomainList(locations []*domain.Location) LocationListResponse {
 	}
 }
 
-func FromDomainWithDistance(location *domain.Location, distance float64) NearestLocationResponse {
+// WithDistancesFrom annotates every location with its distance from the
+// reference point measured by distanceFn, leaving the order unchanged
+func (l *LocationListResponse) WithDistancesFrom(latitude, longitude float64, distanceFn geospatial.DistanceFunc) {
+	ref := geospatial.Coordinate{Latitude: latitude, Longitude: longitude}
+	for i := range l.Locations {
+		distance := distanceFn(ref, geospatial.Coordinate{
+			Latitude:  l.Locations[i].Latitude,
+			Longitude: l.Locations[i].Longitude,
+		})
+		l.Locations[i].Distance = &distance
+	}
+}
+
+func FromDomainWithDistance(location *domain.Location, distance geospatial.Distance) NearestLocationResponse {
 	return NearestLocationResponse{
 		Location: FromDomain(location),
 		Distance: distance,
 	}
 }
+
+// WithETA estimates the minutes needed to cover the distance at speedKmh,
+// rounded to the nearest minute but at least 1 when the distance is not zero
+func (r *NearestLocationResponse) WithETA(speedKmh float64) {
+	travel := r.Distance.TravelTime(speedKmh)
+	minutes := int(math.Round(travel.Minutes()))
+	if minutes == 0 && travel > 0 {
+		minutes = 1
+	}
+	r.ETA = &minutes
+}
+
+func FromNearestResult(result *domain.NearestResult) NearestLocationResponse {
+	resp := FromDomainWithDistance(result.Location, result.Distance)
+	if result.Stale {
+		asOf := result.AsOf.UTC()
+		resp.Stale = true
+		resp.AsOf = &asOf
+	}
+	resp.Approximate = result.Approximate
+	return resp
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"slices"
+)
+
+// LocationBody is implemented by every response body that shows stored
+// locations, so that coordinate privacy and external IDs reach each of
+// them without knowing the body's layout. A body that carries a location
+// without implementing it would show that location as stored.
+type LocationBody interface {
+	// MapLocations returns a copy of the body with fn applied to the ID
+	// and coordinates of each location it shows. latitude and longitude
+	// are nil for entries that show only an ID. The body is not modified.
+	MapLocations(fn func(id *string, latitude, longitude *float64)) any
+}
+
+// MapLocations applies fn to the locations in body, as LocationBody does,
+// including locations listed in the details of an error. Bodies that
+// carry no locations are returned as they are.
+func MapLocations(body any, fn func(id *string, latitude, longitude *float64)) any {
+	if b, ok := body.(LocationBody); ok {
+		return b.MapLocations(fn)
+	}
+	if mapped, ok := mapErrorDetails(body, func(v any) any { return MapLocations(v, fn) }); ok {
+		return mapped
+	}
+	return body
+}
+
+func (l LocationResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	return l.mapLocation(fn)
+}
+
+func (l LocationResponse) mapLocation(fn func(id *string, latitude, longitude *float64)) LocationResponse {
+	fn(&l.ID, &l.Latitude, &l.Longitude)
+	return l
+}
+
+func mapLocationList(ls []LocationResponse, fn func(id *string, latitude, longitude *float64)) []LocationResponse {
+	ls = slices.Clone(ls)
+	for i := range ls {
+		ls[i] = ls[i].mapLocation(fn)
+	}
+	return ls
+}
+
+func (b NearestLocationResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	b.Location = b.Location.mapLocation(fn)
+	return b
+}
+
+func (b LocationListResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	b.Locations = mapLocationList(b.Locations, fn)
+	return b
+}
+
+func (b SuggestResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	b.Suggestions = slices.Clone(b.Suggestions)
+	for i := range b.Suggestions {
+		s := &b.Suggestions[i]
+		fn(&s.ID, &s.Latitude, &s.Longitude)
+	}
+	return b
+}
+
+func (b TransactionResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	b.Results = slices.Clone(b.Results)
+	for i := range b.Results {
+		b.Results[i].Location = b.Results[i].Location.mapLocation(fn)
+	}
+	return b
+}
+
+func (b ChangeFeedResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	b.Changes = slices.Clone(b.Changes)
+	for i := range b.Changes {
+		b.Changes[i].Location = b.Changes[i].Location.mapLocation(fn)
+	}
+	return b
+}
+
+func (b DuplicateReportResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	b.Clusters = slices.Clone(b.Clusters)
+	for i := range b.Clusters {
+		b.Clusters[i].Locations = mapLocationList(b.Clusters[i].Locations, fn)
+	}
+	return b
+}
+
+func (b MergeResponse) MapLocations(fn func(id *string, latitude, longitude *float64)) any {
+	b.Losers = mapLocationList(b.Losers, fn)
+	return b
+}
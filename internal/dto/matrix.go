@@ -0,0 +1,31 @@
+package dto
+
+// MatrixPoint is one origin or destination: a stored location named by its
+// name or alias, or a pair of coordinates
+type MatrixPoint struct {
+	Name      string   `json:"name,omitempty" maxLength:"255" example:"Leeta Lekki Phase 1" doc:"Name or alias of a stored location; give either this or both coordinates"`
+	Latitude  *float64 `json:"latitude,omitempty" minimum:"-90" maximum:"90" example:"6.4474" doc:"Latitude in decimal degrees"`
+	Longitude *float64 `json:"longitude,omitempty" minimum:"-180" maximum:"180" example:"3.4723" doc:"Longitude in decimal degrees"`
+}
+
+type DistanceMatrixRequest struct {
+	Origins      []MatrixPoint `json:"origins" minItems:"1" doc:"Points to measure from, one matrix row each"`
+	Destinations []MatrixPoint `json:"destinations" minItems:"1" doc:"Points to measure to, one matrix column each"`
+	Unit         string        `json:"unit,omitempty" enum:"km,m,mi,nmi" doc:"Unit of the distances: kilometres, metres, miles or nautical miles. Defaults to the caller's preferred unit, or the server's"`
+}
+
+// MatrixEntryError reports an origin or destination that could not be
+// resolved; its row or column holds nulls
+type MatrixEntryError struct {
+	List    string `json:"list" enum:"origins,destinations" example:"destinations" doc:"List the entry is in"`
+	Index   int    `json:"index" example:"2" doc:"Position of the entry in its list"`
+	Name    string `json:"name" example:"Leeta Ikoyi" doc:"Name that was not found"`
+	Code    string `json:"code" example:"LOCATION_NOT_FOUND" doc:"Machine-readable reason"`
+	Message string `json:"message" example:"Location not found"`
+}
+
+type DistanceMatrixResponse struct {
+	Unit      string             `json:"unit" example:"km" doc:"Unit of the distances"`
+	Distances [][]*float64       `json:"distances" doc:"One row per origin and one column per destination, great-circle distances; null where either entry could not be resolved"`
+	Errors    []MatrixEntryError `json:"errors" doc:"Entries that could not be resolved"`
+}
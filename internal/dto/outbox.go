@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type OutboxEventResponse struct {
+	Sequence      int64  `json:"sequence"`
+	EventID       string `json:"event_id"`
+	Type          string `json:"type"`
+	LocationName  string `json:"location_name"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt string `json:"next_attempt_at"`
+}
+
+type OutboxListResponse struct {
+	Events               []OutboxEventResponse `json:"events"`
+	Pending              int                   `json:"pending"`
+	Failed               int                   `json:"failed"`
+	OldestPendingSeconds float64               `json:"oldest_pending_seconds"`
+}
+
+func FromOutboxEvents(events []domain.OutboxEvent, stats domain.OutboxStats) OutboxListResponse {
+	responses := make([]OutboxEventResponse, len(events))
+	for i, e := range events {
+		responses[i] = OutboxEventResponse{
+			Sequence:      e.Sequence,
+			EventID:       e.Event.ID,
+			Type:          string(e.Event.Type),
+			LocationName:  e.Event.Location.Name,
+			Status:        string(e.Status),
+			Attempts:      e.Attempts,
+			LastError:     e.LastError,
+			NextAttemptAt: e.NextAttemptAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+
+	return OutboxListResponse{
+		Events:               responses,
+		Pending:              stats.Pending,
+		Failed:               stats.Failed,
+		OldestPendingSeconds: stats.OldestPending.Seconds(),
+	}
+}
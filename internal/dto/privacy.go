@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// Coordinate privacy modes
+const (
+	PrivacyOff    = "off"
+	PrivacyGrid   = "grid"
+	PrivacyJitter = "jitter"
+)
+
+// CoordinatePrivacy coarsens the coordinates responses show, for callers
+// that should not see exact station positions. Distances are computed
+// before it applies, so they stay exact.
+type CoordinatePrivacy struct {
+	// Mode is PrivacyGrid, PrivacyJitter or PrivacyOff
+	Mode string
+	// Meters is the grid cell size, or the largest jitter offset
+	Meters float64
+	// Secret keys the jitter so callers cannot compute and undo it from a
+	// location's ID
+	Secret []byte
+}
+
+// Enabled reports whether the privacy changes coordinates at all
+func (p CoordinatePrivacy) Enabled() bool {
+	return (p.Mode == PrivacyGrid || p.Mode == PrivacyJitter) && p.Meters > 0
+}
+
+// obscure returns the coordinates to show for a location, rounded to places
+func (p CoordinatePrivacy) obscure(id string, latitude, longitude float64, places int) (float64, float64) {
+	c := geospatial.Coordinate{Latitude: latitude, Longitude: longitude}
+	switch p.Mode {
+	case PrivacyGrid:
+		c = geospatial.SnapToGrid(c, p.Meters)
+	case PrivacyJitter:
+		mac := hmac.New(sha256.New, p.Secret)
+		mac.Write([]byte(id))
+		c = geospatial.Jitter(c, mac.Sum(nil), p.Meters)
+	}
+	return geospatial.RoundCoordinate(c.Latitude, places), geospatial.RoundCoordinate(c.Longitude, places)
+}
+
+type coordinatePrivacyKey struct{}
+
+// WithCoordinatePrivacy returns a copy of ctx whose responses are obscured
+// by p
+func WithCoordinatePrivacy(ctx context.Context, p CoordinatePrivacy) context.Context {
+	return context.WithValue(ctx, coordinatePrivacyKey{}, p)
+}
+
+// CoordinatePrivacyFromContext returns the privacy for a request, or the
+// zero value, which leaves coordinates exact
+func CoordinatePrivacyFromContext(ctx context.Context) CoordinatePrivacy {
+	p, _ := ctx.Value(coordinatePrivacyKey{}).(CoordinatePrivacy)
+	return p
+}
+
+// ObscureCoordinates returns body with the coordinates of every location
+// in it obscured by the privacy in ctx, through LocationBody. Bodies that
+// carry no locations, and requests without privacy, are returned as they
+// are. body is not modified.
+func ObscureCoordinates(ctx context.Context, body any) any {
+	p := CoordinatePrivacyFromContext(ctx)
+	if !p.Enabled() {
+		return body
+	}
+	places := CoordinatePrecision(ctx)
+	return MapLocations(body, func(id *string, latitude, longitude *float64) {
+		if latitude != nil && longitude != nil {
+			*latitude, *longitude = p.obscure(*id, *latitude, *longitude, places)
+		}
+	})
+}
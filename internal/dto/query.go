@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// QueryFilter is the filter a saved query runs. Its fields are the location
+// listing's filter parameters, under the same names.
+type QueryFilter struct {
+	CreatedAfter       *time.Time `json:"created_after,omitempty" example:"2025-08-01T00:00:00Z" doc:"Only locations created at or after this RFC 3339 time"`
+	CreatedBefore      *time.Time `json:"created_before,omitempty" example:"2025-09-01T00:00:00Z" doc:"Only locations created at or before this RFC 3339 time; must be later than created_after"`
+	NameContains       string     `json:"name_contains,omitempty" maxLength:"100" example:"lek" doc:"Only locations whose name contains this text, ignoring case"`
+	SearchDescriptions bool       `json:"search_descriptions,omitempty" doc:"Also match name_contains against descriptions"`
+	BBox               string     `json:"bbox,omitempty" example:"3.3,6.4,3.5,6.55" doc:"Only locations inside min_lng,min_lat,max_lng,max_lat"`
+	Region             string     `json:"region,omitempty" maxLength:"64" example:"Lagos" doc:"Only locations in this region"`
+	OpenAt             *time.Time `json:"open_at,omitempty" example:"2025-08-18T21:30:00+01:00" doc:"Only locations open at this RFC 3339 time"`
+	OpenNow            bool       `json:"open_now,omitempty" doc:"Only locations open when the query runs; cannot be combined with open_at"`
+}
+
+// SaveQueryRequest names a filter to keep
+type SaveQueryRequest struct {
+	Name   string      `json:"name" minLength:"1" maxLength:"100" pattern:"^[A-Za-z0-9][A-Za-z0-9_.-]*$" example:"lagos-august" doc:"Name the query is run by; letters, digits, '.', '_' and '-'"`
+	Filter QueryFilter `json:"filter" doc:"Filter the query runs"`
+}
+
+// UpdateQueryRequest replaces a saved query's filter
+type UpdateQueryRequest struct {
+	Filter QueryFilter `json:"filter" doc:"Filter the query runs"`
+}
+
+// SavedQueryResponse is a saved query as stored
+type SavedQueryResponse struct {
+	Name      string         `json:"name" example:"lagos-august"`
+	Filter    map[string]any `json:"filter" doc:"The filter document as saved"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// SavedQueryListResponse lists saved queries by name
+type SavedQueryListResponse struct {
+	Queries []SavedQueryResponse `json:"queries"`
+}
+
+// FromSavedQuery converts a saved query. The filter is shown as stored,
+// even when it no longer decodes as a QueryFilter.
+func FromSavedQuery(query *domain.SavedQuery) SavedQueryResponse {
+	filter := map[string]any{}
+	json.Unmarshal(query.Filter, &filter)
+	return SavedQueryResponse{
+		Name:      query.Name,
+		Filter:    filter,
+		CreatedAt: query.CreatedAt,
+		UpdatedAt: query.UpdatedAt,
+	}
+}
+
+// FromSavedQueries converts saved queries in order
+func FromSavedQueries(queries []*domain.SavedQuery) SavedQueryListResponse {
+	responses := make([]SavedQueryResponse, len(queries))
+	for i, query := range queries {
+		responses[i] = FromSavedQuery(query)
+	}
+	return SavedQueryListResponse{Queries: responses}
+}
@@ -0,0 +1,47 @@
+package dto
+
+import "github.com/jesuloba-world/leeta-task/internal/domain"
+
+// ReadinessResponse describes readiness and the checks behind it
+type ReadinessResponse struct {
+	Ready                bool                          `json:"ready" example:"true" doc:"Whether the instance should receive traffic"`
+	ConsecutiveFailures  int                           `json:"consecutive_failures" example:"0" doc:"Failed checks in a row"`
+	ConsecutiveSuccesses int                           `json:"consecutive_successes" example:"12" doc:"Successful checks in a row"`
+	History              []ReadinessCheckResponse      `json:"history,omitempty" doc:"Recent dependency checks, oldest first"`
+	Transitions          []ReadinessTransitionResponse `json:"transitions,omitempty" doc:"Recent changes of readiness, oldest first"`
+}
+
+// ReadinessCheckResponse is one dependency check
+type ReadinessCheckResponse struct {
+	At         string  `json:"at" example:"2025-09-08T09:00:00Z" doc:"When the check ran"`
+	DurationMs float64 `json:"duration_ms" example:"1.4" doc:"How long the check took"`
+	Error      string  `json:"error,omitempty" doc:"Why the check failed"`
+}
+
+// ReadinessTransitionResponse is one change of readiness
+type ReadinessTransitionResponse struct {
+	At    string `json:"at" example:"2025-09-08T09:00:00Z" doc:"When readiness changed"`
+	Ready bool   `json:"ready" example:"false" doc:"Readiness from then on"`
+}
+
+func FromReadinessStatus(status domain.ReadinessStatus) *ReadinessResponse {
+	response := &ReadinessResponse{
+		Ready:                status.Ready,
+		ConsecutiveFailures:  status.ConsecutiveFailures,
+		ConsecutiveSuccesses: status.ConsecutiveSuccesses,
+	}
+	for _, check := range status.History {
+		response.History = append(response.History, ReadinessCheckResponse{
+			At:         formatOptionalTime(check.At),
+			DurationMs: float64(check.Duration.Microseconds()) / 1000,
+			Error:      check.Error,
+		})
+	}
+	for _, transition := range status.Transitions {
+		response.Transitions = append(response.Transitions, ReadinessTransitionResponse{
+			At:    formatOptionalTime(transition.At),
+			Ready: transition.Ready,
+		})
+	}
+	return response
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// SearchSettingsRequest replaces the stored search settings
+type SearchSettingsRequest struct {
+	SpeedKmh      float64 `json:"speed_kmh" minimum:"0" example:"30" doc:"Speed in km/h nearest responses estimate eta_minutes with when the request gives none; 0 leaves ETAs off"`
+	MaxDistanceKm float64 `json:"max_distance_km" minimum:"0" example:"25" doc:"Furthest a nearest location may be, in km, when the request gives no max_distance_km; 0 means no limit"`
+}
+
+// SearchSettingsResponse is the search settings in effect
+type SearchSettingsResponse struct {
+	SpeedKmh      float64    `json:"speed_kmh" example:"30"`
+	MaxDistanceKm float64    `json:"max_distance_km" example:"25"`
+	Source        string     `json:"source" enum:"stored,config" example:"stored" doc:"stored once settings have been saved, config while the server's configured defaults apply"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty" doc:"When the stored settings were saved, absent for config"`
+}
+
+// FromSearchSettings converts the settings in effect; stored says whether
+// they were saved or are the configured defaults
+func FromSearchSettings(settings domain.SearchSettings, stored bool) SearchSettingsResponse {
+	resp := SearchSettingsResponse{SpeedKmh: settings.SpeedKmh, MaxDistanceKm: settings.MaxDistanceKm, Source: "config"}
+	if stored {
+		resp.Source = "stored"
+		resp.UpdatedAt = &settings.UpdatedAt
+	}
+	return resp
+}
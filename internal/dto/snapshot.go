@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// SnapshotVersion is the format version written by exports
+const SnapshotVersion = 1
+
+type SnapshotLocation struct {
+	ID           string               `json:"id" example:"42" doc:"Location ID, kept on restore"`
+	Name         string               `json:"name" example:"Leeta Lekki Phase 1"`
+	Latitude     float64              `json:"latitude" example:"6.4474"`
+	Longitude    float64              `json:"longitude" example:"3.472"`
+	CreatedAt    time.Time            `json:"created_at" example:"2025-08-18T10:00:00Z" doc:"Creation time, kept on restore"`
+	OpeningHours *domain.OpeningHours `json:"opening_hours,omitempty" doc:"Weekly opening hours, absent when unknown"`
+	Description  string               `json:"description,omitempty" doc:"Free-text notes"`
+	Aliases      []string             `json:"aliases,omitempty" doc:"Alternate names, restored unless another location already uses one"`
+	Region       string               `json:"region,omitempty" example:"Lagos" doc:"Operating region"`
+	Attachments  []domain.Attachment  `json:"attachments,omitempty" doc:"Photos and documents, restored as exported"`
+	// Stock is restored as exported, as of the export
+	CapacityLitres     *float64 `json:"capacity_litres,omitempty" example:"33000" doc:"Fuel storage capacity in litres"`
+	CurrentStockLitres *float64 `json:"current_stock_litres,omitempty" example:"12500" doc:"Fuel held when the snapshot was taken, in litres"`
+	CreatedBy          string   `json:"created_by,omitempty" example:"fleet-app" doc:"Principal that created the location, kept on restore"`
+}
+
+type Snapshot struct {
+	Version    int                `json:"version" enum:"1" example:"1" doc:"Snapshot format version"`
+	ExportedAt time.Time          `json:"exported_at,omitempty" example:"2025-08-18T10:00:00Z" doc:"Time the snapshot was taken"`
+	CRS        int                `json:"crs,omitempty" enum:"4326,3857,32631,32632,32633" example:"32631" doc:"EPSG code of the record coordinates on restore; exports are always 4326. With a projected code each record's longitude holds its x and latitude its y, in metres"`
+	Locations  []SnapshotLocation `json:"locations"`
+}
+
+type RestoreConflictResponse struct {
+	Index  int    `json:"index" example:"3" doc:"Position of the record in the snapshot"`
+	ID     string `json:"id" example:"42"`
+	Name   string `json:"name" example:"Leeta Lekki Phase 1"`
+	Reason string `json:"reason" enum:"id_exists,name_exists,invalid" example:"id_exists" doc:"Why the record was skipped"`
+}
+
+type RestoreResponse struct {
+	Restored  int                       `json:"restored" example:"120" doc:"Number of records restored"`
+	Conflicts []RestoreConflictResponse `json:"conflicts" doc:"Records skipped, each with its reason"`
+}
+
+// ToSnapshot captures locations with their stored coordinates, unrounded
+func ToSnapshot(locations []*domain.Location, exportedAt time.Time) Snapshot {
+	records := make([]SnapshotLocation, len(locations))
+	for i, l := range locations {
+		records[i] = SnapshotLocation{
+			ID:           l.ID,
+			Name:         l.Name,
+			Latitude:     l.Latitude,
+			Longitude:    l.Longitude,
+			CreatedAt:    l.CreatedAt.UTC(),
+			OpeningHours: l.OpeningHours,
+			Description:  l.Description,
+			Aliases:      l.Aliases,
+			Region:       l.Region,
+			Attachments:  l.Attachments,
+
+			CapacityLitres:     l.CapacityLitres,
+			CurrentStockLitres: l.CurrentStockLitres,
+			CreatedBy:          l.CreatedBy,
+		}
+	}
+	return Snapshot{Version: SnapshotVersion, ExportedAt: exportedAt.UTC(), Locations: records}
+}
+
+// ToDomain converts snapshot records for a restore
+func (s Snapshot) ToDomain() []domain.Location {
+	locations := make([]domain.Location, len(s.Locations))
+	for i, l := range s.Locations {
+		locations[i] = domain.Location(l)
+	}
+	return locations
+}
+
+func FromRestoreResult(result *domain.RestoreResult) RestoreResponse {
+	conflicts := make([]RestoreConflictResponse, len(result.Conflicts))
+	for i, c := range result.Conflicts {
+		conflicts[i] = RestoreConflictResponse(c)
+	}
+	return RestoreResponse{Restored: result.Restored, Conflicts: conflicts}
+}
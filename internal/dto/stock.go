@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/jesuloba-world/leeta-task/internal/domain"
+
+// StockRequest is the body for updating a station's stock. Fields left out
+// are not changed.
+type StockRequest struct {
+	CapacityLitres *float64 `json:"capacity_litres,omitempty" required:"false" minimum:"0" example:"33000" doc:"Fuel storage capacity in litres"`
+	StockLitres    *float64 `json:"current_stock_litres,omitempty" required:"false" minimum:"0" example:"12500" doc:"Fuel the station now holds in litres, at most its capacity"`
+	AdjustLitres   float64  `json:"adjust_litres,omitempty" example:"-1200" doc:"Litres to add to the stock, negative for fuel sold, applied after current_stock_litres. Concurrent adjustments all count"`
+}
+
+func (r StockRequest) ToDomain() domain.StockUpdate {
+	return domain.StockUpdate{CapacityLitres: r.CapacityLitres, StockLitres: r.StockLitres, AdjustLitres: r.AdjustLitres}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/jesuloba-world/leeta-task/internal/domain"
+
+// StoreStatsResponse summarizes the in-memory store on verbose health checks
+type StoreStatsResponse struct {
+	Locations           int                  `json:"locations" example:"1200" doc:"Locations held"`
+	EstimatedBytes      int64                `json:"estimated_bytes" example:"540000" doc:"Estimated memory taken by the locations and their primary indexes"`
+	Indexes             []IndexStatsResponse `json:"indexes" doc:"Bucket counts of each index"`
+	Saves               uint64               `json:"saves" example:"1200" doc:"Saves since startup"`
+	MeanSaveSeconds     float64              `json:"mean_save_seconds" example:"0.000004" doc:"Average save duration"`
+	Deletes             uint64               `json:"deletes" example:"3" doc:"Deletes since startup"`
+	MeanDeleteSeconds   float64              `json:"mean_delete_seconds" example:"0.000003" doc:"Average delete duration"`
+	LockWaitSamples     uint64               `json:"lock_wait_samples" example:"75" doc:"Lock acquisitions timed, one in every 16"`
+	MeanLockWaitSeconds float64              `json:"mean_lock_wait_seconds" example:"0.0000002" doc:"Average wait of the timed acquisitions"`
+	Removed             int                  `json:"removed" example:"40" doc:"Locations removed since the indexes were last rebuilt"`
+	Compactions         uint64               `json:"compactions" example:"2" doc:"Index rebuilds since startup"`
+	Reclaimed           uint64               `json:"reclaimed" example:"9000" doc:"Removed entries the rebuilds cleared"`
+}
+
+// IndexStatsResponse summarizes one index's buckets
+type IndexStatsResponse struct {
+	Name    string `json:"name" example:"region" doc:"Index name: region, or grid when a soft nearest scan limit is set"`
+	Buckets int    `json:"buckets" example:"4" doc:"Number of buckets"`
+	Largest int    `json:"largest" example:"800" doc:"Locations in the fullest bucket"`
+}
+
+func FromStoreStats(stats domain.StoreStats) *StoreStatsResponse {
+	waits := domain.OperationStats{
+		Count: stats.ReadLockWaits.Count + stats.WriteLockWaits.Count,
+		Total: stats.ReadLockWaits.Total + stats.WriteLockWaits.Total,
+	}
+	response := &StoreStatsResponse{
+		Locations:           stats.Locations,
+		EstimatedBytes:      stats.EstimatedBytes,
+		Indexes:             make([]IndexStatsResponse, len(stats.Indexes)),
+		Saves:               stats.Saves.Count,
+		MeanSaveSeconds:     stats.Saves.Mean().Seconds(),
+		Deletes:             stats.Deletes.Count,
+		MeanDeleteSeconds:   stats.Deletes.Mean().Seconds(),
+		LockWaitSamples:     waits.Count,
+		MeanLockWaitSeconds: waits.Mean().Seconds(),
+		Removed:             stats.Removed,
+		Compactions:         stats.Compactions.Count,
+		Reclaimed:           stats.Reclaimed,
+	}
+	for i, index := range stats.Indexes {
+		response.Indexes[i] = IndexStatsResponse{Name: index.Name, Buckets: len(index.Sizes), Largest: index.Largest()}
+	}
+	return response
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// SuggestionResponse is one location suggested for a partial name
+type SuggestionResponse struct {
+	ID             string              `json:"id" example:"1"`
+	Name           string              `json:"name" example:"Leeta Lekki Phase 1"`
+	Latitude       float64             `json:"latitude" example:"6.4474"`
+	Longitude      float64             `json:"longitude" example:"3.4723"`
+	Distance       geospatial.Distance `json:"distance_km" example:"2.37" doc:"Great-circle distance from the query point in kilometres"`
+	DistanceInUnit float64             `json:"distance" example:"1.47" doc:"distance_km in the response's unit"`
+	Score          float64             `json:"score" example:"0.93" doc:"Relevance from 0 to 1, combining the name match with nearness"`
+	Match          *MatchRange         `json:"match,omitempty" doc:"Characters of the name that matched the query, for highlighting; absent when the name only resembles it"`
+}
+
+// MatchRange locates the matched part of a name in Unicode code points
+type MatchRange struct {
+	Start int `json:"start" example:"6" doc:"Offset of the first matched character"`
+	End   int `json:"end" example:"11" doc:"Offset after the last matched character"`
+}
+
+// SuggestResponse lists suggestions, most relevant first
+type SuggestResponse struct {
+	Suggestions []SuggestionResponse `json:"suggestions"`
+	Unit        string               `json:"unit" enum:"km,m,mi,nmi" example:"mi" doc:"Unit of each suggestion's distance: the request's unit parameter, else the caller's preferred unit, else the server's"`
+}
+
+// FromSuggestions converts suggestions for query to their response,
+// locating the matched part of each name
+func FromSuggestions(query string, suggestions []domain.Suggestion) SuggestResponse {
+	response := SuggestResponse{Suggestions: make([]SuggestionResponse, len(suggestions))}
+	for i, s := range suggestions {
+		suggestion := SuggestionResponse{
+			ID:        s.Location.ID,
+			Name:      s.Location.Name,
+			Latitude:  s.Location.Latitude,
+			Longitude: s.Location.Longitude,
+			Distance:  s.Distance,
+			Score:     s.Score,
+		}
+		if match, ok := domain.SuggestMatch(s.Location.Name, query); ok {
+			suggestion.Match = &MatchRange{Start: match.Start, End: match.End}
+		}
+		response.Suggestions[i] = suggestion
+	}
+	return response
+}
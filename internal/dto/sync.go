@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// SyncRequest names the instance to copy locations from
+type SyncRequest struct {
+	SourceURL string `json:"source_url" format:"uri" maxLength:"2048" example:"https://locations.leeta.ng" doc:"Base URL of the instance to copy, as its Go client takes it"`
+	APIKey    string `json:"api_key,omitempty" maxLength:"256" doc:"API key sent to the source; give it the exact scope if the source obscures coordinates"`
+	DryRun    bool   `json:"dry_run,omitempty" doc:"Report the planned changes without applying them"`
+}
+
+type SyncChangeResponse struct {
+	Action string `json:"action" enum:"create,update,delete" example:"update"`
+	Name   string `json:"name" example:"Leeta Lekki Phase 1"`
+}
+
+type SyncConflictResponse struct {
+	Name   string `json:"name" example:"Leeta Lekki Phase 1"`
+	Action string `json:"action,omitempty" enum:"create,update,delete,alias" example:"create" doc:"Change that failed, absent for a source record that was not planned"`
+	Reason string `json:"reason" enum:"duplicate_name,rejected" example:"rejected" doc:"duplicate_name when the source lists the name twice, rejected when this server refused the change"`
+	Detail string `json:"detail,omitempty" example:"region is required" doc:"Why the change was refused"`
+}
+
+// SyncResponse summarizes a sync. In a dry run the counts and changes are
+// planned; otherwise they were applied.
+type SyncResponse struct {
+	DryRun    bool                   `json:"dry_run"`
+	Source    int                    `json:"source" example:"120" doc:"Number of locations read from the source"`
+	Created   int                    `json:"created" example:"3"`
+	Updated   int                    `json:"updated" example:"1"`
+	Deleted   int                    `json:"deleted" example:"2"`
+	Unchanged int                    `json:"unchanged" example:"116"`
+	Changes   []SyncChangeResponse   `json:"changes" doc:"Planned changes in the order they are applied: deletes, updates, then creates"`
+	Conflicts []SyncConflictResponse `json:"conflicts" doc:"Source records and changes that were not applied"`
+}
+
+// ToDomain converts a location read from another instance. Distance is
+// dropped; ID and CreatedAt are kept but a sync does not copy them.
+func (r LocationResponse) ToDomain() domain.Location {
+	return domain.Location{
+		ID:           r.ID,
+		Name:         r.Name,
+		Latitude:     r.Latitude,
+		Longitude:    r.Longitude,
+		CreatedAt:    r.CreatedAt,
+		OpeningHours: r.OpeningHours,
+		Description:  r.Description,
+		Aliases:      r.Aliases,
+		Region:       r.Region,
+		Attachments:  r.Attachments,
+	}
+}
+
+func FromSyncResult(result *domain.SyncResult) SyncResponse {
+	changes := make([]SyncChangeResponse, len(result.Changes))
+	for i, change := range result.Changes {
+		changes[i] = SyncChangeResponse{Action: change.Action, Name: change.Name}
+	}
+	conflicts := make([]SyncConflictResponse, len(result.Conflicts))
+	for i, c := range result.Conflicts {
+		conflicts[i] = SyncConflictResponse(c)
+	}
+	return SyncResponse{
+		DryRun:    result.DryRun,
+		Source:    result.Source,
+		Created:   result.Created,
+		Updated:   result.Updated,
+		Deleted:   result.Deleted,
+		Unchanged: result.Unchanged,
+		Changes:   changes,
+		Conflicts: conflicts,
+	}
+}
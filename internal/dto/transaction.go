@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// TransactionRequest is the body for applying several location changes at
+// once, all or none
+type TransactionRequest struct {
+	Operations []OperationRequest `json:"operations" minItems:"1" maxItems:"100" doc:"Operations applied in order; if any fails, none is kept"`
+}
+
+// OperationRequest is one step of a transaction. A create carries
+// location; an update carries name and changes; a delete carries name.
+type OperationRequest struct {
+	Op       string                  `json:"op" enum:"create,update,delete" example:"create" doc:"What the operation does"`
+	Name     string                  `json:"name,omitempty" maxLength:"255" example:"Leeta Lekki Phase 1" doc:"Name or alias of the location to update or delete; names created or renamed by earlier operations in the same transaction are found"`
+	Location *LocationRequest        `json:"location,omitempty" doc:"Location to create, as the body of POST /locations"`
+	Changes  *LocationChangesRequest `json:"changes,omitempty" doc:"Fields an update sets; fields left out keep their value"`
+}
+
+// LocationChangesRequest lists the fields an update sets
+type LocationChangesRequest struct {
+	Name         *string              `json:"name,omitempty" minLength:"1" maxLength:"255" example:"Leeta Lekki Phase 2" doc:"New name; the old name is no longer found and the location keeps its ID"`
+	Latitude     *float64             `json:"latitude,omitempty" minimum:"-90" maximum:"90" example:"6.4474" doc:"Latitude in decimal degrees"`
+	Longitude    *float64             `json:"longitude,omitempty" minimum:"-180" maximum:"180" example:"3.4723" doc:"Longitude in decimal degrees"`
+	Description  *string              `json:"description,omitempty" doc:"Free-text notes; empty clears them"`
+	Region       *string              `json:"region,omitempty" maxLength:"64" example:"Lagos" doc:"Operating region; empty clears it unless a region is required"`
+	OpeningHours *domain.OpeningHours `json:"opening_hours,omitempty" doc:"Weekly opening hours, replacing any set before"`
+}
+
+// OperationResultResponse reports one applied operation
+type OperationResultResponse struct {
+	Op       string           `json:"op" example:"create" doc:"What the operation did"`
+	Location LocationResponse `json:"location" doc:"The location as created or updated, or as it was when deleted"`
+}
+
+// TransactionResponse lists the result of every operation, in order
+type TransactionResponse struct {
+	Results []OperationResultResponse `json:"results"`
+}
+
+// ToDomain converts the fields an update sets
+func (req *LocationChangesRequest) ToDomain() domain.LocationChanges {
+	return domain.LocationChanges{
+		Name:         req.Name,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		Description:  req.Description,
+		Region:       req.Region,
+		OpeningHours: req.OpeningHours,
+	}
+}
+
+// FromOperationResults converts applied operations to their response
+func FromOperationResults(results []domain.OperationResult) TransactionResponse {
+	response := TransactionResponse{Results: make([]OperationResultResponse, len(results))}
+	for i, result := range results {
+		response.Results[i] = OperationResultResponse{Op: string(result.Type), Location: FromDomain(&result.Location)}
+	}
+	return response
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type TruncateRequest struct {
+	Confirm string `json:"confirm" minLength:"1" example:"qa" doc:"Name of the environment being truncated, as configured by ENVIRONMENT_NAME"`
+}
+
+type TruncateResponse struct {
+	DryRun      bool       `json:"dry_run" example:"false" doc:"Whether the locations were only counted"`
+	Count       int        `json:"count" example:"1834" doc:"Locations removed, or that would be removed on a dry run"`
+	ID          string     `json:"id,omitempty" example:"3" doc:"Audit entry identifier; absent on a dry run"`
+	Actor       string     `json:"actor,omitempty" example:"qa-runner" doc:"Caller that truncated the locations"`
+	TruncatedAt *time.Time `json:"truncated_at,omitempty" example:"2025-09-07T09:00:00Z" doc:"Time of the truncation; absent on a dry run"`
+}
+
+func FromTruncateAudit(audit *domain.TruncateAudit) TruncateResponse {
+	return TruncateResponse{
+		Count:       audit.Count,
+		ID:          audit.ID,
+		Actor:       audit.Actor,
+		TruncatedAt: &audit.TruncatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// Distance units responses can give distances in
+const (
+	UnitKilometers    = "km"
+	UnitMeters        = "m"
+	UnitMiles         = "mi"
+	UnitNauticalMiles = "nmi"
+)
+
+// DistanceUnits lists the distance units, kilometres first
+var DistanceUnits = []string{UnitKilometers, UnitMeters, UnitMiles, UnitNauticalMiles}
+
+// ValidUnit reports whether unit names one of the distance units
+func ValidUnit(unit string) bool {
+	return slices.Contains(DistanceUnits, unit)
+}
+
+// InUnit expresses a distance in one of the distance units, kilometres
+// unless unit names another
+func InUnit(d geospatial.Distance, unit string) float64 {
+	switch unit {
+	case UnitMeters:
+		return d.Meters()
+	case UnitMiles:
+		return d.Miles()
+	case UnitNauticalMiles:
+		return d.NauticalMiles()
+	}
+	return d.Kilometers()
+}
+
+type defaultUnitKey struct{}
+
+// WithDefaultUnit returns a copy of ctx whose responses give distances in
+// unit when neither the request nor the caller's profile names one;
+// unknown units are ignored
+func WithDefaultUnit(ctx context.Context, unit string) context.Context {
+	if !ValidUnit(unit) {
+		return ctx
+	}
+	return context.WithValue(ctx, defaultUnitKey{}, unit)
+}
+
+type preferredUnitKey struct{}
+
+// WithPreferredUnit returns a copy of ctx whose responses give distances in
+// unit when the request names none
+func WithPreferredUnit(ctx context.Context, unit string) context.Context {
+	return context.WithValue(ctx, preferredUnitKey{}, unit)
+}
+
+// DistanceUnit returns the unit a response gives distances in: explicit,
+// which the request named, else the caller's preferred unit in ctx, else
+// the server's default in ctx, else kilometres
+func DistanceUnit(ctx context.Context, explicit string) string {
+	if ValidUnit(explicit) {
+		return explicit
+	}
+	if preferred, _ := ctx.Value(preferredUnitKey{}).(string); ValidUnit(preferred) {
+		return preferred
+	}
+	if unit, ok := ctx.Value(defaultUnitKey{}).(string); ok {
+		return unit
+	}
+	return UnitKilometers
+}
+
+// InUnit adds the distance in unit alongside distance_km
+func (r *NearestLocationResponse) InUnit(unit string) {
+	r.DistanceInUnit = InUnit(r.Distance, unit)
+	r.Unit = unit
+}
+
+// InUnit adds each location's distance in unit alongside distance_km. A
+// list without distances is left as it is.
+func (l *LocationListResponse) InUnit(unit string) {
+	for i := range l.Locations {
+		if d := l.Locations[i].Distance; d != nil {
+			distance := InUnit(*d, unit)
+			l.Locations[i].DistanceInUnit = &distance
+			l.Unit = unit
+		}
+	}
+}
+
+// InUnit adds each suggestion's distance in unit alongside distance_km
+func (r *SuggestResponse) InUnit(unit string) {
+	for i := range r.Suggestions {
+		r.Suggestions[i].DistanceInUnit = InUnit(r.Suggestions[i].Distance, unit)
+	}
+	r.Unit = unit
+}
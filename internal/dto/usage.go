@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type UsageRecordResponse struct {
+	Key       string `json:"key"`
+	Tenant    string `json:"tenant,omitempty"`
+	Operation string `json:"operation"`
+	Day       string `json:"day" example:"2025-08-10"`
+	Count     int64  `json:"count"`
+}
+
+type UsageResponse struct {
+	From    string                `json:"from" example:"2025-08-01"`
+	To      string                `json:"to" example:"2025-08-31"`
+	Total   int64                 `json:"total"`
+	Records []UsageRecordResponse `json:"records"`
+}
+
+const usageDayFormat = "2006-01-02"
+
+func FromUsageRecords(records []domain.UsageRecord, from, to string) UsageResponse {
+	responses := make([]UsageRecordResponse, len(records))
+	var total int64
+	for i, record := range records {
+		responses[i] = UsageRecordResponse{
+			Key:       record.Key,
+			Tenant:    record.Tenant,
+			Operation: record.Operation,
+			Day:       record.Day.Format(usageDayFormat),
+			Count:     record.Count,
+		}
+		total += record.Count
+	}
+
+	return UsageResponse{
+		From:    from,
+		To:      to,
+		Total:   total,
+		Records: responses,
+	}
+}
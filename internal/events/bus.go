@@ -0,0 +1,44 @@
+package events
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// Handler receives published events
+type Handler func(event domain.Event) error
+
+// Bus fans events out to in-process subscribers such as webhooks and SSE streams
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for every subsequently published event
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers the event to every subscriber, returning their joined
+// errors so the outbox dispatcher can schedule a retry
+func (b *Bus) Publish(event domain.Event) error {
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
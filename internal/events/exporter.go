@@ -0,0 +1,24 @@
+package events
+
+import (
+	"log/slog"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+// Exporter subscribes publisher to the bus under the backend name. Every
+// export is counted, and failures are logged and returned so the outbox
+// dispatcher can retry; without an outbox the service only logs them, so
+// a failed export never fails the request that caused it.
+func Exporter(backend string, publisher domain.EventPublisher) Handler {
+	return func(event domain.Event) error {
+		if err := publisher.Publish(event); err != nil {
+			metrics.EventsExported.WithLabelValues(backend, "failure").Inc()
+			slog.Error("Failed to export event", "backend", backend, "type", event.Type, "event_id", event.ID, "error", err)
+			return err
+		}
+		metrics.EventsExported.WithLabelValues(backend, "success").Inc()
+		return nil
+	}
+}
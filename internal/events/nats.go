@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// ErrNotConnected is returned when publishing before Connect or after Close
+var ErrNotConnected = errors.New("event publisher is not connected")
+
+// EventSequenceHeader carries an event's per-location sequence on NATS
+const EventSequenceHeader = "Leeta-Event-Sequence"
+
+// NATSPublisher publishes each event as JSON, in the same shape the outbox
+// stores, on the subject prefix.<event type>, e.g. leeta.location.created.
+// The event ID goes in the Nats-Msg-Id header and its sequence in
+// Leeta-Event-Sequence. Core NATS publishing is fire-and-forget: events are buffered while the
+// client reconnects, and an error means the connection is closed.
+type NATSPublisher struct {
+	url    string
+	prefix string
+	opts   []nats.Option
+
+	mu   sync.RWMutex
+	conn *nats.Conn
+}
+
+// NewNATSPublisher creates a publisher for the server at url; it connects
+// on Connect
+func NewNATSPublisher(url, subjectPrefix string, opts ...nats.Option) *NATSPublisher {
+	return &NATSPublisher{url: url, prefix: subjectPrefix, opts: opts}
+}
+
+// Connect dials the server
+func (p *NATSPublisher) Connect() error {
+	conn, err := nats.Connect(p.url, p.opts...)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.conn = conn
+	p.mu.Unlock()
+	return nil
+}
+
+// Subject returns the subject events of the given type are published on
+func (p *NATSPublisher) Subject(eventType domain.EventType) string {
+	if p.prefix == "" {
+		return string(eventType)
+	}
+	return p.prefix + "." + string(eventType)
+}
+
+func (p *NATSPublisher) Publish(event domain.Event) error {
+	p.mu.RLock()
+	conn := p.conn
+	p.mu.RUnlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(p.Subject(event.Type))
+	msg.Data = payload
+	// JetStream discards a message whose Nats-Msg-Id it has already
+	// stored, so a redelivered event is stored once
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Header.Set(EventSequenceHeader, strconv.FormatInt(event.Sequence, 10))
+	return conn.PublishMsg(msg)
+}
+
+// Close drains buffered events to the server, giving up when ctx ends
+func (p *NATSPublisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	conn := p.conn
+	p.conn = nil
+	p.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+
+	defer conn.Close()
+	if _, ok := ctx.Deadline(); !ok {
+		return conn.Flush()
+	}
+	return conn.FlushWithContext(ctx)
+}
@@ -0,0 +1,186 @@
+package events_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+type message struct {
+	Subject string
+	Header  textproto.MIMEHeader
+	Data    []byte
+}
+
+// runNATSServer serves just enough of the NATS client protocol (INFO,
+// CONNECT, PING, PUB and HPUB) to capture what the publisher sends
+func runNATSServer(t *testing.T) (string, chan message) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan message, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveNATS(conn, messages)
+		}
+	}()
+	return "nats://" + listener.Addr().String(), messages
+}
+
+func serveNATS(conn net.Conn, messages chan message) {
+	defer conn.Close()
+	fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"version\":\"2.11.0\",\"proto\":1,\"headers\":true,\"max_payload\":1048576}\r\n")
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "PUB":
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			messages <- message{Subject: fields[1], Data: payload[:size]}
+		case "HPUB":
+			// HPUB <subject> [reply] <header bytes> <total bytes>
+			headerSize, err := strconv.Atoi(fields[len(fields)-2])
+			if err != nil {
+				return
+			}
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			headers := textproto.NewReader(bufio.NewReader(bytes.NewReader(payload[:headerSize])))
+			headers.ReadLine() // NATS/1.0
+			header, _ := headers.ReadMIMEHeader()
+			messages <- message{Subject: fields[1], Header: header, Data: payload[headerSize:size]}
+		}
+	}
+}
+
+func receive(t *testing.T, messages chan message) (message, domain.Event) {
+	t.Helper()
+	select {
+	case msg := <-messages:
+		var event domain.Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			t.Fatalf("Failed to decode payload %s: %v", msg.Data, err)
+		}
+		return msg, event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+		return message{}, domain.Event{}
+	}
+}
+
+func TestNATSPublisherExportsLocationEvents(t *testing.T) {
+	url, messages := runNATSServer(t)
+	publisher := events.NewNATSPublisher(url, "leeta")
+	if err := publisher.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer publisher.Close(context.Background())
+
+	bus := events.NewBus()
+	bus.Subscribe(events.Exporter("nats", publisher))
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(), service.WithEventPublisher(events.NewSequencer(bus)))
+
+	created, err := svc.CreateLocation("Ikeja", 6.6018, 3.3515)
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if err := svc.DeleteLocation("Ikeja"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+
+	var lastID string
+	for i, want := range []domain.EventType{domain.EventLocationCreated, domain.EventLocationDeleted} {
+		msg, event := receive(t, messages)
+		if msg.Subject != "leeta."+string(want) || event.Type != want {
+			t.Fatalf("Expected %s on leeta.%s, got %s on %s", want, want, event.Type, msg.Subject)
+		}
+		if len(event.ID) != 26 || event.ID <= lastID || event.OccurredAt.IsZero() {
+			t.Errorf("Expected a ULID after %q and a time on %s, got %+v", lastID, want, event)
+		}
+		lastID = event.ID
+		if sequence := int64(i + 1); event.Sequence != sequence || msg.Header.Get("Leeta-Event-Sequence") != strconv.FormatInt(sequence, 10) {
+			t.Errorf("Expected sequence %d on %s, got %d and header %q", sequence, want, event.Sequence, msg.Header.Get("Leeta-Event-Sequence"))
+		}
+		if msg.Header.Get("Nats-Msg-Id") != event.ID {
+			t.Errorf("Expected Nats-Msg-Id %s, got %q", event.ID, msg.Header.Get("Nats-Msg-Id"))
+		}
+		if event.Location.ID != created.ID || event.Location.Name != "Ikeja" ||
+			event.Location.Latitude != 6.6018 || event.Location.Longitude != 3.3515 {
+			t.Errorf("Expected the Ikeja location on %s, got %+v", want, event.Location)
+		}
+	}
+}
+
+func TestNATSPublisherFailureDoesNotFailRequest(t *testing.T) {
+	url, _ := runNATSServer(t)
+	publisher := events.NewNATSPublisher(url, "leeta")
+	if err := publisher.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	publisher.Close(context.Background())
+
+	failures := metrics.EventsExported.WithLabelValues("nats", "failure")
+	before := testutil.ToFloat64(failures)
+
+	bus := events.NewBus()
+	bus.Subscribe(events.Exporter("nats", publisher))
+	if err := bus.Publish(domain.NewEvent(domain.EventLocationCreated, domain.Location{Name: "Ikeja"})); err == nil {
+		t.Error("Expected the bus to report the failed export for outbox retries")
+	}
+	if got := testutil.ToFloat64(failures) - before; got != 1 {
+		t.Errorf("Expected one counted failure, got %v", got)
+	}
+
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(), service.WithEventPublisher(events.NewSequencer(bus)))
+	if _, err := svc.CreateLocation("Ikeja", 6.6018, 3.3515); err != nil {
+		t.Fatalf("Expected the create to succeed despite the failed export, got %v", err)
+	}
+}
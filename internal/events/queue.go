@@ -0,0 +1,143 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+var (
+	errQueueFull   = errors.New("delivery queue is full")
+	errQueueClosed = errors.New("delivery queue is closed")
+)
+
+// Queue hands events to a handler from a goroutine of its own, for stores
+// without an outbox, which publish on the request that made the change. A
+// failed delivery is retried with the same event, and so the same ID, up
+// to attempts times, waiting twice as long after each failure. Events are
+// delivered one at a time in the order they were queued.
+type Queue struct {
+	handler  Handler
+	attempts int
+	backoff  time.Duration
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	events  chan domain.Event
+	abandon chan struct{}
+	done    chan struct{}
+}
+
+// NewQueue returns a Queue holding up to size events for handler
+func NewQueue(handler Handler, size, attempts int, backoff time.Duration) *Queue {
+	if size <= 0 {
+		size = 1000
+	}
+	if attempts <= 0 {
+		attempts = 1
+	}
+	return &Queue{
+		handler:  handler,
+		attempts: attempts,
+		backoff:  backoff,
+		events:   make(chan domain.Event, size),
+		abandon:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Handle queues the event and returns without waiting for its delivery.
+// An event that finds the queue full or closed is dropped and logged.
+func (q *Queue) Handle(event domain.Event) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return errQueueClosed
+	}
+	select {
+	case q.events <- event:
+		return nil
+	default:
+		slog.Error("Dropped event", "type", event.Type, "event_id", event.ID, "error", errQueueFull)
+		return errQueueFull
+	}
+}
+
+// Start delivers queued events until Close
+func (q *Queue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started || q.closed {
+		return
+	}
+	q.started = true
+	go q.run()
+}
+
+// Close stops queueing and waits for the events already queued to be
+// delivered. If ctx ends first, retries stop and the events left are
+// dropped.
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.events)
+	started := q.started
+	q.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		close(q.abandon)
+		<-q.done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) run() {
+	defer close(q.done)
+	for event := range q.events {
+		select {
+		case <-q.abandon:
+			slog.Error("Dropped event at shutdown", "type", event.Type, "event_id", event.ID)
+			continue
+		default:
+		}
+		q.deliver(event)
+	}
+}
+
+// deliver hands the event to the handler until it succeeds or has had
+// every attempt
+func (q *Queue) deliver(event domain.Event) {
+	wait := q.backoff
+	for attempt := 1; ; attempt++ {
+		err := q.handler(event)
+		if err == nil {
+			return
+		}
+		if attempt == q.attempts {
+			slog.Error("Gave up delivering event", "type", event.Type, "event_id", event.ID, "attempts", attempt, "error", err)
+			return
+		}
+		select {
+		case <-time.After(wait):
+		case <-q.abandon:
+			slog.Error("Dropped event at shutdown", "type", event.Type, "event_id", event.ID, "attempts", attempt)
+			return
+		}
+		wait *= 2
+	}
+}
@@ -0,0 +1,82 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+)
+
+func TestQueueRetriesWithTheSameEvent(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var delivered []string
+	calls := map[string]int{}
+	release := make(chan struct{})
+	queue := events.NewQueue(func(e domain.Event) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		calls[e.ID]++
+		// Yaba fails twice, then gets through; Ikeja never does
+		if e.Location.Name == "Ikeja" || calls[e.ID] < 3 {
+			return errors.New("webhook answered 502 Bad Gateway")
+		}
+		delivered = append(delivered, e.Location.Name)
+		return nil
+	}, 10, 3, time.Millisecond)
+	queue.Start()
+
+	yaba := domain.NewEvent(domain.EventLocationCreated, domain.Location{ID: "1", Name: "Yaba"})
+	ikeja := domain.NewEvent(domain.EventLocationCreated, domain.Location{ID: "2", Name: "Ikeja"})
+	lekki := domain.NewEvent(domain.EventLocationCreated, domain.Location{ID: "3", Name: "Lekki"})
+	// The handler is held up, so returning shows nothing waits on it
+	for _, event := range []domain.Event{yaba, ikeja, lekki} {
+		if err := queue.Handle(event); err != nil {
+			t.Fatalf("Failed to queue %s: %v", event.Location.Name, err)
+		}
+	}
+	close(release)
+	if err := queue.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to drain the queue: %v", err)
+	}
+
+	if calls[yaba.ID] != 3 || calls[ikeja.ID] != 3 || calls[lekki.ID] != 3 {
+		t.Errorf("Expected every event tried up to 3 times under its own ID, got %v", calls)
+	}
+	if len(delivered) != 2 || delivered[0] != "Yaba" || delivered[1] != "Lekki" {
+		t.Errorf("Expected Yaba then Lekki delivered in order, got %v", delivered)
+	}
+	if err := queue.Handle(yaba); err == nil {
+		t.Error("Expected a closed queue to refuse events")
+	}
+}
+
+func TestQueueDropsWhenFull(t *testing.T) {
+	t.Parallel()
+	queue := events.NewQueue(func(domain.Event) error { return nil }, 1, 1, 0)
+	// Not started, so the first event stays queued
+	if err := queue.Handle(domain.NewEvent(domain.EventLocationCreated, domain.Location{Name: "Yaba"})); err != nil {
+		t.Fatalf("Failed to queue: %v", err)
+	}
+	if err := queue.Handle(domain.NewEvent(domain.EventLocationCreated, domain.Location{Name: "Ikeja"})); err == nil {
+		t.Error("Expected a full queue to drop the event")
+	}
+}
+
+func TestQueueCloseGivesUpAtDeadline(t *testing.T) {
+	t.Parallel()
+	queue := events.NewQueue(func(domain.Event) error { return errors.New("webhook unreachable") }, 10, 100, time.Hour)
+	queue.Start()
+	queue.Handle(domain.NewEvent(domain.EventLocationCreated, domain.Location{Name: "Yaba"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := queue.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected retries abandoned at the deadline, got %v", err)
+	}
+}
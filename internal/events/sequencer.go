@@ -0,0 +1,58 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// Sequencer numbers the events of each location before passing them on,
+// for stores that publish directly. A store with an outbox numbers events
+// in the transaction that records them instead. A location's count is
+// dropped once its location.deleted event is published; IDs are not
+// reused, so the location has no events after that.
+type Sequencer struct {
+	next domain.EventPublisher
+
+	mu        sync.Mutex
+	locations map[string]*locationSequence
+}
+
+// locationSequence is held while one of its location's events is numbered
+// and published, so the events reach next in the order they are numbered
+type locationSequence struct {
+	mu   sync.Mutex
+	last int64
+}
+
+// NewSequencer returns a Sequencer publishing to next
+func NewSequencer(next domain.EventPublisher) *Sequencer {
+	return &Sequencer{next: next, locations: make(map[string]*locationSequence)}
+}
+
+// Publish gives the event the sequence after the last one its location's
+// events had, then publishes it. Events of one location are published one
+// at a time; those of different locations do not wait for each other.
+func (s *Sequencer) Publish(event domain.Event) error {
+	s.mu.Lock()
+	seq, ok := s.locations[event.Location.ID]
+	if !ok {
+		seq = &locationSequence{}
+		s.locations[event.Location.ID] = seq
+	}
+	s.mu.Unlock()
+
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+	seq.last++
+	event.Sequence = seq.last
+	err := s.next.Publish(event)
+	if event.Type == domain.EventLocationDeleted {
+		s.mu.Lock()
+		if s.locations[event.Location.ID] == seq {
+			delete(s.locations, event.Location.ID)
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
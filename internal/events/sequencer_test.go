@@ -0,0 +1,80 @@
+package events_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+)
+
+func TestSequencerNumbersEventsPerLocation(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	last := map[string]int64{}
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Sequence != last[e.Location.ID]+1 {
+			t.Errorf("Expected %s published in order after %d, got %d", e.Location.ID, last[e.Location.ID], e.Sequence)
+		}
+		last[e.Location.ID] = e.Sequence
+		return nil
+	})
+	sequencer := events.NewSequencer(bus)
+
+	ikeja, yaba := domain.Location{ID: "1", Name: "Ikeja"}, domain.Location{ID: "2", Name: "Yaba"}
+	for _, e := range []domain.Event{
+		domain.NewEvent(domain.EventLocationCreated, ikeja),
+		domain.NewEvent(domain.EventLocationUpdated, ikeja),
+		domain.NewEvent(domain.EventLocationCreated, yaba),
+		domain.NewEvent(domain.EventLocationUpdated, ikeja),
+	} {
+		sequencer.Publish(e)
+	}
+	if last["1"] != 3 || last["2"] != 1 {
+		t.Fatalf("Expected Ikeja at 3 and Yaba at 1, got %v", last)
+	}
+
+	// Concurrent changes to one location are published in the order they
+	// are numbered
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sequencer.Publish(domain.NewEvent(domain.EventLocationUpdated, yaba))
+		}()
+	}
+	wg.Wait()
+	if last["2"] != 51 {
+		t.Errorf("Expected Yaba's 51 events numbered up to 51, got %d", last["2"])
+	}
+}
+
+func TestSequencerForgetsDeletedLocations(t *testing.T) {
+	t.Parallel()
+	var published []domain.Event
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		published = append(published, e)
+		return nil
+	})
+	sequencer := events.NewSequencer(bus)
+
+	ikeja := domain.Location{ID: "1", Name: "Ikeja"}
+	for _, eventType := range []domain.EventType{domain.EventLocationCreated, domain.EventLocationUpdated, domain.EventLocationDeleted} {
+		sequencer.Publish(domain.NewEvent(eventType, ikeja))
+	}
+	if got := published[2].Sequence; got != 3 {
+		t.Fatalf("Expected the delete numbered 3, got %d", got)
+	}
+
+	// Only a store reusing IDs would publish for ID 1 again, and it would
+	// count from the start
+	sequencer.Publish(domain.NewEvent(domain.EventLocationCreated, ikeja))
+	if got := published[3].Sequence; got != 1 {
+		t.Errorf("Expected the count for the deleted location dropped, got %d", got)
+	}
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/webhook"
+)
+
+// EventIDHeader carries an event's ID on webhook deliveries
+const EventIDHeader = "Leeta-Event-Id"
+
+// WebhookPublisher posts each event as JSON, in the same shape the outbox
+// stores, to a URL, signed in the webhook.SignatureHeader. The event ID and
+// sequence are also sent in Leeta-Event-Id and Leeta-Event-Sequence. Any
+// answer other than 2xx is a failed delivery, which the outbox retries with
+// the same event ID.
+type WebhookPublisher struct {
+	url    string
+	signer *webhook.Signer
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhookPublisher creates a publisher posting to url with client
+func NewWebhookPublisher(url string, signer *webhook.Signer, client *http.Client) *WebhookPublisher {
+	return &WebhookPublisher{url: url, signer: signer, client: client, now: time.Now}
+}
+
+func (p *WebhookPublisher) Publish(event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.SignatureHeader, p.signer.Sign(payload, p.now()))
+	req.Header.Set(EventIDHeader, event.ID)
+	req.Header.Set(EventSequenceHeader, strconv.FormatInt(event.Sequence, 10))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
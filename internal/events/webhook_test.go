@@ -0,0 +1,55 @@
+package events_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/pkg/webhook"
+)
+
+func TestWebhookPublisherSignsDeliveries(t *testing.T) {
+	current := webhook.Key{ID: "2024-10", Secret: []byte("new-secret")}
+	previous := webhook.Key{ID: "2024-04", Secret: []byte("old-secret")}
+	signer, err := webhook.NewSigner(current, previous)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	status := http.StatusNoContent
+	deliveries := make(chan *http.Request, 4)
+	bodies := make(chan []byte, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		deliveries <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	publisher := events.NewWebhookPublisher(server.URL, signer, server.Client())
+	event := domain.NewEvent(domain.EventLocationCreated, domain.Location{Name: "Ikeja"})
+	if err := publisher.Publish(event); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	req, body := <-deliveries, <-bodies
+	for _, key := range []webhook.Key{current, previous} {
+		if err := webhook.VerifySignature(key.Secret, []byte(req.Header.Get(webhook.SignatureHeader)), body, time.Minute); err != nil {
+			t.Errorf("Expected the delivery to verify with %s, got %v", key.ID, err)
+		}
+	}
+	if req.Header.Get(events.EventIDHeader) != event.ID || req.Header.Get(events.EventSequenceHeader) != strconv.FormatInt(event.Sequence, 10) {
+		t.Errorf("Expected the event ID and sequence headers, got %v", req.Header)
+	}
+
+	status = http.StatusBadGateway
+	if err := publisher.Publish(event); err == nil {
+		t.Error("Expected a failed delivery to be reported for outbox retries")
+	}
+}
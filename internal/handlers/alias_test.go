@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestLocationAliases(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Leeta Lekki Phase 1", Latitude: ptr(6.4474), Longitude: ptr(3.4720)})
+	api.Post("/locations", dto.LocationRequest{Name: "Leeta Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+
+	resp := api.Post("/locations/"+url.PathEscape("Leeta Lekki Phase 1")+"/aliases", map[string]any{"alias": "Leeta Admiralty Way"})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var location dto.LocationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &location); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if location.Name != "Leeta Lekki Phase 1" || !slices.Equal(location.Aliases, []string{"Leeta Admiralty Way"}) {
+		t.Errorf("Expected the canonical name with its alias, got %+v", location)
+	}
+
+	tests := []struct {
+		name        string
+		method, url string
+		body        any
+		status      int
+		code        string
+	}{
+		{"create over an alias", http.MethodPost, "/locations",
+			dto.LocationRequest{Name: "Leeta Admiralty Way", Latitude: ptr(6.5), Longitude: ptr(3.5)}, http.StatusConflict, "NAME_TAKEN"},
+		{"alias another location's alias", http.MethodPost, "/locations/" + url.PathEscape("Leeta Yaba") + "/aliases",
+			map[string]any{"alias": "Leeta Admiralty Way"}, http.StatusConflict, "NAME_TAKEN"},
+		{"alias a missing location", http.MethodPost, "/locations/Ikeja/aliases",
+			map[string]any{"alias": "Ikeja City Mall"}, http.StatusNotFound, "LOCATION_NOT_FOUND"},
+		{"remove another location's alias", http.MethodDelete, "/locations/" + url.PathEscape("Leeta Yaba") + "/aliases/" + url.PathEscape("Leeta Admiralty Way"),
+			nil, http.StatusNotFound, "ALIAS_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		var args []any
+		if tt.body != nil {
+			args = append(args, tt.body)
+		}
+		resp := api.Do(tt.method, tt.url, args...)
+		if resp.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, resp.Code, resp.Body.String())
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != tt.code {
+			t.Errorf("%s: expected %s, got %+v", tt.name, tt.code, body)
+		}
+	}
+
+	resp = api.Post("/locations", dto.LocationRequest{Name: "Leeta Admiralty Way", Latitude: ptr(6.5), Longitude: ptr(3.5)})
+	if body := decodeCodedError(t, resp.Body.Bytes()); body.Detail != "The name Leeta Admiralty Way is already used by Leeta Lekki Phase 1" {
+		t.Errorf("Expected the conflict to name the owner, got %q", body.Detail)
+	}
+
+	resp = api.Delete("/locations/" + url.PathEscape("Leeta Lekki Phase 1") + "/aliases/" + url.PathEscape("Leeta Admiralty Way"))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	json.Unmarshal(resp.Body.Bytes(), &location)
+	if len(location.Aliases) != 0 {
+		t.Errorf("Expected no aliases left, got %v", location.Aliases)
+	}
+
+	api.Post("/locations/"+url.PathEscape("Leeta Yaba")+"/aliases", map[string]any{"alias": "Yaba"})
+	if resp := api.Delete("/locations/Yaba"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected deleting by alias to succeed, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Delete("/locations/" + url.PathEscape("Leeta Yaba")); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected the aliased location gone, got %d", resp.Code)
+	}
+}
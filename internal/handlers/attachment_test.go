@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// stubChecker fails the URLs it lists
+type stubChecker map[string]error
+
+func (c stubChecker) CheckAttachment(url string) error {
+	return c[url]
+}
+
+func TestCreateLocationAttachments(t *testing.T) {
+	t.Parallel()
+	checker := stubChecker{"https://cdn.leeta.ng/gone.jpg": errors.New("status 404")}
+	locationService := service.NewLocationService(memory.NewInMemoryLocationRepository(),
+		service.WithAttachmentPolicy(domain.AttachmentPolicy{AllowedHosts: []string{".leeta.ng"}}),
+		service.WithAttachmentChecker(checker))
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(locationService).RegisterRoutes(api)
+
+	attachments := []domain.Attachment{
+		{URL: "https://cdn.leeta.ng/yaba.jpg", Kind: domain.AttachmentPhoto},
+		{URL: "https://docs.leeta.ng/yaba-permit.pdf", Kind: domain.AttachmentDocument},
+	}
+	resp := api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711), Attachments: attachments})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+
+	resp = api.Get("/locations")
+	var list dto.LocationListResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if got := list.Locations[0].Attachments; len(got) != 2 || got[1] != attachments[1] {
+		t.Errorf("Expected the attachments listed as given, got %+v", got)
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		code   string
+		detail string
+	}{
+		{"plain http", "http://cdn.leeta.ng/ikeja.jpg", "INVALID_ATTACHMENT", "Invalid attachment 1: url must use https"},
+		{"other host", "https://images.example.com/ikeja.jpg", "INVALID_ATTACHMENT", "Invalid attachment 1: host images.example.com is not allowed"},
+		{"unreachable", "https://cdn.leeta.ng/gone.jpg", "ATTACHMENT_UNREACHABLE", "Attachment 1 could not be reached: status 404"},
+	}
+	for _, tt := range tests {
+		body := dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515),
+			Attachments: append([]domain.Attachment{attachments[0]}, domain.Attachment{URL: tt.url, Kind: domain.AttachmentPhoto})}
+		resp := api.Post("/locations", body)
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+			continue
+		}
+		got := decodeCodedError(t, resp.Body.Bytes())
+		if got.Code != tt.code || got.Detail != tt.detail {
+			t.Errorf("%s: expected %s %q, got %+v", tt.name, tt.code, tt.detail, got)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// AuditExportRequest represents the range of an event log export
+type AuditExportRequest struct {
+	From   time.Time `query:"from" required:"true" example:"2025-08-01T00:00:00Z" doc:"Only events recorded at or after this RFC 3339 time"`
+	To     time.Time `query:"to" required:"true" example:"2025-09-01T00:00:00Z" doc:"Only events recorded before this RFC 3339 time; must be later than from"`
+	Cursor string    `query:"cursor" doc:"Continuation cursor from the last line of a previous export of the same range"`
+	Limit  int       `query:"limit" minimum:"0" example:"1000" doc:"Most entries to return, up to the configured maximum (10000 by default)"`
+
+	acceptEncoding string
+}
+
+// Resolve captures the encodings the client accepts
+func (r *AuditExportRequest) Resolve(ctx huma.Context) []error {
+	r.acceptEncoding = ctx.Header("Accept-Encoding")
+	return nil
+}
+
+// auditEntry is one exported event, in the form events are published in
+type auditEntry struct {
+	Sequence int64 `json:"sequence"`
+	domain.Event
+}
+
+// auditControlLine ends an export early, either with the cursor to resume
+// from or with the error that stopped it
+type auditControlLine struct {
+	Type   string `json:"type"`
+	Cursor string `json:"cursor,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditHandler exports the event log for compliance tooling
+type AuditHandler struct {
+	log    domain.EventLog
+	limits config.LimitsConfig
+}
+
+// NewAuditHandler creates a new audit handler; zero limits mean the defaults
+func NewAuditHandler(log domain.EventLog, limits config.LimitsConfig) *AuditHandler {
+	if limits == (config.LimitsConfig{}) {
+		limits = config.DefaultLimits()
+	}
+	return &AuditHandler{log: log, limits: limits}
+}
+
+// RegisterRoutes registers the audit routes with the Huma API
+func (h *AuditHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "export-audit-log",
+		Method:      http.MethodGet,
+		Path:        "/audit/export",
+		Summary:     "Export Audit Log",
+		Description: "Stream the location change events recorded in a time range as newline-delimited JSON, oldest first. " +
+			"When more events remain than the limit allows, the last line is a `continuation` carrying the cursor to request the rest with. " +
+			"The response is gzip-compressed when the client accepts it.",
+		Tags:   []string{"Admin"},
+		Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	}, h.Export)
+}
+
+// Export handles GET /audit/export requests
+func (h *AuditHandler) Export(ctx context.Context, input *AuditExportRequest) (*huma.StreamResponse, error) {
+	if err := checkLimit(ctx, "limit", input.Limit, h.limits.MaxExportRows); err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit == 0 {
+		limit = h.limits.MaxExportRows
+	}
+
+	rng := domain.EventRange{From: input.From, To: input.To}
+	if err := rng.Validate(); err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_EXPORT_RANGE", "from must be before to"))
+	}
+	if input.Cursor != "" {
+		after, err := decodeEventCursor(input.Cursor)
+		if err != nil {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadRequest, "INVALID_CURSOR", "Invalid cursor"))
+		}
+		rng.After = &after
+	}
+
+	// One extra event tells whether a continuation is needed
+	batchSize := min(limit+1, h.limits.MaxBatchSize)
+
+	return &huma.StreamResponse{
+		Body: func(ctx huma.Context) {
+			ctx.SetHeader("Content-Type", "application/x-ndjson")
+			ctx.SetHeader("Vary", "Accept-Encoding")
+			var writer io.Writer = ctx.BodyWriter()
+			if acceptsGzip(input.acceptEncoding) {
+				ctx.SetHeader("Content-Encoding", "gzip")
+				gz := gzip.NewWriter(writer)
+				defer gz.Close()
+				writer = gz
+			}
+			ctx.SetStatus(http.StatusOK)
+			encoder := json.NewEncoder(writer)
+
+			written := 0
+			var last domain.LoggedEvent
+			for event, err := range h.log.StreamEvents(ctx.Context(), rng, batchSize) {
+				if err != nil {
+					// The status line has already been sent, so report in-band
+					encoder.Encode(auditControlLine{Type: "error", Error: "Export stopped: " + err.Error()})
+					return
+				}
+				if written == limit {
+					encoder.Encode(auditControlLine{Type: "continuation", Cursor: encodeEventCursor(last.Cursor())})
+					return
+				}
+				if err := encoder.Encode(auditEntry{Sequence: event.Sequence, Event: event.Event}); err != nil {
+					return
+				}
+				last = event
+				written++
+			}
+		},
+	}, nil
+}
+
+// encodeEventCursor packs the position as microseconds since the epoch,
+// the precision events are stored at, and the sequence
+func encodeEventCursor(cursor domain.EventCursor) string {
+	raw := fmt.Sprintf("%d.%d", cursor.LoggedAt.UnixMicro(), cursor.Sequence)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeEventCursor(cursor string) (domain.EventCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return domain.EventCursor{}, err
+	}
+	micros, sequence, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return domain.EventCursor{}, errors.New("invalid cursor")
+	}
+	at, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return domain.EventCursor{}, err
+	}
+	seq, err := strconv.ParseInt(sequence, 10, 64)
+	if err != nil {
+		return domain.EventCursor{}, err
+	}
+	return domain.EventCursor{LoggedAt: time.UnixMicro(at).UTC(), Sequence: seq}, nil
+}
+
+// acceptsGzip reports whether an Accept-Encoding header lists gzip without
+// refusing it with q=0
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
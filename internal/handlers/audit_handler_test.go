@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// fakeEventLog serves events kept in log order
+type fakeEventLog struct {
+	events []domain.LoggedEvent
+}
+
+func (l *fakeEventLog) StreamEvents(ctx context.Context, rng domain.EventRange, batchSize int) iter.Seq2[domain.LoggedEvent, error] {
+	return func(yield func(domain.LoggedEvent, error) bool) {
+		for _, event := range l.events {
+			if event.LoggedAt.Before(rng.From) || !event.LoggedAt.Before(rng.To) {
+				continue
+			}
+			if after := rng.After; after != nil && (event.LoggedAt.Before(after.LoggedAt) ||
+				(event.LoggedAt.Equal(after.LoggedAt) && event.Sequence <= after.Sequence)) {
+				continue
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}
+}
+
+func seedEventLog(start time.Time, names ...string) *fakeEventLog {
+	log := &fakeEventLog{}
+	for i, name := range names {
+		at := start.Add(time.Duration(i) * time.Minute)
+		event := domain.Event{ID: name, Type: domain.EventLocationCreated, Location: domain.Location{Name: name}, OccurredAt: at}
+		log.events = append(log.events, domain.LoggedEvent{Sequence: int64(i + 1), LoggedAt: at, Event: event})
+	}
+	return log
+}
+
+// readAuditLines splits an export into entries and the trailing control line
+func readAuditLines(t *testing.T, body io.Reader) ([]auditEntry, auditControlLine) {
+	t.Helper()
+	var entries []auditEntry
+	var control auditControlLine
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Failed to decode line %q: %v", scanner.Text(), err)
+		}
+		if _, ok := line["sequence"]; !ok {
+			json.Unmarshal(scanner.Bytes(), &control)
+			continue
+		}
+		var entry auditEntry
+		json.Unmarshal(scanner.Bytes(), &entry)
+		entries = append(entries, entry)
+	}
+	return entries, control
+}
+
+func exportPath(from, to time.Time, params ...string) string {
+	query := url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}}
+	for i := 0; i+1 < len(params); i += 2 {
+		query.Set(params[i], params[i+1])
+	}
+	return "/audit/export?" + query.Encode()
+}
+
+func TestAuditExportContinuation(t *testing.T) {
+	start := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	log := seedEventLog(start, "Before", "Ikeja", "Yaba", "Lekki", "Ajah", "After")
+	_, api := humatest.New(t)
+	NewAuditHandler(log, config.DefaultLimits()).RegisterRoutes(api)
+
+	// Before and After fall just outside the range
+	from, to := start.Add(time.Minute), start.Add(5*time.Minute)
+	resp := api.Get(exportPath(from, to, "limit", "3"))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON, got %q", ct)
+	}
+	entries, control := readAuditLines(t, resp.Body)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Location.Name)
+	}
+	if !slices.Equal(names, []string{"Ikeja", "Yaba", "Lekki"}) {
+		t.Fatalf("Expected the first three events in order, got %v", names)
+	}
+	if control.Type != "continuation" || control.Cursor == "" {
+		t.Fatalf("Expected a continuation line, got %+v", control)
+	}
+
+	resp = api.Get(exportPath(from, to, "limit", "3", "cursor", control.Cursor))
+	entries, control = readAuditLines(t, resp.Body)
+	if len(entries) != 1 || entries[0].Location.Name != "Ajah" || entries[0].Sequence != 5 {
+		t.Fatalf("Expected only Ajah after the cursor, got %+v", entries)
+	}
+	if control.Type != "" {
+		t.Errorf("Expected no continuation once the range is exhausted, got %+v", control)
+	}
+}
+
+func TestAuditExportGzip(t *testing.T) {
+	start := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	_, api := humatest.New(t)
+	NewAuditHandler(seedEventLog(start, "Ikeja", "Yaba"), config.DefaultLimits()).RegisterRoutes(api)
+
+	resp := api.Get(exportPath(start, start.Add(time.Hour)), "Accept-Encoding: br, gzip")
+	if resp.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected a gzip response, got headers %v", resp.Header())
+	}
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	if entries, _ := readAuditLines(t, reader); len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %+v", entries)
+	}
+
+	resp = api.Get(exportPath(start, start.Add(time.Hour)), "Accept-Encoding: gzip;q=0")
+	if resp.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected gzip refused with q=0, got %q", resp.Header().Get("Content-Encoding"))
+	}
+	if entries, _ := readAuditLines(t, bytes.NewReader(resp.Body.Bytes())); len(entries) != 2 {
+		t.Errorf("Expected 2 plain entries, got %+v", entries)
+	}
+}
+
+func TestAuditExportRejects(t *testing.T) {
+	start := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	_, api := humatest.New(t)
+	NewAuditHandler(seedEventLog(start, "Ikeja"), config.DefaultLimits()).RegisterRoutes(api)
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{exportPath(start, start), http.StatusUnprocessableEntity, "INVALID_EXPORT_RANGE"},
+		{exportPath(start, start.Add(time.Hour), "cursor", "not-a-cursor"), http.StatusBadRequest, "INVALID_CURSOR"},
+		{exportPath(start, start.Add(time.Hour), "limit", "10001"), http.StatusUnprocessableEntity, "LIMIT_EXCEEDED"},
+	}
+	for _, tt := range tests {
+		resp := api.Get(tt.path)
+		if resp.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, resp.Code)
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != tt.code {
+			t.Errorf("%s: expected %s, got %+v", tt.path, tt.code, body)
+		}
+	}
+
+	if op := api.OpenAPI().Paths["/audit/export"].Get; !slices.Contains(op.Tags, "Admin") {
+		t.Errorf("Expected the export tagged Admin so it needs the admin scope, got %v", op.Tags)
+	}
+}
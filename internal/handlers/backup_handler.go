@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// ExportResponse represents a snapshot of every location
+type ExportResponse struct {
+	AcceptRanges string       `header:"Accept-Ranges" doc:"Always none: resume with resume_after_id instead of byte ranges"`
+	ResumeAfter  string       `header:"X-Resume-After" doc:"ID to pass as resume_after_id for the rest, present only when limit cut the export short"`
+	Body         dto.Snapshot `json:"body"`
+}
+
+// ExportRequest represents the filters an export accepts
+type ExportRequest struct {
+	LocationFilterParams
+	ResumeAfterID string `query:"resume_after_id" pattern:"^[0-9]+$" example:"1834" doc:"Only locations with a greater ID, to continue an interrupted export after the last complete record"`
+	Limit         int    `query:"limit" minimum:"0" example:"50000" doc:"Most locations to export; 0 exports all"`
+}
+
+// RestoreRequest represents a snapshot to restore
+type RestoreRequest struct {
+	Body dto.Snapshot `json:"body"`
+}
+
+// RestoreResponse represents the outcome of a restore
+type RestoreResponse struct {
+	Body dto.RestoreResponse `json:"body"`
+}
+
+// BackupHandler exports and restores snapshots for operators
+type BackupHandler struct {
+	repo     domain.LocationRepository
+	restorer domain.LocationRestorer
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(repo domain.LocationRepository, restorer domain.LocationRestorer) *BackupHandler {
+	return &BackupHandler{repo: repo, restorer: restorer}
+}
+
+// RegisterRoutes registers the backup admin routes with the Huma API
+func (h *BackupHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "export-locations",
+		Method:      http.MethodGet,
+		Path:        "/admin/export",
+		Summary:     "Export Locations",
+		Description: "Snapshot every location with its ID and creation time, ordered by ID. The filters of the location listing narrow the snapshot. " +
+			"Byte ranges are not supported, as the same export taken twice differs in its exported_at; instead, continue an interrupted or limited export " +
+			"with `resume_after_id` set to the last ID received, or to `X-Resume-After`.",
+		Tags: []string{"Admin"},
+	}, h.Export)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "restore-locations",
+		Method:      http.MethodPost,
+		Path:        "/admin/restore",
+		Summary:     "Restore Locations",
+		Description: "Restore a snapshot, keeping IDs and creation times. The snapshot is merged into the existing data: " +
+			"records whose ID or name is already taken, or that are invalid, are skipped and listed as conflicts. " +
+			"Set `crs` to restore projected coordinates, which are converted to WGS-84.",
+		Tags: []string{"Admin"},
+	}, h.Restore)
+}
+
+// Export handles GET /admin/export requests
+func (h *BackupHandler) Export(ctx context.Context, input *ExportRequest) (*ExportResponse, error) {
+	filter, err := input.filter()
+	if err != nil {
+		return nil, filterError(ctx, err)
+	}
+	filter.AfterID = input.ResumeAfterID
+
+	// One extra location tells whether the limit cut the export short
+	page := domain.Page{}
+	if input.Limit > 0 {
+		page.Limit = input.Limit + 1
+	}
+	locations, err := h.repo.Find(filter, page, domain.LocationSort{Field: domain.SortByID})
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to export locations"))
+	}
+
+	resp := &ExportResponse{AcceptRanges: "none"}
+	if input.Limit > 0 && len(locations) > input.Limit {
+		locations = locations[:input.Limit]
+		resp.ResumeAfter = locations[len(locations)-1].ID
+	}
+	resp.Body = dto.ToSnapshot(locations, time.Now())
+	return resp, nil
+}
+
+// Restore handles POST /admin/restore requests
+func (h *BackupHandler) Restore(ctx context.Context, input *RestoreRequest) (*RestoreResponse, error) {
+	locations, positions, invalid := input.Body.Project()
+	result, err := h.restorer.RestoreLocations(locations)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to restore locations"))
+	}
+	dto.MergeConflicts(result, positions, invalid)
+
+	return &RestoreResponse{
+		Body: dto.FromRestoreResult(result),
+	}, nil
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+func setupBackupAPI(t *testing.T, repo *memory.InMemoryLocationRepository) humatest.TestAPI {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewBackupHandler(repo, repo).RegisterRoutes(api)
+	return api
+}
+
+func TestExportRestoreRoundTrip(t *testing.T) {
+	source := memory.NewInMemoryLocationRepository()
+	for _, name := range []string{"Ikeja", "Yaba", "Lekki"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.4)
+		source.Save(location)
+	}
+	source.Delete("Yaba")
+
+	resp := setupBackupAPI(t, source).Get("/admin/export")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	var snapshot dto.Snapshot
+	if err := json.Unmarshal(resp.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Failed to decode snapshot: %v", err)
+	}
+	if snapshot.Version != dto.SnapshotVersion || len(snapshot.Locations) != 2 ||
+		snapshot.Locations[0].ID != "1" || snapshot.Locations[1].ID != "3" {
+		t.Fatalf("Expected Ikeja and Lekki with their IDs, got %+v", snapshot)
+	}
+
+	target := memory.NewInMemoryLocationRepository()
+	api := setupBackupAPI(t, target)
+	resp = api.Post("/admin/restore", snapshot)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var result dto.RestoreResponse
+	json.Unmarshal(resp.Body.Bytes(), &result)
+	if result.Restored != 2 || len(result.Conflicts) != 0 {
+		t.Errorf("Expected a clean restore, got %+v", result)
+	}
+	if location, err := target.FindByID("3"); err != nil || location.Name != "Lekki" || !location.CreatedAt.Equal(snapshot.Locations[1].CreatedAt) {
+		t.Errorf("Expected Lekki restored under ID 3, got %+v, %v", location, err)
+	}
+
+	// Restoring again reports every record as a conflict
+	resp = api.Post("/admin/restore", snapshot)
+	json.Unmarshal(resp.Body.Bytes(), &result)
+	if result.Restored != 0 || len(result.Conflicts) != 2 || result.Conflicts[1].Index != 1 || result.Conflicts[1].Reason != domain.RestoreIDExists {
+		t.Errorf("Expected per-record conflicts, got %+v", result)
+	}
+}
+
+func TestExportFilters(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	for _, name := range []string{"Ikeja", "Yaba", "Lekki"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.4)
+		repo.Save(location)
+	}
+	api := setupBackupAPI(t, repo)
+
+	resp := api.Get("/admin/export?name_contains=k")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var snapshot dto.Snapshot
+	if err := json.Unmarshal(resp.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Failed to decode snapshot: %v", err)
+	}
+	if len(snapshot.Locations) != 2 || snapshot.Locations[0].Name != "Ikeja" || snapshot.Locations[1].Name != "Lekki" {
+		t.Errorf("Expected Ikeja and Lekki in ID order, got %+v", snapshot.Locations)
+	}
+
+	if resp := api.Get("/admin/export?bbox=0,10,1,5"); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected an invalid bbox to be rejected, got %d", resp.Code)
+	}
+}
+
+func TestExportResume(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	for _, name := range []string{"Ikeja", "Yaba", "Lekki", "Ajah", "Ikoyi"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.4)
+		repo.Save(location)
+	}
+	api := setupBackupAPI(t, repo)
+	export := func(query string) (dto.Snapshot, string) {
+		t.Helper()
+		resp := api.Get("/admin/export" + query)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		if ranges := resp.Header().Get("Accept-Ranges"); ranges != "none" {
+			t.Errorf("Expected Accept-Ranges none, got %q", ranges)
+		}
+		var snapshot dto.Snapshot
+		if err := json.Unmarshal(resp.Body.Bytes(), &snapshot); err != nil {
+			t.Fatalf("Failed to decode snapshot: %v", err)
+		}
+		return snapshot, resp.Header().Get("X-Resume-After")
+	}
+	ids := func(locations []dto.SnapshotLocation) []string {
+		var ids []string
+		for _, location := range locations {
+			ids = append(ids, location.ID)
+		}
+		return ids
+	}
+
+	full, resume := export("")
+	if resume != "" {
+		t.Errorf("Expected no X-Resume-After on a complete export, got %q", resume)
+	}
+	want := ids(full.Locations)
+
+	// A download that died after three records picks up from the third
+	resumed, _ := export("?resume_after_id=" + full.Locations[2].ID)
+	if got := append(ids(full.Locations[:3]), ids(resumed.Locations)...); !slices.Equal(got, want) {
+		t.Errorf("Expected the resumed export to complete %v, got %v", want, got)
+	}
+
+	// Limited exports chain through X-Resume-After
+	var chained []dto.SnapshotLocation
+	for query := "?limit=2"; ; {
+		snapshot, resume := export(query)
+		chained = append(chained, snapshot.Locations...)
+		if resume == "" {
+			break
+		}
+		query = "?limit=2&resume_after_id=" + resume
+	}
+	if !slices.EqualFunc(chained, full.Locations, func(a, b dto.SnapshotLocation) bool { return a.ID == b.ID && a.Name == b.Name }) {
+		t.Errorf("Expected the chained export to equal the full one, got %v", ids(chained))
+	}
+
+	if resp := api.Get("/admin/export?resume_after_id=abc"); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a non-numeric ID, got %d", http.StatusUnprocessableEntity, resp.Code)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// GetCapabilitiesRequest represents a read of the capability document
+type GetCapabilitiesRequest struct {
+	IfNoneMatch string `header:"If-None-Match" example:"\"3f2a9c41d07be815\"" doc:"ETags of copies the caller holds, comma-separated; answers 304 while the document is unchanged"`
+}
+
+// GetCapabilitiesResponse represents the capability document
+type GetCapabilitiesResponse struct {
+	ETag string                   `header:"ETag" doc:"Entity tag of the document"`
+	Body dto.CapabilitiesResponse `json:"body"`
+}
+
+// CapabilitiesHandler tells clients which optional features this
+// deployment has enabled. Features are registered while the server is
+// wired, from the configuration they read, so the document cannot drift
+// from what is running.
+type CapabilitiesHandler struct {
+	mu       sync.RWMutex
+	features map[string]any
+}
+
+// NewCapabilitiesHandler creates a capabilities handler with no features
+func NewCapabilitiesHandler() *CapabilitiesHandler {
+	return &CapabilitiesHandler{features: map[string]any{}}
+}
+
+// Register describes an enabled feature. The descriptor is shown as JSON,
+// so it is usually the config struct the feature reads, whose json tags
+// name its settings and hide its secrets. Features that are off are not
+// registered and so are absent from the document.
+func (h *CapabilitiesHandler) Register(name string, descriptor any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.features[name] = descriptor
+}
+
+// RegisterRoutes registers the capabilities route with the Huma API
+func (h *CapabilitiesHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-capabilities",
+		Method:      http.MethodGet,
+		Path:        "/capabilities",
+		Summary:     "Get Capabilities",
+		Description: "The optional features this deployment has enabled and their settings, such as page size limits, distance units and the authentication mode. " +
+			"Features that are off are left out. The document changes only when the server is reconfigured, so it may be cached for long; " +
+			"send its `ETag` in `If-None-Match` to revalidate.",
+		Tags: []string{"Capabilities"},
+	}, h.GetCapabilities)
+}
+
+// GetCapabilities handles GET /capabilities requests
+func (h *CapabilitiesHandler) GetCapabilities(ctx context.Context, input *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error) {
+	h.mu.RLock()
+	body := dto.CapabilitiesResponse{SchemaVersion: dto.CapabilitiesSchemaVersion, Features: maps.Clone(h.features)}
+	h.mu.RUnlock()
+
+	// Map keys are marshalled in order, so equal documents hash equally
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	if etagListed(input.IfNoneMatch, etag, false) {
+		return nil, huma.ErrorWithHeaders(huma.Status304NotModified(), http.Header{"ETag": {etag}})
+	}
+	return &GetCapabilitiesResponse{ETag: etag, Body: body}, nil
+}
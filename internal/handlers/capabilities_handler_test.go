@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	t.Parallel()
+	api := newTestAPI(t)
+	capabilities := NewCapabilitiesHandler()
+	capabilities.Register("sync", config.SyncConfig{Enabled: true, Timeout: 30})
+	capabilities.RegisterRoutes(api)
+
+	resp := api.Get("/capabilities")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var body struct {
+		SchemaVersion int                        `json:"schema_version"`
+		Features      map[string]json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode capabilities: %v", err)
+	}
+	if body.SchemaVersion != 1 {
+		t.Errorf("Expected schema version 1, got %d", body.SchemaVersion)
+	}
+	var sync config.SyncConfig
+	if err := json.Unmarshal(body.Features["sync"], &sync); err != nil || !sync.Enabled || sync.Timeout != 30 {
+		t.Errorf("Expected the sync descriptor, got %s", body.Features["sync"])
+	}
+
+	etag := resp.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+	if resp := api.Get("/capabilities", "If-None-Match: "+etag); resp.Code != http.StatusNotModified || resp.Header().Get("ETag") != etag {
+		t.Errorf("Expected 304 with the ETag, got %d %q", resp.Code, resp.Header().Get("ETag"))
+	}
+
+	capabilities.Register("truncate", struct{}{})
+	resp = api.Get("/capabilities", "If-None-Match: "+etag)
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") == etag {
+		t.Errorf("Expected a new document after registering a feature, got %d %q", resp.Code, resp.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// ChangeFeedRequest represents a page of the change log
+type ChangeFeedRequest struct {
+	SinceSeq int64 `query:"since_seq" minimum:"0" example:"48213" doc:"Only changes after this sequence; 0 reads from the start"`
+	Limit    int   `query:"limit" minimum:"0" example:"100" doc:"Most changes to return, up to the maximum page size; 0 means the default page size"`
+}
+
+// ChangeFeedResponse represents a page of the change log
+type ChangeFeedResponse struct {
+	Body dto.ChangeFeedResponse `json:"body"`
+}
+
+// ChangeHandler serves the change log to clients syncing incrementally
+type ChangeHandler struct {
+	log    domain.ChangeLog
+	limits config.LimitsConfig
+}
+
+// NewChangeHandler creates a new change handler; zero limits mean the
+// defaults
+func NewChangeHandler(log domain.ChangeLog, limits config.LimitsConfig) *ChangeHandler {
+	if limits == (config.LimitsConfig{}) {
+		limits = config.DefaultLimits()
+	}
+	return &ChangeHandler{log: log, limits: limits}
+}
+
+// RegisterRoutes registers the change feed route with the Huma API
+func (h *ChangeHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-location-changes",
+		Method:      http.MethodGet,
+		Path:        "/locations/changes",
+		Summary:     "List Location Changes",
+		Description: "List creates, updates and deletes recorded after `since_seq`, oldest first. " +
+			"Page through with the `seq` of the last change until `has_more` is false, then poll from `latest_seq`. " +
+			"Superseded changes are compacted away over time, but the latest change to every name is always kept.",
+		Tags:   []string{"Locations"},
+		Errors: []int{http.StatusUnprocessableEntity},
+	}, h.ListChanges)
+}
+
+// ListChanges handles GET /locations/changes requests
+func (h *ChangeHandler) ListChanges(ctx context.Context, input *ChangeFeedRequest) (*ChangeFeedResponse, error) {
+	if err := checkLimit(ctx, "limit", input.Limit, h.limits.MaxPageSize); err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit == 0 {
+		limit = h.limits.DefaultPageSize
+	}
+
+	// One extra change tells whether more follow
+	feed, err := h.log.ChangesSince(input.SinceSeq, limit+1)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to read changes"))
+	}
+	hasMore := len(feed.Changes) > limit
+	if hasMore {
+		feed.Changes = feed.Changes[:limit]
+	}
+	return &ChangeFeedResponse{Body: dto.FromChangeFeed(feed, hasMore)}, nil
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestChangeFeed(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	api := newTestAPI(t)
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+	NewChangeHandler(repo, config.DefaultLimits()).RegisterRoutes(api)
+
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	api.Post("/locations/Ikeja/aliases", map[string]string{"alias": "Ikeja Along"})
+	api.Delete("/locations/" + url.PathEscape("Ikeja Along"))
+
+	resp := api.Get("/locations/changes?limit=2")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var feed dto.ChangeFeedResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(feed.Changes) != 2 || !feed.HasMore || feed.LatestSeq != 3 {
+		t.Fatalf("Expected the first 2 of 3 changes, got %+v", feed)
+	}
+	if feed.Changes[0].Action != "created" || feed.Changes[1].Action != "updated" || feed.Changes[1].Location.Aliases[0] != "Ikeja Along" {
+		t.Errorf("Expected the create then the alias update, got %+v", feed.Changes)
+	}
+
+	resp = api.Get("/locations/changes?limit=2&since_seq=2")
+	feed = dto.ChangeFeedResponse{}
+	json.Unmarshal(resp.Body.Bytes(), &feed)
+	if len(feed.Changes) != 1 || feed.HasMore || feed.Changes[0].Action != "deleted" || feed.Changes[0].Name != "Ikeja" {
+		t.Errorf("Expected only the deletion, got %+v", feed)
+	}
+
+	resp = api.Get("/locations/changes?since_seq=3")
+	feed = dto.ChangeFeedResponse{}
+	json.Unmarshal(resp.Body.Bytes(), &feed)
+	if len(feed.Changes) != 0 || feed.LatestSeq != 3 {
+		t.Errorf("Expected an empty page at the head of the log, got %+v", feed)
+	}
+
+	resp = api.Get("/locations/changes?limit=101")
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, resp.Code)
+	}
+	if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "LIMIT_EXCEEDED" {
+		t.Errorf("Expected LIMIT_EXCEEDED, got %+v", body)
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestCreateLocationIfNoneMatch(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	yaba := dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)}
+
+	resp := api.Post("/locations", "If-None-Match: *", yaba)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d while absent, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	etag := resp.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `"`) || len(etag) < 3 {
+		t.Fatalf("Expected a strong ETag on the created location, got %q", etag)
+	}
+
+	// A different body under the same name still fails the condition, and
+	// is tagged with the location that exists
+	yaba.Latitude = ptr(6.6)
+	resp = api.Post("/locations", "If-None-Match: *", yaba)
+	if resp.Code != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status %d while present, got %d: %s", http.StatusPreconditionFailed, resp.Code, resp.Body.String())
+	}
+	if got := resp.Header().Get("ETag"); got != etag {
+		t.Errorf("Expected the existing ETag %s, got %s", etag, got)
+	}
+	if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "PRECONDITION_FAILED" || !strings.Contains(body.Detail, "Yaba") {
+		t.Errorf("Expected PRECONDITION_FAILED naming Yaba, got %+v", body)
+	}
+
+	// Without the header a taken name is still a conflict
+	resp = api.Post("/locations", yaba)
+	if resp.Code != http.StatusConflict {
+		t.Errorf("Expected status %d without the header, got %d", http.StatusConflict, resp.Code)
+	}
+
+	// A name taken by an alias is reported with the owner's tag
+	api.Post("/locations/Yaba/aliases", map[string]string{"alias": "Tejuosho"})
+	resp = api.Post("/locations", "If-None-Match: *", dto.LocationRequest{Name: "Tejuosho", Latitude: ptr(6.5), Longitude: ptr(3.36)})
+	if resp.Code != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status %d for an alias, got %d: %s", http.StatusPreconditionFailed, resp.Code, resp.Body.String())
+	}
+	if body := decodeCodedError(t, resp.Body.Bytes()); !strings.Contains(body.Detail, "Yaba") || resp.Header().Get("ETag") == "" {
+		t.Errorf("Expected the alias owner Yaba and its ETag, got %+v %q", body, resp.Header().Get("ETag"))
+	}
+
+	// Only * is understood
+	resp = api.Post("/locations", `If-None-Match: "abc"`, dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for an entity tag, got %d", http.StatusUnprocessableEntity, resp.Code)
+	}
+}
+
+func TestCreateLocationIfNoneMatchRace(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	const racers = 16
+
+	var wg sync.WaitGroup
+	codes := make([]int, racers)
+	etags := make([]string, racers)
+	start := make(chan struct{})
+	for i := range racers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			resp := api.Post("/locations", "If-None-Match: *", dto.LocationRequest{Name: "Lekki", Latitude: ptr(6.4474), Longitude: ptr(3.4723)})
+			codes[i] = resp.Code
+			etags[i] = resp.Header().Get("ETag")
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	created := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusPreconditionFailed:
+		default:
+			t.Errorf("Expected 201 or 412, got %d", code)
+		}
+		if etags[i] != etags[0] {
+			t.Errorf("Expected every answer tagged with the one location, got %s and %s", etags[i], etags[0])
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected exactly one creation, got %d", created)
+	}
+}
+
+func TestGetLocationIfNoneMatch(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+
+	resp := api.Get("/locations/Yaba")
+	etag := resp.Header().Get("ETag")
+	if resp.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d %q", resp.Code, etag)
+	}
+	for _, header := range []string{etag, `"stale", ` + etag, "W/" + etag, "*"} {
+		resp = api.Get("/locations/Yaba", "If-None-Match: "+header)
+		if resp.Code != http.StatusNotModified || resp.Body.Len() != 0 || resp.Header().Get("ETag") != etag {
+			t.Errorf("Expected an empty 304 with the ETag for If-None-Match %s, got %d %q: %s", header, resp.Code, resp.Header().Get("ETag"), resp.Body.String())
+		}
+	}
+
+	// A change gives the location a new tag, which the old one no longer matches
+	api.Post("/locations/Yaba/aliases", map[string]string{"alias": "Tejuosho"})
+	resp = api.Get("/locations/Yaba", "If-None-Match: "+etag)
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") == etag || resp.Header().Get("ETag") == "" {
+		t.Errorf("Expected 200 with a new ETag after the change, got %d %q", resp.Code, resp.Header().Get("ETag"))
+	}
+}
+
+func TestWriteLocationIfMatch(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	etag := api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)}).Header().Get("ETag")
+
+	resp := api.Post("/locations/Yaba/aliases", "If-Match: "+etag, map[string]string{"alias": "Tejuosho"})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected the alias added while the ETag matched, got %d: %s", resp.Code, resp.Body.String())
+	}
+	current := resp.Header().Get("ETag")
+
+	// The stale tag no longer matches, and the answer carries the current one
+	for _, header := range []string{etag, "W/" + current} {
+		resp = api.Delete("/locations/Yaba", "If-Match: "+header)
+		if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusPreconditionFailed || body.Code != "LOCATION_MODIFIED" ||
+			!strings.Contains(body.Detail, "Yaba") || resp.Header().Get("ETag") != current {
+			t.Errorf("Expected 412 LOCATION_MODIFIED with the current ETag for If-Match %s, got %d %+v %q", header, resp.Code, body, resp.Header().Get("ETag"))
+		}
+	}
+	if resp := api.Get("/locations/Yaba"); resp.Code != http.StatusOK {
+		t.Fatalf("Expected the location kept after failed deletes, got %d", resp.Code)
+	}
+
+	resp = api.Delete("/locations/Tejuosho/aliases/Tejuosho", "If-Match: "+etag)
+	if resp.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected a stale alias removal refused, got %d", resp.Code)
+	}
+	if resp := api.Delete("/locations/Yaba", "If-Match: "+current); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected the delete with the current ETag to succeed, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Delete("/locations/Yaba", "If-Match: *"); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected a missing location to answer 404, got %d", resp.Code)
+	}
+}
+
+func TestConditionalWritesRequired(t *testing.T) {
+	t.Parallel()
+	api := newTestAPI(t)
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository()), WithConditionalWrites(true)).RegisterRoutes(api)
+	etag := api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)}).Header().Get("ETag")
+
+	resp := api.Delete("/locations/Yaba")
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusPreconditionRequired || body.Code != "PRECONDITION_REQUIRED" {
+		t.Errorf("Expected 428 PRECONDITION_REQUIRED without If-Match, got %d %+v", resp.Code, body)
+	}
+	if resp := api.Post("/locations/Yaba/aliases", map[string]string{"alias": "Tejuosho"}); resp.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected an alias without If-Match refused, got %d", resp.Code)
+	}
+	if resp := api.Delete("/locations/Yaba", "If-Match: "+etag); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected the conditional delete to succeed, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestWriteLocationIfMatchRace(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	etag := api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)}).Header().Get("ETag")
+	const racers = 16
+
+	// Every racer sends the ETag read before any of them wrote, so only
+	// the first write may apply
+	var wg sync.WaitGroup
+	codes := make([]int, racers)
+	start := make(chan struct{})
+	for i := range racers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			codes[i] = api.Post("/locations/Yaba/aliases", "If-Match: "+etag, map[string]string{"alias": "Tejuosho " + strconv.Itoa(i)}).Code
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	applied := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			applied++
+		case http.StatusPreconditionFailed:
+		default:
+			t.Errorf("Expected 200 or 412, got %d", code)
+		}
+	}
+	if applied != 1 {
+		t.Errorf("Expected exactly one write with the ETag, got %d", applied)
+	}
+	var location dto.LocationResponse
+	if err := json.Unmarshal(api.Get("/locations/Yaba").Body.Bytes(), &location); err != nil || len(location.Aliases) != 1 {
+		t.Errorf("Expected one alias added, got %+v, %v", location, err)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func TestCreateLocationProjected(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+
+	resp := api.Post("/locations", map[string]any{"name": "Lagos", "crs": 32631, "x": 541924.3014, "y": 721189.2172})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	var created dto.LocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	got := geospatial.Coordinate{Latitude: created.Latitude, Longitude: created.Longitude}
+	if d := geospatial.HaversineDistance(got, geospatial.Coordinate{Latitude: 6.5244, Longitude: 3.3792}).Meters(); d > 0.5 {
+		t.Errorf("Expected Lagos stored in WGS-84, got %+v, %.2f m off", got, d)
+	}
+
+	tests := []struct {
+		name   string
+		body   map[string]any
+		status int
+		reason string
+	}{
+		{"outside the zone", map[string]any{"name": "Kano", "crs": 32631, "x": 1100000, "y": 1326829}, http.StatusUnprocessableEntity, dto.CRSOutsideArea},
+		{"missing y", map[string]any{"name": "Kano", "crs": 32632, "x": 455585.5}, http.StatusUnprocessableEntity, dto.CRSMissingXY},
+		{"latitude with a projected crs", map[string]any{"name": "Kano", "crs": 3857, "latitude": 12, "longitude": 8.6}, http.StatusUnprocessableEntity, dto.CRSMixedCoordinates},
+		{"x without a crs", map[string]any{"name": "Kano", "x": 455585.5, "y": 1326829.8}, http.StatusUnprocessableEntity, dto.CRSUnprojectedXY},
+		{"unsupported crs", map[string]any{"name": "Kano", "crs": 27700, "x": 530000, "y": 180000}, http.StatusUnprocessableEntity, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := api.Post("/locations", tt.body)
+			if resp.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, resp.Code, resp.Body.String())
+			}
+			if tt.reason == "" {
+				return
+			}
+			if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "INVALID_CRS_COORDINATES" || !strings.Contains(body.Detail, tt.reason) {
+				t.Errorf("Expected INVALID_CRS_COORDINATES for %s, got %+v", tt.reason, body)
+			}
+		})
+	}
+}
+
+func TestRestoreProjected(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	snapshot := dto.Snapshot{
+		Version: dto.SnapshotVersion,
+		CRS:     32631,
+		Locations: []dto.SnapshotLocation{
+			{ID: "1", Name: "Lagos", Longitude: 541924.3014, Latitude: 721189.2172},
+			{ID: "2", Name: "Kano", Longitude: 1100000, Latitude: 1326829},
+			{ID: "3", Name: "Badagry", Longitude: 486873.8055, Latitude: 709081.4436},
+			{ID: "3", Name: "Yaba", Longitude: 541029.9649, Latitude: 719541.4063},
+		},
+	}
+	resp := setupBackupAPI(t, repo).Post("/admin/restore", snapshot)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var result dto.RestoreResponse
+	json.Unmarshal(resp.Body.Bytes(), &result)
+	want := []dto.RestoreConflictResponse{
+		{Index: 1, ID: "2", Name: "Kano", Reason: domain.RestoreInvalid},
+		{Index: 3, ID: "3", Name: "Yaba", Reason: domain.RestoreIDExists},
+	}
+	if result.Restored != 2 || len(result.Conflicts) != 2 || result.Conflicts[0] != want[0] || result.Conflicts[1] != want[1] {
+		t.Fatalf("Expected Lagos and Badagry restored and conflicts at snapshot indexes, got %+v", result)
+	}
+
+	badagry, err := repo.FindByID("3")
+	if err != nil {
+		t.Fatalf("Failed to find Badagry: %v", err)
+	}
+	got := geospatial.Coordinate{Latitude: badagry.Latitude, Longitude: badagry.Longitude}
+	if d := geospatial.HaversineDistance(got, geospatial.Coordinate{Latitude: 6.415, Longitude: 2.8813}).Meters(); d > 0.5 {
+		t.Errorf("Expected Badagry stored in WGS-84, got %+v, %.2f m off", got, d)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestLocationDescription(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+
+	resp := api.Post("/locations", dto.LocationRequest{Name: "Suva", Latitude: ptr(-18.1416), Longitude: ptr(178.4419), Description: "Ferry wharf\x07, open Sundays"})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	var created dto.LocationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if created.Description != "Ferry wharf, open Sundays" {
+		t.Errorf("Expected the cleaned description, got %q", created.Description)
+	}
+	api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+
+	for query, want := range map[string]int{
+		"name_contains=wharf":                          0,
+		"name_contains=wharf&search_descriptions=true": 1,
+		"name_contains=yaba&search_descriptions=true":  1,
+	} {
+		resp := api.Get("/locations?" + query)
+		var list dto.LocationListResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if list.Count != want {
+			t.Errorf("%s: expected %d locations, got %+v", query, want, list.Locations)
+		}
+	}
+
+	resp = api.Post("/locations", dto.LocationRequest{Name: "Long", Latitude: ptr(6.5), Longitude: ptr(3.4), Description: strings.Repeat("a", 2001)})
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	}
+	if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "DESCRIPTION_TOO_LONG" || !strings.Contains(body.Detail, "2000") {
+		t.Errorf("Expected DESCRIPTION_TOO_LONG naming the limit, got %+v", body)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// DuplicateReportRequest represents the criteria for the duplicate report
+type DuplicateReportRequest struct {
+	RadiusM        float64 `query:"radius_m" minimum:"0" default:"50" doc:"Match locations at most this many metres apart; 0 disables the proximity check"`
+	NameSimilarity float64 `query:"name_similarity" minimum:"0" maximum:"1" default:"0.8" doc:"Match names at least this similar, from 0 to 1; 0 disables the name check"`
+	Match          string  `query:"match" enum:"any,all" default:"any" doc:"Report pairs meeting any enabled criterion, or all of them"`
+}
+
+// DuplicateReportResponse represents clusters of suspected duplicates
+type DuplicateReportResponse struct {
+	Body dto.DuplicateReportResponse `json:"body"`
+}
+
+// MergeLocationsRequest represents the merge of duplicates into a winner
+type MergeLocationsRequest struct {
+	Body dto.MergeRequest `json:"body"`
+}
+
+// MergeLocationsResponse represents the audit entry of a merge
+type MergeLocationsResponse struct {
+	Body dto.MergeResponse `json:"body"`
+}
+
+// DuplicateHandler exposes duplicate detection and merging to operators
+type DuplicateHandler struct {
+	service *service.DuplicateService
+}
+
+// NewDuplicateHandler creates a new duplicate handler
+func NewDuplicateHandler(service *service.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{service: service}
+}
+
+// RegisterRoutes registers the duplicate admin routes with the Huma API
+func (h *DuplicateHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "find-duplicate-locations",
+		Method:      http.MethodGet,
+		Path:        "/locations/duplicates",
+		Summary:     "Find Duplicate Locations",
+		Description: "Report clusters of locations that are suspiciously close together or have similar names",
+		Tags:        []string{"Admin"},
+	}, h.FindDuplicates)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "merge-locations",
+		Method:      http.MethodPost,
+		Path:        "/locations/merge",
+		Summary:     "Merge Duplicate Locations",
+		Description: "Delete the losers in favour of the winner and record an audit entry. Nothing changes if any named location is missing.",
+		Tags:        []string{"Admin"},
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	}, h.MergeLocations)
+}
+
+// FindDuplicates handles GET /locations/duplicates requests
+func (h *DuplicateHandler) FindDuplicates(ctx context.Context, input *DuplicateReportRequest) (*DuplicateReportResponse, error) {
+	clusters, err := h.service.FindDuplicates(service.DuplicateCriteria{
+		Radius:         geospatial.Meters(input.RadiusM),
+		NameSimilarity: input.NameSimilarity,
+		RequireBoth:    input.Match == "all",
+	})
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to find duplicate locations"))
+	}
+
+	return &DuplicateReportResponse{
+		Body: dto.FromDuplicateClusters(clusters),
+	}, nil
+}
+
+// MergeLocations handles POST /locations/merge requests
+func (h *DuplicateHandler) MergeLocations(ctx context.Context, input *MergeLocationsRequest) (*MergeLocationsResponse, error) {
+	actor := auditActor(ctx)
+
+	audit, err := h.service.MergeLocations(input.Body.Winner, input.Body.Losers, actor)
+	if err != nil {
+		var notFound *domain.MergeNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "MERGE_LOCATION_NOT_FOUND", "Location "+notFound.Name+" not found").
+				With("name", notFound.Name))
+		}
+		if errors.Is(err, domain.ErrInvalidMerge) {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadRequest, "MERGE_INVALID", "The winner must not be a loser and names must not repeat"))
+		}
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to merge locations"))
+	}
+
+	return &MergeLocationsResponse{
+		Body: dto.FromMergeAudit(audit),
+	}, nil
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+func setupDuplicateAPI(t *testing.T) (humatest.TestAPI, *memory.InMemoryLocationRepository) {
+	repo := memory.NewInMemoryLocationRepository()
+	for _, seed := range []domain.Location{
+		{Name: "Leeta Lekki Phase 1", Latitude: 6.4474, Longitude: 3.4720},
+		{Name: "Leeta Lekki Phase I", Latitude: 6.4475, Longitude: 3.4721},
+		{Name: "Ikeja Station", Latitude: 6.6018, Longitude: 3.3515},
+	} {
+		location, _ := domain.NewLocation(seed.Name, seed.Latitude, seed.Longitude)
+		repo.Save(location)
+	}
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(i18n.Middleware)
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+		{Name: "ops", Key: "ops-key", Scopes: []auth.Scope{auth.ScopeAdmin}},
+	})))
+	NewDuplicateHandler(service.NewDuplicateService(repo, repo, nil)).RegisterRoutes(api)
+	return api, repo
+}
+
+func TestFindDuplicatesReport(t *testing.T) {
+	api, _ := setupDuplicateAPI(t)
+
+	if resp := api.Get("/locations/duplicates", "X-API-Key: partner-key"); resp.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for a non-admin key, got %d", http.StatusForbidden, resp.Code)
+	}
+
+	resp := api.Get("/locations/duplicates?radius_m=50&name_similarity=0.8", "X-API-Key: ops-key")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var report dto.DuplicateReportResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if report.Count != 1 || len(report.Clusters[0].Locations) != 2 || len(report.Clusters[0].Pairs) != 1 {
+		t.Fatalf("Expected one cluster of two, got %+v", report)
+	}
+	pair := report.Clusters[0].Pairs[0]
+	if pair.A != "Leeta Lekki Phase 1" || pair.B != "Leeta Lekki Phase I" || pair.DistanceM > 20 || pair.NameSimilarity < 0.9 {
+		t.Errorf("Unexpected pair: %+v", pair)
+	}
+
+	resp = api.Get("/locations/duplicates?name_similarity=1.5", "X-API-Key: ops-key")
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for similarity above 1, got %d", http.StatusUnprocessableEntity, resp.Code)
+	}
+}
+
+func TestMergeLocationsEndpoint(t *testing.T) {
+	api, repo := setupDuplicateAPI(t)
+
+	resp := api.Post("/locations/merge", "X-API-Key: ops-key", "Accept-Language: fr", dto.MergeRequest{
+		Winner: "Leeta Lekki Phase 1",
+		Losers: []string{"Leeta Lekki Phase I", "Missing"},
+	})
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d, got %d", http.StatusNotFound, resp.Code)
+	}
+	body := decodeCodedError(t, resp.Body.Bytes())
+	if body.Code != "MERGE_LOCATION_NOT_FOUND" || body.Detail != "Emplacement Missing introuvable" {
+		t.Errorf("Unexpected error: %+v", body)
+	}
+	if _, err := repo.FindByName("Leeta Lekki Phase I"); err != nil {
+		t.Errorf("Expected the failed merge to keep every loser, got %v", err)
+	}
+
+	resp = api.Post("/locations/merge", "X-API-Key: ops-key", dto.MergeRequest{
+		Winner: "Leeta Lekki Phase 1",
+		Losers: []string{"Leeta Lekki Phase 1"},
+	})
+	if resp.Code != http.StatusBadRequest || decodeCodedError(t, resp.Body.Bytes()).Code != "MERGE_INVALID" {
+		t.Errorf("Expected MERGE_INVALID, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Post("/locations/merge", "X-API-Key: ops-key", dto.MergeRequest{
+		Winner: "Leeta Lekki Phase 1",
+		Losers: []string{"Leeta Lekki Phase I"},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var merged dto.MergeResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &merged); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if merged.Actor != "ops" || merged.Winner != "Leeta Lekki Phase 1" || len(merged.Losers) != 1 || merged.Losers[0].Name != "Leeta Lekki Phase I" {
+		t.Errorf("Unexpected merge response: %+v", merged)
+	}
+	if merges, _ := repo.Merges(); len(merges) != 1 {
+		t.Errorf("Expected one audit entry, got %d", len(merges))
+	}
+}
+
+func TestMergeLocationsAnonymousActor(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	for _, name := range []string{"Leeta Lekki Phase 1", "Leeta Lekki Phase I"} {
+		location, _ := domain.NewLocation(name, 6.4474, 3.4720)
+		repo.Save(location)
+	}
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithContext(ctx, middleware.WithClientIP(ctx.Context(), "203.0.113.7")))
+	})
+	NewDuplicateHandler(service.NewDuplicateService(repo, repo, nil)).RegisterRoutes(api)
+
+	resp := api.Post("/locations/merge", dto.MergeRequest{Winner: "Leeta Lekki Phase 1", Losers: []string{"Leeta Lekki Phase I"}})
+	var merged dto.MergeResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &merged); err != nil || resp.Code != http.StatusOK {
+		t.Fatalf("Expected the merge made, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if merged.Actor != "203.0.113.7" {
+		t.Errorf("Expected an anonymous merge audited under the client IP, got %q", merged.Actor)
+	}
+}
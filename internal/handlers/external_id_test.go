@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func setupExternalIDAPI(t *testing.T, ids *dto.ExternalIDs) humatest.TestAPI {
+	t.Helper()
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Transformers = append(config.Transformers, middleware.EncodeIDs)
+	_, api := humatest.New(t, config)
+	api.UseMiddleware(middleware.ExternalIDs(ids))
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository())).RegisterRoutes(api)
+	for _, name := range []string{"Ikeja", "Lekki", "Yaba"} {
+		if resp := api.Post("/locations", dto.LocationRequest{Name: name, Latitude: ptr(6.5), Longitude: ptr(3.4)}); resp.Code != http.StatusCreated {
+			t.Fatalf("Failed to create %s: %s", name, resp.Body.String())
+		}
+	}
+	return api
+}
+
+func TestExternalIDsRoundTrip(t *testing.T) {
+	t.Parallel()
+	ids := dto.NewExternalIDs([]byte("deployment-one-key"))
+	for _, id := range []string{"0", "1", "42", "18446744073709551615"} {
+		external := ids.Encode(id)
+		if external == id {
+			t.Errorf("Expected %s to be encoded", id)
+		}
+		if got, ok := ids.Decode(external); !ok || got != id {
+			t.Errorf("Expected %s back from %s, got %q, %v", id, external, got, ok)
+		}
+	}
+
+	// Every altered byte is rejected, as are IDs of the wrong size
+	raw, _ := base64.RawURLEncoding.DecodeString(ids.Encode("42"))
+	for i := range raw {
+		tampered := append([]byte(nil), raw...)
+		tampered[i] ^= 0x01
+		if id, ok := ids.Decode(base64.RawURLEncoding.EncodeToString(tampered)); ok {
+			t.Errorf("Expected byte %d altered to be rejected, got %s", i, id)
+		}
+	}
+	for _, forged := range []string{"", "42", "NDI", "not base64!", base64.RawURLEncoding.EncodeToString(make([]byte, 16))} {
+		if id, ok := ids.Decode(forged); ok {
+			t.Errorf("Expected %q to be rejected, got %s", forged, id)
+		}
+	}
+
+	// Another key gives the same row another ID, and cannot read this one's
+	other := dto.NewExternalIDs([]byte("deployment-two-key"))
+	if ids.Encode("42") == other.Encode("42") {
+		t.Errorf("Expected different keys to give different external IDs")
+	}
+	if _, ok := other.Decode(ids.Encode("42")); ok {
+		t.Errorf("Expected another key's external ID to be rejected")
+	}
+
+	// Without an encoding IDs are shown as they are
+	var none *dto.ExternalIDs
+	if got, ok := none.Decode(none.Encode("42")); !ok || got != "42" {
+		t.Errorf("Expected IDs unchanged without an encoding, got %q", got)
+	}
+}
+
+func TestExternalIDsInResponses(t *testing.T) {
+	t.Parallel()
+	list := func(t *testing.T, api humatest.TestAPI, path string) dto.LocationListResponse {
+		t.Helper()
+		resp := api.Get(path)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var body dto.LocationListResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return body
+	}
+
+	one := dto.NewExternalIDs([]byte("deployment-one-key"))
+	two := dto.NewExternalIDs([]byte("deployment-two-key"))
+	apiOne, apiTwo := setupExternalIDAPI(t, one), setupExternalIDAPI(t, two)
+
+	first := list(t, apiOne, "/locations?name=Ikeja").Locations[0]
+	second := list(t, apiTwo, "/locations?name=Ikeja").Locations[0]
+	if first.ID == second.ID {
+		t.Errorf("Expected deployments with different keys to show different IDs, both showed %s", first.ID)
+	}
+	if id, ok := one.Decode(first.ID); !ok || id != "1" {
+		t.Errorf("Expected the first location's external ID, got %s", first.ID)
+	}
+	resp := apiOne.Get("/nearest?lat=6.5&lng=3.4")
+	var nearest dto.NearestLocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &nearest)
+	if _, ok := one.Decode(nearest.Location.ID); !ok {
+		t.Errorf("Expected the nearest location's external ID, got %s", nearest.Location.ID)
+	}
+
+	// Cursors carry external IDs and are read back with them
+	var names []string
+	path := "/locations?limit=1"
+	for path != "" {
+		page := list(t, apiOne, path)
+		for _, location := range page.Locations {
+			names = append(names, location.Name)
+		}
+		path = ""
+		if page.NextCursor != "" {
+			if page.NextCursor != page.Locations[0].ID {
+				t.Errorf("Expected the cursor to be the last external ID, got %s", page.NextCursor)
+			}
+			path = "/locations?limit=1&cursor=" + page.NextCursor
+		}
+	}
+	if len(names) != 3 {
+		t.Errorf("Expected every location across the pages, got %v", names)
+	}
+
+	// Raw IDs, the old cursors and another deployment's cursors are refused
+	for _, cursor := range []string{"1", "MQ", second.ID} {
+		if resp := apiOne.Get("/locations?limit=1&cursor=" + cursor); resp.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for cursor %s, got %d", http.StatusBadRequest, cursor, resp.Code)
+		}
+	}
+
+	// Without an encoding the real IDs are shown
+	if plain := list(t, setupExternalIDAPI(t, nil), "/locations?name=Ikeja").Locations[0]; plain.ID != "1" {
+		t.Errorf("Expected the real ID without an encoding, got %s", plain.ID)
+	}
+}
+
+func TestExternalIDsInErrorDetails(t *testing.T) {
+	t.Parallel()
+	ids := dto.NewExternalIDs([]byte("deployment-one-key"))
+	api := setupExternalIDAPI(t, ids)
+
+	// Every location sits at one point, so the lookup lists them all
+	resp := api.Get("/locations/at?lat=6.5&lng=3.4")
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusConflict, resp.Code, resp.Body.String())
+	}
+	if raw := regexp.MustCompile(`"id":\s*"\d+"`).FindString(resp.Body.String()); raw != "" {
+		t.Errorf("Expected no raw ID in the candidates, found %s in %s", raw, resp.Body.String())
+	}
+	var conflict struct {
+		Errors []struct {
+			Value dto.LocationResponse `json:"value"`
+		} `json:"errors"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &conflict)
+	if len(conflict.Errors) != 3 {
+		t.Fatalf("Expected three candidates, got %s", resp.Body.String())
+	}
+	for _, candidate := range conflict.Errors {
+		if _, ok := ids.Decode(candidate.Value.ID); !ok {
+			t.Errorf("Expected %s listed by external ID, got %s", candidate.Value.Name, candidate.Value.ID)
+		}
+	}
+}
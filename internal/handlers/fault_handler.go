@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+// InjectFaultsRequest represents a request to replace the injected faults
+type InjectFaultsRequest struct {
+	Body dto.InjectFaultsRequest `json:"body"`
+}
+
+// FaultPlanResponse represents the faults in effect
+type FaultPlanResponse struct {
+	Body dto.FaultPlanResponse `json:"body"`
+}
+
+// FaultHandler lets operators inject repository faults to rehearse
+// incidents
+type FaultHandler struct {
+	faults domain.FaultInjector
+}
+
+// NewFaultHandler creates a new fault handler. Register it only when
+// fault injection is enabled.
+func NewFaultHandler(faults domain.FaultInjector) *FaultHandler {
+	return &FaultHandler{faults: faults}
+}
+
+// RegisterRoutes registers the fault injection admin routes with the
+// Huma API
+func (h *FaultHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-faults",
+		Method:      http.MethodGet,
+		Path:        "/admin/faults",
+		Summary:     "Get Injected Faults",
+		Description: "The faults being injected into the location repository, and when they are cleared.",
+		Tags:        []string{"Admin"},
+	}, h.GetFaults)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "inject-faults",
+		Method:      http.MethodPut,
+		Path:        "/admin/faults",
+		Summary:     "Inject Faults",
+		Description: "Replace the faults injected into the location repository: per method, a share of calls that fail " +
+			"with a given error and latency added to every call. Faults are cleared on their own after `duration_seconds`, " +
+			"at most the configured maximum, so a forgotten rehearsal ends by itself.",
+		Tags: []string{"Admin"},
+	}, h.InjectFaults)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "clear-faults",
+		Method:        http.MethodDelete,
+		Path:          "/admin/faults",
+		Summary:       "Clear Faults",
+		Description:   "Stop injecting faults into the location repository",
+		Tags:          []string{"Admin"},
+		DefaultStatus: http.StatusNoContent,
+	}, h.ClearFaults)
+}
+
+// GetFaults handles GET /admin/faults requests
+func (h *FaultHandler) GetFaults(ctx context.Context, input *struct{}) (*FaultPlanResponse, error) {
+	return &FaultPlanResponse{Body: dto.FromFaultPlan(h.faults.Faults())}, nil
+}
+
+// InjectFaults handles PUT /admin/faults requests
+func (h *FaultHandler) InjectFaults(ctx context.Context, input *InjectFaultsRequest) (*FaultPlanResponse, error) {
+	seen := make(map[string]bool, len(input.Body.Faults))
+	for i, fault := range input.Body.Faults {
+		if seen[fault.Method] {
+			name := fmt.Sprintf("faults[%d].method", i)
+			validationErr := apierrors.NewValidationError(map[string]string{
+				name: i18n.Translate(ctx, "validation.invalid", name+" is invalid", map[string]string{"field": name}),
+			})
+			validationErr.Message = i18n.Translate(ctx, validationErr.Code, "Validation failed", nil)
+			return nil, apierrors.ToHuma(ctx, validationErr)
+		}
+		seen[fault.Method] = true
+	}
+
+	plan, err := h.faults.InjectFaults(input.Body.ToDomain(), time.Duration(input.Body.DurationSeconds)*time.Second)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.BadRequest(err.Error()))
+	}
+	return &FaultPlanResponse{Body: dto.FromFaultPlan(plan)}, nil
+}
+
+// ClearFaults handles DELETE /admin/faults requests
+func (h *FaultHandler) ClearFaults(ctx context.Context, input *struct{}) (*struct{}, error) {
+	h.faults.ClearFaults()
+	return nil, nil
+}
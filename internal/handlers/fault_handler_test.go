@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/faults"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestInjectFaults(t *testing.T) {
+	injector := faults.NewInjector(0)
+	repo := faults.NewFaultyLocationRepository(memory.NewInMemoryLocationRepository(), injector)
+	api := newTestAPI(t)
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+	NewFaultHandler(injector).RegisterRoutes(api)
+
+	if resp := api.Post("/locations", map[string]any{"name": "Ikeja", "latitude": 6.6, "longitude": 3.35}); resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.Code)
+	}
+
+	resp := api.Put("/admin/faults", map[string]any{
+		"faults":           []map[string]any{{"method": "FindNearest", "error_rate": 1}},
+		"duration_seconds": 60,
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var plan dto.FaultPlanResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &plan); err != nil {
+		t.Fatalf("Failed to decode the plan: %v", err)
+	}
+	if len(plan.Faults) != 1 || plan.Faults[0].Method != "FindNearest" || plan.Faults[0].Error != "internal" || plan.ExpiresAt == nil {
+		t.Fatalf("Expected an internal fault on FindNearest with an expiry, got %+v", plan)
+	}
+
+	resp = api.Get("/nearest?lat=6.5&lng=3.4")
+	if resp.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d while faulted, got %d", http.StatusInternalServerError, resp.Code)
+	}
+	if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "INTERNAL_SERVER_ERROR" {
+		t.Errorf("Expected INTERNAL_SERVER_ERROR, got %+v", body)
+	}
+	if resp := api.Get("/locations"); resp.Code != http.StatusOK {
+		t.Errorf("Expected methods without faults to answer %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	if resp := api.Delete("/admin/faults"); resp.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, resp.Code)
+	}
+	if resp := api.Get("/nearest?lat=6.5&lng=3.4"); resp.Code != http.StatusOK {
+		t.Fatalf("Expected nearest to recover once cleared, got %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/admin/faults")
+	plan = dto.FaultPlanResponse{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &plan); err != nil || len(plan.Faults) != 0 || plan.ExpiresAt != nil {
+		t.Errorf("Expected no faults in effect, got %s", resp.Body.String())
+	}
+}
+
+func TestInjectFaultsValidation(t *testing.T) {
+	api := newTestAPI(t)
+	NewFaultHandler(faults.NewInjector(0)).RegisterRoutes(api)
+
+	tests := []struct {
+		name     string
+		faults   []map[string]any
+		location string
+	}{
+		{"unknown method", []map[string]any{{"method": "DropTable", "error_rate": 1}}, "body.faults[0].method"},
+		{"rate above 1", []map[string]any{{"method": "Save", "error_rate": 1.5}}, "body.faults[0].error_rate"},
+		{"unknown error", []map[string]any{{"method": "Save", "error_rate": 1, "error": "teapot"}}, "body.faults[0].error"},
+		{"repeated method", []map[string]any{{"method": "Save", "error_rate": 1}, {"method": "Save", "latency_ms": 10}}, "body.faults[1].method"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := api.Put("/admin/faults", map[string]any{"faults": tt.faults})
+			if resp.Code != http.StatusBadRequest && resp.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected the faults to be rejected, got %d", resp.Code)
+			}
+			body := decodeCodedError(t, resp.Body.Bytes())
+			if body.Code != "VALIDATION_ERROR" || len(body.Errors) != 1 || body.Errors[0].Location != tt.location {
+				t.Errorf("Expected a VALIDATION_ERROR at %s, got %+v", tt.location, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// LocationFilterParams are the query parameters every location listing
+// accepts. Embed it in a request so each endpoint parses and rejects
+// filters the same way.
+type LocationFilterParams struct {
+	CreatedAfter       time.Time `query:"created_after" example:"2025-07-01T00:00:00Z" doc:"Only locations created at or after this RFC 3339 time"`
+	CreatedBefore      time.Time `query:"created_before" example:"2025-08-01T00:00:00Z" doc:"Only locations created at or before this RFC 3339 time; must be later than created_after"`
+	NameContains       string    `query:"name_contains" maxLength:"100" example:"lek" doc:"Only locations whose name contains this text, ignoring case"`
+	SearchDescriptions bool      `query:"search_descriptions" doc:"Also match name_contains against descriptions"`
+	BBox               string    `query:"bbox" example:"3.3,6.4,3.5,6.55" doc:"Only locations inside min_lng,min_lat,max_lng,max_lat, edges included; min_lng greater than max_lng crosses the antimeridian"`
+	Region             string    `query:"region" maxLength:"64" example:"Lagos" doc:"Only locations in this region"`
+}
+
+// filter parses and validates the parameters
+func (p LocationFilterParams) filter() (domain.LocationFilter, error) {
+	filter := domain.LocationFilter{
+		CreatedAfter:       p.CreatedAfter,
+		CreatedBefore:      p.CreatedBefore,
+		NameContains:       p.NameContains,
+		SearchDescriptions: p.SearchDescriptions,
+		Region:             p.Region,
+	}
+	if p.BBox != "" {
+		box, err := parseBoundingBox(p.BBox)
+		if err != nil {
+			return domain.LocationFilter{}, err
+		}
+		filter.BBox = box
+	}
+	return filter, filter.Validate()
+}
+
+// parseBoundingBox reads min_lng,min_lat,max_lng,max_lat
+func parseBoundingBox(s string) (*domain.BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, domain.ErrInvalidBoundingBox
+	}
+	var values [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, domain.ErrInvalidBoundingBox
+		}
+		values[i] = v
+	}
+	return &domain.BoundingBox{MinLongitude: values[0], MinLatitude: values[1], MaxLongitude: values[2], MaxLatitude: values[3]}, nil
+}
+
+// OpenFilterParams select locations open at a time, read against each
+// station's opening hours
+type OpenFilterParams struct {
+	OpenAt  time.Time `query:"open_at" example:"2025-08-18T21:30:00+01:00" doc:"Only locations open at this RFC 3339 time"`
+	OpenNow bool      `query:"open_now" doc:"Only locations open now; cannot be combined with open_at"`
+}
+
+// open parses and validates the parameters
+func (p OpenFilterParams) open() (domain.OpenAt, error) {
+	open := domain.OpenAt{Time: p.OpenAt, Now: p.OpenNow}
+	return open, open.Validate()
+}
+
+// filterError maps an invalid filter to its API error, or returns nil when
+// err is not a filter error
+func filterError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCreatedRange):
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_CREATED_RANGE", "created_after must be before created_before"))
+	case errors.Is(err, domain.ErrInvalidBoundingBox):
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_BBOX",
+			"bbox must be min_lng,min_lat,max_lng,max_lat with coordinates in range and min_lat at most max_lat"))
+	case errors.Is(err, domain.ErrOpenFilterConflict):
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "OPEN_FILTER_CONFLICT", "open_at and open_now cannot be combined"))
+	case errors.Is(err, domain.ErrInvalidRegion):
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_REGION", "region is not one of the configured regions"))
+	case errors.Is(err, domain.ErrRegionRequired):
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "REGION_REQUIRED", "region is required"))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestListLocationsFilters(t *testing.T) {
+	t.Parallel()
+	// Station i is at (i, i)
+	api := setupPaginatedAPI(t, 5)
+
+	tests := []struct {
+		query string
+		want  []string
+		total int
+	}{
+		{"name_contains=STATION%203", []string{"Station 3"}, 0},
+		{"bbox=1.5,1.5,4,4", []string{"Station 2", "Station 3", "Station 4"}, 0},
+		{"bbox=1.5,1.5,4,4&name_contains=4", []string{"Station 4"}, 0},
+		{"bbox=4.5,0,-170,10", []string{"Station 5"}, 0},
+		{"bbox=1.5,1.5,4,4&page=2&page_size=2", []string{"Station 4"}, 3},
+	}
+	for _, tt := range tests {
+		resp := api.Get("/locations?" + tt.query)
+		if resp.Code != http.StatusOK {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.query, http.StatusOK, resp.Code, resp.Body.String())
+			continue
+		}
+		var body dto.LocationListResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		var names []string
+		for _, location := range body.Locations {
+			names = append(names, location.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) || body.Total != tt.total {
+			t.Errorf("%s: expected %v of %d, got %v of %d", tt.query, tt.want, tt.total, names, body.Total)
+		}
+	}
+
+	for _, query := range []string{"bbox=1,2,3", "bbox=a,b,c,d", "bbox=0,10,1,5", "bbox=0,0,181,1"} {
+		resp := api.Get("/locations?" + query)
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusUnprocessableEntity, resp.Code)
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "INVALID_BBOX" {
+			t.Errorf("%s: expected INVALID_BBOX, got %+v", query, body)
+		}
+	}
+}
@@ -5,18 +5,93 @@ import (
 	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/scheduler"
 )
 
+type HealthRequest struct {
+	Verbose bool `query:"verbose" doc:"Include the status of background jobs, of the in-memory store, of cache priming and recent readiness checks"`
+}
+
 type HealthResponse struct {
 	Body struct {
-		Status string `json:"status" example:"ok"`
+		Status string                  `json:"status" example:"ok" doc:"Always ok while the process is serving"`
+		Jobs   []dto.JobStatusResponse `json:"jobs,omitempty" doc:"Background job status, when verbose"`
+		Store  *dto.StoreStatsResponse `json:"store,omitempty" doc:"In-memory store summary, when verbose and metrics are enabled"`
+		Cache  *dto.CachePrimeResponse `json:"cache_priming,omitempty" doc:"Startup cache priming, when verbose and CACHE_PRIME_ON_START is set"`
+		Ready  *dto.ReadinessResponse  `json:"readiness,omitempty" doc:"Readiness with recent checks and transitions, when verbose"`
 	} `json:"body"`
 }
 
-type HealthHandler struct{}
+// ReadinessResponse answers 200 while the instance is ready and 503 once
+// it is not
+type ReadinessResponse struct {
+	Status int
+	Body   dto.ReadinessResponse `json:"body"`
+}
+
+// JobStatusSource reports the status of background jobs
+type JobStatusSource interface {
+	Statuses() []scheduler.JobStatus
+}
+
+// CachePrimeSource reports startup cache priming
+type CachePrimeSource interface {
+	Status() domain.CachePrimeStatus
+}
+
+// ReadinessSource reports readiness from checks run in the background
+type ReadinessSource interface {
+	Status() domain.ReadinessStatus
+}
+
+type HealthHandler struct {
+	jobs  JobStatusSource
+	store domain.StoreStatsReporter
+	cache CachePrimeSource
+	ready ReadinessSource
+}
+
+// HealthHandlerOption configures optional HealthHandler behaviour
+type HealthHandlerOption func(*HealthHandler)
+
+// WithJobStatus reports background jobs on verbose health checks
+func WithJobStatus(jobs JobStatusSource) HealthHandlerOption {
+	return func(h *HealthHandler) {
+		h.jobs = jobs
+	}
+}
+
+// WithStoreStats summarizes the store on verbose health checks
+func WithStoreStats(store domain.StoreStatsReporter) HealthHandlerOption {
+	return func(h *HealthHandler) {
+		h.store = store
+	}
+}
+
+// WithCachePriming reports startup cache priming on verbose health checks
+func WithCachePriming(cache CachePrimeSource) HealthHandlerOption {
+	return func(h *HealthHandler) {
+		h.cache = cache
+	}
+}
+
+// WithReadiness answers readiness probes from source and reports its
+// recent checks on verbose health checks
+func WithReadiness(source ReadinessSource) HealthHandlerOption {
+	return func(h *HealthHandler) {
+		h.ready = source
+	}
+}
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(opts ...HealthHandlerOption) *HealthHandler {
+	h := &HealthHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *HealthHandler) RegisterRoutes(api huma.API) {
@@ -25,17 +100,55 @@ func (h *HealthHandler) RegisterRoutes(api huma.API) {
 		Method:      http.MethodGet,
 		Path:        "/health",
 		Summary:     "Health Check",
-		Description: "Check if the API is running and healthy",
+		Description: "Check if the API is running and healthy. With `verbose=true` the response also lists background jobs and their last run, summarizes the in-memory store when metrics are enabled, reports whether the cache is still warming when startup priming is on, and lists recent readiness checks.",
 		Tags:        []string{"Health"},
+		Errors:      []int{http.StatusInternalServerError},
 	}, h.HealthCheck)
-}
 
-func (h *HealthHandler) HealthCheck(ctx context.Context, input *struct{}) (*HealthResponse, error) {
-	return &HealthResponse{
-		Body: struct {
-			Status string `json:"status" example:"ok"`
-		}{
-			Status: "ok",
+	huma.Register(api, huma.Operation{
+		OperationID: "readiness-check",
+		Method:      http.MethodGet,
+		Path:        "/ready",
+		Summary:     "Readiness Check",
+		Description: "Check whether the instance should receive traffic. Answers `503` once several dependency checks in a row have failed, and `200` again after several have passed. The checks run in the background, so a probe never waits on the database.",
+		Tags:        []string{"Health"},
+		Responses: map[string]*huma.Response{
+			"503": {Description: "Not ready"},
 		},
-	}, nil
-}
\ No newline at end of file
+	}, h.ReadinessCheck)
+}
+
+func (h *HealthHandler) HealthCheck(ctx context.Context, input *HealthRequest) (*HealthResponse, error) {
+	resp := &HealthResponse{}
+	resp.Body.Status = "ok"
+	if input.Verbose && h.jobs != nil {
+		resp.Body.Jobs = dto.FromJobStatuses(h.jobs.Statuses())
+	}
+	if input.Verbose && h.store != nil {
+		resp.Body.Store = dto.FromStoreStats(h.store.StoreStats())
+	}
+	if input.Verbose && h.cache != nil {
+		resp.Body.Cache = dto.FromCachePrimeStatus(h.cache.Status())
+	}
+	if input.Verbose && h.ready != nil {
+		resp.Body.Ready = dto.FromReadinessStatus(h.ready.Status())
+	}
+	return resp, nil
+}
+
+func (h *HealthHandler) ReadinessCheck(ctx context.Context, input *struct{}) (*ReadinessResponse, error) {
+	resp := &ReadinessResponse{Status: http.StatusOK, Body: dto.ReadinessResponse{Ready: true}}
+	if h.ready == nil {
+		return resp, nil
+	}
+	status := h.ready.Status()
+	resp.Body = dto.ReadinessResponse{
+		Ready:                status.Ready,
+		ConsecutiveFailures:  status.ConsecutiveFailures,
+		ConsecutiveSuccesses: status.ConsecutiveSuccesses,
+	}
+	if !status.Ready {
+		resp.Status = http.StatusServiceUnavailable
+	}
+	return resp, nil
+}
@@ -1,12 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/scheduler"
+	"github.com/jesuloba-world/leeta-task/internal/service"
 )
 
 func setupHealthTestAPI(t *testing.T) humatest.TestAPI {
@@ -35,4 +43,167 @@ func TestHealthCheck(t *testing.T) {
 	if response["status"] != "ok" {
 		t.Errorf("Expected status 'ok', got %v", response["status"])
 	}
-}
\ No newline at end of file
+}
+
+type staticJobs []scheduler.JobStatus
+
+func (s staticJobs) Statuses() []scheduler.JobStatus { return s }
+
+func TestHealthCheckVerboseListsJobs(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewHealthHandler(WithJobStatus(staticJobs{{
+		Name:         "location-stats",
+		Interval:     time.Minute,
+		Runs:         3,
+		Failures:     1,
+		LastStart:    time.Date(2025, 8, 18, 10, 0, 0, 0, time.UTC),
+		LastDuration: 250 * time.Millisecond,
+		LastError:    "database unavailable",
+	}})).RegisterRoutes(api)
+
+	var plain map[string]any
+	json.Unmarshal(api.Get("/health").Body.Bytes(), &plain)
+	if _, ok := plain["jobs"]; ok {
+		t.Errorf("Expected jobs only on verbose checks, got %v", plain)
+	}
+
+	resp := api.Get("/health?verbose=true")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	var verbose struct {
+		Status string                  `json:"status"`
+		Jobs   []dto.JobStatusResponse `json:"jobs"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &verbose); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	want := dto.JobStatusResponse{
+		Name:                "location-stats",
+		IntervalSeconds:     60,
+		Runs:                3,
+		Failures:            1,
+		LastStart:           "2025-08-18T10:00:00Z",
+		LastDurationSeconds: 0.25,
+		LastError:           "database unavailable",
+	}
+	if verbose.Status != "ok" || len(verbose.Jobs) != 1 || verbose.Jobs[0] != want {
+		t.Errorf("Unexpected verbose health: %+v", verbose)
+	}
+}
+
+type staticStore domain.StoreStats
+
+func (s staticStore) StoreStats() domain.StoreStats { return domain.StoreStats(s) }
+
+func TestHealthCheckVerboseSummarizesStore(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewHealthHandler(WithStoreStats(staticStore{
+		Locations:      3,
+		EstimatedBytes: 900,
+		Indexes:        []domain.IndexStats{{Name: "region", Sizes: []int{2, 1}}},
+		Saves:          domain.OperationStats{Count: 4, Total: 8 * time.Millisecond},
+		ReadLockWaits:  domain.OperationStats{Count: 1, Total: time.Millisecond},
+		WriteLockWaits: domain.OperationStats{Count: 1, Total: 3 * time.Millisecond},
+	})).RegisterRoutes(api)
+
+	var body struct {
+		Store *dto.StoreStatsResponse `json:"store"`
+	}
+	json.Unmarshal(api.Get("/health").Body.Bytes(), &body)
+	if body.Store != nil {
+		t.Errorf("Expected no store summary without verbose, got %+v", body.Store)
+	}
+
+	json.Unmarshal(api.Get("/health?verbose=true").Body.Bytes(), &body)
+	store := body.Store
+	if store == nil || store.Locations != 3 || store.EstimatedBytes != 900 || store.Saves != 4 || store.MeanSaveSeconds != 0.002 {
+		t.Fatalf("Expected the store summary, got %+v", store)
+	}
+	if store.LockWaitSamples != 2 || store.MeanLockWaitSeconds != 0.002 {
+		t.Errorf("Expected read and write waits combined, got %d samples averaging %v", store.LockWaitSamples, store.MeanLockWaitSeconds)
+	}
+	if len(store.Indexes) != 1 || store.Indexes[0] != (dto.IndexStatsResponse{Name: "region", Buckets: 2, Largest: 2}) {
+		t.Errorf("Expected the region index summarized, got %+v", store.Indexes)
+	}
+}
+
+type staticPriming domain.CachePrimeStatus
+
+func (s staticPriming) Status() domain.CachePrimeStatus { return domain.CachePrimeStatus(s) }
+
+func TestHealthCheckVerboseReportsCachePriming(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewHealthHandler(WithCachePriming(staticPriming{
+		State:     domain.CachePrimeWarming,
+		Names:     5,
+		StartedAt: time.Date(2025, 9, 8, 9, 0, 0, 0, time.UTC),
+	})).RegisterRoutes(api)
+
+	var body struct {
+		Cache *dto.CachePrimeResponse `json:"cache_priming"`
+	}
+	json.Unmarshal(api.Get("/health").Body.Bytes(), &body)
+	if body.Cache != nil {
+		t.Errorf("Expected no priming state without verbose, got %+v", body.Cache)
+	}
+
+	json.Unmarshal(api.Get("/health?verbose=true").Body.Bytes(), &body)
+	want := dto.CachePrimeResponse{State: "warming", Names: 5, StartedAt: "2025-09-08T09:00:00Z"}
+	if body.Cache == nil || *body.Cache != want {
+		t.Errorf("Expected the priming state, got %+v", body.Cache)
+	}
+}
+
+// blockingPinger fails once released, like a database that hangs and
+// then drops the connection
+type blockingPinger struct {
+	release chan struct{}
+	pinged  chan struct{}
+}
+
+func (p *blockingPinger) PingContext(ctx context.Context) error {
+	p.pinged <- struct{}{}
+	<-p.release
+	return errors.New("connection reset")
+}
+
+func TestReadinessProbeNeverWaitsOnDatabase(t *testing.T) {
+	pinger := &blockingPinger{release: make(chan struct{}), pinged: make(chan struct{}, 1)}
+	readiness := service.NewReadinessService(pinger, 1, 1, 0)
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewHealthHandler(WithReadiness(readiness)).RegisterRoutes(api)
+
+	checked := make(chan struct{})
+	go func() {
+		readiness.Check(context.Background())
+		close(checked)
+	}()
+	<-pinger.pinged
+
+	// The check is stuck on the database; probes answer from the last result
+	probed := make(chan int)
+	go func() { probed <- api.Get("/ready").Code }()
+	select {
+	case code := <-probed:
+		if code != http.StatusOK {
+			t.Errorf("Expected ready while the check is pending, got %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the probe to answer while the check is pending")
+	}
+
+	close(pinger.release)
+	<-checked
+	if resp := api.Get("/ready"); resp.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 once the check failed, got %d", resp.Code)
+	}
+
+	var body struct {
+		Readiness *dto.ReadinessResponse `json:"readiness"`
+	}
+	json.Unmarshal(api.Get("/health?verbose=true").Body.Bytes(), &body)
+	if r := body.Readiness; r == nil || r.Ready || len(r.History) != 1 || r.History[0].Error != "connection reset" || len(r.Transitions) != 1 {
+		t.Errorf("Expected the failed check and the transition, got %+v", body.Readiness)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+type codedErrorBody struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	Errors []struct {
+		Message  string `json:"message"`
+		Location string `json:"location"`
+		Value    any    `json:"value"`
+	} `json:"errors"`
+}
+
+func setupLocalizedAPI(t *testing.T) humatest.TestAPI {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(i18n.Middleware)
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository())).RegisterRoutes(api)
+	return api
+}
+
+func decodeCodedError(t *testing.T, body []byte) codedErrorBody {
+	t.Helper()
+	var decoded codedErrorBody
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Failed to decode error body: %v", err)
+	}
+	return decoded
+}
+
+func TestLocalizedErrorMessages(t *testing.T) {
+	api := setupLocalizedAPI(t)
+
+	tests := []struct {
+		language string
+		detail   string
+		content  string
+	}{
+		{language: "fr-FR,fr;q=0.9", detail: "Emplacement introuvable", content: "fr"},
+		{language: "pt-BR", detail: "Localização não encontrada", content: "pt"},
+		{language: "de", detail: "Location not found", content: "en"},
+		{language: "", detail: "Location not found", content: "en"},
+	}
+
+	for _, tt := range tests {
+		resp := api.Delete("/locations/missing", "Accept-Language: "+tt.language)
+		if resp.Code != http.StatusNotFound {
+			t.Fatalf("Expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+		body := decodeCodedError(t, resp.Body.Bytes())
+		if body.Code != "LOCATION_NOT_FOUND" {
+			t.Errorf("%q: expected stable code LOCATION_NOT_FOUND, got %q", tt.language, body.Code)
+		}
+		if body.Detail != tt.detail {
+			t.Errorf("%q: expected detail %q, got %q", tt.language, tt.detail, body.Detail)
+		}
+		if got := resp.Header().Get("Content-Language"); got != tt.content {
+			t.Errorf("%q: expected Content-Language %q, got %q", tt.language, tt.content, got)
+		}
+	}
+}
+
+func TestLocalizedValidationFieldMessages(t *testing.T) {
+	api := setupLocalizedAPI(t)
+
+	resp := api.Post("/locations", "Accept-Language: fr", dto.LocationRequest{
+		Name:      "Out of range",
+		Latitude:  ptr(91.0),
+		Longitude: ptr(3.4),
+	})
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, resp.Code)
+	}
+
+	body := decodeCodedError(t, resp.Body.Bytes())
+	if body.Code != "VALIDATION_ERROR" {
+		t.Errorf("Expected code VALIDATION_ERROR, got %q", body.Code)
+	}
+	if body.Detail != "La validation a échoué" {
+		t.Errorf("Expected French detail, got %q", body.Detail)
+	}
+	if len(body.Errors) != 1 {
+		t.Fatalf("Expected 1 field error, got %d", len(body.Errors))
+	}
+	if body.Errors[0].Location != "body.latitude" || body.Errors[0].Message != "latitude doit être au plus 90" {
+		t.Errorf("Unexpected field error: %+v", body.Errors[0])
+	}
+}
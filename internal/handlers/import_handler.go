@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// ImportLocationsRequest represents a CSV file to import
+type ImportLocationsRequest struct {
+	Async   bool   `query:"async" doc:"Store the file and import it in the background, answering 202 with a job to poll"`
+	RawBody []byte `contentType:"text/csv"`
+}
+
+// ImportLocationsResponse represents a finished import, or an accepted
+// asynchronous one
+type ImportLocationsResponse struct {
+	Status int
+	Body   dto.ImportJobResponse `json:"body"`
+}
+
+// ImportJobRequest represents an import job ID
+type ImportJobRequest struct {
+	ID string `path:"id" example:"01K4EJ2Z8S3Q9V6W0X1Y2Z3A4B" doc:"Import job ID"`
+}
+
+// ImportStatusResponse represents an import job and its progress
+type ImportStatusResponse struct {
+	Body dto.ImportJobResponse `json:"body"`
+}
+
+// ImportHandler imports locations from CSV files
+type ImportHandler struct {
+	service *service.ImportService
+	config  config.ImportsConfig
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(service *service.ImportService, cfg config.ImportsConfig) *ImportHandler {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 64 << 20
+	}
+	return &ImportHandler{service: service, config: cfg}
+}
+
+// RegisterRoutes registers the import routes with the Huma API
+func (h *ImportHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "import-locations",
+		Method:      http.MethodPost,
+		Path:        "/locations/import",
+		Summary:     "Import Locations",
+		Description: "Create locations from a CSV file whose header names its columns: `name`, `latitude` (or `lat`) and `longitude` (`lng` or `lon`) " +
+			"are required, `region` and `description` optional, and other columns ignored. Each row is created as `POST /locations` would; " +
+			"rows that fail are listed with their line and reason and the rest are imported. " +
+			"With `async=true` the file is stored and imported in the background, and the 202 response carries a job to poll with `GET /imports/{id}`; " +
+			"use it for files too large to import within a request timeout.",
+		Tags:         []string{"Locations"},
+		MaxBodyBytes: int64(h.config.MaxBytes),
+		Errors:       []int{http.StatusUnprocessableEntity},
+	}, h.Import)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-import",
+		Method:      http.MethodGet,
+		Path:        "/imports/{id}",
+		Summary:     "Get Import",
+		Description: "Report an asynchronous import's status, its progress counts and the rows that failed so far. " +
+			"The error report is complete once the status is completed, failed or cancelled. " +
+			"A job whose instance stops while running it is marked failed.",
+		Tags:   []string{"Locations"},
+		Errors: []int{http.StatusNotFound},
+	}, h.Get)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "cancel-import",
+		Method:      http.MethodDelete,
+		Path:        "/imports/{id}",
+		Summary:     "Cancel Import",
+		Description: "Cancel a pending import, or stop a running one after its current batch; locations already imported are kept. " +
+			"A finished import is returned unchanged.",
+		Tags:   []string{"Locations"},
+		Errors: []int{http.StatusNotFound},
+	}, h.Cancel)
+}
+
+// Import handles POST /locations/import requests
+func (h *ImportHandler) Import(ctx context.Context, input *ImportLocationsRequest) (*ImportLocationsResponse, error) {
+	if input.Async {
+		job, err := h.service.Submit(input.RawBody)
+		if err != nil {
+			return nil, importError(ctx, err, "Failed to store the import")
+		}
+		return &ImportLocationsResponse{Status: http.StatusAccepted, Body: dto.FromImportJob(job)}, nil
+	}
+
+	job, err := h.service.Import(ctx, input.RawBody)
+	if err != nil {
+		return nil, importError(ctx, err, "Failed to import locations")
+	}
+	return &ImportLocationsResponse{Status: http.StatusOK, Body: dto.FromImportJob(job)}, nil
+}
+
+// Get handles GET /imports/{id} requests
+func (h *ImportHandler) Get(ctx context.Context, input *ImportJobRequest) (*ImportStatusResponse, error) {
+	job, err := h.service.Get(input.ID)
+	if err != nil {
+		return nil, importError(ctx, err, "Failed to read the import")
+	}
+	return &ImportStatusResponse{Body: dto.FromImportJob(job)}, nil
+}
+
+// Cancel handles DELETE /imports/{id} requests
+func (h *ImportHandler) Cancel(ctx context.Context, input *ImportJobRequest) (*ImportStatusResponse, error) {
+	job, err := h.service.Cancel(input.ID)
+	if err != nil {
+		return nil, importError(ctx, err, "Failed to cancel the import")
+	}
+	return &ImportStatusResponse{Body: dto.FromImportJob(job)}, nil
+}
+
+// importError answers an import the service could not start or find
+func importError(ctx context.Context, err error, internal string) error {
+	switch {
+	case errors.Is(err, domain.ErrImportNotFound):
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "IMPORT_NOT_FOUND", "Import not found"))
+	case errors.Is(err, domain.ErrInvalidImportFile):
+		reason := strings.TrimPrefix(err.Error(), domain.ErrInvalidImportFile.Error()+": ")
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_IMPORT_FILE", "Invalid import file: "+reason).
+			With("reason", reason))
+	}
+	return apierrors.ToHuma(ctx, apierrors.InternalServerError(internal))
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestImportLocations(t *testing.T) {
+	t.Parallel()
+	locations := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	svc := service.NewImportService(memory.NewInMemoryJobRepository(), locations, 2, time.Minute)
+	svc.Start()
+	t.Cleanup(func() { svc.Stop(context.Background()) })
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewImportHandler(svc, config.ImportsConfig{}).RegisterRoutes(api)
+	NewLocationHandler(locations).RegisterRoutes(api)
+
+	decode := func(body []byte) dto.ImportJobResponse {
+		t.Helper()
+		var job dto.ImportJobResponse
+		if err := json.Unmarshal(body, &job); err != nil {
+			t.Fatalf("Failed to decode job: %v", err)
+		}
+		return job
+	}
+	csv := "name,latitude,longitude\n" +
+		"Leeta Ikeja,6.6018,3.3515\n" +
+		"Leeta Yaba,6.5095,\n" +
+		"Leeta Lekki,6.4474,3.472\n"
+
+	t.Run("async", func(t *testing.T) {
+		resp := api.Post("/locations/import?async=true", "Content-Type: text/csv", strings.NewReader(csv))
+		accepted := decode(resp.Body.Bytes())
+		if resp.Code != http.StatusAccepted || accepted.ID == "" || accepted.Total != 3 {
+			t.Fatalf("Expected 202 with a job of 3 rows, got %d %s", resp.Code, resp.Body.String())
+		}
+
+		var job dto.ImportJobResponse
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			resp = api.Get("/imports/" + accepted.ID)
+			if job = decode(resp.Body.Bytes()); job.Status == "completed" {
+				break
+			}
+		}
+		if job.Status != "completed" || job.Processed != 3 || job.Imported != 2 || job.Failed != 1 || job.FinishedAt == "" {
+			t.Fatalf("Expected a completed job with 2 imported and 1 failed, got %+v", job)
+		}
+		if len(job.Errors) != 1 || job.Errors[0].Row != 3 || job.Errors[0].Name != "Leeta Yaba" {
+			t.Errorf("Expected row 3 reported, got %+v", job.Errors)
+		}
+		if resp := api.Get("/locations/Leeta%20Lekki"); resp.Code != http.StatusOK {
+			t.Errorf("Expected Leeta Lekki imported, got %d", resp.Code)
+		}
+
+		resp = api.Delete("/imports/" + accepted.ID)
+		if cancelled := decode(resp.Body.Bytes()); resp.Code != http.StatusOK || cancelled.Status != "completed" {
+			t.Errorf("Expected cancelling a finished job to leave it completed, got %d %+v", resp.Code, cancelled)
+		}
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		resp := api.Post("/locations/import", "Content-Type: text/csv", strings.NewReader("name,lat,lon\nLeeta Wuse,9.0765,7.3986\n"))
+		if job := decode(resp.Body.Bytes()); resp.Code != http.StatusOK || job.ID != "" || job.Status != "completed" || job.Imported != 1 {
+			t.Errorf("Expected 200 with a completed report, got %d %s", resp.Code, resp.Body.String())
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		resp := api.Post("/locations/import?async=true", "Content-Type: text/csv", strings.NewReader("name,latitude\nIkeja,6.6\n"))
+		if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusUnprocessableEntity || body.Code != "INVALID_IMPORT_FILE" {
+			t.Errorf("Expected 422 INVALID_IMPORT_FILE, got %d %+v", resp.Code, body)
+		}
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			resp := api.Do(method, "/imports/missing")
+			if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusNotFound || body.Code != "IMPORT_NOT_FOUND" {
+				t.Errorf("Expected %s of a missing import to answer 404 IMPORT_NOT_FOUND, got %d %+v", method, resp.Code, body)
+			}
+		}
+	})
+}
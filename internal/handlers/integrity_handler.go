@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// StartIntegrityCheckRequest represents the options for an integrity scan
+type StartIntegrityCheckRequest struct {
+	Fix string `query:"fix" enum:"trim" doc:"Apply a safe normalisation while scanning: trim strips whitespace around names unless the trimmed name is taken"`
+}
+
+// IntegrityReportResponse represents the latest integrity scan
+type IntegrityReportResponse struct {
+	Body domain.IntegrityReport `json:"body"`
+}
+
+// IntegrityHandler exposes the data integrity scan to operators
+type IntegrityHandler struct {
+	service *service.IntegrityService
+}
+
+// NewIntegrityHandler creates a new integrity handler
+func NewIntegrityHandler(service *service.IntegrityService) *IntegrityHandler {
+	return &IntegrityHandler{service: service}
+}
+
+// RegisterRoutes registers the integrity admin routes with the Huma API
+func (h *IntegrityHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-integrity-report",
+		Method:      http.MethodGet,
+		Path:        "/admin/integrity-report",
+		Summary:     "Get Integrity Report",
+		Description: "Report of the latest scan of stored locations against the current domain rules: its status, " +
+			"the number of locations with each problem and the offending locations. A running scan reports its progress so far.",
+		Tags: []string{"Admin"},
+	}, h.GetReport)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "start-integrity-check",
+		Method:        http.MethodPost,
+		Path:          "/admin/integrity-report",
+		Summary:       "Start Integrity Check",
+		Description:   "Start a scan in the background and return its running report; poll the report for the result.",
+		Tags:          []string{"Admin"},
+		DefaultStatus: http.StatusAccepted,
+		Errors:        []int{http.StatusConflict},
+	}, h.Start)
+}
+
+// GetReport handles GET /admin/integrity-report requests
+func (h *IntegrityHandler) GetReport(ctx context.Context, input *struct{}) (*IntegrityReportResponse, error) {
+	return &IntegrityReportResponse{Body: h.service.Report()}, nil
+}
+
+// Start handles POST /admin/integrity-report requests
+func (h *IntegrityHandler) Start(ctx context.Context, input *StartIntegrityCheckRequest) (*IntegrityReportResponse, error) {
+	if err := h.service.Start(input.Fix); err != nil {
+		if errors.Is(err, domain.ErrIntegrityCheckRunning) {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusConflict, "INTEGRITY_CHECK_RUNNING", "An integrity check is already running"))
+		}
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to start the integrity check"))
+	}
+	return &IntegrityReportResponse{Body: h.service.Report()}, nil
+}
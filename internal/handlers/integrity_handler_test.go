@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestIntegrityReport(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	repo.Save(&domain.Location{Name: "Ikeja ", Latitude: 6.6018, Longitude: 3.3515})
+	repo.Save(&domain.Location{Name: "Gulf", Latitude: 0, Longitude: 0})
+	svc := service.NewIntegrityService(repo, 0)
+	t.Cleanup(svc.Stop)
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewIntegrityHandler(svc).RegisterRoutes(api)
+
+	decode := func(body []byte) domain.IntegrityReport {
+		t.Helper()
+		var report domain.IntegrityReport
+		if err := json.Unmarshal(body, &report); err != nil {
+			t.Fatalf("Failed to decode report: %v", err)
+		}
+		return report
+	}
+
+	resp := api.Get("/admin/integrity-report")
+	if report := decode(resp.Body.Bytes()); resp.Code != http.StatusOK || report.Status != domain.IntegrityIdle {
+		t.Errorf("Expected an idle report, got %d %+v", resp.Code, report)
+	}
+
+	if resp := api.Post("/admin/integrity-report?fix=delete"); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected an unknown fix to be rejected, got %d", resp.Code)
+	}
+
+	resp = api.Post("/admin/integrity-report?fix=trim")
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, resp.Code, resp.Body.String())
+	}
+
+	var report domain.IntegrityReport
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if report = decode(api.Get("/admin/integrity-report").Body.Bytes()); report.Status != domain.IntegrityRunning {
+			break
+		}
+	}
+	if report.Status != domain.IntegrityComplete || report.Fix != domain.IntegrityFixTrim || report.Scanned != 2 ||
+		report.Counts[domain.IntegrityUntrimmedName] != 1 || report.Counts[domain.IntegrityNullIsland] != 1 {
+		t.Errorf("Expected a complete report of both problems, got %+v", report)
+	}
+	if _, err := repo.FindByName("Ikeja"); err != nil {
+		t.Errorf("Expected Ikeja trimmed, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// checkLimit rejects a request value above its configured maximum with a 422
+// naming the parameter and the limit
+func checkLimit(ctx context.Context, name string, value, max int) error {
+	if value <= max {
+		return nil
+	}
+	apiErr := apierrors.New(http.StatusUnprocessableEntity, "LIMIT_EXCEEDED",
+		fmt.Sprintf("%s exceeds the maximum of %d", name, max))
+	return apierrors.ToHuma(ctx, apiErr.With("name", name).With("max", strconv.Itoa(max)))
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// setupLimitedAPI builds the API from limits loaded from the environment
+func setupLimitedAPI(t *testing.T, count int) humatest.TestAPI {
+	t.Helper()
+	limits := config.LoadConfig().Limits
+
+	repo := memory.NewInMemoryLocationRepository()
+	svc := service.NewLocationService(repo)
+	for i := 0; i < count; i++ {
+		svc.CreateLocation(fmt.Sprintf("Station %d", i), 6.5, 3.3)
+	}
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(svc, WithLimits(limits)).RegisterRoutes(api)
+	NewSpatialHandler(repo, limits).RegisterRoutes(api)
+	return api
+}
+
+func TestPageSizeLimitFromEnv(t *testing.T) {
+	t.Setenv("LIMITS_DEFAULT_PAGE_SIZE", "3")
+	t.Setenv("LIMITS_MAX_PAGE_SIZE", "5")
+	api := setupLimitedAPI(t, 10)
+
+	resp := api.Get("/locations?page=1")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	var body dto.LocationListResponse
+	json.Unmarshal(resp.Body.Bytes(), &body)
+	if body.PageSize != 3 || body.Count != 3 {
+		t.Errorf("Expected default page size 3, got page_size=%d count=%d", body.PageSize, body.Count)
+	}
+
+	if resp := api.Get("/locations?page_size=5"); resp.Code != http.StatusOK {
+		t.Errorf("Expected page_size at the maximum to succeed, got %d", resp.Code)
+	}
+
+	for _, query := range []string{"page_size=6", "limit=6"} {
+		resp := api.Get("/locations?" + query)
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusUnprocessableEntity, resp.Code)
+			continue
+		}
+		body := decodeCodedError(t, resp.Body.Bytes())
+		if body.Code != "LIMIT_EXCEEDED" || !strings.Contains(body.Detail, "maximum of 5") {
+			t.Errorf("%s: expected error naming the limit, got %+v", query, body)
+		}
+	}
+}
+
+func TestBatchSizeLimitFromEnv(t *testing.T) {
+	t.Setenv("LIMITS_DEFAULT_BATCH_SIZE", "2")
+	t.Setenv("LIMITS_MAX_BATCH_SIZE", "4")
+	api := setupLimitedAPI(t, 6)
+
+	resp := api.Post("/admin/verify-spatial?batch_size=5")
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, resp.Code)
+	}
+	if body := decodeCodedError(t, resp.Body.Bytes()); !strings.Contains(body.Detail, "batch_size") {
+		t.Errorf("Expected error to name batch_size, got %q", body.Detail)
+	}
+
+	// Without batch_size the configured default splits the scan
+	resp = api.Post("/admin/verify-spatial")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	batches := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line spatialReportLine
+		json.Unmarshal(scanner.Bytes(), &line)
+		if line.Type != "batch" {
+			continue
+		}
+		batches++
+		if line.Scanned > 2 {
+			t.Errorf("Expected at most 2 rows per batch, got %d", line.Scanned)
+		}
+	}
+	// Six names and six ID index entries in batches of two
+	if batches != 6 {
+		t.Errorf("Expected 6 batches, got %d", batches)
+	}
+}
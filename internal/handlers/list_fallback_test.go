@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// unavailableListRepository fails every listing once down is set
+type unavailableListRepository struct {
+	*memory.InMemoryLocationRepository
+	down bool
+}
+
+func (r *unavailableListRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	if r.down {
+		return nil, errors.New("connection refused")
+	}
+	return r.InMemoryLocationRepository.Find(filter, page, order)
+}
+
+func TestListLocationsServesLastKnownGood(t *testing.T) {
+	repo := &unavailableListRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	svc := service.NewLocationService(repo, service.WithClock(func() time.Time { return now }),
+		service.WithListFallback(cache.NewLastKnownGood(0), time.Minute))
+	api := newTestAPI(t)
+	NewLocationHandler(svc).RegisterRoutes(api)
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+
+	if resp := api.Get("/locations"); resp.Code != http.StatusOK || resp.Header().Get("X-Served-From-Cache") != "" {
+		t.Fatalf("Expected a fresh listing, got %d with %v", resp.Code, resp.Header())
+	}
+
+	repo.down = true
+	now = now.Add(10 * time.Second)
+	resp := api.Get("/locations")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "Ikeja") {
+		t.Fatalf("Expected the last known good listing, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp.Header().Get("X-Served-From-Cache") != "true" {
+		t.Errorf("Expected X-Served-From-Cache: true, got %q", resp.Header().Get("X-Served-From-Cache"))
+	}
+	if want := `110 - "Response is Stale" "Tue, 01 Jul 2025 12:00:00 GMT"`; resp.Header().Get("Warning") != want {
+		t.Errorf("Expected Warning %s, got %q", want, resp.Header().Get("Warning"))
+	}
+
+	if resp := api.Get("/locations", "If-None-Match: \"abc\""); resp.Code != http.StatusInternalServerError {
+		t.Errorf("Expected a conditional request to fail as before, got %d", resp.Code)
+	}
+
+	now = now.Add(time.Minute)
+	if resp := api.Get("/locations"); resp.Code != http.StatusInternalServerError || resp.Header().Get("X-Served-From-Cache") != "" {
+		t.Errorf("Expected a hard failure past the staleness cap, got %d with %v", resp.Code, resp.Header())
+	}
+}
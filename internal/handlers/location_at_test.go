@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestLocationAt(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja Annex", Latitude: ptr(6.60185), Longitude: ptr(3.3515)})
+
+	// The default tolerance is one metre
+	resp := api.Get("/locations/at?lat=6.6018&lng=3.3515")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var location dto.LocationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &location); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if location.Name != "Ikeja" {
+		t.Errorf("Expected Ikeja, got %+v", location)
+	}
+
+	resp = api.Get("/locations/at?lat=6.6019&lng=3.3515")
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d outside the tolerance, got %d", http.StatusNotFound, resp.Code)
+	} else if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "LOCATION_NOT_FOUND" {
+		t.Errorf("Expected LOCATION_NOT_FOUND, got %+v", body)
+	}
+
+	resp = api.Get("/locations/at?lat=6.6018&lng=3.3515&tolerance_m=10")
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusConflict, resp.Code, resp.Body.String())
+	}
+	var conflict struct {
+		Code   string `json:"code"`
+		Errors []struct {
+			Message  string `json:"message"`
+			Location string `json:"location"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &conflict); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if conflict.Code != "AMBIGUOUS_LOCATION" || len(conflict.Errors) != 2 ||
+		conflict.Errors[0].Message != "Ikeja" || conflict.Errors[1].Message != "Ikeja Annex" {
+		t.Errorf("Expected both candidates listed, got %+v", conflict)
+	}
+
+	resp = api.Get("/locations/at?lat=6.6018&lng=3.3515&tolerance_m=101")
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a tolerance above 100 m, got %d", http.StatusUnprocessableEntity, resp.Code)
+	}
+}
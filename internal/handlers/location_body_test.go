@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// sampleCoordinate fills every coordinate of a generated body
+const sampleCoordinate = 1.234567
+
+// showsExactLocations lists the bodies that carry locations as stored on
+// purpose, and why
+var showsExactLocations = map[reflect.Type]string{
+	reflect.TypeFor[dto.Snapshot]():        "backups keep the stored coordinates and IDs, as a restore needs them",
+	reflect.TypeFor[dto.RestoreResponse](): "restore conflicts name the IDs of the backup being restored",
+}
+
+// responseTypes returns the Go type of every response body the registered
+// operations document, by operation ID and status
+func responseTypes(t *testing.T) map[string]reflect.Type {
+	t.Helper()
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	registerEveryRoute(api)
+	registry := api.OpenAPI().Components.Schemas
+
+	types := map[string]reflect.Type{}
+	for _, item := range api.OpenAPI().Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch} {
+			if op == nil {
+				continue
+			}
+			for status, resp := range op.Responses {
+				for _, media := range resp.Content {
+					schema := media.Schema
+					if schema != nil && schema.Ref == "" && schema.Items != nil {
+						schema = schema.Items
+					}
+					if schema == nil || schema.Ref == "" {
+						continue
+					}
+					if typ := registry.TypeFromRef(schema.Ref); typ != nil {
+						types[op.OperationID+" "+status] = typ
+					}
+				}
+			}
+		}
+	}
+	if len(types) == 0 {
+		t.Fatal("Expected the operations to document their response bodies")
+	}
+	return types
+}
+
+// carries reports whether values of typ hold a struct with a field whose
+// JSON name is one of names
+func carries(typ reflect.Type, names ...string) bool {
+	return carriesSeen(typ, names, map[reflect.Type]bool{})
+}
+
+func carriesSeen(typ reflect.Type, names []string, seen map[reflect.Type]bool) bool {
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || seen[typ] {
+		return false
+	}
+	seen[typ] = true
+	fields := map[string]bool{}
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fields[strings.Split(field.Tag.Get("json"), ",")[0]] = true
+		if carriesSeen(field.Type, names, seen) {
+			return true
+		}
+	}
+	for _, name := range names {
+		if !fields[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// sample returns a value of typ with every string, number and list filled
+// in, so each location it can carry is present
+func sample(typ reflect.Type) any {
+	v := reflect.New(typ).Elem()
+	fill(v, 0)
+	return v.Interface()
+}
+
+func fill(v reflect.Value, depth int) {
+	if depth > 8 || !v.CanSet() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("7")
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(sampleCoordinate)
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), depth+1)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			fill(v.Field(i), depth+1)
+		}
+	}
+}
+
+// eachObject calls fn with every JSON object in the encoding of body
+func eachObject(t *testing.T, body any, fn func(map[string]any)) {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to encode %T: %v", body, err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode %T: %v", body, err)
+	}
+	var walk func(any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			fn(v)
+			for _, value := range v {
+				walk(value)
+			}
+		case []any:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+	walk(decoded)
+}
+
+// TestEveryLocationBodyIsObscured keeps coordinate privacy from missing a
+// body: every response that can show coordinates must be a
+// dto.LocationBody, and obscuring it must change each of them
+func TestEveryLocationBodyIsObscured(t *testing.T) {
+	t.Parallel()
+	ctx := dto.WithCoordinatePrivacy(context.Background(), dto.CoordinatePrivacy{Mode: dto.PrivacyGrid, Meters: 1000})
+	bodyType := reflect.TypeFor[dto.LocationBody]()
+
+	for operation, typ := range responseTypes(t) {
+		if !carries(typ, "latitude") {
+			continue
+		}
+		if reason, ok := showsExactLocations[typ]; ok {
+			t.Logf("%s shows %s as stored: %s", operation, typ, reason)
+			continue
+		}
+		if !typ.Implements(bodyType) {
+			t.Errorf("%s answers %s, which carries coordinates but is not a dto.LocationBody", operation, typ)
+			continue
+		}
+		eachObject(t, dto.ObscureCoordinates(ctx, sample(typ)), func(object map[string]any) {
+			for _, field := range []string{"latitude", "longitude"} {
+				if object[field] == sampleCoordinate {
+					t.Errorf("%s answers %s with an exact %s in %v", operation, typ, field, object)
+				}
+			}
+		})
+	}
+}
+
+// TestEveryLocationBodyHidesIDs keeps external IDs from missing a body:
+// every response that can show a location's ID must be a
+// dto.LocationBody, and encoding it must leave no raw ID behind
+func TestEveryLocationBodyHidesIDs(t *testing.T) {
+	t.Parallel()
+	ctx := dto.WithExternalIDs(context.Background(), dto.NewExternalIDs([]byte("deployment-one-key")))
+	bodyType := reflect.TypeFor[dto.LocationBody]()
+
+	for operation, typ := range responseTypes(t) {
+		if !carries(typ, "id", "name") {
+			continue
+		}
+		if reason, ok := showsExactLocations[typ]; ok {
+			t.Logf("%s shows %s as stored: %s", operation, typ, reason)
+			continue
+		}
+		if !typ.Implements(bodyType) {
+			t.Errorf("%s answers %s, which carries location IDs but is not a dto.LocationBody", operation, typ)
+			continue
+		}
+		eachObject(t, dto.EncodeIDs(ctx, sample(typ)), func(object map[string]any) {
+			if _, named := object["name"]; named && object["id"] == "7" {
+				t.Errorf("%s answers %s with a raw ID in %v", operation, typ, object)
+			}
+		})
+	}
+}
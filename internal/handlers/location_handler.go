@@ -2,78 +2,283 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
 
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
 	"github.com/jesuloba-world/leeta-task/internal/domain"
 	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 // LocationRequest represents the request body for creating a location
 type LocationRequest struct {
-	Body dto.LocationRequest `json:"body"`
+	IfNoneMatch string              `header:"If-None-Match" enum:"*" doc:"Set to * to create only if no location has the name; otherwise answers 412 with the existing location's ETag"`
+	Body        dto.LocationRequest `json:"body"`
 }
 
 // LocationResponse represents a location response
 type LocationResponse struct {
+	ETag string               `header:"ETag" doc:"Entity tag of the location as shown to this caller"`
 	Body dto.LocationResponse `json:"body"`
 }
 
+// newLocationResponse converts a location and tags it
+func newLocationResponse(ctx context.Context, location *domain.Location) *LocationResponse {
+	body := dto.FromDomain(location)
+	return &LocationResponse{ETag: dto.ETag(ctx, body), Body: body}
+}
+
 // LocationListResponse represents a list of locations
 type LocationListResponse struct {
-	Body dto.LocationListResponse `json:"body"`
+	Link            string                   `header:"Link" doc:"RFC 8288 pagination links"`
+	Warning         string                   `header:"Warning" doc:"110 \"Response is Stale\" with the time of the listing, when X-Served-From-Cache is set"`
+	ServedFromCache string                   `header:"X-Served-From-Cache" doc:"Set to true when the repository failed and the page was cut from the last listing it returned"`
+	Body            dto.LocationListResponse `json:"body"`
 }
 
 // NearestLocationRequest represents the query parameters for finding nearest location
 type NearestLocationRequest struct {
-	Lat float64 `query:"lat" required:"true" minimum:"-90" maximum:"90" doc:"Latitude coordinate"`
-	Lng float64 `query:"lng" required:"true" minimum:"-180" maximum:"180" doc:"Longitude coordinate"`
+	Lat     float64  `query:"lat" required:"true" minimum:"-90" maximum:"90" example:"6.4281" doc:"Latitude of the query point in decimal degrees"`
+	Lng     float64  `query:"lng" required:"true" minimum:"-180" maximum:"180" example:"3.4219" doc:"Longitude of the query point in decimal degrees"`
+	Exclude []string `query:"exclude,explode" example:"Leeta Lekki Phase 1" doc:"Names of locations to skip; repeat the parameter to exclude several. Unknown names are ignored"`
+	// SpeedKmh is left without a default so the configured one can apply
+	SpeedKmh float64 `query:"speed_kmh" exclusiveMinimum:"0" example:"30" doc:"Travel speed in km/h for eta_minutes, a straight-line estimate that ignores roads and traffic. Defaults to the server's configured speed, if any"`
+	Region   string  `query:"region" maxLength:"64" example:"Lagos" doc:"Only search locations in this region; required when the server is configured so"`
+	MinStock float64 `query:"min_stock" minimum:"0" example:"5000" doc:"Only search stations known to hold at least this many litres of fuel; stations whose stock is unknown are passed over. 0 applies no minimum"`
+	// MaxDistanceKm is left without a default so the search settings apply
+	MaxDistanceKm float64 `query:"max_distance_km" exclusiveMinimum:"0" example:"25" doc:"Furthest the nearest location may be, in km. Defaults to the search settings, if any"`
+	// Unit is left without a default so the caller's preference can apply
+	Unit string `query:"unit" enum:"km,m,mi,nmi" example:"mi" doc:"Unit of distance in the response. Defaults to the caller's preferred unit, or the server's"`
+	OpenFilterParams
 }
 
 // NearestLocationResponse represents the nearest location response
 type NearestLocationResponse struct {
-	Body dto.NearestLocationResponse `json:"body"`
+	DataStale string                      `header:"X-Data-Stale" doc:"Set to true when the answer came from a fallback snapshot"`
+	Body      dto.NearestLocationResponse `json:"body"`
+}
+
+// LocationAtRequest represents the query parameters for a reverse lookup
+type LocationAtRequest struct {
+	Lat        float64 `query:"lat" required:"true" minimum:"-90" maximum:"90" example:"6.4474" doc:"Latitude in decimal degrees"`
+	Lng        float64 `query:"lng" required:"true" minimum:"-180" maximum:"180" example:"3.4723" doc:"Longitude in decimal degrees"`
+	ToleranceM float64 `query:"tolerance_m" minimum:"0" maximum:"100" default:"1" example:"1" doc:"Match locations at most this many metres away; 0 matches the stored coordinates exactly. Capped so the lookup cannot stand in for /nearest"`
+}
+
+// GetLocationRequest represents the path parameter for getting a location
+type GetLocationRequest struct {
+	Name        string `path:"name" required:"true" example:"Leeta Lekki Phase 1" doc:"Name or alias of the location, percent-encoded as one path segment"`
+	IfNoneMatch string `header:"If-None-Match" example:"\"9f86d081884c7d65\"" doc:"ETags of copies the caller holds, comma-separated; answers 304 when the location still has one of them"`
 }
 
 // DeleteLocationRequest represents the path parameter for deleting a location
 type DeleteLocationRequest struct {
-	Name string `path:"name" required:"true" doc:"Name of the location to delete"`
+	Name    string `path:"name" required:"true" example:"Leeta Lekki Phase 1" doc:"Name or alias of the location to delete, percent-encoded as one path segment"`
+	IfMatch string `header:"If-Match" example:"\"9f86d081884c7d65\"" doc:"ETag the caller last read the location with, or *; answers 412 when the location has changed since. Required when the server is configured so"`
+}
+
+// AddAliasRequest represents an alias to add to a location
+type AddAliasRequest struct {
+	Name    string `path:"name" required:"true" example:"Leeta Lekki Phase 1" doc:"Name or alias of the location"`
+	IfMatch string `header:"If-Match" example:"\"9f86d081884c7d65\"" doc:"ETag the caller last read the location with, or *; answers 412 when the location has changed since. Required when the server is configured so"`
+	Body    struct {
+		Alias string `json:"alias" minLength:"1" maxLength:"255" example:"Leeta Admiralty Way" doc:"Alternate name; must not be any location's name or alias"`
+	}
+}
+
+// RemoveAliasRequest represents an alias to remove from a location
+type RemoveAliasRequest struct {
+	Name    string `path:"name" required:"true" example:"Leeta Lekki Phase 1" doc:"Name or alias of the location"`
+	Alias   string `path:"alias" required:"true" example:"Leeta Admiralty Way" doc:"Alias to remove"`
+	IfMatch string `header:"If-Match" example:"\"9f86d081884c7d65\"" doc:"ETag the caller last read the location with, or *; answers 412 when the location has changed since. Required when the server is configured so"`
+}
+
+// UpdateStockRequest represents a change to a station's stock
+type UpdateStockRequest struct {
+	Name    string `path:"name" required:"true" example:"Leeta Lekki Phase 1" doc:"Name or alias of the location"`
+	IfMatch string `header:"If-Match" example:"\"9f86d081884c7d65\"" doc:"ETag the caller last read the location with, or *; answers 412 when the location has changed since. Required when the server is configured so"`
+	Body    dto.StockRequest
 }
 
 // HealthResponse represents the health check response
 // LocationHandler wraps the location service for API operations
 type LocationHandler struct {
-	service domain.LocationService
+	service         domain.LocationService
+	externalBaseURL string
+	limits          config.LimitsConfig
+	sphere          geospatial.Sphere
+	defaultSpeedKmh float64
+	settings        *service.SettingsService
+	strictBodies    bool
+	// conditionalWrites requires If-Match on changes to a single location
+	conditionalWrites bool
+	// enforceOwnership limits changes to a location to its creator and
+	// admins
+	enforceOwnership bool
+	// precision is the decimal places responses round coordinates to, as
+	// documented in the OpenAPI schema
+	precision int
+}
+
+// LocationHandlerOption configures optional LocationHandler behaviour
+type LocationHandlerOption func(*LocationHandler)
+
+// WithExternalBaseURL sets the base URL used when building absolute links
+func WithExternalBaseURL(baseURL string) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		h.externalBaseURL = baseURL
+	}
+}
+
+// WithLimits sets the page size defaults and maxima enforced by the handler
+func WithLimits(limits config.LimitsConfig) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		if limits != (config.LimitsConfig{}) {
+			h.limits = limits
+		}
+	}
+}
+
+// WithSphere sets the reference sphere used for distances computed by the handler
+func WithSphere(sphere geospatial.Sphere) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		h.sphere = sphere
+	}
+}
+
+// WithDefaultSpeed sets the speed, in km/h, nearest responses estimate
+// eta_minutes with when the request gives none; 0 leaves ETAs off by default
+func WithDefaultSpeed(speedKmh float64) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		h.defaultSpeedKmh = speedKmh
+	}
+}
+
+// WithSearchSettings makes nearest searches take their defaults from the
+// stored search settings, read on every request, instead of WithDefaultSpeed
+func WithSearchSettings(settings *service.SettingsService) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		h.settings = settings
+	}
+}
+
+// WithStrictBodies decides whether unknown top-level fields in request
+// bodies answer UNKNOWN_FIELDS with suggestions, the default, or are left
+// to Huma's generic validation error
+func WithStrictBodies(strict bool) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		h.strictBodies = strict
+	}
+}
+
+// WithConditionalWrites decides whether deleting a location or changing
+// its aliases requires an If-Match header, so that clients cannot
+// overwrite changes they have not seen
+func WithConditionalWrites(required bool) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		h.conditionalWrites = required
+	}
+}
+
+// WithOwnershipEnforcement decides whether deleting or changing a location
+// is refused with NOT_OWNER to authenticated callers that neither created
+// it nor hold the admin scope
+func WithOwnershipEnforcement(enforce bool) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		h.enforceOwnership = enforce
+	}
+}
+
+// WithCoordinatePrecision sets the decimal places the OpenAPI schema
+// documents response coordinates as rounded to. The rounding itself is
+// applied by dto.RoundCoordinates with the precision in the request
+// context.
+func WithCoordinatePrecision(places int) LocationHandlerOption {
+	return func(h *LocationHandler) {
+		if places >= geospatial.MinCoordinatePrecision && places <= geospatial.MaxCoordinatePrecision {
+			h.precision = places
+		}
+	}
 }
 
 // NewLocationHandler creates a new location handler
-func NewLocationHandler(service domain.LocationService) *LocationHandler {
-	return &LocationHandler{service: service}
+func NewLocationHandler(service domain.LocationService, opts ...LocationHandlerOption) *LocationHandler {
+	h := &LocationHandler{service: service, limits: config.DefaultLimits(), sphere: geospatial.Earth, strictBodies: true,
+		precision: geospatial.DefaultCoordinatePrecision}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
+// nameSegmentNote documents how a name travels in a /locations/{name} path
+const nameSegmentNote = "The name is one path segment, percent-encoded the way encodeURIComponent does it: " +
+	"`/` as `%2F`, `?` as `%3F`, `#` as `%23`, a space as `%20` and `+` as `%2B`. An unencoded `/` addresses a different path."
+
+// ifMatchNote documents If-Match on changes to a single location
+const ifMatchNote = "Send the location's `ETag` in `If-Match` to make the change only if the location has not changed since it was read; " +
+	"otherwise the answer is 412 with the current `ETag`. Without If-Match the answer is 428 when the server requires conditional writes."
+
+// ownerNote documents ownership enforcement on changes to a location
+const ownerNote = "When the server enforces ownership, only the caller that created the location or an admin may change it; " +
+	"others get 403 with code NOT_OWNER."
+
 // RegisterRoutes registers all location routes with the Huma API
 func (h *LocationHandler) RegisterRoutes(api huma.API) {
+	// Operations with a body check its fields before Huma reads it
+	var bodyChecks huma.Middlewares
+	if h.strictBodies {
+		bodyChecks = huma.Middlewares{strictBody(api)}
+	}
+
 	// Create location endpoint
 	huma.Register(api, huma.Operation{
 		OperationID:   "create-location",
 		Method:        http.MethodPost,
 		Path:          "/locations",
 		Summary:       "Create Location",
-		Description:   "Register a new geolocated station with latitude and longitude coordinates",
+		Description:   "Register a new geolocated station with latitude and longitude coordinates. A body sent without a Content-Type is read as JSON. With `If-None-Match: *` an existing name answers 412 with that location's ETag instead of 409.",
 		Tags:          []string{"Locations"},
 		DefaultStatus: http.StatusCreated,
+		MaxBodyBytes:  int64(h.limits.MaxBodyBytes),
+		Middlewares:   bodyChecks,
+		Errors:        []int{http.StatusBadRequest, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity},
 	}, h.CreateLocation)
 
+	// Transaction endpoint
+	huma.Register(api, huma.Operation{
+		OperationID: "apply-location-transaction",
+		Method:      http.MethodPost,
+		Path:        "/locations/transaction",
+		Summary:     "Apply Location Transaction",
+		Description: "Create, update and delete several locations at once, in order, all or none. Every operation is checked before any is applied; " +
+			"if one is invalid or fails, nothing is kept and the error's details are located under `body.operations[index]`. " +
+			"Results are returned in the order of the operations. " + ownerNote,
+		Tags:         []string{"Locations"},
+		MaxBodyBytes: int64(h.limits.MaxBodyBytes),
+		Middlewares:  bodyChecks,
+		Errors:       []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, h.ApplyTransaction)
+
 	// Get all locations endpoint
 	huma.Register(api, huma.Operation{
 		OperationID: "get-locations",
 		Method:      http.MethodGet,
 		Path:        "/locations",
 		Summary:     "Get All Locations",
-		Description: "Retrieve all registered locations",
+		Description: "Retrieve registered locations, optionally filtered by creation time, name, bounding box or opening hours and paginated by page or cursor. Both creation bounds are inclusive.",
 		Tags:        []string{"Locations"},
+		Errors:      []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
 	}, h.GetAllLocations)
 
 	// Delete location endpoint
@@ -82,57 +287,464 @@ func (h *LocationHandler) RegisterRoutes(api huma.API) {
 		Method:        http.MethodDelete,
 		Path:          "/locations/{name}",
 		Summary:       "Delete Location",
-		Description:   "Delete a location by its name",
+		Description:   "Delete a location by its name. " + nameSegmentNote + " " + ifMatchNote + " " + ownerNote,
 		Tags:          []string{"Locations"},
 		DefaultStatus: http.StatusNoContent,
+		Errors:        []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired},
 	}, h.DeleteLocation)
 
+	// Alias endpoints
+	huma.Register(api, huma.Operation{
+		OperationID: "add-location-alias",
+		Method:      http.MethodPost,
+		Path:        "/locations/{name}/aliases",
+		Summary:     "Add Location Alias",
+		Description: "Give a location an alternate name it is also found and deleted by. Names and aliases share one namespace, " +
+			"so an alias already used by any location answers 409 naming its owner. Adding an alias the location already has changes nothing. " + ifMatchNote + " " + ownerNote,
+		Tags:        []string{"Locations"},
+		Errors:      []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired, http.StatusUnprocessableEntity},
+		Middlewares: bodyChecks,
+	}, h.AddAlias)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "remove-location-alias",
+		Method:      http.MethodDelete,
+		Path:        "/locations/{name}/aliases/{alias}",
+		Summary:     "Remove Location Alias",
+		Description: "Remove one of a location's alternate names. " + ifMatchNote + " " + ownerNote,
+		Tags:        []string{"Locations"},
+		Errors:      []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired},
+	}, h.RemoveAlias)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-location-stock",
+		Method:      http.MethodPatch,
+		Path:        "/locations/{name}/stock",
+		Summary:     "Update Location Stock",
+		Description: "Set a station's fuel capacity and stock, or adjust its stock by the litres delivered or sold. Only the fields sent change, " +
+			"and nothing else about the location is checked, so stations can report often. Stock may not be negative or exceed the capacity. " +
+			"Adjustments sent at the same time are all applied. " + ifMatchNote + " " + ownerNote,
+		Tags:         []string{"Locations"},
+		MaxBodyBytes: int64(h.limits.MaxBodyBytes),
+		Middlewares:  bodyChecks,
+		Errors:       []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired, http.StatusUnprocessableEntity},
+	}, h.UpdateStock)
+
 	// Find nearest location endpoint
 	huma.Register(api, huma.Operation{
 		OperationID: "find-nearest",
 		Method:      http.MethodGet,
 		Path:        "/nearest",
 		Summary:     "Find Nearest Location",
-		Description: "Find the closest registered location to the given coordinates",
-		Tags:        []string{"Locations"},
+		Description: "Find the closest registered location to the given coordinates, optionally skipping locations named in `exclude` or closed at the time `open_at` or `open_now` selects. Distance is the great-circle distance in kilometres. " +
+			"Of locations at the same distance, the one whose name is lowest in byte order wins, then the lowest ID, on every storage backend. " +
+			"`speed_kmh` and `max_distance_km` default to the search settings under /settings/search.",
+		Tags:   []string{"Locations"},
+		Errors: []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
 	}, h.FindNearest)
+
+	// Reverse lookup endpoint
+	huma.Register(api, huma.Operation{
+		OperationID: "get-location-at",
+		Method:      http.MethodGet,
+		Path:        "/locations/at",
+		Summary:     "Get Location At Coordinates",
+		Description: "Find the registered location at the given coordinates, within `tolerance_m` metres. " +
+			"Answers 404 when none matches and 409 listing the candidates when several do, nearest first and, at the same distance, by name in byte order, then ID.",
+		Tags:   []string{"Locations"},
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	}, h.LocationAt)
+
+	// Autocomplete endpoint
+	huma.Register(api, huma.Operation{
+		OperationID: "suggest-locations",
+		Method:      http.MethodGet,
+		Path:        "/locations/suggest",
+		Summary:     "Suggest Locations",
+		Description: "Suggest locations as a name is typed, ranked by a score that combines how well the name matches `q` with how near the location is to `lat` and `lng`. " +
+			"Names starting with `q` match best, then names with a word starting with it, then names that only resemble it. " +
+			"`match` gives the part of the name to highlight.",
+		Tags:   []string{"Locations"},
+		Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	}, h.Suggest)
+
+	// Get location by name endpoint, registered after the fixed
+	// /locations/... paths so routers that match in order still reach them
+	huma.Register(api, huma.Operation{
+		OperationID: "get-location",
+		Method:      http.MethodGet,
+		Path:        "/locations/{name}",
+		Summary:     "Get Location",
+		Description: "Get a location by its name or alias. " + nameSegmentNote + " " +
+			"The response carries the location's `ETag`; send it back in `If-None-Match` to get 304 while the location is unchanged.",
+		Tags:   []string{"Locations"},
+		Errors: []int{http.StatusNotFound, http.StatusServiceUnavailable},
+	}, h.GetLocation)
+
+	// Distance matrix endpoint
+	huma.Register(api, huma.Operation{
+		OperationID: "distance-matrix",
+		Method:      http.MethodPost,
+		Path:        "/distance/matrix",
+		Summary:     "Distance Matrix",
+		Description: "Compute the great-circle distance from every origin to every destination. Each entry is either a stored location's name or alias, or a pair of coordinates. " +
+			"Names that are not found are listed in `errors` and their rows or columns hold nulls, so one bad name does not fail the whole matrix.",
+		Tags:        []string{"Locations"},
+		Errors:      []int{http.StatusUnprocessableEntity},
+		Middlewares: bodyChecks,
+	}, h.DistanceMatrix)
+
+	documentCoordinatePrecision(api, h.precision)
+}
+
+// documentCoordinatePrecision records the rounding applied to response
+// coordinates as multipleOf on the LocationResponse schema
+func documentCoordinatePrecision(api huma.API, places int) {
+	schema := api.OpenAPI().Components.Schemas.Map()["LocationResponse"]
+	if schema == nil {
+		return
+	}
+	step := math.Pow10(-places)
+	for _, name := range []string{"latitude", "longitude"} {
+		if property := schema.Properties[name]; property != nil {
+			property.MultipleOf = &step
+		}
+	}
 }
 
 // CreateLocation handles POST /locations requests
 func (h *LocationHandler) CreateLocation(ctx context.Context, input *LocationRequest) (*LocationResponse, error) {
-	createdLocation, err := h.service.CreateLocation(input.Body.Name, input.Body.Latitude, input.Body.Longitude)
+	if err := input.Body.Project(); err != nil {
+		return nil, crsError(ctx, err)
+	}
+	if err := input.Body.Validate(); err != nil {
+		if validationErr, ok := apierrors.FromValidator(ctx, err); ok {
+			return nil, apierrors.ToHuma(ctx, validationErr)
+		}
+		return nil, apierrors.ToHuma(ctx, apierrors.BadRequest(err.Error()))
+	}
+
+	// Admins may use reserved name prefixes
+	principal := auth.PrincipalFromContext(ctx)
+	createdLocation, err := h.service.CreateLocation(input.Body.Name, *input.Body.Latitude, *input.Body.Longitude,
+		domain.Privileged(principal.HasScope(auth.ScopeAdmin)), domain.WithOpeningHours(input.Body.OpeningHours), domain.WithDescription(input.Body.Description),
+		domain.WithRegion(input.Body.Region), domain.WithAttachments(input.Body.Attachments), domain.WithCreatedBy(principalID(principal)))
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			return nil, huma.Error409Conflict("Location with this name already exists")
+		if input.IfNoneMatch == "*" && errors.Is(err, domain.ErrLocationExists) {
+			return nil, h.existsPrecondition(ctx, input.Body.Name, err)
 		}
-		return nil, huma.Error400BadRequest(err.Error())
+		return nil, createError(ctx, input.Body.Name, err)
+	}
+
+	return newLocationResponse(ctx, createdLocation), nil
+}
+
+// createError answers a location the service would not create
+func createError(ctx context.Context, name string, err error) error {
+	if mapped := filterError(ctx, err); mapped != nil {
+		return mapped
+	}
+	var attachmentErr *domain.AttachmentError
+	if errors.As(err, &attachmentErr) {
+		return attachmentError(ctx, attachmentErr)
+	}
+	var tooLong *domain.DescriptionTooLongError
+	if errors.As(err, &tooLong) {
+		limit := strconv.Itoa(tooLong.Max)
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "DESCRIPTION_TOO_LONG", "description exceeds the maximum of "+limit+" characters").
+			With("max", limit))
 	}
+	if errors.Is(err, domain.ErrInvalidOpeningHours) {
+		reason := strings.TrimPrefix(err.Error(), domain.ErrInvalidOpeningHours.Error()+": ")
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_OPENING_HOURS", "Invalid opening hours: "+reason).
+			With("reason", reason))
+	}
+	if errors.Is(err, domain.ErrNameNotAllowed) {
+		name = strings.TrimSpace(name)
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "NAME_NOT_ALLOWED", "The name "+name+" is not allowed").
+			With("name", name))
+	}
+	var taken *domain.NameTakenError
+	if errors.As(err, &taken) {
+		return nameTakenError(ctx, taken)
+	}
+	if strings.Contains(err.Error(), "already exists") {
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusConflict, "LOCATION_EXISTS", "Location with this name already exists"))
+	}
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
+		return apierrors.ToHuma(ctx, domainValidationError(ctx, invalid))
+	}
+	if validationErr, ok := apierrors.FromValidator(ctx, err); ok {
+		return apierrors.ToHuma(ctx, validationErr)
+	}
+	return apierrors.ToHuma(ctx, apierrors.BadRequest(err.Error()))
+}
 
-	return &LocationResponse{
-		Body: dto.FromDomain(createdLocation),
+// domainValidationError describes each field the domain rejected, with the
+// value given
+func domainValidationError(ctx context.Context, invalid *domain.ValidationError) apierrors.ValidationError {
+	fields := make([]apierrors.FieldError, len(invalid.Fields))
+	for i, field := range invalid.Fields {
+		fields[i] = apierrors.FieldError{Field: field.Field, Rule: field.Rule, Param: field.Param, Value: field.Value}
+	}
+	return apierrors.FromFields(ctx, fields)
+}
+
+// crsError answers coordinates that cannot be read in the request's crs
+func crsError(ctx context.Context, err error) error {
+	var crsErr *dto.CRSError
+	if !errors.As(err, &crsErr) {
+		return apierrors.ToHuma(ctx, apierrors.BadRequest(err.Error()))
+	}
+	crs := strconv.Itoa(crsErr.CRS)
+	return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_CRS_COORDINATES", "The coordinates are not valid in EPSG:"+crs+" ("+crsErr.Reason+")").
+		With("crs", crs).With("reason", crsErr.Reason))
+}
+
+// existsPrecondition answers a conditional create whose name is taken,
+// tagging the answer with the location holding the name
+func (h *LocationHandler) existsPrecondition(ctx context.Context, name string, err error) error {
+	name = strings.TrimSpace(name)
+	var taken *domain.NameTakenError
+	if errors.As(err, &taken) {
+		name = taken.Owner
+	}
+	existing, lookupErr := h.service.GetLocation(name)
+	if lookupErr != nil {
+		// Deleted since the create failed; the condition held then
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusPreconditionFailed, "PRECONDITION_FAILED", "The location "+name+" already exists").
+			With("name", name))
+	}
+	return huma.ErrorWithHeaders(
+		apierrors.ToHuma(ctx, apierrors.New(http.StatusPreconditionFailed, "PRECONDITION_FAILED", "The location "+existing.Name+" already exists").
+			With("name", existing.Name)),
+		http.Header{"ETag": {dto.ETag(ctx, dto.FromDomain(existing))}})
+}
+
+// preconditions returns the checks a change to the named location must
+// pass, for the repository to run in the same step as the change: that the
+// caller owns it, then that it still has an ETag listed in ifMatch
+func (h *LocationHandler) preconditions(ctx context.Context, name, ifMatch string) ([]domain.Precondition, error) {
+	var checks []domain.Precondition
+	if owner := h.ownerCheck(ctx); owner != nil {
+		checks = append(checks, owner)
+	}
+	match, err := h.ifMatch(ctx, name, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil {
+		checks = append(checks, match)
+	}
+	return checks, nil
+}
+
+// ifMatch returns the check that a location still has an ETag listed in
+// ifMatch, or nil when ifMatch is empty. A missing ifMatch is refused at
+// once when conditional writes are required.
+func (h *LocationHandler) ifMatch(ctx context.Context, name, ifMatch string) (domain.Precondition, error) {
+	if strings.TrimSpace(ifMatch) == "" {
+		if h.conditionalWrites {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "Send If-Match with the ETag of "+name+" to change it").
+				With("name", name))
+		}
+		return nil, nil
+	}
+	return func(location *domain.Location) error {
+		etag := dto.ETag(ctx, dto.FromDomain(location))
+		if etagListed(ifMatch, etag, true) {
+			return nil
+		}
+		return &refusal{huma.ErrorWithHeaders(
+			apierrors.ToHuma(ctx, apierrors.New(http.StatusPreconditionFailed, "LOCATION_MODIFIED", "The location "+location.Name+" has changed since it was read").
+				With("name", location.Name)),
+			http.Header{"ETag": {etag}})}
 	}, nil
 }
 
+// ownerCheck returns the check that the caller created a location, or nil
+// when ownership is not enforced or the caller holds the admin scope.
+// Locations with no owner, such as imported ones or those created before
+// owners were recorded, are not restricted. Anonymous callers are not
+// checked: deployments without authentication record no owners.
+func (h *LocationHandler) ownerCheck(ctx context.Context) domain.Precondition {
+	principal := auth.PrincipalFromContext(ctx)
+	if !h.enforceOwnership || principal == nil || principal.HasScope(auth.ScopeAdmin) {
+		return nil
+	}
+	return func(location *domain.Location) error {
+		if location.CreatedBy == "" || location.CreatedBy == principal.ID {
+			return nil
+		}
+		return &refusal{notOwnerError(ctx, location.Name)}
+	}
+}
+
+// refusal carries the response a precondition refused a change with back
+// through the service and repository, which return it as it is
+type refusal struct {
+	response error
+}
+
+func (r *refusal) Error() string {
+	return r.response.Error()
+}
+
+// refused returns the response a precondition refused the change behind
+// err with, or nil when err is not a refusal
+func refused(err error) error {
+	var r *refusal
+	if errors.As(err, &r) {
+		return r.response
+	}
+	return nil
+}
+
+// notOwnerError reports a change to a location the caller does not own
+func notOwnerError(ctx context.Context, name string) error {
+	return apierrors.ToHuma(ctx, apierrors.New(http.StatusForbidden, "NOT_OWNER", "Only the creator of "+name+" or an admin may change it").
+		With("name", name))
+}
+
+// principalID returns the ID of the caller recorded as a location's
+// owner, empty for anonymous callers
+func principalID(principal *auth.Principal) string {
+	if principal == nil {
+		return ""
+	}
+	return principal.ID
+}
+
+// auditActor returns who an audit entry records as making a change: the
+// caller's ID, or its client IP when it is anonymous
+func auditActor(ctx context.Context) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		return principal.ID
+	}
+	return middleware.ClientIPFromContext(ctx)
+}
+
+// etagListed reports whether a comma-separated If-Match or If-None-Match
+// header lists etag or is *. Weak tags match only when strong is false, as
+// If-Match compares strongly and If-None-Match weakly.
+func etagListed(header, etag string, strong bool) bool {
+	for _, listed := range strings.Split(header, ",") {
+		listed = strings.TrimSpace(listed)
+		if listed == "*" {
+			return true
+		}
+		if weak, ok := strings.CutPrefix(listed, "W/"); ok {
+			if strong {
+				continue
+			}
+			listed = weak
+		}
+		if listed == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAllLocations handles GET /locations requests
-func (h *LocationHandler) GetAllLocations(ctx context.Context, input *struct{}) (*LocationListResponse, error) {
-	locations, err := h.service.GetAllLocations()
+func (h *LocationHandler) GetAllLocations(ctx context.Context, input *ListLocationsRequest) (*LocationListResponse, error) {
+	if input.cursorMode() && input.offsetMode() {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadRequest, "PAGINATION_CONFLICT", "Cursor and page pagination cannot be combined"))
+	}
+	if input.hasRefLat != input.hasRefLng {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "REFERENCE_POINT_INCOMPLETE", "ref_lat and ref_lng must be given together"))
+	}
+
+	if err := checkLimit(ctx, "page_size", input.PageSize, h.limits.MaxPageSize); err != nil {
+		return nil, err
+	}
+	if err := checkLimit(ctx, "limit", input.Limit, h.limits.MaxPageSize); err != nil {
+		return nil, err
+	}
+
+	// Pagination applies to the filtered listing
+	filter, err := input.filter()
 	if err != nil {
-		return nil, huma.Error500InternalServerError("Failed to retrieve locations")
+		return nil, filterError(ctx, err)
+	}
+	open, err := input.open()
+	if err != nil {
+		return nil, filterError(ctx, err)
+	}
+	opts := domain.ListOptions{Filter: filter, Open: open, Fresh: input.conditional}
+	number, size, err := input.window(&opts, h.limits.DefaultPageSize)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadRequest, "INVALID_CURSOR", "Invalid cursor"))
+	}
+	page, err := h.service.ListLocations(ctx, opts)
+	if mapped := filterError(ctx, err); mapped != nil {
+		return nil, mapped
+	}
+	if err != nil {
+		return nil, storageError(ctx, err, "Failed to retrieve locations")
 	}
 
-	return &LocationListResponse{
-		Body: dto.FromDomainList(locations),
-	}, nil
+	links := newLinkBuilder(input, h.externalBaseURL)
+	resp := &LocationListResponse{Body: h.listBody(ctx, page.Items, input)}
+	if page.Stale {
+		resp.Warning = `110 - "Response is Stale" "` + page.AsOf.UTC().Format(http.TimeFormat) + `"`
+		resp.ServedFromCache = "true"
+	}
+	switch {
+	case input.cursorMode():
+		resp.Body.NextCursor, resp.Link = nextCursor(page, size, links)
+	case input.offsetMode():
+		resp.Body.Total = page.Total
+		resp.Body.Page = number
+		resp.Body.PageSize = size
+		resp.Link = offsetLinks(page.Total, number, size, links)
+	}
+	return resp, nil
+}
+
+// listBody converts a page of locations, adding distances when the request
+// carries a reference point
+func (h *LocationHandler) listBody(ctx context.Context, locations []*domain.Location, input *ListLocationsRequest) dto.LocationListResponse {
+	body := dto.FromDomainList(locations)
+	if input.hasReference() {
+		body.WithDistancesFrom(input.RefLat, input.RefLng, h.sphere.Distance)
+		body.InUnit(dto.DistanceUnit(ctx, input.Unit))
+	}
+	return body
+}
+
+// GetLocation handles GET /locations/{name} requests
+func (h *LocationHandler) GetLocation(ctx context.Context, input *GetLocationRequest) (*LocationResponse, error) {
+	location, err := h.service.GetLocation(input.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "LOCATION_NOT_FOUND", "Location not found"))
+		}
+		return nil, storageError(ctx, err, "Failed to get location")
+	}
+	resp := newLocationResponse(ctx, location)
+	if etagListed(input.IfNoneMatch, resp.ETag, false) {
+		return nil, huma.ErrorWithHeaders(huma.Status304NotModified(), http.Header{"ETag": {resp.ETag}})
+	}
+	return resp, nil
 }
 
 // DeleteLocation handles DELETE /locations/{name} requests
 func (h *LocationHandler) DeleteLocation(ctx context.Context, input *DeleteLocationRequest) (*struct{}, error) {
-	err := h.service.DeleteLocation(input.Name)
+	checks, err := h.preconditions(ctx, input.Name, input.IfMatch)
 	if err != nil {
+		return nil, err
+	}
+	err = h.service.DeleteLocation(input.Name, checks...)
+	if err != nil {
+		if refusal := refused(err); refusal != nil {
+			return nil, refusal
+		}
 		if strings.Contains(err.Error(), "not found") {
-			return nil, huma.Error404NotFound("Location not found")
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "LOCATION_NOT_FOUND", "Location not found"))
 		}
-		return nil, huma.Error500InternalServerError("Failed to delete location")
+		return nil, storageError(ctx, err, "Failed to delete location")
 	}
 
 	return &struct{}{}, nil
@@ -140,15 +752,224 @@ func (h *LocationHandler) DeleteLocation(ctx context.Context, input *DeleteLocat
 
 // FindNearest handles GET /nearest requests
 func (h *LocationHandler) FindNearest(ctx context.Context, input *NearestLocationRequest) (*NearestLocationResponse, error) {
-	location, distance, err := h.service.FindNearest(input.Lat, input.Lng)
+	open, err := input.open()
 	if err != nil {
+		return nil, filterError(ctx, err)
+	}
+	result, err := h.service.FindNearest(ctx, domain.NearestQuery{
+		Latitude:  input.Lat,
+		Longitude: input.Lng,
+		Filter:    domain.NearestFilter{Open: open, Region: input.Region, MinStockLitres: input.MinStock},
+		Exclude:   input.Exclude,
+	})
+	if err != nil {
+		if mapped := filterError(ctx, err); mapped != nil {
+			return nil, mapped
+		}
+		if within := (*domain.NoOpenWithinError)(nil); errors.As(err, &within) {
+			candidates := strconv.Itoa(within.Candidates)
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "NO_OPEN_LOCATION", "No open location among the nearest "+candidates).
+				With("candidates", candidates))
+		}
+		if errors.Is(err, domain.ErrNoOpenLocation) {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "NO_OPEN_LOCATION", "No open location found"))
+		}
+		if input.MinStock > 0 && errors.Is(err, domain.ErrLocationNotFound) {
+			limit := strconv.FormatFloat(input.MinStock, 'f', -1, 64)
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "NO_STOCKED_LOCATION", "No location found holding at least "+limit+" litres").
+				With("min_stock", limit))
+		}
+		if errors.Is(err, domain.ErrScanBudgetExceeded) {
+			return nil, scanBudgetError(ctx)
+		}
 		if strings.Contains(err.Error(), "no locations") {
-			return nil, huma.Error404NotFound("No locations found")
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "NO_LOCATIONS", "No locations found"))
 		}
-		return nil, huma.Error500InternalServerError("Failed to find nearest location")
+		return nil, storageError(ctx, err, "Failed to find nearest location")
 	}
 
-	return &NearestLocationResponse{
-		Body: dto.FromDomainWithDistance(location, distance),
-	}, nil
-}
\ No newline at end of file
+	defaults := h.searchSettings()
+	maxDistance := input.MaxDistanceKm
+	if maxDistance == 0 {
+		maxDistance = defaults.MaxDistanceKm
+	}
+	if maxDistance > 0 && result.Distance.Kilometers() > maxDistance {
+		limit := strconv.FormatFloat(maxDistance, 'f', -1, 64)
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "NO_LOCATION_IN_RANGE", "No location found within "+limit+" km").
+			With("max_distance_km", limit))
+	}
+
+	resp := &NearestLocationResponse{
+		Body: dto.FromNearestResult(result),
+	}
+	resp.Body.InUnit(dto.DistanceUnit(ctx, input.Unit))
+	speed := input.SpeedKmh
+	if speed == 0 {
+		speed = defaults.SpeedKmh
+	}
+	if speed > 0 {
+		resp.Body.WithETA(speed)
+	}
+	if result.Stale {
+		resp.DataStale = "true"
+	}
+	return resp, nil
+}
+
+// storageError answers a failed repository call: 503 STORAGE_TIMEOUT
+// when it ran past its adaptive timeout, so clients retry, and a 500 with
+// message otherwise
+func storageError(ctx context.Context, err error, message string) error {
+	if errors.Is(err, domain.ErrStorageTimeout) {
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusServiceUnavailable, "STORAGE_TIMEOUT",
+			"The location store took too long to answer; try again later"))
+	}
+	return apierrors.ToHuma(ctx, apierrors.InternalServerError(message))
+}
+
+// scanBudgetError answers a search that would examine more locations than
+// the hard scan limit allows
+func scanBudgetError(ctx context.Context) error {
+	return apierrors.ToHuma(ctx, apierrors.New(http.StatusServiceUnavailable, "NEAREST_SCAN_LIMIT",
+		"The search would examine more locations than the server allows; try again later"))
+}
+
+// searchSettings returns the defaults for parameters a nearest search
+// leaves out
+func (h *LocationHandler) searchSettings() domain.SearchSettings {
+	if h.settings == nil {
+		return domain.SearchSettings{SpeedKmh: h.defaultSpeedKmh}
+	}
+	settings, _ := h.settings.SearchSettings()
+	return settings
+}
+
+// LocationAt handles GET /locations/at requests
+func (h *LocationHandler) LocationAt(ctx context.Context, input *LocationAtRequest) (*LocationResponse, error) {
+	matches, err := h.service.LocationsAt(input.Lat, input.Lng, input.ToleranceM)
+	if err != nil {
+		return nil, storageError(ctx, err, "Failed to look up the location")
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "LOCATION_NOT_FOUND", "Location not found"))
+	case 1:
+		return newLocationResponse(ctx, matches[0]), nil
+	}
+
+	count := strconv.Itoa(len(matches))
+	statusErr := apierrors.ToHuma(ctx, apierrors.New(http.StatusConflict, "AMBIGUOUS_LOCATION", count+" locations match these coordinates").
+		With("count", count))
+	// Each candidate is listed so the caller can tell them apart
+	if coded, ok := statusErr.(*apierrors.CodedError); ok {
+		for _, match := range matches {
+			coded.Errors = append(coded.Errors, &huma.ErrorDetail{
+				Message:  match.Name,
+				Location: "candidate",
+				Value:    dto.FromDomain(match),
+			})
+		}
+	}
+	return nil, statusErr
+}
+
+// AddAlias handles POST /locations/{name}/aliases requests
+func (h *LocationHandler) AddAlias(ctx context.Context, input *AddAliasRequest) (*LocationResponse, error) {
+	checks, err := h.preconditions(ctx, input.Name, input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	// Admins may use reserved name prefixes, as when creating
+	privileged := auth.PrincipalFromContext(ctx).HasScope(auth.ScopeAdmin)
+	location, err := h.service.AddAlias(input.Name, input.Body.Alias, privileged, checks...)
+	if err != nil {
+		if refusal := refused(err); refusal != nil {
+			return nil, refusal
+		}
+		var taken *domain.NameTakenError
+		switch {
+		case errors.As(err, &taken):
+			return nil, nameTakenError(ctx, taken)
+		case errors.Is(err, domain.ErrLocationNotFound):
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "LOCATION_NOT_FOUND", "Location not found"))
+		case errors.Is(err, domain.ErrNameNotAllowed):
+			alias := strings.TrimSpace(input.Body.Alias)
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "NAME_NOT_ALLOWED", "The name "+alias+" is not allowed").
+				With("name", alias))
+		case errors.Is(err, domain.ErrEmptyName):
+			return nil, apierrors.ToHuma(ctx, apierrors.BadRequest("alias cannot be blank"))
+		}
+		return nil, storageError(ctx, err, "Failed to add alias")
+	}
+
+	return newLocationResponse(ctx, location), nil
+}
+
+// RemoveAlias handles DELETE /locations/{name}/aliases/{alias} requests
+func (h *LocationHandler) RemoveAlias(ctx context.Context, input *RemoveAliasRequest) (*LocationResponse, error) {
+	checks, err := h.preconditions(ctx, input.Name, input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	location, err := h.service.RemoveAlias(input.Name, input.Alias, checks...)
+	if err != nil {
+		if refusal := refused(err); refusal != nil {
+			return nil, refusal
+		}
+		switch {
+		case errors.Is(err, domain.ErrLocationNotFound):
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "LOCATION_NOT_FOUND", "Location not found"))
+		case errors.Is(err, domain.ErrAliasNotFound):
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "ALIAS_NOT_FOUND", "The location has no alias "+input.Alias).
+				With("alias", input.Alias))
+		}
+		return nil, storageError(ctx, err, "Failed to remove alias")
+	}
+
+	return newLocationResponse(ctx, location), nil
+}
+
+// UpdateStock handles PATCH /locations/{name}/stock requests
+func (h *LocationHandler) UpdateStock(ctx context.Context, input *UpdateStockRequest) (*LocationResponse, error) {
+	checks, err := h.preconditions(ctx, input.Name, input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	location, err := h.service.UpdateStock(input.Name, input.Body.ToDomain(), checks...)
+	if err != nil {
+		if refusal := refused(err); refusal != nil {
+			return nil, refusal
+		}
+		var invalid *domain.StockError
+		switch {
+		case errors.Is(err, domain.ErrLocationNotFound):
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "LOCATION_NOT_FOUND", "Location not found"))
+		case errors.As(err, &invalid):
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_STOCK", "Invalid stock: "+invalid.Reason).
+				With("reason", invalid.Reason))
+		}
+		return nil, storageError(ctx, err, "Failed to update stock")
+	}
+
+	return newLocationResponse(ctx, location), nil
+}
+
+// nameTakenError reports a name or alias already held by another location,
+// naming its owner
+func nameTakenError(ctx context.Context, taken *domain.NameTakenError) error {
+	return apierrors.ToHuma(ctx, apierrors.New(http.StatusConflict, "NAME_TAKEN", "The name "+taken.Name+" is already used by "+taken.Owner).
+		With("name", taken.Name).With("owner", taken.Owner))
+}
+
+// attachmentError reports the first attachment that failed, by its position
+// in the request
+func attachmentError(ctx context.Context, err *domain.AttachmentError) error {
+	index := strconv.Itoa(err.Index)
+	if errors.Is(err, domain.ErrAttachmentUnreachable) {
+		return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "ATTACHMENT_UNREACHABLE", "Attachment "+index+" could not be reached: "+err.Reason).
+			With("index", index).With("reason", err.Reason))
+	}
+	return apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_ATTACHMENT", "Invalid attachment "+index+": "+err.Reason).
+		With("index", index).With("reason", err.Reason))
+}
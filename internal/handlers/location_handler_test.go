@@ -1,16 +1,27 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
 	"github.com/danielgtaylor/huma/v2/humatest"
 
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
 	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/fallback"
 	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
 	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 func setupTestAPI(t *testing.T) (humatest.TestAPI, *LocationHandler) {
@@ -18,19 +29,30 @@ func setupTestAPI(t *testing.T) (humatest.TestAPI, *LocationHandler) {
 	locationService := service.NewLocationService(repo)
 	locationHandler := NewLocationHandler(locationService)
 
-	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api := newTestAPI(t)
 	locationHandler.RegisterRoutes(api)
 
 	return api, locationHandler
 }
 
+// newTestAPI serves through the server's adapter, so paths are matched and
+// decoded as in production: GET /locations/{name} does not shadow fixed
+// paths registered after it, and a + in a name stays a +
+func newTestAPI(t *testing.T) humatest.TestAPI {
+	return humatest.Wrap(t, humago.New(http.NewServeMux(), huma.DefaultConfig("Test API", "1.0.0")))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func TestCreateLocation(t *testing.T) {
 	api, _ := setupTestAPI(t)
 
 	locationReq := dto.LocationRequest{
 		Name:      "New York",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	resp := api.Post("/locations", locationReq)
@@ -60,8 +82,8 @@ func TestCreateLocationDuplicate(t *testing.T) {
 
 	locationReq := dto.LocationRequest{
 		Name:      "New York",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	// Create first location
@@ -82,14 +104,14 @@ func TestGetAllLocations(t *testing.T) {
 
 	locationReq1 := dto.LocationRequest{
 		Name:      "New York",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	locationReq2 := dto.LocationRequest{
 		Name:      "Los Angeles",
-		Latitude:  34.0522,
-		Longitude: -118.2437,
+		Latitude:  ptr(34.0522),
+		Longitude: ptr(-118.2437),
 	}
 
 	// Create locations
@@ -118,8 +140,8 @@ func TestDeleteLocation(t *testing.T) {
 
 	locationReq := dto.LocationRequest{
 		Name:      "To Delete",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	// Create location
@@ -161,19 +183,46 @@ func TestDeleteLocationNotFound(t *testing.T) {
 	}
 }
 
+func TestGetLocation(t *testing.T) {
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	api.Post("/locations/Ikeja/aliases", map[string]string{"alias": "Ikeja Along"})
+
+	for _, name := range []string{"Ikeja", "Ikeja%20Along"} {
+		resp := api.Get("/locations/" + name)
+		var location dto.LocationResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &location); resp.Code != http.StatusOK || err != nil || location.Name != "Ikeja" {
+			t.Fatalf("Expected Ikeja for %s, got %d: %s", name, resp.Code, resp.Body.String())
+		}
+		if resp.Header().Get("ETag") == "" {
+			t.Errorf("Expected an ETag for %s", name)
+		}
+	}
+
+	resp := api.Get("/locations/Yaba")
+	if resp.Code != http.StatusNotFound || !strings.Contains(resp.Body.String(), "LOCATION_NOT_FOUND") {
+		t.Errorf("Expected 404 LOCATION_NOT_FOUND, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	// Fixed paths under /locations still reach their own operations
+	if resp := api.Get("/locations/at?lat=6.6018&lng=3.3515"); resp.Code != http.StatusOK {
+		t.Errorf("Expected /locations/at to answer %d, got %d", http.StatusOK, resp.Code)
+	}
+}
+
 func TestFindNearest(t *testing.T) {
 	api, _ := setupTestAPI(t)
 
 	locationReq1 := dto.LocationRequest{
 		Name:      "New York",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	locationReq2 := dto.LocationRequest{
 		Name:      "Los Angeles",
-		Latitude:  34.0522,
-		Longitude: -118.2437,
+		Latitude:  ptr(34.0522),
+		Longitude: ptr(-118.2437),
 	}
 
 	// Create locations
@@ -197,6 +246,103 @@ func TestFindNearest(t *testing.T) {
 	}
 }
 
+func TestFindNearestETA(t *testing.T) {
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+
+	nearest := func(t *testing.T, api humatest.TestAPI, query string) dto.NearestLocationResponse {
+		t.Helper()
+		resp := api.Get("/nearest?lat=6.5&lng=3.35" + query)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var response dto.NearestLocationResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return response
+	}
+
+	if response := nearest(t, api, ""); response.ETA != nil {
+		t.Errorf("Expected no eta_minutes without a speed, got %d", *response.ETA)
+	}
+
+	// Ikeja is about 11.3 km away: 22.6 minutes at 30 km/h, 13.6 at 50 km/h
+	response := nearest(t, api, "&speed_kmh=30")
+	if response.ETA == nil || *response.ETA != 23 {
+		t.Errorf("Expected 23 minutes at 30 km/h for %v, got %v", response.Distance, response.ETA)
+	}
+	if want := int(math.Round(response.Distance.TravelTime(30).Minutes())); *response.ETA != want {
+		t.Errorf("Expected the ETA to follow the reported distance, got %d for %v", *response.ETA, response.Distance)
+	}
+
+	repo := memory.NewInMemoryLocationRepository()
+	repo.Save(&domain.Location{Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515})
+	_, defaulted := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(service.NewLocationService(repo), WithDefaultSpeed(50)).RegisterRoutes(defaulted)
+
+	if response := nearest(t, defaulted, ""); response.ETA == nil || *response.ETA != 14 {
+		t.Errorf("Expected 14 minutes at the configured 50 km/h, got %v", response.ETA)
+	}
+	if response := nearest(t, defaulted, "&speed_kmh=30"); response.ETA == nil || *response.ETA != 23 {
+		t.Errorf("Expected the requested speed to override the default, got %v", response.ETA)
+	}
+
+	for _, speed := range []string{"0", "-5"} {
+		if resp := api.Get("/nearest?lat=6.5&lng=3.35&speed_kmh=" + speed); resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("speed_kmh=%s: expected status %d, got %d", speed, http.StatusUnprocessableEntity, resp.Code)
+		}
+	}
+}
+
+func TestNearestETARounding(t *testing.T) {
+	tests := []struct {
+		distance geospatial.Distance
+		speedKmh float64
+		want     int
+	}{
+		{geospatial.Kilometers(2.37), 30, 5}, // 4.74 minutes
+		{geospatial.Kilometers(2.24), 30, 4}, // 4.48 minutes
+		{geospatial.Kilometers(2.25), 30, 5}, // 4.5 minutes rounds half up
+		{geospatial.Meters(100), 30, 1},      // 12 seconds is still a minute away
+		{0, 30, 0},
+		{geospatial.Miles(30), 30, 97}, // 48.28 km
+	}
+
+	for _, tt := range tests {
+		response := dto.NearestLocationResponse{Distance: tt.distance}
+		response.WithETA(tt.speedKmh)
+		if *response.ETA != tt.want {
+			t.Errorf("%v at %v km/h: expected %d minutes, got %d", tt.distance, tt.speedKmh, tt.want, *response.ETA)
+		}
+	}
+}
+
+func TestFindNearestExclude(t *testing.T) {
+	api, _ := setupTestAPI(t)
+
+	api.Post("/locations", dto.LocationRequest{Name: "New York", Latitude: ptr(40.7128), Longitude: ptr(-74.0060)})
+	api.Post("/locations", dto.LocationRequest{Name: "Boston", Latitude: ptr(42.3601), Longitude: ptr(-71.0589)})
+	api.Post("/locations", dto.LocationRequest{Name: "Los Angeles", Latitude: ptr(34.0522), Longitude: ptr(-118.2437)})
+
+	resp := api.Get("/nearest?lat=40.7589&lng=-73.9851&exclude=New%20York&exclude=Unknown")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var response dto.NearestLocationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Location.Name != "Boston" {
+		t.Errorf("Expected second-nearest Boston, got %s", response.Location.Name)
+	}
+	// Manhattan to Boston is roughly 300 km; New York itself would be ~5 km
+	if response.Distance < 250*geospatial.Kilometer || response.Distance > 350*geospatial.Kilometer {
+		t.Errorf("Expected distance to Boston, got %v", response.Distance)
+	}
+}
+
 func TestFindNearestMissingParams(t *testing.T) {
 	api, _ := setupTestAPI(t)
 
@@ -232,8 +378,8 @@ func TestCreateLocationInvalidData(t *testing.T) {
 			name: "empty name",
 			request: dto.LocationRequest{
 				Name:      "",
-				Latitude:  40.7128,
-				Longitude: -74.0060,
+				Latitude:  ptr(40.7128),
+				Longitude: ptr(-74.0060),
 			},
 			expected: 400,
 		},
@@ -241,8 +387,8 @@ func TestCreateLocationInvalidData(t *testing.T) {
 			name: "invalid_latitude",
 			request: dto.LocationRequest{
 				Name:      "Invalid Lat",
-				Latitude:  91.0,
-				Longitude: -74.0060,
+				Latitude:  ptr(91.0),
+				Longitude: ptr(-74.0060),
 			},
 			expected: 400,
 		},
@@ -250,8 +396,8 @@ func TestCreateLocationInvalidData(t *testing.T) {
 			name: "invalid_longitude",
 			request: dto.LocationRequest{
 				Name:      "Invalid Lng",
-				Latitude:  40.7128,
-				Longitude: -181.0,
+				Latitude:  ptr(40.7128),
+				Longitude: ptr(-181.0),
 			},
 			expected: 400,
 		},
@@ -266,3 +412,278 @@ func TestCreateLocationInvalidData(t *testing.T) {
 		})
 	}
 }
+
+func TestLocationCoordinatesRoundTrip(t *testing.T) {
+	api, _ := setupTestAPI(t)
+
+	resp := api.Post("/locations", map[string]any{"name": "Noisy", "latitude": 40.712800000000004, "longitude": -74.00600000000001})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.Code)
+	}
+	if body := resp.Body.String(); !strings.Contains(body, `"latitude":40.7128`) || !strings.Contains(body, `"longitude":-74.006,`) {
+		t.Errorf("Expected canonical coordinates in the response, got %s", body)
+	}
+	var created dto.LocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &created)
+
+	// Reading it back gives values equal to the ones returned on create
+	resp = api.Get("/locations")
+	var list dto.LocationListResponse
+	json.Unmarshal(resp.Body.Bytes(), &list)
+	if len(list.Locations) != 1 || list.Locations[0].Latitude != created.Latitude || list.Locations[0].Longitude != created.Longitude {
+		t.Errorf("Expected listed coordinates to equal created ones, got %+v", list.Locations)
+	}
+}
+
+func TestCreateLocationCoordinateBounds(t *testing.T) {
+	api, _ := setupTestAPI(t)
+
+	// The bounds themselves, the equator and the prime meridian are valid
+	accepted := []map[string]any{
+		{"name": "North Pole", "latitude": 90.0, "longitude": 0.0},
+		{"name": "South Pole", "latitude": -90.0, "longitude": 180.0},
+		{"name": "Date Line", "latitude": 0.0, "longitude": -180.0},
+		{"name": "Null Island", "latitude": 0, "longitude": 0},
+	}
+	for _, body := range accepted {
+		if resp := api.Post("/locations", body); resp.Code != http.StatusCreated {
+			t.Errorf("%s: expected status %d, got %d: %s", body["name"], http.StatusCreated, resp.Code, resp.Body.String())
+		}
+	}
+
+	rejected := []map[string]any{
+		{"name": "Too North", "latitude": 90.0000001, "longitude": 0},
+		{"name": "Too East", "latitude": 0, "longitude": 180.0000001},
+		{"name": "Missing Latitude", "longitude": 3.4},
+	}
+	for _, body := range rejected {
+		if resp := api.Post("/locations", body); resp.Code < 400 || resp.Code >= 500 {
+			t.Errorf("%s: expected a client error, got %d", body["name"], resp.Code)
+		}
+	}
+
+	resp := api.Get("/nearest?lat=-90&lng=180")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	var nearest dto.NearestLocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &nearest)
+	if nearest.Location.Name != "South Pole" || nearest.Distance != 0 {
+		t.Errorf("Expected South Pole at 0 km, got %s at %f", nearest.Location.Name, nearest.Distance)
+	}
+}
+
+func TestCreateLocationOmittedZeroAndOutOfRange(t *testing.T) {
+	api, _ := setupTestAPI(t)
+
+	tests := []struct {
+		name     string
+		body     map[string]any
+		status   int
+		location string
+		message  string
+	}{
+		{"latitude omitted", map[string]any{"longitude": 3.4}, http.StatusBadRequest, "body.latitude", "latitude is required"},
+		{"longitude omitted", map[string]any{"latitude": 6.4}, http.StatusBadRequest, "body.longitude", "longitude is required"},
+		{"latitude null", map[string]any{"latitude": nil, "longitude": 3.4}, http.StatusBadRequest, "body.latitude", "latitude is required"},
+		{"latitude zero", map[string]any{"latitude": 0, "longitude": 3.4}, http.StatusCreated, "", ""},
+		{"longitude zero", map[string]any{"latitude": 6.4, "longitude": 0}, http.StatusCreated, "", ""},
+		{"latitude out of range", map[string]any{"latitude": -90.5, "longitude": 3.4}, http.StatusBadRequest, "body.latitude", "latitude must be at least -90"},
+		{"longitude out of range", map[string]any{"latitude": 6.4, "longitude": 180.5}, http.StatusBadRequest, "body.longitude", "longitude must be at most 180"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.body["name"] = tt.name
+			resp := api.Post("/locations", tt.body)
+			if resp.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, resp.Code, resp.Body.String())
+			}
+			if tt.location == "" {
+				return
+			}
+
+			body := decodeCodedError(t, resp.Body.Bytes())
+			if body.Code != "VALIDATION_ERROR" || len(body.Errors) != 1 {
+				t.Fatalf("Expected one VALIDATION_ERROR field error, got %+v", body)
+			}
+			if body.Errors[0].Location != tt.location || body.Errors[0].Message != tt.message {
+				t.Errorf("Expected %q at %s, got %q at %s", tt.message, tt.location, body.Errors[0].Message, body.Errors[0].Location)
+			}
+		})
+	}
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	api, _ := setupTestAPI(t)
+	if resp := api.Post("/locations", map[string]any{"name": "Hub", "latitude": 6.45, "longitude": 3.39}); resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.Code)
+	}
+
+	type detail struct {
+		location string
+		message  string
+		value    any
+	}
+	tests := []struct {
+		name    string
+		path    string
+		body    map[string]any
+		details []detail
+	}{
+		{
+			name: "create",
+			path: "/locations",
+			body: map[string]any{"name": "", "latitude": 91.5, "longitude": -180.5},
+			details: []detail{
+				{"body.latitude", "latitude must be at most 90", 91.5},
+				{"body.longitude", "longitude must be at least -180", -180.5},
+				{"body.name", "name is required", ""},
+			},
+		},
+		{
+			// The schema checks the coordinates of changes, leaving the
+			// blank name to the service
+			name: "update in a transaction",
+			path: "/locations/transaction",
+			body: map[string]any{"operations": []map[string]any{
+				{"op": "update", "name": "Hub", "changes": map[string]any{"name": " "}},
+			}},
+			details: []detail{
+				{"body.operations[0].changes.name", "name is required", " "},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := api.Post(tt.path, tt.body)
+			if resp.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+			}
+			body := decodeCodedError(t, resp.Body.Bytes())
+			if body.Code != "VALIDATION_ERROR" || len(body.Errors) != len(tt.details) {
+				t.Fatalf("Expected %d VALIDATION_ERROR field errors, got %+v", len(tt.details), body)
+			}
+			for i, want := range tt.details {
+				got := body.Errors[i]
+				if got.Location != want.location || got.Message != want.message || got.Value != want.value {
+					t.Errorf("Expected %q at %s with value %v, got %q at %s with value %v",
+						want.message, want.location, want.value, got.Message, got.Location, got.Value)
+				}
+			}
+		})
+	}
+}
+
+// unavailableNearestRepository fails every nearest search once down is set
+type unavailableNearestRepository struct {
+	*memory.InMemoryLocationRepository
+	down bool
+}
+
+func (r *unavailableNearestRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	if r.down {
+		return nil, 0, errors.New("connection refused")
+	}
+	return r.InMemoryLocationRepository.FindNearest(latitude, longitude, exclude...)
+}
+
+func TestFindNearestMarksStaleFallback(t *testing.T) {
+	repo := &unavailableNearestRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	snapshot := fallback.NewSnapshot(repo, time.Hour)
+	svc := service.NewLocationService(repo, service.WithNearestFallback(snapshot, 0))
+	svc.CreateLocation("Ikeja", 6.6018, 3.3515)
+	snapshot.Refresh(context.Background())
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(svc).RegisterRoutes(api)
+
+	resp := api.Get("/nearest?lat=6.5&lng=3.4")
+	if resp.Code != http.StatusOK || resp.Header().Get("X-Data-Stale") != "" || strings.Contains(resp.Body.String(), "stale") {
+		t.Fatalf("Expected a fresh answer without staleness markers, got %d %q: %s", resp.Code, resp.Header().Get("X-Data-Stale"), resp.Body.String())
+	}
+
+	repo.down = true
+	resp = api.Get("/nearest?lat=6.5&lng=3.4")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if got := resp.Header().Get("X-Data-Stale"); got != "true" {
+		t.Errorf("Expected X-Data-Stale: true, got %q", got)
+	}
+	var body dto.NearestLocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &body)
+	if !body.Stale || body.AsOf == nil || !body.AsOf.Equal(snapshot.TakenAt()) || body.Location.Name != "Ikeja" {
+		t.Errorf("Expected a stale answer as of the snapshot, got %+v", body)
+	}
+}
+
+func TestCreateLocationNamePolicy(t *testing.T) {
+	blocklist, err := text.NewBlocklist([]string{"asdf"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to compile blocklist: %v", err)
+	}
+	locationService := service.NewLocationService(memory.NewInMemoryLocationRepository(),
+		service.WithNamePolicy(blocklist, []string{"internal-"}))
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+		{Name: "ops", Key: "ops-key", Scopes: []auth.Scope{auth.ScopeWrite, auth.ScopeAdmin}},
+	})))
+	NewLocationHandler(locationService).RegisterRoutes(api)
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"ASDF", "partner-key", http.StatusUnprocessableEntity},
+		{"asdf", "ops-key", http.StatusUnprocessableEntity},
+		{"internal-depot", "partner-key", http.StatusUnprocessableEntity},
+		{"internal-depot", "ops-key", http.StatusCreated},
+		{"Ikeja", "partner-key", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		resp := api.Post("/locations", "X-API-Key: "+tt.key,
+			dto.LocationRequest{Name: tt.name, Latitude: ptr(6.5), Longitude: ptr(3.4)})
+		if resp.Code != tt.status {
+			t.Errorf("%s as %s: expected status %d, got %d", tt.name, tt.key, tt.status, resp.Code)
+			continue
+		}
+		if tt.status == http.StatusUnprocessableEntity {
+			if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "NAME_NOT_ALLOWED" {
+				t.Errorf("%s as %s: expected NAME_NOT_ALLOWED, got %+v", tt.name, tt.key, body)
+			}
+		}
+	}
+}
+
+// TestRouteNamesNotAllowed keeps locations from taking a name that a fixed
+// route under /locations/ would answer for
+func TestRouteNamesNotAllowed(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	api := newTestAPI(t)
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+
+	for _, name := range []string{"at", "changes", "suggest"} {
+		resp := api.Post("/locations", dto.LocationRequest{Name: name, Latitude: ptr(6.5), Longitude: ptr(3.4)})
+		if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusUnprocessableEntity || body.Code != "NAME_NOT_ALLOWED" {
+			t.Errorf("Expected %s refused, got %d %+v", name, resp.Code, body)
+		}
+	}
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6), Longitude: ptr(3.35)})
+	resp := api.Post("/locations/Ikeja/aliases", map[string]string{"alias": "duplicates"})
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusUnprocessableEntity || body.Code != "NAME_NOT_ALLOWED" {
+		t.Errorf("Expected the alias refused, got %d %+v", resp.Code, body)
+	}
+
+	// Names that only resemble a route can be read back
+	api.Post("/locations", dto.LocationRequest{Name: "At", Latitude: ptr(6.5), Longitude: ptr(3.4)})
+	var read dto.LocationResponse
+	resp = api.Get("/locations/At")
+	json.Unmarshal(resp.Body.Bytes(), &read)
+	if resp.Code != http.StatusOK || read.Name != "At" {
+		t.Errorf("Expected At read back, got %d %s", resp.Code, resp.Body.String())
+	}
+}
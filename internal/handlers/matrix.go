@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// DistanceMatrixRequest represents the points of a distance matrix
+type DistanceMatrixRequest struct {
+	Body dto.DistanceMatrixRequest `json:"body"`
+}
+
+// DistanceMatrixResponse represents a distance matrix
+type DistanceMatrixResponse struct {
+	Body dto.DistanceMatrixResponse `json:"body"`
+}
+
+// DistanceMatrix handles POST /distance/matrix requests
+func (h *LocationHandler) DistanceMatrix(ctx context.Context, input *DistanceMatrixRequest) (*DistanceMatrixResponse, error) {
+	origins, destinations := input.Body.Origins, input.Body.Destinations
+	if err := checkLimit(ctx, "origins x destinations", len(origins)*len(destinations), h.limits.MaxMatrixCells); err != nil {
+		return nil, err
+	}
+
+	resolver := matrixResolver{service: h.service, found: map[string]*geospatial.Coordinate{}}
+	from, err := resolver.resolve(ctx, "origins", origins)
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolver.resolve(ctx, "destinations", destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Measure only between resolved points, then spread the results back
+	// out to their positions
+	fromPoints, fromIndex := compactPoints(from)
+	toPoints, toIndex := compactPoints(to)
+	measured := h.sphere.DistanceMatrix(fromPoints, toPoints)
+
+	unit := dto.DistanceUnit(ctx, input.Body.Unit)
+	distances := make([][]*float64, len(origins))
+	for i := range distances {
+		distances[i] = make([]*float64, len(destinations))
+		if fromIndex[i] < 0 {
+			continue
+		}
+		for j := range distances[i] {
+			if toIndex[j] < 0 {
+				continue
+			}
+			distance := dto.InUnit(measured[fromIndex[i]][toIndex[j]], unit)
+			distances[i][j] = &distance
+		}
+	}
+
+	return &DistanceMatrixResponse{Body: dto.DistanceMatrixResponse{
+		Unit:      unit,
+		Distances: distances,
+		Errors:    append([]dto.MatrixEntryError{}, resolver.errors...),
+	}}, nil
+}
+
+// matrixResolver looks up the names in a matrix request, each name once
+type matrixResolver struct {
+	service domain.LocationService
+	// found caches lookups; nil marks a name that was not found
+	found  map[string]*geospatial.Coordinate
+	errors []dto.MatrixEntryError
+}
+
+// resolve returns the coordinates of each point, nil for names that were
+// not found. A point that is neither a name nor a full coordinate pair
+// fails the request.
+func (r *matrixResolver) resolve(ctx context.Context, list string, points []dto.MatrixPoint) ([]*geospatial.Coordinate, error) {
+	resolved := make([]*geospatial.Coordinate, len(points))
+	for i, point := range points {
+		hasCoordinates := point.Latitude != nil && point.Longitude != nil
+		if (point.Latitude != nil) != (point.Longitude != nil) || (point.Name != "") == hasCoordinates {
+			index := strconv.Itoa(i)
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "INVALID_MATRIX_POINT",
+				list+"["+index+"] must have either a name or both latitude and longitude").
+				With("list", list).With("index", index))
+		}
+		if hasCoordinates {
+			resolved[i] = &geospatial.Coordinate{Latitude: *point.Latitude, Longitude: *point.Longitude}
+			continue
+		}
+
+		coordinate, seen := r.found[point.Name]
+		if !seen {
+			location, err := r.service.GetLocation(point.Name)
+			switch {
+			case err == nil:
+				coordinate = &geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude}
+			case !errors.Is(err, domain.ErrLocationNotFound):
+				return nil, storageError(ctx, err, "Failed to resolve locations")
+			}
+			r.found[point.Name] = coordinate
+		}
+		if coordinate == nil {
+			r.errors = append(r.errors, dto.MatrixEntryError{
+				List: list, Index: i, Name: point.Name, Code: "LOCATION_NOT_FOUND", Message: "Location not found",
+			})
+		}
+		resolved[i] = coordinate
+	}
+	return resolved, nil
+}
+
+// compactPoints drops unresolved points, returning each original
+// position's index among the rest, or -1 when it was dropped
+func compactPoints(points []*geospatial.Coordinate) ([]geospatial.Coordinate, []int) {
+	compact := make([]geospatial.Coordinate, 0, len(points))
+	index := make([]int, len(points))
+	for i, point := range points {
+		if point == nil {
+			index[i] = -1
+			continue
+		}
+		index[i] = len(compact)
+		compact = append(compact, *point)
+	}
+	return compact, index
+}
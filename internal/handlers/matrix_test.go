@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestDistanceMatrix(t *testing.T) {
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	api.Post("/locations", dto.LocationRequest{Name: "Lekki", Latitude: ptr(6.4474), Longitude: ptr(3.4723)})
+	api.Post("/locations/Lekki/aliases", map[string]string{"alias": "Lekki Phase 1"})
+
+	resp := api.Post("/distance/matrix", dto.DistanceMatrixRequest{
+		Origins:      []dto.MatrixPoint{{Name: "Ikeja"}, {Name: "Ajah"}},
+		Destinations: []dto.MatrixPoint{{Name: "Lekki Phase 1"}, {Latitude: ptr(6.6018), Longitude: ptr(3.3515)}, {Name: "Ajah"}},
+		Unit:         "m",
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var matrix dto.DistanceMatrixResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &matrix); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if matrix.Unit != "m" || len(matrix.Distances) != 2 || len(matrix.Distances[0]) != 3 {
+		t.Fatalf("Expected a 2x3 matrix in metres, got %+v", matrix)
+	}
+
+	row := matrix.Distances[0]
+	if row[0] == nil || math.Abs(*row[0]-21850) > 500 {
+		t.Errorf("Expected Ikeja to Lekki about 21.85 km in metres, got %v", row[0])
+	}
+	if row[1] == nil || *row[1] > 1e-6 {
+		t.Errorf("Expected Ikeja to its own coordinates to be 0, got %v", row[1])
+	}
+	if row[2] != nil {
+		t.Errorf("Expected a null column for the unknown destination, got %v", *row[2])
+	}
+	for j, cell := range matrix.Distances[1] {
+		if cell != nil {
+			t.Errorf("Expected a null row for the unknown origin, got %v at column %d", *cell, j)
+		}
+	}
+
+	want := []dto.MatrixEntryError{
+		{List: "origins", Index: 1, Name: "Ajah", Code: "LOCATION_NOT_FOUND", Message: "Location not found"},
+		{List: "destinations", Index: 2, Name: "Ajah", Code: "LOCATION_NOT_FOUND", Message: "Location not found"},
+	}
+	if len(matrix.Errors) != len(want) || matrix.Errors[0] != want[0] || matrix.Errors[1] != want[1] {
+		t.Errorf("Expected errors %+v, got %+v", want, matrix.Errors)
+	}
+}
+
+func TestDistanceMatrixRejects(t *testing.T) {
+	limits := config.DefaultLimits()
+	limits.MaxMatrixCells = 4
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository()), WithLimits(limits)).RegisterRoutes(api)
+
+	point := dto.MatrixPoint{Latitude: ptr(6.5), Longitude: ptr(3.4)}
+	tests := []struct {
+		name string
+		body dto.DistanceMatrixRequest
+		code string
+	}{
+		{"too many cells", dto.DistanceMatrixRequest{
+			Origins: []dto.MatrixPoint{point, point, point}, Destinations: []dto.MatrixPoint{point, point},
+		}, "LIMIT_EXCEEDED"},
+		{"name and coordinates", dto.DistanceMatrixRequest{
+			Origins: []dto.MatrixPoint{{Name: "Ikeja", Latitude: ptr(6.5), Longitude: ptr(3.4)}}, Destinations: []dto.MatrixPoint{point},
+		}, "INVALID_MATRIX_POINT"},
+		{"half a coordinate", dto.DistanceMatrixRequest{
+			Origins: []dto.MatrixPoint{point}, Destinations: []dto.MatrixPoint{{Latitude: ptr(6.5)}},
+		}, "INVALID_MATRIX_POINT"},
+		{"empty point", dto.DistanceMatrixRequest{
+			Origins: []dto.MatrixPoint{{}}, Destinations: []dto.MatrixPoint{point},
+		}, "INVALID_MATRIX_POINT"},
+	}
+	for _, tt := range tests {
+		resp := api.Post("/distance/matrix", tt.body)
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != tt.code {
+			t.Errorf("%s: expected %s, got %+v", tt.name, tt.code, body)
+		}
+	}
+}
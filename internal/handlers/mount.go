@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type basePathKey struct{}
+
+// StripBasePath serves next under prefix, such as "/geo", for mounting the
+// API inside another server. Like http.StripPrefix it removes the prefix
+// from request paths, and answers 404 to paths outside it, but keeps it so
+// Link headers point back under the prefix.
+func StripBasePath(prefix string, next http.Handler) http.Handler {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" {
+		return next
+	}
+	stripped := http.StripPrefix(prefix, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), basePathKey{}, basePathFromContext(r.Context())+prefix)
+		stripped.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// basePathFromContext returns the prefixes StripBasePath removed, outermost
+// first
+func basePathFromContext(ctx context.Context) string {
+	prefix, _ := ctx.Value(basePathKey{}).(string)
+	return prefix
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// openAPIDoc is the subset of the produced openapi.json the tests walk
+type openAPIDoc struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string `json:"operationId"`
+	Deprecated  bool   `json:"deprecated"`
+	Parameters  []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Example     any    `json:"example"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]openAPIMediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]openAPIMediaType `json:"content"`
+	} `json:"responses"`
+}
+
+type openAPIMediaType struct {
+	Schema openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Examples   []any                    `json:"examples"`
+	Properties map[string]openAPISchema `json:"properties"`
+	Items      *openAPISchema           `json:"items"`
+	MultipleOf float64                  `json:"multipleOf"`
+}
+
+func fetchOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+	api, _ := setupTestAPI(t)
+	NewHealthHandler().RegisterRoutes(api)
+
+	resp := api.Get("/openapi.json")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var doc openAPIDoc
+	if err := json.Unmarshal(resp.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode openapi.json: %v", err)
+	}
+	return doc
+}
+
+// hasExample reports whether the schema or anything it references carries an example
+func (d openAPIDoc) hasExample(schema openAPISchema, seen map[string]bool) bool {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		if seen[name] {
+			return false
+		}
+		seen[name] = true
+		return d.hasExample(d.Components.Schemas[name], seen)
+	}
+	if len(schema.Examples) > 0 {
+		return true
+	}
+	if schema.Items != nil && d.hasExample(*schema.Items, seen) {
+		return true
+	}
+	for _, property := range schema.Properties {
+		if d.hasExample(property, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOpenAPIDeclaresErrorResponses(t *testing.T) {
+	doc := fetchOpenAPI(t)
+
+	expected := map[string][]int{
+		"create-location": {400, 409, 422, 500},
+		"get-locations":   {400, 422, 500},
+		"delete-location": {404, 422, 500},
+		"find-nearest":    {404, 422, 500},
+		"health-check":    {500},
+	}
+
+	found := map[string]bool{}
+	for path, methods := range doc.Paths {
+		for method, op := range methods {
+			codes, ok := expected[op.OperationID]
+			if !ok {
+				continue
+			}
+			found[op.OperationID] = true
+
+			for _, code := range codes {
+				response, ok := op.Responses[strconv.Itoa(code)]
+				if !ok {
+					t.Errorf("%s %s: missing %d response", method, path, code)
+					continue
+				}
+				media, ok := response.Content["application/problem+json"]
+				if !ok || media.Schema.Ref != "#/components/schemas/CodedError" {
+					t.Errorf("%s %s: %d response does not use the error schema", method, path, code)
+				}
+			}
+		}
+	}
+
+	for id := range expected {
+		if !found[id] {
+			t.Errorf("Operation %s not found in openapi.json", id)
+		}
+	}
+}
+
+func TestOpenAPIDeclaresExamples(t *testing.T) {
+	doc := fetchOpenAPI(t)
+
+	for path, methods := range doc.Paths {
+		for method, op := range methods {
+			hasExample := false
+			for _, param := range op.Parameters {
+				if param.Description == "" {
+					t.Errorf("%s %s: parameter %s has no description", method, path, param.Name)
+				}
+				if param.Example != nil {
+					hasExample = true
+				}
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					hasExample = hasExample || doc.hasExample(media.Schema, map[string]bool{})
+				}
+			}
+			for code, response := range op.Responses {
+				if !strings.HasPrefix(code, "2") {
+					continue
+				}
+				for _, media := range response.Content {
+					hasExample = hasExample || doc.hasExample(media.Schema, map[string]bool{})
+				}
+			}
+
+			if !hasExample {
+				t.Errorf("%s %s: no request or response example", method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPIDocumentsCoordinatePrecision(t *testing.T) {
+	doc := fetchOpenAPI(t)
+
+	schema := doc.Components.Schemas["LocationResponse"]
+	for _, name := range []string{"latitude", "longitude"} {
+		if got := schema.Properties[name].MultipleOf; got != 0.000001 {
+			t.Errorf("Expected %s multipleOf 0.000001, got %v", name, got)
+		}
+	}
+	// Requests are rounded rather than rejected, so they carry no multipleOf
+	if got := doc.Components.Schemas["LocationRequest"].Properties["latitude"].MultipleOf; got != 0 {
+		t.Errorf("Expected no multipleOf on request latitude, got %v", got)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestCreateLocationWithOpeningHours(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+
+	hours := &domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{
+		"saturday": {{Open: "22:00", Close: "02:00"}},
+	}}
+	resp := api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711), OpeningHours: hours})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	var created dto.LocationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if created.OpeningHours == nil || created.OpeningHours.Days["saturday"][0].Close != "02:00" {
+		t.Errorf("Expected the hours echoed back, got %+v", created.OpeningHours)
+	}
+
+	for name, bad := range map[string]*domain.OpeningHours{
+		"Atlantis": {Timezone: "Africa/Atlantis"},
+		"Overlap": {Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{
+			"monday": {{Open: "06:00", Close: "12:00"}, {Open: "11:00", Close: "18:00"}},
+		}},
+	} {
+		resp := api.Post("/locations", dto.LocationRequest{Name: name, Latitude: ptr(6.5), Longitude: ptr(3.4), OpeningHours: bad})
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusUnprocessableEntity, resp.Code)
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "INVALID_OPENING_HOURS" {
+			t.Errorf("%s: expected INVALID_OPENING_HOURS, got %+v", name, body)
+		}
+	}
+}
+
+func TestOpenFilters(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	weekdays := &domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{
+		"monday": {{Open: "06:00", Close: "22:00"}},
+	}}
+	api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711), OpeningHours: weekdays})
+
+	// 18 August 2025 is a Monday; 11:00 UTC is midday in Lagos
+	resp := api.Get("/nearest?lat=6.5&lng=3.37&open_at=2025-08-18T11:00:00Z")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	resp = api.Get("/nearest?lat=6.5&lng=3.37&open_at=2025-08-17T11:00:00Z")
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusNotFound, resp.Code, resp.Body.String())
+	}
+	if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "NO_OPEN_LOCATION" {
+		t.Errorf("Expected NO_OPEN_LOCATION, got %+v", body)
+	}
+
+	resp = api.Get("/locations?open_at=2025-08-17T11:00:00Z")
+	var list dto.LocationListResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if list.Count != 0 {
+		t.Errorf("Expected no open locations on Sunday, got %+v", list.Locations)
+	}
+
+	for _, path := range []string{"/nearest?lat=6.5&lng=3.37&", "/locations?"} {
+		resp := api.Get(path + "open_now=true&open_at=2025-08-18T11:00:00Z")
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d", path, http.StatusUnprocessableEntity, resp.Code)
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "OPEN_FILTER_CONFLICT" {
+			t.Errorf("%s: expected OPEN_FILTER_CONFLICT, got %+v", path, body)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// DisableOperations wraps api so that RegisterRoutes registers the listed
+// operations as stubs answering 403 ENDPOINT_DISABLED, and leaves them out
+// of the OpenAPI document. The stubs still run the API's middlewares, so
+// the error is localized and authentication applies as before.
+func DisableOperations(api huma.API, operationIDs []string) huma.API {
+	if len(operationIDs) == 0 {
+		return api
+	}
+	disabled := make(map[string]bool, len(operationIDs))
+	for _, id := range operationIDs {
+		disabled[id] = true
+	}
+	return &operationFilter{API: api, disabled: disabled}
+}
+
+type operationFilter struct {
+	huma.API
+	disabled map[string]bool
+}
+
+// DocumentOperation implements huma.OperationDocumenter, which huma.Register
+// calls instead of adding the operation to the document itself
+func (a *operationFilter) DocumentOperation(op *huma.Operation) {
+	if a.disabled[op.OperationID] {
+		return
+	}
+	if documenter, ok := a.API.(huma.OperationDocumenter); ok {
+		documenter.DocumentOperation(op)
+		return
+	}
+	if !op.Hidden {
+		a.OpenAPI().AddOperation(op)
+	}
+}
+
+func (a *operationFilter) Adapter() huma.Adapter {
+	return &filteredAdapter{Adapter: a.API.Adapter(), api: a}
+}
+
+type filteredAdapter struct {
+	huma.Adapter
+	api *operationFilter
+}
+
+func (a *filteredAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
+	if !a.api.disabled[op.OperationID] {
+		a.Adapter.Handle(op, handler)
+		return
+	}
+	a.Adapter.Handle(op, a.api.Middlewares().Handler(func(ctx huma.Context) {
+		apierrors.RespondWithHumaError(ctx, apierrors.New(http.StatusForbidden, "ENDPOINT_DISABLED",
+			"The "+op.OperationID+" endpoint is disabled").With("operation", op.OperationID))
+	}))
+}
+
+// OmitAdminOperations wraps api so that RegisterRoutes neither serves nor
+// documents operations that need the admin scope. A server without
+// authentication cannot tell an admin from anyone else, so it must not
+// offer them.
+func OmitAdminOperations(api huma.API) huma.API {
+	return &adminOmitter{API: api}
+}
+
+type adminOmitter struct {
+	huma.API
+}
+
+func isAdminOperation(op *huma.Operation) bool {
+	return auth.RequiredScope(op) == auth.ScopeAdmin
+}
+
+// DocumentOperation implements huma.OperationDocumenter
+func (a *adminOmitter) DocumentOperation(op *huma.Operation) {
+	if isAdminOperation(op) {
+		return
+	}
+	if documenter, ok := a.API.(huma.OperationDocumenter); ok {
+		documenter.DocumentOperation(op)
+		return
+	}
+	if !op.Hidden {
+		a.OpenAPI().AddOperation(op)
+	}
+}
+
+func (a *adminOmitter) Adapter() huma.Adapter {
+	return &adminOmittingAdapter{Adapter: a.API.Adapter()}
+}
+
+type adminOmittingAdapter struct {
+	huma.Adapter
+}
+
+func (a *adminOmittingAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
+	if !isAdminOperation(op) {
+		a.Adapter.Handle(op, handler)
+	}
+}
+
+// DeprecateOperations wraps api so that RegisterRoutes marks the listed
+// operations deprecated in the OpenAPI document. The headers telling
+// callers so come from middleware.Deprecation.
+func DeprecateOperations(api huma.API, operationIDs []string) huma.API {
+	if len(operationIDs) == 0 {
+		return api
+	}
+	deprecated := make(map[string]bool, len(operationIDs))
+	for _, id := range operationIDs {
+		deprecated[id] = true
+	}
+	return &deprecationMarker{API: api, deprecated: deprecated}
+}
+
+type deprecationMarker struct {
+	huma.API
+	deprecated map[string]bool
+}
+
+// DocumentOperation implements huma.OperationDocumenter
+func (a *deprecationMarker) DocumentOperation(op *huma.Operation) {
+	if a.deprecated[op.OperationID] {
+		op.Deprecated = true
+	}
+	if documenter, ok := a.API.(huma.OperationDocumenter); ok {
+		documenter.DocumentOperation(op)
+		return
+	}
+	if !op.Hidden {
+		a.OpenAPI().AddOperation(op)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestDisableOperations(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	location, _ := domain.NewLocation("Ikeja", 6.6, 3.35)
+	repo.Save(location)
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	filtered := DisableOperations(api, []string{"delete-location", "get-location", "restore-locations"})
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(filtered)
+	NewBackupHandler(repo, repo).RegisterRoutes(filtered)
+
+	resp := api.Delete("/locations/Ikeja")
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, resp.Code)
+	}
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil || body.Error.Code != "ENDPOINT_DISABLED" {
+		t.Fatalf("Expected ENDPOINT_DISABLED, got %s", resp.Body.String())
+	}
+	if _, err := repo.FindByName("Ikeja"); err != nil {
+		t.Fatalf("Expected Ikeja to survive, got %v", err)
+	}
+
+	if resp := api.Post("/admin/restore", map[string]any{}); resp.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d for restore, got %d", http.StatusForbidden, resp.Code)
+	}
+	if resp := api.Get("/locations"); resp.Code != http.StatusOK {
+		t.Fatalf("Expected enabled operation to answer %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	resp = api.Get("/openapi.json")
+	var doc openAPIDoc
+	if err := json.Unmarshal(resp.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode openapi.json: %v", err)
+	}
+	for path, item := range doc.Paths {
+		for _, op := range item {
+			if op.OperationID == "delete-location" || op.OperationID == "get-location" || op.OperationID == "restore-locations" {
+				t.Errorf("Expected %s to be left out of the document, found at %s", op.OperationID, path)
+			}
+		}
+	}
+	if _, ok := doc.Paths["/locations/{name}"]; ok {
+		t.Error("Expected the emptied /locations/{name} path to be left out")
+	}
+	if doc.Paths["/locations"]["get"].OperationID != "get-locations" || doc.Paths["/admin/export"]["get"].OperationID != "export-locations" {
+		t.Errorf("Expected enabled operations to stay documented, got %v", doc.Paths)
+	}
+}
+
+func TestOmitAdminOperations(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	location, _ := domain.NewLocation("Ikeja", 6.6, 3.35)
+	repo.Save(location)
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	omitted := OmitAdminOperations(api)
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(omitted)
+	NewBackupHandler(repo, repo).RegisterRoutes(omitted)
+
+	if resp := api.Get("/admin/export"); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected the admin export not served, got %d", resp.Code)
+	}
+	if resp := api.Post("/admin/restore", map[string]any{}); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected the admin restore not served, got %d", resp.Code)
+	}
+	if resp := api.Get("/locations/Ikeja"); resp.Code != http.StatusOK {
+		t.Errorf("Expected other operations served, got %d", resp.Code)
+	}
+
+	var doc openAPIDoc
+	if err := json.Unmarshal(api.Get("/openapi.json").Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode openapi.json: %v", err)
+	}
+	for path := range doc.Paths {
+		if strings.HasPrefix(path, "/admin/") {
+			t.Errorf("Expected admin operations left out of the document, found %s", path)
+		}
+	}
+	if doc.Paths["/locations"]["get"].OperationID != "get-locations" {
+		t.Errorf("Expected other operations to stay documented, got %v", doc.Paths)
+	}
+}
+
+func TestDeprecateOperations(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	routes := DeprecateOperations(DisableOperations(api, []string{"delete-location"}), []string{"get-locations"})
+	NewLocationHandler(nil).RegisterRoutes(routes)
+
+	resp := api.Get("/openapi.json")
+	var doc openAPIDoc
+	if err := json.Unmarshal(resp.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode openapi.json: %v", err)
+	}
+	if !doc.Paths["/locations"]["get"].Deprecated {
+		t.Error("Expected get-locations to be marked deprecated")
+	}
+	if doc.Paths["/locations"]["post"].Deprecated {
+		t.Error("Expected create-location to stay current")
+	}
+	if _, ok := doc.Paths["/locations/{name}"]["delete"]; ok {
+		t.Error("Expected the disabled operation to stay left out")
+	}
+}
+
+// registerEveryRoute registers every handler's routes, with no services
+// behind them
+func registerEveryRoute(api huma.API) {
+	NewHealthHandler().RegisterRoutes(api)
+	NewLocationHandler(nil).RegisterRoutes(api)
+	NewUsageHandler(nil).RegisterRoutes(api)
+	NewSpatialHandler(nil, config.DefaultLimits()).RegisterRoutes(api)
+	NewDuplicateHandler(nil).RegisterRoutes(api)
+	NewBackupHandler(nil, nil).RegisterRoutes(api)
+	NewIntegrityHandler(nil).RegisterRoutes(api)
+	NewOutboxHandler(nil).RegisterRoutes(api)
+	NewAuditHandler(nil, config.DefaultLimits()).RegisterRoutes(api)
+	NewChangeHandler(nil, config.DefaultLimits()).RegisterRoutes(api)
+	NewQueryHandler(nil, nil, config.DefaultLimits(), "").RegisterRoutes(api)
+	NewSettingsHandler(nil).RegisterRoutes(api)
+	NewSyncHandler(nil, config.SyncConfig{}).RegisterRoutes(api)
+	NewFaultHandler(nil).RegisterRoutes(api)
+	NewImportHandler(nil, config.ImportsConfig{}).RegisterRoutes(api)
+	NewTruncateHandler(nil, nil, nil, "").RegisterRoutes(api)
+	NewCapabilitiesHandler().RegisterRoutes(api)
+}
+
+// TestOperationIDsMatchRoutes keeps config.OperationIDs, which validates
+// ENDPOINTS_DISABLED, in step with what the handlers register
+func TestOperationIDsMatchRoutes(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	registerEveryRoute(api)
+
+	var registered []string
+	for _, item := range api.OpenAPI().Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch} {
+			if op != nil {
+				registered = append(registered, op.OperationID)
+			}
+		}
+	}
+	sort.Strings(registered)
+	known := slices.Sorted(slices.Values(config.OperationIDs))
+	if !slices.Equal(registered, known) {
+		t.Fatalf("Expected config.OperationIDs to match the routes:\n  routes: %s\n  config: %s",
+			strings.Join(registered, ", "), strings.Join(known, ", "))
+	}
+}
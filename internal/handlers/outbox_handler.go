@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// OutboxListRequest represents the filters for inspecting the outbox
+type OutboxListRequest struct {
+	Status string `query:"status" enum:"pending,delivered,failed" default:"failed" doc:"Delivery status to list"`
+	Limit  int    `query:"limit" minimum:"1" maximum:"500" default:"100" doc:"Maximum number of events to return"`
+}
+
+// OutboxListResponse represents outbox events with dispatcher lag statistics
+type OutboxListResponse struct {
+	Body dto.OutboxListResponse `json:"body"`
+}
+
+// OutboxRequeueRequest represents the path parameter for requeueing an event
+type OutboxRequeueRequest struct {
+	Sequence int64 `path:"sequence" doc:"Outbox sequence number of the event"`
+}
+
+// OutboxHandler exposes the transactional outbox to operators
+type OutboxHandler struct {
+	repo domain.OutboxRepository
+}
+
+// NewOutboxHandler creates a new outbox handler
+func NewOutboxHandler(repo domain.OutboxRepository) *OutboxHandler {
+	return &OutboxHandler{repo: repo}
+}
+
+// RegisterRoutes registers the outbox admin routes with the Huma API
+func (h *OutboxHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-outbox-events",
+		Method:      http.MethodGet,
+		Path:        "/admin/outbox",
+		Summary:     "List Outbox Events",
+		Description: "Inspect outbox events by delivery status along with dispatcher lag",
+		Tags:        []string{"Admin"},
+	}, h.ListEvents)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "requeue-outbox-event",
+		Method:        http.MethodPost,
+		Path:          "/admin/outbox/{sequence}/requeue",
+		Summary:       "Requeue Outbox Event",
+		Description:   "Reset a failed or pending event so the dispatcher retries it immediately",
+		Tags:          []string{"Admin"},
+		DefaultStatus: http.StatusNoContent,
+	}, h.RequeueEvent)
+}
+
+// ListEvents handles GET /admin/outbox requests
+func (h *OutboxHandler) ListEvents(ctx context.Context, input *OutboxListRequest) (*OutboxListResponse, error) {
+	events, err := h.repo.ListOutboxEvents(domain.OutboxStatus(input.Status), input.Limit)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to list outbox events")
+	}
+
+	stats, err := h.repo.OutboxStats()
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to read outbox statistics")
+	}
+
+	return &OutboxListResponse{
+		Body: dto.FromOutboxEvents(events, stats),
+	}, nil
+}
+
+// RequeueEvent handles POST /admin/outbox/{sequence}/requeue requests
+func (h *OutboxHandler) RequeueEvent(ctx context.Context, input *OutboxRequeueRequest) (*struct{}, error) {
+	if err := h.repo.RequeueOutboxEvent(input.Sequence); err != nil {
+		if errors.Is(err, domain.ErrOutboxEventNotFound) {
+			return nil, huma.Error404NotFound("Undelivered outbox event not found")
+		}
+		return nil, huma.Error500InternalServerError("Failed to requeue outbox event")
+	}
+
+	return &struct{}{}, nil
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// setupOwnershipAPI serves locations to two writers and an admin
+func setupOwnershipAPI(t *testing.T, enforce bool) humatest.TestAPI {
+	t.Helper()
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "fleet-app", Key: "fleet-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+		{Name: "ops", Key: "ops-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin}},
+	})))
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository()), WithOwnershipEnforcement(enforce)).RegisterRoutes(api)
+	return api
+}
+
+func TestLocationCreatedBy(t *testing.T) {
+	t.Parallel()
+	api := setupOwnershipAPI(t, false)
+
+	resp := api.Post("/locations", "X-API-Key: fleet-key", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+	var created dto.LocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	if resp.Code != http.StatusCreated || created.CreatedBy != "fleet-app" {
+		t.Fatalf("Expected the location created by fleet-app, got %d %+v", resp.Code, created)
+	}
+	var read dto.LocationResponse
+	json.Unmarshal(api.Get("/locations/Yaba", "X-API-Key: partner-key").Body.Bytes(), &read)
+	if read.CreatedBy != "fleet-app" {
+		t.Errorf("Expected the owner stored, got %q", read.CreatedBy)
+	}
+
+	api.Post("/locations/transaction", "X-API-Key: partner-key", dto.TransactionRequest{Operations: []dto.OperationRequest{
+		{Op: "create", Location: &dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)}},
+	}})
+	json.Unmarshal(api.Get("/locations/Ikeja", "X-API-Key: partner-key").Body.Bytes(), &read)
+	if read.CreatedBy != "partner" {
+		t.Errorf("Expected a transaction create owned by its caller, got %q", read.CreatedBy)
+	}
+
+	// Without authentication there is no one to record
+	anonymous := newTestAPI(t)
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository()), WithOwnershipEnforcement(true)).RegisterRoutes(anonymous)
+	resp = anonymous.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+	var body map[string]any
+	json.Unmarshal(resp.Body.Bytes(), &body)
+	if _, ok := body["created_by"]; ok || resp.Code != http.StatusCreated {
+		t.Errorf("Expected an anonymous location with no owner, got %d %v", resp.Code, body)
+	}
+	if resp := anonymous.Delete("/locations/Yaba"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected anonymous deployments not to enforce ownership, got %d", resp.Code)
+	}
+}
+
+func TestOwnershipEnforcement(t *testing.T) {
+	t.Parallel()
+	api := setupOwnershipAPI(t, true)
+	for _, name := range []string{"Yaba", "Ikeja"} {
+		if resp := api.Post("/locations", "X-API-Key: fleet-key", dto.LocationRequest{Name: name, Latitude: ptr(6.5), Longitude: ptr(3.4)}); resp.Code != http.StatusCreated {
+			t.Fatalf("Failed to create %s: %d %s", name, resp.Code, resp.Body.String())
+		}
+	}
+
+	// Another writer may not change or delete the location
+	denied := []struct {
+		name string
+		resp func() *http.Response
+	}{
+		{"delete", func() *http.Response { return api.Delete("/locations/Yaba", "X-API-Key: partner-key").Result() }},
+		{"add alias", func() *http.Response {
+			return api.Post("/locations/Yaba/aliases", "X-API-Key: partner-key", map[string]string{"alias": "Tejuosho"}).Result()
+		}},
+		{"stock", func() *http.Response {
+			return api.Patch("/locations/Yaba/stock", "X-API-Key: partner-key", dto.StockRequest{CapacityLitres: ptr(33000.0)}).Result()
+		}},
+	}
+	for _, tt := range denied {
+		resp := tt.resp()
+		var body codedErrorBody
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != http.StatusForbidden || body.Code != "NOT_OWNER" {
+			t.Errorf("%s: expected 403 NOT_OWNER, got %d %+v", tt.name, resp.StatusCode, body)
+		}
+	}
+	resp := api.Post("/locations/transaction", "X-API-Key: partner-key", dto.TransactionRequest{Operations: []dto.OperationRequest{
+		{Op: "create", Location: &dto.LocationRequest{Name: "Ojota", Latitude: ptr(6.58), Longitude: ptr(3.38)}},
+		{Op: "delete", Name: "Ikeja"},
+	}})
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusForbidden || len(body.Errors) != 1 || body.Errors[0].Location != "body.operations[1].name" {
+		t.Errorf("Expected the transaction refused at its delete, got %d %+v", resp.Code, body)
+	}
+	if resp := api.Get("/locations/Ojota", "X-API-Key: partner-key"); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected nothing of the refused transaction kept, got %d", resp.Code)
+	}
+
+	// The owner may
+	if resp := api.Post("/locations/Yaba/aliases", "X-API-Key: fleet-key", map[string]string{"alias": "Tejuosho"}); resp.Code != http.StatusOK {
+		t.Errorf("Expected the owner to add an alias, got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Delete("/locations/Yaba", "X-API-Key: fleet-key"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected the owner to delete, got %d %s", resp.Code, resp.Body.String())
+	}
+
+	// And so may an admin
+	if resp := api.Delete("/locations/Ikeja", "X-API-Key: ops-key"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected an admin to delete another caller's location, got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Delete("/locations/Ikeja", "X-API-Key: partner-key"); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected a missing location to answer 404, got %d", resp.Code)
+	}
+}
+
+func TestOwnershipLeavesUnownedLocationsOpen(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	// Imported, or created before owners were recorded
+	location, _ := domain.NewLocation("Yaba", 6.5095, 3.3711)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save the location: %v", err)
+	}
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+	})))
+	NewLocationHandler(service.NewLocationService(repo), WithOwnershipEnforcement(true)).RegisterRoutes(api)
+
+	if resp := api.Post("/locations/Yaba/aliases", "X-API-Key: partner-key", map[string]string{"alias": "Tejuosho"}); resp.Code != http.StatusOK {
+		t.Errorf("Expected any writer to change a location with no owner, got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Delete("/locations/Yaba", "X-API-Key: partner-key"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected any writer to delete a location with no owner, got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+// recreatingRepository deletes a location and creates it again under
+// another owner just before deleting it, as a concurrent writer might
+type recreatingRepository struct {
+	*memory.InMemoryLocationRepository
+	owner string
+}
+
+func (r *recreatingRepository) Delete(name string, checks ...domain.Precondition) error {
+	previous, err := r.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if err := r.InMemoryLocationRepository.Delete(name); err != nil {
+		return err
+	}
+	location, _ := domain.NewLocation(name, previous.Latitude, previous.Longitude)
+	location.CreatedBy = r.owner
+	if err := r.Save(location); err != nil {
+		return err
+	}
+	return r.InMemoryLocationRepository.Delete(name, checks...)
+}
+
+func TestOwnershipCheckedWithTheChange(t *testing.T) {
+	t.Parallel()
+	repo := &recreatingRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository(), owner: "fleet-app"}
+	location, _ := domain.NewLocation("Yaba", 6.5095, 3.3711)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save the location: %v", err)
+	}
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+	})))
+	NewLocationHandler(service.NewLocationService(repo), WithOwnershipEnforcement(true)).RegisterRoutes(api)
+
+	// Yaba has no owner when the request arrives, but fleet-app owns it by
+	// the time it would be deleted
+	resp := api.Delete("/locations/Yaba", "X-API-Key: partner-key")
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusForbidden || body.Code != "NOT_OWNER" {
+		t.Errorf("Expected 403 NOT_OWNER for the owner found by the delete, got %d %+v", resp.Code, body)
+	}
+	if stored, err := repo.FindByName("Yaba"); err != nil || stored.CreatedBy != "fleet-app" {
+		t.Errorf("Expected fleet-app's location kept, got %+v, %v", stored, err)
+	}
+}
+
+func TestOwnershipNotEnforcedByDefault(t *testing.T) {
+	t.Parallel()
+	api := setupOwnershipAPI(t, false)
+	api.Post("/locations", "X-API-Key: fleet-key", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+	if resp := api.Delete("/locations/Yaba", "X-API-Key: partner-key"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected any writer to delete without enforcement, got %d", resp.Code)
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+)
+
+// ListLocationsRequest represents the query parameters for listing locations.
+// Offset pagination is selected with page/page_size, cursor pagination with
+// cursor/limit. Without any of them every location is returned.
+type ListLocationsRequest struct {
+	Page     int     `query:"page" minimum:"0" example:"1" doc:"1-based page number for offset pagination"`
+	PageSize int     `query:"page_size" minimum:"0" example:"20" doc:"Number of locations per page, up to the configured maximum (100 by default)"`
+	Cursor   string  `query:"cursor" example:"NDI" doc:"Opaque cursor returned by a previous response"`
+	Limit    int     `query:"limit" minimum:"0" example:"20" doc:"Number of locations per page for cursor pagination, up to the configured maximum"`
+	RefLat   float64 `query:"ref_lat" minimum:"-90" maximum:"90" example:"6.4281" doc:"Latitude of a reference point; with ref_lng, each location gains distance_km and distance"`
+	RefLng   float64 `query:"ref_lng" minimum:"-180" maximum:"180" example:"3.4219" doc:"Longitude of a reference point; must be given together with ref_lat"`
+	Unit     string  `query:"unit" enum:"km,m,mi,nmi" example:"mi" doc:"Unit of each location's distance from the reference point. Defaults to the caller's preferred unit, or the server's"`
+	LocationFilterParams
+	OpenFilterParams
+
+	hasRefLat     bool
+	hasRefLng     bool
+	requestURL    url.URL
+	basePath      string
+	host          string
+	forwardedHost string
+	proto         string
+	// conditional is set when the request carries a precondition, which
+	// a stale listing must not answer
+	conditional bool
+	// ids encodes the location IDs cursors carry
+	ids *dto.ExternalIDs
+}
+
+// conditionalHeaders are the preconditions of RFC 9110
+var conditionalHeaders = []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range"}
+
+// Resolve captures the request URL and, from a trusted proxy, the
+// forwarding headers needed to build Link headers, whether the request is
+// conditional, and how its cursors encode IDs
+func (r *ListLocationsRequest) Resolve(ctx huma.Context) []error {
+	r.requestURL = ctx.URL()
+	r.ids = dto.ExternalIDsFromContext(ctx.Context())
+	r.basePath = basePathFromContext(ctx.Context())
+	r.hasRefLat = ctx.Query("ref_lat") != ""
+	r.hasRefLng = ctx.Query("ref_lng") != ""
+	r.host = ctx.Host()
+	// Anyone can send forwarding headers; only a trusted proxy's are used
+	if middleware.FromTrustedPeer(ctx.Context()) {
+		r.forwardedHost = firstHeaderValue(ctx.Header("X-Forwarded-Host"))
+		r.proto = firstHeaderValue(ctx.Header("X-Forwarded-Proto"))
+	}
+	if r.proto == "" {
+		r.proto = "http"
+		if ctx.TLS() != nil {
+			r.proto = "https"
+		}
+	}
+	for _, header := range conditionalHeaders {
+		if ctx.Header(header) != "" {
+			r.conditional = true
+		}
+	}
+	return nil
+}
+
+func (r *ListLocationsRequest) cursorMode() bool {
+	return r.Cursor != "" || r.Limit > 0
+}
+
+func (r *ListLocationsRequest) offsetMode() bool {
+	return r.Page > 0 || r.PageSize > 0
+}
+
+func (r *ListLocationsRequest) hasReference() bool {
+	return r.hasRefLat && r.hasRefLng
+}
+
+// linkBuilder builds absolute RFC 8288 link targets from the current request
+type linkBuilder struct {
+	base  url.URL
+	query url.Values
+	ids   *dto.ExternalIDs
+}
+
+func newLinkBuilder(input *ListLocationsRequest, externalBaseURL string) linkBuilder {
+	base := url.URL{
+		Scheme: input.proto,
+		Host:   input.host,
+		Path:   input.basePath + input.requestURL.Path,
+	}
+	if input.forwardedHost != "" {
+		base.Host = input.forwardedHost
+	}
+
+	if externalBaseURL != "" {
+		if ext, err := url.Parse(externalBaseURL); err == nil {
+			base.Scheme = ext.Scheme
+			base.Host = ext.Host
+			base.Path = strings.TrimRight(ext.Path, "/") + input.basePath + input.requestURL.Path
+		}
+	}
+
+	return linkBuilder{base: base, query: input.requestURL.Query(), ids: input.ids}
+}
+
+// link returns a single link-value with the given parameters substituted
+func (b linkBuilder) link(rel string, params map[string]string) string {
+	query := url.Values{}
+	for k, v := range b.query {
+		query[k] = append([]string(nil), v...)
+	}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	u := b.base
+	u.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=\"%s\"", u.String(), rel)
+}
+
+// window sets the part of opts the pagination parameters select. It
+// returns the page size, and the page number in offset mode.
+func (r *ListLocationsRequest) window(opts *domain.ListOptions, defaultSize int) (int, int, error) {
+	switch {
+	case r.cursorMode():
+		size := r.Limit
+		if size < 1 {
+			size = defaultSize
+		}
+		if r.Cursor != "" {
+			afterID, err := decodeCursor(r.Cursor, r.ids)
+			if err != nil {
+				return 0, 0, err
+			}
+			opts.Filter.AfterID = afterID
+		}
+		opts.Limit = size
+		return 0, size, nil
+	case r.offsetMode():
+		page := max(r.Page, 1)
+		size := r.PageSize
+		if size < 1 {
+			size = defaultSize
+		}
+		opts.Offset = (page - 1) * size
+		opts.Limit = size
+		return page, size, nil
+	}
+	return 0, 0, nil
+}
+
+// offsetLinks returns the Link header value for one page of a listing of
+// total locations
+func offsetLinks(total, page, size int, b linkBuilder) string {
+	last := max((total+size-1)/size, 1)
+
+	pageParams := func(p int) map[string]string {
+		return map[string]string{
+			"page":      strconv.Itoa(p),
+			"page_size": strconv.Itoa(size),
+		}
+	}
+
+	links := []string{b.link("first", pageParams(1))}
+	if page > 1 {
+		links = append(links, b.link("prev", pageParams(min(page-1, last))))
+	}
+	if page < last {
+		links = append(links, b.link("next", pageParams(page+1)))
+	}
+	links = append(links, b.link("last", pageParams(last)))
+	return strings.Join(links, ", ")
+}
+
+// nextCursor returns the cursor after page and its Link header value, or
+// empty strings when no locations remain
+func nextCursor(page domain.ListPage[*domain.Location], size int, b linkBuilder) (string, string) {
+	if !page.More || len(page.Items) == 0 {
+		return "", ""
+	}
+	next := encodeCursor(page.Items[len(page.Items)-1].ID, b.ids)
+	link := b.link("next", map[string]string{
+		"cursor": next,
+		"limit":  strconv.Itoa(size),
+	})
+	return next, link
+}
+
+// encodeCursor returns the cursor resuming after id: its external ID when
+// ids encodes them, so a cursor reveals no more than the listing did
+func encodeCursor(id string, ids *dto.ExternalIDs) string {
+	if ids != nil {
+		return ids.Encode(id)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// decodeCursor returns the location ID a cursor resumes after
+func decodeCursor(cursor string, ids *dto.ExternalIDs) (string, error) {
+	if ids != nil {
+		id, ok := ids.Decode(cursor)
+		if !ok {
+			return "", fmt.Errorf("invalid cursor")
+		}
+		return id, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) == 0 {
+		return "", fmt.Errorf("invalid cursor")
+	}
+	if _, err := strconv.ParseUint(string(raw), 10, 64); err != nil {
+		return "", fmt.Errorf("invalid cursor")
+	}
+	return string(raw), nil
+}
+
+func firstHeaderValue(value string) string {
+	if i := strings.Index(value, ","); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
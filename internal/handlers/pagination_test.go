@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func setupPaginatedAPI(t *testing.T, count int, opts ...LocationHandlerOption) humatest.TestAPI {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	return seedPaginatedAPI(t, api, count, opts...)
+}
+
+// setupProxiedAPI is setupPaginatedAPI with every request marked as coming
+// from a trusted proxy, or not, as the client IP middleware would
+func setupProxiedAPI(t *testing.T, count int, trusted bool) humatest.TestAPI {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithContext(ctx, middleware.WithTrustedPeer(ctx.Context(), trusted)))
+	})
+	return seedPaginatedAPI(t, api, count)
+}
+
+func seedPaginatedAPI(t *testing.T, api humatest.TestAPI, count int, opts ...LocationHandlerOption) humatest.TestAPI {
+	repo := memory.NewInMemoryLocationRepository()
+	locationService := service.NewLocationService(repo)
+	locationHandler := NewLocationHandler(locationService, opts...)
+	locationHandler.RegisterRoutes(api)
+
+	for i := 1; i <= count; i++ {
+		resp := api.Post("/locations", dto.LocationRequest{
+			Name:      fmt.Sprintf("Station %d", i),
+			Latitude:  ptr(float64(i)),
+			Longitude: ptr(float64(i)),
+		})
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("Failed to seed location %d: status %d", i, resp.Code)
+		}
+	}
+
+	return api
+}
+
+// paginationLinks returns the Link header values other than the describedBy
+// schema link Huma adds to every JSON response
+func paginationLinks(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		if !strings.Contains(value, `rel="describedBy"`) {
+			return value
+		}
+	}
+	return ""
+}
+
+func TestListLocationsLinkHeaders(t *testing.T) {
+	api := setupPaginatedAPI(t, 5)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		count    int
+	}{
+		{
+			name: "first page",
+			path: "/locations?page=1&page_size=2",
+			expected: `<http://api.test/locations?page=1&page_size=2>; rel="first", ` +
+				`<http://api.test/locations?page=2&page_size=2>; rel="next", ` +
+				`<http://api.test/locations?page=3&page_size=2>; rel="last"`,
+			count: 2,
+		},
+		{
+			name: "middle page",
+			path: "/locations?page=2&page_size=2",
+			expected: `<http://api.test/locations?page=1&page_size=2>; rel="first", ` +
+				`<http://api.test/locations?page=1&page_size=2>; rel="prev", ` +
+				`<http://api.test/locations?page=3&page_size=2>; rel="next", ` +
+				`<http://api.test/locations?page=3&page_size=2>; rel="last"`,
+			count: 2,
+		},
+		{
+			name: "last page",
+			path: "/locations?page=3&page_size=2",
+			expected: `<http://api.test/locations?page=1&page_size=2>; rel="first", ` +
+				`<http://api.test/locations?page=2&page_size=2>; rel="prev", ` +
+				`<http://api.test/locations?page=3&page_size=2>; rel="last"`,
+			count: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := api.Get(tt.path, "Host: api.test")
+			if resp.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+			}
+
+			if link := paginationLinks(resp.Header()); link != tt.expected {
+				t.Errorf("Expected Link header\n%s\ngot\n%s", tt.expected, link)
+			}
+
+			var body dto.LocationListResponse
+			if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if body.Count != tt.count {
+				t.Errorf("Expected %d locations, got %d", tt.count, body.Count)
+			}
+			if body.Total != 5 {
+				t.Errorf("Expected total 5, got %d", body.Total)
+			}
+		})
+	}
+}
+
+func TestListLocationsLinkHeadersBehindProxy(t *testing.T) {
+	forwarded := []any{
+		"Host: 10.0.0.5",
+		"X-Forwarded-Proto: https",
+		"X-Forwarded-Host: geo.example.com",
+	}
+
+	resp := setupProxiedAPI(t, 3, true).Get("/locations?page=1&page_size=2", forwarded...)
+	expected := `<https://geo.example.com/locations?page=1&page_size=2>; rel="first", ` +
+		`<https://geo.example.com/locations?page=2&page_size=2>; rel="next", ` +
+		`<https://geo.example.com/locations?page=2&page_size=2>; rel="last"`
+	if link := paginationLinks(resp.Header()); link != expected {
+		t.Errorf("Expected Link header\n%s\ngot\n%s", expected, link)
+	}
+
+	// The same headers from any other peer are ignored
+	resp = setupProxiedAPI(t, 3, false).Get("/locations?page=1&page_size=2", forwarded...)
+	expected = `<http://10.0.0.5/locations?page=1&page_size=2>; rel="first", ` +
+		`<http://10.0.0.5/locations?page=2&page_size=2>; rel="next", ` +
+		`<http://10.0.0.5/locations?page=2&page_size=2>; rel="last"`
+	if link := paginationLinks(resp.Header()); link != expected {
+		t.Errorf("Expected Link header\n%s\ngot\n%s", expected, link)
+	}
+}
+
+func TestListLocationsLinkHeadersExternalBaseURL(t *testing.T) {
+	api := setupPaginatedAPI(t, 3, WithExternalBaseURL("https://public.example.com/geo/"))
+
+	resp := api.Get("/locations?page=2&page_size=2", "Host: internal:8080", "X-Forwarded-Proto: http")
+
+	expected := `<https://public.example.com/geo/locations?page=1&page_size=2>; rel="first", ` +
+		`<https://public.example.com/geo/locations?page=1&page_size=2>; rel="prev", ` +
+		`<https://public.example.com/geo/locations?page=2&page_size=2>; rel="last"`
+	if link := paginationLinks(resp.Header()); link != expected {
+		t.Errorf("Expected Link header\n%s\ngot\n%s", expected, link)
+	}
+}
+
+func TestListLocationsCursorPagination(t *testing.T) {
+	api := setupPaginatedAPI(t, 3)
+
+	resp := api.Get("/locations?limit=2", "Host: api.test")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var body dto.LocationListResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if body.Count != 2 || body.NextCursor == "" {
+		t.Fatalf("Expected 2 locations and a next cursor, got %d and %q", body.Count, body.NextCursor)
+	}
+
+	expected := fmt.Sprintf(`<http://api.test/locations?cursor=%s&limit=2>; rel="next"`, body.NextCursor)
+	if link := paginationLinks(resp.Header()); link != expected {
+		t.Errorf("Expected Link header %s, got %s", expected, link)
+	}
+
+	resp = api.Get("/locations?limit=2&cursor="+body.NextCursor, "Host: api.test")
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if body.Count != 1 || body.Locations[0].Name != "Station 3" {
+		t.Errorf("Expected only Station 3 on the final page, got %+v", body.Locations)
+	}
+	if link := paginationLinks(resp.Header()); link != "" {
+		t.Errorf("Expected no Link header on the final page, got %s", link)
+	}
+
+	// A cursor must decode to a location ID
+	for _, cursor := range []string{"not*base64", base64.RawURLEncoding.EncodeToString([]byte("Station 2"))} {
+		resp = api.Get("/locations?limit=2&cursor=" + cursor)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for cursor %q, got %d", http.StatusBadRequest, cursor, resp.Code)
+		} else if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "INVALID_CURSOR" {
+			t.Errorf("Expected INVALID_CURSOR for cursor %q, got %+v", cursor, body)
+		}
+	}
+}
+
+func TestListLocationsUnpaginatedHasNoLinkHeader(t *testing.T) {
+	api := setupPaginatedAPI(t, 3)
+
+	resp := api.Get("/locations")
+	if link := paginationLinks(resp.Header()); link != "" {
+		t.Errorf("Expected no Link header, got %s", link)
+	}
+}
+
+func TestListLocationsReferencePointDistances(t *testing.T) {
+	api := setupPaginatedAPI(t, 3)
+
+	// The reference point sits on Station 3, yet the list keeps its order
+	for _, query := range []string{"ref_lat=3&ref_lng=3", "ref_lat=3&ref_lng=3&page_size=3", "ref_lat=3&ref_lng=3&limit=3"} {
+		resp := api.Get("/locations?" + query)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", query, http.StatusOK, resp.Code)
+		}
+		var body dto.LocationListResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if len(body.Locations) != 3 {
+			t.Fatalf("%s: expected 3 locations, got %d", query, len(body.Locations))
+		}
+		for i, location := range body.Locations {
+			if location.Name != fmt.Sprintf("Station %d", i+1) {
+				t.Errorf("%s: expected Station %d at position %d, got %s", query, i+1, i, location.Name)
+			}
+			if location.Distance == nil {
+				t.Fatalf("%s: expected distance_km on %s", query, location.Name)
+			}
+			expected := geospatial.HaversineDistance(
+				geospatial.Coordinate{Latitude: 3, Longitude: 3},
+				geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude},
+			).Kilometers()
+			if got := location.Distance.Kilometers(); math.Abs(got-expected) > 1e-9 {
+				t.Errorf("%s: expected %s at %f km, got %f", query, location.Name, expected, got)
+			}
+		}
+	}
+
+	// A reference point at 0,0 is still a reference point
+	resp := api.Get("/locations?ref_lat=0&ref_lng=0")
+	var body dto.LocationListResponse
+	json.Unmarshal(resp.Body.Bytes(), &body)
+	if len(body.Locations) == 0 || body.Locations[0].Distance == nil || *body.Locations[0].Distance == 0 {
+		t.Errorf("Expected distances from 0,0, got %+v", body.Locations)
+	}
+
+	resp = api.Get("/locations")
+	if strings.Contains(resp.Body.String(), "distance_km") {
+		t.Errorf("Expected no distances without a reference point, got %s", resp.Body.String())
+	}
+}
+
+func TestListLocationsReferencePointRequiresBothCoordinates(t *testing.T) {
+	api := setupPaginatedAPI(t, 1)
+
+	for _, query := range []string{"ref_lat=3", "ref_lng=3"} {
+		resp := api.Get("/locations?" + query)
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusUnprocessableEntity, resp.Code)
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "REFERENCE_POINT_INCOMPLETE" {
+			t.Errorf("%s: expected REFERENCE_POINT_INCOMPLETE, got %+v", query, body)
+		}
+	}
+}
+
+func TestListLocationsCreatedRange(t *testing.T) {
+	base := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	now := base
+	locationService := service.NewLocationService(memory.NewInMemoryLocationRepository(), service.WithClock(func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}))
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(locationService).RegisterRoutes(api)
+
+	// Station i is created at base + i hours
+	for i := 1; i <= 5; i++ {
+		api.Post("/locations", dto.LocationRequest{Name: fmt.Sprintf("Station %d", i), Latitude: ptr(float64(i)), Longitude: ptr(float64(i))})
+	}
+	at := func(hours int) string { return base.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339) }
+
+	resp := api.Get("/locations?created_after="+at(2)+"&created_before="+at(4)+"&limit=2", "Host: api.test")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var body dto.LocationListResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if body.Count != 2 || body.Locations[0].Name != "Station 2" || body.Locations[1].Name != "Station 3" {
+		t.Fatalf("Expected Stations 2 and 3 on the first page, got %+v", body.Locations)
+	}
+	link := paginationLinks(resp.Header())
+	if !strings.Contains(link, "created_after=") || !strings.Contains(link, "created_before=") {
+		t.Errorf("Expected the next link to keep the filter, got %s", link)
+	}
+
+	resp = api.Get("/locations?created_after=" + at(2) + "&created_before=" + at(4) + "&limit=2&cursor=" + body.NextCursor)
+	body = dto.LocationListResponse{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if body.Count != 1 || body.Locations[0].Name != "Station 4" || body.NextCursor != "" {
+		t.Errorf("Expected only Station 4, created on the upper bound, on the final page, got %+v", body)
+	}
+
+	for _, query := range []string{
+		"created_after=" + at(4) + "&created_before=" + at(2),
+		"created_after=" + at(3) + "&created_before=" + at(3),
+	} {
+		resp := api.Get("/locations?" + query)
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusUnprocessableEntity, resp.Code)
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "INVALID_CREATED_RANGE" {
+			t.Errorf("%s: expected INVALID_CREATED_RANGE, got %+v", query, body)
+		}
+	}
+}
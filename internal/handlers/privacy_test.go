@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func setupPrivacyAPI(t *testing.T, privacy dto.CoordinatePrivacy) humatest.TestAPI {
+	t.Helper()
+	humaConfig := huma.DefaultConfig("Test API", "1.0.0")
+	humaConfig.Transformers = append(humaConfig.Transformers, middleware.ObscureCoordinates)
+	// The standard mux prefers /locations/changes to /locations/{name}
+	api := humatest.Wrap(t, humago.New(http.NewServeMux(), humaConfig))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "public", Key: "public-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeExact}},
+		{Name: "ops", Key: "ops-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin}},
+	})))
+	api.UseMiddleware(middleware.CoordinatePrivacy(privacy))
+	repo := memory.NewInMemoryLocationRepository()
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+	NewChangeHandler(repo, config.DefaultLimits()).RegisterRoutes(api)
+	NewDuplicateHandler(service.NewDuplicateService(repo, repo, nil)).RegisterRoutes(api)
+	return api
+}
+
+func TestCoordinatePrivacy(t *testing.T) {
+	t.Parallel()
+	ikeja := geospatial.Coordinate{Latitude: 6.6018, Longitude: 3.3515}
+	query := geospatial.Coordinate{Latitude: 6.45, Longitude: 3.47}
+	nearestPath := "/nearest?lat=6.45&lng=3.47"
+
+	nearest := func(api humatest.TestAPI, key string) dto.NearestLocationResponse {
+		t.Helper()
+		resp := api.Get(nearestPath, "X-API-Key: "+key)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var body dto.NearestLocationResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return body
+	}
+	shown := func(l dto.LocationResponse) geospatial.Coordinate {
+		return geospatial.Coordinate{Latitude: l.Latitude, Longitude: l.Longitude}
+	}
+	trueDistance := geospatial.HaversineDistance(query, ikeja).Kilometers()
+
+	t.Run("grid", func(t *testing.T) {
+		t.Parallel()
+		api := setupPrivacyAPI(t, dto.CoordinatePrivacy{Mode: dto.PrivacyGrid, Meters: 500})
+		api.Post("/locations", "X-API-Key: public-key", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(ikeja.Latitude), Longitude: ptr(ikeja.Longitude)})
+
+		want := geospatial.RoundCoordinates(geospatial.SnapToGrid(ikeja, 500), geospatial.DefaultCoordinatePrecision)
+		first := nearest(api, "public-key")
+		if got := shown(first.Location); got != want || got == ikeja {
+			t.Errorf("Expected the grid cell centre %+v, got %+v", want, got)
+		}
+		if again := nearest(api, "public-key"); shown(again.Location) != shown(first.Location) {
+			t.Errorf("Expected the same snapped position on every request, got %+v and %+v", first.Location, again.Location)
+		}
+		// Distances are measured from the true position, not the one shown
+		if math.Abs(first.Distance.Kilometers()-trueDistance) > 1e-9 {
+			t.Errorf("Expected the true distance %v km, got %v", trueDistance, first.Distance.Kilometers())
+		}
+
+		resp := api.Get("/locations?ref_lat=6.45&ref_lng=3.47", "X-API-Key: public-key")
+		var list dto.LocationListResponse
+		json.Unmarshal(resp.Body.Bytes(), &list)
+		if got := shown(list.Locations[0]); got != want {
+			t.Errorf("Expected the listing snapped to %+v, got %+v", want, got)
+		}
+		if d := list.Locations[0].Distance; d == nil || math.Abs(d.Kilometers()-trueDistance) > 1e-6 {
+			t.Errorf("Expected the listing's distance from the true position, got %v", d)
+		}
+
+		if got := shown(nearest(api, "partner-key").Location); got != ikeja {
+			t.Errorf("Expected the exact scope to see %+v, got %+v", ikeja, got)
+		}
+
+		// Entity tags follow the representation each caller sees
+		at := "/locations/at?lat=6.6018&lng=3.3515"
+		public, again, exact := api.Get(at, "X-API-Key: public-key"), api.Get(at, "X-API-Key: public-key"), api.Get(at, "X-API-Key: partner-key")
+		if tag := public.Header().Get("ETag"); tag == "" || tag != again.Header().Get("ETag") || tag == exact.Header().Get("ETag") {
+			t.Errorf("Expected a stable obscured ETag distinct from the exact one, got %q, %q and %q",
+				tag, again.Header().Get("ETag"), exact.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		t.Parallel()
+		api := setupPrivacyAPI(t, dto.CoordinatePrivacy{Mode: dto.PrivacyJitter, Meters: 500, Secret: []byte("test-secret")})
+		api.Post("/locations", "X-API-Key: public-key", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(ikeja.Latitude), Longitude: ptr(ikeja.Longitude)})
+
+		first := nearest(api, "public-key")
+		got := shown(first.Location)
+		if got == ikeja || geospatial.HaversineDistance(ikeja, got).Meters() > 501 {
+			t.Errorf("Expected a position within 500 m of the true one, got %+v", got)
+		}
+		if again := nearest(api, "public-key"); shown(again.Location) != got {
+			t.Errorf("Expected the same offset on every request, got %+v and %+v", got, again.Location)
+		}
+		if math.Abs(first.Distance.Kilometers()-trueDistance) > 1e-9 {
+			t.Errorf("Expected the true distance %v km, got %v", trueDistance, first.Distance.Kilometers())
+		}
+		if got := shown(nearest(api, "partner-key").Location); got != ikeja {
+			t.Errorf("Expected the exact scope to see %+v, got %+v", ikeja, got)
+		}
+	})
+}
+
+func TestCoordinatePrivacyCoversEveryLocation(t *testing.T) {
+	t.Parallel()
+	ikeja := geospatial.Coordinate{Latitude: 6.6018, Longitude: 3.3515}
+	annex := geospatial.Coordinate{Latitude: 6.60181, Longitude: 3.3515}
+	snapped := geospatial.RoundCoordinates(geospatial.SnapToGrid(ikeja, 500), geospatial.DefaultCoordinatePrecision)
+
+	setup := func(t *testing.T) humatest.TestAPI {
+		t.Helper()
+		api := setupPrivacyAPI(t, dto.CoordinatePrivacy{Mode: dto.PrivacyGrid, Meters: 500})
+		for name, c := range map[string]geospatial.Coordinate{"Ikeja": ikeja, "Ikeja Annex": annex} {
+			if resp := api.Post("/locations", "X-API-Key: public-key", dto.LocationRequest{Name: name, Latitude: ptr(c.Latitude), Longitude: ptr(c.Longitude)}); resp.Code != http.StatusCreated {
+				t.Fatalf("Failed to create %s: %s", name, resp.Body.String())
+			}
+		}
+		return api
+	}
+	check := func(t *testing.T, what string, locations []dto.LocationResponse) {
+		t.Helper()
+		if len(locations) == 0 {
+			t.Fatalf("Expected %s to list locations", what)
+		}
+		for _, l := range locations {
+			if got := (geospatial.Coordinate{Latitude: l.Latitude, Longitude: l.Longitude}); got != snapped {
+				t.Errorf("Expected %s to show %s at %+v, got %+v", what, l.Name, snapped, got)
+			}
+		}
+	}
+
+	t.Run("change feed", func(t *testing.T) {
+		t.Parallel()
+		api := setup(t)
+		var feed dto.ChangeFeedResponse
+		json.Unmarshal(api.Get("/locations/changes", "X-API-Key: public-key").Body.Bytes(), &feed)
+		var locations []dto.LocationResponse
+		for _, change := range feed.Changes {
+			locations = append(locations, change.Location)
+		}
+		check(t, "the change feed", locations)
+	})
+
+	t.Run("duplicate report", func(t *testing.T) {
+		t.Parallel()
+		api := setup(t)
+		var report dto.DuplicateReportResponse
+		json.Unmarshal(api.Get("/locations/duplicates?radius_m=50&name_similarity=0.5", "X-API-Key: ops-key").Body.Bytes(), &report)
+		if len(report.Clusters) != 1 {
+			t.Fatalf("Expected one cluster, got %+v", report)
+		}
+		check(t, "the duplicate report", report.Clusters[0].Locations)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		t.Parallel()
+		api := setup(t)
+		var merged dto.MergeResponse
+		json.Unmarshal(api.Post("/locations/merge", "X-API-Key: ops-key", dto.MergeRequest{Winner: "Ikeja", Losers: []string{"Ikeja Annex"}}).Body.Bytes(), &merged)
+		check(t, "the merge", merged.Losers)
+	})
+
+	t.Run("ambiguous location", func(t *testing.T) {
+		t.Parallel()
+		api := setup(t)
+		resp := api.Get("/locations/at?lat=6.6018&lng=3.3515&tolerance_m=10", "X-API-Key: public-key")
+		var conflict struct {
+			Errors []struct {
+				Value dto.LocationResponse `json:"value"`
+			} `json:"errors"`
+		}
+		json.Unmarshal(resp.Body.Bytes(), &conflict)
+		if resp.Code != http.StatusConflict || len(conflict.Errors) != 2 {
+			t.Fatalf("Expected two candidates, got %d %s", resp.Code, resp.Body.String())
+		}
+		check(t, "the candidates", []dto.LocationResponse{conflict.Errors[0].Value, conflict.Errors[1].Value})
+		if strings.Contains(resp.Body.String(), "3.3515,") || strings.Contains(resp.Body.String(), "6.6018,") {
+			t.Errorf("Expected no exact coordinate in %s", resp.Body.String())
+		}
+	})
+}
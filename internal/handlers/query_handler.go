@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// SaveQueryInput represents a new saved query
+type SaveQueryInput struct {
+	Body dto.SaveQueryRequest
+}
+
+// UpdateQueryInput represents a replacement filter for a saved query
+type UpdateQueryInput struct {
+	Name string `path:"name" maxLength:"100" example:"lagos-august" doc:"Name of the saved query"`
+	Body dto.UpdateQueryRequest
+}
+
+// QueryNameInput names a saved query
+type QueryNameInput struct {
+	Name string `path:"name" maxLength:"100" example:"lagos-august" doc:"Name of the saved query"`
+}
+
+// RunQueryRequest represents a saved query run with the location listing's
+// pagination parameters
+type RunQueryRequest struct {
+	Name     string `path:"name" maxLength:"100" example:"lagos-august" doc:"Name of the saved query"`
+	Page     int    `query:"page" minimum:"0" example:"1" doc:"1-based page number for offset pagination"`
+	PageSize int    `query:"page_size" minimum:"0" example:"20" doc:"Number of locations per page, up to the configured maximum (100 by default)"`
+	Cursor   string `query:"cursor" example:"NDI" doc:"Opaque cursor returned by a previous response"`
+	Limit    int    `query:"limit" minimum:"0" example:"20" doc:"Number of locations per page for cursor pagination, up to the configured maximum"`
+
+	list ListLocationsRequest
+}
+
+// Resolve captures the pagination and the request URL for Link headers the
+// way a location listing does
+func (r *RunQueryRequest) Resolve(ctx huma.Context) []error {
+	r.list = ListLocationsRequest{Page: r.Page, PageSize: r.PageSize, Cursor: r.Cursor, Limit: r.Limit}
+	return r.list.Resolve(ctx)
+}
+
+// SavedQueryResponse represents a saved query
+type SavedQueryResponse struct {
+	Body dto.SavedQueryResponse `json:"body"`
+}
+
+// SavedQueryListResponse represents every saved query
+type SavedQueryListResponse struct {
+	Body dto.SavedQueryListResponse `json:"body"`
+}
+
+// QueryHandler manages saved location queries and runs them
+type QueryHandler struct {
+	queries         domain.QueryRepository
+	service         domain.LocationService
+	limits          config.LimitsConfig
+	externalBaseURL string
+}
+
+// NewQueryHandler creates a new saved query handler; zero limits mean the
+// defaults
+func NewQueryHandler(queries domain.QueryRepository, service domain.LocationService, limits config.LimitsConfig, externalBaseURL string) *QueryHandler {
+	if limits == (config.LimitsConfig{}) {
+		limits = config.DefaultLimits()
+	}
+	return &QueryHandler{queries: queries, service: service, limits: limits, externalBaseURL: externalBaseURL}
+}
+
+// RegisterRoutes registers the saved query routes with the Huma API
+func (h *QueryHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-saved-query",
+		Method:        http.MethodPost,
+		Path:          "/queries",
+		Summary:       "Save Query",
+		Description:   "Store a location filter under a name so it can be run again with `GET /queries/{name}/run`. The filter is validated as a location listing's would be.",
+		Tags:          []string{"Queries"},
+		DefaultStatus: http.StatusCreated,
+		Errors:        []int{http.StatusConflict, http.StatusUnprocessableEntity},
+	}, h.CreateQuery)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-saved-queries",
+		Method:      http.MethodGet,
+		Path:        "/queries",
+		Summary:     "List Saved Queries",
+		Description: "List every saved query by name",
+		Tags:        []string{"Queries"},
+	}, h.ListQueries)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-saved-query",
+		Method:      http.MethodGet,
+		Path:        "/queries/{name}",
+		Summary:     "Get Saved Query",
+		Description: "Get a saved query's filter",
+		Tags:        []string{"Queries"},
+		Errors:      []int{http.StatusNotFound},
+	}, h.GetQuery)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-saved-query",
+		Method:      http.MethodPut,
+		Path:        "/queries/{name}",
+		Summary:     "Update Saved Query",
+		Description: "Replace a saved query's filter",
+		Tags:        []string{"Queries"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, h.UpdateQuery)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "delete-saved-query",
+		Method:        http.MethodDelete,
+		Path:          "/queries/{name}",
+		Summary:       "Delete Saved Query",
+		Description:   "Delete a saved query",
+		Tags:          []string{"Queries"},
+		DefaultStatus: http.StatusNoContent,
+		Errors:        []int{http.StatusNotFound},
+	}, h.DeleteQuery)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "run-saved-query",
+		Method:      http.MethodGet,
+		Path:        "/queries/{name}/run",
+		Summary:     "Run Saved Query",
+		Description: "List the locations matching a saved query's filter, paginated like `GET /locations`. " +
+			"A saved filter the current filter schema no longer accepts answers 422 `SAVED_QUERY_INVALID`; update the query to fix it.",
+		Tags:   []string{"Queries"},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, h.RunQuery)
+}
+
+// CreateQuery handles POST /queries requests
+func (h *QueryHandler) CreateQuery(ctx context.Context, input *SaveQueryInput) (*SavedQueryResponse, error) {
+	filter, err := encodeQueryFilter(ctx, input.Body.Filter)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	query := &domain.SavedQuery{Name: input.Body.Name, Filter: filter, CreatedAt: now, UpdatedAt: now}
+	if err := h.queries.CreateQuery(query); err != nil {
+		if errors.Is(err, domain.ErrQueryExists) {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusConflict, "QUERY_EXISTS", "A saved query named "+query.Name+" already exists").With("name", query.Name))
+		}
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to save query"))
+	}
+	return &SavedQueryResponse{Body: dto.FromSavedQuery(query)}, nil
+}
+
+// ListQueries handles GET /queries requests
+func (h *QueryHandler) ListQueries(ctx context.Context, input *struct{}) (*SavedQueryListResponse, error) {
+	queries, err := h.queries.ListQueries()
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to retrieve saved queries"))
+	}
+	return &SavedQueryListResponse{Body: dto.FromSavedQueries(queries)}, nil
+}
+
+// GetQuery handles GET /queries/{name} requests
+func (h *QueryHandler) GetQuery(ctx context.Context, input *QueryNameInput) (*SavedQueryResponse, error) {
+	query, err := h.findQuery(ctx, input.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &SavedQueryResponse{Body: dto.FromSavedQuery(query)}, nil
+}
+
+// UpdateQuery handles PUT /queries/{name} requests
+func (h *QueryHandler) UpdateQuery(ctx context.Context, input *UpdateQueryInput) (*SavedQueryResponse, error) {
+	filter, err := encodeQueryFilter(ctx, input.Body.Filter)
+	if err != nil {
+		return nil, err
+	}
+	query := &domain.SavedQuery{Name: input.Name, Filter: filter, UpdatedAt: time.Now().UTC()}
+	if err := h.queries.UpdateQuery(query); err != nil {
+		if errors.Is(err, domain.ErrQueryNotFound) {
+			return nil, queryNotFound(ctx, input.Name)
+		}
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to save query"))
+	}
+	return &SavedQueryResponse{Body: dto.FromSavedQuery(query)}, nil
+}
+
+// DeleteQuery handles DELETE /queries/{name} requests
+func (h *QueryHandler) DeleteQuery(ctx context.Context, input *QueryNameInput) (*struct{}, error) {
+	if err := h.queries.DeleteQuery(input.Name); err != nil {
+		if errors.Is(err, domain.ErrQueryNotFound) {
+			return nil, queryNotFound(ctx, input.Name)
+		}
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to delete saved query"))
+	}
+	return &struct{}{}, nil
+}
+
+// RunQuery handles GET /queries/{name}/run requests
+func (h *QueryHandler) RunQuery(ctx context.Context, input *RunQueryRequest) (*LocationListResponse, error) {
+	list := &input.list
+	if list.cursorMode() && list.offsetMode() {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadRequest, "PAGINATION_CONFLICT", "Cursor and page pagination cannot be combined"))
+	}
+	if err := checkLimit(ctx, "page_size", list.PageSize, h.limits.MaxPageSize); err != nil {
+		return nil, err
+	}
+	if err := checkLimit(ctx, "limit", list.Limit, h.limits.MaxPageSize); err != nil {
+		return nil, err
+	}
+
+	query, err := h.findQuery(ctx, input.Name)
+	if err != nil {
+		return nil, err
+	}
+	// The stored document is decoded strictly, so a field the filter no
+	// longer has fails the run instead of being dropped
+	var doc dto.QueryFilter
+	decoder := json.NewDecoder(bytes.NewReader(query.Filter))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "SAVED_QUERY_INVALID",
+			"Saved query "+query.Name+" no longer matches the filter schema: "+err.Error()).With("name", query.Name).With("reason", err.Error()))
+	}
+	filter, open, err := queryFilter(doc)
+	if err != nil {
+		return nil, filterError(ctx, err)
+	}
+
+	opts := domain.ListOptions{Filter: filter, Open: open}
+	number, size, err := list.window(&opts, h.limits.DefaultPageSize)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadRequest, "INVALID_CURSOR", "Invalid cursor"))
+	}
+	page, err := h.service.ListLocations(ctx, opts)
+	if mapped := filterError(ctx, err); mapped != nil {
+		return nil, mapped
+	}
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to retrieve locations"))
+	}
+
+	links := newLinkBuilder(list, h.externalBaseURL)
+	body := dto.FromDomainList(page.Items)
+	switch {
+	case list.cursorMode():
+		next, link := nextCursor(page, size, links)
+		body.NextCursor = next
+		return &LocationListResponse{Link: link, Body: body}, nil
+	case list.offsetMode():
+		body.Total = page.Total
+		body.Page = number
+		body.PageSize = size
+		return &LocationListResponse{Link: offsetLinks(page.Total, number, size, links), Body: body}, nil
+	}
+	return &LocationListResponse{Body: body}, nil
+}
+
+func (h *QueryHandler) findQuery(ctx context.Context, name string) (*domain.SavedQuery, error) {
+	query, err := h.queries.FindQuery(name)
+	if errors.Is(err, domain.ErrQueryNotFound) {
+		return nil, queryNotFound(ctx, name)
+	}
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to retrieve saved query"))
+	}
+	return query, nil
+}
+
+func queryNotFound(ctx context.Context, name string) error {
+	return apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "QUERY_NOT_FOUND", "Saved query "+name+" not found").With("name", name))
+}
+
+// encodeQueryFilter validates a filter as a listing's parameters would be
+// and encodes it for storage
+func encodeQueryFilter(ctx context.Context, doc dto.QueryFilter) (json.RawMessage, error) {
+	if _, _, err := queryFilter(doc); err != nil {
+		if mapped := filterError(ctx, err); mapped != nil {
+			return nil, mapped
+		}
+		return nil, apierrors.ToHuma(ctx, err)
+	}
+	filter, err := json.Marshal(doc)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to save query"))
+	}
+	return filter, nil
+}
+
+// queryFilter reads a saved filter through the listing's filter parameters,
+// so both validate alike
+func queryFilter(doc dto.QueryFilter) (domain.LocationFilter, domain.OpenAt, error) {
+	params := LocationFilterParams{
+		NameContains:       doc.NameContains,
+		SearchDescriptions: doc.SearchDescriptions,
+		BBox:               doc.BBox,
+		Region:             doc.Region,
+	}
+	if doc.CreatedAfter != nil {
+		params.CreatedAfter = *doc.CreatedAfter
+	}
+	if doc.CreatedBefore != nil {
+		params.CreatedBefore = *doc.CreatedBefore
+	}
+	filter, err := params.filter()
+	if err != nil {
+		return domain.LocationFilter{}, domain.OpenAt{}, err
+	}
+
+	openParams := OpenFilterParams{OpenNow: doc.OpenNow}
+	if doc.OpenAt != nil {
+		openParams.OpenAt = *doc.OpenAt
+	}
+	open, err := openParams.open()
+	if err != nil {
+		return domain.LocationFilter{}, domain.OpenAt{}, err
+	}
+	return filter, open, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func setupQueryAPI(t *testing.T) (humatest.TestAPI, *memory.InMemoryQueryRepository) {
+	t.Helper()
+	queries := memory.NewInMemoryQueryRepository()
+	locationService := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(locationService).RegisterRoutes(api)
+	NewQueryHandler(queries, locationService, config.DefaultLimits(), "").RegisterRoutes(api)
+
+	for _, name := range []string{"Leeta Lekki", "Leeta Lekki Phase 1", "Leeta Lekki Phase 2", "Leeta Ikeja"} {
+		api.Post("/locations", dto.LocationRequest{Name: name, Latitude: ptr(6.45), Longitude: ptr(3.47)})
+	}
+	return api, queries
+}
+
+func runQueryNames(t *testing.T, resp *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var list dto.LocationListResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	var names []string
+	for _, location := range list.Locations {
+		names = append(names, location.Name)
+	}
+	return names
+}
+
+func TestSavedQueryLifecycle(t *testing.T) {
+	api, _ := setupQueryAPI(t)
+
+	resp := api.Post("/queries", map[string]any{"name": "lekki", "filter": map[string]any{"name_contains": "lekki"}})
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	resp = api.Post("/queries", map[string]any{"name": "lekki", "filter": map[string]any{}})
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusConflict || body.Code != "QUERY_EXISTS" {
+		t.Errorf("Expected 409 QUERY_EXISTS, got %d %+v", resp.Code, body)
+	}
+
+	// Runs page like the listing, carrying the query's path in links
+	resp = api.Get("/queries/lekki/run?page=1&page_size=2")
+	if names := runQueryNames(t, resp); strings.Join(names, ",") != "Leeta Lekki,Leeta Lekki Phase 1" {
+		t.Errorf("Expected the first page of Lekki locations, got %v", names)
+	}
+	if link := resp.Header().Get("Link"); !strings.Contains(link, "/queries/lekki/run?page=2&page_size=2") {
+		t.Errorf("Expected a next link to page 2 of the run, got %q", link)
+	}
+	resp = api.Get("/queries/lekki/run?limit=2")
+	var list dto.LocationListResponse
+	json.Unmarshal(resp.Body.Bytes(), &list)
+	resp = api.Get("/queries/lekki/run?limit=2&cursor=" + list.NextCursor)
+	if names := runQueryNames(t, resp); strings.Join(names, ",") != "Leeta Lekki Phase 2" {
+		t.Errorf("Expected the cursor page after the first two, got %v", names)
+	}
+
+	resp = api.Put("/queries/lekki", map[string]any{"filter": map[string]any{"name_contains": "ikeja"}})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/queries/lekki/run")
+	if names := runQueryNames(t, resp); strings.Join(names, ",") != "Leeta Ikeja" {
+		t.Errorf("Expected the updated filter to run, got %v", names)
+	}
+
+	resp = api.Get("/queries")
+	var queries dto.SavedQueryListResponse
+	json.Unmarshal(resp.Body.Bytes(), &queries)
+	if len(queries.Queries) != 1 || queries.Queries[0].Filter["name_contains"] != "ikeja" {
+		t.Errorf("Expected the updated query listed, got %+v", queries)
+	}
+
+	if resp := api.Delete("/queries/lekki"); resp.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, resp.Code)
+	}
+	for _, resp := range []*httptest.ResponseRecorder{api.Get("/queries/lekki/run"), api.Delete("/queries/lekki"),
+		api.Put("/queries/lekki", map[string]any{"filter": map[string]any{}})} {
+		if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusNotFound || body.Code != "QUERY_NOT_FOUND" {
+			t.Errorf("Expected 404 QUERY_NOT_FOUND after delete, got %d %+v", resp.Code, body)
+		}
+	}
+}
+
+func TestSavedQueryValidatedOnSave(t *testing.T) {
+	api, _ := setupQueryAPI(t)
+
+	tests := []struct {
+		name   string
+		filter map[string]any
+		code   string
+	}{
+		{"renamed field", map[string]any{"name_like": "lekki"}, "VALIDATION_ERROR"},
+		{"bad bbox", map[string]any{"bbox": "3.3,6.4"}, "INVALID_BBOX"},
+		{"open conflict", map[string]any{"open_now": true, "open_at": "2025-08-18T21:30:00Z"}, "OPEN_FILTER_CONFLICT"},
+		{"created range", map[string]any{"created_after": "2025-09-01T00:00:00Z", "created_before": "2025-08-01T00:00:00Z"}, "INVALID_CREATED_RANGE"},
+	}
+	for _, tt := range tests {
+		resp := api.Post("/queries", map[string]any{"name": "q", "filter": tt.filter})
+		if resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+			continue
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != tt.code {
+			t.Errorf("%s: expected %s, got %+v", tt.name, tt.code, body)
+		}
+	}
+	if resp := api.Get("/queries/q"); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected no query saved from invalid filters, got %d", resp.Code)
+	}
+}
+
+func TestSavedQueryRemovedField(t *testing.T) {
+	api, queries := setupQueryAPI(t)
+
+	// A query saved before its status field was removed from the filter
+	queries.CreateQuery(&domain.SavedQuery{Name: "inactive", Filter: json.RawMessage(`{"region":"Lagos","status":"inactive"}`)})
+
+	resp := api.Get("/queries/inactive/run")
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	}
+	body := decodeCodedError(t, resp.Body.Bytes())
+	if body.Code != "SAVED_QUERY_INVALID" || !strings.Contains(body.Detail, `unknown field "status"`) {
+		t.Errorf("Expected SAVED_QUERY_INVALID naming the field, got %+v", body)
+	}
+
+	// It can still be read, so it can be fixed
+	resp = api.Get("/queries/inactive")
+	var query dto.SavedQueryResponse
+	json.Unmarshal(resp.Body.Bytes(), &query)
+	if query.Filter["status"] != "inactive" {
+		t.Errorf("Expected the stored filter shown as saved, got %+v", query)
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestRegionFilters(t *testing.T) {
+	t.Parallel()
+	locationService := service.NewLocationService(memory.NewInMemoryLocationRepository(),
+		service.WithRegions([]string{"Lagos", "Abuja"}, true))
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(locationService).RegisterRoutes(api)
+
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515), Region: "Lagos"})
+	api.Post("/locations", dto.LocationRequest{Name: "Border", Latitude: ptr(6.5010), Longitude: ptr(3.3700), Region: "Abuja"})
+
+	resp := api.Get("/nearest?lat=6.5&lng=3.37&region=Lagos")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var nearest dto.NearestLocationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &nearest); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if nearest.Location.Name != "Ikeja" || nearest.Location.Region != "Lagos" {
+		t.Errorf("Expected Ikeja in Lagos despite Border being nearer, got %+v", nearest.Location)
+	}
+
+	resp = api.Get("/locations?region=Abuja")
+	var list dto.LocationListResponse
+	json.Unmarshal(resp.Body.Bytes(), &list)
+	if list.Count != 1 || list.Locations[0].Name != "Border" {
+		t.Errorf("Expected only Border in Abuja, got %+v", list.Locations)
+	}
+
+	tests := []struct {
+		name   string
+		resp   *httptest.ResponseRecorder
+		status int
+		code   string
+	}{
+		{"nearest without region", api.Get("/nearest?lat=6.5&lng=3.37"), http.StatusUnprocessableEntity, "REGION_REQUIRED"},
+		{"nearest in unknown region", api.Get("/nearest?lat=6.5&lng=3.37&region=Kano"), http.StatusUnprocessableEntity, "INVALID_REGION"},
+		{"list in unknown region", api.Get("/locations?region=Kano"), http.StatusUnprocessableEntity, "INVALID_REGION"},
+		{"create without region", api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)}),
+			http.StatusUnprocessableEntity, "REGION_REQUIRED"},
+	}
+	for _, tt := range tests {
+		if tt.resp.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, tt.resp.Code, tt.resp.Body.String())
+			continue
+		}
+		if body := decodeCodedError(t, tt.resp.Body.Bytes()); body.Code != tt.code {
+			t.Errorf("%s: expected %s, got %+v", tt.name, tt.code, body)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import "github.com/danielgtaylor/huma/v2"
+
+// RouteRegistrar is implemented by every handler in this package
+type RouteRegistrar interface {
+	RegisterRoutes(api huma.API)
+}
+
+// Routes collects the handlers an API serves, so building them is kept
+// apart from the router they are registered on
+type Routes struct {
+	handlers []RouteRegistrar
+}
+
+// Add appends handlers, registered later in the order added
+func (r *Routes) Add(handlers ...RouteRegistrar) {
+	r.handlers = append(r.handlers, handlers...)
+}
+
+// RegisterAll registers every added handler's operations on api. Handlers
+// only see huma.API, so any adapter's router serves them alike.
+func (r *Routes) RegisterAll(api huma.API) {
+	for _, h := range r.handlers {
+		h.RegisterRoutes(api)
+	}
+}
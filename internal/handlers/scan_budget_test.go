@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func setupBudgetedAPI(t *testing.T, budget domain.ScanBudget) humatest.TestAPI {
+	t.Helper()
+	repo := memory.NewInMemoryLocationRepository(memory.WithScanBudget(budget))
+	handler := NewLocationHandler(service.NewLocationService(repo, service.WithBudgetedNearest(repo)))
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	handler.RegisterRoutes(api)
+	return api
+}
+
+func TestNearestScanBudget(t *testing.T) {
+	t.Parallel()
+	seed := func(api humatest.TestAPI) {
+		api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+		api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	}
+
+	api := setupBudgetedAPI(t, domain.ScanBudget{Soft: 1})
+	seed(api)
+	resp := api.Get("/nearest?lat=6.51&lng=3.37")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var nearest dto.NearestLocationResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &nearest); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if nearest.Location.Name != "Yaba" || !nearest.Approximate {
+		t.Errorf("Expected an approximate Yaba, got %+v", nearest)
+	}
+
+	api = setupBudgetedAPI(t, domain.ScanBudget{Hard: 1})
+	seed(api)
+	resp = api.Get("/nearest?lat=6.51&lng=3.37")
+	if resp.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusServiceUnavailable, resp.Code, resp.Body.String())
+	} else if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "NEAREST_SCAN_LIMIT" {
+		t.Errorf("Expected NEAREST_SCAN_LIMIT, got %+v", body)
+	}
+	// A lookup at a point reads only the cells within its tolerance
+	if resp = api.Get("/locations/at?lat=6.5095&lng=3.3711"); resp.Code != http.StatusOK {
+		t.Errorf("Expected /locations/at within the hard limit, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	// Within the budget the answer is exact and says nothing more
+	api = setupBudgetedAPI(t, domain.ScanBudget{Soft: 10, Hard: 10})
+	seed(api)
+	resp = api.Get("/nearest?lat=6.51&lng=3.37")
+	var raw map[string]any
+	json.Unmarshal(resp.Body.Bytes(), &raw)
+	if _, ok := raw["approximate"]; resp.Code != http.StatusOK || ok {
+		t.Errorf("Expected an exact answer without the approximate field, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
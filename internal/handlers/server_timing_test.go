@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+const repoDelay = 20 * time.Millisecond
+
+// slowRepository takes repoDelay to answer searches and listings
+type slowRepository struct {
+	*memory.InMemoryLocationRepository
+}
+
+func (r slowRepository) Find(filter domain.LocationFilter, page domain.Page, sort domain.LocationSort) ([]*domain.Location, error) {
+	time.Sleep(repoDelay)
+	return r.InMemoryLocationRepository.Find(filter, page, sort)
+}
+
+func (r slowRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	time.Sleep(repoDelay)
+	return r.InMemoryLocationRepository.FindNearest(latitude, longitude, exclude...)
+}
+
+// parseServerTiming reads a Server-Timing header into durations by name
+func parseServerTiming(t *testing.T, header string) map[string]float64 {
+	t.Helper()
+	durations := map[string]float64{}
+	for _, metric := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(metric), ";")
+		value, ok := strings.CutPrefix(params, "dur=")
+		if !ok {
+			t.Fatalf("Expected a duration for %q in %q", name, header)
+		}
+		dur, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("Invalid duration for %q in %q: %v", name, header, err)
+		}
+		durations[name] = dur
+	}
+	return durations
+}
+
+func TestServerTiming(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Transformers = append([]huma.Transformer{middleware.MarkServerTiming}, config.Transformers...)
+	_, api := humatest.New(t, config)
+	api.UseMiddleware(middleware.ServerTiming)
+	repo := slowRepository{memory.NewInMemoryLocationRepository()}
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+
+	if resp := api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)}); resp.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+
+	for _, path := range []string{"/nearest?lat=6.45&lng=3.47", "/locations"} {
+		t.Run(path, func(t *testing.T) {
+			start := time.Now()
+			resp := api.Get(path)
+			observed := float64(time.Since(start).Microseconds()) / 1000
+			if resp.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+			}
+			if !strings.Contains(resp.Body.String(), "Ikeja") {
+				t.Errorf("Expected the body to be sent after the header, got %s", resp.Body.String())
+			}
+
+			timing := parseServerTiming(t, resp.Header().Get("Server-Timing"))
+			for _, name := range []string{"repo", "service", "serialize", "total"} {
+				if _, ok := timing[name]; !ok {
+					t.Errorf("Expected a %s metric, got %v", name, timing)
+				}
+			}
+			if delay := float64(repoDelay.Milliseconds()); timing["repo"] < delay {
+				t.Errorf("Expected repo to include the %gms store delay, got %v", delay, timing)
+			}
+			if sum := timing["repo"] + timing["service"] + timing["serialize"]; math.Abs(sum-timing["total"]) > 0.01 {
+				t.Errorf("Expected the segments to add up to total, got %g for %v", sum, timing)
+			}
+			if timing["total"] > observed {
+				t.Errorf("Expected total within the observed %gms, got %v", observed, timing)
+			}
+		})
+	}
+
+	// Errors are timed too
+	resp := api.Get("/nearest?lat=6.45")
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	}
+	if timing := parseServerTiming(t, resp.Header().Get("Server-Timing")); timing["repo"] != 0 {
+		t.Errorf("Expected a rejected request to spend no time in the store, got %v", timing)
+	}
+}
+
+func TestServerTimingDisabled(t *testing.T) {
+	api, _ := setupTestAPI(t)
+	resp := api.Get("/locations")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if header := resp.Header().Get("Server-Timing"); header != "" {
+		t.Errorf("Expected no Server-Timing header, got %q", header)
+	}
+}
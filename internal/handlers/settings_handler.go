@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// UpdateSearchSettingsRequest represents a request to replace the search settings
+type UpdateSearchSettingsRequest struct {
+	Body dto.SearchSettingsRequest `json:"body"`
+}
+
+// SearchSettingsResponse represents the search settings in effect
+type SearchSettingsResponse struct {
+	Body dto.SearchSettingsResponse `json:"body"`
+}
+
+// SettingsHandler lets operators change search defaults without a restart
+type SettingsHandler struct {
+	service *service.SettingsService
+}
+
+// NewSettingsHandler creates a new settings handler
+func NewSettingsHandler(service *service.SettingsService) *SettingsHandler {
+	return &SettingsHandler{service: service}
+}
+
+// RegisterRoutes registers the settings routes with the Huma API
+func (h *SettingsHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-search-settings",
+		Method:      http.MethodGet,
+		Path:        "/settings/search",
+		Summary:     "Get Search Settings",
+		Description: "The defaults nearest searches use for parameters the request leaves out: the stored settings, " +
+			"or the server's configured defaults until some are saved.",
+		Tags: []string{"Admin"},
+	}, h.GetSearchSettings)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-search-settings",
+		Method:      http.MethodPut,
+		Path:        "/settings/search",
+		Summary:     "Update Search Settings",
+		Description: "Replace the stored search settings. They apply to the next search; an explicit request " +
+			"parameter still takes precedence.",
+		Tags: []string{"Admin"},
+	}, h.UpdateSearchSettings)
+}
+
+// GetSearchSettings handles GET /settings/search requests
+func (h *SettingsHandler) GetSearchSettings(ctx context.Context, input *struct{}) (*SearchSettingsResponse, error) {
+	settings, stored := h.service.SearchSettings()
+	return &SearchSettingsResponse{Body: dto.FromSearchSettings(settings, stored)}, nil
+}
+
+// UpdateSearchSettings handles PUT /settings/search requests
+func (h *SettingsHandler) UpdateSearchSettings(ctx context.Context, input *UpdateSearchSettingsRequest) (*SearchSettingsResponse, error) {
+	settings, err := h.service.UpdateSearchSettings(domain.SearchSettings{
+		SpeedKmh:      input.Body.SpeedKmh,
+		MaxDistanceKm: input.Body.MaxDistanceKm,
+	})
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to save the search settings"))
+	}
+	return &SearchSettingsResponse{Body: dto.FromSearchSettings(*settings, true)}, nil
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestSearchSettingsPrecedence(t *testing.T) {
+	t.Parallel()
+	locationService := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	settings := service.NewSettingsService(memory.NewInMemorySettingsRepository(), domain.SearchSettings{SpeedKmh: 60, MaxDistanceKm: 50})
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(locationService, WithSearchSettings(settings)).RegisterRoutes(api)
+	NewSettingsHandler(settings).RegisterRoutes(api)
+
+	// Ikeja is about 21 km from the query point
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	const query = "/nearest?lat=6.45&lng=3.47"
+
+	nearest := func(path string, wantSpeed float64) {
+		t.Helper()
+		resp := api.Get(path)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d: %s", path, http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var body dto.NearestLocationResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		want := int(math.Round(body.Distance.Kilometers() / wantSpeed * 60))
+		if body.ETA == nil || *body.ETA != want {
+			t.Errorf("%s: expected an ETA of %d minutes at %v km/h, got %v", path, want, wantSpeed, body.ETA)
+		}
+	}
+	outOfRange := func(path string) {
+		t.Helper()
+		resp := api.Get(path)
+		if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusNotFound || body.Code != "NO_LOCATION_IN_RANGE" {
+			t.Errorf("%s: expected 404 NO_LOCATION_IN_RANGE, got %d %+v", path, resp.Code, body)
+		}
+	}
+
+	// Config defaults apply until settings are stored
+	resp := api.Get("/settings/search")
+	var current dto.SearchSettingsResponse
+	json.Unmarshal(resp.Body.Bytes(), &current)
+	if current.Source != "config" || current.SpeedKmh != 60 || current.MaxDistanceKm != 50 || current.UpdatedAt != nil {
+		t.Errorf("Expected the configured defaults, got %+v", current)
+	}
+	nearest(query, 60)
+	outOfRange(query + "&max_distance_km=10")
+
+	// Stored settings replace them at once
+	resp = api.Put("/settings/search", map[string]any{"speed_kmh": 30, "max_distance_km": 10})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	json.Unmarshal(resp.Body.Bytes(), &current)
+	if current.Source != "stored" || current.UpdatedAt == nil {
+		t.Errorf("Expected stored settings, got %+v", current)
+	}
+	outOfRange(query)
+
+	// Explicit parameters win over stored settings
+	nearest(query+"&max_distance_km=50", 30)
+	nearest(query+"&max_distance_km=50&speed_kmh=120", 120)
+
+	// Zero stored values turn the defaults off
+	api.Put("/settings/search", map[string]any{"speed_kmh": 0, "max_distance_km": 0})
+	resp = api.Get(query)
+	var body dto.NearestLocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &body)
+	if resp.Code != http.StatusOK || body.ETA != nil {
+		t.Errorf("Expected no limit and no ETA, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestSearchSettingsValidation(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemorySettingsRepository()
+	settings := service.NewSettingsService(repo, domain.SearchSettings{SpeedKmh: 60})
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewSettingsHandler(settings).RegisterRoutes(api)
+
+	for _, body := range []map[string]any{
+		{"speed_kmh": -1, "max_distance_km": 10},
+		{"speed_kmh": 30, "max_distance_km": -5},
+		{"speed_kmh": "fast", "max_distance_km": 10},
+		{"speed_kmh": 30},
+	} {
+		if resp := api.Put("/settings/search", body); resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%v: expected status %d, got %d: %s", body, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+		}
+	}
+	if _, err := repo.FindSearchSettings(); err == nil {
+		t.Errorf("Expected nothing stored from invalid settings")
+	}
+
+	// A stored row that no longer validates falls back to the config
+	repo.SaveSearchSettings(&domain.SearchSettings{SpeedKmh: -3})
+	resp := api.Get("/settings/search")
+	var current dto.SearchSettingsResponse
+	json.Unmarshal(resp.Body.Bytes(), &current)
+	if current.Source != "config" || current.SpeedKmh != 60 {
+		t.Errorf("Expected the configured defaults in place of invalid stored settings, got %+v", current)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// VerifySpatialRequest represents the options for a spatial consistency scan
+type VerifySpatialRequest struct {
+	Fix       bool `query:"fix" doc:"Repair inconsistencies that can be derived from the stored coordinates"`
+	BatchSize int  `query:"batch_size" minimum:"0" doc:"Rows checked per batch, up to the configured maximum; each batch holds its locks only briefly"`
+}
+
+// spatialReportLine is one line of the newline-delimited JSON progress report
+type spatialReportLine struct {
+	Type     string                `json:"type"`
+	Scanned  int                   `json:"scanned"`
+	Issues   []domain.SpatialIssue `json:"issues,omitempty"`
+	Found    int                   `json:"found,omitempty"`
+	Repaired int                   `json:"repaired,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// SpatialHandler exposes spatial consistency checks to operators
+type SpatialHandler struct {
+	verifier domain.SpatialVerifier
+	limits   config.LimitsConfig
+}
+
+// NewSpatialHandler creates a new spatial handler; zero limits mean the defaults
+func NewSpatialHandler(verifier domain.SpatialVerifier, limits config.LimitsConfig) *SpatialHandler {
+	if limits == (config.LimitsConfig{}) {
+		limits = config.DefaultLimits()
+	}
+	return &SpatialHandler{verifier: verifier, limits: limits}
+}
+
+// RegisterRoutes registers the spatial admin routes with the Huma API
+func (h *SpatialHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "verify-spatial",
+		Method:      http.MethodPost,
+		Path:        "/admin/verify-spatial",
+		Summary:     "Verify Spatial Consistency",
+		Description: "Scan stored locations for coordinates that disagree with their spatial index, optionally repairing them. " +
+			"Progress is streamed as newline-delimited JSON: one `batch` line per batch followed by a `summary` line.",
+		Tags:   []string{"Admin"},
+		Errors: []int{http.StatusUnprocessableEntity},
+	}, h.VerifySpatial)
+}
+
+// VerifySpatial handles POST /admin/verify-spatial requests
+func (h *SpatialHandler) VerifySpatial(ctx context.Context, input *VerifySpatialRequest) (*huma.StreamResponse, error) {
+	if err := checkLimit(ctx, "batch_size", input.BatchSize, h.limits.MaxBatchSize); err != nil {
+		return nil, err
+	}
+	batchSize := input.BatchSize
+	if batchSize == 0 {
+		batchSize = h.limits.DefaultBatchSize
+	}
+
+	// Streamed lines skip the transformers, so their IDs are encoded here
+	ids := dto.ExternalIDsFromContext(ctx)
+	return &huma.StreamResponse{
+		Body: func(ctx huma.Context) {
+			ctx.SetHeader("Content-Type", "application/x-ndjson")
+			writer := ctx.BodyWriter()
+			encoder := json.NewEncoder(writer)
+			flusher, _ := writer.(http.Flusher)
+
+			summary := spatialReportLine{Type: "summary"}
+			err := h.verifier.VerifySpatial(batchSize, input.Fix, func(batch domain.SpatialBatch) error {
+				summary.Scanned += batch.Scanned
+				summary.Found += len(batch.Issues)
+				batch.Issues = slices.Clone(batch.Issues)
+				for i, issue := range batch.Issues {
+					if issue.Repaired {
+						summary.Repaired++
+					}
+					batch.Issues[i].ID = ids.Encode(issue.ID)
+				}
+
+				if err := encoder.Encode(spatialReportLine{Type: "batch", Scanned: batch.Scanned, Issues: batch.Issues}); err != nil {
+					return err
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+				return nil
+			})
+			if err != nil {
+				// The status line has already been sent, so report in-band
+				summary.Type = "error"
+				summary.Error = "Verification stopped: " + err.Error()
+			}
+			encoder.Encode(summary)
+		},
+	}, nil
+}
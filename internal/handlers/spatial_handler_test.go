@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+func setupSpatialAPI(t *testing.T, repo *memory.InMemoryLocationRepository) humatest.TestAPI {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+		{Name: "ops", Key: "ops-key", Scopes: []auth.Scope{auth.ScopeAdmin}},
+	})))
+	NewSpatialHandler(repo, config.LimitsConfig{}).RegisterRoutes(api)
+	return api
+}
+
+func TestVerifySpatialRequiresAdmin(t *testing.T) {
+	api := setupSpatialAPI(t, memory.NewInMemoryLocationRepository())
+
+	if resp := api.Post("/admin/verify-spatial"); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, resp.Code)
+	}
+	if resp := api.Post("/admin/verify-spatial", "X-API-Key: partner-key"); resp.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, resp.Code)
+	}
+}
+
+func TestVerifySpatialStreamsReport(t *testing.T) {
+	repo := memory.NewInMemoryLocationRepository()
+	for _, name := range []string{"A", "B", "C"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.3)
+		repo.Save(location)
+	}
+	api := setupSpatialAPI(t, repo)
+
+	resp := api.Post("/admin/verify-spatial?batch_size=2", "X-API-Key: ops-key")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if got := resp.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %s", got)
+	}
+
+	var lines []spatialReportLine
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line spatialReportLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Failed to decode line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) < 3 {
+		t.Fatalf("Expected several batch lines and a summary, got %d lines", len(lines))
+	}
+	summary := lines[len(lines)-1]
+	if summary.Type != "summary" {
+		t.Errorf("Expected final summary line, got %s", summary.Type)
+	}
+	// Three names and three ID index entries are checked
+	if summary.Scanned != 6 || summary.Found != 0 {
+		t.Errorf("Expected 6 scanned with no issues, got %+v", summary)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestUpdateStock(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+
+	resp := api.Patch("/locations/Yaba/stock", dto.StockRequest{CapacityLitres: ptr(30000.0), StockLitres: ptr(12500.0)})
+	var location dto.LocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &location)
+	if resp.Code != http.StatusOK || location.StockLitres == nil || *location.StockLitres != 12500 || *location.CapacityLitres != 30000 {
+		t.Fatalf("Expected the stock set, got %d %s", resp.Code, resp.Body.String())
+	}
+	etag := resp.Header().Get("ETag")
+
+	resp = api.Patch("/locations/Yaba/stock", dto.StockRequest{AdjustLitres: -2500})
+	json.Unmarshal(resp.Body.Bytes(), &location)
+	if resp.Code != http.StatusOK || *location.StockLitres != 10000 || *location.CapacityLitres != 30000 {
+		t.Errorf("Expected the adjustment applied and the capacity kept, got %d %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Patch("/locations/Yaba/stock", dto.StockRequest{StockLitres: ptr(30001.0)})
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusUnprocessableEntity || body.Code != "INVALID_STOCK" {
+		t.Errorf("Expected 422 INVALID_STOCK above capacity, got %d %+v", resp.Code, body)
+	}
+	if resp := api.Patch("/locations/Yaba/stock", dto.StockRequest{StockLitres: ptr(-1.0)}); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a negative stock refused, got %d", resp.Code)
+	}
+	if resp := api.Patch("/locations/Nowhere/stock", dto.StockRequest{StockLitres: ptr(1.0)}); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown location, got %d", resp.Code)
+	}
+	if resp := api.Patch("/locations/Yaba/stock", dto.StockRequest{StockLitres: ptr(1.0)}, "If-Match: "+etag); resp.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 with an outdated ETag, got %d", resp.Code)
+	}
+}
+
+func TestNearestMinStock(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	api.Patch("/locations/Yaba/stock", dto.StockRequest{StockLitres: ptr(0.0)})
+	api.Patch("/locations/Ikeja/stock", dto.StockRequest{StockLitres: ptr(8000.0)})
+
+	resp := api.Get("/nearest?lat=6.5&lng=3.37&min_stock=5000")
+	var nearest dto.NearestLocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &nearest)
+	if resp.Code != http.StatusOK || nearest.Location.Name != "Ikeja" {
+		t.Fatalf("Expected Ikeja despite the empty Yaba being nearer, got %d %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Get("/nearest?lat=6.5&lng=3.37&min_stock=10000")
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusNotFound || body.Code != "NO_STOCKED_LOCATION" {
+		t.Errorf("Expected 404 NO_STOCKED_LOCATION, got %d %+v", resp.Code, body)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// timingOutRepository fails reads by name as the adaptive timeout does
+type timingOutRepository struct {
+	*memory.InMemoryLocationRepository
+}
+
+func (r timingOutRepository) FindByName(string) (*domain.Location, error) {
+	return nil, fmt.Errorf("FindByName took longer than 500ms: %w", domain.ErrStorageTimeout)
+}
+
+func TestStorageTimeoutAnswers503(t *testing.T) {
+	t.Parallel()
+	repo := timingOutRepository{memory.NewInMemoryLocationRepository()}
+	api := newTestAPI(t)
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+
+	resp := api.Get("/locations/Yaba")
+	if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusServiceUnavailable || body.Code != "STORAGE_TIMEOUT" {
+		t.Errorf("Expected 503 STORAGE_TIMEOUT, got %d %+v", resp.Code, body)
+	}
+}
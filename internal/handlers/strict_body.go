@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+// strictBody checks the top-level fields of a JSON request body against
+// the operation's body schema before Huma reads it, and answers 422
+// UNKNOWN_FIELDS listing every field the schema lacks, each with the known
+// field it most likely meant. Bodies that are not JSON objects, or are
+// over the operation's size limit, are left to Huma to reject.
+func strictBody(api huma.API) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if ct := ctx.Header("Content-Type"); ct != "" && !strings.Contains(ct, "json") {
+			next(ctx)
+			return
+		}
+		// Reading one byte past the limit is enough for Huma to answer 413
+		reader, limit := ctx.BodyReader(), ctx.Operation().MaxBodyBytes
+		if limit > 0 {
+			reader = io.LimitReader(reader, limit+1)
+		}
+		body, err := io.ReadAll(reader)
+		replay := &bodyContext{humaContext: ctx, body: io.MultiReader(bytes.NewReader(body), ctx.BodyReader())}
+		if err != nil || (limit > 0 && int64(len(body)) > limit) {
+			next(replay)
+			return
+		}
+
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(body, &fields) != nil {
+			next(replay)
+			return
+		}
+		known := bodyProperties(api, ctx.Operation())
+		if known == nil {
+			next(replay)
+			return
+		}
+		var unknown []string
+		for name := range fields {
+			if _, ok := known[name]; !ok {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) == 0 {
+			next(replay)
+			return
+		}
+		sort.Strings(unknown)
+		apierrors.WriteHuma(ctx, unknownFieldsError(ctx, unknown, known))
+	}
+}
+
+// bodyProperties returns the top-level properties of the operation's JSON
+// request body, or nil when the schema does not list them
+func bodyProperties(api huma.API, op *huma.Operation) map[string]*huma.Schema {
+	if op.RequestBody == nil || op.RequestBody.Content["application/json"] == nil {
+		return nil
+	}
+	schema := op.RequestBody.Content["application/json"].Schema
+	if schema != nil && schema.Ref != "" {
+		schema = api.OpenAPI().Components.Schemas.SchemaFromRef(schema.Ref)
+	}
+	if schema == nil || schema.Properties == nil {
+		return nil
+	}
+	return schema.Properties
+}
+
+// unknownFieldsError lists the unknown fields, each as its own detail
+// carrying the suggestion for it
+func unknownFieldsError(ctx huma.Context, unknown []string, known map[string]*huma.Schema) huma.StatusError {
+	names := make([]string, 0, len(known))
+	for name := range known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := strings.Join(unknown, ", ")
+	coded := apierrors.ToHuma(ctx.Context(), apierrors.New(http.StatusUnprocessableEntity, "UNKNOWN_FIELDS",
+		"Unknown fields: "+list).With("fields", list)).(*apierrors.CodedError)
+	for _, name := range unknown {
+		params := map[string]string{"field": name}
+		message := i18n.Translate(ctx.Context(), "validation.unknown", name+" is not a known field", params)
+		if suggestion := text.Suggest(name, names); suggestion != "" {
+			params["suggestion"] = suggestion
+			message = i18n.Translate(ctx.Context(), "validation.unknown_suggestion",
+				name+" is not a known field; did you mean "+suggestion+"?", params)
+		}
+		coded.Errors = append(coded.Errors, &huma.ErrorDetail{Message: message, Location: "body." + name})
+	}
+	return coded
+}
+
+// humaContext is embedded under this name because huma.Context has a
+// Context method, which the field would shadow
+type humaContext = huma.Context
+
+// bodyContext replays a request body a middleware has already read
+type bodyContext struct {
+	humaContext
+	body io.Reader
+}
+
+func (c *bodyContext) BodyReader() io.Reader {
+	return c.body
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+func TestStrictBodyUnknownFields(t *testing.T) {
+	api, _ := setupTestAPI(t)
+
+	resp := api.Post("/locations", strings.NewReader(`{"name":"Ikeja","lat":6.6,"lng":3.35,"colour":"red"}`))
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	}
+	body := decodeCodedError(t, resp.Body.Bytes())
+	if body.Code != "UNKNOWN_FIELDS" || body.Detail != "Unknown fields: colour, lat, lng" {
+		t.Fatalf("Expected UNKNOWN_FIELDS listing the fields, got %+v", body)
+	}
+	expected := map[string]string{
+		"body.colour": "colour is not a known field",
+		"body.lat":    "lat is not a known field; did you mean latitude?",
+		"body.lng":    "lng is not a known field; did you mean longitude?",
+	}
+	if len(body.Errors) != len(expected) {
+		t.Fatalf("Expected one detail per unknown field, got %+v", body.Errors)
+	}
+	for _, detail := range body.Errors {
+		if expected[detail.Location] != detail.Message {
+			t.Errorf("%s: expected %q, got %q", detail.Location, expected[detail.Location], detail.Message)
+		}
+	}
+
+	resp = api.Post("/locations/Ikeja/aliases", map[string]any{"alais": "Ikeja Station"})
+	if body := decodeCodedError(t, resp.Body.Bytes()); body.Code != "UNKNOWN_FIELDS" ||
+		len(body.Errors) != 1 || body.Errors[0].Message != "alais is not a known field; did you mean alias?" {
+		t.Errorf("Expected the alias body checked too, got %d %+v", resp.Code, body)
+	}
+
+	// Known fields pass through to the handler untouched
+	resp = api.Post("/locations", map[string]any{"name": "Ikeja", "latitude": 6.6, "longitude": 3.35})
+	if resp.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+	}
+}
+
+func TestStrictBodyLocalized(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(i18n.Middleware)
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository())).RegisterRoutes(api)
+
+	resp := api.Post("/locations", "Accept-Language: fr", map[string]any{"name": "Ikeja", "lat": 6.6, "longitude": 3.35})
+	body := decodeCodedError(t, resp.Body.Bytes())
+	if body.Detail != "Champs inconnus : lat" || len(body.Errors) != 1 ||
+		body.Errors[0].Message != "lat n'est pas un champ connu ; vouliez-vous dire latitude ?" {
+		t.Errorf("Expected a French error, got %+v", body)
+	}
+}
+
+func TestStrictBodyOff(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository()),
+		WithStrictBodies(false)).RegisterRoutes(api)
+
+	// Huma's own schema validation still rejects the field, without suggestions
+	resp := api.Post("/locations", map[string]any{"name": "Ikeja", "lat": 6.6, "latitude": 6.6, "longitude": 3.35})
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	}
+	body := decodeCodedError(t, resp.Body.Bytes())
+	if body.Code != "VALIDATION_ERROR" || len(body.Errors) != 1 ||
+		body.Errors[0].Location != "body.lat" || strings.Contains(body.Errors[0].Message, "did you mean") {
+		t.Errorf("Expected Huma's generic validation error, got %+v", body)
+	}
+}
+
+func TestStrictBodyLeavesOtherBodiesToHuma(t *testing.T) {
+	api, _ := setupTestAPI(t)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"not an object", `["Ikeja"]`, http.StatusUnprocessableEntity},
+		{"malformed", `{"name":`, http.StatusBadRequest},
+		{"too large", `{"name":"` + strings.Repeat("a", 2<<20) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		resp := api.Post("/locations", strings.NewReader(tt.body))
+		if resp.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, resp.Code, resp.Body.String())
+		}
+		if body := decodeCodedError(t, resp.Body.Bytes()); body.Code == "UNKNOWN_FIELDS" {
+			t.Errorf("%s: expected Huma's error, got %+v", tt.name, body)
+		}
+	}
+}
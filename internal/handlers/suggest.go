@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+// SuggestRequest represents the query parameters for name suggestions
+type SuggestRequest struct {
+	Q     string  `query:"q" required:"true" minLength:"1" maxLength:"255" example:"lek" doc:"Name, or the start of a word in it, typed so far; names that only resemble it match too"`
+	Lat   float64 `query:"lat" required:"true" minimum:"-90" maximum:"90" example:"6.4281" doc:"Latitude of the point suggestions are favoured near"`
+	Lng   float64 `query:"lng" required:"true" minimum:"-180" maximum:"180" example:"3.4219" doc:"Longitude of the point suggestions are favoured near"`
+	Limit int     `query:"limit" minimum:"1" maximum:"50" default:"10" example:"10" doc:"Most suggestions to return"`
+	Unit  string  `query:"unit" enum:"km,m,mi,nmi" example:"mi" doc:"Unit of each suggestion's distance. Defaults to the caller's preferred unit, or the server's"`
+}
+
+// SuggestResponse represents ranked suggestions
+type SuggestResponse struct {
+	Body dto.SuggestResponse `json:"body"`
+}
+
+// Suggest handles GET /locations/suggest requests
+func (h *LocationHandler) Suggest(ctx context.Context, input *SuggestRequest) (*SuggestResponse, error) {
+	suggestions, err := h.service.SuggestLocations(ctx, domain.SuggestQuery{
+		Query: input.Q,
+		Near:  &geospatial.Coordinate{Latitude: input.Lat, Longitude: input.Lng},
+		Limit: input.Limit,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrEmptyName) {
+			message := i18n.Translate(ctx, "validation.required", "q is required", map[string]string{"field": "q"})
+			validationErr := apierrors.NewValidationError(map[string]string{"q": message})
+			validationErr.Message = i18n.Translate(ctx, validationErr.Code, "Validation failed", nil)
+			return nil, apierrors.ToHuma(ctx, validationErr)
+		}
+		return nil, storageError(ctx, err, "Failed to suggest locations")
+	}
+	resp := &SuggestResponse{Body: dto.FromSuggestions(strings.TrimSpace(input.Q), suggestions)}
+	resp.Body.InUnit(dto.DistanceUnit(ctx, input.Unit))
+	return resp, nil
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	api.Post("/locations", dto.LocationRequest{Name: "Leeta Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	api.Post("/locations", dto.LocationRequest{Name: "Leeta Lekki", Latitude: ptr(6.4474), Longitude: ptr(3.4723)})
+	api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+
+	// Both names match equally, so the one nearer Victoria Island leads
+	resp := api.Get("/locations/suggest?q=LEETA&lat=6.4281&lng=3.4219")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var body dto.SuggestResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(body.Suggestions) != 2 || body.Suggestions[0].Name != "Leeta Lekki" || body.Suggestions[1].Name != "Leeta Ikeja" {
+		t.Fatalf("Expected Leeta Lekki then Leeta Ikeja, got %+v", body.Suggestions)
+	}
+	if match := body.Suggestions[0].Match; match == nil || *match != (dto.MatchRange{Start: 0, End: 5}) {
+		t.Errorf("Expected the first five characters matched, got %+v", match)
+	}
+	if body.Suggestions[0].Distance >= body.Suggestions[1].Distance {
+		t.Errorf("Expected distances increasing, got %+v", body.Suggestions)
+	}
+
+	resp = api.Get("/locations/suggest?q=lekki&lat=6.4281&lng=3.4219&limit=1")
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(body.Suggestions) != 1 || *body.Suggestions[0].Match != (dto.MatchRange{Start: 6, End: 11}) {
+		t.Errorf("Expected the word Lekki matched, got %+v", body.Suggestions)
+	}
+
+	// A query of only spaces is empty once trimmed
+	resp = api.Get("/locations/suggest?q=%20%20&lat=6.4281&lng=3.4219")
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a blank query, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+	}
+	for _, path := range []string{
+		"/locations/suggest?lat=6.4281&lng=3.4219",
+		"/locations/suggest?q=lekki&lat=6.4281",
+		"/locations/suggest?q=lekki&lat=6.4281&lng=3.4219&limit=51",
+	} {
+		if resp := api.Get(path); resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d for %s, got %d: %s", http.StatusUnprocessableEntity, path, resp.Code, resp.Body.String())
+		}
+	}
+}
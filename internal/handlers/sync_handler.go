@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/client"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// SyncLocationsRequest represents a request to copy another instance
+type SyncLocationsRequest struct {
+	Body dto.SyncRequest `json:"body"`
+}
+
+// SyncLocationsResponse represents the outcome of a sync
+type SyncLocationsResponse struct {
+	Body dto.SyncResponse `json:"body"`
+}
+
+// SyncHandler mirrors another running instance's locations
+type SyncHandler struct {
+	sync           *service.SyncService
+	allowedSources []string
+	timeout        time.Duration
+	pageSize       int
+}
+
+// NewSyncHandler creates a new sync handler. Register it only when syncs
+// are enabled; without allowed sources every sync is refused.
+func NewSyncHandler(sync *service.SyncService, cfg config.SyncConfig) *SyncHandler {
+	allowed := make([]string, len(cfg.AllowedSources))
+	for i, source := range cfg.AllowedSources {
+		allowed[i] = strings.TrimRight(strings.TrimSpace(source), "/")
+	}
+	return &SyncHandler{
+		sync:           sync,
+		allowedSources: allowed,
+		timeout:        time.Duration(cfg.Timeout) * time.Second,
+		pageSize:       cfg.PageSize,
+	}
+}
+
+// RegisterRoutes registers the sync admin route with the Huma API
+func (h *SyncHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "sync-locations",
+		Method:      http.MethodPost,
+		Path:        "/admin/sync",
+		Summary:     "Sync Locations",
+		Description: "Make this server's locations match another instance's. The source's locations are read page by page " +
+			"and compared by name: locations the source lacks are deleted, those that differ are updated in place and new ones are created, " +
+			"through the same checks and transactions as `POST /locations/transactions`. Updated locations keep their ID, creation time and owner. " +
+			"With `dry_run` the planned changes are returned without applying them.",
+		Tags: []string{"Admin"},
+	}, h.Sync)
+}
+
+// Sync handles POST /admin/sync requests
+func (h *SyncHandler) Sync(ctx context.Context, input *SyncLocationsRequest) (*SyncLocationsResponse, error) {
+	source := strings.TrimRight(input.Body.SourceURL, "/")
+	if !slices.Contains(h.allowedSources, source) {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "SYNC_SOURCE_NOT_ALLOWED",
+			"Syncing from "+source+" is not allowed").With("source", source))
+	}
+
+	opts := []client.Option{}
+	if input.Body.APIKey != "" {
+		opts = append(opts, client.WithAPIKey(input.Body.APIKey))
+	}
+	c, err := client.New(source, opts...)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "SYNC_SOURCE_NOT_ALLOWED",
+			"Syncing from "+source+" is not allowed").With("source", source))
+	}
+
+	pullCtx := ctx
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		pullCtx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	pageSize := h.pageSize
+	if pageSize == 0 {
+		// A source that advertises no limit is read with its default page
+		// size, which it always accepts
+		pageSize, err = c.MaxPageSize(pullCtx)
+		if err != nil {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadGateway, "SYNC_SOURCE_FAILED",
+				"Failed to read limits from "+source+": "+err.Error()).With("source", source).With("reason", err.Error()))
+		}
+	}
+	var locations []domain.Location
+	for location, err := range c.ListLocations(pullCtx, pageSize) {
+		if err != nil {
+			return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusBadGateway, "SYNC_SOURCE_FAILED",
+				"Failed to read locations from "+source+": "+err.Error()).With("source", source).With("reason", err.Error()))
+		}
+		locations = append(locations, location.ToDomain())
+	}
+
+	result, err := h.sync.Sync(locations, input.Body.DryRun)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to sync locations"))
+	}
+	return &SyncLocationsResponse{Body: dto.FromSyncResult(result)}, nil
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+// TransactionRequest represents a list of operations applied all or none
+type TransactionRequest struct {
+	Body dto.TransactionRequest `json:"body"`
+}
+
+// TransactionResponse represents the results of an applied transaction
+type TransactionResponse struct {
+	Body dto.TransactionResponse `json:"body"`
+}
+
+// ApplyTransaction handles POST /locations/transaction requests. Every
+// operation is checked before any is applied; the error for the one that
+// fails carries details located under body.operations[index].
+func (h *LocationHandler) ApplyTransaction(ctx context.Context, input *TransactionRequest) (*TransactionResponse, error) {
+	ops := make([]domain.LocationOperation, len(input.Body.Operations))
+	for i := range input.Body.Operations {
+		op, err := operationToDomain(ctx, i, &input.Body.Operations[i])
+		if err != nil {
+			return nil, err
+		}
+		ops[i] = op
+	}
+
+	// Creates are owned by the caller, and updates and deletes are checked
+	// against the owner as the transaction finds it
+	principal := auth.PrincipalFromContext(ctx)
+	owner := h.ownerCheck(ctx)
+	for i, op := range ops {
+		if op.Type == domain.OperationCreate {
+			op.Location.CreatedBy = principalID(principal)
+			continue
+		}
+		if owner != nil {
+			ops[i].Checks = []domain.Precondition{owner}
+		}
+	}
+
+	// Admins may use reserved name prefixes, as on create
+	results, err := h.service.ApplyOperations(ops, principal.HasScope(auth.ScopeAdmin))
+	if err != nil {
+		var opErr *domain.OperationError
+		if errors.As(err, &opErr) && opErr.Index < len(input.Body.Operations) {
+			if refusal := refused(opErr.Err); refusal != nil {
+				return nil, atOperation(refusal, opErr.Index, "name")
+			}
+			return nil, operationError(ctx, opErr.Index, &input.Body.Operations[opErr.Index], opErr.Err)
+		}
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to apply transaction"))
+	}
+	return &TransactionResponse{Body: dto.FromOperationResults(results)}, nil
+}
+
+// operationToDomain checks an operation carries the fields its op needs,
+// and only those, and converts it
+func operationToDomain(ctx context.Context, index int, req *dto.OperationRequest) (domain.LocationOperation, error) {
+	op := domain.LocationOperation{Type: domain.OperationType(req.Op), Name: req.Name}
+	switch op.Type {
+	case domain.OperationCreate:
+		switch {
+		case req.Location == nil:
+			return op, operationFieldError(ctx, index, "location", "required")
+		case req.Name != "":
+			return op, operationFieldError(ctx, index, "name", "invalid")
+		case req.Changes != nil:
+			return op, operationFieldError(ctx, index, "changes", "invalid")
+		}
+		if err := req.Location.Project(); err != nil {
+			return op, atOperation(crsError(ctx, err), index, "location")
+		}
+		if err := req.Location.Validate(); err != nil {
+			return op, atOperation(createError(ctx, req.Location.Name, err), index, "location")
+		}
+		op.Location = &domain.Location{
+			Name:         req.Location.Name,
+			Latitude:     *req.Location.Latitude,
+			Longitude:    *req.Location.Longitude,
+			OpeningHours: req.Location.OpeningHours,
+			Description:  req.Location.Description,
+			Region:       req.Location.Region,
+			Attachments:  req.Location.Attachments,
+		}
+	case domain.OperationUpdate:
+		switch {
+		case req.Name == "":
+			return op, operationFieldError(ctx, index, "name", "required")
+		case req.Changes == nil:
+			return op, operationFieldError(ctx, index, "changes", "required")
+		case req.Location != nil:
+			return op, operationFieldError(ctx, index, "location", "invalid")
+		}
+		op.Changes = req.Changes.ToDomain()
+	case domain.OperationDelete:
+		switch {
+		case req.Name == "":
+			return op, operationFieldError(ctx, index, "name", "required")
+		case req.Location != nil:
+			return op, operationFieldError(ctx, index, "location", "invalid")
+		case req.Changes != nil:
+			return op, operationFieldError(ctx, index, "changes", "invalid")
+		}
+	}
+	return op, nil
+}
+
+// operationError answers the operation a transaction stopped at
+func operationError(ctx context.Context, index int, req *dto.OperationRequest, err error) error {
+	var invalid *domain.ValidationError
+	switch {
+	case errors.Is(err, domain.ErrLocationNotFound):
+		return atOperation(apierrors.ToHuma(ctx, apierrors.New(http.StatusNotFound, "LOCATION_NOT_FOUND", "Location not found")), index, "name")
+	case errors.As(err, &invalid):
+		// The changes or location were invalid, not the operation's name;
+		// createError below describes each field
+	case errors.Is(err, domain.ErrEmptyName):
+		return operationFieldError(ctx, index, "name", "required")
+	case errors.Is(err, domain.ErrEmptyUpdate):
+		return operationFieldError(ctx, index, "changes", "required")
+	}
+
+	name := req.Name
+	switch {
+	case req.Location != nil:
+		name = req.Location.Name
+	case req.Changes != nil && req.Changes.Name != nil:
+		name = *req.Changes.Name
+	}
+	field := "location"
+	if req.Changes != nil {
+		field = "changes"
+	}
+	return atOperation(createError(ctx, name, err), index, field)
+}
+
+// atOperation moves an error's field details under the operation at index,
+// within its field, or adds one detail locating the operation when the
+// error has none
+func atOperation(err error, index int, field string) error {
+	coded, ok := err.(*apierrors.CodedError)
+	if !ok {
+		return err
+	}
+	prefix := fmt.Sprintf("body.operations[%d]", index)
+	if len(coded.Errors) == 0 {
+		coded.Errors = []*huma.ErrorDetail{{Message: coded.Detail, Location: prefix + "." + field}}
+		return coded
+	}
+	for _, detail := range coded.Errors {
+		detail.Location = prefix + "." + field + strings.TrimPrefix(detail.Location, "body")
+	}
+	return coded
+}
+
+// operationFieldError reports a field of the operation at index that is
+// missing or does not belong to its op
+func operationFieldError(ctx context.Context, index int, field, key string) error {
+	name := fmt.Sprintf("operations[%d].%s", index, field)
+	message := i18n.Translate(ctx, "validation."+key, field+" is "+key, map[string]string{"field": name})
+	validationErr := apierrors.NewValidationError(map[string]string{name: message})
+	validationErr.Message = i18n.Translate(ctx, validationErr.Code, "Validation failed", nil)
+	return apierrors.ToHuma(ctx, validationErr)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+func TestApplyTransaction(t *testing.T) {
+	t.Parallel()
+	api, _ := setupTestAPI(t)
+	if resp := api.Post("/locations", map[string]any{"name": "Depot Ikeja Old", "latitude": 6.6, "longitude": 3.35}); resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to seed: %s", resp.Body.String())
+	}
+
+	resp := api.Post("/locations/transaction", map[string]any{"operations": []map[string]any{
+		{"op": "create", "location": map[string]any{"name": "Depot Ikeja", "latitude": 6.6018, "longitude": 3.3515, "region": "Lagos"}},
+		{"op": "create", "location": map[string]any{"name": "Ikeja Satellite 1", "crs": 32631, "x": 541924.3014, "y": 721189.2172}},
+		{"op": "update", "name": "Ikeja Satellite 1", "changes": map[string]any{"description": "  Behind the market  "}},
+		{"op": "delete", "name": "Depot Ikeja Old"},
+	}})
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var body dto.TransactionResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	want := []struct{ op, name string }{{"create", "Depot Ikeja"}, {"create", "Ikeja Satellite 1"}, {"update", "Ikeja Satellite 1"}, {"delete", "Depot Ikeja Old"}}
+	if len(body.Results) != len(want) {
+		t.Fatalf("Expected %d results, got %+v", len(want), body.Results)
+	}
+	for i, w := range want {
+		if body.Results[i].Op != w.op || body.Results[i].Location.Name != w.name {
+			t.Errorf("Result %d: expected %s %s, got %+v", i, w.op, w.name, body.Results[i])
+		}
+	}
+	if satellite := body.Results[2].Location; satellite.Description != "Behind the market" || satellite.Latitude != 6.5244 {
+		t.Errorf("Expected the projected satellite with a cleaned description, got %+v", satellite)
+	}
+
+	if resp := api.Get("/locations?name_contains=Ikeja"); resp.Code != http.StatusOK {
+		t.Fatalf("Failed to list: %s", resp.Body.String())
+	} else {
+		var list dto.LocationListResponse
+		json.Unmarshal(resp.Body.Bytes(), &list)
+		if list.Count != 2 {
+			t.Errorf("Expected the depot and its satellite only, got %+v", list.Locations)
+		}
+	}
+}
+
+func TestApplyTransactionFailures(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		ops      []map[string]any
+		status   int
+		code     string
+		location string
+	}{
+		{
+			name: "missing location",
+			ops: []map[string]any{
+				{"op": "create", "location": map[string]any{"name": "Hub", "latitude": 6.45, "longitude": 3.39}},
+				{"op": "create", "name": "Hub Satellite"},
+			},
+			status: http.StatusBadRequest, code: "VALIDATION_ERROR", location: "body.operations[1].location",
+		},
+		{
+			name: "invalid create",
+			ops: []map[string]any{
+				{"op": "create", "location": map[string]any{"name": "Hub", "latitude": 6.45, "longitude": 3.39}},
+				{"op": "create", "location": map[string]any{"name": "Hub Satellite", "latitude": 6.45}},
+			},
+			status: http.StatusBadRequest, code: "VALIDATION_ERROR", location: "body.operations[1].location.longitude",
+		},
+		{
+			name: "projection outside the zone",
+			ops: []map[string]any{
+				{"op": "create", "location": map[string]any{"name": "Hub", "crs": 32631, "x": 1100000, "y": 1326829}},
+			},
+			status: http.StatusUnprocessableEntity, code: "INVALID_CRS_COORDINATES", location: "body.operations[0].location",
+		},
+		{
+			name: "empty update",
+			ops: []map[string]any{
+				{"op": "create", "location": map[string]any{"name": "Hub", "latitude": 6.45, "longitude": 3.39}},
+				{"op": "update", "name": "Hub", "changes": map[string]any{}},
+			},
+			status: http.StatusBadRequest, code: "VALIDATION_ERROR", location: "body.operations[1].changes",
+		},
+		{
+			name: "duplicate create",
+			ops: []map[string]any{
+				{"op": "create", "location": map[string]any{"name": "Hub", "latitude": 6.45, "longitude": 3.39}},
+				{"op": "create", "location": map[string]any{"name": "Hub Satellite", "latitude": 6.46, "longitude": 3.39}},
+				{"op": "create", "location": map[string]any{"name": "Hub", "latitude": 6.47, "longitude": 3.39}},
+			},
+			status: http.StatusConflict, code: "LOCATION_EXISTS", location: "body.operations[2].location",
+		},
+		{
+			name: "missing location to delete",
+			ops: []map[string]any{
+				{"op": "create", "location": map[string]any{"name": "Hub", "latitude": 6.45, "longitude": 3.39}},
+				{"op": "delete", "name": "Hub Satellite"},
+			},
+			status: http.StatusNotFound, code: "LOCATION_NOT_FOUND", location: "body.operations[1].name",
+		},
+		{
+			name:   "unknown op",
+			ops:    []map[string]any{{"op": "upsert", "name": "Hub"}},
+			status: http.StatusUnprocessableEntity, code: "VALIDATION_ERROR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			api, h := setupTestAPI(t)
+			resp := api.Post("/locations/transaction", map[string]any{"operations": tt.ops})
+			if resp.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, resp.Code, resp.Body.String())
+			}
+			body := decodeCodedError(t, resp.Body.Bytes())
+			if body.Code != tt.code {
+				t.Errorf("Expected code %s, got %+v", tt.code, body)
+			}
+			if tt.location != "" && (len(body.Errors) != 1 || body.Errors[0].Location != tt.location) {
+				t.Errorf("Expected one detail at %s, got %+v", tt.location, body.Errors)
+			}
+
+			if all, _ := h.service.GetAllLocations(); len(all) != 0 {
+				t.Errorf("Expected nothing kept, got %+v", all)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// TruncateLocationsRequest represents the confirmed removal of every location
+type TruncateLocationsRequest struct {
+	DryRun bool                `query:"dry_run" doc:"Count the locations that would be removed without removing them"`
+	Body   dto.TruncateRequest `json:"body"`
+}
+
+// TruncateLocationsResponse represents the outcome of a truncation
+type TruncateLocationsResponse struct {
+	Body dto.TruncateResponse `json:"body"`
+}
+
+// TruncateHandler lets test environments remove every location in one call
+type TruncateHandler struct {
+	repo        domain.LocationRepository
+	truncator   domain.LocationTruncator
+	publisher   domain.EventPublisher
+	environment string
+}
+
+// NewTruncateHandler creates a new truncate handler. Register it only when
+// the environment is named, as the name is the confirmation. publisher may
+// be nil when the truncator records deletion events itself.
+func NewTruncateHandler(repo domain.LocationRepository, truncator domain.LocationTruncator, publisher domain.EventPublisher, environment string) *TruncateHandler {
+	return &TruncateHandler{repo: repo, truncator: truncator, publisher: publisher, environment: environment}
+}
+
+// RegisterRoutes registers the truncate admin route with the Huma API
+func (h *TruncateHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "truncate-locations",
+		Method:      http.MethodPost,
+		Path:        "/admin/truncate",
+		Summary:     "Truncate Locations",
+		Description: "Remove every location and alias at once and record one audit entry with the count. IDs are not reused; " +
+			"the change feed keeps its sequence and lists a deletion for each location removed, and a `location.deleted` event is published for each. " +
+			"`confirm` must be the environment name the server is configured with, so a call meant for another environment is refused. " +
+			"With `dry_run=true` nothing is removed and the count is what would be.",
+		Tags:   []string{"Admin"},
+		Errors: []int{http.StatusUnprocessableEntity},
+	}, h.Truncate)
+}
+
+// Truncate handles POST /admin/truncate requests
+func (h *TruncateHandler) Truncate(ctx context.Context, input *TruncateLocationsRequest) (*TruncateLocationsResponse, error) {
+	if input.Body.Confirm != h.environment {
+		return nil, apierrors.ToHuma(ctx, apierrors.New(http.StatusUnprocessableEntity, "TRUNCATE_NOT_CONFIRMED",
+			"confirm must be the name of this environment"))
+	}
+
+	if input.DryRun {
+		count, err := h.repo.Count()
+		if err != nil {
+			return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to count locations"))
+		}
+		return &TruncateLocationsResponse{Body: dto.TruncateResponse{DryRun: true, Count: count}}, nil
+	}
+
+	actor := auditActor(ctx)
+	audit, err := h.truncator.TruncateLocations(actor)
+	if err != nil {
+		return nil, apierrors.ToHuma(ctx, apierrors.InternalServerError("Failed to truncate locations"))
+	}
+	if h.publisher != nil {
+		for _, location := range audit.Removed {
+			if err := h.publisher.Publish(domain.NewEvent(domain.EventLocationDeleted, location)); err != nil {
+				log.Printf("Failed to publish %s event for %s: %v", domain.EventLocationDeleted, location.Name, err)
+			}
+		}
+	}
+	return &TruncateLocationsResponse{Body: dto.FromTruncateAudit(audit)}, nil
+}
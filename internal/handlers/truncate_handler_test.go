@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestTruncateLocations(t *testing.T) {
+	t.Parallel()
+	// Reads go through a cache, which must not answer with what was removed
+	repo := cache.NewCachedLocationRepository(memory.NewInMemoryLocationRepository(), time.Hour)
+	var deleted []string
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		if e.Type == domain.EventLocationDeleted {
+			deleted = append(deleted, e.Location.Name)
+		}
+		return nil
+	})
+	api := newTestAPI(t)
+	NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+	NewTruncateHandler(repo, repo, bus, "qa").RegisterRoutes(api)
+
+	api.Post("/locations", dto.LocationRequest{Name: "Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+	etag := api.Get("/locations/Yaba").Header().Get("ETag")
+
+	decode := func(body []byte) dto.TruncateResponse {
+		t.Helper()
+		var result dto.TruncateResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("Failed to decode truncate response: %v", err)
+		}
+		return result
+	}
+
+	for _, confirm := range []string{"production", "QA"} {
+		resp := api.Post("/admin/truncate", dto.TruncateRequest{Confirm: confirm})
+		if body := decodeCodedError(t, resp.Body.Bytes()); resp.Code != http.StatusUnprocessableEntity || body.Code != "TRUNCATE_NOT_CONFIRMED" {
+			t.Errorf("Expected 422 TRUNCATE_NOT_CONFIRMED for confirm %q, got %d %+v", confirm, resp.Code, body)
+		}
+	}
+	if resp := api.Post("/admin/truncate?dry_run=true", dto.TruncateRequest{Confirm: "staging"}); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a dry run to need the confirmation too, got %d", resp.Code)
+	}
+
+	resp := api.Post("/admin/truncate?dry_run=true", dto.TruncateRequest{Confirm: "qa"})
+	if result := decode(resp.Body.Bytes()); resp.Code != http.StatusOK || !result.DryRun || result.Count != 2 || result.ID != "" || result.TruncatedAt != nil {
+		t.Fatalf("Expected a dry run counting 2, got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Get("/locations/Yaba"); resp.Code != http.StatusOK {
+		t.Fatalf("Expected a dry run to remove nothing, got %d", resp.Code)
+	}
+
+	resp = api.Post("/admin/truncate", dto.TruncateRequest{Confirm: "qa"})
+	if result := decode(resp.Body.Bytes()); resp.Code != http.StatusOK || result.DryRun || result.Count != 2 || result.ID == "" || result.TruncatedAt == nil {
+		t.Fatalf("Expected 2 locations truncated with an audit entry, got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Get("/locations/Yaba"); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected the cached Yaba gone, got %d", resp.Code)
+	}
+	if !slices.Equal(deleted, []string{"Ikeja", "Yaba"}) {
+		t.Errorf("Expected a deletion event for each location, got %v", deleted)
+	}
+
+	// A location created afterwards takes a new ID and does not match the
+	// tag of the one it replaces
+	resp = api.Post("/locations", dto.LocationRequest{Name: "Yaba", Latitude: ptr(6.5095), Longitude: ptr(3.3711)})
+	var created dto.LocationResponse
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	if resp.Code != http.StatusCreated || created.ID != "3" {
+		t.Fatalf("Expected Yaba created with ID 3, got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := api.Get("/locations/Yaba", "If-None-Match: "+etag); resp.Code != http.StatusOK {
+		t.Errorf("Expected the ETag from before the truncation not to match, got %d", resp.Code)
+	}
+}
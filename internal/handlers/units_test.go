@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func setupUnitAPI(t *testing.T) humatest.TestAPI {
+	t.Helper()
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "mobile", Key: "mobile-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}},
+		{Name: "us-partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeWrite}, Profile: auth.Profile{Unit: dto.UnitMiles}},
+	})))
+	api.UseMiddleware(middleware.PreferredUnit)
+	NewLocationHandler(service.NewLocationService(memory.NewInMemoryLocationRepository())).RegisterRoutes(api)
+
+	api.Post("/locations", "X-API-Key: mobile-key", dto.LocationRequest{Name: "Leeta Ikeja", Latitude: ptr(6.6018), Longitude: ptr(3.3515)})
+	return api
+}
+
+func TestPreferredDistanceUnit(t *testing.T) {
+	t.Parallel()
+	api := setupUnitAPI(t)
+	distance := geospatial.HaversineDistance(
+		geospatial.Coordinate{Latitude: 6.45, Longitude: 3.47},
+		geospatial.Coordinate{Latitude: 6.6018, Longitude: 3.3515},
+	)
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-6 }
+
+	tests := []struct {
+		name  string
+		key   string
+		query string
+		unit  string
+		want  float64
+	}{
+		{name: "profile unit", key: "partner-key", unit: "mi", want: distance.Miles()},
+		{name: "no profile", key: "mobile-key", unit: "km", want: distance.Kilometers()},
+		{name: "parameter overrides profile", key: "partner-key", query: "&unit=m", unit: "m", want: distance.Meters()},
+		{name: "parameter without profile", key: "mobile-key", query: "&unit=nmi", unit: "nmi", want: distance.NauticalMiles()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := api.Get("/nearest?lat=6.45&lng=3.47"+tt.query, "X-API-Key: "+tt.key)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+			}
+			var nearest dto.NearestLocationResponse
+			json.Unmarshal(resp.Body.Bytes(), &nearest)
+			if nearest.Unit != tt.unit || !near(nearest.DistanceInUnit, tt.want) {
+				t.Errorf("Expected nearest distance %v %s, got %v %s", tt.want, tt.unit, nearest.DistanceInUnit, nearest.Unit)
+			}
+			// distance_km stays in kilometres whatever the unit
+			if !near(nearest.Distance.Kilometers(), distance.Kilometers()) {
+				t.Errorf("Expected distance_km %v, got %v", distance.Kilometers(), nearest.Distance.Kilometers())
+			}
+
+			resp = api.Get("/locations?ref_lat=6.45&ref_lng=3.47"+tt.query, "X-API-Key: "+tt.key)
+			var list dto.LocationListResponse
+			json.Unmarshal(resp.Body.Bytes(), &list)
+			if got := list.Locations[0].DistanceInUnit; list.Unit != tt.unit || got == nil || !near(*got, tt.want) {
+				t.Errorf("Expected listed distance %v %s, got %v %s", tt.want, tt.unit, got, list.Unit)
+			}
+
+			resp = api.Get("/locations/suggest?q=ikeja&lat=6.45&lng=3.47"+tt.query, "X-API-Key: "+tt.key)
+			var suggest dto.SuggestResponse
+			json.Unmarshal(resp.Body.Bytes(), &suggest)
+			if suggest.Unit != tt.unit || len(suggest.Suggestions) != 1 || !near(suggest.Suggestions[0].DistanceInUnit, tt.want) {
+				t.Errorf("Expected suggested distance %v %s, got %+v", tt.want, tt.unit, suggest)
+			}
+		})
+	}
+
+	t.Run("list without reference point", func(t *testing.T) {
+		resp := api.Get("/locations", "X-API-Key: partner-key")
+		var list dto.LocationListResponse
+		json.Unmarshal(resp.Body.Bytes(), &list)
+		if list.Unit != "" || list.Locations[0].DistanceInUnit != nil {
+			t.Errorf("Expected no unit or distance without a reference point, got %+v", list)
+		}
+	})
+
+	t.Run("matrix", func(t *testing.T) {
+		request := dto.DistanceMatrixRequest{
+			Origins:      []dto.MatrixPoint{{Latitude: ptr(6.45), Longitude: ptr(3.47)}},
+			Destinations: []dto.MatrixPoint{{Name: "Leeta Ikeja"}},
+		}
+		resp := api.Post("/distance/matrix", "X-API-Key: partner-key", request)
+		var matrix dto.DistanceMatrixResponse
+		json.Unmarshal(resp.Body.Bytes(), &matrix)
+		if matrix.Unit != "mi" || matrix.Distances[0][0] == nil || !near(*matrix.Distances[0][0], distance.Miles()) {
+			t.Errorf("Expected the matrix in the profile's miles, got %s", resp.Body.String())
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+const usageDayFormat = "2006-01-02"
+
+// UsageRequest represents the date range for a usage report
+type UsageRequest struct {
+	From string `query:"from" format:"date" doc:"First day of the report (YYYY-MM-DD), defaults to the start of the current month"`
+	To   string `query:"to" format:"date" doc:"Last day of the report (YYYY-MM-DD), defaults to today"`
+}
+
+// AdminUsageRequest represents the date range and optional key filter for the admin report
+type AdminUsageRequest struct {
+	UsageRequest
+	Key string `query:"key" doc:"Limit the report to one API key"`
+}
+
+// UsageResponse represents a usage report
+type UsageResponse struct {
+	Body dto.UsageResponse `json:"body"`
+}
+
+// UsageHandler exposes request usage accounting
+type UsageHandler struct {
+	service domain.UsageService
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(service domain.UsageService) *UsageHandler {
+	return &UsageHandler{service: service}
+}
+
+// RegisterRoutes registers the usage routes with the Huma API
+func (h *UsageHandler) RegisterRoutes(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-usage",
+		Method:      http.MethodGet,
+		Path:        "/usage",
+		Summary:     "Get Usage",
+		Description: "Daily request counts per operation for the calling API key",
+		Tags:        []string{"Usage"},
+	}, h.GetUsage)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-all-usage",
+		Method:      http.MethodGet,
+		Path:        "/admin/usage",
+		Summary:     "Get Usage For All Keys",
+		Description: "Daily request counts per operation for every API key",
+		Tags:        []string{"Admin"},
+	}, h.GetAllUsage)
+}
+
+// Middleware counts each authenticated request against its key and rejects
+// requests once the key's monthly quota is exhausted. Anonymous requests are not counted.
+func (h *UsageHandler) Middleware(ctx huma.Context, next func(huma.Context)) {
+	principal := auth.PrincipalFromContext(ctx.Context())
+	if principal == nil || ctx.Operation() == nil {
+		next(ctx)
+		return
+	}
+
+	err := h.service.Record(principal.ID, principal.Tenant, ctx.Operation().OperationID)
+	if errors.Is(err, domain.ErrQuotaExceeded) {
+		apierrors.RespondWithHumaError(ctx, apierrors.QuotaExceeded("Monthly request quota exceeded"))
+		return
+	}
+	if err != nil {
+		// Accounting failures should not take the API down with them
+		log.Printf("Failed to record usage for %s: %v", principal.ID, err)
+	}
+
+	next(ctx)
+}
+
+// GetUsage handles GET /usage requests
+func (h *UsageHandler) GetUsage(ctx context.Context, input *UsageRequest) (*UsageResponse, error) {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, huma.Error401Unauthorized("Usage is tracked per API key; authenticate to view it")
+	}
+
+	return h.report(principal.ID, input)
+}
+
+// GetAllUsage handles GET /admin/usage requests
+func (h *UsageHandler) GetAllUsage(ctx context.Context, input *AdminUsageRequest) (*UsageResponse, error) {
+	return h.report(input.Key, &input.UsageRequest)
+}
+
+func (h *UsageHandler) report(key string, input *UsageRequest) (*UsageResponse, error) {
+	now := time.Now().UTC()
+	from, _ := domain.UsageMonth(now)
+	to := domain.UsageDay(now)
+
+	var err error
+	if input.From != "" {
+		if from, err = time.Parse(usageDayFormat, input.From); err != nil {
+			return nil, huma.Error400BadRequest("from must be a date in YYYY-MM-DD format")
+		}
+	}
+	if input.To != "" {
+		if to, err = time.Parse(usageDayFormat, input.To); err != nil {
+			return nil, huma.Error400BadRequest("to must be a date in YYYY-MM-DD format")
+		}
+	}
+	if to.Before(from) {
+		return nil, huma.Error400BadRequest("to must not be before from")
+	}
+
+	records, err := h.service.Usage(key, from, to)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to retrieve usage")
+	}
+
+	return &UsageResponse{
+		Body: dto.FromUsageRecords(records, from.Format(usageDayFormat), to.Format(usageDayFormat)),
+	}, nil
+}
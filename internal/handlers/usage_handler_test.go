@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func setupUsageAPI(t *testing.T, quotas service.UsageQuotas) humatest.TestAPI {
+	locationService := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	usageService := service.NewUsageService(memory.NewInMemoryUsageRepository(), quotas, time.Hour)
+	usageHandler := NewUsageHandler(usageService)
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "partner", Key: "partner-key", Scopes: []auth.Scope{auth.ScopeRead}},
+		{Name: "ops", Key: "ops-key", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeAdmin}},
+	})))
+	api.UseMiddleware(usageHandler.Middleware)
+
+	NewLocationHandler(locationService).RegisterRoutes(api)
+	usageHandler.RegisterRoutes(api)
+
+	return api
+}
+
+func TestUsageQuotaExceeded(t *testing.T) {
+	api := setupUsageAPI(t, service.UsageQuotas{PerKey: map[string]int64{"partner": 2}})
+
+	for i := 0; i < 2; i++ {
+		if resp := api.Get("/locations", "X-API-Key: partner-key"); resp.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+		}
+	}
+
+	resp := api.Get("/locations", "X-API-Key: partner-key")
+	if resp.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, resp.Code)
+	}
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &body)
+	if body.Error.Code != "QUOTA_EXCEEDED" {
+		t.Errorf("Expected code QUOTA_EXCEEDED, got %s", body.Error.Code)
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	api := setupUsageAPI(t, service.UsageQuotas{})
+
+	api.Get("/locations", "X-API-Key: partner-key")
+	api.Get("/locations", "X-API-Key: partner-key")
+	api.Get("/locations", "X-API-Key: ops-key")
+
+	resp := api.Get("/usage", "X-API-Key: partner-key")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var usage dto.UsageResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &usage); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	// Two list calls plus the usage call itself
+	if usage.Total != 3 {
+		t.Errorf("Expected 3 requests for partner, got %d", usage.Total)
+	}
+	for _, r := range usage.Records {
+		if r.Key != "partner" {
+			t.Errorf("Expected only partner records, got %s", r.Key)
+		}
+	}
+
+	resp = api.Get("/admin/usage", "X-API-Key: ops-key")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	json.Unmarshal(resp.Body.Bytes(), &usage)
+	if usage.Total != 5 {
+		t.Errorf("Expected 5 requests across all keys, got %d", usage.Total)
+	}
+
+	if resp := api.Get("/admin/usage", "X-API-Key: partner-key"); resp.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for non-admin key, got %d", http.StatusForbidden, resp.Code)
+	}
+}
+
+func TestGetUsageInvalidRange(t *testing.T) {
+	api := setupUsageAPI(t, service.UsageQuotas{})
+
+	resp := api.Get("/usage?from=2025-08-10&to=2025-08-01", "X-API-Key: partner-key")
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, resp.Code)
+	}
+}
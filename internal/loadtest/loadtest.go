@@ -0,0 +1,321 @@
+// Package loadtest replays a seeded mix of nearest, list and create
+// requests against the location API in process and reports latency
+// percentiles per kind of request.
+//
+// Run seeds a memory repository straight through the repository, not the
+// API, so seeding 100k locations takes seconds, then serves the location
+// routes from it the way tests/ does, without the server's middleware.
+// The requests are drawn up front from the seed, so two runs with the
+// same Config send the same requests in the same order; only their
+// timing differs.
+//
+// With RPS set, request i is due at i/RPS after the start and its latency
+// is measured from when it was due, so time spent queued behind slow
+// requests counts against the handler instead of being hidden. Without
+// it, workers send back to back and latency is measured from sending.
+//
+// cmd/loadtest runs it from the command line; BenchmarkNearest100k tracks
+// the p99 of /nearest at 100k locations.
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/handlers"
+	"github.com/jesuloba-world/leeta-task/internal/locationgen"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// Kinds of request
+const (
+	OpNearest = "nearest"
+	OpList    = "list"
+	OpCreate  = "create"
+)
+
+// Mix weighs the kinds of request against each other; {8, 1, 1} sends
+// eight nearest requests for every list and every create
+type Mix struct {
+	Nearest int
+	List    int
+	Create  int
+}
+
+// DefaultMix is mostly reads, as production traffic is
+var DefaultMix = Mix{Nearest: 90, List: 8, Create: 2}
+
+// Config describes a run
+type Config struct {
+	// Data draws the seeded locations and the points /nearest is asked
+	// about; its Seed also fixes the request sequence
+	Data locationgen.Config
+	// Locations is the number seeded before the run
+	Locations int
+	// Requests is the number sent
+	Requests int
+	// Mix weighs the kinds of request, DefaultMix if zero
+	Mix Mix
+	// RPS paces the requests; 0 sends them as fast as Workers allow
+	RPS int
+	// Workers sends requests concurrently, GOMAXPROCS if 0
+	Workers int
+	// PageSize is the limit list requests ask for, 20 if 0
+	PageSize int
+}
+
+// Result summarizes the requests of one kind
+type Result struct {
+	Op string
+	// Count is the number sent and Errors those answered with a status
+	// other than 200 or 201
+	Count  int
+	Errors int
+	P50    time.Duration
+	P90    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// Report summarizes a run
+type Report struct {
+	// Results has one entry per kind sent, in the order nearest, list,
+	// create
+	Results []Result
+	// Seeding is how long seeding the repository took, and Elapsed how
+	// long the requests took
+	Seeding time.Duration
+	Elapsed time.Duration
+}
+
+// Result returns the result for op, or the zero Result if none was sent
+func (r *Report) Result(op string) Result {
+	for _, result := range r.Results {
+		if result.Op == op {
+			return result
+		}
+	}
+	return Result{Op: op}
+}
+
+// Write prints the report as a table
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tcount\terrors\tp50\tp90\tp99\tmax\t")
+	total := 0
+	for _, result := range r.Results {
+		total += result.Count
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%v\t%v\t%v\t%v\t\n", result.Op, result.Count, result.Errors,
+			result.P50, result.P90, result.P99, result.Max)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	rate := 0.0
+	if r.Elapsed > 0 {
+		rate = float64(total) / r.Elapsed.Seconds()
+	}
+	_, err := fmt.Fprintf(w, "seeded in %v, sent %d requests in %v (%.0f/s)\n",
+		r.Seeding.Round(time.Millisecond), total, r.Elapsed.Round(time.Millisecond), rate)
+	return err
+}
+
+// Seed saves n locations from gen straight into repo
+func Seed(repo domain.LocationRepository, gen *locationgen.Generator, n int) error {
+	for _, location := range gen.Locations(n) {
+		if err := repo.Save(&location); err != nil {
+			return fmt.Errorf("failed to seed %s: %w", location.Name, err)
+		}
+	}
+	return nil
+}
+
+// NewHandler serves the location routes from repo
+func NewHandler(repo domain.LocationRepository) http.Handler {
+	mux := http.NewServeMux()
+	api := humago.New(mux, huma.DefaultConfig("Load Test API", "1.0.0"))
+	handlers.NewLocationHandler(service.NewLocationService(repo)).RegisterRoutes(api)
+	return mux
+}
+
+// request is one request of a run
+type request struct {
+	op     string
+	method string
+	path   string
+	body   []byte
+}
+
+func (r request) send(h http.Handler) int {
+	req := httptest.NewRequest(r.method, r.path, bytes.NewReader(r.body))
+	if r.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// draw draws the requests of a run from gen, after the seeded
+// locations have been drawn from it
+func draw(gen *locationgen.Generator, cfg Config) []request {
+	mix := cfg.Mix
+	if mix == (Mix{}) {
+		mix = DefaultMix
+	}
+	pageSize := cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	total := mix.Nearest + mix.List + mix.Create
+	rng := rand.New(rand.NewPCG(cfg.Data.Seed, 0x72657173))
+
+	requests := make([]request, cfg.Requests)
+	for i := range requests {
+		switch pick := rng.IntN(total); {
+		case pick < mix.Nearest:
+			c := gen.Point()
+			requests[i] = request{op: OpNearest, method: http.MethodGet, path: fmt.Sprintf("/nearest?lat=%.6f&lng=%.6f", c.Latitude, c.Longitude)}
+		case pick < mix.Nearest+mix.List:
+			requests[i] = request{op: OpList, method: http.MethodGet, path: "/locations?limit=" + strconv.Itoa(pageSize)}
+		default:
+			location := gen.Locations(1)[0]
+			body, _ := json.Marshal(dto.LocationRequest{Name: location.Name, Latitude: &location.Latitude, Longitude: &location.Longitude})
+			requests[i] = request{op: OpCreate, method: http.MethodPost, path: "/locations", body: body}
+		}
+	}
+	return requests
+}
+
+// Run seeds a fresh memory repository and replays the requests of cfg
+// against it. It stops early, reporting what was sent, when ctx ends.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	gen := locationgen.New(cfg.Data)
+	repo := memory.NewInMemoryLocationRepository()
+	start := time.Now()
+	if err := Seed(repo, gen, cfg.Locations); err != nil {
+		return nil, err
+	}
+	report := &Report{Seeding: time.Since(start)}
+	report.Results, report.Elapsed = replay(ctx, NewHandler(repo), draw(gen, cfg), cfg.RPS, cfg.Workers)
+	return report, nil
+}
+
+// sample is the outcome of one request
+type sample struct {
+	latency time.Duration
+	failed  bool
+}
+
+// replay sends requests to h from workers, paced at rps when it is above
+// 0, and returns a Result for each kind sent and how long they took
+func replay(ctx context.Context, h http.Handler, requests []request, rps, workers int) ([]Result, time.Duration) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	samples := make([]sample, len(requests))
+	next := make(chan int)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				sent := time.Now()
+				code := requests[i].send(h)
+				from := sent
+				if rps > 0 {
+					from = start.Add(time.Duration(i) * time.Second / time.Duration(rps))
+				}
+				samples[i] = sample{latency: time.Since(from), failed: code != http.StatusOK && code != http.StatusCreated}
+			}
+		}()
+	}
+
+	sent := 0
+feed:
+	for i := range requests {
+		if rps > 0 {
+			due := start.Add(time.Duration(i) * time.Second / time.Duration(rps))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					break feed
+				}
+			}
+		}
+		select {
+		case next <- i:
+			sent++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	var results []Result
+	for _, op := range []string{OpNearest, OpList, OpCreate} {
+		var latencies []time.Duration
+		errors := 0
+		for i, s := range samples[:sent] {
+			if requests[i].op != op {
+				continue
+			}
+			latencies = append(latencies, s.latency)
+			if s.failed {
+				errors++
+			}
+		}
+		if len(latencies) > 0 {
+			results = append(results, summarize(op, latencies, errors))
+		}
+	}
+	return results, elapsed
+}
+
+func summarize(op string, latencies []time.Duration, errors int) Result {
+	slices.Sort(latencies)
+	return Result{
+		Op:     op,
+		Count:  len(latencies),
+		Errors: errors,
+		P50:    Percentile(latencies, 50),
+		P90:    Percentile(latencies, 90),
+		P99:    Percentile(latencies, 99),
+		Max:    latencies[len(latencies)-1],
+	}
+}
+
+// Percentile returns the nearest-rank pth percentile of sorted
+// latencies
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(len(sorted))*p/100)) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
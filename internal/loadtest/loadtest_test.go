@@ -0,0 +1,119 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/locationgen"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	cfg := Config{Data: locationgen.Config{Seed: 1}, Locations: 1000, Requests: 300, Mix: Mix{Nearest: 6, List: 2, Create: 2}, Workers: 4}
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	total := 0
+	for _, op := range []string{OpNearest, OpList, OpCreate} {
+		result := report.Result(op)
+		if result.Count == 0 || result.Errors != 0 {
+			t.Errorf("Expected %s requests without errors, got %+v", op, result)
+		}
+		if result.P50 > result.P90 || result.P90 > result.P99 || result.P99 > result.Max {
+			t.Errorf("Expected ordered percentiles for %s, got %+v", op, result)
+		}
+		total += result.Count
+	}
+	if total != cfg.Requests {
+		t.Errorf("Expected %d requests, got %d", cfg.Requests, total)
+	}
+
+	var out strings.Builder
+	report.Write(&out)
+	if !strings.Contains(out.String(), "nearest") || !strings.Contains(out.String(), "sent 300 requests") {
+		t.Errorf("Expected a table of results, got:\n%s", out.String())
+	}
+}
+
+func TestDrawIsDeterministic(t *testing.T) {
+	t.Parallel()
+	cfg := Config{Data: locationgen.Config{Seed: 5}, Requests: 200}
+	first := draw(locationgen.New(cfg.Data), cfg)
+	again := draw(locationgen.New(cfg.Data), cfg)
+	if !slices.EqualFunc(first, again, func(a, b request) bool {
+		return a.op == b.op && a.path == b.path && string(a.body) == string(b.body)
+	}) {
+		t.Errorf("Expected the same requests from the same seed")
+	}
+}
+
+func TestReplayPaces(t *testing.T) {
+	t.Parallel()
+	cfg := Config{Data: locationgen.Config{Seed: 2}, Requests: 20, Mix: Mix{Nearest: 1}}
+	gen := locationgen.New(cfg.Data)
+	repo := memory.NewInMemoryLocationRepository()
+	if err := Seed(repo, gen, 100); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	// 20 requests at 200 per second take at least 95ms
+	results, elapsed := replay(context.Background(), NewHandler(repo), draw(gen, cfg), 200, 2)
+	if elapsed < 95*time.Millisecond {
+		t.Errorf("Expected the requests paced over 95ms, took %v", elapsed)
+	}
+	if len(results) != 1 || results[0].Count != 20 {
+		t.Errorf("Expected 20 nearest requests, got %+v", results)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	for p, want := range map[float64]time.Duration{50: 50, 90: 90, 99: 99, 100: 100, 0: 1} {
+		if got := Percentile(sorted, p); got != want {
+			t.Errorf("Expected p%v of 1..100 to be %d, got %d", p, want, got)
+		}
+	}
+	if got := Percentile(nil, 99); got != 0 {
+		t.Errorf("Expected 0 for no latencies, got %v", got)
+	}
+}
+
+// BenchmarkNearest100k sends /nearest requests at 100k clustered
+// locations, one at a time, and reports their p99 as p99-ns/op next to
+// the mean. testdata/baseline.txt holds a run on one core of a Xeon;
+// compare a change against it on the same machine with
+//
+//	go test ./internal/loadtest -run '^$' -bench Nearest100k -benchtime 200x -count 6 > new.txt
+//	benchstat internal/loadtest/testdata/baseline.txt new.txt
+func BenchmarkNearest100k(b *testing.B) {
+	cfg := Config{Data: locationgen.Config{Seed: 1, Clusters: 40, Background: 0.05}, Mix: Mix{Nearest: 1}, Requests: 4096}
+	gen := locationgen.New(cfg.Data)
+	repo := memory.NewInMemoryLocationRepository()
+	if err := Seed(repo, gen, 100_000); err != nil {
+		b.Fatalf("Failed to seed: %v", err)
+	}
+	h := NewHandler(repo)
+	requests := draw(gen, cfg)
+
+	latencies := make([]time.Duration, 0, b.N)
+	b.ResetTimer()
+	for i := range b.N {
+		start := time.Now()
+		if code := requests[i%len(requests)].send(h); code != http.StatusOK {
+			b.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+		latencies = append(latencies, time.Since(start))
+	}
+	b.StopTimer()
+	slices.Sort(latencies)
+	b.ReportMetric(float64(Percentile(latencies, 99).Nanoseconds()), "p99-ns/op")
+}
@@ -0,0 +1,152 @@
+// Package locationgen generates seeded, reproducible sets of locations for
+// benchmarks and load tests. Real stations bunch up around cities, so most
+// locations are drawn around a number of cluster centres, a few clusters
+// much bigger than the rest, with some scattered evenly over the bounds in
+// between. The same Config always yields the same locations, in the same
+// order, on every machine.
+package locationgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// Nigeria bounds the country, the default area locations are drawn from
+var Nigeria = domain.BoundingBox{MinLatitude: 4.2, MinLongitude: 2.7, MaxLatitude: 13.9, MaxLongitude: 14.7}
+
+// kmPerDegree is the length of one degree of latitude
+const kmPerDegree = geospatial.EarthRadiusKm * math.Pi / 180
+
+// Config describes a dataset. The zero value, apart from Seed, draws
+// around 20 clusters 10 km across in Nigeria.
+type Config struct {
+	// Seed selects the dataset; the same seed gives the same locations
+	Seed uint64
+	// Clusters is the number of cluster centres, 20 if 0. Cluster k draws
+	// in proportion to 1/(k+1), so the first few hold most locations, as
+	// the biggest cities do.
+	Clusters int
+	// SpreadKm is the standard deviation of a location's distance from
+	// its cluster centre along each axis, 10 if 0
+	SpreadKm float64
+	// Background is the share of locations drawn evenly over Bounds
+	// instead of around a cluster, from 0 to 1
+	Background float64
+	// Bounds limits every location and centre, Nigeria if zero. Boxes
+	// crossing the antimeridian are supported.
+	Bounds domain.BoundingBox
+	// Prefix starts every name, followed by the location's number;
+	// "Station" if empty
+	Prefix string
+}
+
+// Generator draws locations for a Config. It is not safe for concurrent
+// use.
+type Generator struct {
+	cfg     Config
+	rng     *rand.Rand
+	centres []geospatial.Coordinate
+	// weights holds the cumulative draw weight of each cluster
+	weights []float64
+	next    int
+}
+
+// New draws the cluster centres for cfg
+func New(cfg Config) *Generator {
+	if cfg.Clusters <= 0 {
+		cfg.Clusters = 20
+	}
+	if cfg.SpreadKm <= 0 {
+		cfg.SpreadKm = 10
+	}
+	if cfg.Bounds == (domain.BoundingBox{}) {
+		cfg.Bounds = Nigeria
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "Station"
+	}
+	g := &Generator{cfg: cfg, rng: rand.New(rand.NewPCG(cfg.Seed, 0x6c6f63))}
+	total := 0.0
+	for k := range cfg.Clusters {
+		g.centres = append(g.centres, g.uniform())
+		total += 1 / float64(k+1)
+		g.weights = append(g.weights, total)
+	}
+	return g
+}
+
+// Centres returns the cluster centres, biggest cluster first
+func (g *Generator) Centres() []geospatial.Coordinate {
+	return append([]geospatial.Coordinate(nil), g.centres...)
+}
+
+// Point draws one coordinate the way locations are drawn
+func (g *Generator) Point() geospatial.Coordinate {
+	if g.rng.Float64() < g.cfg.Background {
+		return g.uniform()
+	}
+	pick := g.rng.Float64() * g.weights[len(g.weights)-1]
+	k := 0
+	for g.weights[k] < pick {
+		k++
+	}
+	centre := g.centres[k]
+	spread := g.cfg.SpreadKm / kmPerDegree
+	// Redraw points that fall outside the bounds rather than pile them up
+	// on the edge; a centre inside the bounds makes this end quickly
+	for {
+		latitude := centre.Latitude + g.rng.NormFloat64()*spread
+		longitude := wrap(centre.Longitude + g.rng.NormFloat64()*spread/math.Max(math.Cos(latitude*math.Pi/180), 0.01))
+		if latitude >= -90 && latitude <= 90 && g.cfg.Bounds.Contains(latitude, longitude) {
+			return geospatial.Coordinate{Latitude: latitude, Longitude: longitude}
+		}
+	}
+}
+
+// Locations draws the next n locations, named Prefix followed by their
+// number, counting on from the last call. They carry no ID.
+func (g *Generator) Locations(n int) []domain.Location {
+	locations := make([]domain.Location, n)
+	for i := range locations {
+		c := g.Point()
+		locations[i] = domain.Location{
+			Name:      fmt.Sprintf("%s %06d", g.cfg.Prefix, g.next),
+			Latitude:  c.Latitude,
+			Longitude: c.Longitude,
+		}
+		g.next++
+	}
+	return locations
+}
+
+// Generate draws n locations for cfg
+func Generate(cfg Config, n int) []domain.Location {
+	return New(cfg).Locations(n)
+}
+
+// uniform draws evenly by area over the bounds
+func (g *Generator) uniform() geospatial.Coordinate {
+	b := g.cfg.Bounds
+	low, high := math.Sin(b.MinLatitude*math.Pi/180), math.Sin(b.MaxLatitude*math.Pi/180)
+	latitude := math.Asin(low+g.rng.Float64()*(high-low)) * 180 / math.Pi
+	width := b.MaxLongitude - b.MinLongitude
+	if b.CrossesAntimeridian() {
+		width += 360
+	}
+	return geospatial.Coordinate{Latitude: latitude, Longitude: wrap(b.MinLongitude + g.rng.Float64()*width)}
+}
+
+// wrap brings a longitude back into [-180, 180]
+func wrap(longitude float64) float64 {
+	for longitude > 180 {
+		longitude -= 360
+	}
+	for longitude < -180 {
+		longitude += 360
+	}
+	return longitude
+}
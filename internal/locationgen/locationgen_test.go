@@ -0,0 +1,72 @@
+package locationgen
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func same(a, b domain.Location) bool {
+	return a.Name == b.Name && a.Latitude == b.Latitude && a.Longitude == b.Longitude
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	t.Parallel()
+	cfg := Config{Seed: 7, Clusters: 5, Background: 0.1}
+	first, again := Generate(cfg, 500), Generate(cfg, 500)
+	if !slices.EqualFunc(first, again, same) {
+		t.Fatalf("Expected the same locations from the same seed")
+	}
+	// Drawing in batches gives the same locations as drawing at once
+	gen := New(cfg)
+	if batched := append(gen.Locations(200), gen.Locations(300)...); !slices.EqualFunc(first, batched, same) {
+		t.Errorf("Expected batches to continue where the last one stopped")
+	}
+	if other := Generate(Config{Seed: 8, Clusters: 5, Background: 0.1}, 500); slices.EqualFunc(first, other, same) {
+		t.Errorf("Expected another seed to give other locations")
+	}
+	if first[0].Name != "Station 000000" || first[499].Name != "Station 000499" {
+		t.Errorf("Expected numbered names, got %s to %s", first[0].Name, first[499].Name)
+	}
+}
+
+func TestGenerateClusters(t *testing.T) {
+	t.Parallel()
+	bounds := domain.BoundingBox{MinLatitude: 6, MinLongitude: 2.7, MaxLatitude: 7, MaxLongitude: 4}
+	gen := New(Config{Seed: 1, Clusters: 4, SpreadKm: 2, Bounds: bounds})
+	centres := gen.Centres()
+	counts := make([]int, len(centres))
+	for _, location := range gen.Locations(4000) {
+		if !bounds.Contains(location.Latitude, location.Longitude) {
+			t.Fatalf("Expected %s inside the bounds, got (%f, %f)", location.Name, location.Latitude, location.Longitude)
+		}
+		c := geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude}
+		nearest, best := 0, geospatial.HaversineDistance(c, centres[0])
+		for k, centre := range centres[1:] {
+			if d := geospatial.HaversineDistance(c, centre); d < best {
+				nearest, best = k+1, d
+			}
+		}
+		// Five standard deviations along each axis
+		if best.Kilometers() > 5*2*1.5 {
+			t.Fatalf("Expected %s near a cluster centre, it is %v away", location.Name, best)
+		}
+		counts[nearest]++
+	}
+	// Weights 1, 1/2, 1/3 and 1/4 put 48% in the first cluster
+	if counts[0] < 1700 || counts[0] < counts[3]*2 {
+		t.Errorf("Expected the first cluster to be the biggest, got %v", counts)
+	}
+}
+
+func TestGenerateAcrossAntimeridian(t *testing.T) {
+	t.Parallel()
+	bounds := domain.BoundingBox{MinLatitude: -20, MinLongitude: 175, MaxLatitude: -15, MaxLongitude: -178}
+	for _, location := range Generate(Config{Seed: 3, Background: 0.5, SpreadKm: 50, Bounds: bounds}, 1000) {
+		if !bounds.Contains(location.Latitude, location.Longitude) {
+			t.Fatalf("Expected %s inside the bounds, got (%f, %f)", location.Name, location.Latitude, location.Longitude)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+var (
+	cacheLookupsDesc = prometheus.NewDesc("location_cache_lookups_total",
+		"Number of location cache lookups by result: fresh, stale or miss", []string{"result"}, nil)
+	cacheRefreshesDesc = prometheus.NewDesc("location_cache_refreshes_total",
+		"Number of background refreshes of stale location cache entries by result: success or failure", []string{"result"}, nil)
+)
+
+// cacheCollector reads the cache's stats on every scrape
+type cacheCollector struct {
+	cache domain.CacheStatsReporter
+}
+
+func (c cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheLookupsDesc
+	ch <- cacheRefreshesDesc
+}
+
+func (c cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.CacheStats()
+	ch <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(stats.FreshHits), "fresh")
+	ch <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(stats.StaleHits), "stale")
+	ch <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(stats.Misses), "miss")
+	ch <- prometheus.MustNewConstMetric(cacheRefreshesDesc, prometheus.CounterValue, float64(stats.Refreshes), "success")
+	ch <- prometheus.MustNewConstMetric(cacheRefreshesDesc, prometheus.CounterValue, float64(stats.RefreshErrors), "failure")
+}
+
+var (
+	cacheMu        sync.Mutex
+	cacheCollected prometheus.Collector
+)
+
+// RegisterCache exports cache's stats from Registry, replacing the cache
+// registered before, like RegisterStore
+func RegisterCache(cache domain.CacheStatsReporter) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if cacheCollected != nil {
+		Registry.Unregister(cacheCollected)
+	}
+	cacheCollected = cacheCollector{cache: cache}
+	Registry.MustRegister(cacheCollected)
+}
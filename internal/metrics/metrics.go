@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric the service exports
+var Registry = prometheus.NewRegistry()
+
+var (
+	OutboxLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_dispatcher_lag_seconds",
+		Help: "Age of the oldest undelivered outbox event",
+	})
+	OutboxPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_pending_events",
+		Help: "Number of outbox events awaiting delivery",
+	})
+	OutboxFailed = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_failed_events",
+		Help: "Number of outbox events that exhausted their delivery attempts",
+	})
+	OutboxDelivered = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_delivered_events_total",
+		Help: "Number of outbox events delivered",
+	})
+
+	LocationsTotal = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "locations_total",
+		Help: "Number of stored locations as of the last stats refresh",
+	})
+	LocationStatsStale = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "location_stats_stale",
+		Help: "1 when the last stats refresh failed and dataset gauges are outdated",
+	})
+	LocationStatsRefreshed = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "location_stats_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful stats refresh",
+	})
+
+	SchedulerJobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduler_job_runs_total",
+		Help: "Number of background job runs by result: success, failure or timeout",
+	}, []string{"job", "result"})
+	SchedulerJobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "scheduler_job_duration_seconds",
+		Help: "Duration of background job runs",
+	}, []string{"job"})
+	SchedulerJobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduler_job_last_success_timestamp_seconds",
+		Help: "Unix time of each background job's last successful run",
+	}, []string{"job"})
+
+	ListFallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "location_list_fallbacks_total",
+		Help: "Location listings the repository failed, by result: served from the last known good listing, or unavailable when none was recent enough",
+	}, []string{"result"})
+
+	ReplicaFailovers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_replica_failovers_total",
+		Help: "Times location reads moved between the read replica and the primary, by where they went: primary or replica",
+	}, []string{"to"})
+	ReplicaLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "db_replica_lag_seconds",
+		Help: "How far the read replica was behind the primary at the last check",
+	})
+
+	FaultsInjected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repository_faults_injected_total",
+		Help: "Faults injected into location repository calls by method and fault: an error kind or latency",
+	}, []string{"method", "fault"})
+	FaultErrorRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "repository_fault_error_rate",
+		Help: "Share of calls to each location repository method that fault injection fails",
+	}, []string{"method"})
+	FaultLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "repository_fault_latency_seconds",
+		Help: "Latency fault injection adds to each location repository method",
+	}, []string{"method"})
+
+	DeprecatedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_deprecated_requests_total",
+		Help: "Requests to deprecated operations by operation and client: the API key's principal, or anonymous",
+	}, []string{"operation", "client"})
+
+	EventsExported = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_exported_total",
+		Help: "Number of change events exported to the event backend by result: success or failure",
+	}, []string{"backend", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		OutboxLag,
+		OutboxPending,
+		OutboxFailed,
+		OutboxDelivered,
+		LocationsTotal,
+		LocationStatsStale,
+		LocationStatsRefreshed,
+		SchedulerJobRuns,
+		SchedulerJobDuration,
+		SchedulerJobLastSuccess,
+		ListFallbacks,
+		ReplicaFailovers,
+		ReplicaLag,
+		FaultsInjected,
+		FaultErrorRate,
+		FaultLatency,
+		DeprecatedRequests,
+		EventsExported,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
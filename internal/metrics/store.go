@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// bucketSizeBounds are the histogram bounds for index bucket sizes
+var bucketSizeBounds = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 5000, 10000}
+
+var (
+	storeLocationsDesc = prometheus.NewDesc("memory_store_locations",
+		"Number of locations in the in-memory store", nil, nil)
+	storeBytesDesc = prometheus.NewDesc("memory_store_estimated_bytes",
+		"Estimated memory taken by the in-memory store's locations and primary indexes", nil, nil)
+	storeBucketsDesc = prometheus.NewDesc("memory_store_index_bucket_size",
+		"Number of locations per bucket of each in-memory index", []string{"index"}, nil)
+	storeOperationsDesc = prometheus.NewDesc("memory_store_operation_duration_seconds",
+		"Duration of in-memory store writes", []string{"operation"}, nil)
+	storeLockWaitDesc = prometheus.NewDesc("memory_store_lock_wait_seconds",
+		"Time a sample of callers waited for the in-memory store's lock", []string{"mode"}, nil)
+	storeRemovedDesc = prometheus.NewDesc("memory_store_removed_entries",
+		"Locations removed from the in-memory store since its indexes were last rebuilt", nil, nil)
+	storeCompactionDesc = prometheus.NewDesc("memory_store_compaction_duration_seconds",
+		"Duration of in-memory store index rebuilds", nil, nil)
+	storeReclaimedDesc = prometheus.NewDesc("memory_store_compaction_reclaimed_total",
+		"Removed entries cleared from the in-memory store's indexes by rebuilds", nil, nil)
+)
+
+// storeCollector reads the store's stats on every scrape
+type storeCollector struct {
+	store domain.StoreStatsReporter
+}
+
+func (c storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storeLocationsDesc
+	ch <- storeBytesDesc
+	ch <- storeBucketsDesc
+	ch <- storeOperationsDesc
+	ch <- storeLockWaitDesc
+	ch <- storeRemovedDesc
+	ch <- storeCompactionDesc
+	ch <- storeReclaimedDesc
+}
+
+func (c storeCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.store.StoreStats()
+	ch <- prometheus.MustNewConstMetric(storeLocationsDesc, prometheus.GaugeValue, float64(stats.Locations))
+	ch <- prometheus.MustNewConstMetric(storeBytesDesc, prometheus.GaugeValue, float64(stats.EstimatedBytes))
+
+	for _, index := range stats.Indexes {
+		counts := make(map[float64]uint64, len(bucketSizeBounds))
+		sum := 0
+		for _, size := range index.Sizes {
+			sum += size
+			for _, bound := range bucketSizeBounds {
+				if float64(size) <= bound {
+					counts[bound]++
+				}
+			}
+		}
+		ch <- prometheus.MustNewConstHistogram(storeBucketsDesc, uint64(len(index.Sizes)), float64(sum), counts, index.Name)
+	}
+
+	for label, op := range map[string]domain.OperationStats{"save": stats.Saves, "delete": stats.Deletes} {
+		ch <- prometheus.MustNewConstSummary(storeOperationsDesc, op.Count, op.Total.Seconds(), nil, label)
+	}
+	for label, op := range map[string]domain.OperationStats{"read": stats.ReadLockWaits, "write": stats.WriteLockWaits} {
+		ch <- prometheus.MustNewConstSummary(storeLockWaitDesc, op.Count, op.Total.Seconds(), nil, label)
+	}
+	ch <- prometheus.MustNewConstMetric(storeRemovedDesc, prometheus.GaugeValue, float64(stats.Removed))
+	ch <- prometheus.MustNewConstSummary(storeCompactionDesc, stats.Compactions.Count, stats.Compactions.Total.Seconds(), nil)
+	ch <- prometheus.MustNewConstMetric(storeReclaimedDesc, prometheus.CounterValue, float64(stats.Reclaimed))
+}
+
+var (
+	storeMu        sync.Mutex
+	storeCollected prometheus.Collector
+)
+
+// RegisterStore exports store's stats from Registry, replacing the store
+// registered before, so a server built again reports its own store
+func RegisterStore(store domain.StoreStatsReporter) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if storeCollected != nil {
+		Registry.Unregister(storeCollected)
+	}
+	storeCollected = storeCollector{store: store}
+	Registry.MustRegister(storeCollected)
+}
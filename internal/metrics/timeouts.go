@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+var (
+	timeoutDesc = prometheus.NewDesc("repository_adaptive_timeout_seconds",
+		"Timeout the next call to each location repository read is allowed", []string{"method"}, nil)
+	timeoutP99Desc = prometheus.NewDesc("repository_latency_p99_seconds",
+		"99th percentile latency of each location repository read over the adaptive timeout window", []string{"method"}, nil)
+	timeoutsDesc = prometheus.NewDesc("repository_timeouts_total",
+		"Location repository reads cut off by their adaptive timeout", []string{"method"}, nil)
+)
+
+// timeoutCollector reads the adaptive timeouts on every scrape
+type timeoutCollector struct {
+	timeouts domain.TimeoutReporter
+}
+
+func (c timeoutCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- timeoutDesc
+	ch <- timeoutP99Desc
+	ch <- timeoutsDesc
+}
+
+func (c timeoutCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range c.timeouts.TimeoutStats() {
+		ch <- prometheus.MustNewConstMetric(timeoutDesc, prometheus.GaugeValue, stats.Timeout.Seconds(), stats.Method)
+		ch <- prometheus.MustNewConstMetric(timeoutP99Desc, prometheus.GaugeValue, stats.P99.Seconds(), stats.Method)
+		ch <- prometheus.MustNewConstMetric(timeoutsDesc, prometheus.CounterValue, float64(stats.TimedOut), stats.Method)
+	}
+}
+
+var (
+	timeoutMu        sync.Mutex
+	timeoutCollected prometheus.Collector
+)
+
+// RegisterTimeouts exports the adaptive timeouts from Registry, replacing
+// those registered before, like RegisterStore
+func RegisterTimeouts(timeouts domain.TimeoutReporter) {
+	timeoutMu.Lock()
+	defer timeoutMu.Unlock()
+	if timeoutCollected != nil {
+		Registry.Unregister(timeoutCollected)
+	}
+	timeoutCollected = timeoutCollector{timeouts: timeouts}
+	Registry.MustRegister(timeoutCollected)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// noStore is sent wherever no cache policy applies
+const noStore = "no-store"
+
+// CacheControl sets the Cache-Control header from policies, keyed by
+// operation ID. Successful GET and HEAD responses and 304s carry the
+// operation's policy, so a revalidated response is cached as long as the
+// original. Mutations, errors and operations without a policy are sent
+// no-store, so a CDN never keeps a failure or a write.
+//
+// perCaller says responses depend on the caller's credentials, as they do
+// when coordinate privacy or authentication is on. Cached responses are
+// then marked private unless their policy is explicitly public, and vary
+// on the credential headers, so a shared cache never hands one caller's
+// answer to another.
+func CacheControl(policies map[string]string, perCaller bool) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		policy := noStore
+		if op := ctx.Operation(); op != nil && (op.Method == http.MethodGet || op.Method == http.MethodHead) {
+			if configured, ok := policies[op.OperationID]; ok {
+				policy = configured
+			}
+		}
+		if perCaller && policy != noStore && !hasDirective(policy, "public") && !hasDirective(policy, "private") {
+			policy = "private, " + policy
+		}
+		next(&cacheControlContext{humaContext: ctx, policy: policy, perCaller: perCaller})
+	}
+}
+
+// hasDirective reports whether the normalised policy includes directive
+func hasDirective(policy, directive string) bool {
+	for _, part := range strings.Split(policy, ", ") {
+		if part == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// humaContext is embedded under this name because huma.Context has a
+// Context method, which the field would shadow
+type humaContext = huma.Context
+
+// cacheControlContext sets the header when the status is known, which is
+// before any of the response is written
+type cacheControlContext struct {
+	humaContext
+	policy    string
+	perCaller bool
+}
+
+func (c *cacheControlContext) SetStatus(code int) {
+	value := c.policy
+	if code != http.StatusNotModified && (code < 200 || code >= 300) {
+		value = noStore
+	}
+	c.humaContext.SetHeader("Cache-Control", value)
+	if c.perCaller && value != noStore {
+		c.humaContext.AppendHeader("Vary", "Authorization, X-API-Key")
+	}
+	c.humaContext.SetStatus(code)
+}
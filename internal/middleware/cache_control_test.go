@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+type cacheTestOutput struct {
+	Body struct {
+		OK bool `json:"ok"`
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	_, api := humatest.New(t)
+	api.UseMiddleware(CacheControl(map[string]string{
+		"cached":     "max-age=30",
+		"failing":    "max-age=30",
+		"revalidate": "max-age=30",
+		"create":     "max-age=30",
+	}, false))
+
+	ok := func(ctx context.Context, _ *struct{}) (*cacheTestOutput, error) {
+		return &cacheTestOutput{}, nil
+	}
+	huma.Register(api, huma.Operation{OperationID: "cached", Method: http.MethodGet, Path: "/cached"}, ok)
+	huma.Register(api, huma.Operation{OperationID: "uncached", Method: http.MethodGet, Path: "/uncached"}, ok)
+	huma.Register(api, huma.Operation{OperationID: "create", Method: http.MethodPost, Path: "/create"}, ok)
+	huma.Register(api, huma.Operation{OperationID: "failing", Method: http.MethodGet, Path: "/failing"},
+		func(ctx context.Context, _ *struct{}) (*cacheTestOutput, error) {
+			return nil, huma.Error404NotFound("missing")
+		})
+	huma.Register(api, huma.Operation{OperationID: "revalidate", Method: http.MethodGet, Path: "/revalidate"},
+		func(ctx context.Context, _ *struct{}) (*cacheTestOutput, error) {
+			return nil, huma.Status304NotModified()
+		})
+
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{http.MethodGet, "/cached", "max-age=30"},
+		{http.MethodGet, "/uncached", "no-store"},
+		{http.MethodPost, "/create", "no-store"},
+		{http.MethodGet, "/failing", "no-store"},
+		{http.MethodGet, "/revalidate", "max-age=30"},
+	}
+	for _, tt := range tests {
+		resp := api.Do(tt.method, tt.path)
+		if got := resp.Header().Get("Cache-Control"); got != tt.expected {
+			t.Errorf("%s %s (status %d): expected Cache-Control %q, got %q", tt.method, tt.path, resp.Code, tt.expected, got)
+		}
+	}
+}
+
+func TestCacheControlPerCaller(t *testing.T) {
+	_, api := humatest.New(t)
+	api.UseMiddleware(CacheControl(map[string]string{
+		"cached":  "max-age=30",
+		"shared":  "public, max-age=30, s-maxage=120",
+		"private": "private, max-age=30",
+	}, true))
+
+	ok := func(ctx context.Context, _ *struct{}) (*cacheTestOutput, error) {
+		return &cacheTestOutput{}, nil
+	}
+	for _, id := range []string{"cached", "shared", "private", "uncached"} {
+		huma.Register(api, huma.Operation{OperationID: id, Method: http.MethodGet, Path: "/" + id}, ok)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+		vary     string
+	}{
+		{"/cached", "private, max-age=30", "Authorization, X-API-Key"},
+		{"/shared", "public, max-age=30, s-maxage=120", "Authorization, X-API-Key"},
+		{"/private", "private, max-age=30", "Authorization, X-API-Key"},
+		{"/uncached", "no-store", ""},
+	}
+	for _, tt := range tests {
+		resp := api.Get(tt.path)
+		if got := resp.Header().Get("Cache-Control"); got != tt.expected {
+			t.Errorf("%s: expected Cache-Control %q, got %q", tt.path, tt.expected, got)
+		}
+		if got := resp.Header().Get("Vary"); got != tt.vary {
+			t.Errorf("%s: expected Vary %q, got %q", tt.path, tt.vary, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// ClassRateLimiter limits each client per class of operation, with a
+// RateLimiter per class, so a client that exhausts one class, say with
+// exports, keeps its whole budget for the others
+type ClassRateLimiter struct {
+	operations map[string]string
+	limiters   map[string]*RateLimiter
+	now        func() time.Time
+}
+
+// NewClassRateLimiter creates a limiter for the limited classes
+func NewClassRateLimiter(limits config.RateLimits) *ClassRateLimiter {
+	l := &ClassRateLimiter{operations: limits.Operations, limiters: make(map[string]*RateLimiter, len(limits.Classes)), now: time.Now}
+	for class, limit := range limits.Classes {
+		limiter := NewBurstRateLimiter(limit.RPS, limit.Burst)
+		limiter.now = func() time.Time { return l.now() }
+		l.limiters[class] = limiter
+	}
+	return l
+}
+
+// Class returns the class of an operation
+func (l *ClassRateLimiter) Class(op *huma.Operation) string {
+	if class, ok := l.operations[op.OperationID]; ok {
+		return class
+	}
+	if op.Method == http.MethodGet || op.Method == http.MethodHead {
+		return config.RateLimitRead
+	}
+	return config.RateLimitMutation
+}
+
+// Middleware answers 429 with CLASS_RATE_LIMITED, naming the class and when
+// its next request is allowed, once the caller has no tokens left in the
+// operation's class. Callers are told apart by principal, or by IP when
+// anonymous, so it must run after auth.Middleware and inside
+// ClientIPResolver.Middleware.
+func (l *ClassRateLimiter) Middleware(ctx huma.Context, next func(huma.Context)) {
+	op := ctx.Operation()
+	if op == nil {
+		next(ctx)
+		return
+	}
+	class := l.Class(op)
+	limiter, ok := l.limiters[class]
+	if !ok {
+		next(ctx)
+		return
+	}
+
+	client := "ip:" + ClientIPFromContext(ctx.Context())
+	if principal := auth.PrincipalFromContext(ctx.Context()); principal != nil {
+		client = "key:" + principal.ID
+	}
+	if ok, wait := limiter.Allow(client); !ok {
+		// Rounded up to the second, as Retry-After is
+		reset := l.now().Add(wait).UTC()
+		if whole := reset.Truncate(time.Second); whole.Before(reset) {
+			reset = whole.Add(time.Second)
+		}
+		ctx.SetHeader("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		ctx.SetHeader("X-RateLimit-Class", class)
+		ctx.SetHeader("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		apierrors.RespondWithHumaError(ctx, apierrors.ClassRateLimited(
+			"Too many "+class+" requests; try again after "+reset.Format(time.RFC3339)).
+			With("class", class).With("reset", reset.Format(time.RFC3339)))
+		return
+	}
+	next(ctx)
+}
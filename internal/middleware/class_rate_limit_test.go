@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
+)
+
+func TestClassRateLimiterIsolatesClasses(t *testing.T) {
+	limits, err := config.RateLimitConfig{Classes: map[string]string{
+		"bulk":    "rps=0.1,burst=2",
+		"nearest": "rps=5,burst=5",
+	}}.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	now := time.Date(2025, 9, 6, 9, 0, 0, 0, time.UTC)
+	limiter := NewClassRateLimiter(limits)
+	limiter.now = func() time.Time { return now }
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "fleet-app", Key: "key", Scopes: []auth.Scope{auth.ScopeRead}},
+		{Name: "tooling", Key: "other", Scopes: []auth.Scope{auth.ScopeRead}},
+	})))
+	api.UseMiddleware(limiter.Middleware)
+	ok := func(ctx context.Context, _ *struct{}) (*struct{}, error) { return nil, nil }
+	huma.Register(api, huma.Operation{OperationID: "export-locations", Method: http.MethodGet, Path: "/export"}, ok)
+	huma.Register(api, huma.Operation{OperationID: "find-nearest", Method: http.MethodGet, Path: "/nearest"}, ok)
+	huma.Register(api, huma.Operation{OperationID: "get-locations", Method: http.MethodGet, Path: "/locations"}, ok)
+
+	// Mixed traffic from one key: exports interleaved with nearest lookups
+	for i := range 5 {
+		want := http.StatusNoContent
+		if i >= 2 {
+			want = http.StatusTooManyRequests
+		}
+		if export := api.Get("/export", "X-API-Key: key"); export.Code != want {
+			t.Fatalf("Export %d: expected %d, got %d", i+1, want, export.Code)
+		}
+		if nearest := api.Get("/nearest", "X-API-Key: key"); nearest.Code != http.StatusNoContent {
+			t.Fatalf("Nearest %d: expected the export class not to touch it, got %d", i+1, nearest.Code)
+		}
+	}
+
+	refused := api.Get("/export", "X-API-Key: key")
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	json.Unmarshal(refused.Body.Bytes(), &body)
+	if body.Error.Code != "CLASS_RATE_LIMITED" || body.Error.Message != "Too many bulk requests; try again after 2025-09-06T09:00:10Z" {
+		t.Errorf("Expected the class and reset time named, got %+v", body.Error)
+	}
+	if refused.Header().Get("X-RateLimit-Class") != "bulk" || refused.Header().Get("Retry-After") != "10" ||
+		refused.Header().Get("X-RateLimit-Reset") != "1757149210" {
+		t.Errorf("Expected the class and reset headers, got %v", refused.Header())
+	}
+
+	// Another key has buckets of its own, and unlimited classes pass
+	if resp := api.Get("/export", "X-API-Key: other"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected another key unaffected, got %d", resp.Code)
+	}
+	for range 10 {
+		if resp := api.Get("/locations", "X-API-Key: key"); resp.Code != http.StatusNoContent {
+			t.Fatalf("Expected the unlimited read class to pass, got %d", resp.Code)
+		}
+	}
+
+	// A token comes back every 10 seconds
+	now = now.Add(10 * time.Second)
+	if resp := api.Get("/export", "X-API-Key: key"); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected an export after the reset, got %d", resp.Code)
+	}
+}
+
+func TestClassRateLimiterClasses(t *testing.T) {
+	limits, err := config.RateLimitConfig{Operations: map[string]string{"get-location": "nearest"}}.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	limiter := NewClassRateLimiter(limits)
+	for _, tt := range []struct {
+		op   huma.Operation
+		want string
+	}{
+		{huma.Operation{OperationID: "get-location", Method: http.MethodGet}, "nearest"},
+		{huma.Operation{OperationID: "get-locations", Method: http.MethodGet}, "read"},
+		{huma.Operation{OperationID: "create-location", Method: http.MethodPost}, "mutation"},
+		{huma.Operation{OperationID: "import-locations", Method: http.MethodPost}, "bulk"},
+		{huma.Operation{OperationID: "readiness-check", Method: http.MethodGet}, "none"},
+	} {
+		if got := limiter.Class(&tt.op); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.op.OperationID, tt.want, got)
+		}
+	}
+}
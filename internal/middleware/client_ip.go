@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+type trustedPeerKey struct{}
+
+// ClientIPResolver resolves the originating client address for a request,
+// trusting forwarding headers only when they were added by a known proxy
+type ClientIPResolver struct {
+	trusted []netip.Prefix
+}
+
+// NewClientIPResolver creates a resolver trusting the given proxy prefixes
+func NewClientIPResolver(trusted []netip.Prefix) *ClientIPResolver {
+	return &ClientIPResolver{trusted: trusted}
+}
+
+// Middleware stores the resolved client IP in the request context, and
+// whether the request came from a trusted proxy
+func (c *ClientIPResolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithClientIP(r.Context(), c.Resolve(r))
+		ctx = WithTrustedPeer(ctx, c.TrustsPeer(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TrustsPeer reports whether the request came straight from a trusted
+// proxy, so the forwarding headers it carries can be believed
+func (c *ClientIPResolver) TrustsPeer(r *http.Request) bool {
+	peer, ok := parseAddr(r.RemoteAddr)
+	return ok && c.isTrusted(peer)
+}
+
+// Resolve returns the client IP for the request. X-Forwarded-For is walked
+// from the right, skipping trusted hops, so the first untrusted address wins.
+// X-Real-IP is consulted only when X-Forwarded-For is absent.
+func (c *ClientIPResolver) Resolve(r *http.Request) string {
+	peer, ok := parseAddr(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !c.isTrusted(peer) {
+		return peer.String()
+	}
+
+	if hops := forwardedFor(r.Header); len(hops) > 0 {
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, ok := parseAddr(hops[i])
+			if !ok {
+				break
+			}
+			client = hop
+			if !c.isTrusted(hop) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if realIP, ok := parseAddr(r.Header.Get("X-Real-IP")); ok {
+		return realIP.String()
+	}
+
+	return peer.String()
+}
+
+func (c *ClientIPResolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range c.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithClientIP returns a copy of ctx carrying the client IP
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP stored by the middleware, if any
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// WithTrustedPeer returns a copy of ctx recording whether the request came
+// from a trusted proxy
+func WithTrustedPeer(ctx context.Context, trusted bool) context.Context {
+	return context.WithValue(ctx, trustedPeerKey{}, trusted)
+}
+
+// FromTrustedPeer reports whether the middleware found the request came
+// from a trusted proxy; false when it did not run
+func FromTrustedPeer(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedPeerKey{}).(bool)
+	return trusted
+}
+
+func forwardedFor(header http.Header) []string {
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// parseAddr accepts bare addresses as well as host:port and [v6]:port forms
+func parseAddr(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return netip.Addr{}, false
+	}
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.WithZone("").Unmap(), true
+}
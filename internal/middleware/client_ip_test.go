@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func mustPrefixes(t *testing.T, values ...string) []netip.Prefix {
+	t.Helper()
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		prefixes = append(prefixes, netip.MustParsePrefix(v))
+	}
+	return prefixes
+}
+
+func TestClientIPResolver(t *testing.T) {
+	resolver := NewClientIPResolver(mustPrefixes(t, "10.0.0.0/8", "192.168.1.0/24", "fd00::/8"))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{
+			name:       "direct connection",
+			remoteAddr: "203.0.113.7:51234",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "one proxy hop",
+			remoteAddr: "10.0.0.2:443",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "two proxy hops",
+			remoteAddr: "10.0.0.2:443",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7, 192.168.1.10"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "client-supplied entry left of the real client is ignored",
+			remoteAddr: "10.0.0.2:443",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.7, 192.168.1.10"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer forging headers",
+			remoteAddr: "198.51.100.9:40000",
+			headers: map[string]string{
+				"X-Forwarded-For": "1.2.3.4",
+				"X-Real-IP":       "1.2.3.4",
+			},
+			expected: "198.51.100.9",
+		},
+		{
+			name:       "x-real-ip from trusted proxy",
+			remoteAddr: "10.0.0.2:443",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.7"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "ipv6 direct connection",
+			remoteAddr: "[2001:db8::1]:8080",
+			expected:   "2001:db8::1",
+		},
+		{
+			name:       "ipv6 behind ipv6 proxy",
+			remoteAddr: "[fd00::2]:443",
+			headers:    map[string]string{"X-Forwarded-For": "2001:db8::beef"},
+			expected:   "2001:db8::beef",
+		},
+		{
+			name:       "garbage hop stops the walk",
+			remoteAddr: "10.0.0.2:443",
+			headers:    map[string]string{"X-Forwarded-For": "not-an-ip, 10.0.0.3"},
+			expected:   "10.0.0.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			if got := resolver.Resolve(req); got != tt.expected {
+				t.Errorf("Expected client IP %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClientIPMiddlewareStoresIP(t *testing.T) {
+	resolver := NewClientIPResolver(mustPrefixes(t, "10.0.0.0/8"))
+
+	var got string
+	var trusted bool
+	handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClientIPFromContext(r.Context())
+		trusted = FromTrustedPeer(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "203.0.113.7" || !trusted {
+		t.Errorf("Expected client IP 203.0.113.7 from a trusted proxy in context, got %s (trusted %t)", got, trusted)
+	}
+
+	req.RemoteAddr = "198.51.100.9:40000"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "198.51.100.9" || trusted {
+		t.Errorf("Expected client IP 198.51.100.9 from an untrusted peer in context, got %s (trusted %t)", got, trusted)
+	}
+}
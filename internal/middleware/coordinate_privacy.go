@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// CoordinatePrivacy marks requests whose responses should show obscured
+// coordinates: every request, unless the caller holds the exact scope. It
+// must run after auth.Middleware so the caller is known.
+func CoordinatePrivacy(privacy dto.CoordinatePrivacy) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if !privacy.Enabled() || auth.PrincipalFromContext(ctx.Context()).HasScope(auth.ScopeExact) {
+			next(ctx)
+			return
+		}
+		next(huma.WithContext(ctx, dto.WithCoordinatePrivacy(ctx.Context(), privacy)))
+	}
+}
+
+// ObscureCoordinates is a huma.Transformer that applies the privacy
+// CoordinatePrivacy chose to location responses
+func ObscureCoordinates(ctx huma.Context, status string, v any) (any, error) {
+	return dto.ObscureCoordinates(ctx.Context(), v), nil
+}
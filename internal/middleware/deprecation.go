@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+// Deprecation marks every response of the deprecated operations with a
+// Deprecation header, the Sunset date and a Link to the successor when
+// known, and counts the requests by caller so they can be chased before
+// the sunset. It must run after auth.Middleware so the caller is known.
+func Deprecation(deprecations map[string]config.Deprecation) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		if op == nil {
+			next(ctx)
+			return
+		}
+		deprecation, ok := deprecations[op.OperationID]
+		if !ok {
+			next(ctx)
+			return
+		}
+		client := "anonymous"
+		if principal := auth.PrincipalFromContext(ctx.Context()); principal != nil {
+			client = principal.ID
+		}
+		metrics.DeprecatedRequests.WithLabelValues(op.OperationID, client).Inc()
+		next(&deprecationContext{humaContext: ctx, deprecation: deprecation})
+	}
+}
+
+// deprecationContext adds the headers when the status is known, after
+// the handler's own, so a Link header from pagination is kept
+type deprecationContext struct {
+	humaContext
+	deprecation config.Deprecation
+}
+
+func (c *deprecationContext) SetStatus(code int) {
+	c.humaContext.SetHeader("Deprecation", "true")
+	if !c.deprecation.Sunset.IsZero() {
+		c.humaContext.SetHeader("Sunset", c.deprecation.Sunset.Format(http.TimeFormat))
+	}
+	if c.deprecation.Successor != "" {
+		c.humaContext.AppendHeader("Link", "<"+c.deprecation.Successor+`>; rel="successor-version"`)
+	}
+	c.humaContext.SetStatus(code)
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+type deprecationTestOutput struct {
+	Link string `header:"Link"`
+	Body struct {
+		OK bool `json:"ok"`
+	}
+}
+
+func TestDeprecation(t *testing.T) {
+	_, api := humatest.New(t)
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "fleet-app", Key: "key", Scopes: []auth.Scope{auth.ScopeRead}},
+	})))
+	api.UseMiddleware(Deprecation(map[string]config.Deprecation{
+		"old-list": {
+			Sunset:    time.Date(2027, time.March, 31, 0, 0, 0, 0, time.UTC),
+			Successor: "/v2/locations",
+		},
+		"old-flag": {},
+	}))
+
+	ok := func(ctx context.Context, _ *struct{}) (*deprecationTestOutput, error) {
+		return &deprecationTestOutput{Link: `</old?cursor=abc>; rel="next"`}, nil
+	}
+	huma.Register(api, huma.Operation{OperationID: "old-list", Method: http.MethodGet, Path: "/old"}, ok)
+	huma.Register(api, huma.Operation{OperationID: "old-flag", Method: http.MethodGet, Path: "/flag", Tags: []string{"Health"}}, ok)
+	huma.Register(api, huma.Operation{OperationID: "current", Method: http.MethodGet, Path: "/current"}, ok)
+
+	counter := metrics.DeprecatedRequests.WithLabelValues("old-list", "fleet-app")
+	before := testutil.ToFloat64(counter)
+	resp := api.Get("/old", "X-API-Key: key")
+	if got := resp.Header().Get("Deprecation"); got != "true" {
+		t.Errorf("Expected Deprecation true, got %q", got)
+	}
+	if got := resp.Header().Get("Sunset"); got != "Wed, 31 Mar 2027 00:00:00 GMT" {
+		t.Errorf("Expected the sunset date, got %q", got)
+	}
+	links := resp.Header().Values("Link")
+	if len(links) != 2 || links[0] != `</old?cursor=abc>; rel="next"` || links[1] != `</v2/locations>; rel="successor-version"` {
+		t.Errorf("Expected the handler's link and the successor, got %q", links)
+	}
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("Expected the counter to go up by 1, got %v", got)
+	}
+
+	anonymous := metrics.DeprecatedRequests.WithLabelValues("old-flag", "anonymous")
+	before = testutil.ToFloat64(anonymous)
+	resp = api.Get("/flag")
+	if resp.Header().Get("Deprecation") != "true" || resp.Header().Get("Sunset") != "" || len(resp.Header().Values("Link")) != 1 {
+		t.Errorf("Expected only the Deprecation header without a sunset or successor, got %v", resp.Header())
+	}
+	if got := testutil.ToFloat64(anonymous) - before; got != 1 {
+		t.Errorf("Expected the anonymous counter to go up by 1, got %v", got)
+	}
+
+	resp = api.Get("/current", "X-API-Key: key")
+	for _, name := range []string{"Deprecation", "Sunset"} {
+		if got := resp.Header().Get(name); got != "" {
+			t.Errorf("Expected no %s header on a current operation, got %q", name, got)
+		}
+	}
+	if got := testutil.CollectAndCount(metrics.DeprecatedRequests, "http_deprecated_requests_total"); got != 2 {
+		t.Errorf("Expected only the deprecated operations counted, got %d series", got)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// ExternalIDs marks every request to show IDs encoded by ids, and to read
+// the IDs it is given the same way. A nil ids leaves IDs as they are.
+func ExternalIDs(ids *dto.ExternalIDs) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if ids == nil {
+			next(ctx)
+			return
+		}
+		next(huma.WithContext(ctx, dto.WithExternalIDs(ctx.Context(), ids)))
+	}
+}
+
+// EncodeIDs is a huma.Transformer that applies the encoding ExternalIDs
+// chose to location responses
+func EncodeIDs(ctx huma.Context, status string, v any) (any, error) {
+	return dto.EncodeIDs(ctx.Context(), v), nil
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// PreferredUnit marks requests from callers whose profile names a distance
+// unit to give distances in it when the request names none. It must run
+// after auth.Middleware so the caller is known.
+func PreferredUnit(ctx huma.Context, next func(huma.Context)) {
+	principal := auth.PrincipalFromContext(ctx.Context())
+	if principal == nil || principal.Profile.Unit == "" {
+		next(ctx)
+		return
+	}
+	next(huma.WithContext(ctx, dto.WithPreferredUnit(ctx.Context(), principal.Profile.Unit)))
+}
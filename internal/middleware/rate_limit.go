@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apierrors "github.com/jesuloba-world/leeta-task/pkg/errors"
+)
+
+// RateLimiter limits each client to a rate of requests with a token
+// bucket of its own, so a client that has been quiet may make a burst of
+// requests at once
+type RateLimiter struct {
+	perSecond float64
+	capacity  float64
+	now       func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastSweep time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	filled time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per client,
+// in bursts of up to as many
+func NewRateLimiter(perMinute int) *RateLimiter {
+	return NewBurstRateLimiter(float64(perMinute)/60, perMinute)
+}
+
+// NewBurstRateLimiter creates a limiter allowing rps requests a second per
+// client, in bursts of up to burst
+func NewBurstRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{perSecond: rps, capacity: float64(burst), now: time.Now, buckets: map[string]*rateBucket{}}
+}
+
+// Allow takes a token from the client's bucket. When the bucket is empty
+// it reports how long until the next token.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity, perSecond := l.capacity, l.perSecond
+	l.sweep(now, capacity, perSecond)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &rateBucket{tokens: capacity, filled: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = min(capacity, bucket.tokens+now.Sub(bucket.filled).Seconds()*perSecond)
+	bucket.filled = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+}
+
+// sweep forgets, once a minute, the clients whose buckets have refilled,
+// as they are indistinguishable from new ones
+func (l *RateLimiter) sweep(now time.Time, capacity, perSecond float64) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.filled).Seconds()*perSecond >= capacity {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// Limit answers requests for which limited reports true with 429 and a
+// Retry-After header once their client has no tokens left. It must run
+// inside ClientIPResolver.Middleware so the client is known.
+func (l *RateLimiter) Limit(limited func(*http.Request) bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limited(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		client := ClientIPFromContext(r.Context())
+		if client == "" {
+			client = r.RemoteAddr
+		}
+		if ok, wait := l.Allow(client); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			apierrors.RespondWithError(w, apierrors.RateLimited("Too many requests; try again later"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
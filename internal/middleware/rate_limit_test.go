@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefillsPerClient(t *testing.T) {
+	now := time.Date(2025, 9, 6, 9, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(3)
+	limiter.now = func() time.Time { return now }
+
+	for i := range 3 {
+		if ok, _ := limiter.Allow("10.0.0.1"); !ok {
+			t.Fatalf("Expected request %d within the burst allowed", i+1)
+		}
+	}
+	ok, wait := limiter.Allow("10.0.0.1")
+	if ok || wait != 20*time.Second {
+		t.Fatalf("Expected the fourth request refused for 20s, got %v %v", ok, wait)
+	}
+	if ok, _ := limiter.Allow("10.0.0.2"); !ok {
+		t.Errorf("Expected another client to have its own bucket")
+	}
+
+	// One token comes back every 20 seconds
+	now = now.Add(20 * time.Second)
+	if ok, _ := limiter.Allow("10.0.0.1"); !ok {
+		t.Errorf("Expected a token after 20s")
+	}
+	if ok, _ := limiter.Allow("10.0.0.1"); ok {
+		t.Errorf("Expected only one token after 20s")
+	}
+
+	// Refilled buckets are forgotten
+	now = now.Add(2 * time.Minute)
+	limiter.Allow("10.0.0.3")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected only the latest client remembered, got %d buckets", len(limiter.buckets))
+	}
+}
+
+func TestRateLimiterLimitsMatchingRequests(t *testing.T) {
+	limiter := NewRateLimiter(1)
+	handler := limiter.Limit(func(r *http.Request) bool { return r.URL.Path == "/docs" },
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		handler.ServeHTTP(rec, req.WithContext(WithClientIP(req.Context(), "10.0.0.1")))
+		return rec
+	}
+
+	if rec := serve("/docs"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the first request served, got %d", rec.Code)
+	}
+	rec := serve("/docs")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Expected 429 with Retry-After: 60, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := serve("/locations"); rec.Code != http.StatusOK {
+		t.Errorf("Expected other paths unlimited, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// ResponseFormat marks every request to round response coordinates to
+// places decimal places and to give distances in unit when neither the
+// request nor the caller names one. Each server carries its own, so two
+// servers in one process can differ.
+func ResponseFormat(places int, unit string) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		c := dto.WithCoordinatePrecision(ctx.Context(), places)
+		next(huma.WithContext(ctx, dto.WithDefaultUnit(c, unit)))
+	}
+}
+
+// RoundCoordinates is a huma.Transformer that rounds the coordinates of
+// location responses to the precision ResponseFormat chose
+func RoundCoordinates(ctx huma.Context, status string, v any) (any, error) {
+	return dto.RoundCoordinates(ctx.Context(), v), nil
+}
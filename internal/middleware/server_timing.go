@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+)
+
+// ServerTiming reports where each request's time went in a Server-Timing
+// header: repo, service, serialize and total, in milliseconds. Responses
+// are buffered so the header can include serialization; streamed
+// responses are passed through without it. It must be installed first,
+// so total covers every other middleware, together with the
+// MarkServerTiming transformer, which marks where serialization starts.
+func ServerTiming(ctx huma.Context, next func(huma.Context)) {
+	c, timings := telemetry.WithTimings(ctx.Context())
+	timed := &serverTimingContext{humaContext: huma.WithContext(ctx, c), timings: timings}
+	next(timed)
+	timed.flush()
+}
+
+// MarkServerTiming is a huma.Transformer that records the handler has
+// returned. Install it before other transformers so their work counts as
+// serialization.
+func MarkServerTiming(ctx huma.Context, status string, v any) (any, error) {
+	telemetry.TimingsFromContext(ctx.Context()).MarkHandled()
+	return v, nil
+}
+
+// serverTimingContext holds back the status and body of a serialized
+// response until the header is known. A response written before the
+// handled mark, such as a stream, goes straight through.
+type serverTimingContext struct {
+	humaContext
+	timings     *telemetry.Timings
+	status      int
+	body        bytes.Buffer
+	buffered    bool
+	passthrough bool
+}
+
+func (c *serverTimingContext) buffering() bool {
+	if !c.buffered && !c.passthrough {
+		if c.timings.Handled() {
+			c.buffered = true
+		} else {
+			c.passthrough = true
+		}
+	}
+	return c.buffered
+}
+
+func (c *serverTimingContext) SetStatus(code int) {
+	if c.buffering() {
+		c.status = code
+		return
+	}
+	c.humaContext.SetStatus(code)
+}
+
+func (c *serverTimingContext) Status() int {
+	if c.buffered && c.status != 0 {
+		return c.status
+	}
+	return c.humaContext.Status()
+}
+
+func (c *serverTimingContext) BodyWriter() io.Writer {
+	if c.buffering() {
+		return &c.body
+	}
+	return c.humaContext.BodyWriter()
+}
+
+// flush sends the held back response with its Server-Timing header
+func (c *serverTimingContext) flush() {
+	if !c.buffered {
+		return
+	}
+	c.humaContext.SetHeader("Server-Timing", c.timings.Header(time.Now()))
+	if c.status != 0 {
+		c.humaContext.SetStatus(c.status)
+	}
+	if c.body.Len() > 0 {
+		c.humaContext.BodyWriter().Write(c.body.Bytes())
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+)
+
+// Telemetry continues the caller's trace context and baggage, if any, and
+// records the operation ID in the baggage. auth.Middleware adds the tenant
+// and principal once the caller is known.
+func Telemetry(ctx huma.Context, next func(huma.Context)) {
+	header := http.Header{}
+	ctx.EachHeader(func(name, value string) {
+		header.Add(name, value)
+	})
+	c := telemetry.Extract(ctx.Context(), header)
+	if op := ctx.Operation(); op != nil {
+		c = telemetry.With(c, telemetry.KeyOperation, op.OperationID)
+	}
+	next(huma.WithContext(ctx, c))
+}
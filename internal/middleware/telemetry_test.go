@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+)
+
+func TestTelemetryBaggage(t *testing.T) {
+	_, api := humatest.New(t)
+	api.UseMiddleware(Telemetry)
+	api.UseMiddleware(auth.Middleware(auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "fleet-app", Key: "key", Tenant: "acme", Scopes: []auth.Scope{auth.ScopeRead}},
+	})))
+
+	var comment string
+	handler := func(ctx context.Context, _ *struct{}) (*struct{}, error) {
+		comment = telemetry.SQLComment(ctx)
+		return nil, nil
+	}
+	huma.Register(api, huma.Operation{OperationID: "list-locations", Method: http.MethodGet, Path: "/locations"}, handler)
+	huma.Register(api, huma.Operation{OperationID: "health", Method: http.MethodGet, Path: "/health", Tags: []string{"Health"}}, handler)
+
+	api.Get("/locations", "X-API-Key: key",
+		"traceparent: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"baggage: tenant=other,principal=admin,region=lagos")
+	want := "/*operation='list-locations',principal='fleet-app',tenant='acme'," +
+		"traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/"
+	if comment != want {
+		t.Errorf("Expected %s, got %s", want, comment)
+	}
+
+	// A caller cannot name a tenant for itself
+	api.Get("/health", "baggage: tenant=other,principal=admin")
+	if want := "/*operation='health'*/"; comment != want {
+		t.Errorf("Expected %s for an anonymous caller, got %s", want, comment)
+	}
+}
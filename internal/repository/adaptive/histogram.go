@@ -0,0 +1,90 @@
+package adaptive
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// histogramBuckets buckets grow by bucketGrowth from bucketFloor, so
+	// the last holds latencies from about 12 minutes up
+	histogramBuckets = 72
+	bucketFloor      = 100 * time.Microsecond
+	bucketGrowth     = 1.25
+	// histogramSlots split the window, so latencies age out a slot at a
+	// time rather than all at once
+	histogramSlots = 6
+)
+
+// bucketBounds holds the largest latency each bucket counts
+var bucketBounds = func() [histogramBuckets]time.Duration {
+	var bounds [histogramBuckets]time.Duration
+	for i := range bounds {
+		bounds[i] = time.Duration(float64(bucketFloor) * math.Pow(bucketGrowth, float64(i)))
+	}
+	return bounds
+}()
+
+func bucketFor(latency time.Duration) int {
+	i := sort.Search(histogramBuckets, func(i int) bool { return bucketBounds[i] >= latency })
+	return min(i, histogramBuckets-1)
+}
+
+type slot struct {
+	start  time.Time
+	total  int
+	counts [histogramBuckets]uint32
+}
+
+// rollingHistogram counts latencies over a sliding window, in slots of
+// slotLength; it is not safe for concurrent use
+type rollingHistogram struct {
+	slotLength time.Duration
+	slots      [histogramSlots]slot
+}
+
+func newRollingHistogram(window time.Duration) *rollingHistogram {
+	return &rollingHistogram{slotLength: max(window/histogramSlots, time.Millisecond)}
+}
+
+func (h *rollingHistogram) observe(now time.Time, latency time.Duration) {
+	start := now.Truncate(h.slotLength)
+	s := &h.slots[int(start.UnixNano()/int64(h.slotLength))%histogramSlots]
+	if !s.start.Equal(start) {
+		*s = slot{start: start}
+	}
+	s.counts[bucketFor(latency)]++
+	s.total++
+}
+
+// quantile returns the q quantile of the latencies in the window ending
+// at now, rounded up to its bucket's bound, and how many there were
+func (h *rollingHistogram) quantile(now time.Time, q float64) (time.Duration, int) {
+	oldest := now.Truncate(h.slotLength).Add(-h.slotLength * (histogramSlots - 1))
+	var merged [histogramBuckets]int
+	total := 0
+	for i := range h.slots {
+		s := &h.slots[i]
+		if s.total == 0 || s.start.Before(oldest) {
+			continue
+		}
+		for b, count := range s.counts {
+			merged[b] += int(count)
+		}
+		total += s.total
+	}
+	if total == 0 {
+		return 0, 0
+	}
+
+	rank := int(math.Ceil(q * float64(total)))
+	seen := 0
+	for b, count := range merged {
+		seen += count
+		if seen >= rank {
+			return bucketBounds[b], total
+		}
+	}
+	return bucketBounds[histogramBuckets-1], total
+}
@@ -0,0 +1,271 @@
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// TimeoutLocationRepository limits reads from another repository to their
+// adaptive timeout, failing them with domain.ErrStorageTimeout past it.
+// The repository takes no context, so a read cut off runs on and its
+// latency still counts; only the caller stops waiting. Writes, FindAll,
+// which exports read whole, and streams are passed through untimed. The
+// optional interfaces the stores implement are passed through too, and
+// fail as the cache does when the underlying repository lacks them.
+type TimeoutLocationRepository struct {
+	inner    domain.LocationRepository
+	timeouts *Timeouts
+}
+
+// NewTimeoutLocationRepository limits reads from inner to timeouts
+func NewTimeoutLocationRepository(inner domain.LocationRepository, timeouts *Timeouts) *TimeoutLocationRepository {
+	return &TimeoutLocationRepository{inner: inner, timeouts: timeouts}
+}
+
+// TimeoutStats reports the current timeout of every read called so far
+func (r *TimeoutLocationRepository) TimeoutStats() []domain.TimeoutStats {
+	return r.timeouts.TimeoutStats()
+}
+
+// timed runs read in the background and waits for it up to method's
+// timeout
+func timed[T any](r *TimeoutLocationRepository, method string, read func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+	timeout := r.timeouts.Timeout(method)
+	start := r.timeouts.now()
+	done := make(chan result, 1)
+	go func() {
+		value, err := read()
+		r.timeouts.observe(method, r.timeouts.now().Sub(start))
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-timer.C:
+		r.timeouts.timedOut(method)
+		var zero T
+		return zero, fmt.Errorf("%s took longer than %s: %w", method, timeout, domain.ErrStorageTimeout)
+	}
+}
+
+// nearest is a location with its distance, for timing FindNearest
+type nearest struct {
+	location *domain.Location
+	distance geospatial.Distance
+}
+
+func (r *TimeoutLocationRepository) FindByName(name string) (*domain.Location, error) {
+	return timed(r, "FindByName", func() (*domain.Location, error) { return r.inner.FindByName(name) })
+}
+
+func (r *TimeoutLocationRepository) FindByID(id string) (*domain.Location, error) {
+	return timed(r, "FindByID", func() (*domain.Location, error) { return r.inner.FindByID(id) })
+}
+
+func (r *TimeoutLocationRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	return timed(r, "Find", func() ([]*domain.Location, error) { return r.inner.Find(filter, page, order) })
+}
+
+// PagesSort reports the underlying repository's answer, false when it does
+// not page listings itself
+func (r *TimeoutLocationRepository) PagesSort(order domain.LocationSort) bool {
+	finder, ok := r.inner.(domain.PagedFinder)
+	return ok && finder.PagesSort(order)
+}
+
+// CountMatching is timed as Count. Without a count in the underlying
+// repository the matches are read and counted.
+func (r *TimeoutLocationRepository) CountMatching(filter domain.LocationFilter) (int, error) {
+	if finder, ok := r.inner.(domain.PagedFinder); ok {
+		return timed(r, "Count", func() (int, error) { return finder.CountMatching(filter) })
+	}
+	locations, err := r.Find(filter, domain.Page{}, domain.LocationSort{})
+	return len(locations), err
+}
+
+func (r *TimeoutLocationRepository) Count() (int, error) {
+	return timed(r, "Count", r.inner.Count)
+}
+
+func (r *TimeoutLocationRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	found, err := timed(r, "FindNearest", func() (nearest, error) {
+		location, distance, err := r.inner.FindNearest(latitude, longitude, exclude...)
+		return nearest{location, distance}, err
+	})
+	return found.location, found.distance, err
+}
+
+// FindNearestInRegion is timed as FindNearest
+func (r *TimeoutLocationRepository) FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	finder, ok := r.inner.(domain.RegionalNearestFinder)
+	if !ok {
+		return nil, 0, errors.New("underlying repository does not support region searches")
+	}
+	found, err := timed(r, "FindNearest", func() (nearest, error) {
+		location, distance, err := finder.FindNearestInRegion(region, latitude, longitude, exclude...)
+		return nearest{location, distance}, err
+	})
+	return found.location, found.distance, err
+}
+
+// FindWithinRadius is timed as FindNearest
+func (r *TimeoutLocationRepository) FindWithinRadius(latitude, longitude float64, radius geospatial.Distance, limit int) ([]domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.RadiusFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support radius searches")
+	}
+	return timed(r, "FindNearest", func() ([]domain.NearestResult, error) {
+		return finder.FindWithinRadius(latitude, longitude, radius, limit)
+	})
+}
+
+// FindNearestBudgeted is timed as FindNearest. It passes the search
+// through when the underlying repository has a scan budget, and otherwise
+// answers exactly.
+func (r *TimeoutLocationRepository) FindNearestBudgeted(region string, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	if finder, ok := r.inner.(domain.BudgetedNearestFinder); ok {
+		return timed(r, "FindNearest", func() (*domain.NearestResult, error) {
+			return finder.FindNearestBudgeted(region, latitude, longitude, exclude...)
+		})
+	}
+	search := r.FindNearest
+	if region != "" {
+		search = func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+			return r.FindNearestInRegion(region, latitude, longitude, exclude...)
+		}
+	}
+	location, distance, err := search(latitude, longitude, exclude...)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance}, nil
+}
+
+// FindNearestStocked is timed as FindNearest
+func (r *TimeoutLocationRepository) FindNearestStocked(region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.StockedNearestFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support stock searches")
+	}
+	return timed(r, "FindNearest", func() (*domain.NearestResult, error) {
+		return finder.FindNearestStocked(region, minLitres, latitude, longitude, exclude...)
+	})
+}
+
+// FindNearestStockedWithin is timed as FindNearest
+func (r *TimeoutLocationRepository) FindNearestStockedWithin(budget domain.ScanBudget, region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.BudgetedStockedFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support budgeted stock searches")
+	}
+	return timed(r, "FindNearest", func() (*domain.NearestResult, error) {
+		return finder.FindNearestStockedWithin(budget, region, minLitres, latitude, longitude, exclude...)
+	})
+}
+
+func (r *TimeoutLocationRepository) SuggestLocations(query domain.SuggestQuery) ([]domain.Suggestion, error) {
+	suggester, ok := r.inner.(domain.LocationSuggester)
+	if !ok {
+		return nil, domain.ErrSuggestionsUnsupported
+	}
+	return timed(r, "SuggestLocations", func() ([]domain.Suggestion, error) { return suggester.SuggestLocations(query) })
+}
+
+func (r *TimeoutLocationRepository) FindAll() ([]*domain.Location, error) {
+	return r.inner.FindAll()
+}
+
+func (r *TimeoutLocationRepository) Save(location *domain.Location) error {
+	return r.inner.Save(location)
+}
+
+func (r *TimeoutLocationRepository) Delete(name string, checks ...domain.Precondition) error {
+	return r.inner.Delete(name, checks...)
+}
+
+func (r *TimeoutLocationRepository) AddAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := r.inner.(domain.LocationAliaser)
+	if !ok {
+		return nil, errors.New("underlying repository does not support aliases")
+	}
+	return aliaser.AddAlias(name, alias, checks...)
+}
+
+func (r *TimeoutLocationRepository) RemoveAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := r.inner.(domain.LocationAliaser)
+	if !ok {
+		return nil, errors.New("underlying repository does not support aliases")
+	}
+	return aliaser.RemoveAlias(name, alias, checks...)
+}
+
+func (r *TimeoutLocationRepository) UpdateStock(name string, update domain.StockUpdate, checks ...domain.Precondition) (*domain.Location, error) {
+	updater, ok := r.inner.(domain.StockUpdater)
+	if !ok {
+		return nil, errors.New("underlying repository does not support stock updates")
+	}
+	return updater.UpdateStock(name, update, checks...)
+}
+
+func (r *TimeoutLocationRepository) ApplyOperations(ops []domain.LocationOperation) ([]domain.OperationResult, error) {
+	transactor, ok := r.inner.(domain.LocationTransactor)
+	if !ok {
+		return nil, errors.New("underlying repository does not support transactions")
+	}
+	return transactor.ApplyOperations(ops)
+}
+
+func (r *TimeoutLocationRepository) MergeLocations(winner string, losers []string, actor string) (*domain.MergeAudit, error) {
+	merger, ok := r.inner.(domain.LocationMerger)
+	if !ok {
+		return nil, errors.New("underlying repository does not support merges")
+	}
+	return merger.MergeLocations(winner, losers, actor)
+}
+
+func (r *TimeoutLocationRepository) RestoreLocations(locations []domain.Location) (*domain.RestoreResult, error) {
+	restorer, ok := r.inner.(domain.LocationRestorer)
+	if !ok {
+		return nil, errors.New("underlying repository does not support restores")
+	}
+	return restorer.RestoreLocations(locations)
+}
+
+func (r *TimeoutLocationRepository) TruncateLocations(actor string) (*domain.TruncateAudit, error) {
+	truncator, ok := r.inner.(domain.LocationTruncator)
+	if !ok {
+		return nil, errors.New("underlying repository does not support truncating")
+	}
+	return truncator.TruncateLocations(actor)
+}
+
+func (r *TimeoutLocationRepository) RenameLocation(id, name string) error {
+	renamer, ok := r.inner.(domain.LocationRenamer)
+	if !ok {
+		return errors.New("underlying repository does not support renames")
+	}
+	return renamer.RenameLocation(id, name)
+}
+
+func (r *TimeoutLocationRepository) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	streamer, ok := r.inner.(domain.LocationStreamer)
+	if !ok {
+		return func(yield func(*domain.Location, error) bool) {
+			yield(nil, errors.New("underlying repository does not support streaming"))
+		}
+	}
+	return streamer.StreamLocations(ctx, batchSize)
+}
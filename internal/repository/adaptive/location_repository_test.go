@@ -0,0 +1,66 @@
+package adaptive_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/adaptive"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+// stuckRepository blocks reads until release is closed
+type stuckRepository struct {
+	*memory.InMemoryLocationRepository
+	release chan struct{}
+}
+
+func (r *stuckRepository) FindByName(name string) (*domain.Location, error) {
+	<-r.release
+	return r.InMemoryLocationRepository.FindByName(name)
+}
+
+func (r *stuckRepository) FindAll() ([]*domain.Location, error) {
+	<-r.release
+	return r.InMemoryLocationRepository.FindAll()
+}
+
+func TestTimeoutCutsOffSlowReads(t *testing.T) {
+	t.Parallel()
+	inner := &stuckRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository(), release: make(chan struct{})}
+	location, _ := domain.NewLocation("Yaba", 6.5, 3.37)
+	inner.Save(location)
+	repo := adaptive.NewTimeoutLocationRepository(inner, adaptive.NewTimeouts(20*time.Millisecond, 3, time.Minute))
+
+	if _, err := repo.FindByName("Yaba"); !errors.Is(err, domain.ErrStorageTimeout) {
+		t.Fatalf("Expected ErrStorageTimeout, got %v", err)
+	}
+	if stats := repo.TimeoutStats(); len(stats) != 1 || stats[0].TimedOut != 1 {
+		t.Errorf("Expected the timeout counted, got %+v", stats)
+	}
+
+	// FindAll, which exports read whole, waits as long as it takes
+	found := make(chan error, 1)
+	go func() {
+		_, err := repo.FindAll()
+		found <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(inner.release)
+	if err := <-found; err != nil {
+		t.Errorf("Expected FindAll untimed, got %v", err)
+	}
+
+	// The read cut off still finishes, and its latency counts
+	deadline := time.Now().Add(time.Second)
+	for repo.TimeoutStats()[0].Samples == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if stats := repo.TimeoutStats(); stats[0].Samples != 1 || stats[0].Timeout <= 20*time.Millisecond {
+		t.Errorf("Expected the slow read to raise the timeout, got %+v", stats)
+	}
+	if _, err := repo.FindByName("Yaba"); err != nil {
+		t.Errorf("Expected reads to answer once released, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package adaptive limits location repository reads to a timeout that
+// follows their recent latency, so a slow database fails reads fast
+// without a fixed limit cutting off legitimate slow calls.
+package adaptive
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// timeoutQuantile is the latency the timeout is a multiple of
+const timeoutQuantile = 0.99
+
+// Defaults for the settings NewTimeouts is given as zero
+const (
+	DefaultFloor      = 500 * time.Millisecond
+	DefaultMultiplier = 3
+	DefaultWindow     = time.Minute
+)
+
+// Timeouts tracks the latency of each repository method over a rolling
+// window and derives its timeout: the larger of a floor and a multiple of
+// the 99th percentile
+type Timeouts struct {
+	floor      time.Duration
+	multiplier float64
+	window     time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	methods map[string]*methodLatency
+}
+
+type methodLatency struct {
+	histogram *rollingHistogram
+	timedOut  uint64
+}
+
+// Option configures Timeouts
+type Option func(*Timeouts)
+
+// WithClock replaces the wall clock, for tests
+func WithClock(now func() time.Time) Option {
+	return func(t *Timeouts) {
+		t.now = now
+	}
+}
+
+// NewTimeouts allows each method max(floor, multiplier × p99), the p99
+// taken over the last window of calls. A method with no calls in the
+// window is allowed the floor. Settings that are not positive take their
+// defaults.
+func NewTimeouts(floor time.Duration, multiplier float64, window time.Duration, opts ...Option) *Timeouts {
+	if floor <= 0 {
+		floor = DefaultFloor
+	}
+	if multiplier <= 0 {
+		multiplier = DefaultMultiplier
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	t := &Timeouts{
+		floor:      floor,
+		multiplier: multiplier,
+		window:     window,
+		now:        time.Now,
+		methods:    make(map[string]*methodLatency),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Timeout returns what the next call to method is allowed
+func (t *Timeouts) Timeout(method string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timeout, _, _ := t.timeout(method)
+	return timeout
+}
+
+// timeout derives method's timeout; the caller holds mu
+func (t *Timeouts) timeout(method string) (timeout, p99 time.Duration, samples int) {
+	m, ok := t.methods[method]
+	if !ok {
+		return t.floor, 0, 0
+	}
+	p99, samples = m.histogram.quantile(t.now(), timeoutQuantile)
+	return max(t.floor, time.Duration(t.multiplier*float64(p99))), p99, samples
+}
+
+// observe records a call that took latency, timed out or not
+func (t *Timeouts) observe(method string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.method(method).histogram.observe(t.now(), latency)
+}
+
+func (t *Timeouts) timedOut(method string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.method(method).timedOut++
+}
+
+// method returns method's latency, creating it; the caller holds mu
+func (t *Timeouts) method(method string) *methodLatency {
+	m, ok := t.methods[method]
+	if !ok {
+		m = &methodLatency{histogram: newRollingHistogram(t.window)}
+		t.methods[method] = m
+	}
+	return m
+}
+
+// TimeoutStats reports every method called so far, by name
+func (t *Timeouts) TimeoutStats() []domain.TimeoutStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make([]domain.TimeoutStats, 0, len(t.methods))
+	for method, m := range t.methods {
+		timeout, p99, samples := t.timeout(method)
+		stats = append(stats, domain.TimeoutStats{Method: method, Timeout: timeout, P99: p99, Samples: samples, TimedOut: m.timedOut})
+	}
+	slices.SortFunc(stats, func(a, b domain.TimeoutStats) int { return cmp.Compare(a.Method, b.Method) })
+	return stats
+}
@@ -0,0 +1,113 @@
+package adaptive_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/adaptive"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// scriptedRepository answers FindByName at once but advances the clock
+// by the latency it is scripted to take
+type scriptedRepository struct {
+	*memory.InMemoryLocationRepository
+	clock   *fakeClock
+	latency time.Duration
+}
+
+func (r *scriptedRepository) FindByName(name string) (*domain.Location, error) {
+	r.clock.Advance(r.latency)
+	return r.InMemoryLocationRepository.FindByName(name)
+}
+
+func TestTimeoutAdaptsToLatency(t *testing.T) {
+	t.Parallel()
+	const floor = 100 * time.Millisecond
+	clock := &fakeClock{now: time.Date(2025, 9, 8, 9, 0, 0, 0, time.UTC)}
+	inner := &scriptedRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository(), clock: clock}
+	location, _ := domain.NewLocation("Yaba", 6.5, 3.37)
+	inner.Save(location)
+	timeouts := adaptive.NewTimeouts(floor, 3, time.Minute, adaptive.WithClock(clock.Now))
+	repo := adaptive.NewTimeoutLocationRepository(inner, timeouts)
+
+	calls := func(n int, latency time.Duration) {
+		t.Helper()
+		inner.latency = latency
+		for range n {
+			if _, err := repo.FindByName("Yaba"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+	}
+
+	if timeout := timeouts.Timeout("FindByName"); timeout != floor {
+		t.Errorf("Expected the floor before any call, got %s", timeout)
+	}
+
+	// Fast reads stay at the floor rather than going under it
+	calls(200, 5*time.Millisecond)
+	if timeout := timeouts.Timeout("FindByName"); timeout != floor {
+		t.Errorf("Expected the floor while 3 x p99 is below it, got %s", timeout)
+	}
+
+	// Sustained slowness raises the timeout to follow the p99
+	calls(200, 400*time.Millisecond)
+	timeout := timeouts.Timeout("FindByName")
+	if timeout < 1200*time.Millisecond || timeout > 1500*time.Millisecond {
+		t.Errorf("Expected about 3 x 400ms under sustained slowness, got %s", timeout)
+	}
+
+	// A few slow reads among many fast ones are under the p99
+	clock.Advance(2 * time.Minute)
+	calls(500, 5*time.Millisecond)
+	calls(3, 400*time.Millisecond)
+	if timeout := timeouts.Timeout("FindByName"); timeout != floor {
+		t.Errorf("Expected the timeout back at the floor once the slow window passed, got %s", timeout)
+	}
+
+	stats := repo.TimeoutStats()
+	if len(stats) != 1 || stats[0].Method != "FindByName" || stats[0].Samples != 503 || stats[0].Timeout != floor || stats[0].TimedOut != 0 {
+		t.Errorf("Expected the FindByName timeout reported, got %+v", stats)
+	}
+}
+
+func TestTimeoutDecaysSlotBySlot(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2025, 9, 8, 9, 0, 0, 0, time.UTC)}
+	inner := &scriptedRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository(), clock: clock, latency: time.Second}
+	timeouts := adaptive.NewTimeouts(50*time.Millisecond, 2, time.Minute, adaptive.WithClock(clock.Now))
+	repo := adaptive.NewTimeoutLocationRepository(inner, timeouts)
+
+	repo.FindByName("Yaba")
+	if timeout := timeouts.Timeout("FindByName"); timeout < 2*time.Second {
+		t.Fatalf("Expected a slow read to raise the timeout, got %s", timeout)
+	}
+	clock.Advance(30 * time.Second)
+	if timeout := timeouts.Timeout("FindByName"); timeout < 2*time.Second {
+		t.Errorf("Expected the slow read still in the window, got %s", timeout)
+	}
+	clock.Advance(40 * time.Second)
+	if timeout := timeouts.Timeout("FindByName"); timeout != 50*time.Millisecond {
+		t.Errorf("Expected the floor once the slow read aged out, got %s", timeout)
+	}
+}
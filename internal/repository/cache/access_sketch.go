@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// defaultSketchSize bounds the names an accessSketch counts
+const defaultSketchSize = 1024
+
+// accessSketch estimates the most read names in bounded memory with the
+// space-saving algorithm: once full, a new name takes over the least
+// counted slot and inherits its count, so counts may overestimate but a
+// name read often enough is never lost.
+type accessSketch struct {
+	mu     sync.Mutex
+	size   int
+	counts map[string]uint64
+}
+
+func newAccessSketch(size int) *accessSketch {
+	return &accessSketch{size: max(size, 1), counts: make(map[string]uint64)}
+}
+
+func (s *accessSketch) record(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.counts[name]; ok || len(s.counts) < s.size {
+		s.counts[name]++
+		return
+	}
+	var evict string
+	var least uint64
+	for candidate, count := range s.counts {
+		if evict == "" || count < least {
+			evict, least = candidate, count
+		}
+	}
+	delete(s.counts, evict)
+	s.counts[name] = least + 1
+}
+
+// top returns up to n names, most counted first and ties by name
+func (s *accessSketch) top(n int) []string {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.counts))
+	for name := range s.counts {
+		names = append(names, name)
+	}
+	counts := s.counts
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	s.mu.Unlock()
+
+	if n >= 0 && len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
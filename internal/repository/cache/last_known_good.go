@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// DefaultLastKnownGoodEntries bounds the listings a LastKnownGood keeps
+// unless told otherwise
+const DefaultLastKnownGoodEntries = 256
+
+type rememberedList struct {
+	page domain.ListPage[*domain.Location]
+	at   time.Time
+}
+
+// LastKnownGood keeps the last page each listing query returned, for
+// answering that query when the repository fails. Unlike the read-through
+// cache it is never invalidated: what it returns is known to be stale, and
+// the caller decides how old is too old. It copies locations in and out
+// as the cache does.
+type LastKnownGood struct {
+	maxEntries int
+
+	mu    sync.Mutex
+	lists map[string]rememberedList
+}
+
+// NewLastKnownGood keeps up to maxEntries listings, forgetting the oldest
+// first; 0 means DefaultLastKnownGoodEntries
+func NewLastKnownGood(maxEntries int) *LastKnownGood {
+	if maxEntries <= 0 {
+		maxEntries = DefaultLastKnownGoodEntries
+	}
+	return &LastKnownGood{maxEntries: maxEntries, lists: make(map[string]rememberedList)}
+}
+
+// RememberList replaces the listing kept under key
+func (l *LastKnownGood) RememberList(key string, page domain.ListPage[*domain.Location], at time.Time) {
+	page.Items = copyLocations(page.Items)
+	remembered := rememberedList{page: page, at: at}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, exists := l.lists[key]; !exists && len(l.lists) >= l.maxEntries {
+		l.forgetOldest()
+	}
+	l.lists[key] = remembered
+}
+
+// RecallList returns a copy of the listing kept under key and when it was
+// remembered
+func (l *LastKnownGood) RecallList(key string) (domain.ListPage[*domain.Location], time.Time, bool) {
+	l.mu.Lock()
+	remembered, ok := l.lists[key]
+	l.mu.Unlock()
+	if !ok {
+		return domain.ListPage[*domain.Location]{}, time.Time{}, false
+	}
+	page := remembered.page
+	page.Items = copyLocations(page.Items)
+	return page, remembered.at, true
+}
+
+// forgetOldest drops the listing remembered longest ago; the caller holds
+// the lock
+func (l *LastKnownGood) forgetOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, remembered := range l.lists {
+		if oldest.IsZero() || remembered.at.Before(oldest) {
+			oldestKey, oldest = key, remembered.at
+		}
+	}
+	delete(l.lists, oldestKey)
+}
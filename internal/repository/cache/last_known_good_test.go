@@ -0,0 +1,41 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+)
+
+func TestLastKnownGood(t *testing.T) {
+	t.Parallel()
+	lists := cache.NewLastKnownGood(2)
+	start := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	ikeja := &domain.Location{ID: "1", Name: "Ikeja"}
+	lists.RememberList("a", domain.ListPage[*domain.Location]{Items: []*domain.Location{ikeja}, Total: 3, More: true}, start)
+	// Later changes to the remembered locations do not reach the copy
+	ikeja.Name = "Renamed"
+	recalled, at, ok := lists.RecallList("a")
+	if !ok || !at.Equal(start) || len(recalled.Items) != 1 || recalled.Items[0].Name != "Ikeja" || recalled.Total != 3 || !recalled.More {
+		t.Fatalf("Expected Ikeja, first of 3, as of %v, got %+v at %v", start, recalled, at)
+	}
+	recalled.Items[0].Name = "Changed"
+	if again, _, _ := lists.RecallList("a"); again.Items[0].Name != "Ikeja" {
+		t.Errorf("Expected recalled copies to be independent, got %s", again.Items[0].Name)
+	}
+
+	// Past two listings the one remembered longest ago is forgotten
+	for i, key := range []string{"b", "a", "c"} {
+		lists.RememberList(key, domain.ListPage[*domain.Location]{}, start.Add(time.Duration(i+1)*time.Minute))
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, _, ok := lists.RecallList(key); ok != want {
+			t.Errorf("Expected %s kept %v, got %v", key, want, ok)
+		}
+	}
+	if _, _, ok := lists.RecallList("missing"); ok {
+		t.Error("Expected nothing under a key never remembered")
+	}
+}
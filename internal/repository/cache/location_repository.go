@@ -0,0 +1,614 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+type entry struct {
+	location  *domain.Location
+	expiresAt time.Time
+}
+
+type listEntry struct {
+	locations []*domain.Location
+	expiresAt time.Time
+}
+
+// CachedLocationRepository caches reads from another repository for a fixed
+// TTL. Local writes invalidate immediately; writes made by other replicas are
+// applied through Invalidate and Flush. With WithStaleTTL, an expired entry
+// is still served for that long while one background refresh reloads it.
+type CachedLocationRepository struct {
+	inner    domain.LocationRepository
+	ttl      time.Duration
+	staleTTL time.Duration
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	byName map[string]entry
+	byID   map[string]entry
+	all    *listEntry
+	// refreshing holds the keys with a background refresh running
+	refreshing map[string]bool
+
+	version atomic.Uint64
+	stats   cacheStats
+	// accesses counts FindByName reads, so the next run can prime the
+	// names read most
+	accesses *accessSketch
+}
+
+type cacheStats struct {
+	freshHits     atomic.Uint64
+	staleHits     atomic.Uint64
+	misses        atomic.Uint64
+	refreshes     atomic.Uint64
+	refreshErrors atomic.Uint64
+}
+
+// Option configures a CachedLocationRepository
+type Option func(*CachedLocationRepository)
+
+// WithStaleTTL serves entries for up to staleTTL past their TTL while a
+// background refresh reloads them, so expiry does not make a request wait
+// on the repository. Past that, requests wait as without it. The default,
+// 0, never serves an expired entry.
+func WithStaleTTL(staleTTL time.Duration) Option {
+	return func(r *CachedLocationRepository) {
+		r.staleTTL = max(staleTTL, 0)
+	}
+}
+
+// WithClock replaces the wall clock, for tests
+func WithClock(now func() time.Time) Option {
+	return func(r *CachedLocationRepository) {
+		r.now = now
+	}
+}
+
+func NewCachedLocationRepository(inner domain.LocationRepository, ttl time.Duration, opts ...Option) *CachedLocationRepository {
+	r := &CachedLocationRepository{
+		inner:      inner,
+		ttl:        ttl,
+		now:        time.Now,
+		byName:     make(map[string]entry),
+		byID:       make(map[string]entry),
+		refreshing: make(map[string]bool),
+		accesses:   newAccessSketch(defaultSketchSize),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// CacheStats reports how lookups have been answered since the cache was
+// created
+func (r *CachedLocationRepository) CacheStats() domain.CacheStats {
+	return domain.CacheStats{
+		FreshHits:     r.stats.freshHits.Load(),
+		StaleHits:     r.stats.staleHits.Load(),
+		Misses:        r.stats.misses.Load(),
+		Refreshes:     r.stats.refreshes.Load(),
+		RefreshErrors: r.stats.refreshErrors.Load(),
+	}
+}
+
+// Version changes whenever cached data may have changed, for use in ETags
+func (r *CachedLocationRepository) Version() uint64 {
+	return r.version.Load()
+}
+
+func (r *CachedLocationRepository) Save(location *domain.Location) error {
+	if err := r.inner.Save(location); err != nil {
+		return err
+	}
+	r.Invalidate(location.Name)
+	return nil
+}
+
+func (r *CachedLocationRepository) Delete(name string, checks ...domain.Precondition) error {
+	if err := r.inner.Delete(name, checks...); err != nil {
+		return err
+	}
+	r.Invalidate(name)
+	return nil
+}
+
+// MergeLocations merges through the underlying repository, which must
+// implement domain.LocationMerger, and drops the losers from the cache
+func (r *CachedLocationRepository) MergeLocations(winner string, losers []string, actor string) (*domain.MergeAudit, error) {
+	merger, ok := r.inner.(domain.LocationMerger)
+	if !ok {
+		return nil, errors.New("underlying repository does not support merging")
+	}
+	audit, err := merger.MergeLocations(winner, losers, actor)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range losers {
+		r.Invalidate(name)
+	}
+	return audit, nil
+}
+
+// RestoreLocations restores through the underlying repository, which must
+// implement domain.LocationRestorer, and flushes the cache
+func (r *CachedLocationRepository) RestoreLocations(locations []domain.Location) (*domain.RestoreResult, error) {
+	restorer, ok := r.inner.(domain.LocationRestorer)
+	if !ok {
+		return nil, errors.New("underlying repository does not support restoring")
+	}
+	result, err := restorer.RestoreLocations(locations)
+	if err != nil {
+		return nil, err
+	}
+	r.Flush()
+	return result, nil
+}
+
+// TruncateLocations empties the underlying repository, which must
+// implement domain.LocationTruncator, and flushes the cache
+func (r *CachedLocationRepository) TruncateLocations(actor string) (*domain.TruncateAudit, error) {
+	truncator, ok := r.inner.(domain.LocationTruncator)
+	if !ok {
+		return nil, errors.New("underlying repository does not support truncating")
+	}
+	audit, err := truncator.TruncateLocations(actor)
+	if err != nil {
+		return nil, err
+	}
+	r.Flush()
+	return audit, nil
+}
+
+// RenameLocation renames through the underlying repository, which must
+// implement domain.LocationRenamer, and flushes the cache, as the old name
+// is not known here
+func (r *CachedLocationRepository) RenameLocation(id, name string) error {
+	renamer, ok := r.inner.(domain.LocationRenamer)
+	if !ok {
+		return errors.New("underlying repository does not support renaming")
+	}
+	if err := renamer.RenameLocation(id, name); err != nil {
+		return err
+	}
+	r.Flush()
+	return nil
+}
+
+// ApplyOperations applies through the underlying repository, which must
+// implement domain.LocationTransactor, and flushes the cache
+func (r *CachedLocationRepository) ApplyOperations(ops []domain.LocationOperation) ([]domain.OperationResult, error) {
+	transactor, ok := r.inner.(domain.LocationTransactor)
+	if !ok {
+		return nil, domain.ErrTransactionsUnsupported
+	}
+	results, err := transactor.ApplyOperations(ops)
+	if err != nil {
+		return nil, err
+	}
+	r.Flush()
+	return results, nil
+}
+
+// AddAlias adds through the underlying repository, which must implement
+// domain.LocationAliaser, and drops the location from the cache
+func (r *CachedLocationRepository) AddAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := r.inner.(domain.LocationAliaser)
+	if !ok {
+		return nil, errors.New("underlying repository does not support aliases")
+	}
+	location, err := aliaser.AddAlias(name, alias, checks...)
+	if err != nil {
+		return nil, err
+	}
+	r.Invalidate(location.Name)
+	return location, nil
+}
+
+// RemoveAlias removes through the underlying repository, which must
+// implement domain.LocationAliaser, and drops the location from the cache
+func (r *CachedLocationRepository) RemoveAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := r.inner.(domain.LocationAliaser)
+	if !ok {
+		return nil, errors.New("underlying repository does not support aliases")
+	}
+	location, err := aliaser.RemoveAlias(name, alias, checks...)
+	if err != nil {
+		return nil, err
+	}
+	r.Invalidate(location.Name)
+	return location, nil
+}
+
+// UpdateStock updates through the underlying repository, which must
+// implement domain.StockUpdater, and replaces the location's cached copies,
+// including its place in the cached listing. Stock changes often, and
+// dropping the listing on every update would leave it mostly uncached.
+func (r *CachedLocationRepository) UpdateStock(name string, update domain.StockUpdate, checks ...domain.Precondition) (*domain.Location, error) {
+	updater, ok := r.inner.(domain.StockUpdater)
+	if !ok {
+		return nil, errors.New("underlying repository does not support stock updates")
+	}
+	location, err := updater.UpdateStock(name, update, checks...)
+	if err != nil {
+		return nil, err
+	}
+	r.replace(location)
+	return location, nil
+}
+
+// StreamLocations reads the underlying repository, which must implement
+// domain.LocationStreamer; scans should see the store, not the cache
+func (r *CachedLocationRepository) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	streamer, ok := r.inner.(domain.LocationStreamer)
+	if !ok {
+		return func(yield func(*domain.Location, error) bool) {
+			yield(nil, errors.New("underlying repository does not support streaming"))
+		}
+	}
+	return streamer.StreamLocations(ctx, batchSize)
+}
+
+func (r *CachedLocationRepository) FindByName(name string) (*domain.Location, error) {
+	r.accesses.record(name)
+	r.mu.RLock()
+	cached, ok := r.byName[name]
+	r.mu.RUnlock()
+	if ok && r.serve(cached.expiresAt, "name:"+name, func() error {
+		_, err := r.loadByName(name)
+		return err
+	}) {
+		return copyLocation(cached.location), nil
+	}
+
+	r.stats.misses.Add(1)
+	location, err := r.loadByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLocation(location), nil
+}
+
+func (r *CachedLocationRepository) loadByName(name string) (*domain.Location, error) {
+	version := r.Version()
+	location, err := r.inner.FindByName(name)
+	if errors.Is(err, domain.ErrLocationNotFound) {
+		r.drop(version, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.store(version, location)
+	return location, nil
+}
+
+func (r *CachedLocationRepository) FindByID(id string) (*domain.Location, error) {
+	r.mu.RLock()
+	cached, ok := r.byID[id]
+	r.mu.RUnlock()
+	if ok && r.serve(cached.expiresAt, "id:"+id, func() error {
+		_, err := r.loadByID(id)
+		return err
+	}) {
+		return copyLocation(cached.location), nil
+	}
+
+	r.stats.misses.Add(1)
+	location, err := r.loadByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return copyLocation(location), nil
+}
+
+func (r *CachedLocationRepository) loadByID(id string) (*domain.Location, error) {
+	version := r.Version()
+	location, err := r.inner.FindByID(id)
+	if errors.Is(err, domain.ErrLocationNotFound) {
+		r.mu.RLock()
+		cached, ok := r.byID[id]
+		r.mu.RUnlock()
+		if ok {
+			r.drop(version, cached.location.Name)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.store(version, location)
+	return location, nil
+}
+
+func (r *CachedLocationRepository) FindAll() ([]*domain.Location, error) {
+	r.mu.RLock()
+	cached := r.all
+	r.mu.RUnlock()
+	if cached != nil && r.serve(cached.expiresAt, "all", func() error {
+		_, err := r.loadAll()
+		return err
+	}) {
+		return copyLocations(cached.locations), nil
+	}
+	r.stats.misses.Add(1)
+	return r.loadAll()
+}
+
+func (r *CachedLocationRepository) loadAll() ([]*domain.Location, error) {
+	version := r.Version()
+	locations, err := r.inner.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	// Skip caching a read that raced with an invalidation
+	if r.Version() == version {
+		r.all = &listEntry{locations: copyLocations(locations), expiresAt: r.now().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+	return locations, nil
+}
+
+// serve reports whether a cached entry expiring at expiresAt may be
+// returned, counting the hit. A stale entry may be, and starts a
+// refresh of key with load unless one is running; otherwise the caller
+// loads the entry itself.
+func (r *CachedLocationRepository) serve(expiresAt time.Time, key string, load func() error) bool {
+	now := r.now()
+	switch {
+	case now.Before(expiresAt):
+		r.stats.freshHits.Add(1)
+		return true
+	case now.Before(expiresAt.Add(r.staleTTL)):
+		r.stats.staleHits.Add(1)
+		r.refresh(key, load)
+		return true
+	}
+	return false
+}
+
+// refresh runs load in the background unless a refresh of key is running
+func (r *CachedLocationRepository) refresh(key string, load func() error) {
+	r.mu.Lock()
+	running := r.refreshing[key]
+	r.refreshing[key] = true
+	r.mu.Unlock()
+	if running {
+		return
+	}
+
+	go func() {
+		err := load()
+		r.mu.Lock()
+		delete(r.refreshing, key)
+		r.mu.Unlock()
+		// A location found gone is dropped, which is a refresh too
+		if err != nil && !errors.Is(err, domain.ErrLocationNotFound) {
+			r.stats.refreshErrors.Add(1)
+			return
+		}
+		r.stats.refreshes.Add(1)
+	}()
+}
+
+// Find is cached only for the unfiltered, unpaged listing in id order,
+// which is FindAll
+func (r *CachedLocationRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	if filter.IsZero() && page == (domain.Page{}) && order == (domain.LocationSort{}) {
+		return r.FindAll()
+	}
+	return r.inner.Find(filter, page, order)
+}
+
+// PagesSort reports the underlying repository's answer, false when it does
+// not page listings itself
+func (r *CachedLocationRepository) PagesSort(order domain.LocationSort) bool {
+	finder, ok := r.inner.(domain.PagedFinder)
+	return ok && finder.PagesSort(order)
+}
+
+// CountMatching is not cached, like Count. Without a count in the
+// underlying repository the matches are read and counted.
+func (r *CachedLocationRepository) CountMatching(filter domain.LocationFilter) (int, error) {
+	if finder, ok := r.inner.(domain.PagedFinder); ok {
+		return finder.CountMatching(filter)
+	}
+	locations, err := r.Find(filter, domain.Page{}, domain.LocationSort{})
+	return len(locations), err
+}
+
+// FindNearest is not cached; results depend on arbitrary coordinates
+func (r *CachedLocationRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	return r.inner.FindNearest(latitude, longitude, exclude...)
+}
+
+// FindNearestInRegion searches the underlying repository, which must
+// implement domain.RegionalNearestFinder; like FindNearest it is not cached
+func (r *CachedLocationRepository) FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	finder, ok := r.inner.(domain.RegionalNearestFinder)
+	if !ok {
+		return nil, 0, errors.New("underlying repository does not support region searches")
+	}
+	return finder.FindNearestInRegion(region, latitude, longitude, exclude...)
+}
+
+// FindWithinRadius searches the underlying repository, which must
+// implement domain.RadiusFinder; like FindNearest it is not cached
+func (r *CachedLocationRepository) FindWithinRadius(latitude, longitude float64, radius geospatial.Distance, limit int) ([]domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.RadiusFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support radius searches")
+	}
+	return finder.FindWithinRadius(latitude, longitude, radius, limit)
+}
+
+// FindNearestStocked searches the underlying repository, which must
+// implement domain.StockedNearestFinder; stock changes too often to cache
+func (r *CachedLocationRepository) FindNearestStocked(region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.StockedNearestFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support stock searches")
+	}
+	return finder.FindNearestStocked(region, minLitres, latitude, longitude, exclude...)
+}
+
+// FindNearestStockedWithin searches the underlying repository, which must
+// implement domain.BudgetedStockedFinder; like FindNearestStocked it is not
+// cached
+func (r *CachedLocationRepository) FindNearestStockedWithin(budget domain.ScanBudget, region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.BudgetedStockedFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support budgeted stock searches")
+	}
+	return finder.FindNearestStockedWithin(budget, region, minLitres, latitude, longitude, exclude...)
+}
+
+// SuggestLocations searches the underlying repository, which must
+// implement domain.LocationSuggester; like FindNearest it is not cached
+func (r *CachedLocationRepository) SuggestLocations(query domain.SuggestQuery) ([]domain.Suggestion, error) {
+	suggester, ok := r.inner.(domain.LocationSuggester)
+	if !ok {
+		return nil, domain.ErrSuggestionsUnsupported
+	}
+	return suggester.SuggestLocations(query)
+}
+
+// FindNearestBudgeted passes the search through when the underlying
+// repository has a scan budget, and otherwise answers exactly
+func (r *CachedLocationRepository) FindNearestBudgeted(region string, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	if finder, ok := r.inner.(domain.BudgetedNearestFinder); ok {
+		return finder.FindNearestBudgeted(region, latitude, longitude, exclude...)
+	}
+	search := r.FindNearest
+	if region != "" {
+		search = func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+			return r.FindNearestInRegion(region, latitude, longitude, exclude...)
+		}
+	}
+	location, distance, err := search(latitude, longitude, exclude...)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance}, nil
+}
+
+// Count is not cached; it is cheap and polled by the stats collector
+func (r *CachedLocationRepository) Count() (int, error) {
+	return r.inner.Count()
+}
+
+// Invalidate drops every cached entry for the location with this name or
+// alias, as deletes may name a location by either
+func (r *CachedLocationRepository) Invalidate(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropEntries(name)
+	r.all = nil
+	r.version.Add(1)
+}
+
+// InvalidateEntry drops the cached location with this name or alias but
+// keeps the cached listing, for changes to stock made by other replicas.
+// Until it expires the listing may show the stock as it was.
+func (r *CachedLocationRepository) InvalidateEntry(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropEntries(name)
+	r.version.Add(1)
+}
+
+// dropEntries removes the location's entries; the caller holds the lock
+func (r *CachedLocationRepository) dropEntries(name string) {
+	delete(r.byName, name)
+	for id, cached := range r.byID {
+		if cached.location.HasName(name) {
+			delete(r.byID, id)
+			delete(r.byName, cached.location.Name)
+		}
+	}
+}
+
+// replace swaps the cached copies of location for the updated one, in its
+// entries and in the listing, which readers may still be copying and so is
+// rebuilt rather than changed in place
+func (r *CachedLocationRepository) replace(location *domain.Location) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.byID[location.ID]; ok {
+		cached.location = copyLocation(location)
+		r.byID[location.ID] = cached
+		r.byName[location.Name] = cached
+	}
+	if r.all != nil {
+		if i := slices.IndexFunc(r.all.locations, func(l *domain.Location) bool { return l.ID == location.ID }); i >= 0 {
+			locations := slices.Clone(r.all.locations)
+			locations[i] = copyLocation(location)
+			r.all = &listEntry{locations: locations, expiresAt: r.all.expiresAt}
+		}
+	}
+	// Reads that started before the update must not store what they read
+	r.version.Add(1)
+}
+
+// Flush drops the whole cache, used when invalidations may have been missed
+func (r *CachedLocationRepository) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byName = make(map[string]entry)
+	r.byID = make(map[string]entry)
+	r.all = nil
+	r.version.Add(1)
+}
+
+// drop removes the cached entries for a location the repository no longer
+// has, unless the cache changed since version was read
+func (r *CachedLocationRepository) drop(version uint64, name string) {
+	r.mu.RLock()
+	_, cached := r.byName[name]
+	r.mu.RUnlock()
+	if cached && r.Version() == version {
+		r.Invalidate(name)
+	}
+}
+
+func (r *CachedLocationRepository) store(version uint64, location *domain.Location) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Version() != version {
+		return
+	}
+	cached := entry{location: copyLocation(location), expiresAt: r.now().Add(r.ttl)}
+	r.byName[location.Name] = cached
+	r.byID[location.ID] = cached
+}
+
+func copyLocation(location *domain.Location) *domain.Location {
+	clone := *location
+	clone.Aliases = slices.Clone(location.Aliases)
+	clone.Attachments = slices.Clone(location.Attachments)
+	return &clone
+}
+
+func copyLocations(locations []*domain.Location) []*domain.Location {
+	clones := make([]*domain.Location, len(locations))
+	for i, location := range locations {
+		clones[i] = copyLocation(location)
+	}
+	return clones
+}
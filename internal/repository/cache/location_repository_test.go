@@ -0,0 +1,165 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+// newCachedStore returns the cache and its backing store, so tests can
+// simulate another replica writing behind the cache's back
+func newCachedStore(t *testing.T) (*cache.CachedLocationRepository, *memory.InMemoryLocationRepository) {
+	t.Helper()
+	inner := memory.NewInMemoryLocationRepository()
+	return cache.NewCachedLocationRepository(inner, time.Hour), inner
+}
+
+func TestCacheServesUntilInvalidated(t *testing.T) {
+	t.Parallel()
+	repo, inner := newCachedStore(t)
+
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.FindByName("Station"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Another replica deletes the station directly
+	if err := inner.Delete("Station"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.FindByName("Station"); err != nil {
+		t.Errorf("Expected cached station before invalidation, got %v", err)
+	}
+	if _, err := repo.FindByID(location.ID); err != nil {
+		t.Errorf("Expected cached station by ID before invalidation, got %v", err)
+	}
+
+	version := repo.Version()
+	repo.Invalidate("Station")
+
+	if _, err := repo.FindByName("Station"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected not found after invalidation, got %v", err)
+	}
+	if repo.Version() == version {
+		t.Error("Expected version to change on invalidation")
+	}
+}
+
+func TestCacheLocalWritesInvalidateList(t *testing.T) {
+	t.Parallel()
+	repo, _ := newCachedStore(t)
+
+	if all, _ := repo.FindAll(); len(all) != 0 {
+		t.Fatalf("Expected empty list, got %d", len(all))
+	}
+
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if all, _ := repo.FindAll(); len(all) != 1 {
+		t.Errorf("Expected saved location in list, got %d", len(all))
+	}
+}
+
+func TestCacheFlush(t *testing.T) {
+	t.Parallel()
+	repo, inner := newCachedStore(t)
+
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	inner.Save(location)
+	repo.FindAll()
+	repo.FindByName("Station")
+	inner.Delete("Station")
+
+	repo.Flush()
+
+	if all, _ := repo.FindAll(); len(all) != 0 {
+		t.Errorf("Expected flushed list to be reloaded, got %d", len(all))
+	}
+	if _, err := repo.FindByName("Station"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected not found after flush, got %v", err)
+	}
+}
+
+func TestCacheReturnsCopies(t *testing.T) {
+	t.Parallel()
+	repo, _ := newCachedStore(t)
+
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	repo.Save(location)
+
+	first, _ := repo.FindByName("Station")
+	first.Latitude = 0
+
+	second, _ := repo.FindByName("Station")
+	if second.Latitude != 6.5 {
+		t.Errorf("Expected cached location to be unaffected by caller mutation, got %v", second.Latitude)
+	}
+}
+
+func TestCacheAliasChangesInvalidate(t *testing.T) {
+	t.Parallel()
+	repo, _ := newCachedStore(t)
+
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	repo.Save(location)
+	repo.FindByName("Station")
+
+	if _, err := repo.AddAlias("Station", "Depot"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cached, _ := repo.FindByName("Station"); len(cached.Aliases) != 1 {
+		t.Errorf("Expected the new alias after adding it, got %v", cached.Aliases)
+	}
+	if _, err := repo.RemoveAlias("Depot", "Depot"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cached, _ := repo.FindByName("Station"); len(cached.Aliases) != 0 {
+		t.Errorf("Expected no aliases after removing it, got %v", cached.Aliases)
+	}
+}
+
+func TestCacheStockUpdatesKeepList(t *testing.T) {
+	t.Parallel()
+	repo, _ := newCachedStore(t)
+
+	for _, name := range []string{"Station", "Depot"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.3)
+		repo.Save(location)
+	}
+	repo.FindAll()
+	repo.FindByName("Station")
+	misses := repo.CacheStats().Misses
+
+	stock := 1200.0
+	if _, err := repo.UpdateStock("Station", domain.StockUpdate{StockLitres: &stock}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	all, _ := repo.FindAll()
+	cached, _ := repo.FindByName("Station")
+	if got := repo.CacheStats().Misses; got != misses {
+		t.Errorf("Expected the list and entry still cached after a stock update, got %d more misses", got-misses)
+	}
+	if len(all) != 2 || all[0].CurrentStockLitres == nil || *all[0].CurrentStockLitres != 1200 {
+		t.Errorf("Expected the cached list to show the new stock, got %+v", all)
+	}
+	if cached.CurrentStockLitres == nil || *cached.CurrentStockLitres != 1200 {
+		t.Errorf("Expected the cached entry to show the new stock, got %v", cached.CurrentStockLitres)
+	}
+
+	// Another replica's stock update drops only the entry
+	repo.InvalidateEntry("Station")
+	repo.FindAll()
+	repo.FindByName("Station")
+	if got := repo.CacheStats().Misses; got != misses+1 {
+		t.Errorf("Expected only the entry reloaded, got %d more misses", got-misses)
+	}
+}
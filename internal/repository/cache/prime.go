@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// primeBatchSize is how many locations priming reads at a time
+const primeBatchSize = 500
+
+// Prime fills the cache ahead of requests: the full listing, and entries
+// for the locations with these names or aliases. It streams the underlying
+// repository when it implements domain.LocationStreamer and otherwise reads
+// it whole. Nothing is cached if a write lands while it reads, as the
+// listing would be stale; the first request then loads it as usual.
+func (r *CachedLocationRepository) Prime(ctx context.Context, names []string) (int, error) {
+	version := r.Version()
+	var locations []*domain.Location
+	if streamer, ok := r.inner.(domain.LocationStreamer); ok {
+		for location, err := range streamer.StreamLocations(ctx, primeBatchSize) {
+			if err != nil {
+				return len(locations), err
+			}
+			locations = append(locations, location)
+		}
+	} else {
+		var err error
+		if locations, err = r.inner.FindAll(); err != nil {
+			return 0, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return len(locations), err
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Version() != version {
+		return len(locations), errors.New("locations changed while priming")
+	}
+	expiresAt := r.now().Add(r.ttl)
+	r.all = &listEntry{locations: copyLocations(locations), expiresAt: expiresAt}
+	for _, location := range locations {
+		if !wanted[location.Name] && !hasWantedAlias(location, wanted) {
+			continue
+		}
+		cached := entry{location: copyLocation(location), expiresAt: expiresAt}
+		r.byName[location.Name] = cached
+		r.byID[location.ID] = cached
+	}
+	return len(locations), nil
+}
+
+// HotNames returns up to n of the names FindByName was asked for most
+// often, most asked first. Counts are estimates once more distinct names
+// were asked for than the cache tracks.
+func (r *CachedLocationRepository) HotNames(n int) []string {
+	return r.accesses.top(n)
+}
+
+func hasWantedAlias(location *domain.Location, wanted map[string]bool) bool {
+	for _, alias := range location.Aliases {
+		if wanted[alias] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package cache_test
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+func TestPrimeWarmsListingAndHotNames(t *testing.T) {
+	t.Parallel()
+	inner := memory.NewInMemoryLocationRepository()
+	for _, name := range []string{"Yaba", "Ikeja", "Lekki"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.3)
+		if err := inner.Save(location); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if _, err := inner.AddAlias("Lekki", "Lekki Phase 1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The previous run read Ikeja most, then the alias
+	previous := cache.NewCachedLocationRepository(inner, time.Hour)
+	for _, name := range []string{"Ikeja", "Lekki Phase 1", "Ikeja", "Yaba", "Ikeja", "Lekki Phase 1"} {
+		previous.FindByName(name)
+	}
+	names := previous.HotNames(2)
+	if !slices.Equal(names, []string{"Ikeja", "Lekki Phase 1"}) {
+		t.Fatalf("Expected the two most read names, got %v", names)
+	}
+
+	repo := cache.NewCachedLocationRepository(inner, time.Hour)
+	read, err := repo.Prime(context.Background(), names)
+	if err != nil || read != 3 {
+		t.Fatalf("Expected 3 locations primed, got %d, %v", read, err)
+	}
+	if all, err := repo.FindAll(); err != nil || len(all) != 3 {
+		t.Fatalf("Expected the primed listing, got %d, %v", len(all), err)
+	}
+	if _, err := repo.FindByName("Ikeja"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.FindByName("Lekki"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats := repo.CacheStats(); stats.Misses != 0 || stats.FreshHits != 3 {
+		t.Errorf("Expected only hits after priming, got %+v", stats)
+	}
+
+	// Yaba was not among the names, so it is read through
+	repo.FindByName("Yaba")
+	if stats := repo.CacheStats(); stats.Misses != 1 {
+		t.Errorf("Expected Yaba to miss, got %+v", stats)
+	}
+}
+
+func TestPrimeSkipsRacingWrite(t *testing.T) {
+	t.Parallel()
+	inner := &invalidatingStore{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	location, _ := domain.NewLocation("Yaba", 6.5, 3.3)
+	inner.Save(location)
+	repo := cache.NewCachedLocationRepository(inner, time.Hour)
+
+	// A write that invalidates mid-read leaves nothing cached
+	inner.invalidate = func() { repo.Invalidate("Yaba") }
+	if _, err := repo.Prime(context.Background(), []string{"Yaba"}); err == nil {
+		t.Fatal("Expected priming to fail when a write races it")
+	}
+	inner.invalidate = func() {}
+	repo.FindAll()
+	repo.FindByName("Yaba")
+	if stats := repo.CacheStats(); stats.Misses != 2 {
+		t.Errorf("Expected nothing cached by the racing prime, got %+v", stats)
+	}
+}
+
+// invalidatingStore calls invalidate when a stream starts, as a write from
+// another replica would
+type invalidatingStore struct {
+	*memory.InMemoryLocationRepository
+	invalidate func()
+}
+
+func (s *invalidatingStore) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	s.invalidate()
+	return s.InMemoryLocationRepository.StreamLocations(ctx, batchSize)
+}
@@ -0,0 +1,174 @@
+package cache_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+// fakeClock is read by background refreshes, so it is locked
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// slowRepository counts reads and, while gated, holds them until released
+type slowRepository struct {
+	*memory.InMemoryLocationRepository
+	reads atomic.Int32
+	gate  atomic.Pointer[chan struct{}]
+}
+
+func (r *slowRepository) wait() {
+	r.reads.Add(1)
+	if gate := r.gate.Load(); gate != nil {
+		<-*gate
+	}
+}
+
+func (r *slowRepository) FindAll() ([]*domain.Location, error) {
+	r.wait()
+	return r.InMemoryLocationRepository.FindAll()
+}
+
+func (r *slowRepository) FindByName(name string) (*domain.Location, error) {
+	r.wait()
+	return r.InMemoryLocationRepository.FindByName(name)
+}
+
+func newStaleCache(t *testing.T) (*cache.CachedLocationRepository, *slowRepository, *fakeClock) {
+	t.Helper()
+	inner := &slowRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	clock := &fakeClock{now: time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)}
+	repo := cache.NewCachedLocationRepository(inner, time.Minute, cache.WithStaleTTL(5*time.Minute), cache.WithClock(clock.Now))
+	return repo, inner, clock
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheServesStaleWhileRefreshing(t *testing.T) {
+	t.Parallel()
+	repo, inner, clock := newStaleCache(t)
+
+	station, _ := domain.NewLocation("Station", 6.5, 3.3)
+	inner.Save(station)
+	if all, _ := repo.FindAll(); len(all) != 1 {
+		t.Fatalf("Expected one location, got %d", len(all))
+	}
+
+	// Another replica writes, the entry expires and the store turns slow
+	depot, _ := domain.NewLocation("Depot", 6.6, 3.4)
+	inner.Save(depot)
+	clock.Advance(2 * time.Minute)
+	release := make(chan struct{})
+	inner.gate.Store(&release)
+
+	// Nobody waits on the slow store while the entry is in its stale window
+	var readers sync.WaitGroup
+	results := make(chan int, 10)
+	for range 10 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			all, _ := repo.FindAll()
+			results <- len(all)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		readers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		close(release)
+		t.Fatal("Expected stale reads not to wait for the refresh")
+	}
+	close(results)
+	for count := range results {
+		if count != 1 {
+			t.Errorf("Expected the stale list of one location, got %d", count)
+		}
+	}
+
+	// Exactly one refresh reached the store
+	waitFor(t, "the refresh to start", func() bool { return inner.reads.Load() == 2 })
+	close(release)
+	waitFor(t, "the refresh to finish", func() bool { return repo.CacheStats().Refreshes == 1 })
+	if all, _ := repo.FindAll(); len(all) != 2 {
+		t.Errorf("Expected the refreshed list of two locations, got %d", len(all))
+	}
+	if reads := inner.reads.Load(); reads != 2 {
+		t.Errorf("Expected one load and one refresh, got %d reads", reads)
+	}
+
+	stats := repo.CacheStats()
+	if stats.StaleHits != 10 || stats.FreshHits != 1 || stats.Misses != 1 || stats.RefreshErrors != 0 {
+		t.Errorf("Expected 10 stale hits, 1 fresh hit and 1 miss, got %+v", stats)
+	}
+}
+
+func TestCacheBlocksPastStaleCap(t *testing.T) {
+	t.Parallel()
+	repo, inner, clock := newStaleCache(t)
+
+	station, _ := domain.NewLocation("Station", 6.5, 3.3)
+	inner.Save(station)
+	repo.FindByName("Station")
+
+	// Past TTL plus the stale window the read goes to the store itself
+	inner.Delete("Station")
+	clock.Advance(6*time.Minute + time.Second)
+	if _, err := repo.FindByName("Station"); err != domain.ErrLocationNotFound {
+		t.Errorf("Expected not found once the stale window has passed, got %v", err)
+	}
+	if stats := repo.CacheStats(); stats.Misses != 2 || stats.StaleHits != 0 || stats.Refreshes != 0 {
+		t.Errorf("Expected two misses and no refresh, got %+v", stats)
+	}
+}
+
+func TestCacheStaleRefreshDropsDeleted(t *testing.T) {
+	t.Parallel()
+	repo, inner, clock := newStaleCache(t)
+
+	station, _ := domain.NewLocation("Station", 6.5, 3.3)
+	inner.Save(station)
+	repo.FindByName("Station")
+
+	inner.Delete("Station")
+	clock.Advance(2 * time.Minute)
+	if _, err := repo.FindByName("Station"); err != nil {
+		t.Fatalf("Expected the stale station, got %v", err)
+	}
+	waitFor(t, "the refresh to finish", func() bool { return repo.CacheStats().Refreshes == 1 })
+	if _, err := repo.FindByName("Station"); err != domain.ErrLocationNotFound {
+		t.Errorf("Expected not found after the refresh, got %v", err)
+	}
+}
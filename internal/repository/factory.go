@@ -1,12 +1,22 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/jesuloba-world/leeta-task/internal/config"
 	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/adaptive"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/faults"
 	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
 	"github.com/jesuloba-world/leeta-task/internal/repository/postgres"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 const (
@@ -14,25 +24,257 @@ const (
 	PostgresRepository = "postgres"
 )
 
-func NewRepositoryFromConfig(cfg config.Config) (domain.LocationRepository, func() error, error) {
+// Repositories groups the repositories sharing one storage backend
+type Repositories struct {
+	Locations domain.LocationRepository
+	Usage     domain.UsageRepository
+	Queries   domain.QueryRepository
+	Settings  domain.SettingsRepository
+	Jobs      domain.JobRepository
+	// Outbox is nil for backends that publish events directly
+	Outbox domain.OutboxRepository
+	// Events is nil for backends that keep no event log
+	Events domain.EventLog
+	// Changes records every mutation of the underlying store
+	Changes domain.ChangeLog
+	// ChangeCompactor is nil for backends that compact as they write
+	ChangeCompactor domain.ChangeCompactor
+	// StoreCompactor is nil for backends whose storage reclaims its own
+	// space
+	StoreCompactor domain.StoreCompactor
+	// Cache is nil unless CACHE_TTL is set; Locations then reads through it
+	Cache *cache.CachedLocationRepository
+	// Spatial verifies the underlying store, bypassing any cache
+	Spatial domain.SpatialVerifier
+	// Merger merges duplicate locations, invalidating any cache
+	Merger domain.LocationMerger
+	// Restorer restores snapshots with their IDs, invalidating any cache
+	Restorer domain.LocationRestorer
+	// Truncator empties the store, invalidating any cache
+	Truncator domain.LocationTruncator
+	// Integrity streams the underlying store and renames through any cache
+	Integrity domain.IntegrityStore
+	// Stats is nil for backends that report no store statistics
+	Stats domain.StoreStatsReporter
+	// Replica is nil unless location reads may go to a read replica
+	Replica domain.ReplicaChecker
+	// Faults is nil unless fault injection is enabled; Locations, Merger,
+	// Restorer, Truncator and Integrity then call the store through it
+	Faults domain.FaultInjector
+	// Timeouts is nil unless adaptive timeouts are enabled; Locations,
+	// Merger, Restorer, Truncator and Integrity then call the store
+	// through them, above any fault injection
+	Timeouts domain.TimeoutReporter
+	// Pinger is nil for backends with no database to lose
+	Pinger domain.Pinger
+}
+
+func NewRepositoryFromConfig(cfg config.Config) (*Repositories, func() error, error) {
+	collation, err := text.NewCollation(cfg.Names.Collation)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	switch cfg.Storage {
 	case MemoryRepository:
-		return memory.NewInMemoryLocationRepository(), func() error { return nil }, nil
-	case PostgresRepository:
-		pgConfig := postgres.Config{
-			Host:     cfg.Database.Host,
-			Port:     cfg.Database.Port,
-			User:     cfg.Database.User,
-			Password: cfg.Database.Password,
-			DBName:   cfg.Database.DBName,
-			SSLMode:  cfg.Database.SSLMode,
+		opts := []memory.Option{
+			memory.WithDistanceStrategy(geospatial.DistanceStrategy(cfg.DistanceStrategy)),
+			memory.WithSphere(geospatial.NewSphere(cfg.EarthRadiusKm)),
+			memory.WithChangeRetention(cfg.Changes.Retain),
+			memory.WithNameCollation(collation),
+			memory.WithScanBudget(domain.ScanBudget{Soft: cfg.NearestScan.SoftLimit, Hard: cfg.NearestScan.HardLimit}),
 		}
+		if cfg.Metrics.Enabled {
+			opts = append(opts, memory.WithMetrics())
+		}
+		locations := memory.NewInMemoryLocationRepository(opts...)
+		repos := &Repositories{
+			Locations: locations,
+			Usage:     memory.NewInMemoryUsageRepository(),
+			Queries:   memory.NewInMemoryQueryRepository(),
+			Settings:  memory.NewInMemorySettingsRepository(),
+			Jobs:      memory.NewInMemoryJobRepository(),
+			Changes:   locations,
+			Spatial:   locations,
+			Merger:    locations,
+			Restorer:  locations,
+			Truncator: locations,
+			Integrity: locations,
+			Stats:     locations,
+			// Go maps keep the room of removed locations until rebuilt
+			StoreCompactor: locations,
+		}
+		withFaults(repos, cfg.FaultInjection)
+		withTimeouts(repos, cfg.AdaptiveTimeout)
+		withCache(repos, cfg.Cache)
+		return repos, func() error { return nil }, nil
+	case PostgresRepository:
+		pgConfig := PostgresConfig(cfg.Database)
 		db, err := postgres.NewConnection(pgConfig)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
 		}
-		return postgres.NewPostgresLocationRepository(db), db.Close, nil
+		if cfg.Database.AutoMigrate {
+			if err := migrate(db, cfg.Database); err != nil {
+				db.Close()
+				return nil, nil, err
+			}
+		}
+		pgOpts := []postgres.Option{postgres.WithNameCollation(collation)}
+		// The replica is not pinged: reads stay on the primary until the
+		// first check finds it, so a replica that is down does not stop
+		// the service starting
+		closeDB := db.Close
+		var replicaDB *sql.DB
+		if cfg.Database.Replica.Host != "" {
+			replicaDB, err = postgres.Open(ReplicaPostgresConfig(cfg.Database))
+			if err != nil {
+				db.Close()
+				return nil, nil, fmt.Errorf("failed to open the read replica: %w", err)
+			}
+			pgOpts = append(pgOpts, postgres.WithReplica(replicaDB, time.Duration(cfg.Database.Replica.MaxLag)*time.Second))
+			closeDB = func() error { return errors.Join(replicaDB.Close(), db.Close()) }
+		}
+		locations := postgres.NewPostgresLocationRepository(db, pgOpts...)
+		outbox := postgres.NewPostgresOutboxRepository(db)
+		repos := &Repositories{
+			Locations: locations,
+			Usage:     postgres.NewPostgresUsageRepository(db),
+			Queries:   postgres.NewPostgresQueryRepository(db),
+			Settings:  postgres.NewPostgresSettingsRepository(db),
+			Jobs:      postgres.NewPostgresJobRepository(db),
+			Outbox:    outbox,
+			Events:    outbox,
+			Changes:   locations,
+			Spatial:   locations,
+			Merger:    locations,
+			Restorer:  locations,
+			Truncator: locations,
+			Integrity: locations,
+			// Compaction deletes rows, so it runs on a schedule rather
+			// than on the write path
+			ChangeCompactor: locations,
+			Pinger:          db,
+		}
+		if replicaDB != nil {
+			repos.Replica = locations
+		}
+		withFaults(repos, cfg.FaultInjection)
+		withTimeouts(repos, cfg.AdaptiveTimeout)
+		if !withCache(repos, cfg.Cache) {
+			return repos, closeDB, nil
+		}
+
+		// Other replicas write to the same database; drop their changes from
+		// the local cache as soon as they commit
+		listener, err := postgres.ListenForChanges(pgConfig.DSN(), repos.Cache.Invalidate, repos.Cache.InvalidateEntry, repos.Cache.Flush)
+		if err != nil {
+			closeDB()
+			return nil, nil, fmt.Errorf("failed to listen for location changes: %w", err)
+		}
+		return repos, func() error {
+			return errors.Join(listener.Close(), closeDB())
+		}, nil
 	default:
 		return nil, nil, fmt.Errorf("unsupported repository type: %s", cfg.Storage)
 	}
 }
+
+// migrate applies the pending migrations, logging whether this instance
+// applied them, found them applied by another or gave up waiting for one
+func migrate(db *sql.DB, cfg config.DatabaseConfig) error {
+	migrations, err := postgres.LoadMigrations(cfg.MigrationsDir)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	wait := time.Duration(cfg.MigrationLockWait) * time.Second
+	result, err := postgres.Migrate(context.Background(), db, migrations, wait)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to migrate the database: %w", err)
+	case !result.Locked:
+		log.Printf("Migration lock still held after %v; the schema is up to date, starting without it", result.Waited.Round(time.Millisecond))
+	case len(result.Applied) > 0:
+		log.Printf("Applied %d migrations, up to %s", len(result.Applied), result.Applied[len(result.Applied)-1].Name)
+	case result.Contended:
+		log.Printf("Waited %v for another instance's migrations; none left to apply", result.Waited.Round(time.Millisecond))
+	default:
+		log.Printf("Migrations up to date")
+	}
+	return nil
+}
+
+// withFaults puts fault injection between the store and everything
+// reading or writing locations through Repositories.Locations, below any
+// cache, as a failing database would be
+func withFaults(repos *Repositories, cfg config.FaultInjectionConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	injector := faults.NewInjector(time.Duration(cfg.MaxDuration) * time.Second)
+	faulty := faults.NewFaultyLocationRepository(repos.Locations, injector)
+	repos.Locations = faulty
+	repos.Merger = faulty
+	repos.Restorer = faulty
+	repos.Truncator = faulty
+	repos.Integrity = faulty
+	repos.Faults = injector
+}
+
+// withTimeouts limits reads to their adaptive timeout above fault
+// injection, so injected latency trips it as a slow database would, and
+// below any cache, so cache hits are never cut off
+func withTimeouts(repos *Repositories, cfg config.AdaptiveTimeoutConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	timeouts := adaptive.NewTimeouts(time.Duration(cfg.Floor)*time.Millisecond, cfg.Multiplier, time.Duration(cfg.Window)*time.Second)
+	timed := adaptive.NewTimeoutLocationRepository(repos.Locations, timeouts)
+	repos.Locations = timed
+	repos.Merger = timed
+	repos.Restorer = timed
+	repos.Truncator = timed
+	repos.Integrity = timed
+	repos.Timeouts = timed
+}
+
+func withCache(repos *Repositories, cfg config.CacheConfig) bool {
+	if cfg.TTL <= 0 {
+		return false
+	}
+	repos.Cache = cache.NewCachedLocationRepository(repos.Locations, time.Duration(cfg.TTL)*time.Second,
+		cache.WithStaleTTL(time.Duration(cfg.StaleTTL)*time.Second))
+	repos.Locations = repos.Cache
+	repos.Merger = repos.Cache
+	repos.Restorer = repos.Cache
+	repos.Truncator = repos.Cache
+	repos.Integrity = repos.Cache
+	return true
+}
+
+// PostgresConfig converts the database settings to a connection config
+func PostgresConfig(cfg config.DatabaseConfig) postgres.Config {
+	return postgres.Config{
+		Host:        cfg.Host,
+		Port:        cfg.Port,
+		User:        cfg.User,
+		Password:    cfg.Password,
+		DBName:      cfg.DBName,
+		SSLMode:     cfg.SSLMode,
+		SQLComments: cfg.SQLComments,
+	}
+}
+
+// ReplicaPostgresConfig converts the read replica settings to a
+// connection config
+func ReplicaPostgresConfig(cfg config.DatabaseConfig) postgres.Config {
+	return postgres.Config{
+		Host:        cfg.Replica.Host,
+		Port:        cfg.Replica.Port,
+		User:        cfg.Replica.User,
+		Password:    cfg.Replica.Password,
+		DBName:      cfg.Replica.DBName,
+		SSLMode:     cfg.Replica.SSLMode,
+		SQLComments: cfg.SQLComments,
+	}
+}
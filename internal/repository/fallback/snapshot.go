@@ -0,0 +1,115 @@
+// Package fallback keeps an in-memory copy of the locations to answer
+// nearest searches when the primary repository is unavailable.
+package fallback
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+var (
+	// ErrNoSnapshot is returned before the first successful refresh
+	ErrNoSnapshot = errors.New("no fallback snapshot taken yet")
+	// ErrSnapshotExpired is returned once the snapshot is older than its maximum age
+	ErrSnapshotExpired = errors.New("fallback snapshot is too old")
+)
+
+// Snapshot is a read-only copy of the primary repository held in a memory
+// repository. Refresh replaces the copy as a whole, so searches never see a
+// partly loaded snapshot. It implements domain.NearestFallback and
+// domain.RegionalNearestFallback.
+type Snapshot struct {
+	primary domain.LocationRepository
+	maxAge  time.Duration
+	options []memory.Option
+	now     func() time.Time
+
+	mu      sync.RWMutex
+	store   *memory.InMemoryLocationRepository
+	takenAt time.Time
+}
+
+// NewSnapshot creates an empty snapshot of primary. Answers are refused once
+// the snapshot is older than maxAge; 0 means no limit. options configure the
+// memory repository searched, such as its distance strategy.
+func NewSnapshot(primary domain.LocationRepository, maxAge time.Duration, options ...memory.Option) *Snapshot {
+	return &Snapshot{
+		primary: primary,
+		maxAge:  maxAge,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Refresh reloads every location from the primary repository. On failure
+// the previous snapshot is kept.
+func (s *Snapshot) Refresh(ctx context.Context) error {
+	takenAt := s.now()
+	locations, err := s.primary.FindAll()
+	if err != nil {
+		return err
+	}
+
+	store := memory.NewInMemoryLocationRepository(s.options...)
+	for _, location := range locations {
+		stored := *location
+		if err := store.Save(&stored); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+	s.takenAt = takenAt
+	return nil
+}
+
+// TakenAt returns the time of the last successful refresh
+func (s *Snapshot) TakenAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.takenAt
+}
+
+// FindNearest searches the snapshot, returning the time it was taken
+func (s *Snapshot) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, time.Time, error) {
+	store, takenAt, err := s.current()
+	if err != nil {
+		return nil, 0, takenAt, err
+	}
+	location, distance, err := store.FindNearest(latitude, longitude, exclude...)
+	return location, distance, takenAt, err
+}
+
+// FindNearestInRegion searches one region of the snapshot, returning the
+// time it was taken
+func (s *Snapshot) FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, time.Time, error) {
+	store, takenAt, err := s.current()
+	if err != nil {
+		return nil, 0, takenAt, err
+	}
+	location, distance, err := store.FindNearestInRegion(region, latitude, longitude, exclude...)
+	return location, distance, takenAt, err
+}
+
+// current returns the store to search, unless there is none or it is too old
+func (s *Snapshot) current() (*memory.InMemoryLocationRepository, time.Time, error) {
+	s.mu.RLock()
+	store, takenAt := s.store, s.takenAt
+	s.mu.RUnlock()
+
+	if store == nil {
+		return nil, time.Time{}, ErrNoSnapshot
+	}
+	if s.maxAge > 0 && s.now().Sub(takenAt) > s.maxAge {
+		return nil, takenAt, ErrSnapshotExpired
+	}
+	return store, takenAt, nil
+}
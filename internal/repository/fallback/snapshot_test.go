@@ -0,0 +1,95 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+type failingRepository struct {
+	*memory.InMemoryLocationRepository
+	err error
+}
+
+func (r *failingRepository) FindAll() ([]*domain.Location, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.InMemoryLocationRepository.FindAll()
+}
+
+func TestSnapshotRefreshAndStaleness(t *testing.T) {
+	primary := &failingRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	ikeja, _ := domain.NewLocation("Ikeja", 6.6018, 3.3515)
+	primary.Save(ikeja)
+
+	now := time.Date(2025, 8, 18, 10, 0, 0, 0, time.UTC)
+	snapshot := NewSnapshot(primary, 10*time.Minute)
+	snapshot.now = func() time.Time { return now }
+
+	if _, _, _, err := snapshot.FindNearest(6.5, 3.4); !errors.Is(err, ErrNoSnapshot) {
+		t.Fatalf("Expected ErrNoSnapshot before the first refresh, got %v", err)
+	}
+	if err := snapshot.Refresh(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+
+	location, distance, takenAt, err := snapshot.FindNearest(6.5, 3.4)
+	if err != nil || location.Name != "Ikeja" || distance <= 0 || !takenAt.Equal(now) {
+		t.Fatalf("Expected Ikeja from the snapshot taken now, got %v %v %v %v", location, distance, takenAt, err)
+	}
+	if location.ID != ikeja.ID {
+		t.Errorf("Expected the snapshot to keep ID %s, got %s", ikeja.ID, location.ID)
+	}
+
+	// A failed refresh keeps the previous snapshot
+	yaba, _ := domain.NewLocation("Yaba", 6.5095, 3.3711)
+	primary.Save(yaba)
+	primary.err = errors.New("database unavailable")
+	now = now.Add(5 * time.Minute)
+	if err := snapshot.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected the refresh to fail")
+	}
+	if location, _, takenAt, _ := snapshot.FindNearest(6.5095, 3.3711); location.Name != "Ikeja" || !takenAt.Equal(now.Add(-5*time.Minute)) {
+		t.Errorf("Expected the previous snapshot, got %s taken at %v", location.Name, takenAt)
+	}
+
+	now = now.Add(6 * time.Minute)
+	if _, _, _, err := snapshot.FindNearest(6.5, 3.4); !errors.Is(err, ErrSnapshotExpired) {
+		t.Errorf("Expected ErrSnapshotExpired past the maximum age, got %v", err)
+	}
+
+	primary.err = nil
+	if err := snapshot.Refresh(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+	if location, _, _, err := snapshot.FindNearest(6.5095, 3.3711); err != nil || location.Name != "Yaba" {
+		t.Errorf("Expected the refreshed snapshot to hold Yaba, got %v, %v", location, err)
+	}
+}
+
+func TestSnapshotFindNearestInRegion(t *testing.T) {
+	primary := memory.NewInMemoryLocationRepository()
+	ikeja, _ := domain.NewLocation("Ikeja", 6.6018, 3.3515)
+	ikeja.Region = "Lagos"
+	border, _ := domain.NewLocation("Border", 6.5010, 3.3700)
+	border.Region = "Abuja"
+	primary.Save(ikeja)
+	primary.Save(border)
+
+	snapshot := NewSnapshot(primary, 0)
+	if _, _, _, err := snapshot.FindNearestInRegion("Lagos", 6.5, 3.37); !errors.Is(err, ErrNoSnapshot) {
+		t.Fatalf("Expected ErrNoSnapshot before the first refresh, got %v", err)
+	}
+	if err := snapshot.Refresh(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+	location, _, _, err := snapshot.FindNearestInRegion("Lagos", 6.5, 3.37)
+	if err != nil || location.Name != "Ikeja" {
+		t.Errorf("Expected Ikeja, the only Lagos station, got %v, %v", location, err)
+	}
+}
@@ -0,0 +1,163 @@
+// Package faults injects errors and latency into the location repository
+// at runtime, so incident response can be rehearsed without touching the
+// database. It is compiled into every build but does nothing until
+// FAULT_INJECTION_ENABLED is set and faults are injected through
+// /admin/faults.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+// DefaultMaxDuration bounds how long faults stay in effect when no
+// maximum is configured
+const DefaultMaxDuration = 5 * time.Minute
+
+// Injector holds the faults in effect and clears them once they expire.
+// It implements domain.FaultInjector.
+type Injector struct {
+	maxDuration time.Duration
+
+	mu     sync.Mutex
+	plan   domain.FaultPlan
+	expiry *time.Timer
+
+	// chance and sleep are replaced by tests
+	chance func() float64
+	sleep  func(time.Duration)
+}
+
+// NewInjector creates an injector with no faults in effect, whose faults
+// last at most maxDuration, or DefaultMaxDuration when that is not
+// positive
+func NewInjector(maxDuration time.Duration) *Injector {
+	if maxDuration <= 0 {
+		maxDuration = DefaultMaxDuration
+	}
+	return &Injector{maxDuration: maxDuration, chance: rand.Float64, sleep: time.Sleep}
+}
+
+// Faults returns the faults in effect
+func (i *Injector) Faults() domain.FaultPlan {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return domain.FaultPlan{Faults: maps.Clone(i.plan.Faults), ExpiresAt: i.plan.ExpiresAt}
+}
+
+// InjectFaults replaces the faults in effect, clearing them after
+// duration or the injector's maximum, whichever is shorter
+func (i *Injector) InjectFaults(faults map[string]domain.Fault, duration time.Duration) (domain.FaultPlan, error) {
+	for method, fault := range faults {
+		if !slices.Contains(domain.FaultMethods, method) {
+			return domain.FaultPlan{}, fmt.Errorf("%w %q", domain.ErrUnknownFaultMethod, method)
+		}
+		if fault.ErrorRate < 0 || fault.ErrorRate > 1 {
+			return domain.FaultPlan{}, fmt.Errorf("error rate for %s must be between 0 and 1", method)
+		}
+		if fault.ErrorRate > 0 && faultError(fault.Kind) == nil {
+			return domain.FaultPlan{}, fmt.Errorf("unknown fault kind %q for %s", fault.Kind, method)
+		}
+		if fault.Latency < 0 {
+			return domain.FaultPlan{}, fmt.Errorf("latency for %s cannot be negative", method)
+		}
+	}
+	if duration <= 0 || duration > i.maxDuration {
+		duration = i.maxDuration
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.reset()
+	if len(faults) == 0 {
+		return domain.FaultPlan{}, nil
+	}
+	i.plan = domain.FaultPlan{Faults: maps.Clone(faults), ExpiresAt: time.Now().Add(duration)}
+	for method, fault := range faults {
+		metrics.FaultErrorRate.WithLabelValues(method).Set(fault.ErrorRate)
+		metrics.FaultLatency.WithLabelValues(method).Set(fault.Latency.Seconds())
+	}
+	plan := i.plan
+	i.expiry = time.AfterFunc(duration, func() { i.expire(plan.ExpiresAt) })
+	log.Printf("Fault injection: injecting faults into %v until %s", slices.Sorted(maps.Keys(faults)), plan.ExpiresAt.Format(time.RFC3339))
+	return domain.FaultPlan{Faults: maps.Clone(plan.Faults), ExpiresAt: plan.ExpiresAt}, nil
+}
+
+// ClearFaults removes every fault
+func (i *Injector) ClearFaults() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.plan.Faults) > 0 {
+		log.Printf("Fault injection: faults cleared")
+	}
+	i.reset()
+}
+
+// expire clears the faults set to expire at expiresAt, unless they have
+// been replaced since
+func (i *Injector) expire(expiresAt time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.plan.ExpiresAt.Equal(expiresAt) {
+		return
+	}
+	log.Printf("Fault injection: faults expired")
+	i.reset()
+}
+
+// reset clears the faults and their gauges; the caller holds mu
+func (i *Injector) reset() {
+	if i.expiry != nil {
+		i.expiry.Stop()
+		i.expiry = nil
+	}
+	i.plan = domain.FaultPlan{}
+	metrics.FaultErrorRate.Reset()
+	metrics.FaultLatency.Reset()
+}
+
+// inject applies the fault for method, if any: it waits out the added
+// latency, then returns the error the call should fail with, or nil
+func (i *Injector) inject(method string) error {
+	i.mu.Lock()
+	fault, ok := i.plan.Faults[method]
+	i.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if fault.Latency > 0 {
+		metrics.FaultsInjected.WithLabelValues(method, "latency").Inc()
+		i.sleep(fault.Latency)
+	}
+	if fault.ErrorRate <= 0 || i.chance() >= fault.ErrorRate {
+		return nil
+	}
+	metrics.FaultsInjected.WithLabelValues(method, string(fault.Kind)).Inc()
+	return fmt.Errorf("injected fault in %s: %w", method, faultError(fault.Kind))
+}
+
+var errInjected = errors.New("storage failure")
+
+// faultError is the error a fault of kind fails calls with, or nil for
+// an unknown kind
+func faultError(kind domain.FaultKind) error {
+	switch kind {
+	case domain.FaultInternal:
+		return errInjected
+	case domain.FaultTimeout:
+		return context.DeadlineExceeded
+	case domain.FaultNotFound:
+		return domain.ErrLocationNotFound
+	}
+	return nil
+}
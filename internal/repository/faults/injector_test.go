@@ -0,0 +1,189 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+func newFaulty(t *testing.T) (*FaultyLocationRepository, *Injector) {
+	t.Helper()
+	store := memory.NewInMemoryLocationRepository()
+	location, _ := domain.NewLocation("Ikeja", 6.6, 3.35)
+	if err := store.Save(location); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	injector := NewInjector(time.Minute)
+	t.Cleanup(injector.ClearFaults)
+	return NewFaultyLocationRepository(store, injector), injector
+}
+
+func TestFaultKinds(t *testing.T) {
+	repo, injector := newFaulty(t)
+
+	tests := []struct {
+		kind domain.FaultKind
+		want error
+	}{
+		{domain.FaultInternal, errInjected},
+		{domain.FaultTimeout, context.DeadlineExceeded},
+		{domain.FaultNotFound, domain.ErrLocationNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := injector.InjectFaults(map[string]domain.Fault{"FindByName": {ErrorRate: 1, Kind: tt.kind}}, 0); err != nil {
+			t.Fatalf("Failed to inject: %v", err)
+		}
+		if _, err := repo.FindByName("Ikeja"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.kind, tt.want, err)
+		}
+	}
+	if _, err := repo.FindByID("1"); err != nil {
+		t.Errorf("Expected a method without faults to pass through, got %v", err)
+	}
+}
+
+func TestNearestSearchesShareTheFault(t *testing.T) {
+	repo, injector := newFaulty(t)
+	injector.InjectFaults(map[string]domain.Fault{"FindNearest": {ErrorRate: 1, Kind: domain.FaultInternal}}, 0)
+
+	failures := metrics.FaultsInjected.WithLabelValues("FindNearest", "internal")
+	before := testutil.ToFloat64(failures)
+	if _, _, err := repo.FindNearest(6.5, 3.4); !errors.Is(err, errInjected) {
+		t.Errorf("Expected FindNearest to fail, got %v", err)
+	}
+	if _, _, err := repo.FindNearestInRegion("Lagos", 6.5, 3.4); !errors.Is(err, errInjected) {
+		t.Errorf("Expected FindNearestInRegion to fail, got %v", err)
+	}
+	if _, err := repo.FindNearestBudgeted("", 6.5, 3.4); !errors.Is(err, errInjected) {
+		t.Errorf("Expected FindNearestBudgeted to fail, got %v", err)
+	}
+	if got := testutil.ToFloat64(failures) - before; got != 3 {
+		t.Errorf("Expected 3 injected failures counted, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.FaultErrorRate.WithLabelValues("FindNearest")); got != 1 {
+		t.Errorf("Expected the error rate gauge at 1, got %v", got)
+	}
+}
+
+func TestErrorRateAndLatency(t *testing.T) {
+	repo, injector := newFaulty(t)
+	rolls := []float64{0.1, 0.9, 0.29, 0.3}
+	injector.chance = func() float64 {
+		roll := rolls[0]
+		rolls = rolls[1:]
+		return roll
+	}
+	var slept []time.Duration
+	injector.sleep = func(d time.Duration) { slept = append(slept, d) }
+	injector.InjectFaults(map[string]domain.Fault{
+		"Count": {ErrorRate: 0.3, Kind: domain.FaultTimeout, Latency: 250 * time.Millisecond},
+	}, 0)
+
+	var failed []bool
+	for range 4 {
+		_, err := repo.Count()
+		failed = append(failed, err != nil)
+	}
+	if want := []bool{true, false, true, false}; !slices.Equal(failed, want) {
+		t.Errorf("Expected calls rolling under the rate to fail %v, got %v", want, failed)
+	}
+	if len(slept) != 4 || slept[0] != 250*time.Millisecond {
+		t.Errorf("Expected every call delayed by 250ms, got %v", slept)
+	}
+
+	// A latency-only fault never fails
+	injector.InjectFaults(map[string]domain.Fault{"Count": {Latency: time.Millisecond}}, 0)
+	if _, err := repo.Count(); err != nil {
+		t.Errorf("Expected a latency-only fault to succeed, got %v", err)
+	}
+}
+
+func TestStreamFault(t *testing.T) {
+	repo, injector := newFaulty(t)
+	injector.InjectFaults(map[string]domain.Fault{"StreamLocations": {ErrorRate: 1, Kind: domain.FaultInternal}}, 0)
+	var yielded int
+	var streamErr error
+	for location, err := range repo.StreamLocations(context.Background(), 10) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		if location != nil {
+			yielded++
+		}
+	}
+	if yielded != 0 || !errors.Is(streamErr, errInjected) {
+		t.Errorf("Expected the stream to fail before any location, got %d locations and %v", yielded, streamErr)
+	}
+
+	injector.ClearFaults()
+	yielded = 0
+	for _, err := range repo.StreamLocations(context.Background(), 10) {
+		if err != nil {
+			t.Fatalf("Expected the stream to recover, got %v", err)
+		}
+		yielded++
+	}
+	if yielded != 1 {
+		t.Errorf("Expected 1 location streamed, got %d", yielded)
+	}
+}
+
+func TestFaultsExpire(t *testing.T) {
+	repo, _ := newFaulty(t)
+	injector := NewInjector(50 * time.Millisecond)
+	repo.injector = injector
+	t.Cleanup(injector.ClearFaults)
+
+	// Asking for longer than the maximum gets the maximum
+	plan, err := injector.InjectFaults(map[string]domain.Fault{"FindAll": {ErrorRate: 1, Kind: domain.FaultInternal}}, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to inject: %v", err)
+	}
+	if remaining := time.Until(plan.ExpiresAt); remaining > 50*time.Millisecond {
+		t.Errorf("Expected the duration capped at 50ms, got %v", remaining)
+	}
+	if _, err := repo.FindAll(); err == nil {
+		t.Fatal("Expected FindAll to fail while faulted")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(injector.Faults().Faults) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if faults := injector.Faults(); len(faults.Faults) != 0 || !faults.ExpiresAt.IsZero() {
+		t.Fatalf("Expected the faults to expire, got %+v", faults)
+	}
+	if _, err := repo.FindAll(); err != nil {
+		t.Errorf("Expected FindAll to recover after expiry, got %v", err)
+	}
+	if got := testutil.CollectAndCount(metrics.FaultErrorRate); got != 0 {
+		t.Errorf("Expected the gauges reset, got %d series", got)
+	}
+}
+
+func TestInjectFaultsRejectsInvalid(t *testing.T) {
+	injector := NewInjector(0)
+	invalid := []map[string]domain.Fault{
+		{"DropTable": {ErrorRate: 1, Kind: domain.FaultInternal}},
+		{"Save": {ErrorRate: 1.5, Kind: domain.FaultInternal}},
+		{"Save": {ErrorRate: 1, Kind: "teapot"}},
+		{"Save": {Latency: -time.Second}},
+	}
+	for _, faults := range invalid {
+		if _, err := injector.InjectFaults(faults, 0); err == nil {
+			t.Errorf("Expected %+v to be rejected", faults)
+		}
+	}
+	if len(injector.Faults().Faults) != 0 {
+		t.Error("Expected rejected faults to leave none in effect")
+	}
+}
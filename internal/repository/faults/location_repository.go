@@ -0,0 +1,291 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// FaultyLocationRepository passes every call through to another
+// repository after applying the injector's fault for the method. The
+// optional interfaces the stores implement are passed through too, and
+// fail as the cache does when the underlying repository lacks them.
+type FaultyLocationRepository struct {
+	inner    domain.LocationRepository
+	injector *Injector
+}
+
+// NewFaultyLocationRepository injects the faults injector holds into
+// calls to inner
+func NewFaultyLocationRepository(inner domain.LocationRepository, injector *Injector) *FaultyLocationRepository {
+	return &FaultyLocationRepository{inner: inner, injector: injector}
+}
+
+func (r *FaultyLocationRepository) Save(location *domain.Location) error {
+	if err := r.injector.inject("Save"); err != nil {
+		return err
+	}
+	return r.inner.Save(location)
+}
+
+func (r *FaultyLocationRepository) FindByName(name string) (*domain.Location, error) {
+	if err := r.injector.inject("FindByName"); err != nil {
+		return nil, err
+	}
+	return r.inner.FindByName(name)
+}
+
+func (r *FaultyLocationRepository) FindByID(id string) (*domain.Location, error) {
+	if err := r.injector.inject("FindByID"); err != nil {
+		return nil, err
+	}
+	return r.inner.FindByID(id)
+}
+
+func (r *FaultyLocationRepository) FindAll() ([]*domain.Location, error) {
+	if err := r.injector.inject("FindAll"); err != nil {
+		return nil, err
+	}
+	return r.inner.FindAll()
+}
+
+func (r *FaultyLocationRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	if err := r.injector.inject("Find"); err != nil {
+		return nil, err
+	}
+	return r.inner.Find(filter, page, order)
+}
+
+func (r *FaultyLocationRepository) Delete(name string, checks ...domain.Precondition) error {
+	if err := r.injector.inject("Delete"); err != nil {
+		return err
+	}
+	return r.inner.Delete(name, checks...)
+}
+
+func (r *FaultyLocationRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	if err := r.injector.inject("FindNearest"); err != nil {
+		return nil, 0, err
+	}
+	return r.inner.FindNearest(latitude, longitude, exclude...)
+}
+
+func (r *FaultyLocationRepository) Count() (int, error) {
+	if err := r.injector.inject("Count"); err != nil {
+		return 0, err
+	}
+	return r.inner.Count()
+}
+
+// PagesSort reports the underlying repository's answer, false when it does
+// not page listings itself
+func (r *FaultyLocationRepository) PagesSort(order domain.LocationSort) bool {
+	finder, ok := r.inner.(domain.PagedFinder)
+	return ok && finder.PagesSort(order)
+}
+
+// CountMatching is faulted as Count. Without a count in the underlying
+// repository the matches are read and counted.
+func (r *FaultyLocationRepository) CountMatching(filter domain.LocationFilter) (int, error) {
+	if err := r.injector.inject("Count"); err != nil {
+		return 0, err
+	}
+	if finder, ok := r.inner.(domain.PagedFinder); ok {
+		return finder.CountMatching(filter)
+	}
+	locations, err := r.inner.Find(filter, domain.Page{}, domain.LocationSort{})
+	return len(locations), err
+}
+
+// FindNearestInRegion is faulted as FindNearest
+func (r *FaultyLocationRepository) FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	finder, ok := r.inner.(domain.RegionalNearestFinder)
+	if !ok {
+		return nil, 0, errors.New("underlying repository does not support region searches")
+	}
+	if err := r.injector.inject("FindNearest"); err != nil {
+		return nil, 0, err
+	}
+	return finder.FindNearestInRegion(region, latitude, longitude, exclude...)
+}
+
+// FindWithinRadius is faulted as FindNearest
+func (r *FaultyLocationRepository) FindWithinRadius(latitude, longitude float64, radius geospatial.Distance, limit int) ([]domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.RadiusFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support radius searches")
+	}
+	if err := r.injector.inject("FindNearest"); err != nil {
+		return nil, err
+	}
+	return finder.FindWithinRadius(latitude, longitude, radius, limit)
+}
+
+// FindNearestBudgeted is faulted as FindNearest. It passes the search
+// through when the underlying repository has a scan budget, and
+// otherwise answers exactly.
+func (r *FaultyLocationRepository) FindNearestBudgeted(region string, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	if finder, ok := r.inner.(domain.BudgetedNearestFinder); ok {
+		if err := r.injector.inject("FindNearest"); err != nil {
+			return nil, err
+		}
+		return finder.FindNearestBudgeted(region, latitude, longitude, exclude...)
+	}
+	search := r.FindNearest
+	if region != "" {
+		search = func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+			return r.FindNearestInRegion(region, latitude, longitude, exclude...)
+		}
+	}
+	location, distance, err := search(latitude, longitude, exclude...)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance}, nil
+}
+
+// FindNearestStocked is faulted as FindNearest
+func (r *FaultyLocationRepository) FindNearestStocked(region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.StockedNearestFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support stock searches")
+	}
+	if err := r.injector.inject("FindNearest"); err != nil {
+		return nil, err
+	}
+	return finder.FindNearestStocked(region, minLitres, latitude, longitude, exclude...)
+}
+
+// FindNearestStockedWithin is faulted as FindNearest
+func (r *FaultyLocationRepository) FindNearestStockedWithin(budget domain.ScanBudget, region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	finder, ok := r.inner.(domain.BudgetedStockedFinder)
+	if !ok {
+		return nil, errors.New("underlying repository does not support budgeted stock searches")
+	}
+	if err := r.injector.inject("FindNearest"); err != nil {
+		return nil, err
+	}
+	return finder.FindNearestStockedWithin(budget, region, minLitres, latitude, longitude, exclude...)
+}
+
+func (r *FaultyLocationRepository) SuggestLocations(query domain.SuggestQuery) ([]domain.Suggestion, error) {
+	suggester, ok := r.inner.(domain.LocationSuggester)
+	if !ok {
+		return nil, domain.ErrSuggestionsUnsupported
+	}
+	if err := r.injector.inject("SuggestLocations"); err != nil {
+		return nil, err
+	}
+	return suggester.SuggestLocations(query)
+}
+
+func (r *FaultyLocationRepository) AddAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := r.inner.(domain.LocationAliaser)
+	if !ok {
+		return nil, errors.New("underlying repository does not support aliases")
+	}
+	if err := r.injector.inject("AddAlias"); err != nil {
+		return nil, err
+	}
+	return aliaser.AddAlias(name, alias, checks...)
+}
+
+func (r *FaultyLocationRepository) RemoveAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := r.inner.(domain.LocationAliaser)
+	if !ok {
+		return nil, errors.New("underlying repository does not support aliases")
+	}
+	if err := r.injector.inject("RemoveAlias"); err != nil {
+		return nil, err
+	}
+	return aliaser.RemoveAlias(name, alias, checks...)
+}
+
+func (r *FaultyLocationRepository) UpdateStock(name string, update domain.StockUpdate, checks ...domain.Precondition) (*domain.Location, error) {
+	updater, ok := r.inner.(domain.StockUpdater)
+	if !ok {
+		return nil, errors.New("underlying repository does not support stock updates")
+	}
+	if err := r.injector.inject("UpdateStock"); err != nil {
+		return nil, err
+	}
+	return updater.UpdateStock(name, update, checks...)
+}
+
+func (r *FaultyLocationRepository) ApplyOperations(ops []domain.LocationOperation) ([]domain.OperationResult, error) {
+	transactor, ok := r.inner.(domain.LocationTransactor)
+	if !ok {
+		return nil, errors.New("underlying repository does not support transactions")
+	}
+	if err := r.injector.inject("ApplyOperations"); err != nil {
+		return nil, err
+	}
+	return transactor.ApplyOperations(ops)
+}
+
+func (r *FaultyLocationRepository) MergeLocations(winner string, losers []string, actor string) (*domain.MergeAudit, error) {
+	merger, ok := r.inner.(domain.LocationMerger)
+	if !ok {
+		return nil, errors.New("underlying repository does not support merges")
+	}
+	if err := r.injector.inject("MergeLocations"); err != nil {
+		return nil, err
+	}
+	return merger.MergeLocations(winner, losers, actor)
+}
+
+func (r *FaultyLocationRepository) RestoreLocations(locations []domain.Location) (*domain.RestoreResult, error) {
+	restorer, ok := r.inner.(domain.LocationRestorer)
+	if !ok {
+		return nil, errors.New("underlying repository does not support restores")
+	}
+	if err := r.injector.inject("RestoreLocations"); err != nil {
+		return nil, err
+	}
+	return restorer.RestoreLocations(locations)
+}
+
+func (r *FaultyLocationRepository) TruncateLocations(actor string) (*domain.TruncateAudit, error) {
+	truncator, ok := r.inner.(domain.LocationTruncator)
+	if !ok {
+		return nil, errors.New("underlying repository does not support truncating")
+	}
+	if err := r.injector.inject("TruncateLocations"); err != nil {
+		return nil, err
+	}
+	return truncator.TruncateLocations(actor)
+}
+
+func (r *FaultyLocationRepository) RenameLocation(id, name string) error {
+	renamer, ok := r.inner.(domain.LocationRenamer)
+	if !ok {
+		return errors.New("underlying repository does not support renames")
+	}
+	if err := r.injector.inject("RenameLocation"); err != nil {
+		return err
+	}
+	return renamer.RenameLocation(id, name)
+}
+
+// StreamLocations fails, when faulted, before yielding any location
+func (r *FaultyLocationRepository) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	return func(yield func(*domain.Location, error) bool) {
+		streamer, ok := r.inner.(domain.LocationStreamer)
+		if !ok {
+			yield(nil, errors.New("underlying repository does not support streaming"))
+			return
+		}
+		if err := r.injector.inject("StreamLocations"); err != nil {
+			yield(nil, err)
+			return
+		}
+		for location, err := range streamer.StreamLocations(ctx, batchSize) {
+			if !yield(location, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"slices"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// resolve finds a location by its name or one of its aliases; the caller
+// holds the lock
+func (r *InMemoryLocationRepository) resolve(name string) (*domain.Location, bool) {
+	if location, exists := r.locations[name]; exists {
+		return location, true
+	}
+	if owner, exists := r.aliases[name]; exists {
+		return r.locations[owner], true
+	}
+	return nil, false
+}
+
+// AddAlias gives the location an alternate name. Adding an alias it
+// already has changes nothing.
+func (r *InMemoryLocationRepository) AddAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	location, exists := r.resolve(name)
+	if !exists {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := domain.CheckPreconditions(location, checks...); err != nil {
+		return nil, err
+	}
+	if owner, taken := r.resolve(alias); taken {
+		if owner == location && alias != location.Name {
+			return location, nil
+		}
+		return nil, &domain.NameTakenError{Name: alias, Owner: owner.Name}
+	}
+
+	// Replace rather than update, so locations handed out earlier keep
+	// their aliases
+	updated := *location
+	updated.Aliases = append(slices.Clone(location.Aliases), alias)
+	slices.Sort(updated.Aliases)
+	r.replace(&updated)
+	r.aliases[alias] = location.Name
+	r.record(domain.ChangeUpdated, updated.Name, &updated)
+	return &updated, nil
+}
+
+// RemoveAlias drops one of the location's alternate names
+func (r *InMemoryLocationRepository) RemoveAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	location, exists := r.resolve(name)
+	if !exists {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := domain.CheckPreconditions(location, checks...); err != nil {
+		return nil, err
+	}
+	if r.aliases[alias] != location.Name {
+		return nil, domain.ErrAliasNotFound
+	}
+
+	updated := *location
+	updated.Aliases = slices.DeleteFunc(slices.Clone(location.Aliases), func(a string) bool { return a == alias })
+	if len(updated.Aliases) == 0 {
+		updated.Aliases = nil
+	}
+	r.replace(&updated)
+	delete(r.aliases, alias)
+	r.record(domain.ChangeUpdated, updated.Name, &updated)
+	return &updated, nil
+}
+
+// replace stores a location, or an updated copy of one, under its name,
+// ID and region
+func (r *InMemoryLocationRepository) replace(location *domain.Location) {
+	if previous, exists := r.locationsById[location.ID]; exists {
+		r.unindexRegion(previous)
+	}
+	r.locations[location.Name] = location
+	r.locationsById[location.ID] = location
+	r.indexRegion(location)
+	r.index.Insert(location.ID, geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude})
+	r.names.set(location)
+}
+
+// remove forgets a location under every index
+func (r *InMemoryLocationRepository) remove(location *domain.Location) {
+	delete(r.locations, location.Name)
+	delete(r.locationsById, location.ID)
+	r.unindexRegion(location)
+	r.index.Remove(location.ID)
+	r.names.remove(location.ID)
+	r.dropAliases(location)
+	r.removed++
+}
+
+func (r *InMemoryLocationRepository) indexRegion(location *domain.Location) {
+	if location.Region == "" {
+		return
+	}
+	if r.byRegion[location.Region] == nil {
+		r.byRegion[location.Region] = make(map[string]*domain.Location)
+	}
+	r.byRegion[location.Region][location.Name] = location
+}
+
+func (r *InMemoryLocationRepository) unindexRegion(location *domain.Location) {
+	if index := r.byRegion[location.Region]; index != nil && index[location.Name] == location {
+		delete(index, location.Name)
+		if len(index) == 0 {
+			delete(r.byRegion, location.Region)
+		}
+	}
+}
+
+// dropAliases forgets the aliases of a removed location
+func (r *InMemoryLocationRepository) dropAliases(location *domain.Location) {
+	for _, alias := range location.Aliases {
+		delete(r.aliases, alias)
+	}
+}
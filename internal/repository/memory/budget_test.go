@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// scatter generates n locations spread over Nigeria, alternating between
+// two regions
+func scatter(n int, seed int64) []domain.Location {
+	rng := rand.New(rand.NewSource(seed))
+	locations := make([]domain.Location, n)
+	for i := range locations {
+		locations[i] = domain.Location{
+			ID:        fmt.Sprint(i + 1),
+			Name:      fmt.Sprintf("Station %06d", i),
+			Latitude:  4 + rng.Float64()*10,
+			Longitude: 3 + rng.Float64()*11,
+			Region:    []string{"south", "north"}[i%2],
+		}
+	}
+	return locations
+}
+
+func restored(t *testing.T, locations []domain.Location, opts ...Option) *InMemoryLocationRepository {
+	t.Helper()
+	repo := NewInMemoryLocationRepository(opts...)
+	if err := repo.Restore(locations); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	return repo
+}
+
+func TestFindNearestBudgetedPastSoftLimit(t *testing.T) {
+	t.Parallel()
+	locations := scatter(50000, 1)
+	exact := restored(t, locations)
+	budgeted := restored(t, locations, WithScanBudget(domain.ScanBudget{Soft: 10000, Hard: 5000}))
+
+	rng := rand.New(rand.NewSource(2))
+	matches := 0
+	const queries = 50
+	for i := 0; i < queries; i++ {
+		lat, lng := 4+rng.Float64()*10, 3+rng.Float64()*11
+		for _, region := range []string{"", "north"} {
+			want, wantDistance, _, err := exact.findNearest(region, 0, lat, lng, nil)
+			if err != nil {
+				t.Fatalf("Failed exact search: %v", err)
+			}
+			got, err := budgeted.FindNearestBudgeted(region, lat, lng)
+			if err != nil {
+				t.Fatalf("Failed budgeted search at (%f, %f): %v", lat, lng, err)
+			}
+			if !got.Approximate {
+				t.Errorf("Expected an approximate answer past the soft limit")
+			}
+			if region != "" && got.Location.Region != region {
+				t.Errorf("Expected a location in %s, got %s", region, got.Location.Region)
+			}
+			// The answer may miss a nearer location just outside the last
+			// ring, but never by more than a cell
+			if got.Distance < wantDistance || got.Distance > wantDistance+15000 {
+				t.Errorf("Expected about %v m, got %v m", wantDistance, got.Distance)
+			}
+			if got.Location.Name == want.Name {
+				matches++
+			}
+		}
+	}
+	if matches < 2*queries*9/10 {
+		t.Errorf("Expected the exact answer almost always, got %d of %d", matches, 2*queries)
+	}
+}
+
+func TestFindNearestBudgetedBelowSoftLimit(t *testing.T) {
+	t.Parallel()
+	locations := scatter(2000, 3)
+	exact := restored(t, locations)
+	budgeted := restored(t, locations, WithScanBudget(domain.ScanBudget{Soft: 2000, Hard: 2000}))
+
+	want, wantDistance, err := exact.FindNearest(6.5, 3.4, "Station 000000")
+	if err != nil {
+		t.Fatalf("Failed exact search: %v", err)
+	}
+	got, err := budgeted.FindNearestBudgeted("", 6.5, 3.4, "Station 000000")
+	if err != nil {
+		t.Fatalf("Failed budgeted search: %v", err)
+	}
+	if got.Approximate || got.Location.Name != want.Name || got.Distance != wantDistance {
+		t.Errorf("Expected the exact answer %s at %v, got %+v", want.Name, wantDistance, got)
+	}
+}
+
+func TestFindNearestBudgetedHardLimit(t *testing.T) {
+	t.Parallel()
+	// A thousand locations in one cell, so the grid cannot narrow the scan
+	clustered := make([]domain.Location, 1000)
+	for i := range clustered {
+		clustered[i] = domain.Location{
+			ID:        fmt.Sprint(i + 1),
+			Name:      fmt.Sprintf("Yaba %04d", i),
+			Latitude:  6.51 + float64(i)*0.00001,
+			Longitude: 3.37,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		budget domain.ScanBudget
+	}{
+		{"hard only", domain.ScanBudget{Hard: 500}},
+		{"grid past hard", domain.ScanBudget{Soft: 100, Hard: 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := restored(t, clustered, WithScanBudget(tt.budget))
+			if _, err := repo.FindNearestBudgeted("", 6.51, 3.37); !errors.Is(err, domain.ErrScanBudgetExceeded) {
+				t.Errorf("Expected ErrScanBudgetExceeded, got %v", err)
+			}
+			if _, _, err := repo.FindNearest(6.51, 3.37); !errors.Is(err, domain.ErrScanBudgetExceeded) {
+				t.Errorf("Expected FindNearest to keep the budget, got %v", err)
+			}
+		})
+	}
+}
+
+func TestScanBudgetGridFollowsWrites(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository(WithScanBudget(domain.ScanBudget{Soft: 1}))
+	for _, l := range []struct {
+		name     string
+		lat, lng float64
+	}{
+		{"Yaba", 6.5095, 3.3711},
+		{"Ikeja", 6.6018, 3.3515},
+		{"Kano", 12.0022, 8.592},
+	} {
+		location, _ := domain.NewLocation(l.name, l.lat, l.lng)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save: %v", err)
+		}
+	}
+
+	nearestName := func() string {
+		t.Helper()
+		got, err := repo.FindNearestBudgeted("", 6.51, 3.37)
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		if !got.Approximate {
+			t.Errorf("Expected an approximate answer")
+		}
+		return got.Location.Name
+	}
+
+	if name := nearestName(); name != "Yaba" {
+		t.Errorf("Expected Yaba, got %s", name)
+	}
+	if err := repo.Delete("Yaba"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if name := nearestName(); name != "Ikeja" {
+		t.Errorf("Expected Ikeja after deleting Yaba, got %s", name)
+	}
+
+	// A renamed location is found under its new name
+	ikeja, _ := repo.FindByName("Ikeja")
+	if err := repo.RenameLocation(ikeja.ID, "Ikeja Central"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if name := nearestName(); name != "Ikeja Central" {
+		t.Errorf("Expected the renamed Ikeja Central, got %s", name)
+	}
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// defaultChangeRetention is how many of the newest changes are kept
+// uncompacted unless WithChangeRetention says otherwise
+const defaultChangeRetention = 10000
+
+// changeLog is the in-memory change log. It compacts itself as it grows:
+// once it doubles past its size after the last compaction, superseded
+// changes outside the newest retain are dropped.
+type changeLog struct {
+	changes   []domain.Change
+	sequence  int64
+	retain    int
+	compactAt int
+}
+
+// WithChangeRetention sets how many of the newest changes are never
+// compacted away; the default is 10000
+func WithChangeRetention(retain int) Option {
+	return func(r *InMemoryLocationRepository) {
+		if retain >= 0 {
+			r.changes.retain = retain
+		}
+	}
+}
+
+// record appends a change; the caller holds the write lock
+func (r *InMemoryLocationRepository) record(action domain.ChangeAction, name string, location *domain.Location) {
+	l := &r.changes
+	l.sequence++
+	l.changes = append(l.changes, domain.Change{
+		Sequence:  l.sequence,
+		Action:    action,
+		Name:      name,
+		Location:  *location,
+		ChangedAt: time.Now().UTC(),
+	})
+	if len(l.changes) >= max(l.compactAt, 2*l.retain) {
+		l.compact(l.retain)
+	}
+}
+
+func (l *changeLog) compact(retain int) int {
+	before := len(l.changes)
+	l.changes = domain.CompactChanges(l.changes, retain)
+	l.compactAt = 2 * max(len(l.changes), 1)
+	return before - len(l.changes)
+}
+
+// ChangesSince returns up to limit changes with a sequence above since
+func (r *InMemoryLocationRepository) ChangesSince(since int64, limit int) (*domain.ChangeFeed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := r.changes.changes
+	start := sort.Search(len(changes), func(i int) bool { return changes[i].Sequence > since })
+	end := min(start+limit, len(changes))
+	return &domain.ChangeFeed{
+		Changes: append([]domain.Change{}, changes[start:end]...),
+		Latest:  r.changes.sequence,
+	}, nil
+}
+
+// CompactChanges drops superseded changes outside the newest retain
+func (r *InMemoryLocationRepository) CompactChanges(retain int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.changes.compact(retain), nil
+}
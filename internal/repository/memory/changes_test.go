@@ -0,0 +1,44 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+	repotest.RunChanges(t, memory.NewInMemoryLocationRepository())
+}
+
+func TestChangesCompactAsTheyGrow(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository(memory.WithChangeRetention(2))
+	for _, name := range []string{"Leeta Ikeja", "Leeta Yaba"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.35)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+	for range 10 {
+		if _, err := repo.AddAlias("Leeta Yaba", "Yaba Tech"); err != nil {
+			t.Fatalf("Failed to add alias: %v", err)
+		}
+		if _, err := repo.RemoveAlias("Leeta Yaba", "Yaba Tech"); err != nil {
+			t.Fatalf("Failed to remove alias: %v", err)
+		}
+	}
+
+	feed, _ := repo.ChangesSince(0, 100)
+	if len(feed.Changes) > 4 {
+		t.Errorf("Expected the log compacted to at most 4 changes, got %d", len(feed.Changes))
+	}
+	if first := feed.Changes[0]; first.Name != "Leeta Ikeja" || first.Action != domain.ChangeCreated {
+		t.Errorf("Expected Ikeja's only change kept, got %+v", first)
+	}
+	if last := feed.Changes[len(feed.Changes)-1]; last.Sequence != 22 || feed.Latest != 22 {
+		t.Errorf("Expected the newest change at sequence 22, got %d with latest %d", last.Sequence, feed.Latest)
+	}
+}
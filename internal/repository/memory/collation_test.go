@@ -0,0 +1,23 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+)
+
+func TestNameCollation(t *testing.T) {
+	t.Parallel()
+	for _, name := range repotest.Collations() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			collation, err := text.NewCollation(name)
+			if err != nil {
+				t.Fatalf("Failed to load collation: %v", err)
+			}
+			repotest.RunCollation(t, memory.NewInMemoryLocationRepository(memory.WithNameCollation(collation)), name)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// CompactStore rebuilds the indexes at the size of the locations they hold
+// once the locations removed since the last rebuild are at least
+// minRemovedRatio of those plus the live ones. Go maps never shrink, so a
+// store that churns through locations otherwise keeps the memory of the
+// most it ever held. Like a transaction, the rebuild is done and swapped
+// in under the write lock, so readers see every live location in the old
+// indexes or the new ones. It takes time linear in the number of
+// locations.
+func (r *InMemoryLocationRepository) CompactStore(minRemovedRatio float64) (domain.CompactionResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := r.removed
+	if removed == 0 || float64(removed) < minRemovedRatio*float64(removed+len(r.locations)) {
+		return domain.CompactionResult{}, nil
+	}
+
+	start := time.Now()
+	r.locations = shrink(r.locations)
+	r.locationsById = shrink(r.locationsById)
+	r.aliases = shrink(r.aliases)
+	byRegion := make(map[string]map[string]*domain.Location, len(r.byRegion))
+	for region, index := range r.byRegion {
+		byRegion[region] = shrink(index)
+	}
+	r.byRegion = byRegion
+	r.names = nameIndex{keys: slices.Clip(slices.Clone(r.names.keys)), at: shrink(r.names.at)}
+	r.index = r.index.Clone()
+	duration := time.Since(start)
+
+	r.removed = 0
+	r.compactions.Count++
+	r.compactions.Total += duration
+	r.reclaimed += uint64(removed)
+	return domain.CompactionResult{Compacted: true, Reclaimed: removed, Duration: duration}, nil
+}
+
+// shrink copies m into a map sized for its entries. maps.Clone is not
+// used, as it may copy the room of deleted entries along with the rest.
+func shrink[K comparable, V any](m map[K]V) map[K]V {
+	shrunk := make(map[K]V, len(m))
+	for k, v := range m {
+		shrunk[k] = v
+	}
+	return shrunk
+}
@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// churn saves n locations named prefix 0..n-1 across two regions, each with
+// an alias
+func churn(t *testing.T, repo *InMemoryLocationRepository, prefix string, n int) {
+	t.Helper()
+	for i := range n {
+		name := fmt.Sprintf("%s %d", prefix, i)
+		location, _ := domain.NewLocation(name, 6.4+float64(i%40)/100, 3.3+float64(i%50)/100)
+		location.Region = []string{"Lagos", "Ogun"}[i%2]
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+		if _, err := repo.AddAlias(name, name+" alias"); err != nil {
+			t.Fatalf("Failed to alias %s: %v", name, err)
+		}
+	}
+}
+
+// checkIndexes fails unless every index holds exactly the live locations
+func checkIndexes(t *testing.T, repo *InMemoryLocationRepository, live []string) {
+	t.Helper()
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if len(repo.locations) != len(live) || len(repo.locationsById) != len(live) || len(repo.names.keys) != len(live) ||
+		len(repo.names.at) != len(live) || len(repo.aliases) != len(live) {
+		t.Fatalf("Expected %d entries in every index, got %d by name, %d by ID, %d names at %d, %d aliases",
+			len(live), len(repo.locations), len(repo.locationsById), len(repo.names.keys), len(repo.names.at), len(repo.aliases))
+	}
+	regions := 0
+	for _, index := range repo.byRegion {
+		regions += len(index)
+	}
+	if regions != len(live) || repo.index.Len() != len(live) {
+		t.Fatalf("Expected %d locations by region and in the spatial index, got %d and %d", len(live), regions, repo.index.Len())
+	}
+	for _, name := range live {
+		location := repo.locations[name]
+		var indexed bool
+		if location != nil {
+			_, indexed = repo.index.Coordinate(location.ID)
+		}
+		if location == nil || repo.locationsById[location.ID] != location || repo.aliases[name+" alias"] != name ||
+			repo.byRegion[location.Region][name] != location || repo.names.keys[repo.names.at[location.ID]].location != location ||
+			!indexed {
+			t.Fatalf("Expected %s in every index", name)
+		}
+	}
+}
+
+func TestCompactStore(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository(WithScanBudget(domain.ScanBudget{Soft: 1}))
+	churn(t, repo, "Kept", 100)
+	churn(t, repo, "Churned", 800)
+	for i := range 800 {
+		if err := repo.Delete(fmt.Sprintf("Churned %d", i)); err != nil {
+			t.Fatalf("Failed to delete: %v", err)
+		}
+	}
+	// A rename leaves the old name behind in the name index too
+	kept, _ := repo.FindByName("Kept 0")
+	if err := repo.RenameLocation(kept.ID, "Kept renamed"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if _, err := repo.RemoveAlias("Kept renamed", "Kept 0 alias"); err != nil {
+		t.Fatalf("Failed to remove alias: %v", err)
+	}
+	if _, err := repo.AddAlias("Kept renamed", "Kept renamed alias"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+
+	if stats := repo.StoreStats(); stats.Removed != 801 {
+		t.Fatalf("Expected 801 removed, got %d", stats.Removed)
+	}
+	// 801 removed against 100 live is about 0.89
+	if result, err := repo.CompactStore(0.95); err != nil || result.Compacted {
+		t.Fatalf("Expected no compaction below the ratio, got %+v, %v", result, err)
+	}
+	result, err := repo.CompactStore(0.5)
+	if err != nil || !result.Compacted || result.Reclaimed != 801 {
+		t.Fatalf("Expected 801 reclaimed, got %+v, %v", result, err)
+	}
+
+	live := []string{"Kept renamed"}
+	for i := 1; i < 100; i++ {
+		live = append(live, fmt.Sprintf("Kept %d", i))
+	}
+	checkIndexes(t, repo, live)
+
+	stats := repo.StoreStats()
+	if stats.Removed != 0 || stats.Reclaimed != 801 || stats.Compactions.Count != 1 {
+		t.Errorf("Expected one compaction reclaiming 801, got %+v", stats)
+	}
+	if result, _ := repo.CompactStore(0); result.Compacted {
+		t.Errorf("Expected nothing to compact straight after compacting")
+	}
+
+	// The rebuilt indexes keep working
+	if _, err := repo.FindByName("Kept renamed alias"); err != nil {
+		t.Errorf("Expected the alias to resolve, got %v", err)
+	}
+	if err := repo.Delete("Kept 1"); err != nil {
+		t.Errorf("Failed to delete after compacting: %v", err)
+	}
+	churn(t, repo, "Added", 2)
+	live = append(slices.Delete(live, 1, 2), "Added 0", "Added 1")
+	checkIndexes(t, repo, live)
+}
+
+func TestCompactStoreCountsTransactions(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository()
+	churn(t, repo, "Station", 4)
+	ops := []domain.LocationOperation{
+		{Type: domain.OperationDelete, Name: "Station 0"},
+		{Type: domain.OperationDelete, Name: "Station 1"},
+	}
+	if _, err := repo.ApplyOperations(ops); err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if result, _ := repo.CompactStore(0.5); !result.Compacted || result.Reclaimed != 2 {
+		t.Fatalf("Expected the transaction's deletes reclaimed, got %+v", result)
+	}
+	checkIndexes(t, repo, []string{"Station 2", "Station 3"})
+}
+
+// TestCompactStoreConcurrentReads compacts while readers look up every
+// live location; run with -race
+func TestCompactStoreConcurrentReads(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository()
+	churn(t, repo, "Kept", 200)
+	kept := make([]*domain.Location, 200)
+	for i := range kept {
+		kept[i], _ = repo.FindByName(fmt.Sprintf("Kept %d", i))
+	}
+
+	var stop atomic.Bool
+	var missing atomic.Int64
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				for _, location := range kept {
+					byName, err := repo.FindByName(location.Name)
+					byID, idErr := repo.FindByID(location.ID)
+					if err != nil || idErr != nil || byName.ID != location.ID || byID.Name != location.Name {
+						missing.Add(1)
+					}
+				}
+				all, _ := repo.FindAll()
+				found := 0
+				for _, location := range all {
+					if strings.HasPrefix(location.Name, "Kept ") {
+						found++
+					}
+				}
+				if found != len(kept) {
+					missing.Add(1)
+				}
+			}
+		}()
+	}
+
+	compacted := 0
+	for round := range 20 {
+		prefix := fmt.Sprintf("Churned %d", round)
+		churn(t, repo, prefix, 50)
+		for i := range 50 {
+			repo.Delete(fmt.Sprintf("%s %d", prefix, i))
+		}
+		if result, err := repo.CompactStore(0.1); err != nil {
+			t.Fatalf("Failed to compact: %v", err)
+		} else if result.Compacted {
+			compacted++
+		}
+	}
+	stop.Store(true)
+	wg.Wait()
+
+	if compacted != 20 {
+		t.Errorf("Expected every round to compact, got %d", compacted)
+	}
+	if n := missing.Load(); n != 0 {
+		t.Fatalf("Expected readers to find every live location, %d reads missed", n)
+	}
+	checkIndexes(t, repo, func() []string {
+		names := make([]string, len(kept))
+		for i, location := range kept {
+			names[i] = location.Name
+		}
+		return names
+	}())
+}
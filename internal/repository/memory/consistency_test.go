@@ -0,0 +1,39 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/fallback"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func TestNearestConsistency(t *testing.T) {
+	t.Parallel()
+	cfg := repotest.ConsistencyConfigFromEnv(repotest.ConsistencyConfig{Stations: 2000, Queries: 1000, Seed: 1})
+	stations := repotest.ConsistencyStations(cfg)
+
+	exact := memory.NewInMemoryLocationRepository()
+	auto := memory.NewInMemoryLocationRepository(memory.WithDistanceStrategy(geospatial.DistanceAuto))
+	fast := memory.NewInMemoryLocationRepository(memory.WithDistanceStrategy(geospatial.DistanceFast))
+	for _, repo := range []*memory.InMemoryLocationRepository{exact, auto, fast} {
+		repotest.SaveStations(t, repo, stations)
+	}
+	snapshot := fallback.NewSnapshot(exact, 0)
+	if err := snapshot.Refresh(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+
+	repotest.RunNearestConsistency(t, cfg, stations,
+		repotest.ConsistencyBackend{Name: "memory/exact", Nearest: exact.FindNearest},
+		repotest.ConsistencyBackend{Name: "memory/auto", Nearest: auto.FindNearest},
+		repotest.ConsistencyBackend{Name: "fallback snapshot", Nearest: func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+			location, distance, _, err := snapshot.FindNearest(latitude, longitude, exclude...)
+			return location, distance, err
+		}},
+		repotest.ConsistencyBackend{Name: "memory/fast", Nearest: fast.FindNearest, Tolerance: 0.05},
+	)
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+)
+
+// compileFilter turns a filter into one predicate, checking only the
+// conditions the filter sets
+func compileFilter(filter domain.LocationFilter) func(*domain.Location) bool {
+	var conditions []func(*domain.Location) bool
+
+	if filter.AfterID != "" {
+		after := filter.AfterID
+		conditions = append(conditions, func(l *domain.Location) bool { return lessID(after, l.ID) })
+	}
+	if !filter.CreatedAfter.IsZero() {
+		after := filter.CreatedAfter
+		conditions = append(conditions, func(l *domain.Location) bool { return !l.CreatedAt.Before(after) })
+	}
+	if !filter.CreatedBefore.IsZero() {
+		before := filter.CreatedBefore
+		conditions = append(conditions, func(l *domain.Location) bool { return !l.CreatedAt.After(before) })
+	}
+	if filter.NameContains != "" {
+		needle := strings.ToLower(filter.NameContains)
+		if filter.SearchDescriptions {
+			conditions = append(conditions, func(l *domain.Location) bool {
+				return strings.Contains(strings.ToLower(l.Name), needle) || strings.Contains(strings.ToLower(l.Description), needle)
+			})
+		} else {
+			conditions = append(conditions, func(l *domain.Location) bool { return strings.Contains(strings.ToLower(l.Name), needle) })
+		}
+	}
+	if filter.Region != "" {
+		region := filter.Region
+		conditions = append(conditions, func(l *domain.Location) bool { return l.Region == region })
+	}
+	if filter.BBox != nil {
+		box := *filter.BBox
+		conditions = append(conditions, func(l *domain.Location) bool { return box.Contains(l.Latitude, l.Longitude) })
+	}
+
+	return func(l *domain.Location) bool {
+		for _, condition := range conditions {
+			if !condition(l) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// compileSort returns a less function for the order, breaking ties on id
+// as postgres does. Names compare under the collation; nil is byte order.
+func compileSort(order domain.LocationSort, collation *text.Collation) func(a, b *domain.Location) bool {
+	var compare func(a, b *domain.Location) int
+	switch order.Field {
+	case domain.SortByName:
+		compare = func(a, b *domain.Location) int { return collation.Compare(a.Name, b.Name) }
+	case domain.SortByCreatedAt:
+		compare = func(a, b *domain.Location) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	default:
+		compare = func(a, b *domain.Location) int { return 0 }
+	}
+
+	return func(a, b *domain.Location) bool {
+		c := compare(a, b)
+		if c == 0 {
+			if a.ID == b.ID {
+				return false
+			}
+			if lessID(a.ID, b.ID) {
+				c = -1
+			} else {
+				c = 1
+			}
+		}
+		if order.Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+}
+
+// paginate returns the window of locations the page selects
+func paginate(locations []*domain.Location, page domain.Page) []*domain.Location {
+	if page.Offset >= len(locations) {
+		return locations[:0]
+	}
+	locations = locations[page.Offset:]
+	if page.Limit > 0 && page.Limit < len(locations) {
+		locations = locations[:page.Limit]
+	}
+	return locations
+}
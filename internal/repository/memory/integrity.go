@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"iter"
+	"sort"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// StreamLocations yields copies of the locations in id order. The IDs are
+// listed up front and each batch is read under a short read lock, so
+// locations created during the stream are not seen and deleted ones are
+// skipped.
+func (r *InMemoryLocationRepository) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return func(yield func(*domain.Location, error) bool) {
+		r.mu.RLock()
+		ids := make([]string, 0, len(r.locationsById))
+		for id := range r.locationsById {
+			ids = append(ids, id)
+		}
+		r.mu.RUnlock()
+		sort.Slice(ids, func(i, j int) bool { return lessID(ids[i], ids[j]) })
+
+		for start := 0; start < len(ids); start += batchSize {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, location := range r.copyByID(ids[start:min(start+batchSize, len(ids))]) {
+				if !yield(location, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *InMemoryLocationRepository) copyByID(ids []string) []*domain.Location {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locations := make([]*domain.Location, 0, len(ids))
+	for _, id := range ids {
+		if location, exists := r.locationsById[id]; exists {
+			copied := *location
+			locations = append(locations, &copied)
+		}
+	}
+	return locations
+}
+
+// RenameLocation replaces the location with a renamed copy, so pointers
+// handed out earlier keep the old name
+func (r *InMemoryLocationRepository) RenameLocation(id, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	location, exists := r.locationsById[id]
+	if !exists {
+		return domain.ErrLocationNotFound
+	}
+	if location.Name == name {
+		return nil
+	}
+	if owner, taken := r.resolve(name); taken {
+		return domain.NameConflict(name, owner)
+	}
+
+	renamed := *location
+	renamed.Name = name
+	r.rename(location, &renamed)
+	return nil
+}
+
+// rename replaces location with an updated copy under a new name and
+// records the change as a delete of the old name; the caller holds the
+// write lock and has checked the new name is free
+func (r *InMemoryLocationRepository) rename(location, renamed *domain.Location) {
+	delete(r.locations, location.Name)
+	r.removed++
+	r.replace(renamed)
+	for _, alias := range renamed.Aliases {
+		r.aliases[alias] = renamed.Name
+	}
+	r.record(domain.ChangeDeleted, location.Name, location)
+	r.record(domain.ChangeUpdated, renamed.Name, renamed)
+}
@@ -0,0 +1,20 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestIntegrityStore(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	repotest.RunIntegrityStore(t, repo, func(name string, latitude, longitude float64) {
+		// Save stores what it is given, as an old import would have
+		if err := repo.Save(&domain.Location{Name: name, Latitude: latitude, Longitude: longitude}); err != nil {
+			t.Fatalf("Failed to seed %q: %v", name, err)
+		}
+	})
+}
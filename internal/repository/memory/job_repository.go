@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type InMemoryJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]*domain.ImportJob
+	data map[string][]byte
+	// queue holds the IDs of pending jobs, oldest first
+	queue []string
+}
+
+func NewInMemoryJobRepository() *InMemoryJobRepository {
+	return &InMemoryJobRepository{
+		jobs: make(map[string]*domain.ImportJob),
+		data: make(map[string][]byte),
+	}
+}
+
+func (r *InMemoryJobRepository) CreateImport(job *domain.ImportJob, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.ID] = copyImport(job)
+	// The caller's buffer may be reused, as a request body is
+	r.data[job.ID] = slices.Clone(data)
+	r.queue = append(r.queue, job.ID)
+	return nil
+}
+
+func (r *InMemoryJobRepository) FindImport(id string) (*domain.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, domain.ErrImportNotFound
+	}
+	return copyImport(job), nil
+}
+
+func (r *InMemoryJobRepository) ClaimImport(now time.Time) (*domain.ImportJob, []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for len(r.queue) > 0 {
+		id := r.queue[0]
+		r.queue = r.queue[1:]
+		job := r.jobs[id]
+		// A job cancelled while queued stays in the queue until here
+		if job == nil || job.Status != domain.ImportPending {
+			continue
+		}
+		job.Status = domain.ImportRunning
+		job.StartedAt = &now
+		job.UpdatedAt = now
+		return copyImport(job), r.data[id], nil
+	}
+	return nil, nil, nil
+}
+
+func (r *InMemoryJobRepository) UpdateImport(job *domain.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[job.ID]
+	if !ok {
+		return domain.ErrImportNotFound
+	}
+	updated := copyImport(job)
+	updated.CancelRequested = stored.CancelRequested
+	r.jobs[job.ID] = updated
+	job.CancelRequested = stored.CancelRequested
+	if job.Status.Finished() {
+		delete(r.data, job.ID)
+	}
+	return nil
+}
+
+func (r *InMemoryJobRepository) CancelImport(id string, now time.Time) (*domain.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, domain.ErrImportNotFound
+	}
+	switch job.Status {
+	case domain.ImportPending:
+		job.Status = domain.ImportCancelled
+		job.UpdatedAt = now
+		job.FinishedAt = &now
+		delete(r.data, id)
+	case domain.ImportRunning:
+		job.CancelRequested = true
+	}
+	return copyImport(job), nil
+}
+
+func (r *InMemoryJobRepository) FailStaleImports(before time.Time, reason string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failed := 0
+	for id, job := range r.jobs {
+		if job.Status != domain.ImportRunning || !job.UpdatedAt.Before(before) {
+			continue
+		}
+		now := time.Now()
+		job.Status = domain.ImportFailed
+		job.Error = reason
+		job.UpdatedAt = now
+		job.FinishedAt = &now
+		delete(r.data, id)
+		failed++
+	}
+	return failed, nil
+}
+
+// copyImport keeps callers from changing a stored job through its pointers
+func copyImport(job *domain.ImportJob) *domain.ImportJob {
+	copied := *job
+	copied.Errors = slices.Clone(job.Errors)
+	return &copied
+}
@@ -1,48 +1,153 @@
 package memory
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"math"
-	"sync"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/text"
 	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 type InMemoryLocationRepository struct {
-	mu        sync.RWMutex
-	locations map[string]*domain.Location // key is name
+	mu            sampledMutex
+	locations     map[string]*domain.Location // key is name
 	locationsById map[string]*domain.Location // key is ID
-	nextID    int
+	aliases       map[string]string           // alias to the owner's name
+	nextID        int
+	distance      geospatial.DistanceStrategy
+	sphere        geospatial.Sphere
+	collation     *text.Collation
+	merges        []domain.MergeAudit
+	truncations   []domain.TruncateAudit
+
+	// byRegion indexes locations by region, then name, so region-scoped
+	// searches scan only their region
+	byRegion map[string]map[string]*domain.Location
+
+	// names holds each location's name prepared for suggestions
+	names nameIndex
+
+	// index finds the locations nearest a point by ID, for exact searches
+	// of the whole store and for the approximate search past the soft
+	// scan limit
+	index *geospatial.Index
+
+	// budget bounds nearest searches
+	budget domain.ScanBudget
+
+	// metrics is nil unless WithMetrics is given
+	metrics *storeMetrics
+
+	// changes records every mutation for incremental sync
+	changes changeLog
+
+	// removed counts locations removed or renamed since CompactStore last
+	// rebuilt the indexes; compactions and reclaimed are its totals
+	removed     int
+	compactions domain.OperationStats
+	reclaimed   uint64
+}
+
+// Option configures an InMemoryLocationRepository
+type Option func(*InMemoryLocationRepository)
+
+// WithDistanceStrategy selects how FindNearest measures distance; the
+// default is geospatial.DistanceExact
+func WithDistanceStrategy(strategy geospatial.DistanceStrategy) Option {
+	return func(r *InMemoryLocationRepository) {
+		if strategy != "" {
+			r.distance = strategy
+		}
+	}
+}
+
+// WithSphere sets the reference sphere FindNearest measures on; the
+// default is geospatial.Earth
+func WithSphere(sphere geospatial.Sphere) Option {
+	return func(r *InMemoryLocationRepository) {
+		r.sphere = sphere
+	}
 }
 
-func NewInMemoryLocationRepository() *InMemoryLocationRepository {
-	return &InMemoryLocationRepository{
-		locations: make(map[string]*domain.Location),
+// WithNameCollation sets how Find orders names; the default, nil, is
+// byte order
+func WithNameCollation(collation *text.Collation) Option {
+	return func(r *InMemoryLocationRepository) {
+		r.collation = collation
+	}
+}
+
+// WithScanBudget bounds the locations one nearest search examines. With
+// more candidates than the soft limit, the search looks only at index cells
+// near the query point and its answer is approximate; past the hard limit
+// it returns domain.ErrScanBudgetExceeded. The default examines every
+// candidate.
+func WithScanBudget(budget domain.ScanBudget) Option {
+	return func(r *InMemoryLocationRepository) {
+		r.budget = budget
+	}
+}
+
+func NewInMemoryLocationRepository(opts ...Option) *InMemoryLocationRepository {
+	r := &InMemoryLocationRepository{
+		locations:     make(map[string]*domain.Location),
 		locationsById: make(map[string]*domain.Location),
-		nextID:    1,
+		aliases:       make(map[string]string),
+		byRegion:      make(map[string]map[string]*domain.Location),
+		names:         newNameIndex(0),
+		nextID:        1,
+		distance:      geospatial.DistanceExact,
+		sphere:        geospatial.Earth,
+		changes:       changeLog{retain: defaultChangeRetention},
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	r.index = geospatial.NewIndex(r.sphere)
+	return r
 }
 
 func (r *InMemoryLocationRepository) Save(location *domain.Location) error {
+	if r.metrics != nil {
+		defer r.metrics.saves.since(time.Now())
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.save(location)
+}
+
+// save stores a new location; the caller holds the write lock
+func (r *InMemoryLocationRepository) save(location *domain.Location) error {
 	if location == nil {
 		return fmt.Errorf("location cannot be nil")
 	}
 
-	if _, exists := r.locations[location.Name]; exists {
-		return domain.ErrLocationExists
+	if owner, exists := r.resolve(location.Name); exists {
+		return domain.NameConflict(location.Name, owner)
+	}
+	location.Aliases = nil
+	// Like the PostgreSQL column default, a missing creation time is now
+	if location.CreatedAt.IsZero() {
+		location.CreatedAt = time.Now()
 	}
 
 	if location.ID == "" {
 		location.ID = fmt.Sprintf("%d", r.nextID)
 		r.nextID++
+	} else {
+		r.reserveID(location.ID)
 	}
 
-	r.locations[location.Name] = location
-	r.locationsById[location.ID] = location
+	r.replace(location)
+	r.record(domain.ChangeCreated, location.Name, location)
 	return nil
 }
 
@@ -50,7 +155,7 @@ func (r *InMemoryLocationRepository) FindByName(name string) (*domain.Location,
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	location, exists := r.locations[name]
+	location, exists := r.resolve(name)
 	if !exists {
 		return nil, domain.ErrLocationNotFound
 	}
@@ -59,53 +164,262 @@ func (r *InMemoryLocationRepository) FindByName(name string) (*domain.Location,
 }
 
 func (r *InMemoryLocationRepository) FindAll() ([]*domain.Location, error) {
+	return r.Find(domain.LocationFilter{}, domain.Page{}, domain.LocationSort{})
+}
+
+func (r *InMemoryLocationRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	match := compileFilter(filter)
+
+	r.mu.RLock()
+	source := r.locations
+	if filter.Region != "" {
+		source = r.byRegion[filter.Region]
+	}
+	locations := make([]*domain.Location, 0, len(source))
+	for _, location := range source {
+		if match(location) {
+			locations = append(locations, location)
+		}
+	}
+	r.mu.RUnlock()
+
+	// Match the postgres repository, which breaks ties on id
+	less := compileSort(order, r.collation)
+	sort.Slice(locations, func(i, j int) bool {
+		return less(locations[i], locations[j])
+	})
+
+	return paginate(locations, page), nil
+}
+
+// PagesSort is true for every order: Find sorts the whole listing before
+// cutting the page
+func (r *InMemoryLocationRepository) PagesSort(domain.LocationSort) bool {
+	return true
+}
+
+// CountMatching counts the locations passing filter
+func (r *InMemoryLocationRepository) CountMatching(filter domain.LocationFilter) (int, error) {
+	match := compileFilter(filter)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	source := r.locations
+	if filter.Region != "" {
+		source = r.byRegion[filter.Region]
+	}
+	count := 0
+	for _, location := range source {
+		if match(location) {
+			count++
+		}
+	}
+	return count, nil
+}
 
-	locations := make([]*domain.Location, 0, len(r.locations))
-	for _, location := range r.locations {
-		locations = append(locations, location)
+// lessID orders numeric IDs numerically and falls back to string comparison
+func lessID(a, b string) bool {
+	ai, errA := strconv.Atoi(a)
+	bi, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return ai < bi
 	}
+	return a < b
+}
 
-	return locations, nil
+// compareTied orders locations at the same distance from a nearest query
+// by name, then ID, as the postgres repository does, so a tie answers the
+// same on every call and on both backends
+func compareTied(a, b *domain.Location) int {
+	if c := strings.Compare(a.Name, b.Name); c != 0 {
+		return c
+	}
+	switch {
+	case lessID(a.ID, b.ID):
+		return -1
+	case lessID(b.ID, a.ID):
+		return 1
+	}
+	return 0
+}
+
+// compareTiedIDs is compareTied for the index, which knows locations by
+// ID; the caller holds the lock
+func (r *InMemoryLocationRepository) compareTiedIDs(a, b string) int {
+	return compareTied(r.locationsById[a], r.locationsById[b])
 }
 
-func (r *InMemoryLocationRepository) Delete(name string) error {
+func (r *InMemoryLocationRepository) Delete(name string, checks ...domain.Precondition) error {
+	if r.metrics != nil {
+		defer r.metrics.deletes.since(time.Now())
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.locations[name]; !exists {
-		return domain.ErrLocationNotFound
+	_, err := r.delete(name, checks...)
+	return err
+}
+
+// delete removes the location found by name, once it passes checks, and
+// returns it; the caller holds the write lock
+func (r *InMemoryLocationRepository) delete(name string, checks ...domain.Precondition) (*domain.Location, error) {
+	location, exists := r.resolve(name)
+	if !exists {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := domain.CheckPreconditions(location, checks...); err != nil {
+		return nil, err
 	}
 
-	delete(r.locations, name)
-	return nil
+	r.remove(location)
+	r.record(domain.ChangeDeleted, location.Name, location)
+	return location, nil
+}
+
+func (r *InMemoryLocationRepository) Count() (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.locations), nil
+}
+
+func (r *InMemoryLocationRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	location, distance, _, err := r.findNearest("", 0, latitude, longitude, exclude)
+	return location, distance, err
+}
+
+// FindNearestInRegion searches only the region's index
+func (r *InMemoryLocationRepository) FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	location, distance, _, err := r.findNearest(region, 0, latitude, longitude, exclude)
+	return location, distance, err
 }
 
-func (r *InMemoryLocationRepository) FindNearest(latitude, longitude float64) (*domain.Location, float64, error) {
+// FindNearestBudgeted reports whether the answer is approximate because
+// the search went past the soft scan limit
+func (r *InMemoryLocationRepository) FindNearestBudgeted(region string, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if len(r.locations) == 0 {
-		return nil, 0, domain.ErrLocationNotFound
+	location, distance, approximate, err := r.findNearest(region, 0, latitude, longitude, exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance, Approximate: approximate}, nil
+}
+
+// FindWithinRadius reads the points near the query from the spatial
+// index, which measures on the sphere whatever the distance strategy
+func (r *InMemoryLocationRepository) FindWithinRadius(latitude, longitude float64, radius geospatial.Distance, limit int) ([]domain.NearestResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query := geospatial.Coordinate{Latitude: latitude, Longitude: longitude}
+	found := r.index.WithinRadiusFunc(query, radius.Kilometers(), r.compareTiedIDs)
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	results := make([]domain.NearestResult, len(found))
+	for i, result := range found {
+		results[i] = domain.NearestResult{Location: r.locationsById[result.ID], Distance: result.Distance}
+	}
+	return results, nil
+}
+
+// findNearest searches one region, or every location when region is
+// empty, over the locations holding at least minStock litres, within the
+// repository's scan budget; the caller holds the lock
+func (r *InMemoryLocationRepository) findNearest(region string, minStock, latitude, longitude float64, exclude []string) (*domain.Location, geospatial.Distance, bool, error) {
+	return r.findNearestWithin(r.budget, region, minStock, latitude, longitude, exclude)
+}
+
+// findNearestWithin is findNearest within budget
+func (r *InMemoryLocationRepository) findNearestWithin(budget domain.ScanBudget, region string, minStock, latitude, longitude float64, exclude []string) (*domain.Location, geospatial.Distance, bool, error) {
+	candidates := r.locations
+	if region != "" {
+		candidates = r.byRegion[region]
+	}
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+	skip := func(location *domain.Location) bool {
+		return excluded[location.Name] || !location.HasStock(minStock)
+	}
+	query := geospatial.Coordinate{Latitude: latitude, Longitude: longitude}
+
+	match := func(id string) bool {
+		location := r.locationsById[id]
+		return !skip(location) && (region == "" || location.Region == region)
+	}
+
+	if budget.Soft > 0 && len(candidates) > budget.Soft {
+		result, found, err := r.index.NearestApproximateFunc(query, budget.Hard, match, r.compareTiedIDs)
+		if errors.Is(err, geospatial.ErrScanLimit) {
+			return nil, 0, false, domain.ErrScanBudgetExceeded
+		}
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if !found {
+			return nil, 0, false, domain.ErrLocationNotFound
+		}
+		return r.locationsById[result.ID], result.Distance, true, nil
+	}
+	if budget.Hard > 0 && len(candidates) > budget.Hard {
+		return nil, 0, false, domain.ErrScanBudgetExceeded
 	}
 
 	var nearest *domain.Location
-	minDistance := math.MaxFloat64
+	var distance geospatial.Distance
+	switch {
+	case r.distance == geospatial.DistanceFast:
+		// Near the poles the projection breaks down, so measure exactly
+		distanceFn := r.sphere.EquirectangularDistance
+		if math.Abs(latitude) > geospatial.FastDistanceMaxLatitude {
+			distanceFn = r.sphere.Distance
+		}
+		nearest, distance = r.scanNearest(candidates, query, skip, distanceFn)
+	case region != "":
+		// A region's index is already the narrow set to scan
+		nearest, distance = r.scanNearest(candidates, query, skip, r.sphere.Distance)
+	default:
+		// Exact and auto both want the exact nearest, which the index
+		// finds without measuring every location
+		if results := r.index.NearestMatchingFunc(query, 1, match, r.compareTiedIDs); len(results) == 1 {
+			nearest, distance = r.locationsById[results[0].ID], results[0].Distance
+		}
+	}
 
-	for _, location := range r.locations {
-		distance := geospatial.HaversineDistance(
-			geospatial.Coordinate{Latitude: latitude, Longitude: longitude},
-			geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude},
-		)
+	if nearest == nil {
+		return nil, 0, false, domain.ErrLocationNotFound
+	}
+	return nearest, distance, false, nil
+}
+
+// scanNearest returns the candidate closest to query under distanceFn,
+// ignoring those skip reports and breaking ties with compareTied
+func (r *InMemoryLocationRepository) scanNearest(candidates map[string]*domain.Location, query geospatial.Coordinate, skip func(*domain.Location) bool, distanceFn geospatial.DistanceFunc) (*domain.Location, geospatial.Distance) {
+	var nearest *domain.Location
+	minDistance := geospatial.Distance(math.MaxFloat64)
 
-		if distance < minDistance {
+	for _, location := range candidates {
+		if skip(location) {
+			continue
+		}
+		distance := distanceFn(query, geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude})
+		if nearest == nil || cmp.Or(cmp.Compare(distance, minDistance), compareTied(location, nearest)) < 0 {
 			minDistance = distance
 			nearest = location
 		}
 	}
 
-	return nearest, minDistance, nil
+	return nearest, minDistance
 }
 
 func (r *InMemoryLocationRepository) FindByID(id string) (*domain.Location, error) {
@@ -118,4 +432,4 @@ func (r *InMemoryLocationRepository) FindByID(id string) (*domain.Location, erro
 	}
 
 	return location, nil
-}
\ No newline at end of file
+}
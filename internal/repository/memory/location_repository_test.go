@@ -2,10 +2,13 @@ package memory_test
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/jesuloba-world/leeta-task/internal/domain"
 	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 func TestSave(t *testing.T) {
@@ -216,4 +219,128 @@ func TestRepositoryState(t *testing.T) {
 	if len(locations) != 0 {
 		t.Errorf("Expected empty repository after deletion, got %d locations", len(locations))
 	}
-}
\ No newline at end of file
+}
+func TestFindNearestExclude(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	for _, location := range []*domain.Location{
+		{Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515},
+		{Name: "Yaba", Latitude: 6.5095, Longitude: 3.3711},
+		{Name: "Lekki", Latitude: 6.4474, Longitude: 3.4723},
+	} {
+		repo.Save(location)
+	}
+
+	nearest, distance, err := repo.FindNearest(6.52, 3.37, "Yaba", "Nowhere")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if nearest.Name != "Ikeja" {
+		t.Errorf("Expected second-nearest Ikeja, got %s", nearest.Name)
+	}
+	// The distance is measured to the returned location, not the excluded one
+	expected := geospatial.HaversineDistance(
+		geospatial.Coordinate{Latitude: 6.52, Longitude: 3.37},
+		geospatial.Coordinate{Latitude: 6.6018, Longitude: 3.3515},
+	)
+	if math.Abs((distance - expected).Kilometers()) > 1e-9 {
+		t.Errorf("Expected distance %v, got %v", expected, distance)
+	}
+
+	if _, _, err := repo.FindNearest(6.52, 3.37, "Ikeja", "Yaba", "Lekki"); err != domain.ErrLocationNotFound {
+		t.Errorf("Expected ErrLocationNotFound when every location is excluded, got %v", err)
+	}
+}
+
+// seedCity fills a repository with n random stations across a metro-sized
+// area around Lagos, plus a few far-away outliers
+func seedCity(repo *memory.InMemoryLocationRepository, n int, rng *rand.Rand) {
+	for i := 0; i < n; i++ {
+		repo.Save(&domain.Location{
+			Name:      fmt.Sprintf("Station %d", i),
+			Latitude:  6.4 + rng.Float64()*0.4,
+			Longitude: 3.2 + rng.Float64()*0.5,
+		})
+	}
+	repo.Save(&domain.Location{Name: "Abuja", Latitude: 9.0765, Longitude: 7.3986})
+	repo.Save(&domain.Location{Name: "Tromsø", Latitude: 69.6492, Longitude: 18.9553})
+}
+
+func TestFindNearestDistanceStrategies(t *testing.T) {
+	t.Parallel()
+	exact := memory.NewInMemoryLocationRepository()
+	fast := memory.NewInMemoryLocationRepository(memory.WithDistanceStrategy(geospatial.DistanceFast))
+	auto := memory.NewInMemoryLocationRepository(memory.WithDistanceStrategy(geospatial.DistanceAuto))
+	for _, repo := range []*memory.InMemoryLocationRepository{exact, fast, auto} {
+		seedCity(repo, 500, rand.New(rand.NewSource(7)))
+	}
+
+	rng := rand.New(rand.NewSource(11))
+	queries := []geospatial.Coordinate{
+		{Latitude: 10.5, Longitude: 7.4},  // beyond the fast range, near Abuja
+		{Latitude: 75.0, Longitude: 20.0}, // beyond the bounded latitude
+	}
+	for i := 0; i < 200; i++ {
+		queries = append(queries, geospatial.Coordinate{
+			Latitude:  6.35 + rng.Float64()*0.5,
+			Longitude: 3.15 + rng.Float64()*0.6,
+		})
+	}
+
+	for _, query := range queries {
+		want, wantDistance, err := exact.FindNearest(query.Latitude, query.Longitude)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// Auto re-ranks with Haversine, so it must agree with exact
+		got, gotDistance, _ := auto.FindNearest(query.Latitude, query.Longitude)
+		if got.Name != want.Name || gotDistance != wantDistance {
+			t.Errorf("auto at %v: expected %s at %v, got %s at %v", query, want.Name, wantDistance, got.Name, gotDistance)
+		}
+
+		// Fast may pick a near-tie, but never one meaningfully further away
+		got, gotDistance, _ = fast.FindNearest(query.Latitude, query.Longitude)
+		if wantDistance > geospatial.Kilometers(geospatial.FastDistanceMaxKm) || math.Abs(query.Latitude) > geospatial.FastDistanceMaxLatitude {
+			continue
+		}
+		gotExact := geospatial.HaversineDistance(query, geospatial.Coordinate{Latitude: got.Latitude, Longitude: got.Longitude})
+		if gotExact > wantDistance*(1+2*geospatial.FastDistanceMaxError) {
+			t.Errorf("fast at %v: picked %s at %v, exact nearest %s is %v", query, got.Name, gotExact, want.Name, wantDistance)
+		}
+		if math.Abs((gotDistance - gotExact).Meters()) > gotExact.Meters()*geospatial.FastDistanceMaxError {
+			t.Errorf("fast at %v: reported %v for %s, exact %v", query, gotDistance, got.Name, gotExact)
+		}
+	}
+}
+
+func BenchmarkFindNearestStrategy(b *testing.B) {
+	strategies := []geospatial.DistanceStrategy{geospatial.DistanceExact, geospatial.DistanceFast, geospatial.DistanceAuto}
+	for _, size := range []int{1000, 10000, 100000} {
+		for _, strategy := range strategies {
+			repo := memory.NewInMemoryLocationRepository(memory.WithDistanceStrategy(strategy))
+			seedCity(repo, size, rand.New(rand.NewSource(1)))
+			b.Run(fmt.Sprintf("%s/%d", strategy, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					repo.FindNearest(6.5, 3.4)
+				}
+			})
+		}
+	}
+}
+
+func TestFindNearestWithSphere(t *testing.T) {
+	t.Parallel()
+	earth := memory.NewInMemoryLocationRepository()
+	wgs84 := memory.NewInMemoryLocationRepository(memory.WithSphere(geospatial.NewSphere(6378.137)))
+	for _, repo := range []*memory.InMemoryLocationRepository{earth, wgs84} {
+		repo.Save(&domain.Location{Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515})
+	}
+
+	_, earthDistance, _ := earth.FindNearest(6.4474, 3.4723)
+	_, wgs84Distance, _ := wgs84.FindNearest(6.4474, 3.4723)
+	expected := earthDistance.Kilometers() * 6378.137 / geospatial.EarthRadiusKm
+	if math.Abs(wgs84Distance.Kilometers()-expected) > 1e-9 {
+		t.Errorf("Expected distance %f km on the equatorial sphere, got %v", expected, wgs84Distance)
+	}
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// MergeLocations deletes the losers and records the merge under one lock,
+// so a missing name leaves the store untouched
+func (r *InMemoryLocationRepository) MergeLocations(winner string, losers []string, actor string) (*domain.MergeAudit, error) {
+	if err := domain.ValidateMerge(winner, losers); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range append([]string{winner}, losers...) {
+		if _, exists := r.locations[name]; !exists {
+			return nil, &domain.MergeNotFoundError{Name: name}
+		}
+	}
+
+	audit := domain.MergeAudit{
+		ID:       strconv.Itoa(len(r.merges) + 1),
+		Winner:   winner,
+		Actor:    actor,
+		MergedAt: time.Now().UTC(),
+	}
+	for _, name := range losers {
+		location := r.locations[name]
+		audit.Losers = append(audit.Losers, *location)
+		r.remove(location)
+		r.record(domain.ChangeDeleted, name, location)
+	}
+	r.merges = append(r.merges, audit)
+
+	return &audit, nil
+}
+
+// Merges returns the merge audit log, oldest first
+func (r *InMemoryLocationRepository) Merges() ([]domain.MergeAudit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]domain.MergeAudit(nil), r.merges...), nil
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// lockSampleRate is how many lock acquisitions there are per timed one
+const lockSampleRate = 16
+
+// storeMetrics times writes and a sample of lock waits. It is nil unless
+// WithMetrics is given, so a store without it pays one nil check per
+// operation.
+type storeMetrics struct {
+	acquisitions atomic.Uint64
+
+	saves      operationCounter
+	deletes    operationCounter
+	readWaits  operationCounter
+	writeWaits operationCounter
+}
+
+type operationCounter struct {
+	count atomic.Uint64
+	nanos atomic.Int64
+}
+
+// since records one operation started at start, for use with defer
+func (c *operationCounter) since(start time.Time) {
+	c.count.Add(1)
+	c.nanos.Add(int64(time.Since(start)))
+}
+
+func (c *operationCounter) stats() domain.OperationStats {
+	return domain.OperationStats{Count: c.count.Load(), Total: time.Duration(c.nanos.Load())}
+}
+
+// sample reports whether to time this lock acquisition
+func (m *storeMetrics) sample() bool {
+	return m.acquisitions.Add(1)%lockSampleRate == 0
+}
+
+// sampledMutex is a sync.RWMutex that, with metrics on, times how long a
+// sample of callers wait to acquire it
+type sampledMutex struct {
+	sync.RWMutex
+	metrics *storeMetrics
+}
+
+func (m *sampledMutex) Lock() {
+	if m.metrics == nil || !m.metrics.sample() {
+		m.RWMutex.Lock()
+		return
+	}
+	defer m.metrics.writeWaits.since(time.Now())
+	m.RWMutex.Lock()
+}
+
+func (m *sampledMutex) RLock() {
+	if m.metrics == nil || !m.metrics.sample() {
+		m.RWMutex.RLock()
+		return
+	}
+	defer m.metrics.readWaits.since(time.Now())
+	m.RWMutex.RLock()
+}
+
+// WithMetrics times saves, deletes and a sample of lock waits for
+// StoreStats. Without it those stay zero.
+func WithMetrics() Option {
+	return func(r *InMemoryLocationRepository) {
+		r.metrics = &storeMetrics{}
+		r.mu.metrics = r.metrics
+	}
+}
+
+var (
+	locationSize   = int64(reflect.TypeFor[domain.Location]().Size())
+	attachmentSize = int64(reflect.TypeFor[domain.Attachment]().Size())
+	intervalSize   = int64(reflect.TypeFor[domain.OpeningInterval]().Size())
+)
+
+// indexEntryBytes approximates one map entry: a string header key and a
+// pointer value, with the map's own overhead
+const indexEntryBytes = 48
+
+// estimateBytes approximates the memory a location takes, with its
+// entries in the name and ID indexes
+func estimateBytes(l *domain.Location) int64 {
+	n := locationSize + 2*indexEntryBytes
+	n += int64(len(l.ID) + len(l.Name) + len(l.Description) + len(l.Region))
+	for _, alias := range l.Aliases {
+		n += 16 + int64(len(alias)) + indexEntryBytes
+	}
+	for _, attachment := range l.Attachments {
+		n += attachmentSize + int64(len(attachment.URL)+len(attachment.Kind))
+	}
+	if h := l.OpeningHours; h != nil {
+		n += int64(len(h.Timezone))
+		for day, intervals := range h.Days {
+			n += indexEntryBytes + int64(len(day)) + int64(len(intervals))*(intervalSize+10)
+		}
+	}
+	return n
+}
+
+// StoreStats reports the store's size and index buckets, which it walks
+// under the read lock, and the timings WithMetrics collects
+func (r *InMemoryLocationRepository) StoreStats() domain.StoreStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := domain.StoreStats{
+		Locations:   len(r.locations),
+		Removed:     r.removed,
+		Compactions: r.compactions,
+		Reclaimed:   r.reclaimed,
+	}
+	for _, location := range r.locations {
+		stats.EstimatedBytes += estimateBytes(location)
+	}
+
+	regions := domain.IndexStats{Name: "region", Sizes: make([]int, 0, len(r.byRegion))}
+	for _, bucket := range r.byRegion {
+		regions.Sizes = append(regions.Sizes, len(bucket))
+	}
+	stats.Indexes = append(stats.Indexes, regions)
+	// The grid is reported only where the scan budget uses it, as before
+	// the index was kept for every store
+	if r.budget.Soft > 0 {
+		stats.Indexes = append(stats.Indexes, domain.IndexStats{Name: "grid", Sizes: r.index.CellSizes()})
+	}
+
+	if m := r.metrics; m != nil {
+		stats.Saves = m.saves.stats()
+		stats.Deletes = m.deletes.stats()
+		stats.ReadLockWaits = m.readWaits.stats()
+		stats.WriteLockWaits = m.writeWaits.stats()
+	}
+	return stats
+}
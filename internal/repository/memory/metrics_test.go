@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+func TestStoreStats(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository(WithMetrics(), WithScanBudget(domain.ScanBudget{Soft: 1}))
+
+	const saved = 40
+	for i := range saved {
+		location, _ := domain.NewLocation(fmt.Sprintf("Station %02d", i), 6.5+float64(i%4), 3.3)
+		location.Region = []string{"Lagos", "Ogun"}[i%2]
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save: %v", err)
+		}
+	}
+	if err := repo.Delete("Station 00"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	for range 64 {
+		repo.FindByName("Station 01")
+	}
+
+	stats := repo.StoreStats()
+	if stats.Locations != saved-1 {
+		t.Errorf("Expected %d locations, got %d", saved-1, stats.Locations)
+	}
+	if stats.Saves.Count != saved || stats.Saves.Total <= 0 || stats.Deletes.Count != 1 || stats.Deletes.Total <= 0 {
+		t.Errorf("Expected %d timed saves and 1 timed delete, got %+v and %+v", saved, stats.Saves, stats.Deletes)
+	}
+	// One acquisition in lockSampleRate is timed
+	if acquisitions := uint64(saved + 1 + 64); stats.ReadLockWaits.Count+stats.WriteLockWaits.Count != acquisitions/lockSampleRate {
+		t.Errorf("Expected %d sampled lock waits, got %+v and %+v", acquisitions/lockSampleRate, stats.ReadLockWaits, stats.WriteLockWaits)
+	}
+
+	indexes := map[string]domain.IndexStats{}
+	for _, index := range stats.Indexes {
+		indexes[index.Name] = index
+	}
+	if regions := indexes["region"]; len(regions.Sizes) != 2 || regions.Largest() != 20 {
+		t.Errorf("Expected two region buckets of at most 20, got %+v", regions)
+	}
+	if cells := indexes["grid"]; len(cells.Sizes) != 4 || cells.Largest() != 10 {
+		t.Errorf("Expected four grid cells of at most 10, got %+v", cells)
+	}
+
+	// A longer description is counted
+	before := stats.EstimatedBytes
+	location, _ := domain.NewLocation("Described", 6.5, 3.3)
+	location.Description = strings.Repeat("d", 1000)
+	repo.Save(location)
+	if grown := repo.StoreStats().EstimatedBytes - before; grown < 1000 {
+		t.Errorf("Expected the estimate to grow by at least the description, got %d", grown)
+	}
+}
+
+func TestStoreStatsWithoutMetrics(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository()
+	location, _ := domain.NewLocation("Yaba", 6.5095, 3.3711)
+	repo.Save(location)
+	repo.Delete("Yaba")
+
+	stats := repo.StoreStats()
+	if stats.Saves.Count != 0 || stats.Deletes.Count != 0 || stats.ReadLockWaits.Count != 0 || stats.WriteLockWaits.Count != 0 {
+		t.Errorf("Expected no timings without metrics, got %+v", stats)
+	}
+	if len(stats.Indexes) != 1 {
+		t.Errorf("Expected only the region index without a scan budget, got %+v", stats.Indexes)
+	}
+}
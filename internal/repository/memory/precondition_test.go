@@ -0,0 +1,13 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestPreconditions(t *testing.T) {
+	t.Parallel()
+	repotest.RunPreconditions(t, memory.NewInMemoryLocationRepository())
+}
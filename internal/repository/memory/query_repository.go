@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type InMemoryQueryRepository struct {
+	mu      sync.RWMutex
+	queries map[string]*domain.SavedQuery
+}
+
+func NewInMemoryQueryRepository() *InMemoryQueryRepository {
+	return &InMemoryQueryRepository{
+		queries: make(map[string]*domain.SavedQuery),
+	}
+}
+
+func (r *InMemoryQueryRepository) CreateQuery(query *domain.SavedQuery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.queries[query.Name]; ok {
+		return domain.ErrQueryExists
+	}
+	r.queries[query.Name] = copyQuery(query)
+	return nil
+}
+
+func (r *InMemoryQueryRepository) UpdateQuery(query *domain.SavedQuery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.queries[query.Name]
+	if !ok {
+		return domain.ErrQueryNotFound
+	}
+	updated := copyQuery(query)
+	updated.CreatedAt = existing.CreatedAt
+	r.queries[query.Name] = updated
+	query.CreatedAt = existing.CreatedAt
+	return nil
+}
+
+func (r *InMemoryQueryRepository) FindQuery(name string) (*domain.SavedQuery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query, ok := r.queries[name]
+	if !ok {
+		return nil, domain.ErrQueryNotFound
+	}
+	return copyQuery(query), nil
+}
+
+func (r *InMemoryQueryRepository) ListQueries() ([]*domain.SavedQuery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	queries := make([]*domain.SavedQuery, 0, len(r.queries))
+	for _, query := range r.queries {
+		queries = append(queries, copyQuery(query))
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	return queries, nil
+}
+
+func (r *InMemoryQueryRepository) DeleteQuery(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.queries[name]; !ok {
+		return domain.ErrQueryNotFound
+	}
+	delete(r.queries, name)
+	return nil
+}
+
+// copyQuery keeps callers from changing a stored filter through the slice
+func copyQuery(query *domain.SavedQuery) *domain.SavedQuery {
+	copied := *query
+	copied.Filter = slices.Clone(query.Filter)
+	return &copied
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RestoreLocations inserts snapshot records with their IDs under one lock
+// and moves nextID past the largest restored ID
+func (r *InMemoryLocationRepository) RestoreLocations(locations []domain.Location) (*domain.RestoreResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accepted, conflicts := domain.PlanRestore(locations,
+		func(id string) bool { return r.locationsById[id] != nil },
+		func(name string) bool { _, taken := r.resolve(name); return taken },
+	)
+	for _, location := range accepted {
+		if location.CreatedAt.IsZero() {
+			location.CreatedAt = time.Now()
+		}
+		r.replace(&location)
+		for _, alias := range location.Aliases {
+			r.aliases[alias] = location.Name
+		}
+		r.reserveID(location.ID)
+		r.record(domain.ChangeCreated, location.Name, &location)
+	}
+
+	return &domain.RestoreResult{Restored: len(accepted), Conflicts: conflicts}, nil
+}
+
+// reserveID keeps generated IDs above a numeric ID stored by the caller
+func (r *InMemoryLocationRepository) reserveID(id string) {
+	if n, err := strconv.Atoi(id); err == nil && n >= r.nextID {
+		r.nextID = n + 1
+	}
+}
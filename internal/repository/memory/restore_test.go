@@ -0,0 +1,28 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestRestoreLocations(t *testing.T) {
+	t.Parallel()
+	repotest.RunRestore(t, memory.NewInMemoryLocationRepository())
+}
+
+func TestSaveWithIDAdvancesNextID(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+
+	repo.Save(&domain.Location{ID: "5", Name: "Given"})
+	generated := &domain.Location{Name: "Generated"}
+	if err := repo.Save(generated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if generated.ID != "6" {
+		t.Errorf("Expected a generated ID above the given one, got %s", generated.ID)
+	}
+}
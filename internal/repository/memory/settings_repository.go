@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type InMemorySettingsRepository struct {
+	mu     sync.RWMutex
+	search *domain.SearchSettings
+}
+
+func NewInMemorySettingsRepository() *InMemorySettingsRepository {
+	return &InMemorySettingsRepository{}
+}
+
+func (r *InMemorySettingsRepository) FindSearchSettings() (*domain.SearchSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.search == nil {
+		return nil, domain.ErrSettingsNotFound
+	}
+	settings := *r.search
+	return &settings, nil
+}
+
+func (r *InMemorySettingsRepository) SaveSearchSettings(settings *domain.SearchSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *settings
+	r.search = &stored
+	return nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// Snapshot returns a copy of every stored location, ordered by id. It is
+// taken under the read lock, so it reflects a single point in time, and the
+// copies share nothing with the repository: later writes do not change it.
+func (r *InMemoryLocationRepository) Snapshot() []domain.Location {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := make([]domain.Location, 0, len(r.locations))
+	for _, location := range r.locations {
+		snapshot = append(snapshot, *location)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return lessID(snapshot[i].ID, snapshot[j].ID)
+	})
+	return snapshot
+}
+
+// Restore replaces the repository contents with copies of locations, as
+// produced by Snapshot. Every entry must be valid and carry an ID, and IDs
+// and names must be unique; otherwise Restore returns an error and leaves
+// the repository untouched. The swap happens under the write lock, so
+// concurrent readers see either the old contents or the new ones, never a
+// mix. Generated IDs continue after the largest numeric restored ID. The
+// swap is not recorded in the change log.
+func (r *InMemoryLocationRepository) Restore(locations []domain.Location) error {
+	byName := make(map[string]*domain.Location, len(locations))
+	byID := make(map[string]*domain.Location, len(locations))
+	nextID := 1
+	for i := range locations {
+		location := locations[i]
+		if err := location.Validate(); err != nil {
+			return fmt.Errorf("location %d: %w", i, err)
+		}
+		if location.ID == "" {
+			return fmt.Errorf("location %d (%s): id is required", i, location.Name)
+		}
+		if byID[location.ID] != nil {
+			return fmt.Errorf("location %d: duplicate id %s", i, location.ID)
+		}
+		if byName[location.Name] != nil {
+			return fmt.Errorf("location %d (%s): %w", i, location.Name, domain.ErrLocationExists)
+		}
+
+		byName[location.Name] = &location
+		byID[location.ID] = &location
+		if n, err := strconv.Atoi(location.ID); err == nil && n >= nextID {
+			nextID = n + 1
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.locations = byName
+	r.locationsById = byID
+	r.nextID = nextID
+	r.removed = 0
+	r.aliases = make(map[string]string)
+	r.byRegion = make(map[string]map[string]*domain.Location)
+	r.names = newNameIndex(len(byID))
+	r.index = geospatial.NewIndex(r.sphere)
+	for _, location := range byName {
+		r.indexRegion(location)
+		r.index.Insert(location.ID, geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude})
+		r.names.set(location)
+		for _, alias := range location.Aliases {
+			r.aliases[alias] = location.Name
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package memory_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+)
+
+func TestSnapshotIsolation(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	repo.Save(&domain.Location{Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515})
+	repo.Save(&domain.Location{Name: "Lekki", Latitude: 6.4698, Longitude: 3.5852})
+
+	snapshot := repo.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Name != "Ikeja" || snapshot[1].Name != "Lekki" {
+		t.Fatalf("Expected Ikeja and Lekki ordered by id, got %+v", snapshot)
+	}
+
+	stored, _ := repo.FindByName("Ikeja")
+	stored.Latitude = 0
+	repo.Delete("Lekki")
+	repo.Save(&domain.Location{Name: "Yaba", Latitude: 6.5095, Longitude: 3.3711})
+
+	if len(snapshot) != 2 || snapshot[0].Latitude != 6.6018 || snapshot[1].Name != "Lekki" {
+		t.Errorf("Expected the snapshot to be unaffected by later writes, got %+v", snapshot)
+	}
+
+	snapshot[0].Name = "Changed"
+	if _, err := repo.FindByName("Ikeja"); err != nil {
+		t.Errorf("Expected editing the snapshot to leave the repository alone, got %v", err)
+	}
+}
+
+func TestRestoreReplacesContents(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	repo.Save(&domain.Location{Name: "Old", Latitude: 1, Longitude: 1})
+
+	locations := []domain.Location{
+		{ID: "7", Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515},
+		{ID: "3", Name: "Lekki", Latitude: 6.4698, Longitude: 3.5852},
+	}
+	if err := repo.Restore(locations); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	locations[0].Name = "Changed"
+
+	if _, err := repo.FindByName("Old"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected existing data to be replaced, got %v", err)
+	}
+	if found, err := repo.FindByID("7"); err != nil || found.Name != "Ikeja" {
+		t.Errorf("Expected Ikeja under id 7, got %+v, %v", found, err)
+	}
+	if found, err := repo.FindByName("Lekki"); err != nil || found.ID != "3" {
+		t.Errorf("Expected Lekki under id 3, got %+v, %v", found, err)
+	}
+
+	generated := &domain.Location{Name: "Yaba"}
+	repo.Save(generated)
+	if generated.ID != "8" {
+		t.Errorf("Expected the next generated id after the largest restored one, got %s", generated.ID)
+	}
+}
+
+func TestRestoreRejectsInvalidEntries(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		invalid domain.Location
+	}{
+		{"missing id", domain.Location{Name: "NoID", Latitude: 1, Longitude: 1}},
+		{"empty name", domain.Location{ID: "9", Latitude: 1, Longitude: 1}},
+		{"invalid latitude", domain.Location{ID: "9", Name: "Bad", Latitude: 91}},
+		{"duplicate id", domain.Location{ID: "1", Name: "Other", Latitude: 1, Longitude: 1}},
+		{"duplicate name", domain.Location{ID: "9", Name: "Ikeja", Latitude: 1, Longitude: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := memory.NewInMemoryLocationRepository()
+			repo.Save(&domain.Location{Name: "Existing", Latitude: 1, Longitude: 1})
+
+			err := repo.Restore([]domain.Location{
+				{ID: "1", Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515},
+				tt.invalid,
+			})
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+
+			snapshot := repo.Snapshot()
+			if len(snapshot) != 1 || snapshot[0].Name != "Existing" {
+				t.Errorf("Expected the repository to be untouched, got %+v", snapshot)
+			}
+		})
+	}
+}
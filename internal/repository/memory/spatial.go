@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// VerifySpatial checks that every location validates and that the name and
+// ID indexes point at the same records. With fix set, index entries are
+// added or removed to match the name index; invalid coordinates are only
+// reported.
+func (r *InMemoryLocationRepository) VerifySpatial(batchSize int, fix bool, onBatch func(domain.SpatialBatch) error) error {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	r.mu.RLock()
+	names := make([]string, 0, len(r.locations))
+	for name := range r.locations {
+		names = append(names, name)
+	}
+	ids := make([]string, 0, len(r.locationsById))
+	for id := range r.locationsById {
+		ids = append(ids, id)
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(names)
+	sort.Slice(ids, func(i, j int) bool { return lessID(ids[i], ids[j]) })
+
+	for start := 0; start < len(names); start += batchSize {
+		batch := names[start:min(start+batchSize, len(names))]
+		if err := onBatch(r.verifyNames(batch, fix)); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(ids); start += batchSize {
+		batch := ids[start:min(start+batchSize, len(ids))]
+		if err := onBatch(r.verifyIDs(batch, fix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryLocationRepository) verifyNames(names []string, fix bool) domain.SpatialBatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := domain.SpatialBatch{Issues: []domain.SpatialIssue{}}
+	for _, name := range names {
+		location, exists := r.locations[name]
+		if !exists {
+			continue
+		}
+		result.Scanned++
+
+		if location.Validate() != nil {
+			result.Issues = append(result.Issues, domain.SpatialIssue{
+				ID: location.ID, Name: name, Problem: domain.SpatialInvalidCoordinates,
+			})
+		}
+		if r.locationsById[location.ID] != location {
+			issue := domain.SpatialIssue{ID: location.ID, Name: name, Problem: domain.SpatialIDIndexMissing}
+			if fix {
+				r.locationsById[location.ID] = location
+				issue.Repaired = true
+			}
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+	return result
+}
+
+func (r *InMemoryLocationRepository) verifyIDs(ids []string, fix bool) domain.SpatialBatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := domain.SpatialBatch{Issues: []domain.SpatialIssue{}}
+	for _, id := range ids {
+		location, exists := r.locationsById[id]
+		if !exists {
+			continue
+		}
+		result.Scanned++
+
+		if r.locations[location.Name] != location || location.ID != id {
+			issue := domain.SpatialIssue{ID: id, Name: location.Name, Problem: domain.SpatialIDIndexStale}
+			if fix {
+				delete(r.locationsById, id)
+				issue.Repaired = true
+			}
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+	return result
+}
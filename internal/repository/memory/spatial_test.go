@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+func collectSpatial(t *testing.T, repo *InMemoryLocationRepository, batchSize int, fix bool) []domain.SpatialIssue {
+	t.Helper()
+	var issues []domain.SpatialIssue
+	err := repo.VerifySpatial(batchSize, fix, func(batch domain.SpatialBatch) error {
+		if batch.Scanned > batchSize {
+			t.Errorf("Expected at most %d rows per batch, got %d", batchSize, batch.Scanned)
+		}
+		issues = append(issues, batch.Issues...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return issues
+}
+
+func TestVerifySpatialDetectsAndRepairsIndexDrift(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository()
+	for _, name := range []string{"A", "B", "C", "D"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.3)
+		repo.Save(location)
+	}
+
+	if issues := collectSpatial(t, repo, 2, false); len(issues) != 0 {
+		t.Fatalf("Expected a clean store, got %+v", issues)
+	}
+
+	// Corrupt the indexes: B loses its ID entry, a stale ID entry appears,
+	// and C gets coordinates that no longer validate
+	b := repo.locations["B"]
+	delete(repo.locationsById, b.ID)
+	repo.locationsById["99"] = &domain.Location{ID: "99", Name: "Ghost", Latitude: 1, Longitude: 1}
+	repo.locations["C"].Latitude = 120
+
+	issues := collectSpatial(t, repo, 2, false)
+	problems := map[string]string{}
+	for _, issue := range issues {
+		problems[issue.Name] = issue.Problem
+		if issue.Repaired {
+			t.Errorf("Expected no repairs without fix, got %+v", issue)
+		}
+	}
+	expected := map[string]string{
+		"B":     domain.SpatialIDIndexMissing,
+		"Ghost": domain.SpatialIDIndexStale,
+		"C":     domain.SpatialInvalidCoordinates,
+	}
+	for name, problem := range expected {
+		if problems[name] != problem {
+			t.Errorf("Expected %s to be reported as %s, got %q", name, problem, problems[name])
+		}
+	}
+
+	collectSpatial(t, repo, 2, true)
+
+	// Only the invalid coordinates remain, since they cannot be derived
+	issues = collectSpatial(t, repo, 2, false)
+	if len(issues) != 1 || issues[0].Problem != domain.SpatialInvalidCoordinates {
+		t.Errorf("Expected only invalid coordinates after repair, got %+v", issues)
+	}
+	if found, err := repo.FindByID(b.ID); err != nil || found.Name != "B" {
+		t.Errorf("Expected B to be findable by ID after repair, got %v", err)
+	}
+}
+
+func TestDeleteRemovesIDIndexEntry(t *testing.T) {
+	t.Parallel()
+	repo := NewInMemoryLocationRepository()
+	location, _ := domain.NewLocation("Gone", 6.5, 3.3)
+	repo.Save(location)
+	repo.Delete("Gone")
+
+	if _, err := repo.FindByID(location.ID); err != domain.ErrLocationNotFound {
+		t.Errorf("Expected deleted location to be gone from the ID index, got %v", err)
+	}
+	if issues := collectSpatial(t, repo, 10, false); len(issues) != 0 {
+		t.Errorf("Expected no drift after delete, got %+v", issues)
+	}
+}
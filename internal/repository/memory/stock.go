@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// UpdateStock changes the location's capacity and stock under the write
+// lock, so every update applies to the result of the one before
+func (r *InMemoryLocationRepository) UpdateStock(name string, update domain.StockUpdate, checks ...domain.Precondition) (*domain.Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	location, exists := r.resolve(name)
+	if !exists {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := domain.CheckPreconditions(location, checks...); err != nil {
+		return nil, err
+	}
+	capacity, stock, err := update.Apply(location.CapacityLitres, location.CurrentStockLitres)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace rather than update, so locations handed out earlier keep
+	// their stock
+	updated := *location
+	updated.CapacityLitres = copyLitres(capacity)
+	updated.CurrentStockLitres = copyLitres(stock)
+	r.replace(&updated)
+	r.record(domain.ChangeUpdated, updated.Name, &updated)
+	return &updated, nil
+}
+
+// FindNearestStocked passes over stations holding less than minLitres
+// while it searches
+func (r *InMemoryLocationRepository) FindNearestStocked(region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	location, distance, approximate, err := r.findNearest(region, minLitres, latitude, longitude, exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance, Approximate: approximate}, nil
+}
+
+// FindNearestStockedWithin is FindNearestStocked within budget in place of
+// the repository's own
+func (r *InMemoryLocationRepository) FindNearestStockedWithin(budget domain.ScanBudget, region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	location, distance, approximate, err := r.findNearestWithin(budget, region, minLitres, latitude, longitude, exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance, Approximate: approximate}, nil
+}
+
+// copyLitres keeps the stored value from sharing memory with the caller's
+func copyLitres(litres *float64) *float64 {
+	if litres == nil {
+		return nil
+	}
+	value := *litres
+	return &value
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// nameKey is a location name lowercased and split into trigrams once, when
+// it is stored, so a suggestion scan only compares
+type nameKey struct {
+	location *domain.Location
+	lower    string
+	trigrams []uint64
+}
+
+func newNameKey(location *domain.Location) nameKey {
+	return nameKey{location: location, lower: strings.ToLower(location.Name), trigrams: text.Trigrams(location.Name)}
+}
+
+// nameIndex holds every location's nameKey in a slice, roughly in the
+// order they were stored, so a scan reads memory in order rather than
+// hopping around a map; at finds a location's place by ID
+type nameIndex struct {
+	keys []nameKey
+	at   map[string]int
+}
+
+func newNameIndex(size int) nameIndex {
+	return nameIndex{keys: make([]nameKey, 0, size), at: make(map[string]int, size)}
+}
+
+func (n *nameIndex) set(location *domain.Location) {
+	if i, exists := n.at[location.ID]; exists {
+		n.keys[i] = newNameKey(location)
+		return
+	}
+	n.at[location.ID] = len(n.keys)
+	n.keys = append(n.keys, newNameKey(location))
+}
+
+// remove moves the last key into the removed one's place
+func (n *nameIndex) remove(id string) {
+	i, exists := n.at[id]
+	if !exists {
+		return
+	}
+	last := len(n.keys) - 1
+	if i != last {
+		n.keys[i] = n.keys[last]
+		n.at[n.keys[i].location.ID] = i
+	}
+	n.keys[last] = nameKey{}
+	n.keys = n.keys[:last]
+	delete(n.at, id)
+}
+
+func (n nameIndex) clone() nameIndex {
+	return nameIndex{keys: slices.Clone(n.keys), at: maps.Clone(n.at)}
+}
+
+// suggestQuery is a query prepared once for scoring every name
+type suggestQuery struct {
+	lower     string
+	wordStart string
+	trigrams  []uint64
+}
+
+func newSuggestQuery(query string) suggestQuery {
+	lower := strings.ToLower(query)
+	return suggestQuery{lower: lower, wordStart: " " + lower, trigrams: text.Trigrams(query)}
+}
+
+// score rates how well the name matches a query, or 0 when it does not;
+// see domain.SuggestPrefixScore
+func (k nameKey) score(q suggestQuery) float64 {
+	switch {
+	case strings.HasPrefix(k.lower, q.lower):
+		return domain.SuggestPrefixScore
+	case strings.Contains(k.lower, q.wordStart):
+		return domain.SuggestWordPrefixScore
+	}
+	// Sets this different in size cannot be similar enough, so skip
+	// comparing them
+	shorter, longer := min(len(k.trigrams), len(q.trigrams)), max(len(k.trigrams), len(q.trigrams))
+	if float64(shorter) < domain.SuggestMinSimilarity*float64(longer) {
+		return 0
+	}
+	if similarity := text.TrigramSimilarity(k.trigrams, q.trigrams); similarity >= domain.SuggestMinSimilarity {
+		return similarity
+	}
+	return 0
+}
+
+// SuggestLocations scores every name against the query and keeps the best
+// query.Limit, measuring distance on the repository's sphere
+func (r *InMemoryLocationRepository) SuggestLocations(query domain.SuggestQuery) ([]domain.Suggestion, error) {
+	q := newSuggestQuery(query.Query)
+	limit := query.Limit
+	if limit <= 0 {
+		limit = domain.DefaultSuggestLimit
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	best := make([]domain.Suggestion, 0, limit+1)
+	for _, key := range r.names.keys {
+		nameScore := key.score(q)
+		if nameScore == 0 {
+			continue
+		}
+		location := key.location
+		var distance geospatial.Distance
+		if query.Near != nil {
+			distance = r.sphere.Distance(*query.Near, geospatial.Coordinate{Latitude: location.Latitude, Longitude: location.Longitude})
+		}
+		suggestion := domain.Suggestion{Location: location, Distance: distance, Score: domain.SuggestScore(query, nameScore, distance)}
+
+		// Keep the best few in order as the scan goes
+		at, _ := slices.BinarySearchFunc(best, suggestion, compareSuggestions)
+		if at == limit {
+			continue
+		}
+		best = slices.Insert(best, at, suggestion)
+		if len(best) > limit {
+			best = best[:limit]
+		}
+	}
+	return best, nil
+}
+
+// compareSuggestions orders by score, highest first, then distance and
+// name
+func compareSuggestions(a, b domain.Suggestion) int {
+	if c := cmp.Compare(b.Score, a.Score); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Distance, b.Distance); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Location.Name, b.Location.Name)
+}
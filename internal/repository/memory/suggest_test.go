@@ -0,0 +1,57 @@
+package memory_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func TestSuggestLocations(t *testing.T) {
+	t.Parallel()
+	repotest.RunSuggest(t, memory.NewInMemoryLocationRepository())
+}
+
+func TestSuggestLocationsFollowsRenames(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	location, _ := domain.NewLocation("Yaba", 6.5095, 3.3711)
+	repo.Save(location)
+	if err := repo.RenameLocation(location.ID, "Sabo"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+
+	for q, want := range map[string]int{"yaba": 0, "sabo": 1} {
+		suggestions, err := repo.SuggestLocations(domain.SuggestQuery{Query: q, Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to suggest: %v", err)
+		}
+		if len(suggestions) != want {
+			t.Errorf("Expected %d suggestions for %q after the rename, got %d", want, q, len(suggestions))
+		}
+	}
+}
+
+// BenchmarkSuggestLocations covers the 30 ms target for 100k stations
+func BenchmarkSuggestLocations(b *testing.B) {
+	near := &geospatial.Coordinate{Latitude: 6.4281, Longitude: 3.4219}
+	for _, size := range []int{10000, 100000} {
+		repo := memory.NewInMemoryLocationRepository()
+		if err := repotest.SeedSuggestions(repo, size); err != nil {
+			b.Fatalf("Failed to seed: %v", err)
+		}
+		for _, q := range []string{"le", "lekki dep", "ikeyja"} {
+			b.Run(fmt.Sprintf("%d/%s", size, q), func(b *testing.B) {
+				query := domain.SuggestQuery{Query: q, Near: near, Limit: domain.DefaultSuggestLimit, DistanceWeight: domain.DefaultSuggestDistanceWeight}
+				for i := 0; i < b.N; i++ {
+					if _, err := repo.SuggestLocations(query); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
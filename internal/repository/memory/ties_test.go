@@ -0,0 +1,23 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func TestFindNearestTieBreak(t *testing.T) {
+	t.Parallel()
+	for _, strategy := range []geospatial.DistanceStrategy{geospatial.DistanceExact, geospatial.DistanceFast, geospatial.DistanceAuto} {
+		t.Run(string(strategy), func(t *testing.T) {
+			repotest.RunNearestTieBreak(t, memory.NewInMemoryLocationRepository(memory.WithDistanceStrategy(strategy)))
+		})
+	}
+	// Past the soft scan limit the approximate search breaks ties the same way
+	t.Run("approximate", func(t *testing.T) {
+		repotest.RunNearestTieBreak(t, memory.NewInMemoryLocationRepository(memory.WithScanBudget(domain.ScanBudget{Soft: 1})))
+	})
+}
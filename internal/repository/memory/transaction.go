@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// ApplyOperations applies the operations to a copy of the store and swaps
+// the copy in only once every operation has succeeded, so a failed
+// transaction leaves nothing behind. The copy is taken and swapped under
+// the write lock, so readers see the store before the transaction or
+// after it. Copying costs time linear in the number of locations.
+func (r *InMemoryLocationRepository) ApplyOperations(ops []domain.LocationOperation) ([]domain.OperationResult, error) {
+	if len(ops) == 0 {
+		return nil, domain.ErrEmptyTransaction
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	staged := r.stage()
+	results := make([]domain.OperationResult, 0, len(ops))
+	for i, op := range ops {
+		location, err := staged.apply(op)
+		if err != nil {
+			return nil, &domain.OperationError{Index: i, Err: err}
+		}
+		results = append(results, domain.OperationResult{Type: op.Type, Location: *location})
+	}
+
+	r.locations = staged.locations
+	r.locationsById = staged.locationsById
+	r.aliases = staged.aliases
+	r.byRegion = staged.byRegion
+	r.names = staged.names
+	r.index = staged.index
+	r.nextID = staged.nextID
+	r.changes = staged.changes
+	r.removed = staged.removed
+	return results, nil
+}
+
+// stage copies the indexes and change log into an unshared repository.
+// Locations themselves are shared: writes replace them rather than change
+// them in place. The caller holds the lock.
+func (r *InMemoryLocationRepository) stage() *InMemoryLocationRepository {
+	staged := &InMemoryLocationRepository{
+		locations:     maps.Clone(r.locations),
+		locationsById: maps.Clone(r.locationsById),
+		aliases:       maps.Clone(r.aliases),
+		byRegion:      make(map[string]map[string]*domain.Location, len(r.byRegion)),
+		names:         r.names.clone(),
+		index:         r.index.Clone(),
+		nextID:        r.nextID,
+		changes:       r.changes,
+		removed:       r.removed,
+	}
+	for region, index := range r.byRegion {
+		staged.byRegion[region] = maps.Clone(index)
+	}
+	staged.changes.changes = slices.Clone(r.changes.changes)
+	return staged
+}
+
+// apply runs one operation against a staged copy and returns the location
+// it created, updated or deleted
+func (r *InMemoryLocationRepository) apply(op domain.LocationOperation) (*domain.Location, error) {
+	switch op.Type {
+	case domain.OperationCreate:
+		if op.Location == nil {
+			return nil, fmt.Errorf("create without a location")
+		}
+		created := *op.Location
+		if err := r.save(&created); err != nil {
+			return nil, err
+		}
+		return &created, nil
+	case domain.OperationUpdate:
+		return r.update(op.Name, op.Changes, op.Checks...)
+	case domain.OperationDelete:
+		return r.delete(op.Name, op.Checks...)
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Type)
+}
+
+// update replaces the location found by name, once it passes checks, with
+// a changed copy; the caller holds the write lock
+func (r *InMemoryLocationRepository) update(name string, changes domain.LocationChanges, checks ...domain.Precondition) (*domain.Location, error) {
+	location, exists := r.resolve(name)
+	if !exists {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := domain.CheckPreconditions(location, checks...); err != nil {
+		return nil, err
+	}
+	updated := changes.Apply(*location)
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	if changes.Aliases != nil {
+		for _, alias := range updated.Aliases {
+			if owner, taken := r.resolve(alias); taken && owner != location {
+				return nil, &domain.NameTakenError{Name: alias, Owner: owner.Name}
+			}
+		}
+		r.dropAliases(location)
+		for _, alias := range updated.Aliases {
+			r.aliases[alias] = updated.Name
+		}
+	}
+
+	if updated.Name == location.Name {
+		r.replace(&updated)
+		r.record(domain.ChangeUpdated, updated.Name, &updated)
+		return &updated, nil
+	}
+	if owner, taken := r.resolve(updated.Name); taken {
+		return nil, domain.NameConflict(updated.Name, owner)
+	}
+	r.rename(location, &updated)
+	return &updated, nil
+}
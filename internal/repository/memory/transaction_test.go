@@ -0,0 +1,56 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestApplyOperations(t *testing.T) {
+	t.Parallel()
+	repotest.RunTransactions(t, memory.NewInMemoryLocationRepository())
+}
+
+func TestApplyOperationsRace(t *testing.T) {
+	t.Parallel()
+	repotest.RunTransactionRace(t, memory.NewInMemoryLocationRepository())
+}
+
+func TestApplyOperationsKeepsIndexes(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository(memory.WithScanBudget(domain.ScanBudget{Soft: 1}))
+	location, _ := domain.NewLocation("Yaba", 6.5095, 3.3711)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	// A failed transaction leaves the region index and grid as they were
+	region := "lagos"
+	if _, err := repo.ApplyOperations([]domain.LocationOperation{
+		{Type: domain.OperationUpdate, Name: "Yaba", Changes: domain.LocationChanges{Region: &region}},
+		{Type: domain.OperationDelete, Name: "Ikeja"},
+	}); err == nil {
+		t.Fatal("Expected deleting a missing location to fail")
+	}
+	if _, _, err := repo.FindNearestInRegion("lagos", 6.5, 3.37); err == nil {
+		t.Error("Expected the rolled back region to be unindexed")
+	}
+
+	north := 7.3775
+	if _, err := repo.ApplyOperations([]domain.LocationOperation{
+		{Type: domain.OperationUpdate, Name: "Yaba", Changes: domain.LocationChanges{Region: &region, Latitude: &north}},
+		{Type: domain.OperationCreate, Location: &domain.Location{Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515}},
+	}); err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	nearest, _, err := repo.FindNearestInRegion("lagos", 7.3, 3.37)
+	if err != nil || nearest.Name != "Yaba" {
+		t.Fatalf("Expected Yaba found in its new region, got %+v (%v)", nearest, err)
+	}
+	result, err := repo.FindNearestBudgeted("", 7.38, 3.37)
+	if err != nil || result.Location.Name != "Yaba" || !result.Approximate {
+		t.Errorf("Expected the grid search to find Yaba at its new coordinates, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// TruncateLocations records a deletion for every location, in ID order,
+// and then replaces the indexes with empty ones under one lock. IDs carry
+// on from the last one given.
+func (r *InMemoryLocationRepository) TruncateLocations(actor string) (*domain.TruncateAudit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	locations := make([]*domain.Location, 0, len(r.locationsById))
+	for _, location := range r.locationsById {
+		locations = append(locations, location)
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return lessID(locations[i].ID, locations[j].ID)
+	})
+	for _, location := range locations {
+		r.record(domain.ChangeDeleted, location.Name, location)
+	}
+
+	r.locations = make(map[string]*domain.Location)
+	r.locationsById = make(map[string]*domain.Location)
+	r.aliases = make(map[string]string)
+	r.byRegion = make(map[string]map[string]*domain.Location)
+	r.names = newNameIndex(0)
+	r.index = geospatial.NewIndex(r.sphere)
+	// The indexes are new, so there is nothing left for CompactStore
+	r.removed = 0
+
+	audit := domain.TruncateAudit{
+		ID:          strconv.Itoa(len(r.truncations) + 1),
+		Count:       len(locations),
+		Actor:       actor,
+		TruncatedAt: time.Now().UTC(),
+	}
+	r.truncations = append(r.truncations, audit)
+	audit.Removed = make([]domain.Location, len(locations))
+	for i, location := range locations {
+		audit.Removed[i] = *location
+	}
+	return &audit, nil
+}
+
+// Truncations returns the truncation audit log, oldest first
+func (r *InMemoryLocationRepository) Truncations() ([]domain.TruncateAudit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]domain.TruncateAudit(nil), r.truncations...), nil
+}
@@ -0,0 +1,13 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestTruncateLocations(t *testing.T) {
+	t.Parallel()
+	repotest.RunTruncate(t, memory.NewInMemoryLocationRepository())
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type usageKey struct {
+	key       string
+	operation string
+	day       time.Time
+}
+
+type InMemoryUsageRepository struct {
+	mu       sync.RWMutex
+	counters map[usageKey]*domain.UsageRecord
+}
+
+func NewInMemoryUsageRepository() *InMemoryUsageRepository {
+	return &InMemoryUsageRepository{
+		counters: make(map[usageKey]*domain.UsageRecord),
+	}
+}
+
+func (r *InMemoryUsageRepository) AddUsage(records []domain.UsageRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range records {
+		day := domain.UsageDay(record.Day)
+		k := usageKey{key: record.Key, operation: record.Operation, day: day}
+		if existing, ok := r.counters[k]; ok {
+			existing.Count += record.Count
+			continue
+		}
+		record.Day = day
+		r.counters[k] = &record
+	}
+
+	return nil
+}
+
+func (r *InMemoryUsageRepository) ListUsage(key string, from, to time.Time) ([]domain.UsageRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	from, to = domain.UsageDay(from), domain.UsageDay(to)
+	records := []domain.UsageRecord{}
+	for k, record := range r.counters {
+		if key != "" && k.key != key {
+			continue
+		}
+		if k.day.Before(from) || k.day.After(to) {
+			continue
+		}
+		records = append(records, *record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].Day.Equal(records[j].Day) {
+			return records[i].Day.Before(records[j].Day)
+		}
+		if records[i].Key != records[j].Key {
+			return records[i].Key < records[j].Key
+		}
+		return records[i].Operation < records[j].Operation
+	})
+
+	return records, nil
+}
+
+func (r *InMemoryUsageRepository) TotalUsage(key string, from, to time.Time) (int64, error) {
+	records, err := r.ListUsage(key, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, record := range records {
+		total += record.Count
+	}
+	return total, nil
+}
@@ -0,0 +1,18 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func TestFindNearestWraparound(t *testing.T) {
+	t.Parallel()
+	for _, strategy := range []geospatial.DistanceStrategy{geospatial.DistanceExact, geospatial.DistanceFast, geospatial.DistanceAuto} {
+		t.Run(string(strategy), func(t *testing.T) {
+			repotest.RunNearestWraparound(t, memory.NewInMemoryLocationRepository(memory.WithDistanceStrategy(strategy)))
+		})
+	}
+}
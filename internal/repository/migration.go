@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/postgres"
+)
+
+// NewMigrationTarget opens the locations of one side of a storage
+// migration. Memory storage starts empty; loading and saving its snapshot
+// file is left to the caller.
+func NewMigrationTarget(cfg config.StorageEndpointConfig) (domain.MigrationTarget, func() error, error) {
+	switch cfg.Type {
+	case MemoryRepository:
+		return memory.NewInMemoryLocationRepository(), func() error { return nil }, nil
+	case PostgresRepository:
+		db, err := postgres.NewConnection(PostgresConfig(cfg.Database))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return postgres.NewPostgresLocationRepository(db), db.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported repository type: %s", cfg.Type)
+	}
+}
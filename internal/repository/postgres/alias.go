@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// resolveNameSQL selects the id of the location whose name or alias is $1
+const resolveNameSQL = `SELECT id FROM locations WHERE name = $1
+			 UNION ALL
+			 SELECT location_id FROM location_aliases WHERE alias = $1
+			 LIMIT 1`
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// lockName serializes claims on a name until the transaction ends. Names
+// and aliases live in separate tables, so no single unique index keeps a
+// new name from matching an alias added at the same moment.
+func lockName(tx *sql.Tx, name string) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, name)
+	return err
+}
+
+// lockLocation locks the location's row until the transaction ends. Alias
+// changes take it too, so a location checked under the lock keeps its
+// aliases as well as its columns.
+func lockLocation(tx *sql.Tx, id string) error {
+	_, err := tx.Exec(`SELECT 1 FROM locations WHERE id = $1 FOR UPDATE`, id)
+	return err
+}
+
+// checkLocation locks the location's row and checks it as stored; with no
+// checks it does neither
+func checkLocation(tx *sql.Tx, id string, checks []domain.Precondition) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	if err := lockLocation(tx, id); err != nil {
+		return err
+	}
+	current, err := findByID(tx, id)
+	if err != nil {
+		return err
+	}
+	return domain.CheckPreconditions(current, checks...)
+}
+
+// nameOwner returns the location holding name as its name or an alias,
+// with only its id and name set, or nil when the name is free
+func nameOwner(q querier, name string) (*domain.Location, error) {
+	var owner domain.Location
+	var id int
+	err := q.QueryRow(`SELECT id, name FROM locations WHERE id = (`+resolveNameSQL+`)`, name).Scan(&id, &owner.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	owner.ID = fmt.Sprintf("%d", id)
+	return &owner, nil
+}
+
+// attachAliases loads the aliases of every location in one query
+func attachAliases(q querier, locations ...*domain.Location) error {
+	if len(locations) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(locations))
+	byID := make(map[int64]*domain.Location, len(locations))
+	for _, location := range locations {
+		id, err := strconv.ParseInt(location.ID, 10, 64)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		byID[id] = location
+		location.Aliases = nil
+	}
+
+	rows, err := q.Query(`SELECT location_id, alias FROM location_aliases
+			 WHERE location_id = ANY($1)
+			 ORDER BY alias`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var alias string
+		if err := rows.Scan(&id, &alias); err != nil {
+			return err
+		}
+		byID[id].Aliases = append(byID[id].Aliases, alias)
+	}
+	return rows.Err()
+}
+
+// AddAlias gives the location an alternate name. Adding an alias it
+// already has changes nothing.
+func (r *PostgresLocationRepository) AddAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockName(tx, alias); err != nil {
+		return nil, err
+	}
+	location, err := nameOwner(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := lockLocation(tx, location.ID); err != nil {
+		return nil, err
+	}
+	if err := checkLocation(tx, location.ID, checks); err != nil {
+		return nil, err
+	}
+	owner, err := nameOwner(tx, alias)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		if owner.ID == location.ID && alias != location.Name {
+			return r.FindByID(location.ID)
+		}
+		return nil, &domain.NameTakenError{Name: alias, Owner: owner.Name}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO location_aliases (alias, location_id) VALUES ($1, $2)`, alias, location.ID); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, domain.ErrLocationExists
+		}
+		return nil, err
+	}
+	return r.commitAliasChange(tx, location)
+}
+
+// RemoveAlias drops one of the location's alternate names
+func (r *PostgresLocationRepository) RemoveAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	location, err := nameOwner(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := lockLocation(tx, location.ID); err != nil {
+		return nil, err
+	}
+	if err := checkLocation(tx, location.ID, checks); err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`DELETE FROM location_aliases WHERE alias = $1 AND location_id = $2`, alias, location.ID)
+	if err != nil {
+		return nil, err
+	}
+	if removed, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if removed == 0 {
+		return nil, domain.ErrAliasNotFound
+	}
+	return r.commitAliasChange(tx, location)
+}
+
+// commitAliasChange records the location's new aliases and commits
+func (r *PostgresLocationRepository) commitAliasChange(tx *sql.Tx, location *domain.Location) (*domain.Location, error) {
+	updated, err := findByID(tx, location.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(tx, domain.ChangeUpdated, updated.Name, *updated); err != nil {
+		return nil, err
+	}
+	if err := notifyChange(tx, location.Name); err != nil {
+		return nil, err
+	}
+	if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationUpdated, *updated)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.wrote()
+	return updated, nil
+}
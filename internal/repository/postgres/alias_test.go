@@ -0,0 +1,14 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestPostgresAliases(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	repotest.RunAliases(t, NewPostgresLocationRepository(db))
+}
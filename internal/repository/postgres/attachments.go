@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// attachments reads and writes the attachments JSONB column
+type attachments struct {
+	list *[]domain.Attachment
+}
+
+// Scan implements sql.Scanner; an empty list or NULL leaves the slice nil
+func (a attachments) Scan(src any) error {
+	*a.list = nil
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into attachments", src)
+	}
+	var list []domain.Attachment
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		*a.list = list
+	}
+	return nil
+}
+
+// Value implements driver.Valuer; no attachments are stored as []
+func (a attachments) Value() (driver.Value, error) {
+	if len(*a.list) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal(*a.list)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
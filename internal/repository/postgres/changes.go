@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// recordChange appends to the change log inside the caller's transaction.
+// Sequences are drawn when a row is inserted but become visible when its
+// transaction commits, so two writers could commit out of order and a
+// reader could pass a sequence before it appears. Holding one advisory
+// lock from the insert until commit makes changes commit in sequence
+// order. The two-key form keeps it apart from the name locks.
+func recordChange(tx *sql.Tx, action domain.ChangeAction, name string, location domain.Location) error {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('location_changes'), 0)`); err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(location)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO location_changes (action, name, snapshot) VALUES ($1, $2, $3)`,
+		string(action), name, snapshot)
+	return err
+}
+
+// ChangesSince reads the page and the latest sequence from one snapshot,
+// so Latest is never behind the changes returned
+func (r *PostgresLocationRepository) ChangesSince(since int64, limit int) (*domain.ChangeFeed, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	feed := &domain.ChangeFeed{Changes: []domain.Change{}}
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM location_changes`).Scan(&feed.Latest); err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(`SELECT seq, action, name, snapshot, changed_at
+			 FROM location_changes
+			 WHERE seq > $1
+			 ORDER BY seq
+			 LIMIT $2`, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var change domain.Change
+		var action string
+		var snapshot []byte
+		if err := rows.Scan(&change.Sequence, &action, &change.Name, &snapshot, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(snapshot, &change.Location); err != nil {
+			return nil, err
+		}
+		change.Action = domain.ChangeAction(action)
+		feed.Changes = append(feed.Changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return feed, tx.Commit()
+}
+
+// CompactChanges deletes every change a later change to the same name
+// supersedes, sparing the newest retain changes
+func (r *PostgresLocationRepository) CompactChanges(retain int) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM location_changes AS c
+			 WHERE c.seq <= (SELECT seq FROM location_changes ORDER BY seq DESC OFFSET $1 LIMIT 1)
+			   AND EXISTS (SELECT 1 FROM location_changes AS later WHERE later.name = c.name AND later.seq > c.seq)`, retain)
+	if err != nil {
+		return 0, err
+	}
+	dropped, err := result.RowsAffected()
+	return int(dropped), err
+}
@@ -0,0 +1,14 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestPostgresChanges(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	repotest.RunChanges(t, NewPostgresLocationRepository(db))
+}
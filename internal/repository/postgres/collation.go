@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"database/sql"
+	"slices"
+	"sync"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+)
+
+// nameOrder decides how Find sorts by name. The database sorts when it has
+// an ICU collation for the configured language; otherwise each page is
+// sorted again under the collation after it is read.
+type nameOrder struct {
+	configured bool
+	collation  *text.Collation
+
+	mu       sync.Mutex
+	resolved bool
+	sqlName  string
+	resort   bool
+}
+
+// icuCollationName is the name PostgreSQL gives the ICU collation for a
+// language when it is initialised with ICU support
+func icuCollationName(collation *text.Collation) string {
+	return collation.Name() + "-x-icu"
+}
+
+// resolve returns the collation to put in ORDER BY, "" for the column's
+// own, and whether the page must be sorted again in the application. The
+// lookup is made once; a failed lookup is retried on the next call.
+func (o *nameOrder) resolve(db *sql.DB) (string, bool) {
+	if !o.configured {
+		return "", false
+	}
+	if o.collation == nil {
+		return "C", false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.resolved {
+		return o.sqlName, o.resort
+	}
+	name := icuCollationName(o.collation)
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_collation WHERE collname = $1 AND collprovider = 'i')`, name).Scan(&exists)
+	if err != nil {
+		return "", true
+	}
+	o.resolved = true
+	if exists {
+		o.sqlName = name
+	} else {
+		o.resort = true
+	}
+	return o.sqlName, o.resort
+}
+
+// sortPage orders a page by name under the collation, keeping the id
+// tie-break the query applied
+func (o *nameOrder) sortPage(locations []*domain.Location, descending bool) {
+	slices.SortStableFunc(locations, func(a, b *domain.Location) int {
+		c := o.collation.Compare(a.Name, b.Name)
+		if descending {
+			return -c
+		}
+		return c
+	})
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+)
+
+func TestPostgresNameCollation(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	for _, name := range repotest.Collations() {
+		t.Run(name, func(t *testing.T) {
+			if _, err := db.Exec("TRUNCATE locations CASCADE"); err != nil {
+				t.Fatalf("Failed to clear locations: %v", err)
+			}
+			collation, err := text.NewCollation(name)
+			if err != nil {
+				t.Fatalf("Failed to load collation: %v", err)
+			}
+			repotest.RunCollation(t, NewPostgresLocationRepository(db, WithNameCollation(collation)), name)
+		})
+	}
+}
+
+func TestPostgresNameCollationWithoutICU(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	collation, _ := text.NewCollation("yo")
+	repo := NewPostgresLocationRepository(db, WithNameCollation(collation))
+	// As on a server built without ICU: the page is sorted after reading
+	repo.names.resolved, repo.names.resort = true, true
+	repotest.RunCollation(t, repo, "yo")
+}
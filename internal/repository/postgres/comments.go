@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+)
+
+// openDB opens a pool over connector, annotating statements with the
+// request identity in their context when comments is set
+func openDB(connector driver.Connector, comments bool) *sql.DB {
+	if comments {
+		connector = commentingConnector{connector}
+	}
+	return sql.OpenDB(connector)
+}
+
+type commentingConnector struct {
+	driver.Connector
+}
+
+func (c commentingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return commentingConn{conn}, nil
+}
+
+// commentingConn appends telemetry.SQLComment to each statement before
+// passing it on. Interfaces the wrapped conn lacks return driver.ErrSkip or
+// their database/sql default, as if they were not implemented.
+type commentingConn struct {
+	driver.Conn
+}
+
+func (c commentingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return queryer.QueryContext(ctx, telemetry.AnnotateSQL(ctx, query), args)
+}
+
+func (c commentingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return execer.ExecContext(ctx, telemetry.AnnotateSQL(ctx, query), args)
+}
+
+func (c commentingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = telemetry.AnnotateSQL(ctx, query)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c commentingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c commentingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c commentingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c commentingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+)
+
+// recordingConnector is a driver that remembers the statements it is sent
+type recordingConnector struct {
+	queries []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return recordingConn{c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c recordingConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c recordingConn) Close() error                        { return nil }
+func (c recordingConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.connector.queries = append(c.connector.queries, query)
+	return emptyRows{}, nil
+}
+
+func (c recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.connector.queries = append(c.connector.queries, query)
+	return driver.RowsAffected(0), nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+func TestSQLComments(t *testing.T) {
+	ctx := telemetry.With(context.Background(), telemetry.KeyOperation, "export-audit-log")
+	ctx = telemetry.With(ctx, telemetry.KeyTenant, "acme")
+	ctx = telemetry.With(ctx, telemetry.KeyPrincipal, "fleet app")
+
+	for _, enabled := range []bool{true, false} {
+		connector := &recordingConnector{}
+		db := openDB(connector, enabled)
+		rows, err := db.QueryContext(ctx, "SELECT id FROM locations WHERE id = $1", "loc-1")
+		if err != nil {
+			t.Fatalf("Failed to query: %v", err)
+		}
+		rows.Close()
+		if _, err := db.ExecContext(context.Background(), "DELETE FROM outbox"); err != nil {
+			t.Fatalf("Failed to exec: %v", err)
+		}
+		db.Close()
+
+		want := []string{"SELECT id FROM locations WHERE id = $1", "DELETE FROM outbox"}
+		if enabled {
+			want[0] += " /*operation='export-audit-log',principal='fleet%20app',tenant='acme'*/"
+		}
+		if len(connector.queries) != 2 || connector.queries[0] != want[0] || connector.queries[1] != want[1] {
+			t.Errorf("With comments %v, expected %q, got %q", enabled, want, connector.queries)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Config struct {
@@ -13,22 +15,51 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// SQLComments appends a sqlcommenter comment to statements run with a
+	// request context; see telemetry.SQLComment
+	SQLComments bool
+	// ConnectTimeout bounds opening a connection, including the startup
+	// handshake, which lib/pq does not cancel with the context; 0 waits
+	// as long as the server takes
+	ConnectTimeout time.Duration
 }
 
-func NewConnection(config Config) (*sql.DB, error) {
+// DSN returns the lib/pq connection string for the config
+func (c Config) DSN() string {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
+		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
+	if c.ConnectTimeout > 0 {
+		// lib/pq takes whole seconds; round down so the timeout fits a
+		// caller's deadline, but never to 0, which would mean none
+		dsn += fmt.Sprintf(" connect_timeout=%d", max(1, int(c.ConnectTimeout.Seconds())))
+	}
+	return dsn
+}
 
-	db, err := sql.Open("postgres", dsn)
+// Open returns a connection pool for config without connecting; the first
+// statement, or a PingContext, opens a connection
+func Open(config Config) (*sql.DB, error) {
+	connector, err := pq.NewConnector(config.DSN())
 	if err != nil {
 		return nil, err
 	}
+	db := openDB(connector, config.SQLComments)
 
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
 
+	return db, nil
+}
+
+func NewConnection(config Config) (*sql.DB, error) {
+	db, err := Open(config)
+	if err != nil {
+		return nil, err
+	}
+
 	if err = db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+// TestPostgresNearestConsistency checks the KNN query against the memory
+// store's exact scan. PostGIS measures on a sphere of 6371.0088 km rather
+// than 6371 km, which scales every distance alike, so rankings agree and
+// reported distances differ by about 1.4e-6 relative.
+func TestPostgresNearestConsistency(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	cfg := repotest.ConsistencyConfigFromEnv(repotest.ConsistencyConfig{Stations: 500, Queries: 200, Seed: 1})
+	stations := repotest.ConsistencyStations(cfg)
+
+	reference := memory.NewInMemoryLocationRepository()
+	repo := NewPostgresLocationRepository(db)
+	repotest.SaveStations(t, reference, stations)
+	repotest.SaveStations(t, repo, stations)
+
+	repotest.RunNearestConsistency(t, cfg, stations,
+		repotest.ConsistencyBackend{Name: "memory/exact", Nearest: reference.FindNearest},
+		repotest.ConsistencyBackend{Name: "postgres", Nearest: repo.FindNearest},
+	)
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// StreamEvents walks the outbox in (created_at, id) order with one keyset
+// query per batch. The range and the cursor are both applied in the query,
+// which the created_at index serves.
+func (r *PostgresOutboxRepository) StreamEvents(ctx context.Context, rng domain.EventRange, batchSize int) iter.Seq2[domain.LoggedEvent, error] {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return func(yield func(domain.LoggedEvent, error) bool) {
+		after := domain.EventCursor{LoggedAt: rng.From}
+		if rng.After != nil {
+			after = *rng.After
+		}
+		for {
+			batch, err := r.eventBatch(ctx, rng.From, rng.To, after, batchSize)
+			if err != nil {
+				yield(domain.LoggedEvent{}, err)
+				return
+			}
+			for _, event := range batch {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			if len(batch) < batchSize {
+				return
+			}
+			after = batch[len(batch)-1].Cursor()
+		}
+	}
+}
+
+func (r *PostgresOutboxRepository) eventBatch(ctx context.Context, from, to time.Time, after domain.EventCursor, limit int) ([]domain.LoggedEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, created_at, payload
+		FROM outbox_events
+		WHERE created_at >= $1 AND created_at < $2
+		  AND (created_at, id) > ($3, $4)
+		ORDER BY created_at, id
+		LIMIT $5`, from, to, after.LoggedAt, after.Sequence, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.LoggedEvent, 0, limit)
+	for rows.Next() {
+		var event domain.LoggedEvent
+		var payload []byte
+		if err := rows.Scan(&event.Sequence, &event.LoggedAt, &payload); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &event.Event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
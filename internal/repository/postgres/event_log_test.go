@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// logEvents records a created event per name at the given times, in the
+// order given rather than time order
+func logEvents(t *testing.T, outbox *PostgresOutboxRepository, at map[string]time.Time, names ...string) {
+	t.Helper()
+	tx, err := outbox.db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin: %v", err)
+	}
+	defer tx.Rollback()
+	for _, name := range names {
+		event := domain.NewEvent(domain.EventLocationCreated, domain.Location{Name: name})
+		event.OccurredAt = at[name]
+		if err := insertOutboxEvent(tx, event); err != nil {
+			t.Fatalf("Failed to log %s: %v", name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func streamedNames(t *testing.T, outbox *PostgresOutboxRepository, rng domain.EventRange, batchSize int) ([]string, []domain.LoggedEvent) {
+	t.Helper()
+	var names []string
+	var events []domain.LoggedEvent
+	for event, err := range outbox.StreamEvents(context.Background(), rng, batchSize) {
+		if err != nil {
+			t.Fatalf("Failed to stream: %v", err)
+		}
+		names = append(names, event.Event.Location.Name)
+		events = append(events, event)
+	}
+	return names, events
+}
+
+func TestPostgresEventLog_StreamsRangeInTimeOrder(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	outbox := NewPostgresOutboxRepository(db)
+
+	start := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	at := map[string]time.Time{
+		"Before": start.Add(-time.Microsecond),
+		"Ikeja":  start,
+		"Yaba":   start.Add(time.Minute),
+		"Lekki":  start.Add(time.Minute),
+		"Ajah":   start.Add(2 * time.Minute),
+		"After":  start.Add(3 * time.Minute),
+	}
+	// Inserted out of time order, so sequence order differs from log order
+	logEvents(t, outbox, at, "Ajah", "After", "Yaba", "Before", "Lekki", "Ikeja")
+
+	rng := domain.EventRange{From: start, To: at["After"]}
+	names, events := streamedNames(t, outbox, rng, 2)
+	if !slices.Equal(names, []string{"Ikeja", "Yaba", "Lekki", "Ajah"}) {
+		t.Fatalf("Expected the range in time then sequence order, got %v", names)
+	}
+
+	// The bounds are applied by the query: a batch larger than the log holds
+	// only the events inside the range
+	batch, err := outbox.eventBatch(context.Background(), rng.From, rng.To, domain.EventCursor{LoggedAt: rng.From}, 100)
+	if err != nil {
+		t.Fatalf("Failed to read a batch: %v", err)
+	}
+	if len(batch) != 4 {
+		t.Errorf("Expected the query to return 4 events in range, got %d", len(batch))
+	}
+
+	// Resuming after Yaba continues with Lekki, which shares its timestamp
+	rng.After = &domain.EventCursor{LoggedAt: events[1].LoggedAt, Sequence: events[1].Sequence}
+	names, _ = streamedNames(t, outbox, rng, 2)
+	if !slices.Equal(names, []string{"Lekki", "Ajah"}) {
+		t.Errorf("Expected Lekki and Ajah after the cursor, got %v", names)
+	}
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// openingHours reads and writes the nullable opening_hours JSONB column
+type openingHours struct {
+	hours **domain.OpeningHours
+}
+
+// Scan implements sql.Scanner; NULL leaves the hours nil
+func (h openingHours) Scan(src any) error {
+	*h.hours = nil
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into opening hours", src)
+	}
+	var hours domain.OpeningHours
+	if err := json.Unmarshal(data, &hours); err != nil {
+		return err
+	}
+	*h.hours = &hours
+	return nil
+}
+
+// Value implements driver.Valuer; nil hours are stored as NULL
+func (h openingHours) Value() (driver.Value, error) {
+	if *h.hours == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(*h.hours)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// uniqueViolation is the SQLSTATE Postgres reports for a duplicate key
+const uniqueViolation = "23505"
+
+// StreamLocations walks the table in id order with one keyset query per
+// batch, so no transaction or cursor stays open between batches
+func (r *PostgresLocationRepository) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return func(yield func(*domain.Location, error) bool) {
+		lastID := 0
+		for {
+			batch, next, err := r.streamBatch(ctx, lastID, batchSize)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, location := range batch {
+				if !yield(location, nil) {
+					return
+				}
+			}
+			if len(batch) < batchSize {
+				return
+			}
+			lastID = next
+		}
+	}
+}
+
+func (r *PostgresLocationRepository) streamBatch(ctx context.Context, afterID, limit int) ([]*domain.Location, int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by
+		FROM locations
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, afterID, err
+	}
+	defer rows.Close()
+
+	lastID := afterID
+	locations := make([]*domain.Location, 0, limit)
+	for rows.Next() {
+		var location domain.Location
+		var id int
+		if err := rows.Scan(&id, &location.Name, &location.Latitude, &location.Longitude, nullableTime{&location.CreatedAt}, openingHours{&location.OpeningHours}, &location.Description, &location.Region, attachments{&location.Attachments}, &location.CapacityLitres, &location.CurrentStockLitres, &location.CreatedBy); err != nil {
+			return nil, afterID, err
+		}
+		lastID = id
+		location.ID = fmt.Sprintf("%d", id)
+		locations = append(locations, &location)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, afterID, err
+	}
+	rows.Close()
+	logDefaulted(locations)
+	return locations, lastID, attachAliases(r.db, locations...)
+}
+
+// RenameLocation updates the name and notifies other replicas of both the
+// old and the new name
+func (r *PostgresLocationRepository) RenameLocation(id, name string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	err = tx.QueryRow(`SELECT name FROM locations WHERE id = $1 FOR UPDATE`, id).Scan(&oldName)
+	if err == sql.ErrNoRows {
+		return domain.ErrLocationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if oldName == name {
+		return nil
+	}
+	if err := lockName(tx, name); err != nil {
+		return err
+	}
+	owner, err := nameOwner(tx, name)
+	if err != nil {
+		return err
+	}
+	if owner != nil {
+		return domain.NameConflict(name, owner)
+	}
+	previous, err := findByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE locations SET name = $2 WHERE id = $1`, id, name); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return domain.ErrLocationExists
+		}
+		return err
+	}
+	renamed := *previous
+	renamed.Name = name
+	if err := recordChange(tx, domain.ChangeDeleted, oldName, *previous); err != nil {
+		return err
+	}
+	if err := recordChange(tx, domain.ChangeUpdated, name, renamed); err != nil {
+		return err
+	}
+	if err := notifyChange(tx, oldName); err != nil {
+		return err
+	}
+	if err := notifyChange(tx, name); err != nil {
+		return err
+	}
+	if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationUpdated, renamed)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	r.wrote()
+	return nil
+}
@@ -0,0 +1,19 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestPostgresIntegrityStore(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	repotest.RunIntegrityStore(t, NewPostgresLocationRepository(db), func(name string, latitude, longitude float64) {
+		// Raw SQL skips the domain rules, as an old import did
+		if _, err := db.Exec(`INSERT INTO locations (name, latitude, longitude) VALUES ($1, $2, $3)`, name, latitude, longitude); err != nil {
+			t.Fatalf("Failed to seed %q: %v", name, err)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type PostgresJobRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresJobRepository(db *sql.DB) *PostgresJobRepository {
+	return &PostgresJobRepository{db: db}
+}
+
+const importColumns = `id, status, total, processed, imported, failed, errors, error, cancel_requested,
+	created_at, updated_at, started_at, finished_at`
+
+func (r *PostgresJobRepository) CreateImport(job *domain.ImportJob, data []byte) error {
+	errs, err := json.Marshal(importErrors(job.Errors))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`INSERT INTO import_jobs (id, status, data, total, processed, imported, failed, errors, error,
+			 created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		job.ID, string(job.Status), data, job.Total, job.Processed, job.Imported, job.Failed, errs, job.Error,
+		job.CreatedAt, job.UpdatedAt)
+	return err
+}
+
+func (r *PostgresJobRepository) FindImport(id string) (*domain.ImportJob, error) {
+	job, err := scanImport(r.db.QueryRow(`SELECT `+importColumns+` FROM import_jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrImportNotFound
+	}
+	return job, err
+}
+
+func (r *PostgresJobRepository) ClaimImport(now time.Time) (*domain.ImportJob, []byte, error) {
+	// SKIP LOCKED lets several instances claim concurrently without
+	// running the same job twice
+	var data []byte
+	row := r.db.QueryRow(`UPDATE import_jobs SET status = 'running', started_at = $1, updated_at = $1
+			 WHERE id = (
+				 SELECT id FROM import_jobs WHERE status = 'pending'
+				 ORDER BY created_at, id
+				 LIMIT 1
+				 FOR UPDATE SKIP LOCKED
+			 )
+			 RETURNING data, `+importColumns, now)
+	job, err := scanImport(row, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, data, nil
+}
+
+func (r *PostgresJobRepository) UpdateImport(job *domain.ImportJob) error {
+	errs, err := json.Marshal(importErrors(job.Errors))
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRow(`UPDATE import_jobs SET status = $2, processed = $3, imported = $4, failed = $5, errors = $6,
+			 error = $7, updated_at = $8, finished_at = $9,
+			 data = CASE WHEN $2 IN ('completed', 'failed', 'cancelled') THEN NULL ELSE data END
+			 WHERE id = $1
+			 RETURNING cancel_requested`,
+		job.ID, string(job.Status), job.Processed, job.Imported, job.Failed, errs, job.Error, job.UpdatedAt,
+		job.FinishedAt).Scan(&job.CancelRequested)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrImportNotFound
+	}
+	return err
+}
+
+func (r *PostgresJobRepository) CancelImport(id string, now time.Time) (*domain.ImportJob, error) {
+	job, err := scanImport(r.db.QueryRow(`UPDATE import_jobs SET
+			 status = CASE WHEN status = 'pending' THEN 'cancelled' ELSE status END,
+			 finished_at = CASE WHEN status = 'pending' THEN $2 ELSE finished_at END,
+			 updated_at = CASE WHEN status = 'pending' THEN $2 ELSE updated_at END,
+			 data = CASE WHEN status = 'pending' THEN NULL ELSE data END,
+			 cancel_requested = cancel_requested OR status = 'running'
+			 WHERE id = $1
+			 RETURNING `+importColumns, id, now))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrImportNotFound
+	}
+	return job, err
+}
+
+func (r *PostgresJobRepository) FailStaleImports(before time.Time, reason string) (int, error) {
+	result, err := r.db.Exec(`UPDATE import_jobs SET status = 'failed', error = $2, data = NULL,
+			 updated_at = NOW(), finished_at = NOW()
+			 WHERE status = 'running' AND updated_at < $1`, before, reason)
+	if err != nil {
+		return 0, err
+	}
+	failed, err := result.RowsAffected()
+	return int(failed), err
+}
+
+// scanImport reads a row of importColumns, after any extra destinations
+func scanImport(row *sql.Row, extra ...any) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	var status string
+	var errs []byte
+	var startedAt, finishedAt sql.NullTime
+	dest := append(extra, &job.ID, &status, &job.Total, &job.Processed, &job.Imported, &job.Failed, &errs, &job.Error,
+		&job.CancelRequested, &job.CreatedAt, &job.UpdatedAt, &startedAt, &finishedAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	job.Status = domain.ImportStatus(status)
+	if err := json.Unmarshal(errs, &job.Errors); err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// importErrors stores no errors as an empty array rather than null
+func importErrors(errs []domain.ImportRowError) []domain.ImportRowError {
+	if errs == nil {
+		return []domain.ImportRowError{}
+	}
+	return errs
+}
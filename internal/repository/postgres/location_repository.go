@@ -4,52 +4,124 @@ import (
 	"database/sql"
 	"fmt"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 type PostgresLocationRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	names nameOrder
+	// replica is nil unless reads may go to a read replica
+	replica *readReplica
 }
 
-func NewPostgresLocationRepository(db *sql.DB) *PostgresLocationRepository {
-	return &PostgresLocationRepository{db: db}
+// Option configures a PostgresLocationRepository
+type Option func(*PostgresLocationRepository)
+
+// WithNameCollation sets how Find orders names; nil is byte order. Without
+// this option names sort under the column's collation.
+func WithNameCollation(collation *text.Collation) Option {
+	return func(r *PostgresLocationRepository) {
+		r.names.configured = true
+		r.names.collation = collation
+	}
+}
+
+func NewPostgresLocationRepository(db *sql.DB, opts ...Option) *PostgresLocationRepository {
+	r := &PostgresLocationRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 func (r *PostgresLocationRepository) Save(location *domain.Location) error {
-	existingLocation, err := r.FindByName(location.Name)
-	if err == nil && existingLocation != nil {
-		return domain.ErrLocationExists
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	query := `INSERT INTO locations (name, latitude, longitude) 
-			 VALUES ($1, $2, $3) 
+	if err := lockName(tx, location.Name); err != nil {
+		return err
+	}
+	if err := insertLocation(tx, location); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	r.wrote()
+	return nil
+}
+
+// insertLocation stores a new location with its event and change record.
+// The caller holds the name's lock.
+func insertLocation(tx *sql.Tx, location *domain.Location) error {
+	owner, err := nameOwner(tx, location.Name)
+	if err != nil {
+		return err
+	}
+	if owner != nil {
+		return domain.NameConflict(location.Name, owner)
+	}
+
+	query := `INSERT INTO locations (name, latitude, longitude, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by) 
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
 			 RETURNING id, created_at`
 
 	var id int
-	err = r.db.QueryRow(query, location.Name, location.Latitude, location.Longitude).Scan(&id, &location.CreatedAt)
+	err = tx.QueryRow(query, location.Name, location.Latitude, location.Longitude, openingHours{&location.OpeningHours}, location.Description, location.Region, attachments{&location.Attachments}, location.CapacityLitres, location.CurrentStockLitres, location.CreatedBy).Scan(&id, &location.CreatedAt)
 	if err != nil {
 		return err
 	}
 
 	location.ID = fmt.Sprintf("%d", id)
-	return nil
+	location.Aliases = nil
+
+	// The event commits or rolls back together with the row it describes
+	if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationCreated, *location)); err != nil {
+		return err
+	}
+	if err := recordChange(tx, domain.ChangeCreated, location.Name, *location); err != nil {
+		return err
+	}
+	return notifyChange(tx, location.Name)
 }
 
 func (r *PostgresLocationRepository) FindByName(name string) (*domain.Location, error) {
-	query := `SELECT id, name, latitude, longitude, created_at 
+	var location *domain.Location
+	err := r.read(func(q querier) error {
+		var err error
+		location, err = findByName(q, name)
+		return err
+	})
+	return location, err
+}
+
+func findByName(q querier, name string) (*domain.Location, error) {
+	query := `SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by 
 			 FROM locations 
-			 WHERE name = $1`
+			 WHERE id = (` + resolveNameSQL + `)`
 
 	var location domain.Location
 	var id int
-	err := r.db.QueryRow(query, name).Scan(
+	err := q.QueryRow(query, name).Scan(
 		&id,
 		&location.Name,
 		&location.Latitude,
 		&location.Longitude,
-		&location.CreatedAt,
+		nullableTime{&location.CreatedAt},
+		openingHours{&location.OpeningHours},
+		&location.Description,
+		&location.Region,
+		attachments{&location.Attachments},
+		&location.CapacityLitres,
+		&location.CurrentStockLitres,
+		&location.CreatedBy,
 	)
 
 	if err != nil {
@@ -60,22 +132,41 @@ func (r *PostgresLocationRepository) FindByName(name string) (*domain.Location,
 	}
 
 	location.ID = fmt.Sprintf("%d", id)
-	return &location, nil
+	return &location, attachAliases(q, &location)
 }
 
 func (r *PostgresLocationRepository) FindByID(id string) (*domain.Location, error) {
-	query := `SELECT id, name, latitude, longitude, created_at 
+	var location *domain.Location
+	err := r.read(func(q querier) error {
+		var err error
+		location, err = findByID(q, id)
+		return err
+	})
+	return location, err
+}
+
+// findByID reads a location with its aliases through q, so transactions
+// see their own writes
+func findByID(q querier, id string) (*domain.Location, error) {
+	query := `SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by 
 			 FROM locations 
 			 WHERE id = $1`
 
 	var location domain.Location
 	var dbID int
-	err := r.db.QueryRow(query, id).Scan(
+	err := q.QueryRow(query, id).Scan(
 		&dbID,
 		&location.Name,
 		&location.Latitude,
 		&location.Longitude,
-		&location.CreatedAt,
+		nullableTime{&location.CreatedAt},
+		openingHours{&location.OpeningHours},
+		&location.Description,
+		&location.Region,
+		attachments{&location.Attachments},
+		&location.CapacityLitres,
+		&location.CurrentStockLitres,
+		&location.CreatedBy,
 	)
 
 	if err != nil {
@@ -86,15 +177,58 @@ func (r *PostgresLocationRepository) FindByID(id string) (*domain.Location, erro
 	}
 
 	location.ID = fmt.Sprintf("%d", dbID)
-	return &location, nil
+	return &location, attachAliases(q, &location)
 }
 
 func (r *PostgresLocationRepository) FindAll() ([]*domain.Location, error) {
-	query := `SELECT id, name, latitude, longitude, created_at 
-			 FROM locations 
-			 ORDER BY id`
+	return r.Find(domain.LocationFilter{}, domain.Page{}, domain.LocationSort{})
+}
 
-	rows, err := r.db.Query(query)
+func (r *PostgresLocationRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	var nameCollation string
+	var resort bool
+	if order.Field == domain.SortByName {
+		nameCollation, resort = r.names.resolve(r.db)
+	}
+	query, args := buildFindQuery(filter, page, order, nameCollation)
+	var locations []*domain.Location
+	err := r.read(func(q querier) error {
+		var err error
+		locations, err = find(q, query, args)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resort {
+		r.names.sortPage(locations, order.Descending)
+	}
+	return locations, nil
+}
+
+// PagesSort is false for a name order the database has no collation for,
+// as Find then sorts each page after reading it
+func (r *PostgresLocationRepository) PagesSort(order domain.LocationSort) bool {
+	if order.Field != domain.SortByName {
+		return true
+	}
+	_, resort := r.names.resolve(r.db)
+	return !resort
+}
+
+// CountMatching counts the locations passing filter
+func (r *PostgresLocationRepository) CountMatching(filter domain.LocationFilter) (int, error) {
+	query, args := buildCountQuery(filter)
+	var count int
+	err := r.read(func(q querier) error {
+		return q.QueryRow(query, args...).Scan(&count)
+	})
+	return count, err
+}
+
+// find reads the locations query selects, with their aliases
+func find(q querier, query string, args []any) ([]*domain.Location, error) {
+	rows, err := q.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +243,14 @@ func (r *PostgresLocationRepository) FindAll() ([]*domain.Location, error) {
 			&location.Name,
 			&location.Latitude,
 			&location.Longitude,
-			&location.CreatedAt,
+			nullableTime{&location.CreatedAt},
+			openingHours{&location.OpeningHours},
+			&location.Description,
+			&location.Region,
+			attachments{&location.Attachments},
+			&location.CapacityLitres,
+			&location.CurrentStockLitres,
+			&location.CreatedBy,
 		)
 		if err != nil {
 			return nil, err
@@ -121,47 +262,237 @@ func (r *PostgresLocationRepository) FindAll() ([]*domain.Location, error) {
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-
-	return locations, nil
+	logDefaulted(locations)
+	return locations, attachAliases(q, locations...)
 }
 
-func (r *PostgresLocationRepository) Delete(name string) error {
-	query := `DELETE FROM locations WHERE name = $1`
-
-	result, err := r.db.Exec(query, name)
+func (r *PostgresLocationRepository) Delete(name string, checks ...domain.Precondition) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	if _, err := deleteLocation(tx, name, checks...); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	r.wrote()
+	return nil
+}
+
+// deleteLocation deletes the location found by name, once it passes
+// checks, with its event and change record, and returns it as it was
+func deleteLocation(tx *sql.Tx, name string, checks ...domain.Precondition) (*domain.Location, error) {
+	// Read the aliases first; they are deleted along with the location
+	owner, err := nameOwner(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := checkLocation(tx, owner.ID, checks); err != nil {
+		return nil, err
+	}
+	var location domain.Location
+	location.ID = owner.ID
+	if err := attachAliases(tx, &location); err != nil {
+		return nil, err
+	}
 
-	rowsAffected, err := result.RowsAffected()
+	query := `DELETE FROM locations WHERE id = $1
+			 RETURNING id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by`
+
+	var id int
+	err = tx.QueryRow(query, owner.ID).Scan(
+		&id,
+		&location.Name,
+		&location.Latitude,
+		&location.Longitude,
+		nullableTime{&location.CreatedAt},
+		openingHours{&location.OpeningHours},
+		&location.Description,
+		&location.Region,
+		attachments{&location.Attachments},
+		&location.CapacityLitres,
+		&location.CurrentStockLitres,
+		&location.CreatedBy,
+	)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrLocationNotFound
+		}
+		return nil, err
+	}
+
+	location.ID = fmt.Sprintf("%d", id)
+	if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationDeleted, location)); err != nil {
+		return nil, err
+	}
+	if err := recordChange(tx, domain.ChangeDeleted, location.Name, location); err != nil {
+		return nil, err
+	}
+	if err := notifyChange(tx, location.Name); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *PostgresLocationRepository) Count() (int, error) {
+	var count int
+	err := r.read(func(q querier) error {
+		return q.QueryRow(`SELECT COUNT(*) FROM locations`).Scan(&count)
+	})
+	return count, err
+}
+
+func (r *PostgresLocationRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	return r.findNearest("", 0, latitude, longitude, exclude)
+}
+
+// FindNearestInRegion searches one region; the (region, geom) index lets
+// the KNN scan start inside it
+func (r *PostgresLocationRepository) FindNearestInRegion(region string, latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	return r.findNearest(region, 0, latitude, longitude, exclude)
+}
+
+// findNearest searches every location when region is empty, and every
+// stock when minStock is 0
+func (r *PostgresLocationRepository) findNearest(region string, minStock, latitude, longitude float64, exclude []string) (*domain.Location, geospatial.Distance, error) {
+	query, args := nearestQuery(region, minStock, latitude, longitude, exclude)
+	// Ties at the same distance go to the lowest name in byte order, then
+	// the lowest id, as in the memory store; the KNN scan still drives the
+	// search and only the tied rows are sorted on top of it
+	query += `
+			  ORDER BY geom <-> ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, name COLLATE "C", id
+			  LIMIT 1`
+
+	var location *domain.Location
+	var distance geospatial.Distance
+	err := r.read(func(q querier) error {
+		var err error
+		location, distance, err = findNearest(q, query, args)
 		return err
+	})
+	return location, distance, err
+}
+
+// FindWithinRadius lets ST_DWithin pick the rows from the geom index and
+// orders them as findNearest does
+func (r *PostgresLocationRepository) FindWithinRadius(latitude, longitude float64, radius geospatial.Distance, limit int) ([]domain.NearestResult, error) {
+	query, args := nearestQuery("", 0, latitude, longitude, nil)
+	args = append(args, radius.Meters())
+	query += fmt.Sprintf(`
+			  AND ST_DWithin(geom, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $%d, false)
+			  ORDER BY distance_m, name COLLATE "C", id`, len(args))
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
 	}
 
-	if rowsAffected == 0 {
-		return domain.ErrLocationNotFound
+	var results []domain.NearestResult
+	err := r.read(func(q querier) error {
+		var err error
+		results, err = findWithinRadius(q, query, args)
+		return err
+	})
+	return results, err
+}
+
+// findWithinRadius reads the locations query selects, with their aliases
+// and distances
+func findWithinRadius(q querier, query string, args []any) ([]domain.NearestResult, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
+	results := []domain.NearestResult{}
+	var locations []*domain.Location
+	for rows.Next() {
+		var location domain.Location
+		var id int
+		var distanceM float64
+		if err := rows.Scan(
+			&id,
+			&location.Name,
+			&location.Latitude,
+			&location.Longitude,
+			nullableTime{&location.CreatedAt},
+			openingHours{&location.OpeningHours},
+			&location.Description,
+			&location.Region,
+			attachments{&location.Attachments},
+			&location.CapacityLitres,
+			&location.CurrentStockLitres,
+			&location.CreatedBy,
+			&distanceM,
+		); err != nil {
+			return nil, err
+		}
+		location.ID = fmt.Sprintf("%d", id)
+		locations = append(locations, &location)
+		results = append(results, domain.NearestResult{Location: &location, Distance: geospatial.Meters(distanceM)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logDefaulted(locations)
+	return results, attachAliases(q, locations...)
 }
 
-func (r *PostgresLocationRepository) FindNearest(latitude, longitude float64) (*domain.Location, float64, error) {
-	query := `SELECT id, name, latitude, longitude, created_at,
-				 ST_Distance(geom, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance
+// nearestQuery selects the locations a nearest search may answer with and
+// their distance, unordered
+func nearestQuery(region string, minStock, latitude, longitude float64, exclude []string) (string, []any) {
+	// The filter runs before the KNN ordering so the index scan skips
+	// excluded rows instead of returning them. Distance is measured on the
+	// sphere, like the KNN operator and the memory store; PostGIS reports
+	// geography distances in metres.
+	query := `SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by,
+				 ST_Distance(geom, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, false) as distance_m
 			  FROM locations 
-			  ORDER BY geom <-> ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography 
-			  LIMIT 1`
+			  WHERE name != ALL($3::text[])`
+	args := []any{longitude, latitude}
+	// A nil array would be sent as NULL, which matches no rows
+	if exclude == nil {
+		exclude = []string{}
+	}
+	args = append(args, pq.Array(exclude))
+	if region != "" {
+		args = append(args, region)
+		query += fmt.Sprintf(` AND region = $%d`, len(args))
+	}
+	if minStock > 0 {
+		args = append(args, minStock)
+		query += fmt.Sprintf(` AND current_stock_litres >= $%d`, len(args))
+	}
+	return query, args
+}
 
+// findNearest reads the location query selects, with its aliases and
+// distance
+func findNearest(q querier, query string, args []any) (*domain.Location, geospatial.Distance, error) {
 	var location domain.Location
 	var id int
-	var distance float64
-	err := r.db.QueryRow(query, longitude, latitude).Scan(
+	var distanceM float64
+	err := q.QueryRow(query, args...).Scan(
 		&id,
 		&location.Name,
 		&location.Latitude,
 		&location.Longitude,
-		&location.CreatedAt,
-		&distance,
+		nullableTime{&location.CreatedAt},
+		openingHours{&location.OpeningHours},
+		&location.Description,
+		&location.Region,
+		attachments{&location.Attachments},
+		&location.CapacityLitres,
+		&location.CurrentStockLitres,
+		&location.CreatedBy,
+		&distanceM,
 	)
 
 	if err != nil {
@@ -172,5 +503,8 @@ func (r *PostgresLocationRepository) FindNearest(latitude, longitude float64) (*
 	}
 
 	location.ID = fmt.Sprintf("%d", id)
-	return &location, distance, nil
+	if err := attachAliases(q, &location); err != nil {
+		return nil, 0, err
+	}
+	return &location, geospatial.Meters(distanceM), nil
 }
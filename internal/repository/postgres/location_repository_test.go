@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +19,30 @@ import (
 )
 
 func setupTestContainer(t *testing.T) (*sql.DB, func()) {
+	db, _, cleanup := setupTestContainerWithDSN(t)
+	return db, cleanup
+}
+
+// setupTestContainerWithDSN also returns the connection string so tests can
+// open further connections, such as change listeners
+func setupTestContainerWithDSN(t *testing.T) (*sql.DB, string, func()) {
+	db, connStr, terminate := startTestContainer(t)
+
+	// Build the schema from the same migrations production runs
+	applyMigrations(t, db)
+
+	cleanup := func() {
+		if _, err := db.Exec("TRUNCATE locations, outbox_events, usage_counters"); err != nil {
+			t.Logf("Failed to clean up test data: %v", err)
+		}
+		terminate()
+	}
+
+	return db, connStr, cleanup
+}
+
+// startTestContainer starts an empty PostGIS database, without the schema
+func startTestContainer(t *testing.T) (*sql.DB, string, func()) {
 	ctx := context.Background()
 
 	postgresContainer, err := postgres.Run(ctx,
@@ -44,61 +72,40 @@ func setupTestContainer(t *testing.T) (*sql.DB, func()) {
 		t.Fatalf("Failed to ping database: %v", err)
 	}
 
-	// Enable PostGIS extension
-	if _, err := db.Exec("CREATE EXTENSION IF NOT EXISTS postgis"); err != nil {
-		t.Fatalf("Failed to create PostGIS extension: %v", err)
+	terminate := func() {
+		db.Close()
+		if err := postgresContainer.Terminate(ctx); err != nil {
+			t.Logf("Failed to terminate container: %v", err)
+		}
 	}
 
-	// Create test table with PostGIS support
-	createTableQuery := `
-		CREATE TABLE IF NOT EXISTS locations (
-			id SERIAL PRIMARY KEY,
-			name VARCHAR(255) UNIQUE NOT NULL,
-			latitude DOUBLE PRECISION NOT NULL,
-			longitude DOUBLE PRECISION NOT NULL,
-			geom GEOGRAPHY(POINT, 4326),
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	if _, err := db.Exec(createTableQuery); err != nil {
-		t.Fatalf("Failed to create test table: %v", err)
-	}
+	return db, connStr, terminate
+}
 
-	// Create spatial index
-	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_locations_geom ON locations USING GIST (geom)"); err != nil {
-		t.Fatalf("Failed to create spatial index: %v", err)
-	}
+// applyMigrations runs the goose Up section of every migration in order
+func applyMigrations(t *testing.T, db *sql.DB) {
+	t.Helper()
 
-	// Create trigger to update geometry column
-	triggerQuery := `
-		CREATE OR REPLACE FUNCTION update_location_geom()
-		RETURNS TRIGGER AS $$
-		BEGIN
-			NEW.geom = ST_Point(NEW.longitude, NEW.latitude)::geography;
-			RETURN NEW;
-		END;
-		$$ LANGUAGE plpgsql;
-
-		DROP TRIGGER IF EXISTS trigger_update_location_geom ON locations;
-		CREATE TRIGGER trigger_update_location_geom
-			BEFORE INSERT OR UPDATE ON locations
-			FOR EACH ROW EXECUTE FUNCTION update_location_geom();
-	`
-	if _, err := db.Exec(triggerQuery); err != nil {
-		t.Fatalf("Failed to create trigger: %v", err)
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "scripts", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatalf("Failed to list migrations: %v", err)
 	}
+	sort.Strings(files)
 
-	cleanup := func() {
-		if _, err := db.Exec("DELETE FROM locations"); err != nil {
-			t.Logf("Failed to clean up test data: %v", err)
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read migration %s: %v", file, err)
 		}
-		db.Close()
-		if err := postgresContainer.Terminate(ctx); err != nil {
-			t.Logf("Failed to terminate container: %v", err)
+
+		up := string(raw)
+		if i := strings.Index(up, "-- +goose Down"); i >= 0 {
+			up = up[:i]
+		}
+		if _, err := db.Exec(up); err != nil {
+			t.Fatalf("Failed to apply migration %s: %v", file, err)
 		}
 	}
-
-	return db, cleanup
 }
 
 func TestPostgresLocationRepository_Save(t *testing.T) {
@@ -348,6 +355,43 @@ func TestPostgresLocationRepository_FindNearest(t *testing.T) {
 		}
 	})
 
+	t.Run("excluded locations are skipped", func(t *testing.T) {
+		db, cleanup := setupTestContainer(t)
+		defer cleanup()
+		repo := NewPostgresLocationRepository(db)
+
+		for _, location := range []*domain.Location{
+			{Name: "New York", Latitude: 40.7128, Longitude: -74.0060, CreatedAt: time.Now()},
+			{Name: "Boston", Latitude: 42.3601, Longitude: -71.0589, CreatedAt: time.Now()},
+			{Name: "Miami", Latitude: 25.7617, Longitude: -80.1918, CreatedAt: time.Now()},
+		} {
+			if err := repo.Save(location); err != nil {
+				t.Fatalf("Failed to save location %s: %v", location.Name, err)
+			}
+		}
+
+		_, bostonDistance, err := repo.FindNearest(40.7500, -74.0000, "New York", "Miami")
+		if err != nil {
+			t.Fatalf("Failed to find nearest location: %v", err)
+		}
+
+		nearest, distance, err := repo.FindNearest(40.7500, -74.0000, "New York", "Unknown")
+		if err != nil {
+			t.Fatalf("Failed to find nearest location: %v", err)
+		}
+		if nearest.Name != "Boston" {
+			t.Errorf("Expected second-nearest 'Boston', got '%s'", nearest.Name)
+		}
+		if distance != bostonDistance {
+			t.Errorf("Expected distance to Boston %f, got %f", bostonDistance, distance)
+		}
+
+		_, _, err = repo.FindNearest(40.7500, -74.0000, "New York", "Boston", "Miami")
+		if err != domain.ErrLocationNotFound {
+			t.Errorf("Expected ErrLocationNotFound when every location is excluded, got: %v", err)
+		}
+	})
+
 	t.Run("no locations found", func(t *testing.T) {
 		db, cleanup := setupTestContainer(t)
 		defer cleanup()
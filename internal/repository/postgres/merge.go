@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// MergeLocations deletes the losers, records their deletion events and
+// writes the audit entry in one transaction. The winner and losers are
+// locked first, so a missing name rolls everything back.
+func (r *PostgresLocationRepository) MergeLocations(winner string, losers []string, actor string) (*domain.MergeAudit, error) {
+	if err := domain.ValidateMerge(winner, losers); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	names := append([]string{winner}, losers...)
+	rows, err := tx.Query(`SELECT id, name, latitude, longitude, created_at
+			 FROM locations
+			 WHERE name = ANY($1)
+			 FOR UPDATE`, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]domain.Location{}
+	for rows.Next() {
+		var location domain.Location
+		var id int
+		if err := rows.Scan(&id, &location.Name, &location.Latitude, &location.Longitude, nullableTime{&location.CreatedAt}); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		location.ID = fmt.Sprintf("%d", id)
+		found[location.Name] = location
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if _, ok := found[name]; !ok {
+			return nil, &domain.MergeNotFoundError{Name: name}
+		}
+	}
+
+	// The change log keeps each loser's full last state, aliases included
+	snapshots := make(map[string]*domain.Location, len(losers))
+	for _, name := range losers {
+		snapshot, err := findByID(tx, found[name].ID)
+		if err != nil {
+			return nil, err
+		}
+		snapshots[name] = snapshot
+	}
+
+	if _, err := tx.Exec(`DELETE FROM locations WHERE name = ANY($1)`, pq.Array(losers)); err != nil {
+		return nil, err
+	}
+
+	audit := domain.MergeAudit{Winner: winner, Actor: actor}
+	for _, name := range losers {
+		location := found[name]
+		audit.Losers = append(audit.Losers, location)
+		if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationDeleted, location)); err != nil {
+			return nil, err
+		}
+		if err := recordChange(tx, domain.ChangeDeleted, name, *snapshots[name]); err != nil {
+			return nil, err
+		}
+		if err := notifyChange(tx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := json.Marshal(audit.Losers)
+	if err != nil {
+		return nil, err
+	}
+	var id int64
+	err = tx.QueryRow(`INSERT INTO location_merges (winner, losers, actor)
+			 VALUES ($1, $2, NULLIF($3, ''))
+			 RETURNING id, merged_at`, winner, payload, actor).Scan(&id, &audit.MergedAt)
+	if err != nil {
+		return nil, err
+	}
+	audit.ID = fmt.Sprintf("%d", id)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.wrote()
+	return &audit, nil
+}
+
+// Merges returns the merge audit log, oldest first
+func (r *PostgresLocationRepository) Merges() ([]domain.MergeAudit, error) {
+	rows, err := r.db.Query(`SELECT id, winner, losers, COALESCE(actor, ''), merged_at
+			 FROM location_merges
+			 ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merges []domain.MergeAudit
+	for rows.Next() {
+		var audit domain.MergeAudit
+		var id int64
+		var losers []byte
+		if err := rows.Scan(&id, &audit.Winner, &losers, &audit.Actor, &audit.MergedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(losers, &audit.Losers); err != nil {
+			return nil, err
+		}
+		audit.ID = fmt.Sprintf("%d", id)
+		merges = append(merges, audit)
+	}
+	return merges, rows.Err()
+}
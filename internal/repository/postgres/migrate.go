@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// migrationLockKey is the advisory lock migrators hold while applying
+// migrations, so instances starting together apply them once. The value
+// is arbitrary but must never change between releases.
+const migrationLockKey int64 = 0x6c65657461 // "leeta"
+
+// migrationLockPoll is how often a migrator waiting for the lock tries to
+// take it again
+var migrationLockPoll = 250 * time.Millisecond
+
+// ErrMigrationLockTimeout is returned when another instance held the
+// migration lock for the whole wait and migrations are still pending
+var ErrMigrationLockTimeout = errors.New("timed out waiting for another instance to apply migrations")
+
+// MigrateResult says what Migrate did
+type MigrateResult struct {
+	// Locked is set when this instance held the migration lock; when it
+	// is clear the wait timed out and the schema was found up to date
+	Locked bool
+	// Contended is set when another instance held the lock at first
+	Contended bool
+	// Waited is how long taking the lock took, or the whole wait
+	Waited time.Duration
+	// Applied are the migrations this instance applied, in order
+	Applied []Migration
+}
+
+// Migrate applies the pending migrations the way goose up would, each in
+// its own transaction and recorded in goose_db_version, so goose and
+// --check see them as applied. It first takes a Postgres advisory lock,
+// waiting up to wait while another instance holds it; that instance's
+// migrations are then already applied and this one applies none. When
+// the wait runs out Migrate checks the schema instead: with nothing
+// pending it returns without the lock, and otherwise fails with
+// ErrMigrationLockTimeout.
+func Migrate(ctx context.Context, db *sql.DB, migrations []Migration, wait time.Duration) (MigrateResult, error) {
+	// Advisory locks belong to a session, so every statement runs on
+	// the connection that holds it
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return MigrateResult{}, err
+	}
+	defer conn.Close()
+
+	var result MigrateResult
+	start := time.Now()
+	locked, contended, err := lockMigrations(ctx, conn, wait)
+	result.Contended = contended
+	result.Waited = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	if !locked {
+		plan, err := planMigrations(ctx, conn, migrations)
+		if err != nil {
+			return result, err
+		}
+		if len(plan.Pending) > 0 {
+			return result, fmt.Errorf("%w: %d migrations pending after %v", ErrMigrationLockTimeout, len(plan.Pending), wait)
+		}
+		return result, nil
+	}
+	result.Locked = true
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return result, err
+	}
+	plan, err := planMigrations(ctx, conn, migrations)
+	if err != nil {
+		return result, err
+	}
+	for _, migration := range plan.Pending {
+		if err := applyMigration(ctx, conn, migration); err != nil {
+			return result, err
+		}
+		result.Applied = append(result.Applied, migration)
+	}
+	return result, nil
+}
+
+// lockMigrations takes the migration lock on conn, trying until wait has
+// passed. It reports whether it took the lock and whether another
+// instance held it at first.
+func lockMigrations(ctx context.Context, conn *sql.Conn, wait time.Duration) (locked, contended bool, err error) {
+	deadline := time.Now().Add(wait)
+	for {
+		if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, migrationLockKey).Scan(&locked); err != nil {
+			return false, contended, fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		if locked || !time.Now().Before(deadline) {
+			return locked, contended, nil
+		}
+		contended = true
+		select {
+		case <-ctx.Done():
+			return false, contended, ctx.Err()
+		case <-time.After(min(migrationLockPoll, time.Until(deadline))):
+		}
+	}
+}
+
+// ensureVersionTable creates goose's version table as goose does, with
+// the row for version 0 it starts from
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	var table sql.NullString
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass('goose_db_version')::text`).Scan(&table); err != nil {
+		return err
+	}
+	if table.Valid {
+		return nil
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE goose_db_version (
+		id serial NOT NULL,
+		version_id bigint NOT NULL,
+		is_applied boolean NOT NULL,
+		tstamp timestamp NULL DEFAULT now(),
+		PRIMARY KEY (id)
+	)`); err != nil {
+		return fmt.Errorf("failed to create goose_db_version: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, true)`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// applyMigration runs a migration's Up section and records it, together
+func applyMigration(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// Without arguments the section is sent as one simple query, so it
+	// may hold several statements
+	if _, err := tx.ExecContext(ctx, migration.up); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)`, migration.Version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration.Name, err)
+	}
+	return tx.Commit()
+}
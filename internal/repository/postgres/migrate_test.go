@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+func TestMigrateConcurrently(t *testing.T) {
+	db, _, terminate := startTestContainer(t)
+	defer terminate()
+	migrations, err := LoadMigrations(filepath.Join("..", "..", "..", "scripts", "migrations"))
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Two instances starting together
+	var wg sync.WaitGroup
+	results := make([]MigrateResult, 2)
+	errs := make([]error, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = Migrate(context.Background(), db, migrations, time.Minute)
+		}()
+	}
+	wg.Wait()
+
+	applied := 0
+	for i, result := range results {
+		if errs[i] != nil {
+			t.Fatalf("Migrator %d failed: %v", i, errs[i])
+		}
+		if !result.Locked {
+			t.Errorf("Expected migrator %d to take the lock once it was free, got %+v", i, result)
+		}
+		switch len(result.Applied) {
+		case len(migrations):
+			applied++
+		case 0:
+		default:
+			t.Errorf("Expected migrator %d to apply all migrations or none, got %d", i, len(result.Applied))
+		}
+	}
+	if applied != 1 {
+		t.Fatalf("Expected exactly one migrator to apply the migrations, got %d", applied)
+	}
+
+	// Both end up with a working schema that goose sees as current
+	plan, err := PlanMigrations(context.Background(), db, migrations)
+	if err != nil || plan.Applied != len(migrations) || len(plan.Pending) != 0 {
+		t.Errorf("Expected every migration applied, got %+v (%v)", plan, err)
+	}
+	location, _ := domain.NewLocation("Ikeja", 6.6018, 3.3515)
+	if err := NewPostgresLocationRepository(db).Save(location); err != nil {
+		t.Errorf("Failed to save on the migrated schema: %v", err)
+	}
+
+	// While another instance holds the lock, a migrator that gives up
+	// waiting starts only when nothing is pending
+	holder, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Close()
+	if _, err := holder.ExecContext(context.Background(), `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Migrate(context.Background(), db, migrations, 100*time.Millisecond)
+	if err != nil || result.Locked || !result.Contended {
+		t.Errorf("Expected to proceed without the lock on an up-to-date schema, got %+v (%v)", result, err)
+	}
+	pending := append(migrations, Migration{Version: 99990101000000, Name: "99990101000000_later.sql", up: "SELECT 1"})
+	if _, err := Migrate(context.Background(), db, pending, 100*time.Millisecond); !errors.Is(err, ErrMigrationLockTimeout) {
+		t.Errorf("Expected ErrMigrationLockTimeout with a migration pending, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestMigrateMemoryToPostgres(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	created := time.Date(2025, 3, 1, 9, 30, 0, 123456000, time.UTC)
+	seed := []domain.Location{
+		{ID: "3", Name: "Yaba", Latitude: 6.5095, Longitude: 3.3711, CreatedAt: created, Region: "lagos", Aliases: []string{"Sabo", "Tejuosho"}},
+		{ID: "7", Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515, CreatedAt: created.Add(time.Hour), Description: "Behind the mall"},
+		{ID: "8", Name: "Lekki", Latitude: 6.4474, Longitude: 3.472, CreatedAt: created.Add(2 * time.Hour),
+			OpeningHours: &domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{"monday": {{Open: "06:00", Close: "22:00"}}}}},
+		{ID: "12", Name: "Kano", Latitude: 12.0022, Longitude: 8.592, CreatedAt: created.Add(3 * time.Hour), Region: "kano"},
+		{ID: "20", Name: "Abuja", Latitude: 9.0579, Longitude: 7.4951, CreatedAt: created.Add(4 * time.Hour)},
+	}
+	source := memory.NewInMemoryLocationRepository()
+	if _, err := source.RestoreLocations(seed); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	dest := NewPostgresLocationRepository(db)
+
+	result, err := service.NewStorageMigrator(source, dest).Migrate(context.Background(),
+		domain.MigrationOptions{PreserveIDs: true, BatchSize: 2, SpotChecks: 5})
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v (%+v)", err, result)
+	}
+	if result.Migrated != 5 || result.SpotChecked != 5 || len(result.Mismatches) != 0 {
+		t.Errorf("Expected 5 locations migrated and verified, got %+v", result)
+	}
+
+	for _, want := range seed {
+		got, err := dest.FindByID(want.ID)
+		if err != nil {
+			t.Fatalf("Failed to find %s: %v", want.ID, err)
+		}
+		if got.ID != want.ID || got.Name != want.Name || got.Latitude != want.Latitude || got.Longitude != want.Longitude ||
+			!got.CreatedAt.Equal(want.CreatedAt) || got.Description != want.Description || got.Region != want.Region ||
+			!reflect.DeepEqual(got.Aliases, want.Aliases) || !reflect.DeepEqual(got.OpeningHours, want.OpeningHours) || len(got.Attachments) != 0 {
+			t.Errorf("Expected %+v, got %+v", want, *got)
+		}
+	}
+
+	// The id sequence continues after the migrated IDs
+	ajah, _ := domain.NewLocation("Ajah", 6.4698, 3.5852)
+	if err := dest.Save(ajah); err != nil || ajah.ID != "21" {
+		t.Errorf("Expected the next ID 21, got %q (%v)", ajah.ID, err)
+	}
+
+	// Streaming the destination back yields the same locations, aliases
+	// included
+	again, err := service.NewStorageMigrator(dest, memory.NewInMemoryLocationRepository()).Migrate(context.Background(),
+		domain.MigrationOptions{PreserveIDs: true, SpotChecks: 6})
+	if err != nil || again.Migrated != 6 {
+		t.Errorf("Expected all 6 locations copied back, got %+v (%v)", again, err)
+	}
+}
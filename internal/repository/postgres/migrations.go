@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrPostGISMissing is returned when the database lacks the PostGIS extension
+var ErrPostGISMissing = errors.New("postgis extension is not installed")
+
+// Migration is a goose SQL migration file
+type Migration struct {
+	Version int64
+	Name    string
+	// up is the SQL of the -- +goose Up section
+	up string
+}
+
+// LoadMigrations lists the goose migrations in dir by version. It fails on
+// a file goose could not run: one without a numeric version prefix, with a
+// version already used, or without a "-- +goose Up" section.
+func LoadMigrations(dir string) ([]Migration, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no migrations in %s", dir)
+	}
+
+	seen := map[int64]string{}
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		name := filepath.Base(file)
+		prefix, _, ok := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s has no version prefix", name)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		seen[version] = name
+
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		_, up, ok := strings.Cut(string(raw), "-- +goose Up")
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no -- +goose Up section", name)
+		}
+		up, _, _ = strings.Cut(up, "-- +goose Down")
+		migrations = append(migrations, Migration{Version: version, Name: name, up: up})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// MigrationPlan compares migrations with those goose has applied
+type MigrationPlan struct {
+	Applied int
+	// Pending are the migrations goose up would apply, in order
+	Pending []Migration
+	// Unknown are applied versions missing from the migrations, as after
+	// rolling back to an older release
+	Unknown []int64
+}
+
+// PlanMigrations reports what goose up would do to db without changing it.
+// A database goose has never touched has every migration pending.
+func PlanMigrations(ctx context.Context, db *sql.DB, migrations []Migration) (MigrationPlan, error) {
+	return planMigrations(ctx, db, migrations)
+}
+
+// contextQuerier is a *sql.DB, or a *sql.Conn when statements must share
+// a session
+type contextQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func planMigrations(ctx context.Context, db contextQuerier, migrations []Migration) (MigrationPlan, error) {
+	var table sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('goose_db_version')::text`).Scan(&table); err != nil {
+		return MigrationPlan{}, err
+	}
+	applied := map[int64]bool{}
+	if table.Valid {
+		// Rows are appended as migrations go up and down; the last row for
+		// a version says where it stands
+		rows, err := db.QueryContext(ctx, `SELECT version_id, is_applied FROM goose_db_version ORDER BY id`)
+		if err != nil {
+			return MigrationPlan{}, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int64
+			var isApplied bool
+			if err := rows.Scan(&version, &isApplied); err != nil {
+				return MigrationPlan{}, err
+			}
+			applied[version] = isApplied
+		}
+		if err := rows.Err(); err != nil {
+			return MigrationPlan{}, err
+		}
+	}
+	// goose records version 0 when it creates its table
+	delete(applied, 0)
+
+	var plan MigrationPlan
+	known := map[int64]bool{}
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		if applied[migration.Version] {
+			plan.Applied++
+		} else {
+			plan.Pending = append(plan.Pending, migration)
+		}
+	}
+	for version, isApplied := range applied {
+		if isApplied && !known[version] {
+			plan.Unknown = append(plan.Unknown, version)
+		}
+	}
+	sort.Slice(plan.Unknown, func(i, j int) bool { return plan.Unknown[i] < plan.Unknown[j] })
+	return plan, nil
+}
+
+// PostGISVersion returns the installed PostGIS extension version, or
+// ErrPostGISMissing
+func PostGISVersion(ctx context.Context, db *sql.DB) (string, error) {
+	var version string
+	err := db.QueryRowContext(ctx, `SELECT extversion FROM pg_extension WHERE extname = 'postgis'`).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrPostGISMissing
+	}
+	return version, err
+}
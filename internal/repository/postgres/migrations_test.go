@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(filepath.Join("..", "..", "..", "scripts", "migrations"))
+	if err != nil {
+		t.Fatalf("Failed to load the shipped migrations: %v", err)
+	}
+	if migrations[0].Name != "20250728210121_initial_schema.sql" {
+		t.Errorf("Expected the initial schema first, got %+v", migrations[0])
+	}
+	if up := migrations[0].up; !strings.Contains(up, "CREATE TABLE") || strings.Contains(up, "DROP TABLE") {
+		t.Errorf("Expected only the Up section to be kept, got %q", up)
+	}
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			t.Errorf("Expected migrations in version order, got %d after %d", migrations[i].Version, migrations[i-1].Version)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"no version", map[string]string{"initial.sql": "-- +goose Up"}, "no version prefix"},
+		{"shared version", map[string]string{"1_a.sql": "-- +goose Up", "1_b.sql": "-- +goose Up"}, "share version 1"},
+		{"no up section", map[string]string{"1_a.sql": "CREATE TABLE a ()"}, "no -- +goose Up section"},
+		{"empty", map[string]string{}, "no migrations"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for name, content := range tt.files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := LoadMigrations(dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
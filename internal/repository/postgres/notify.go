@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// LocationChangesChannel carries the name of every saved or deleted location
+const LocationChangesChannel = "location_changes"
+
+// LocationStockChannel carries the name of every location whose stock
+// changed, and nothing else about it did
+const LocationStockChannel = "location_stock_changes"
+
+// notifyChange queues a notification that Postgres delivers only if the
+// transaction commits
+func notifyChange(tx *sql.Tx, name string) error {
+	_, err := tx.Exec(`SELECT pg_notify($1, $2)`, LocationChangesChannel, name)
+	return err
+}
+
+// notifyStockChange is notifyChange for an update to stock alone
+func notifyStockChange(tx *sql.Tx, name string) error {
+	_, err := tx.Exec(`SELECT pg_notify($1, $2)`, LocationStockChannel, name)
+	return err
+}
+
+// ChangeListener receives location change notifications from other replicas
+type ChangeListener struct {
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+// ListenForChanges calls onChange with the name of each location changed by
+// any replica, or onStockChange when only its stock changed. Notifications
+// sent while the connection is down are lost, so onReset is called on every
+// disconnect and reconnect; callers should drop anything that could have
+// been invalidated in the meantime.
+func ListenForChanges(dsn string, onChange, onStockChange func(name string), onReset func()) (*ChangeListener, error) {
+	listener := pq.NewListener(dsn, time.Second, 30*time.Second, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			log.Printf("Change listener disconnected: %v", err)
+			onReset()
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Printf("Change listener reconnect failed: %v", err)
+		case pq.ListenerEventReconnected:
+			log.Printf("Change listener reconnected")
+		}
+	})
+
+	for _, channel := range []string{LocationChangesChannel, LocationStockChannel} {
+		if err := listener.Listen(channel); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+
+	l := &ChangeListener{listener: listener, done: make(chan struct{})}
+	go l.run(onChange, onStockChange, onReset)
+	return l, nil
+}
+
+func (l *ChangeListener) run(onChange, onStockChange func(name string), onReset func()) {
+	defer close(l.done)
+	for {
+		select {
+		case n, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+			// A nil notification follows a reconnect
+			if n == nil {
+				onReset()
+				continue
+			}
+			if n.Channel == LocationStockChannel {
+				onStockChange(n.Extra)
+				continue
+			}
+			onChange(n.Extra)
+		case <-time.After(90 * time.Second):
+			// Detect dead connections that never reported an error
+			go l.listener.Ping()
+		}
+	}
+}
+
+// Close stops listening and waits for the delivery loop to exit
+func (l *ChangeListener) Close() error {
+	err := l.listener.Close()
+	<-l.done
+	return err
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+)
+
+// replica is one API instance: its own repository, cache and listener
+type replica struct {
+	repo     *cache.CachedLocationRepository
+	listener *ChangeListener
+}
+
+func newReplica(t *testing.T, dsn string) *replica {
+	t.Helper()
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect replica: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := cache.NewCachedLocationRepository(NewPostgresLocationRepository(db), time.Hour)
+	listener, err := ListenForChanges(dsn, repo.Invalidate, repo.InvalidateEntry, repo.Flush)
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	return &replica{repo: repo, listener: listener}
+}
+
+func TestChangeListener_InvalidatesAcrossReplicas(t *testing.T) {
+	_, dsn, cleanup := setupTestContainerWithDSN(t)
+	defer cleanup()
+
+	a := newReplica(t, dsn)
+	b := newReplica(t, dsn)
+
+	location, _ := domain.NewLocation("Shared Station", 6.5244, 3.3792)
+	if err := a.repo.Save(location); err != nil {
+		t.Fatalf("Failed to save location: %v", err)
+	}
+
+	// Replica B caches the station, and an empty list before that
+	if _, err := b.repo.FindByName("Shared Station"); err != nil {
+		t.Fatalf("Expected replica B to find the station: %v", err)
+	}
+	if all, _ := b.repo.FindAll(); len(all) != 1 {
+		t.Fatalf("Expected 1 location, got %d", len(all))
+	}
+	version := b.repo.Version()
+
+	if err := a.repo.Delete("Shared Station"); err != nil {
+		t.Fatalf("Failed to delete location: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, err := b.repo.FindByName("Shared Station")
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Replica B still served the deleted station after 2s")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if all, _ := b.repo.FindAll(); len(all) != 0 {
+		t.Errorf("Expected replica B's list to be invalidated, got %d locations", len(all))
+	}
+	if b.repo.Version() == version {
+		t.Error("Expected replica B's dataset version to change")
+	}
+}
+
+func TestChangeListener_RolledBackWritesDoNotNotify(t *testing.T) {
+	db, dsn, cleanup := setupTestContainerWithDSN(t)
+	defer cleanup()
+
+	b := newReplica(t, dsn)
+	version := b.repo.Version()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin: %v", err)
+	}
+	if err := notifyChange(tx, "Ghost Station"); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	tx.Rollback()
+
+	time.Sleep(200 * time.Millisecond)
+	if b.repo.Version() != version {
+		t.Error("Expected no invalidation from a rolled back transaction")
+	}
+}
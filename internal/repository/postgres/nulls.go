@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// nullableTime reads created_at, which legacy imports left NULL before the
+// column was constrained
+type nullableTime struct {
+	time *time.Time
+}
+
+// Scan implements sql.Scanner; NULL leaves the time zero
+func (n nullableTime) Scan(src any) error {
+	var value sql.NullTime
+	if err := value.Scan(src); err != nil {
+		return err
+	}
+	*n.time = value.Time
+	return nil
+}
+
+// logDefaulted reports the locations read with a NULL created_at, which
+// are returned with a zero creation time rather than failing the read
+func logDefaulted(locations []*domain.Location) {
+	defaulted := 0
+	for _, location := range locations {
+		if location.CreatedAt.IsZero() {
+			defaulted++
+		}
+	}
+	if defaulted > 0 {
+		log.Printf("Read %d locations without a creation time; the integrity check lists them", defaulted)
+	}
+}
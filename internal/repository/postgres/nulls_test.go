@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+func TestPostgresReadsNullCreatedAt(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	repo := NewPostgresLocationRepository(db)
+
+	for _, name := range []string{"Ikeja", "Yaba"} {
+		location, _ := domain.NewLocation(name, 6.55, 3.36)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+
+	// New rows cannot leave created_at NULL
+	const legacyInsert = `INSERT INTO locations (name, latitude, longitude, created_at) VALUES ('Legacy', 6.5, 3.3, NULL)`
+	if _, err := db.Exec(legacyInsert); err == nil {
+		t.Fatal("Expected the constraint to reject a NULL created_at")
+	}
+
+	// Rows imported before the constraint are still read
+	if _, err := db.Exec(`ALTER TABLE locations DROP CONSTRAINT locations_created_at_not_null`); err != nil {
+		t.Fatalf("Failed to drop the constraint: %v", err)
+	}
+	if _, err := db.Exec(legacyInsert); err != nil {
+		t.Fatalf("Failed to insert the legacy row: %v", err)
+	}
+
+	locations, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("Expected the listing to survive the legacy row, got %v", err)
+	}
+	var names []string
+	for _, location := range locations {
+		names = append(names, location.Name)
+		if location.CreatedAt.IsZero() != (location.Name == "Legacy") {
+			t.Errorf("Expected only Legacy without a creation time, got %+v", location)
+		}
+	}
+	if want := []string{"Ikeja", "Yaba", "Legacy"}; !slices.Equal(names, want) {
+		t.Errorf("Expected %q, got %q", want, names)
+	}
+
+	if _, err := repo.FindByName("Legacy"); err != nil {
+		t.Errorf("Failed to find the legacy row: %v", err)
+	}
+	if _, _, err := repo.FindNearest(6.5, 3.3); err != nil {
+		t.Errorf("Failed to search near the legacy row: %v", err)
+	}
+
+	var missing []string
+	for location, err := range repo.StreamLocations(context.Background(), 2) {
+		if err != nil {
+			t.Fatalf("Failed to stream: %v", err)
+		}
+		if slices.Contains(domain.IntegrityProblems(location), domain.IntegrityMissingCreatedAt) {
+			missing = append(missing, location.Name)
+		}
+	}
+	if !slices.Equal(missing, []string{"Legacy"}) {
+		t.Errorf("Expected the integrity check to flag Legacy, got %q", missing)
+	}
+
+	if err := repo.Delete("Legacy"); err != nil {
+		t.Errorf("Failed to delete the legacy row: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// outboxRetryBase is the delay before the first retry; it doubles per attempt
+const outboxRetryBase = time.Second
+
+// outboxRetryMax caps the retry delay
+const outboxRetryMax = 5 * time.Minute
+
+type PostgresOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresOutboxRepository(db *sql.DB) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+// insertOutboxEvent records an event inside the caller's transaction,
+// numbering it after the last event of its location. The sequence row is
+// locked until the transaction ends, so concurrent changes to a location
+// are numbered in the order they commit.
+func insertOutboxEvent(tx *sql.Tx, event domain.Event) error {
+	err := tx.QueryRow(`INSERT INTO location_event_sequences (location_id, last_sequence)
+			 VALUES ($1, 1)
+			 ON CONFLICT (location_id) DO UPDATE SET last_sequence = location_event_sequences.last_sequence + 1
+			 RETURNING last_sequence`, event.Location.ID).Scan(&event.Sequence)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO outbox_events (event_id, event_type, payload, created_at)
+			 VALUES ($1, $2, $3, $4)`,
+		event.ID, string(event.Type), payload, event.OccurredAt)
+	return err
+}
+
+func (r *PostgresOutboxRepository) DispatchPending(limit, maxAttempts int, publish func(domain.Event) error) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets several replicas dispatch concurrently without
+	// delivering the same row twice
+	rows, err := tx.Query(`SELECT id, payload, attempts
+			 FROM outbox_events
+			 WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+			 ORDER BY id
+			 LIMIT $1
+			 FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	type due struct {
+		id       int64
+		event    domain.Event
+		attempts int
+	}
+	var batch []due
+	for rows.Next() {
+		var d due
+		var payload []byte
+		if err := rows.Scan(&d.id, &payload, &d.attempts); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := json.Unmarshal(payload, &d.event); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, d := range batch {
+		if pubErr := publish(d.event); pubErr != nil {
+			attempts := d.attempts + 1
+			if attempts >= maxAttempts {
+				_, err = tx.Exec(`UPDATE outbox_events
+						 SET attempts = $2, last_error = $3, failed_at = NOW()
+						 WHERE id = $1`, d.id, attempts, pubErr.Error())
+			} else {
+				_, err = tx.Exec(`UPDATE outbox_events
+						 SET attempts = $2, last_error = $3, next_attempt_at = NOW() + $4 * INTERVAL '1 millisecond'
+						 WHERE id = $1`, d.id, attempts, pubErr.Error(), outboxBackoff(attempts).Milliseconds())
+			}
+			if err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if _, err := tx.Exec(`UPDATE outbox_events
+				 SET attempts = attempts + 1, delivered_at = NOW(), last_error = NULL
+				 WHERE id = $1`, d.id); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return delivered, nil
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxRetryBase << (attempts - 1)
+	if delay <= 0 || delay > outboxRetryMax {
+		return outboxRetryMax
+	}
+	return delay
+}
+
+func (r *PostgresOutboxRepository) ListOutboxEvents(status domain.OutboxStatus, limit int) ([]domain.OutboxEvent, error) {
+	var condition string
+	switch status {
+	case domain.OutboxDelivered:
+		condition = "delivered_at IS NOT NULL"
+	case domain.OutboxFailed:
+		condition = "failed_at IS NOT NULL"
+	default:
+		condition = "delivered_at IS NULL AND failed_at IS NULL"
+	}
+
+	rows, err := r.db.Query(`SELECT id, payload, attempts, COALESCE(last_error, ''), next_attempt_at, delivered_at, failed_at
+			 FROM outbox_events
+			 WHERE `+condition+`
+			 ORDER BY id
+			 LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.OutboxEvent{}
+	for rows.Next() {
+		var e domain.OutboxEvent
+		var payload []byte
+		var deliveredAt, failedAt sql.NullTime
+		if err := rows.Scan(&e.Sequence, &payload, &e.Attempts, &e.LastError, &e.NextAttemptAt, &deliveredAt, &failedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &e.Event); err != nil {
+			return nil, err
+		}
+
+		e.Status = domain.OutboxPending
+		if deliveredAt.Valid {
+			e.Status = domain.OutboxDelivered
+			e.DeliveredAt = &deliveredAt.Time
+		} else if failedAt.Valid {
+			e.Status = domain.OutboxFailed
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *PostgresOutboxRepository) RequeueOutboxEvent(sequence int64) error {
+	result, err := r.db.Exec(`UPDATE outbox_events
+			 SET failed_at = NULL, attempts = 0, next_attempt_at = NOW()
+			 WHERE id = $1 AND delivered_at IS NULL`, sequence)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrOutboxEventNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresOutboxRepository) OutboxStats() (domain.OutboxStats, error) {
+	var stats domain.OutboxStats
+	var oldestSeconds float64
+	err := r.db.QueryRow(`SELECT
+				 COUNT(*) FILTER (WHERE delivered_at IS NULL AND failed_at IS NULL),
+				 COUNT(*) FILTER (WHERE failed_at IS NOT NULL),
+				 COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(created_at) FILTER (WHERE delivered_at IS NULL AND failed_at IS NULL)), 0)
+			 FROM outbox_events`).Scan(&stats.Pending, &stats.Failed, &oldestSeconds)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.OldestPending = time.Duration(oldestSeconds * float64(time.Second))
+	return stats, nil
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+func TestPostgresOutbox_WritesEventsWithMutations(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	repo := NewPostgresLocationRepository(db)
+	outbox := NewPostgresOutboxRepository(db)
+
+	location, _ := domain.NewLocation("Outbox Station", 6.5244, 3.3792)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save location: %v", err)
+	}
+	if err := repo.Delete("Outbox Station"); err != nil {
+		t.Fatalf("Failed to delete location: %v", err)
+	}
+
+	events, err := outbox.ListOutboxEvents(domain.OutboxPending, 10)
+	if err != nil {
+		t.Fatalf("Failed to list outbox: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 pending events, got %d", len(events))
+	}
+	if events[0].Event.Type != domain.EventLocationCreated || events[1].Event.Type != domain.EventLocationDeleted {
+		t.Errorf("Expected created then deleted events, got %s and %s", events[0].Event.Type, events[1].Event.Type)
+	}
+	if events[0].Event.Location.ID != location.ID {
+		t.Errorf("Expected event to carry location ID %s, got %s", location.ID, events[0].Event.Location.ID)
+	}
+	if events[0].Event.Sequence != 1 || events[1].Event.Sequence != 2 {
+		t.Errorf("Expected sequences 1 and 2, got %d and %d", events[0].Event.Sequence, events[1].Event.Sequence)
+	}
+}
+
+func TestPostgresOutbox_WritesUpdateEvents(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	repo := NewPostgresLocationRepository(db)
+	outbox := NewPostgresOutboxRepository(db)
+
+	location, _ := domain.NewLocation("Yaba", 6.5095, 3.3711)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save location: %v", err)
+	}
+	if _, err := repo.AddAlias("Yaba", "Tejuosho"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	if _, err := repo.RemoveAlias("Yaba", "Tejuosho"); err != nil {
+		t.Fatalf("Failed to remove alias: %v", err)
+	}
+	capacity := 33000.0
+	if _, err := repo.UpdateStock("Yaba", domain.StockUpdate{CapacityLitres: &capacity}); err != nil {
+		t.Fatalf("Failed to update stock: %v", err)
+	}
+	renamed := "Yaba Depot"
+	if _, err := repo.ApplyOperations([]domain.LocationOperation{{Type: domain.OperationUpdate, Name: "Yaba", Changes: domain.LocationChanges{Name: &renamed}}}); err != nil {
+		t.Fatalf("Failed to apply update: %v", err)
+	}
+	if err := repo.RenameLocation(location.ID, "Yaba"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+
+	events, err := outbox.ListOutboxEvents(domain.OutboxPending, 10)
+	if err != nil {
+		t.Fatalf("Failed to list outbox: %v", err)
+	}
+	if len(events) != 6 || events[0].Event.Type != domain.EventLocationCreated {
+		t.Fatalf("Expected a created event and 5 updates, got %+v", events)
+	}
+	for i, event := range events {
+		if i > 0 && (event.Event.Type != domain.EventLocationUpdated || event.Event.Location.ID != location.ID) {
+			t.Errorf("Expected event %d to update location %s, got %+v", i, location.ID, event.Event)
+		}
+		if event.Event.Sequence != int64(i+1) {
+			t.Errorf("Expected event %d to have sequence %d, got %d", i, i+1, event.Event.Sequence)
+		}
+	}
+	if got := events[4].Event.Location.Name; got != renamed {
+		t.Errorf("Expected the transaction's event to carry the new name, got %s", got)
+	}
+}
+
+func TestPostgresOutbox_DeliveredExactlyOnceAfterDispatcherCrash(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	repo := NewPostgresLocationRepository(db)
+	outbox := NewPostgresOutboxRepository(db)
+
+	location, _ := domain.NewLocation("Crash Station", 6.5244, 3.3792)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save location: %v", err)
+	}
+
+	// The dispatcher dies mid-delivery: its transaction never commits
+	func() {
+		defer func() { recover() }()
+		outbox.DispatchPending(10, 5, func(domain.Event) error {
+			panic("dispatcher killed")
+		})
+	}()
+
+	delivered := map[string]int{}
+	publish := func(e domain.Event) error {
+		delivered[e.ID]++
+		return nil
+	}
+
+	// A restarted dispatcher picks the event up
+	n, err := outbox.DispatchPending(10, 5, publish)
+	if err != nil {
+		t.Fatalf("Failed to dispatch: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected 1 delivered event, got %d", n)
+	}
+
+	// And never delivers it again
+	if n, _ := outbox.DispatchPending(10, 5, publish); n != 0 {
+		t.Errorf("Expected no further deliveries, got %d", n)
+	}
+	for id, count := range delivered {
+		if count != 1 {
+			t.Errorf("Expected event %s delivered once, got %d", id, count)
+		}
+	}
+
+	stats, _ := outbox.OutboxStats()
+	if stats.Pending != 0 {
+		t.Errorf("Expected no pending events, got %d", stats.Pending)
+	}
+}
+
+func TestPostgresOutbox_RetryAndRequeue(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	repo := NewPostgresLocationRepository(db)
+	outbox := NewPostgresOutboxRepository(db)
+
+	location, _ := domain.NewLocation("Retry Station", 6.5244, 3.3792)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save location: %v", err)
+	}
+
+	failing := func(domain.Event) error { return errors.New("webhook unavailable") }
+
+	// With a single attempt allowed the event is marked failed immediately
+	if _, err := outbox.DispatchPending(10, 1, failing); err != nil {
+		t.Fatalf("Failed to dispatch: %v", err)
+	}
+
+	failed, _ := outbox.ListOutboxEvents(domain.OutboxFailed, 10)
+	if len(failed) != 1 || failed[0].LastError != "webhook unavailable" || failed[0].Attempts != 1 {
+		t.Fatalf("Expected one failed event with bookkeeping, got %+v", failed)
+	}
+
+	if err := outbox.RequeueOutboxEvent(failed[0].Sequence); err != nil {
+		t.Fatalf("Failed to requeue: %v", err)
+	}
+
+	n, err := outbox.DispatchPending(10, 1, func(domain.Event) error { return nil })
+	if err != nil || n != 1 {
+		t.Errorf("Expected requeued event to be delivered, got %d (%v)", n, err)
+	}
+
+	if err := outbox.RequeueOutboxEvent(failed[0].Sequence); !errors.Is(err, domain.ErrOutboxEventNotFound) {
+		t.Errorf("Expected delivered event to be ineligible for requeue, got %v", err)
+	}
+}
+
+func TestPostgresOutbox_WritesTruncateEvents(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	repo := NewPostgresLocationRepository(db)
+	outbox := NewPostgresOutboxRepository(db)
+
+	for _, name := range []string{"Ikeja", "Yaba"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.4)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+	if _, err := repo.TruncateLocations("qa"); err != nil {
+		t.Fatalf("Failed to truncate: %v", err)
+	}
+
+	events, err := outbox.ListOutboxEvents(domain.OutboxPending, 10)
+	if err != nil {
+		t.Fatalf("Failed to list outbox: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Expected 2 created and 2 deleted events, got %d", len(events))
+	}
+	for i, name := range []string{"Ikeja", "Yaba"} {
+		event := events[i+2].Event
+		if event.Type != domain.EventLocationDeleted || event.Location.Name != name || event.Sequence != 2 {
+			t.Errorf("Expected the second event of %s to delete it, got %+v", name, event)
+		}
+	}
+}
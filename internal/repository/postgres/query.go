@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// sortColumns whitelists the columns a listing can be ordered by
+var sortColumns = map[domain.SortField]string{
+	domain.SortByID:        "id",
+	domain.SortByName:      "name",
+	domain.SortByCreatedAt: "created_at",
+}
+
+// likeEscaper escapes the LIKE wildcards so a name filter matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// findQuery accumulates the conditions and arguments of a location listing
+type findQuery struct {
+	conditions []string
+	args       []any
+}
+
+// arg adds a value and returns its placeholder
+func (q *findQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// buildFindQuery renders the SELECT for a filtered, sorted page of
+// locations. Only the conditions the filter sets are emitted, so the
+// planner sees plain predicates it can match to the created_at and name
+// indexes. A name sort uses nameCollation when it is set.
+func buildFindQuery(filter domain.LocationFilter, page domain.Page, order domain.LocationSort, nameCollation string) (string, []any) {
+	q := filterQuery(filter)
+
+	var sb strings.Builder
+	sb.WriteString("SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by FROM locations")
+	q.writeWhere(&sb)
+
+	column, ok := sortColumns[order.Field]
+	if !ok {
+		column = "id"
+	}
+	if column == "name" && nameCollation != "" {
+		column += " COLLATE " + pq.QuoteIdentifier(nameCollation)
+	}
+	direction := ""
+	if order.Descending {
+		direction = " DESC"
+	}
+	sb.WriteString(" ORDER BY " + column + direction)
+	if column != "id" {
+		sb.WriteString(", id" + direction)
+	}
+
+	if page.Limit > 0 {
+		sb.WriteString(" LIMIT " + q.arg(page.Limit))
+	}
+	if page.Offset > 0 {
+		sb.WriteString(" OFFSET " + q.arg(page.Offset))
+	}
+
+	return sb.String(), q.args
+}
+
+// buildCountQuery renders the SELECT counting the locations passing
+// filter, with the conditions buildFindQuery uses
+func buildCountQuery(filter domain.LocationFilter) (string, []any) {
+	q := filterQuery(filter)
+	var sb strings.Builder
+	sb.WriteString("SELECT COUNT(*) FROM locations")
+	q.writeWhere(&sb)
+	return sb.String(), q.args
+}
+
+// filterQuery turns the conditions the filter sets into placeholders and
+// arguments
+func filterQuery(filter domain.LocationFilter) *findQuery {
+	q := &findQuery{}
+
+	if filter.AfterID != "" {
+		q.conditions = append(q.conditions, "id > "+q.arg(filter.AfterID))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		q.conditions = append(q.conditions, "created_at >= "+q.arg(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		q.conditions = append(q.conditions, "created_at <= "+q.arg(filter.CreatedBefore))
+	}
+	if filter.NameContains != "" {
+		pattern := q.arg("%" + likeEscaper.Replace(filter.NameContains) + "%")
+		if filter.SearchDescriptions {
+			q.conditions = append(q.conditions, "(name ILIKE "+pattern+` ESCAPE '\' OR description ILIKE `+pattern+` ESCAPE '\')`)
+		} else {
+			q.conditions = append(q.conditions, "name ILIKE "+pattern+` ESCAPE '\'`)
+		}
+	}
+	if filter.Region != "" {
+		q.conditions = append(q.conditions, "region = "+q.arg(filter.Region))
+	}
+	if box := filter.BBox; box != nil {
+		q.conditions = append(q.conditions, fmt.Sprintf("latitude BETWEEN %s AND %s", q.arg(box.MinLatitude), q.arg(box.MaxLatitude)))
+		if box.CrossesAntimeridian() {
+			q.conditions = append(q.conditions, fmt.Sprintf("(longitude >= %s OR longitude <= %s)", q.arg(box.MinLongitude), q.arg(box.MaxLongitude)))
+		} else {
+			q.conditions = append(q.conditions, fmt.Sprintf("longitude BETWEEN %s AND %s", q.arg(box.MinLongitude), q.arg(box.MaxLongitude)))
+		}
+	}
+	return q
+}
+
+// writeWhere writes the WHERE clause of the conditions, if any
+func (q *findQuery) writeWhere(sb *strings.Builder) {
+	if len(q.conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(q.conditions, " AND "))
+	}
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type PostgresQueryRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresQueryRepository(db *sql.DB) *PostgresQueryRepository {
+	return &PostgresQueryRepository{db: db}
+}
+
+func (r *PostgresQueryRepository) CreateQuery(query *domain.SavedQuery) error {
+	_, err := r.db.Exec(`INSERT INTO saved_queries (name, filter, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4)`,
+		query.Name, []byte(query.Filter), query.CreatedAt, query.UpdatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return domain.ErrQueryExists
+	}
+	return err
+}
+
+func (r *PostgresQueryRepository) UpdateQuery(query *domain.SavedQuery) error {
+	err := r.db.QueryRow(`UPDATE saved_queries SET filter = $2, updated_at = $3
+			 WHERE name = $1
+			 RETURNING created_at`,
+		query.Name, []byte(query.Filter), query.UpdatedAt).Scan(&query.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrQueryNotFound
+	}
+	return err
+}
+
+func (r *PostgresQueryRepository) FindQuery(name string) (*domain.SavedQuery, error) {
+	var query domain.SavedQuery
+	err := r.db.QueryRow(`SELECT name, filter, created_at, updated_at FROM saved_queries WHERE name = $1`, name).
+		Scan(&query.Name, (*[]byte)(&query.Filter), &query.CreatedAt, &query.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrQueryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &query, nil
+}
+
+func (r *PostgresQueryRepository) ListQueries() ([]*domain.SavedQuery, error) {
+	rows, err := r.db.Query(`SELECT name, filter, created_at, updated_at FROM saved_queries ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queries := []*domain.SavedQuery{}
+	for rows.Next() {
+		var query domain.SavedQuery
+		if err := rows.Scan(&query.Name, (*[]byte)(&query.Filter), &query.CreatedAt, &query.UpdatedAt); err != nil {
+			return nil, err
+		}
+		queries = append(queries, &query)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+func (r *PostgresQueryRepository) DeleteQuery(name string) error {
+	result, err := r.db.Exec(`DELETE FROM saved_queries WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return err
+	} else if deleted == 0 {
+		return domain.ErrQueryNotFound
+	}
+	return nil
+}
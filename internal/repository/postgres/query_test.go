@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+func TestBuildFindQuery(t *testing.T) {
+	t.Parallel()
+	const selectAll = "SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by FROM locations"
+	after := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   domain.LocationFilter
+		page     domain.Page
+		order    domain.LocationSort
+		collate  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:    "zero",
+			wantSQL: selectAll + " ORDER BY id",
+		},
+		{
+			name:     "created and name",
+			filter:   domain.LocationFilter{CreatedAfter: after, NameContains: `50%_off\`},
+			wantSQL:  selectAll + ` WHERE created_at >= $1 AND name ILIKE $2 ESCAPE '\' ORDER BY id`,
+			wantArgs: []any{after, `%50\%\_off\\%`},
+		},
+		{
+			name:     "name or description",
+			filter:   domain.LocationFilter{NameContains: "road", SearchDescriptions: true},
+			wantSQL:  selectAll + ` WHERE (name ILIKE $1 ESCAPE '\' OR description ILIKE $1 ESCAPE '\') ORDER BY id`,
+			wantArgs: []any{"%road%"},
+		},
+		{
+			name:     "region and bounding box",
+			filter:   domain.LocationFilter{Region: "Lagos", BBox: &domain.BoundingBox{MinLatitude: 1, MinLongitude: 2, MaxLatitude: 3, MaxLongitude: 4}},
+			wantSQL:  selectAll + " WHERE region = $1 AND latitude BETWEEN $2 AND $3 AND longitude BETWEEN $4 AND $5 ORDER BY id",
+			wantArgs: []any{"Lagos", 1.0, 3.0, 2.0, 4.0},
+		},
+		{
+			name:     "after id",
+			filter:   domain.LocationFilter{AfterID: "42", Region: "Lagos"},
+			wantSQL:  selectAll + " WHERE id > $1 AND region = $2 ORDER BY id",
+			wantArgs: []any{"42", "Lagos"},
+		},
+		{
+			name:     "bounding box",
+			filter:   domain.LocationFilter{BBox: &domain.BoundingBox{MinLatitude: 1, MinLongitude: 2, MaxLatitude: 3, MaxLongitude: 4}},
+			wantSQL:  selectAll + " WHERE latitude BETWEEN $1 AND $2 AND longitude BETWEEN $3 AND $4 ORDER BY id",
+			wantArgs: []any{1.0, 3.0, 2.0, 4.0},
+		},
+		{
+			name:     "antimeridian bounding box",
+			filter:   domain.LocationFilter{BBox: &domain.BoundingBox{MinLatitude: -20, MinLongitude: 170, MaxLatitude: -10, MaxLongitude: -170}},
+			wantSQL:  selectAll + " WHERE latitude BETWEEN $1 AND $2 AND (longitude >= $3 OR longitude <= $4) ORDER BY id",
+			wantArgs: []any{-20.0, -10.0, 170.0, -170.0},
+		},
+		{
+			name:     "sorted page",
+			page:     domain.Page{Offset: 20, Limit: 10},
+			order:    domain.LocationSort{Field: domain.SortByName, Descending: true},
+			wantSQL:  selectAll + " ORDER BY name DESC, id DESC LIMIT $1 OFFSET $2",
+			wantArgs: []any{10, 20},
+		},
+		{
+			name:    "collated name",
+			order:   domain.LocationSort{Field: domain.SortByName},
+			collate: "yo-x-icu",
+			wantSQL: selectAll + ` ORDER BY name COLLATE "yo-x-icu", id`,
+		},
+		{
+			name:    "collation ignored for other fields",
+			order:   domain.LocationSort{Field: domain.SortByCreatedAt},
+			collate: "C",
+			wantSQL: selectAll + " ORDER BY created_at, id",
+		},
+		{
+			name:    "unknown sort field",
+			order:   domain.LocationSort{Field: "latitude; DROP TABLE locations"},
+			wantSQL: selectAll + " ORDER BY id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sql, args := buildFindQuery(tt.filter, tt.page, tt.order, tt.collate)
+			if sql != tt.wantSQL {
+				t.Errorf("Expected\n  %s\ngot\n  %s", tt.wantSQL, sql)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Expected args %v, got %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestBuildCountQuery(t *testing.T) {
+	t.Parallel()
+	sql, args := buildCountQuery(domain.LocationFilter{})
+	if sql != "SELECT COUNT(*) FROM locations" || args != nil {
+		t.Errorf("Expected an unfiltered count, got %s %v", sql, args)
+	}
+
+	sql, args = buildCountQuery(domain.LocationFilter{AfterID: "42", NameContains: "road"})
+	want := `SELECT COUNT(*) FROM locations WHERE id > $1 AND name ILIKE $2 ESCAPE '\'`
+	if sql != want || !reflect.DeepEqual(args, []any{"42", "%road%"}) {
+		t.Errorf("Expected\n  %s [42 %%road%%]\ngot\n  %s %v", want, sql, args)
+	}
+}
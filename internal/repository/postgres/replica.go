@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+// readReplica is a read replica the repository sends reads to while it is
+// reachable and no further behind than maxLag. Reads go to the primary
+// instead until the replica has replayed this process's last write, so a
+// caller reading back what it just wrote sees it. The repository takes no
+// request context, so the pinning covers every read in the process rather
+// than one request's: after a write, reads go to the primary until the
+// next check sees the replica caught up.
+type readReplica struct {
+	db     *sql.DB
+	maxLag time.Duration
+
+	// usable is cleared when a check finds the replica unreachable or
+	// lagging, or a read on it fails to connect, and set again by a check
+	usable atomic.Bool
+	// replayed is the WAL position the replica had replayed at the last
+	// check, and written the primary's position after this process's last
+	// write
+	replayed atomic.Uint64
+	written  atomic.Uint64
+
+	// mu serializes checks, so state changes are logged once
+	mu  sync.Mutex
+	lag time.Duration
+}
+
+// WithReplica sends reads to replica while it is at most maxLag behind the
+// primary; CheckReplica measures that, so until the first check reads go
+// to the primary. Writes always go to the primary.
+func WithReplica(replica *sql.DB, maxLag time.Duration) Option {
+	return func(r *PostgresLocationRepository) {
+		r.replica = &readReplica{db: replica, maxLag: maxLag}
+	}
+}
+
+// reader returns the pool to read from: the replica when it is usable
+// and has replayed this process's writes, and the primary otherwise
+func (r *PostgresLocationRepository) reader() *sql.DB {
+	if rep := r.replica; rep != nil && rep.usable.Load() && rep.replayed.Load() >= rep.written.Load() {
+		return rep.db
+	}
+	return r.db
+}
+
+// read runs f against the pool reader picks. When that is the replica and
+// f fails to reach it, the replica is marked unusable and f runs again on
+// the primary.
+func (r *PostgresLocationRepository) read(f func(q querier) error) error {
+	db := r.reader()
+	err := f(db)
+	if db == r.db || !isConnectionError(err) {
+		return err
+	}
+	r.replica.fail(err)
+	return f(r.db)
+}
+
+// wrote records the primary's WAL position after a committed write, so
+// reads stay on the primary until the replica has replayed it. Without a
+// replica it does nothing.
+func (r *PostgresLocationRepository) wrote() {
+	if r.replica == nil {
+		return
+	}
+	var position string
+	lsn := uint64(math.MaxUint64)
+	if err := r.db.QueryRow(`SELECT pg_current_wal_lsn()::text`).Scan(&position); err == nil {
+		if parsed, err := parseLSN(position); err == nil {
+			lsn = parsed
+		}
+	}
+	// Without the position, reads stay on the primary until the next
+	// check reads it
+	for {
+		written := r.replica.written.Load()
+		if written >= lsn || r.replica.written.CompareAndSwap(written, lsn) {
+			return
+		}
+	}
+}
+
+// CheckReplica measures how far the replica is behind the primary and
+// routes reads to it only while that is within the configured lag. It
+// returns an error when the replica could not be checked; reads then go
+// to the primary. Without a replica it does nothing.
+func (r *PostgresLocationRepository) CheckReplica(ctx context.Context) (domain.ReplicaStatus, error) {
+	rep := r.replica
+	if rep == nil {
+		return domain.ReplicaStatus{}, nil
+	}
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	var primary string
+	if err := r.db.QueryRowContext(ctx, `SELECT pg_current_wal_lsn()::text`).Scan(&primary); err != nil {
+		return rep.status(), fmt.Errorf("failed to read the primary's WAL position: %w", err)
+	}
+	// A replica with nothing left to replay is not behind however long ago
+	// its last transaction was
+	var replayed sql.NullString
+	var idle sql.NullFloat64
+	err := rep.db.QueryRowContext(ctx, `SELECT pg_last_wal_replay_lsn()::text,
+			EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())`).Scan(&replayed, &idle)
+	if err == nil && !replayed.Valid {
+		err = errors.New("the replica is not in recovery")
+	}
+	if err != nil {
+		rep.setUsable(false, fmt.Sprintf("check failed: %v", err))
+		return rep.status(), fmt.Errorf("failed to check the replica: %w", err)
+	}
+
+	primaryLSN, err := parseLSN(primary)
+	if err != nil {
+		return rep.status(), err
+	}
+	replayedLSN, err := parseLSN(replayed.String)
+	if err != nil {
+		return rep.status(), err
+	}
+	rep.replayed.Store(replayedLSN)
+	// A write whose position could not be read committed before now
+	rep.written.CompareAndSwap(math.MaxUint64, primaryLSN)
+	rep.lag = 0
+	if replayedLSN < primaryLSN && idle.Valid {
+		rep.lag = time.Duration(idle.Float64 * float64(time.Second))
+	}
+	metrics.ReplicaLag.Set(rep.lag.Seconds())
+	if rep.lag > rep.maxLag {
+		rep.setUsable(false, fmt.Sprintf("lagging %v behind the primary", rep.lag.Round(time.Millisecond)))
+	} else {
+		rep.setUsable(true, "")
+	}
+	return rep.status(), nil
+}
+
+// status reports the replica's state; the caller holds mu
+func (rep *readReplica) status() domain.ReplicaStatus {
+	return domain.ReplicaStatus{Configured: true, Usable: rep.usable.Load(), Lag: rep.lag}
+}
+
+// fail routes reads to the primary after a read on the replica could not
+// reach it
+func (rep *readReplica) fail(err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	rep.setUsable(false, fmt.Sprintf("read failed: %v", err))
+}
+
+// setUsable logs and counts changes of route; the caller holds mu
+func (rep *readReplica) setUsable(usable bool, reason string) {
+	if rep.usable.Swap(usable) == usable {
+		return
+	}
+	if usable {
+		log.Printf("Reading from the replica again")
+		metrics.ReplicaFailovers.WithLabelValues("replica").Inc()
+		return
+	}
+	log.Printf("Reading from the primary: replica %s", reason)
+	metrics.ReplicaFailovers.WithLabelValues("primary").Inc()
+}
+
+// parseLSN reads a WAL position written as two hexadecimal halves, such
+// as 16/B374D848
+func parseLSN(text string) (uint64, error) {
+	high, low, ok := strings.Cut(text, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid WAL position %q", text)
+	}
+	h, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid WAL position %q", text)
+	}
+	l, err := strconv.ParseUint(low, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid WAL position %q", text)
+	}
+	return h<<32 | l, nil
+}
+
+// isConnectionError reports whether err means the database could not be
+// reached, rather than that a query failed
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, sql.ErrConnDone), errors.As(err, &netErr):
+		return true
+	case errors.As(err, &pqErr):
+		// Class 08 is connection exceptions; 57P01 to 57P03 are the
+		// server shutting down or not accepting connections
+		return pqErr.Code.Class() == "08" || strings.HasPrefix(string(pqErr.Code), "57P")
+	}
+	return false
+}
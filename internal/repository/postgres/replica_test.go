@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+func TestParseLSN(t *testing.T) {
+	for text, want := range map[string]uint64{
+		"0/0":               0,
+		"0/16B3748":         0x16B3748,
+		"16/B374D848":       0x16<<32 | 0xB374D848,
+		"FFFFFFFF/FFFFFFFF": math.MaxUint64,
+	} {
+		if got, err := parseLSN(text); err != nil || got != want {
+			t.Errorf("parseLSN(%q) = %x, %v; want %x", text, got, err, want)
+		}
+	}
+	for _, text := range []string{"", "16", "16/", "G/1", "1/100000000"} {
+		if _, err := parseLSN(text); err == nil {
+			t.Errorf("Expected parseLSN(%q) to fail", text)
+		}
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	connection := []error{
+		driver.ErrBadConn,
+		io.EOF,
+		fmt.Errorf("read: %w", io.ErrUnexpectedEOF),
+		sql.ErrConnDone,
+		&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+		&pq.Error{Code: "08006"},
+		&pq.Error{Code: "57P01"},
+	}
+	for _, err := range connection {
+		if !isConnectionError(err) {
+			t.Errorf("Expected %v to be a connection error", err)
+		}
+	}
+	for _, err := range []error{nil, sql.ErrNoRows, domain.ErrLocationNotFound, &pq.Error{Code: "42P01"}} {
+		if isConnectionError(err) {
+			t.Errorf("Expected %v not to be a connection error", err)
+		}
+	}
+}
+
+// TestReplicaRouting stands a second database in for the replica. It is
+// not replicating, so each test marks it caught up by hand and tells the
+// two apart by the rows only one of them holds.
+func TestReplicaRouting(t *testing.T) {
+	primaryDB, primaryCleanup := setupTestContainer(t)
+	defer primaryCleanup()
+	replicaDB, replicaCleanup := setupTestContainer(t)
+	defer replicaCleanup()
+
+	if _, err := replicaDB.Exec(`INSERT INTO locations (name, latitude, longitude) VALUES ('Only On Replica', 6.5, 3.4)`); err != nil {
+		t.Fatalf("Failed to seed the replica: %v", err)
+	}
+	repo := NewPostgresLocationRepository(primaryDB, WithReplica(replicaDB, time.Second))
+	caughtUp := func() {
+		repo.replica.usable.Store(true)
+		repo.replica.replayed.Store(repo.replica.written.Load())
+	}
+
+	// Until a check finds it, reads go to the primary
+	if _, err := repo.FindByName("Only On Replica"); err != domain.ErrLocationNotFound {
+		t.Fatalf("Expected reads on the primary before the first check, got %v", err)
+	}
+
+	caughtUp()
+	for name, read := range map[string]func() error{
+		"FindByName": func() error { _, err := repo.FindByName("Only On Replica"); return err },
+		"FindNearest": func() error {
+			location, _, err := repo.FindNearest(6.5, 3.4)
+			if err == nil && location.Name != "Only On Replica" {
+				err = fmt.Errorf("found %s", location.Name)
+			}
+			return err
+		},
+		"FindAll": func() error {
+			all, err := repo.FindAll()
+			if err == nil && (len(all) != 1 || all[0].Name != "Only On Replica") {
+				err = fmt.Errorf("found %d locations", len(all))
+			}
+			return err
+		},
+	} {
+		if err := read(); err != nil {
+			t.Errorf("Expected %s to read the replica, got %v", name, err)
+		}
+	}
+
+	t.Run("read your writes", func(t *testing.T) {
+		caughtUp()
+		location, _ := domain.NewLocation("Written", 6.6, 3.3)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save: %v", err)
+		}
+		// The replica has not replayed the write, so reads stay on the
+		// primary until a check says it has
+		if _, err := repo.FindByName("Written"); err != nil {
+			t.Errorf("Expected to read back the write from the primary, got %v", err)
+		}
+		caughtUp()
+		if _, err := repo.FindByName("Written"); err != domain.ErrLocationNotFound {
+			t.Errorf("Expected reads back on the replica once it caught up, got %v", err)
+		}
+	})
+
+	t.Run("a replica that is not replicating fails its check", func(t *testing.T) {
+		caughtUp()
+		status, err := repo.CheckReplica(context.Background())
+		if err == nil || status.Usable {
+			t.Errorf("Expected the check to fail on a database not in recovery, got %+v, %v", status, err)
+		}
+		if _, err := repo.FindByName("Written"); err != nil {
+			t.Errorf("Expected reads on the primary after a failed check, got %v", err)
+		}
+	})
+
+	t.Run("dropped connection", func(t *testing.T) {
+		// Point the repository at a port nothing listens on, as if the
+		// replica had gone away
+		gone, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=testuser password=testpass dbname=testdb sslmode=disable connect_timeout=2")
+		if err != nil {
+			t.Fatalf("Failed to open: %v", err)
+		}
+		defer gone.Close()
+		repo.replica.db = gone
+		caughtUp()
+		if _, err := repo.FindByName("Written"); err != nil {
+			t.Errorf("Expected the read to fail over to the primary, got %v", err)
+		}
+		if repo.replica.usable.Load() {
+			t.Errorf("Expected the replica marked unusable after a failed read")
+		}
+	})
+}
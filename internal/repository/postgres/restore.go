@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RestoreLocations inserts snapshot records with explicit IDs in one
+// transaction and then moves the id sequence past them. The tables are
+// locked against other writes for the duration, so the conflict check
+// cannot race a concurrent create or alias.
+func (r *PostgresLocationRepository) RestoreLocations(locations []domain.Location) (*domain.RestoreResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE locations, location_aliases IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(locations))
+	names := make([]string, 0, len(locations))
+	for _, location := range locations {
+		if id, err := strconv.ParseInt(location.ID, 10, 32); err == nil {
+			ids = append(ids, id)
+		}
+		names = append(append(names, location.Name), location.Aliases...)
+	}
+	rows, err := tx.Query(`SELECT id, name FROM locations WHERE id = ANY($1) OR name = ANY($2)
+			 UNION ALL
+			 SELECT 0, alias FROM location_aliases WHERE alias = ANY($2)`,
+		pq.Array(ids), pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	takenIDs := map[string]bool{}
+	takenNames := map[string]bool{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if id != 0 {
+			takenIDs[strconv.FormatInt(id, 10)] = true
+		}
+		takenNames[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	accepted, conflicts := domain.PlanRestore(locations,
+		func(id string) bool { return takenIDs[id] },
+		func(name string) bool { return takenNames[name] },
+	)
+	for _, location := range accepted {
+		createdAt := location.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		_, err := tx.Exec(`INSERT INTO locations (id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			location.ID, location.Name, location.Latitude, location.Longitude, createdAt, openingHours{&location.OpeningHours}, location.Description, location.Region, attachments{&location.Attachments}, location.CapacityLitres, location.CurrentStockLitres, location.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		for _, alias := range location.Aliases {
+			if _, err := tx.Exec(`INSERT INTO location_aliases (alias, location_id) VALUES ($1, $2)`, alias, location.ID); err != nil {
+				return nil, err
+			}
+		}
+		location.CreatedAt = createdAt
+		if err := recordChange(tx, domain.ChangeCreated, location.Name, location); err != nil {
+			return nil, err
+		}
+		if err := notifyChange(tx, location.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	// Explicit IDs bypass the SERIAL sequence; advance it past the largest
+	// stored ID unless it is already beyond it
+	_, err = tx.Exec(`SELECT setval('locations_id_seq', restored.max_id)
+			 FROM (SELECT MAX(id) AS max_id FROM locations) AS restored, locations_id_seq AS seq
+			 WHERE restored.max_id >= CASE WHEN seq.is_called THEN seq.last_value + 1 ELSE seq.last_value END`)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.wrote()
+	return &domain.RestoreResult{Restored: len(accepted), Conflicts: conflicts}, nil
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+)
+
+func TestPostgresRestoreLocations(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	repotest.RunRestore(t, NewPostgresLocationRepository(db))
+
+	var lastValue int
+	if err := db.QueryRow(`SELECT last_value FROM locations_id_seq`).Scan(&lastValue); err != nil {
+		t.Fatalf("Failed to read the id sequence: %v", err)
+	}
+	if lastValue != 31 {
+		t.Errorf("Expected the id sequence at the last created ID 31, got %d", lastValue)
+	}
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type PostgresSettingsRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSettingsRepository(db *sql.DB) *PostgresSettingsRepository {
+	return &PostgresSettingsRepository{db: db}
+}
+
+func (r *PostgresSettingsRepository) FindSearchSettings() (*domain.SearchSettings, error) {
+	var settings domain.SearchSettings
+	err := r.db.QueryRow(`SELECT speed_kmh, max_distance_km, updated_at FROM search_settings WHERE id = 1`).
+		Scan(&settings.SpeedKmh, &settings.MaxDistanceKm, &settings.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSettingsNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *PostgresSettingsRepository) SaveSearchSettings(settings *domain.SearchSettings) error {
+	_, err := r.db.Exec(`INSERT INTO search_settings (id, speed_kmh, max_distance_km, updated_at)
+			 VALUES (1, $1, $2, $3)
+			 ON CONFLICT (id) DO UPDATE
+			 SET speed_kmh = EXCLUDED.speed_kmh, max_distance_km = EXCLUDED.max_distance_km, updated_at = EXCLUDED.updated_at`,
+		settings.SpeedKmh, settings.MaxDistanceKm, settings.UpdatedAt)
+	return err
+}
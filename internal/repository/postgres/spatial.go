@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// geomToleranceMeters absorbs floating point noise when comparing geom to
+// the point recomputed from latitude and longitude
+const geomToleranceMeters = 0.01
+
+// VerifySpatial compares each row's geom with the geography recomputed from
+// its latitude and longitude, walking the table in id order one batch per
+// transaction. With fix set, missing or mismatched geoms are rewritten;
+// out-of-range coordinates are only reported.
+func (r *PostgresLocationRepository) VerifySpatial(batchSize int, fix bool, onBatch func(domain.SpatialBatch) error) error {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	lastID := 0
+	for {
+		batch, next, err := r.verifySpatialBatch(lastID, batchSize, fix)
+		if err != nil {
+			return err
+		}
+		if batch.Scanned == 0 {
+			return nil
+		}
+		if err := onBatch(batch); err != nil {
+			return err
+		}
+		if batch.Scanned < batchSize {
+			return nil
+		}
+		lastID = next
+	}
+}
+
+func (r *PostgresLocationRepository) verifySpatialBatch(afterID, limit int, fix bool) (domain.SpatialBatch, int, error) {
+	result := domain.SpatialBatch{Issues: []domain.SpatialIssue{}}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return result, afterID, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id, name, latitude, longitude, geom IS NULL,
+				 COALESCE(NOT ST_DWithin(geom, ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography, $3), false)
+			  FROM locations
+			  WHERE id > $1
+			  ORDER BY id
+			  LIMIT $2`
+	if fix {
+		// Lock only this batch so concurrent writes elsewhere are not blocked
+		query += ` FOR UPDATE`
+	}
+
+	rows, err := tx.Query(query, afterID, limit, geomToleranceMeters)
+	if err != nil {
+		return result, afterID, err
+	}
+
+	lastID := afterID
+	var repair []int64
+	for rows.Next() {
+		var id int
+		var name string
+		var latitude, longitude float64
+		var missing, mismatch bool
+		if err := rows.Scan(&id, &name, &latitude, &longitude, &missing, &mismatch); err != nil {
+			rows.Close()
+			return result, afterID, err
+		}
+		result.Scanned++
+		lastID = id
+
+		location := domain.Location{Name: name, Latitude: latitude, Longitude: longitude}
+		issueID := fmt.Sprintf("%d", id)
+		if location.Validate() != nil {
+			result.Issues = append(result.Issues, domain.SpatialIssue{
+				ID: issueID, Name: name, Problem: domain.SpatialInvalidCoordinates,
+			})
+			continue
+		}
+
+		problem := ""
+		switch {
+		case missing:
+			problem = domain.SpatialGeomMissing
+		case mismatch:
+			problem = domain.SpatialGeomMismatch
+		default:
+			continue
+		}
+		result.Issues = append(result.Issues, domain.SpatialIssue{
+			ID: issueID, Name: name, Problem: problem, Repaired: fix,
+		})
+		if fix {
+			repair = append(repair, int64(id))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return result, afterID, err
+	}
+	rows.Close()
+
+	if len(repair) > 0 {
+		_, err := tx.Exec(`UPDATE locations
+				 SET geom = ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography
+				 WHERE id = ANY($1)`, pq.Array(repair))
+		if err != nil {
+			return result, afterID, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, afterID, err
+	}
+	if fix {
+		r.wrote()
+	}
+	return result, lastID, nil
+}
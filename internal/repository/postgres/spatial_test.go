@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// corruptGeom runs a raw update with the geom trigger disabled, so the
+// column can be made to disagree with latitude and longitude
+func corruptGeom(t *testing.T, db *sql.DB, set, name string) {
+	t.Helper()
+	statements := []string{
+		`ALTER TABLE locations DISABLE TRIGGER update_geom`,
+		fmt.Sprintf(`UPDATE locations SET geom = %s WHERE name = '%s'`, set, name),
+		`ALTER TABLE locations ENABLE TRIGGER update_geom`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Failed to corrupt row: %v", err)
+		}
+	}
+}
+
+func verifyAll(t *testing.T, repo *PostgresLocationRepository, fix bool) []domain.SpatialIssue {
+	t.Helper()
+	var issues []domain.SpatialIssue
+	batches := 0
+	err := repo.VerifySpatial(2, fix, func(batch domain.SpatialBatch) error {
+		batches++
+		issues = append(issues, batch.Issues...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if batches < 2 {
+		t.Errorf("Expected the scan to be split into batches, got %d", batches)
+	}
+	return issues
+}
+
+func TestPostgresVerifySpatial_DetectsAndRepairs(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+	repo := NewPostgresLocationRepository(db)
+
+	for i, name := range []string{"Ikeja", "Yaba", "Lekki", "Ajah"} {
+		location, _ := domain.NewLocation(name, 6.4+float64(i)/100, 3.3+float64(i)/100)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save location: %v", err)
+		}
+	}
+
+	if issues := verifyAll(t, repo, false); len(issues) != 0 {
+		t.Fatalf("Expected a clean table, got %+v", issues)
+	}
+
+	corruptGeom(t, db, `ST_SetSRID(ST_MakePoint(0, 0), 4326)::geography`, "Yaba")
+	corruptGeom(t, db, `NULL`, "Ajah")
+
+	issues := verifyAll(t, repo, false)
+	problems := map[string]string{}
+	for _, issue := range issues {
+		problems[issue.Name] = issue.Problem
+	}
+	if problems["Yaba"] != domain.SpatialGeomMismatch {
+		t.Errorf("Expected Yaba geom mismatch, got %q", problems["Yaba"])
+	}
+	if problems["Ajah"] != domain.SpatialGeomMissing {
+		t.Errorf("Expected Ajah geom missing, got %q", problems["Ajah"])
+	}
+	if len(issues) != 2 {
+		t.Errorf("Expected 2 issues, got %+v", issues)
+	}
+
+	repaired := verifyAll(t, repo, true)
+	for _, issue := range repaired {
+		if !issue.Repaired {
+			t.Errorf("Expected %s to be repaired", issue.Name)
+		}
+	}
+
+	if issues := verifyAll(t, repo, false); len(issues) != 0 {
+		t.Errorf("Expected a clean table after repair, got %+v", issues)
+	}
+
+	// The repaired geom is usable by nearest search again
+	nearest, _, err := repo.FindNearest(6.44, 3.34)
+	if err != nil || nearest.Name != "Ajah" {
+		t.Errorf("Expected Ajah to be nearest after repair, got %v (%v)", nearest, err)
+	}
+}
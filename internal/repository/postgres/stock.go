@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// UpdateStock changes the location's capacity and stock. The row is locked
+// while the update is checked against it, so concurrent updates apply one
+// after another instead of overwriting each other.
+func (r *PostgresLocationRepository) UpdateStock(name string, update domain.StockUpdate, checks ...domain.Precondition) (*domain.Location, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	owner, err := nameOwner(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := checkLocation(tx, owner.ID, checks); err != nil {
+		return nil, err
+	}
+	var capacity, stock *float64
+	err = tx.QueryRow(`SELECT capacity_litres, current_stock_litres FROM locations WHERE id = $1 FOR UPDATE`, owner.ID).
+		Scan(&capacity, &stock)
+	if err != nil {
+		return nil, err
+	}
+	capacity, stock, err = update.Apply(capacity, stock)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE locations SET capacity_litres = $2, current_stock_litres = $3 WHERE id = $1`, owner.ID, capacity, stock); err != nil {
+		return nil, err
+	}
+
+	updated, err := findByID(tx, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(tx, domain.ChangeUpdated, updated.Name, *updated); err != nil {
+		return nil, err
+	}
+	if err := notifyStockChange(tx, updated.Name); err != nil {
+		return nil, err
+	}
+	if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationUpdated, *updated)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.wrote()
+	return updated, nil
+}
+
+// FindNearestStocked filters on stock in the query, so the KNN scan
+// passes over stations holding too little
+func (r *PostgresLocationRepository) FindNearestStocked(region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	location, distance, err := r.findNearest(region, minLitres, latitude, longitude, exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance}, nil
+}
+
+// FindNearestStockedWithin looks for stock among only the budget.Hard
+// stations nearest the query point, whatever they hold, so the KNN scan
+// stops there. The index orders every candidate, so answers are exact and
+// budget.Soft is not used.
+func (r *PostgresLocationRepository) FindNearestStockedWithin(budget domain.ScanBudget, region string, minLitres, latitude, longitude float64, exclude ...string) (*domain.NearestResult, error) {
+	if budget.Hard <= 0 {
+		return r.FindNearestStocked(region, minLitres, latitude, longitude, exclude...)
+	}
+	candidates, args := nearestQuery(region, 0, latitude, longitude, exclude)
+	candidates += `
+			  ORDER BY geom <-> ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, name COLLATE "C", id`
+	// One candidate past the limit tells a spent budget from a store with
+	// no stocked station
+	count := fmt.Sprintf(`SELECT COUNT(*) FROM (%s LIMIT $%d) candidates`, candidates, len(args)+1)
+	countArgs := append(slices.Clone(args), budget.Hard+1)
+
+	args = append(args, budget.Hard, minLitres)
+	query := fmt.Sprintf(`SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by, distance_m
+			  FROM (%s LIMIT $%d) candidates
+			  WHERE current_stock_litres >= $%d
+			  ORDER BY distance_m, name COLLATE "C", id
+			  LIMIT 1`, candidates, len(args)-1, len(args))
+
+	var location *domain.Location
+	var distance geospatial.Distance
+	err := r.read(func(q querier) error {
+		var err error
+		location, distance, err = findNearest(q, query, args)
+		if !errors.Is(err, domain.ErrLocationNotFound) {
+			return err
+		}
+		var examined int
+		if err := q.QueryRow(count, countArgs...).Scan(&examined); err != nil {
+			return err
+		}
+		if examined > budget.Hard {
+			return domain.ErrScanBudgetExceeded
+		}
+		return domain.ErrLocationNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NearestResult{Location: location, Distance: distance}, nil
+}
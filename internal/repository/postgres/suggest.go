@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// buildSuggestQuery renders the scoring described at
+// domain.SuggestPrefixScore in SQL. Each of the three name matches can be
+// served by the name trigram index, so only candidate rows are scored;
+// the similarity threshold of the % operator is pg_trgm's, 0.3 by default.
+func buildSuggestQuery(query domain.SuggestQuery) (string, []any) {
+	q := &findQuery{}
+	similar := q.arg(query.Query)
+	prefix := q.arg(likeEscaper.Replace(query.Query) + "%")
+	wordPrefix := q.arg("% " + likeEscaper.Replace(query.Query) + "%")
+
+	distance, score := "0::float8", "name_score"
+	if query.Near != nil {
+		distance = fmt.Sprintf("ST_Distance(geom, ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography, false)",
+			q.arg(query.Near.Longitude), q.arg(query.Near.Latitude))
+		weight := q.arg(query.DistanceWeight) + "::float8"
+		score = fmt.Sprintf("(1 - %s) * name_score + %s / (1 + distance_m / 1000 / %g)", weight, weight, domain.SuggestDistanceScaleKm)
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = domain.DefaultSuggestLimit
+	}
+
+	sql := fmt.Sprintf(`SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by,
+				 distance_m, %[1]s AS score
+			  FROM (
+				SELECT id, name, latitude, longitude, created_at, opening_hours, description, region, attachments, capacity_litres, current_stock_litres, created_by,
+					   %[2]s AS distance_m,
+					   CASE WHEN name ILIKE %[3]s ESCAPE '\' THEN %[6]g
+							WHEN name ILIKE %[4]s ESCAPE '\' THEN %[7]g
+							ELSE similarity(name, %[5]s)::float8 END AS name_score
+				FROM locations
+				WHERE name ILIKE %[3]s ESCAPE '\' OR name ILIKE %[4]s ESCAPE '\' OR name %% %[5]s
+			  ) candidates
+			  WHERE name_score >= %[8]g
+			  ORDER BY score DESC, distance_m, name COLLATE "C"
+			  LIMIT %[9]s`,
+		score, distance, prefix, wordPrefix, similar,
+		domain.SuggestPrefixScore, domain.SuggestWordPrefixScore, domain.SuggestMinSimilarity, q.arg(limit))
+	return sql, q.args
+}
+
+// SuggestLocations scores candidate names and distances in the database
+// and returns the best query.Limit
+func (r *PostgresLocationRepository) SuggestLocations(query domain.SuggestQuery) ([]domain.Suggestion, error) {
+	sql, args := buildSuggestQuery(query)
+	var suggestions []domain.Suggestion
+	err := r.read(func(q querier) error {
+		var err error
+		suggestions, err = suggest(q, sql, args)
+		return err
+	})
+	return suggestions, err
+}
+
+// suggest reads the suggestions query selects, with their aliases
+func suggest(q querier, sql string, args []any) ([]domain.Suggestion, error) {
+	rows, err := q.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []domain.Suggestion{}
+	var locations []*domain.Location
+	for rows.Next() {
+		var location domain.Location
+		var id int
+		var distanceM, score float64
+		if err := rows.Scan(
+			&id,
+			&location.Name,
+			&location.Latitude,
+			&location.Longitude,
+			nullableTime{&location.CreatedAt},
+			openingHours{&location.OpeningHours},
+			&location.Description,
+			&location.Region,
+			attachments{&location.Attachments},
+			&location.CapacityLitres,
+			&location.CurrentStockLitres,
+			&location.CreatedBy,
+			&distanceM,
+			&score,
+		); err != nil {
+			return nil, err
+		}
+		location.ID = fmt.Sprintf("%d", id)
+		locations = append(locations, &location)
+		suggestions = append(suggestions, domain.Suggestion{Location: &location, Distance: geospatial.Meters(distanceM), Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	logDefaulted(locations)
+	return suggestions, attachAliases(q, locations...)
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/repotest"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func TestBuildSuggestQuery(t *testing.T) {
+	t.Parallel()
+	sql, args := buildSuggestQuery(domain.SuggestQuery{Query: `50%_off`})
+	if want := []any{`50%_off`, `50\%\_off%`, `% 50\%\_off%`, domain.DefaultSuggestLimit}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Expected args %v, got %v", want, args)
+	}
+	for _, part := range []string{"0::float8 AS distance_m", "name_score AS score", "name % $1", "LIMIT $4"} {
+		if !strings.Contains(sql, part) {
+			t.Errorf("Expected %q in %s", part, sql)
+		}
+	}
+
+	near := &geospatial.Coordinate{Latitude: 6.5, Longitude: 3.4}
+	sql, args = buildSuggestQuery(domain.SuggestQuery{Query: "lekki", Near: near, Limit: 5, DistanceWeight: 0.3})
+	if want := []any{"lekki", "lekki%", "% lekki%", 3.4, 6.5, 0.3, 5}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Expected args %v, got %v", want, args)
+	}
+	for _, part := range []string{"ST_MakePoint($4, $5)", "(1 - $6::float8) * name_score", "LIMIT $7"} {
+		if !strings.Contains(sql, part) {
+			t.Errorf("Expected %q in %s", part, sql)
+		}
+	}
+}
+
+func TestPostgresSuggest(t *testing.T) {
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	repotest.RunSuggest(t, NewPostgresLocationRepository(db))
+}
+
+func BenchmarkPostgresSuggestLocations(b *testing.B) {
+	// Create a testing.T instance for setupTestContainer
+	t := &testing.T{}
+	db, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	repo := NewPostgresLocationRepository(db)
+	if err := repotest.SeedSuggestions(repo, 100000); err != nil {
+		b.Fatalf("Failed to seed: %v", err)
+	}
+	query := domain.SuggestQuery{
+		Query:          "lekki dep",
+		Near:           &geospatial.Coordinate{Latitude: 6.4281, Longitude: 3.4219},
+		Limit:          domain.DefaultSuggestLimit,
+		DistanceWeight: domain.DefaultSuggestDistanceWeight,
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.SuggestLocations(query); err != nil {
+			b.Fatalf("Failed to suggest: %v", err)
+		}
+	}
+}
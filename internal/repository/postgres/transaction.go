@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lib/pq"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// ApplyOperations applies the operations in one transaction, so the first
+// failure rolls back everything before it. Every name the transaction
+// claims is locked up front in sorted order, so two transactions claiming
+// the same names wait for each other instead of deadlocking.
+func (r *PostgresLocationRepository) ApplyOperations(ops []domain.LocationOperation) ([]domain.OperationResult, error) {
+	if len(ops) == 0 {
+		return nil, domain.ErrEmptyTransaction
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var claimed []string
+	for _, op := range ops {
+		switch {
+		case op.Type == domain.OperationCreate && op.Location != nil:
+			claimed = append(claimed, op.Location.Name)
+		case op.Type == domain.OperationUpdate:
+			if op.Changes.Name != nil {
+				claimed = append(claimed, *op.Changes.Name)
+			}
+			if op.Changes.Aliases != nil {
+				claimed = append(claimed, *op.Changes.Aliases...)
+			}
+		}
+	}
+	slices.Sort(claimed)
+	for _, name := range slices.Compact(claimed) {
+		if err := lockName(tx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]domain.OperationResult, 0, len(ops))
+	for i, op := range ops {
+		location, err := applyOperation(tx, op)
+		if err != nil {
+			return nil, &domain.OperationError{Index: i, Err: err}
+		}
+		results = append(results, domain.OperationResult{Type: op.Type, Location: *location})
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.wrote()
+	return results, nil
+}
+
+// applyOperation runs one operation and returns the location it created,
+// updated or deleted
+func applyOperation(tx *sql.Tx, op domain.LocationOperation) (*domain.Location, error) {
+	switch op.Type {
+	case domain.OperationCreate:
+		if op.Location == nil {
+			return nil, fmt.Errorf("create without a location")
+		}
+		created := *op.Location
+		if err := insertLocation(tx, &created); err != nil {
+			return nil, err
+		}
+		return &created, nil
+	case domain.OperationUpdate:
+		return updateLocation(tx, op.Name, op.Changes, op.Checks...)
+	case domain.OperationDelete:
+		return deleteLocation(tx, op.Name, op.Checks...)
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Type)
+}
+
+// updateLocation writes the changes to the location found by name, once
+// it passes checks. A new name is recorded as a rename is; the caller
+// holds its lock and those of any new aliases.
+func updateLocation(tx *sql.Tx, name string, changes domain.LocationChanges, checks ...domain.Precondition) (*domain.Location, error) {
+	owner, err := nameOwner(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err := lockLocation(tx, owner.ID); err != nil {
+		return nil, err
+	}
+	previous, err := findByID(tx, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.CheckPreconditions(previous, checks...); err != nil {
+		return nil, err
+	}
+	updated := changes.Apply(*previous)
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	renamed := updated.Name != previous.Name
+	if renamed {
+		taken, err := nameOwner(tx, updated.Name)
+		if err != nil {
+			return nil, err
+		}
+		if taken != nil {
+			return nil, domain.NameConflict(updated.Name, taken)
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE locations
+			 SET name = $2, latitude = $3, longitude = $4, opening_hours = $5, description = $6, region = $7, attachments = $8
+			 WHERE id = $1`,
+		owner.ID, updated.Name, updated.Latitude, updated.Longitude, openingHours{&updated.OpeningHours}, updated.Description, updated.Region, attachments{&updated.Attachments})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, domain.ErrLocationExists
+		}
+		return nil, err
+	}
+	if changes.Aliases != nil {
+		if err := replaceAliases(tx, owner.ID, updated.Aliases); err != nil {
+			return nil, err
+		}
+	}
+
+	if renamed {
+		if err := recordChange(tx, domain.ChangeDeleted, previous.Name, *previous); err != nil {
+			return nil, err
+		}
+		if err := notifyChange(tx, previous.Name); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordChange(tx, domain.ChangeUpdated, updated.Name, updated); err != nil {
+		return nil, err
+	}
+	if err := notifyChange(tx, updated.Name); err != nil {
+		return nil, err
+	}
+	if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationUpdated, updated)); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// replaceAliases makes aliases the only aliases of the location with id.
+// The location's name is already updated, so an alias it held before a
+// rename is free for it to keep.
+func replaceAliases(tx *sql.Tx, id string, aliases []string) error {
+	for _, alias := range aliases {
+		taken, err := nameOwner(tx, alias)
+		if err != nil {
+			return err
+		}
+		if taken != nil && taken.ID != id {
+			return &domain.NameTakenError{Name: alias, Owner: taken.Name}
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM location_aliases WHERE location_id = $1`, id); err != nil {
+		return err
+	}
+	for _, alias := range aliases {
+		if _, err := tx.Exec(`INSERT INTO location_aliases (alias, location_id) VALUES ($1, $2)`, alias, id); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+				return domain.ErrLocationExists
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// TruncateLocations empties locations and their aliases with TRUNCATE in
+// one transaction with a deletion change and outbox event for every
+// location removed and the audit entry. The ID sequence carries on rather
+// than restarting with RESTART IDENTITY, so a later location never takes
+// an ID consumers already saw deleted.
+// The tables are locked first, so no write lands between reading the
+// locations and truncating them.
+func (r *PostgresLocationRepository) TruncateLocations(actor string) (*domain.TruncateAudit, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE locations, location_aliases IN ACCESS EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+	query, args := buildFindQuery(domain.LocationFilter{}, domain.Page{}, domain.LocationSort{Field: domain.SortByID}, "")
+	locations, err := find(tx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, location := range locations {
+		if err := recordChange(tx, domain.ChangeDeleted, location.Name, *location); err != nil {
+			return nil, err
+		}
+		if err := notifyChange(tx, location.Name); err != nil {
+			return nil, err
+		}
+		if err := insertOutboxEvent(tx, domain.NewEvent(domain.EventLocationDeleted, *location)); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`TRUNCATE locations, location_aliases`); err != nil {
+		return nil, err
+	}
+
+	audit := domain.TruncateAudit{Count: len(locations), Actor: actor}
+	var id int64
+	err = tx.QueryRow(`INSERT INTO location_truncations (count, actor)
+			 VALUES ($1, NULLIF($2, ''))
+			 RETURNING id, truncated_at`, audit.Count, actor).Scan(&id, &audit.TruncatedAt)
+	if err != nil {
+		return nil, err
+	}
+	audit.ID = fmt.Sprintf("%d", id)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.wrote()
+	audit.Removed = make([]domain.Location, len(locations))
+	for i, location := range locations {
+		audit.Removed[i] = *location
+	}
+	return &audit, nil
+}
+
+// Truncations returns the truncation audit log, oldest first
+func (r *PostgresLocationRepository) Truncations() ([]domain.TruncateAudit, error) {
+	rows, err := r.db.Query(`SELECT id, count, COALESCE(actor, ''), truncated_at
+			 FROM location_truncations
+			 ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var truncations []domain.TruncateAudit
+	for rows.Next() {
+		var audit domain.TruncateAudit
+		var id int64
+		if err := rows.Scan(&id, &audit.Count, &audit.Actor, &audit.TruncatedAt); err != nil {
+			return nil, err
+		}
+		audit.ID = fmt.Sprintf("%d", id)
+		truncations = append(truncations, audit)
+	}
+	return truncations, rows.Err()
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+type PostgresUsageRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUsageRepository(db *sql.DB) *PostgresUsageRepository {
+	return &PostgresUsageRepository{db: db}
+}
+
+func (r *PostgresUsageRepository) AddUsage(records []domain.UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO usage_counters (api_key, tenant, operation, day, count)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (api_key, operation, day)
+			 DO UPDATE SET count = usage_counters.count + EXCLUDED.count`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		if _, err := stmt.Exec(record.Key, record.Tenant, record.Operation, domain.UsageDay(record.Day), record.Count); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresUsageRepository) ListUsage(key string, from, to time.Time) ([]domain.UsageRecord, error) {
+	query := `SELECT api_key, tenant, operation, day, count
+			 FROM usage_counters
+			 WHERE ($1 = '' OR api_key = $1) AND day BETWEEN $2 AND $3
+			 ORDER BY day, api_key, operation`
+
+	rows, err := r.db.Query(query, key, domain.UsageDay(from), domain.UsageDay(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []domain.UsageRecord{}
+	for rows.Next() {
+		var record domain.UsageRecord
+		if err := rows.Scan(&record.Key, &record.Tenant, &record.Operation, &record.Day, &record.Count); err != nil {
+			return nil, err
+		}
+		record.Day = domain.UsageDay(record.Day)
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *PostgresUsageRepository) TotalUsage(key string, from, to time.Time) (int64, error) {
+	query := `SELECT COALESCE(SUM(count), 0)
+			 FROM usage_counters
+			 WHERE api_key = $1 AND day BETWEEN $2 AND $3`
+
+	var total int64
+	if err := r.db.QueryRow(query, key, domain.UsageDay(from), domain.UsageDay(to)).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
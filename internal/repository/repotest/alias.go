@@ -0,0 +1,101 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// AliasRepository is a location store that gives locations alternate names
+type AliasRepository interface {
+	domain.LocationRepository
+	domain.LocationAliaser
+}
+
+// RunAliases checks that aliases resolve like names, share one namespace
+// with them, and go away with their location
+func RunAliases(t *testing.T, repo AliasRepository) {
+	t.Helper()
+	for _, name := range []string{"Leeta Lekki Phase 1", "Leeta Yaba"} {
+		location, _ := domain.NewLocation(name, 6.4474, 3.4720)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+
+	location, err := repo.AddAlias("Leeta Lekki Phase 1", "Leeta Admiralty Way")
+	if err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	if _, err := repo.AddAlias("Leeta Admiralty Way", "Leeta Lekki"); err != nil {
+		t.Fatalf("Failed to add alias through an alias: %v", err)
+	}
+	location, err = repo.AddAlias("Leeta Lekki Phase 1", "Leeta Lekki")
+	if err != nil {
+		t.Fatalf("Expected re-adding an alias to succeed, got %v", err)
+	}
+	if want := []string{"Leeta Admiralty Way", "Leeta Lekki"}; location.Name != "Leeta Lekki Phase 1" || !slices.Equal(location.Aliases, want) {
+		t.Fatalf("Expected %v on Leeta Lekki Phase 1, got %s %v", want, location.Name, location.Aliases)
+	}
+
+	found, err := repo.FindByName("Leeta Lekki")
+	if err != nil {
+		t.Fatalf("Failed to find by alias: %v", err)
+	}
+	if found.Name != "Leeta Lekki Phase 1" || len(found.Aliases) != 2 {
+		t.Errorf("Expected the canonical location with its aliases, got %s %v", found.Name, found.Aliases)
+	}
+
+	taken := []struct {
+		name, alias, owner string
+	}{
+		{"Leeta Yaba", "Leeta Lekki", "Leeta Lekki Phase 1"},
+		{"Leeta Yaba", "Leeta Lekki Phase 1", "Leeta Lekki Phase 1"},
+		{"Leeta Lekki", "Leeta Yaba", "Leeta Yaba"},
+	}
+	for _, tt := range taken {
+		_, err := repo.AddAlias(tt.name, tt.alias)
+		var nameTaken *domain.NameTakenError
+		if !errors.As(err, &nameTaken) || nameTaken.Owner != tt.owner {
+			t.Errorf("Adding %s to %s: expected the name taken by %s, got %v", tt.alias, tt.name, tt.owner, err)
+		}
+	}
+
+	clash, _ := domain.NewLocation("Leeta Admiralty Way", 6.5, 3.5)
+	err = repo.Save(clash)
+	var nameTaken *domain.NameTakenError
+	if !errors.As(err, &nameTaken) || nameTaken.Owner != "Leeta Lekki Phase 1" || !errors.Is(err, domain.ErrLocationExists) {
+		t.Errorf("Expected saving over an alias to name its owner, got %v", err)
+	}
+
+	if _, err := repo.AddAlias("Leeta Ikeja", "Leeta Ikeja City Mall"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound aliasing a missing location, got %v", err)
+	}
+	if _, err := repo.RemoveAlias("Leeta Yaba", "Leeta Lekki"); !errors.Is(err, domain.ErrAliasNotFound) {
+		t.Errorf("Expected ErrAliasNotFound removing another location's alias, got %v", err)
+	}
+
+	location, err = repo.RemoveAlias("Leeta Lekki Phase 1", "Leeta Lekki")
+	if err != nil {
+		t.Fatalf("Failed to remove alias: %v", err)
+	}
+	if !slices.Equal(location.Aliases, []string{"Leeta Admiralty Way"}) {
+		t.Errorf("Expected one alias left, got %v", location.Aliases)
+	}
+	if _, err := repo.FindByName("Leeta Lekki"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected a removed alias to stop resolving, got %v", err)
+	}
+
+	if err := repo.Delete("Leeta Admiralty Way"); err != nil {
+		t.Fatalf("Failed to delete by alias: %v", err)
+	}
+	if _, err := repo.FindByName("Leeta Lekki Phase 1"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected deleting by alias to delete the location, got %v", err)
+	}
+	reused, _ := domain.NewLocation("Leeta Admiralty Way", 6.5, 3.5)
+	if err := repo.Save(reused); err != nil {
+		t.Errorf("Expected a deleted location's alias to be free again, got %v", err)
+	}
+}
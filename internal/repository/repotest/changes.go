@@ -0,0 +1,119 @@
+package repotest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// ChangeRepository is a location store that records a compactable change
+// log
+type ChangeRepository interface {
+	domain.LocationRepository
+	domain.LocationAliaser
+	domain.ChangeLog
+	domain.ChangeCompactor
+}
+
+// RunChanges checks that every mutation is logged in order, that the log
+// pages by sequence, and that compaction keeps the latest change per name
+func RunChanges(t *testing.T, repo ChangeRepository) {
+	t.Helper()
+	before, err := repo.ChangesSince(0, 1)
+	if err != nil {
+		t.Fatalf("Failed to read changes: %v", err)
+	}
+	start := before.Latest
+
+	location, _ := domain.NewLocation("Leeta Ikeja", 6.6018, 3.3515)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if _, err := repo.AddAlias("Leeta Ikeja", "Ikeja Along"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	if err := repo.Delete("Ikeja Along"); err != nil {
+		t.Fatalf("Failed to delete by alias: %v", err)
+	}
+
+	feed, err := repo.ChangesSince(start, 100)
+	if err != nil {
+		t.Fatalf("Failed to read changes: %v", err)
+	}
+	want := []domain.ChangeAction{domain.ChangeCreated, domain.ChangeUpdated, domain.ChangeDeleted}
+	if !slices.Equal(changeActions(feed.Changes), want) {
+		t.Fatalf("Expected %v, got %+v", want, feed.Changes)
+	}
+	for i, change := range feed.Changes {
+		if change.Name != "Leeta Ikeja" || change.Location.ID != location.ID {
+			t.Errorf("Change %d: expected Leeta Ikeja with id %s, got %s with id %s", i, location.ID, change.Name, change.Location.ID)
+		}
+		if i > 0 && change.Sequence <= feed.Changes[i-1].Sequence {
+			t.Errorf("Expected strictly increasing sequences, got %d after %d", change.Sequence, feed.Changes[i-1].Sequence)
+		}
+	}
+	if deleted := feed.Changes[2].Location; !slices.Equal(deleted.Aliases, []string{"Ikeja Along"}) || deleted.Latitude != 6.6018 {
+		t.Errorf("Expected the deletion to carry the last state, got %+v", deleted)
+	}
+	if feed.Latest != feed.Changes[2].Sequence {
+		t.Errorf("Expected latest %d, got %d", feed.Changes[2].Sequence, feed.Latest)
+	}
+
+	for _, name := range []string{"Leeta Yaba", "Leeta Ajah"} {
+		location, _ := domain.NewLocation(name, 6.5095, 3.3711)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+	all, _ := repo.ChangesSince(start, 100)
+	var paged []domain.Change
+	for since := start; ; {
+		page, err := repo.ChangesSince(since, 2)
+		if err != nil {
+			t.Fatalf("Failed to read page after %d: %v", since, err)
+		}
+		if page.Latest != all.Latest {
+			t.Errorf("Expected latest %d on every page, got %d", all.Latest, page.Latest)
+		}
+		if len(page.Changes) == 0 {
+			break
+		}
+		paged = append(paged, page.Changes...)
+		since = page.Changes[len(page.Changes)-1].Sequence
+	}
+	if len(paged) != 5 || !slices.EqualFunc(paged, all.Changes, func(a, b domain.Change) bool { return a.Sequence == b.Sequence }) {
+		t.Errorf("Expected paging to return the same 5 changes, got %+v", paged)
+	}
+
+	// Ikeja's create and update are superseded by its deletion
+	if dropped, err := repo.CompactChanges(1); err != nil || dropped != 2 {
+		t.Fatalf("Expected 2 changes compacted, got %d, %v", dropped, err)
+	}
+	if _, err := repo.AddAlias("Leeta Yaba", "Yaba Tech"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	// The newest two survive, so only Yaba's create goes
+	if dropped, err := repo.CompactChanges(2); err != nil || dropped != 1 {
+		t.Fatalf("Expected 1 change compacted, got %d, %v", dropped, err)
+	}
+	compacted, _ := repo.ChangesSince(start, 100)
+	var kept []string
+	for _, change := range compacted.Changes {
+		kept = append(kept, change.Name+" "+string(change.Action))
+	}
+	if want := []string{"Leeta Ikeja deleted", "Leeta Ajah created", "Leeta Yaba updated"}; !slices.Equal(kept, want) {
+		t.Errorf("Expected %v after compaction, got %v", want, kept)
+	}
+	if compacted.Latest != compacted.Changes[2].Sequence {
+		t.Errorf("Expected compaction to keep the latest sequence %d, got %d", compacted.Changes[2].Sequence, compacted.Latest)
+	}
+}
+
+func changeActions(changes []domain.Change) []domain.ChangeAction {
+	actions := make([]domain.ChangeAction, len(changes))
+	for i, change := range changes {
+		actions[i] = change.Action
+	}
+	return actions
+}
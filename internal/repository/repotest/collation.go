@@ -0,0 +1,58 @@
+package repotest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+)
+
+// CollatedNames are station names with accented and Yoruba letters, saved
+// in no particular order
+var CollatedNames = []string{"Zuba", "Ékó Station", "Eko", "Ẹgbẹ", "Gbagada", "Garki", "Ọ̀yọ́", "Ota", "Ṣagamu", "Sango", "Abuja"}
+
+// collatedOrder is the ascending order of CollatedNames under each
+// collation RunCollation checks
+var collatedOrder = map[string][]string{
+	text.BinaryCollation: {"Abuja", "Eko", "Garki", "Gbagada", "Ota", "Sango", "Zuba", "Ékó Station", "Ṣagamu", "Ẹgbẹ", "Ọ̀yọ́"},
+	"und":                {"Abuja", "Ẹgbẹ", "Eko", "Ékó Station", "Garki", "Gbagada", "Ota", "Ọ̀yọ́", "Ṣagamu", "Sango", "Zuba"},
+	"yo":                 {"Abuja", "Eko", "Ékó Station", "Ẹgbẹ", "Garki", "Gbagada", "Ota", "Ọ̀yọ́", "Sango", "Ṣagamu", "Zuba"},
+}
+
+// Collations lists the collation names RunCollation has an expected order for
+func Collations() []string {
+	return []string{text.BinaryCollation, "und", "yo"}
+}
+
+// RunCollation checks that an empty repository configured with the named
+// collation lists CollatedNames in that collation's order, both ways
+func RunCollation(t *testing.T, repo domain.LocationRepository, collation string) {
+	t.Helper()
+	for _, name := range CollatedNames {
+		location, _ := domain.NewLocation(name, 6.5, 3.4)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+
+	want := collatedOrder[collation]
+	for _, descending := range []bool{false, true} {
+		locations, err := repo.Find(domain.LocationFilter{}, domain.Page{}, domain.LocationSort{Field: domain.SortByName, Descending: descending})
+		if err != nil {
+			t.Fatalf("Failed to list: %v", err)
+		}
+		var got []string
+		for _, location := range locations {
+			got = append(got, location.Name)
+		}
+		expected := slices.Clone(want)
+		if descending {
+			slices.Reverse(expected)
+		}
+		if !slices.Equal(got, expected) {
+			t.Errorf("%s, descending %v: expected\n  %s\ngot\n  %s", collation, descending, strings.Join(expected, ", "), strings.Join(got, ", "))
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package repotest
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// ConsistencyConfig sizes a consistency run. The same seed always yields the
+// same stations and queries, so a divergence can be replayed exactly.
+type ConsistencyConfig struct {
+	Stations int
+	Queries  int
+	Seed     uint64
+}
+
+// ConsistencyConfigFromEnv overrides defaults with NEAREST_CONSISTENCY_STATIONS,
+// NEAREST_CONSISTENCY_QUERIES and NEAREST_CONSISTENCY_SEED, for soak runs
+func ConsistencyConfigFromEnv(defaults ConsistencyConfig) ConsistencyConfig {
+	cfg := defaults
+	if v, err := strconv.Atoi(os.Getenv("NEAREST_CONSISTENCY_STATIONS")); err == nil && v > 0 {
+		cfg.Stations = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("NEAREST_CONSISTENCY_QUERIES")); err == nil && v > 0 {
+		cfg.Queries = v
+	}
+	if v, err := strconv.ParseUint(os.Getenv("NEAREST_CONSISTENCY_SEED"), 10, 64); err == nil {
+		cfg.Seed = v
+	}
+	return cfg
+}
+
+// NearestFunc is a nearest search under comparison
+type NearestFunc func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error)
+
+// ConsistencyBackend is a nearest search compared against the reference
+type ConsistencyBackend struct {
+	Name    string
+	Nearest NearestFunc
+	// Tolerance is the relative amount by which the chosen station may be
+	// farther than the reference's choice, and its reported distance may
+	// differ from the great-circle distance. 0 demands the same station,
+	// or an exact tie, with distances agreeing to 1e-5.
+	Tolerance float64
+}
+
+// ConsistencyStations generates the stations for cfg: half spread evenly
+// over the globe and half packed into a small region, so that both long
+// searches and near-ties between close stations are exercised
+func ConsistencyStations(cfg ConsistencyConfig) []domain.Location {
+	rng := rand.New(rand.NewPCG(cfg.Seed, 1))
+	stations := make([]domain.Location, cfg.Stations)
+	for i := range stations {
+		var c geospatial.Coordinate
+		if i%2 == 0 {
+			c = randomGlobalPoint(rng)
+		} else {
+			c = geospatial.Coordinate{Latitude: 6.3 + rng.Float64()*0.5, Longitude: 3.2 + rng.Float64()*0.5}
+		}
+		stations[i] = domain.Location{Name: fmt.Sprintf("station-%d", i), Latitude: c.Latitude, Longitude: c.Longitude}
+	}
+	return stations
+}
+
+// SaveStations stores stations in repo
+func SaveStations(t *testing.T, repo domain.LocationRepository, stations []domain.Location) {
+	t.Helper()
+	for _, station := range stations {
+		location := station
+		if err := repo.Save(&location); err != nil {
+			t.Fatalf("Failed to save %s: %v", station.Name, err)
+		}
+	}
+}
+
+// RunNearestConsistency runs cfg.Queries seeded queries against reference
+// and every candidate and fails on the first divergence, reporting the
+// seed, query point and both choices with full coordinates
+func RunNearestConsistency(t *testing.T, cfg ConsistencyConfig, stations []domain.Location, reference ConsistencyBackend, candidates ...ConsistencyBackend) {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(cfg.Seed, 2))
+
+	for i := 0; i < cfg.Queries; i++ {
+		var query geospatial.Coordinate
+		if i%2 == 0 {
+			query = randomGlobalPoint(rng)
+		} else {
+			query = geospatial.Coordinate{Latitude: 6.2 + rng.Float64()*0.7, Longitude: 3.1 + rng.Float64()*0.7}
+		}
+		var exclude []string
+		if i%4 == 3 {
+			exclude = []string{stations[rng.IntN(len(stations))].Name, stations[rng.IntN(len(stations))].Name}
+		}
+
+		want, _, err := reference.Nearest(query.Latitude, query.Longitude, exclude...)
+		if err != nil {
+			t.Fatalf("query %d (seed %d): %s failed: %v", i, cfg.Seed, reference.Name, err)
+		}
+		wantKm := geospatial.HaversineDistance(query, coordinateOf(want)).Kilometers()
+
+		for _, candidate := range candidates {
+			got, reported, err := candidate.Nearest(query.Latitude, query.Longitude, exclude...)
+			if err != nil {
+				t.Fatalf("query %d (seed %d): %s failed: %v", i, cfg.Seed, candidate.Name, err)
+			}
+			gotKm := geospatial.HaversineDistance(query, coordinateOf(got)).Kilometers()
+			distance := reported.Kilometers()
+
+			tolerance := math.Max(candidate.Tolerance, 1e-5)
+			chosenOK := got.Name == want.Name || gotKm <= wantKm*(1+candidate.Tolerance)+1e-9
+			reportedOK := math.Abs(distance-gotKm) <= gotKm*tolerance+1e-6
+			if !chosenOK || !reportedOK {
+				t.Fatalf("query %d (seed %d) at (%.10f, %.10f) excluding %v:\n"+
+					"  %s chose %s at (%.10f, %.10f), %.9f km\n"+
+					"  %s chose %s at (%.10f, %.10f), %.9f km great-circle, %.9f km reported",
+					i, cfg.Seed, query.Latitude, query.Longitude, exclude,
+					reference.Name, want.Name, want.Latitude, want.Longitude, wantKm,
+					candidate.Name, got.Name, got.Latitude, got.Longitude, gotKm, distance)
+			}
+		}
+	}
+}
+
+// randomGlobalPoint is uniform over the sphere's surface
+func randomGlobalPoint(rng *rand.Rand) geospatial.Coordinate {
+	return geospatial.Coordinate{
+		Latitude:  math.Asin(2*rng.Float64()-1) * 180 / math.Pi,
+		Longitude: rng.Float64()*360 - 180,
+	}
+}
+
+func coordinateOf(l *domain.Location) geospatial.Coordinate {
+	return geospatial.Coordinate{Latitude: l.Latitude, Longitude: l.Longitude}
+}
@@ -0,0 +1,91 @@
+package repotest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// FilterSnapshot extends RestoreSnapshot with two locations either side of
+// the antimeridian, all with controlled creation times; Suva has a
+// description
+var FilterSnapshot = append(append([]domain.Location{}, RestoreSnapshot...),
+	domain.Location{ID: "15", Name: "Suva", Latitude: -18.1416, Longitude: 178.4419, CreatedAt: time.Date(2025, 4, 5, 6, 7, 8, 0, time.UTC),
+		Description: "Ferry wharf; entrance on the service road"},
+	domain.Location{ID: "20", Name: "Apia", Latitude: -13.8333, Longitude: -171.7667, CreatedAt: time.Date(2025, 5, 6, 7, 8, 9, 0, time.UTC)},
+)
+
+// RunFind restores FilterSnapshot and checks Find: both created_at bounds
+// are inclusive, name matching ignores case and treats LIKE wildcards
+// literally, descriptions match only when asked, bounding boxes may cross the antimeridian, conditions combine
+// with AND, and sorting and paging apply after filtering
+func RunFind(t *testing.T, repo RestoreRepository) {
+	t.Helper()
+
+	if _, err := repo.RestoreLocations(FilterSnapshot); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	ikeja, yaba, lekki := FilterSnapshot[0].CreatedAt, FilterSnapshot[1].CreatedAt, FilterSnapshot[2].CreatedAt
+	lagos := &domain.BoundingBox{MinLatitude: 6.4, MinLongitude: 3.3, MaxLatitude: 6.55, MaxLongitude: 3.5}
+	pacific := &domain.BoundingBox{MinLatitude: -20, MinLongitude: 170, MaxLatitude: -10, MaxLongitude: -170}
+
+	tests := []struct {
+		name   string
+		filter domain.LocationFilter
+		page   domain.Page
+		order  domain.LocationSort
+		want   []string
+	}{
+		{"zero filter", domain.LocationFilter{}, domain.Page{}, domain.LocationSort{}, []string{"Ikeja", "Yaba", "Lekki", "Suva", "Apia"}},
+		{"after is inclusive", domain.LocationFilter{CreatedAfter: yaba, CreatedBefore: lekki}, domain.Page{}, domain.LocationSort{}, []string{"Yaba", "Lekki"}},
+		{"before is inclusive", domain.LocationFilter{CreatedBefore: yaba}, domain.Page{}, domain.LocationSort{}, []string{"Ikeja", "Yaba"}},
+		{"both bounds", domain.LocationFilter{CreatedAfter: ikeja, CreatedBefore: yaba}, domain.Page{}, domain.LocationSort{}, []string{"Ikeja", "Yaba"}},
+		{"just past a bound", domain.LocationFilter{CreatedAfter: ikeja.Add(time.Second), CreatedBefore: lekki.Add(-time.Second)}, domain.Page{}, domain.LocationSort{}, []string{"Yaba"}},
+		{"no match", domain.LocationFilter{CreatedAfter: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}, domain.Page{}, domain.LocationSort{}, nil},
+		{"name ignores case", domain.LocationFilter{NameContains: "E"}, domain.Page{}, domain.LocationSort{}, []string{"Ikeja", "Lekki"}},
+		{"name wildcards are literal", domain.LocationFilter{NameContains: "%"}, domain.Page{}, domain.LocationSort{}, nil},
+		{"descriptions need the flag", domain.LocationFilter{NameContains: "wharf"}, domain.Page{}, domain.LocationSort{}, nil},
+		{"descriptions with the flag", domain.LocationFilter{NameContains: "WHARF", SearchDescriptions: true}, domain.Page{}, domain.LocationSort{}, []string{"Suva"}},
+		{"flag still matches names", domain.LocationFilter{NameContains: "yab", SearchDescriptions: true}, domain.Page{}, domain.LocationSort{}, []string{"Yaba"}},
+		{"bounding box", domain.LocationFilter{BBox: lagos}, domain.Page{}, domain.LocationSort{}, []string{"Yaba", "Lekki"}},
+		{"antimeridian bounding box", domain.LocationFilter{BBox: pacific}, domain.Page{}, domain.LocationSort{}, []string{"Suva", "Apia"}},
+		{"created and name", domain.LocationFilter{CreatedAfter: yaba, NameContains: "a"}, domain.Page{}, domain.LocationSort{}, []string{"Yaba", "Suva", "Apia"}},
+		{"box and name", domain.LocationFilter{BBox: lagos, NameContains: "yab"}, domain.Page{}, domain.LocationSort{}, []string{"Yaba"}},
+		{"sort by name descending", domain.LocationFilter{}, domain.Page{}, domain.LocationSort{Field: domain.SortByName, Descending: true}, []string{"Yaba", "Suva", "Lekki", "Ikeja", "Apia"}},
+		{"sort by created descending", domain.LocationFilter{BBox: pacific}, domain.Page{}, domain.LocationSort{Field: domain.SortByCreatedAt, Descending: true}, []string{"Apia", "Suva"}},
+		{"page", domain.LocationFilter{}, domain.Page{Offset: 1, Limit: 2}, domain.LocationSort{Field: domain.SortByName}, []string{"Ikeja", "Lekki"}},
+		{"page after filter", domain.LocationFilter{NameContains: "a"}, domain.Page{Offset: 3}, domain.LocationSort{}, []string{"Apia"}},
+		{"after id compares numbers", domain.LocationFilter{AfterID: "7"}, domain.Page{}, domain.LocationSort{}, []string{"Lekki", "Suva", "Apia"}},
+		{"after id and name", domain.LocationFilter{AfterID: "7", NameContains: "a"}, domain.Page{Limit: 1}, domain.LocationSort{}, []string{"Suva"}},
+		{"page past the end", domain.LocationFilter{}, domain.Page{Offset: 10, Limit: 2}, domain.LocationSort{}, nil},
+	}
+
+	for _, tt := range tests {
+		locations, err := repo.Find(tt.filter, tt.page, tt.order)
+		if err != nil {
+			t.Fatalf("%s: failed to list: %v", tt.name, err)
+		}
+		var names []string
+		for _, location := range locations {
+			names = append(names, location.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, names)
+		}
+
+		// The count of a paged finder is that of the unpaged listing
+		finder, ok := repo.(domain.PagedFinder)
+		if !ok {
+			continue
+		}
+		all, err := repo.Find(tt.filter, domain.Page{}, tt.order)
+		if err != nil {
+			t.Fatalf("%s: failed to list: %v", tt.name, err)
+		}
+		if count, err := finder.CountMatching(tt.filter); err != nil || count != len(all) {
+			t.Errorf("%s: expected a count of %d, got %d, %v", tt.name, len(all), count, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RunImports checks that import jobs are claimed once in the order they
+// were created, keep their progress and a cancel request across updates,
+// drop their file when finished, and fail once their worker goes quiet
+func RunImports(t *testing.T, repo domain.JobRepository) {
+	t.Helper()
+	created := time.Date(2025, 9, 6, 9, 0, 0, 0, time.UTC)
+	for i, id := range []string{"first", "second", "third"} {
+		at := created.Add(time.Duration(i) * time.Second)
+		job := &domain.ImportJob{ID: id, Status: domain.ImportPending, Total: 2, CreatedAt: at, UpdatedAt: at}
+		data := []byte("name,latitude,longitude\n" + id + ",6.5,3.3\n")
+		if err := repo.CreateImport(job, data); err != nil {
+			t.Fatalf("Failed to create %s: %v", id, err)
+		}
+		// The file is kept even when the caller reuses its buffer
+		clear(data)
+	}
+	if _, err := repo.FindImport("missing"); !errors.Is(err, domain.ErrImportNotFound) {
+		t.Errorf("Expected ErrImportNotFound, got %v", err)
+	}
+
+	// A pending job is cancelled at once and never claimed
+	cancelled, err := repo.CancelImport("second", created.Add(time.Minute))
+	if err != nil || cancelled.Status != domain.ImportCancelled || cancelled.FinishedAt == nil {
+		t.Fatalf("Expected the pending job cancelled, got %+v (%v)", cancelled, err)
+	}
+
+	claimed := created.Add(2 * time.Minute)
+	job, data, err := repo.ClaimImport(claimed)
+	if err != nil || job == nil || job.ID != "first" || job.Status != domain.ImportRunning || string(data) != "name,latitude,longitude\nfirst,6.5,3.3\n" {
+		t.Fatalf("Expected to claim first with its file, got %+v %q (%v)", job, data, err)
+	}
+	if job.StartedAt == nil || !job.StartedAt.Equal(claimed) {
+		t.Errorf("Expected started at %v, got %v", claimed, job.StartedAt)
+	}
+	third, _, err := repo.ClaimImport(claimed)
+	if err != nil || third == nil || third.ID != "third" {
+		t.Fatalf("Expected to claim third, skipping the cancelled job, got %+v (%v)", third, err)
+	}
+	if none, _, err := repo.ClaimImport(claimed); err != nil || none != nil {
+		t.Fatalf("Expected nothing left to claim, got %+v (%v)", none, err)
+	}
+
+	// A running job is only asked to stop, and learns so on its next update
+	requested, err := repo.CancelImport("first", claimed)
+	if err != nil || requested.Status != domain.ImportRunning || !requested.CancelRequested {
+		t.Fatalf("Expected a cancel request on the running job, got %+v (%v)", requested, err)
+	}
+	job.Processed, job.Imported, job.Failed = 2, 1, 1
+	job.Errors = []domain.ImportRowError{{Row: 3, Name: "Bad", Error: "invalid latitude"}}
+	job.UpdatedAt = claimed.Add(time.Second)
+	if err := repo.UpdateImport(job); err != nil || !job.CancelRequested {
+		t.Fatalf("Expected the update to report the cancel request, got %v (%v)", job.CancelRequested, err)
+	}
+	finished := claimed.Add(2 * time.Second)
+	job.Status, job.UpdatedAt, job.FinishedAt = domain.ImportCancelled, finished, &finished
+	if err := repo.UpdateImport(job); err != nil {
+		t.Fatalf("Failed to finish: %v", err)
+	}
+	found, err := repo.FindImport("first")
+	if err != nil {
+		t.Fatalf("Failed to find: %v", err)
+	}
+	want := []domain.ImportRowError{{Row: 3, Name: "Bad", Error: "invalid latitude"}}
+	if found.Status != domain.ImportCancelled || found.Processed != 2 || found.Imported != 1 || found.Failed != 1 ||
+		!slices.Equal(found.Errors, want) || found.FinishedAt == nil || !found.FinishedAt.Equal(finished) {
+		t.Errorf("Expected the finished job's progress and errors, got %+v", found)
+	}
+	if again, err := repo.CancelImport("first", finished); err != nil || again.Status != domain.ImportCancelled {
+		t.Errorf("Expected a finished job unchanged by cancel, got %+v (%v)", again, err)
+	}
+	if err := repo.UpdateImport(&domain.ImportJob{ID: "missing", Status: domain.ImportRunning}); !errors.Is(err, domain.ErrImportNotFound) {
+		t.Errorf("Expected ErrImportNotFound updating a missing job, got %v", err)
+	}
+
+	// third has not reported since it was claimed
+	failed, err := repo.FailStaleImports(claimed.Add(time.Millisecond), "worker stopped")
+	if err != nil || failed != 1 {
+		t.Fatalf("Expected one stale job failed, got %d (%v)", failed, err)
+	}
+	found, err = repo.FindImport("third")
+	if err != nil || found.Status != domain.ImportFailed || found.Error != "worker stopped" || found.FinishedAt == nil {
+		t.Errorf("Expected third failed as stale, got %+v (%v)", found, err)
+	}
+}
@@ -0,0 +1,82 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RunIntegrityStore seeds rows the domain rules reject through insert,
+// which must bypass validation, then checks StreamLocations yields every
+// row in id order across batches and stops when asked, and RenameLocation
+// keeps names unique
+func RunIntegrityStore(t *testing.T, store domain.IntegrityStore, insert func(name string, latitude, longitude float64)) {
+	t.Helper()
+
+	insert("Ikeja", 6.6018, 3.3515)
+	insert(" Yaba ", 6.5095, 3.3711)
+	insert("Gulf", 0, 0)
+	insert("Lekki", 6.4474, 3.472)
+	insert("Lekki ", 6.4474, 3.472)
+
+	stream := func() []*domain.Location {
+		t.Helper()
+		var locations []*domain.Location
+		for location, err := range store.StreamLocations(context.Background(), 2) {
+			if err != nil {
+				t.Fatalf("Failed to stream: %v", err)
+			}
+			locations = append(locations, location)
+		}
+		return locations
+	}
+
+	locations := stream()
+	var names []string
+	problems := map[string][]string{}
+	for _, location := range locations {
+		names = append(names, location.Name)
+		for _, problem := range domain.IntegrityProblems(location) {
+			problems[problem] = append(problems[problem], location.Name)
+		}
+	}
+	if want := []string{"Ikeja", " Yaba ", "Gulf", "Lekki", "Lekki "}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Expected %q in id order, got %q", want, names)
+	}
+	wantProblems := map[string][]string{
+		domain.IntegrityUntrimmedName: {" Yaba ", "Lekki "},
+		domain.IntegrityNullIsland:    {"Gulf"},
+	}
+	if !reflect.DeepEqual(problems, wantProblems) {
+		t.Errorf("Expected problems %v, got %v", wantProblems, problems)
+	}
+
+	seen := 0
+	for range store.StreamLocations(context.Background(), 2) {
+		seen++
+		if seen == 3 {
+			break
+		}
+	}
+
+	if err := store.RenameLocation(locations[1].ID, "Yaba"); err != nil {
+		t.Errorf("Failed to rename: %v", err)
+	}
+	if err := store.RenameLocation(locations[4].ID, "Lekki"); !errors.Is(err, domain.ErrLocationExists) {
+		t.Errorf("Expected ErrLocationExists renaming onto a taken name, got %v", err)
+	}
+	if err := store.RenameLocation("999", "Nowhere"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound, got %v", err)
+	}
+
+	renamed := stream()
+	if renamed[1].Name != "Yaba" || renamed[1].ID != locations[1].ID || renamed[1].Latitude != locations[1].Latitude {
+		t.Errorf("Expected Yaba renamed in place, got %+v", renamed[1])
+	}
+	if renamed[4].Name != "Lekki " {
+		t.Errorf("Expected the conflicting rename to change nothing, got %+v", renamed[4])
+	}
+}
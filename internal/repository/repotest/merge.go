@@ -0,0 +1,78 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// MergeRepository is a location store that merges duplicates and keeps an
+// audit log of the merges
+type MergeRepository interface {
+	domain.LocationRepository
+	domain.LocationMerger
+	Merges() ([]domain.MergeAudit, error)
+}
+
+// RunMergeAtomicity checks that a merge naming a missing location changes
+// nothing, and that a valid merge deletes every loser and is audited
+func RunMergeAtomicity(t *testing.T, repo MergeRepository) {
+	t.Helper()
+	for _, name := range []string{"Lekki Phase 1", "Lekki Phase I", "Lekki Ph 1"} {
+		location, _ := domain.NewLocation(name, 6.4474, 3.4720)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+
+	_, err := repo.MergeLocations("Lekki Phase 1", []string{"Lekki Phase I", "Lekki Phase One", "Lekki Ph 1"}, "ops")
+	var notFound *domain.MergeNotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "Lekki Phase One" {
+		t.Fatalf("Expected the missing loser to be named, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected the error to wrap ErrLocationNotFound, got %v", err)
+	}
+	all, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("Failed to list locations: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected a failed merge to delete nothing, have %d locations", len(all))
+	}
+	if merges, _ := repo.Merges(); len(merges) != 0 {
+		t.Errorf("Expected a failed merge to leave no audit entry, got %+v", merges)
+	}
+
+	if _, err := repo.MergeLocations("Lekki Phase 1", []string{"Lekki Phase 1"}, "ops"); !errors.Is(err, domain.ErrInvalidMerge) {
+		t.Errorf("Expected ErrInvalidMerge merging the winner into itself, got %v", err)
+	}
+
+	audit, err := repo.MergeLocations("Lekki Phase 1", []string{"Lekki Phase I", "Lekki Ph 1"}, "ops")
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if audit.ID == "" || audit.MergedAt.IsZero() || audit.Actor != "ops" || len(audit.Losers) != 2 {
+		t.Errorf("Unexpected audit entry: %+v", audit)
+	}
+	for _, name := range []string{"Lekki Phase I", "Lekki Ph 1"} {
+		if _, err := repo.FindByName(name); !errors.Is(err, domain.ErrLocationNotFound) {
+			t.Errorf("Expected %s to be deleted, got %v", name, err)
+		}
+	}
+	if _, err := repo.FindByName("Lekki Phase 1"); err != nil {
+		t.Errorf("Expected the winner to remain, got %v", err)
+	}
+
+	merges, err := repo.Merges()
+	if err != nil {
+		t.Fatalf("Failed to list merges: %v", err)
+	}
+	if len(merges) != 1 || merges[0].ID != audit.ID || merges[0].Winner != "Lekki Phase 1" {
+		t.Fatalf("Expected the merge to be audited, got %+v", merges)
+	}
+	if merges[0].Losers[0].Name != "Lekki Phase I" || merges[0].Losers[1].Name != "Lekki Ph 1" {
+		t.Errorf("Expected the audit to keep the losers in order, got %+v", merges[0].Losers)
+	}
+}
@@ -0,0 +1,125 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// PreconditionRepository is a location store whose changes can be guarded
+// by preconditions
+type PreconditionRepository interface {
+	domain.LocationRepository
+	domain.LocationAliaser
+	domain.StockUpdater
+	domain.LocationTransactor
+}
+
+var errRefused = errors.New("refused by precondition")
+
+// RunPreconditions checks that a change is made only when the location as
+// stored passes its checks, in a transaction too, that a refusal comes
+// back as the check returned it, and that the check and change are one
+// step, so guarded updates racing on the same reading do not both apply
+func RunPreconditions(t *testing.T, repo PreconditionRepository) {
+	t.Helper()
+	location, _ := domain.NewLocation("Leeta Yaba", 6.5095, 3.3711)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if _, err := repo.AddAlias("Leeta Yaba", "Leeta Tejuosho"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	if _, err := repo.UpdateStock("Leeta Yaba", domain.StockUpdate{CapacityLitres: litres(30000), StockLitres: litres(0)}); err != nil {
+		t.Fatalf("Failed to set stock: %v", err)
+	}
+
+	var seen *domain.Location
+	refuse := func(current *domain.Location) error {
+		seen = current
+		return errRefused
+	}
+	if _, err := repo.AddAlias("Leeta Tejuosho", "Leeta Sabo", refuse); !errors.Is(err, errRefused) {
+		t.Errorf("Expected the alias refused by its check, got %v", err)
+	}
+	if seen == nil || seen.Name != "Leeta Yaba" || !slices.Equal(seen.Aliases, []string{"Leeta Tejuosho"}) || *seen.CurrentStockLitres != 0 {
+		t.Errorf("Expected the check to see the location as stored, got %+v", seen)
+	}
+	if _, err := repo.RemoveAlias("Leeta Yaba", "Leeta Tejuosho", refuse); !errors.Is(err, errRefused) {
+		t.Errorf("Expected the alias removal refused by its check, got %v", err)
+	}
+	if _, err := repo.UpdateStock("Leeta Yaba", domain.StockUpdate{AdjustLitres: 1}, refuse); !errors.Is(err, errRefused) {
+		t.Errorf("Expected the stock update refused by its check, got %v", err)
+	}
+	if err := repo.Delete("Leeta Yaba", refuse); !errors.Is(err, errRefused) {
+		t.Errorf("Expected the delete refused by its check, got %v", err)
+	}
+	description := "Refused"
+	_, err := repo.ApplyOperations([]domain.LocationOperation{
+		{Type: domain.OperationUpdate, Name: "Leeta Yaba", Changes: domain.LocationChanges{Description: &description}},
+		{Type: domain.OperationDelete, Name: "Leeta Tejuosho", Checks: []domain.Precondition{refuse}},
+	})
+	var opErr *domain.OperationError
+	if !errors.As(err, &opErr) || opErr.Index != 1 || !errors.Is(err, errRefused) {
+		t.Errorf("Expected the transaction refused at its delete, got %v", err)
+	}
+	stored, err := repo.FindByName("Leeta Yaba")
+	if err != nil || !slices.Equal(stored.Aliases, []string{"Leeta Tejuosho"}) || *stored.CurrentStockLitres != 0 || stored.Description != "" {
+		t.Fatalf("Expected refused changes to change nothing, got %+v, %v", stored, err)
+	}
+
+	// Every racer checks the stock it read before adjusting it, so only
+	// one adjustment may apply for each reading
+	const racers = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	applied := 0
+	start := make(chan struct{})
+	for range racers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			read, err := repo.FindByName("Leeta Yaba")
+			if err != nil {
+				t.Errorf("Failed to read: %v", err)
+				return
+			}
+			unchanged := func(current *domain.Location) error {
+				if *current.CurrentStockLitres != *read.CurrentStockLitres {
+					return errRefused
+				}
+				return nil
+			}
+			_, err = repo.UpdateStock("Leeta Yaba", domain.StockUpdate{AdjustLitres: 1}, unchanged)
+			switch {
+			case err == nil:
+				mu.Lock()
+				applied++
+				mu.Unlock()
+			case !errors.Is(err, errRefused):
+				t.Errorf("Expected the update applied or refused, got %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	stored, err = repo.FindByName("Leeta Yaba")
+	if err != nil || applied == 0 || *stored.CurrentStockLitres != float64(applied) {
+		t.Errorf("Expected one litre for each of the %d updates applied, got %+v, %v", applied, stored, err)
+	}
+
+	pass := func(*domain.Location) error { return nil }
+	if _, err := repo.RemoveAlias("Leeta Yaba", "Leeta Tejuosho", pass); err != nil {
+		t.Errorf("Expected the alias removed when its check passes, got %v", err)
+	}
+	if err := repo.Delete("Leeta Yaba", pass); err != nil {
+		t.Errorf("Expected the delete made when its check passes, got %v", err)
+	}
+	if err := repo.Delete("Leeta Yaba", refuse); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected a missing location not found before any check, got %v", err)
+	}
+}
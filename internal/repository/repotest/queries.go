@@ -0,0 +1,68 @@
+package repotest
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RunQueries checks that saved queries are created once, updated in place
+// keeping their creation time, listed by name and deleted
+func RunQueries(t *testing.T, repo domain.QueryRepository) {
+	t.Helper()
+	created := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	for _, name := range []string{"lagos-this-month", "ikeja-open"} {
+		query := &domain.SavedQuery{Name: name, Filter: json.RawMessage(`{"region":"Lagos"}`), CreatedAt: created, UpdatedAt: created}
+		if err := repo.CreateQuery(query); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if err := repo.CreateQuery(&domain.SavedQuery{Name: "ikeja-open", Filter: json.RawMessage(`{}`)}); !errors.Is(err, domain.ErrQueryExists) {
+		t.Errorf("Expected ErrQueryExists for a taken name, got %v", err)
+	}
+
+	updated := created.Add(time.Hour)
+	query := &domain.SavedQuery{Name: "ikeja-open", Filter: json.RawMessage(`{"name_contains":"ikeja","open_now":true}`), UpdatedAt: updated}
+	if err := repo.UpdateQuery(query); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if !query.CreatedAt.Equal(created) {
+		t.Errorf("Expected the update to report the original creation time, got %v", query.CreatedAt)
+	}
+	if err := repo.UpdateQuery(&domain.SavedQuery{Name: "missing", Filter: json.RawMessage(`{}`), UpdatedAt: updated}); !errors.Is(err, domain.ErrQueryNotFound) {
+		t.Errorf("Expected ErrQueryNotFound updating a missing query, got %v", err)
+	}
+
+	found, err := repo.FindQuery("ikeja-open")
+	if err != nil {
+		t.Fatalf("Failed to find: %v", err)
+	}
+	var filter map[string]any
+	if err := json.Unmarshal(found.Filter, &filter); err != nil || filter["name_contains"] != "ikeja" || filter["open_now"] != true {
+		t.Errorf("Expected the updated filter, got %s (%v)", found.Filter, err)
+	}
+	if !found.CreatedAt.Equal(created) || !found.UpdatedAt.Equal(updated) {
+		t.Errorf("Expected created %v and updated %v, got %v and %v", created, updated, found.CreatedAt, found.UpdatedAt)
+	}
+
+	queries, err := repo.ListQueries()
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	if len(queries) != 2 || queries[0].Name != "ikeja-open" || queries[1].Name != "lagos-this-month" {
+		t.Fatalf("Expected both queries ordered by name, got %+v", queries)
+	}
+
+	if err := repo.DeleteQuery("ikeja-open"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := repo.FindQuery("ikeja-open"); !errors.Is(err, domain.ErrQueryNotFound) {
+		t.Errorf("Expected ErrQueryNotFound after delete, got %v", err)
+	}
+	if err := repo.DeleteQuery("ikeja-open"); !errors.Is(err, domain.ErrQueryNotFound) {
+		t.Errorf("Expected ErrQueryNotFound deleting twice, got %v", err)
+	}
+}
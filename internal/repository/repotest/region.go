@@ -0,0 +1,66 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RegionRepository is a location store that searches one region at a time
+type RegionRepository interface {
+	domain.LocationRepository
+	domain.RegionalNearestFinder
+}
+
+// RunRegions checks that region-scoped searches and listings never return
+// a location from another region, however near it is
+func RunRegions(t *testing.T, repo RegionRepository) {
+	t.Helper()
+	stations := []struct {
+		name, region        string
+		latitude, longitude float64
+	}{
+		{"Leeta Ikeja", "Lagos", 6.6018, 3.3515},
+		{"Leeta Lekki", "Lagos", 6.4474, 3.4720},
+		// Mislabelled on purpose: nearest to the query point of all
+		{"Leeta Wuse Depot", "Abuja", 6.5010, 3.3700},
+		{"Leeta Unassigned", "", 6.5020, 3.3700},
+	}
+	for _, s := range stations {
+		location, _ := domain.NewLocation(s.name, s.latitude, s.longitude)
+		location.Region = s.region
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", s.name, err)
+		}
+	}
+
+	if nearest, _, err := repo.FindNearest(6.5, 3.37); err != nil || nearest.Name != "Leeta Wuse Depot" {
+		t.Fatalf("Expected the unscoped search to find the nearer Abuja station, got %v, %v", nearest, err)
+	}
+	nearest, _, err := repo.FindNearestInRegion("Lagos", 6.5, 3.37)
+	if err != nil || nearest.Name != "Leeta Ikeja" || nearest.Region != "Lagos" {
+		t.Fatalf("Expected Leeta Ikeja in Lagos, got %v, %v", nearest, err)
+	}
+	if nearest, _, err := repo.FindNearestInRegion("Lagos", 6.5, 3.37, "Leeta Ikeja"); err != nil || nearest.Name != "Leeta Lekki" {
+		t.Errorf("Expected Leeta Lekki with Leeta Ikeja excluded, got %v, %v", nearest, err)
+	}
+	if _, _, err := repo.FindNearestInRegion("PH", 6.5, 3.37); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound in an empty region, got %v", err)
+	}
+
+	lagos, err := repo.Find(domain.LocationFilter{Region: "Lagos"}, domain.Page{}, domain.LocationSort{Field: domain.SortByName})
+	if err != nil {
+		t.Fatalf("Failed to list Lagos: %v", err)
+	}
+	if len(lagos) != 2 || lagos[0].Name != "Leeta Ikeja" || lagos[1].Name != "Leeta Lekki" {
+		t.Errorf("Expected the two Lagos stations, got %v", lagos)
+	}
+
+	if err := repo.Delete("Leeta Ikeja"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if nearest, _, err := repo.FindNearestInRegion("Lagos", 6.5, 3.37); err != nil || nearest.Name != "Leeta Lekki" {
+		t.Errorf("Expected a deleted station to leave its region, got %v, %v", nearest, err)
+	}
+}
@@ -0,0 +1,96 @@
+package repotest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RestoreRepository is a location store that restores snapshots
+type RestoreRepository interface {
+	domain.LocationRepository
+	domain.LocationRestorer
+}
+
+// RestoreSnapshot has gaps in its IDs, as a snapshot of a store with
+// deletions would
+var RestoreSnapshot = []domain.Location{
+	{ID: "3", Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515, CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+	{ID: "7", Name: "Yaba", Latitude: 6.5095, Longitude: 3.3711, CreatedAt: time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC),
+		Attachments: []domain.Attachment{{URL: "https://cdn.leeta.ng/yaba.jpg", Kind: domain.AttachmentPhoto}}, CreatedBy: "fleet-app"},
+	{ID: "12", Name: "Lekki", Latitude: 6.4474, Longitude: 3.472, CreatedAt: time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)},
+}
+
+// RunRestore restores RestoreSnapshot into an empty store, checks the IDs,
+// creation times, attachments and owners survive and that later creates
+// do not collide and keep their owner, then merges a second snapshot and checks every conflict is
+// reported
+func RunRestore(t *testing.T, repo RestoreRepository) {
+	t.Helper()
+
+	result, err := repo.RestoreLocations(RestoreSnapshot)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if result.Restored != len(RestoreSnapshot) || len(result.Conflicts) != 0 {
+		t.Fatalf("Expected a clean restore, got %+v", result)
+	}
+	for _, want := range RestoreSnapshot {
+		got, err := repo.FindByID(want.ID)
+		if err != nil {
+			t.Fatalf("Expected %s under ID %s, got %v", want.Name, want.ID, err)
+		}
+		if got.Name != want.Name || got.Latitude != want.Latitude || got.Longitude != want.Longitude || !got.CreatedAt.Equal(want.CreatedAt) ||
+			!reflect.DeepEqual(got.Attachments, want.Attachments) || got.CreatedBy != want.CreatedBy {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+
+	created, _ := domain.NewLocation("Ajah", 6.4698, 3.5852)
+	created.CreatedBy = "partner"
+	if err := repo.Save(created); err != nil {
+		t.Fatalf("Failed to create after restore: %v", err)
+	}
+	if created.ID != "13" {
+		t.Errorf("Expected the next ID to follow the largest restored ID, got %s", created.ID)
+	}
+	if got, err := repo.FindByName("Ajah"); err != nil || got.CreatedBy != "partner" {
+		t.Errorf("Expected the owner stored with the location, got %+v (%v)", got, err)
+	}
+
+	result, err = repo.RestoreLocations([]domain.Location{
+		{ID: "7", Name: "Yaba Annex", Latitude: 6.51, Longitude: 3.37},
+		{ID: "20", Name: "Ikeja", Latitude: 6.6, Longitude: 3.35},
+		{ID: "21", Name: "Surulere", Latitude: 6.5, Longitude: 3.35},
+		{ID: "21", Name: "Surulere Two", Latitude: 6.5, Longitude: 3.36},
+		{ID: "abc", Name: "Bad ID", Latitude: 6.5, Longitude: 3.35},
+		{ID: "22", Name: "Off the map", Latitude: 91, Longitude: 3.35},
+		{ID: "30", Name: "Ikoyi", Latitude: 6.45, Longitude: 3.43},
+	})
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	wantConflicts := []domain.RestoreConflict{
+		{Index: 0, ID: "7", Name: "Yaba Annex", Reason: domain.RestoreIDExists},
+		{Index: 1, ID: "20", Name: "Ikeja", Reason: domain.RestoreNameExists},
+		{Index: 3, ID: "21", Name: "Surulere Two", Reason: domain.RestoreIDExists},
+		{Index: 4, ID: "abc", Name: "Bad ID", Reason: domain.RestoreInvalid},
+		{Index: 5, ID: "22", Name: "Off the map", Reason: domain.RestoreInvalid},
+	}
+	if result.Restored != 2 || !reflect.DeepEqual(result.Conflicts, wantConflicts) {
+		t.Errorf("Expected 2 restored and conflicts %+v, got %+v", wantConflicts, result)
+	}
+	if location, err := repo.FindByID("7"); err != nil || location.Name != "Yaba" {
+		t.Errorf("Expected a conflicting record to leave ID 7 alone, got %+v, %v", location, err)
+	}
+
+	created, _ = domain.NewLocation("Oshodi", 6.5536, 3.3436)
+	if err := repo.Save(created); err != nil {
+		t.Fatalf("Failed to create after merge restore: %v", err)
+	}
+	if created.ID != "31" {
+		t.Errorf("Expected the next ID to follow the merged IDs, got %s", created.ID)
+	}
+}
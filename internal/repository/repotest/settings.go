@@ -0,0 +1,35 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// RunSettings checks that an empty store reports no settings and that
+// saving replaces the one stored set
+func RunSettings(t *testing.T, repo domain.SettingsRepository) {
+	t.Helper()
+	if _, err := repo.FindSearchSettings(); !errors.Is(err, domain.ErrSettingsNotFound) {
+		t.Fatalf("Expected ErrSettingsNotFound before any save, got %v", err)
+	}
+
+	saved := time.Date(2025, 9, 2, 9, 0, 0, 0, time.UTC)
+	for _, want := range []domain.SearchSettings{
+		{SpeedKmh: 30, MaxDistanceKm: 25, UpdatedAt: saved},
+		{SpeedKmh: 0, MaxDistanceKm: 12.5, UpdatedAt: saved.Add(time.Hour)},
+	} {
+		if err := repo.SaveSearchSettings(&want); err != nil {
+			t.Fatalf("Failed to save: %v", err)
+		}
+		got, err := repo.FindSearchSettings()
+		if err != nil {
+			t.Fatalf("Failed to find: %v", err)
+		}
+		if got.SpeedKmh != want.SpeedKmh || got.MaxDistanceKm != want.MaxDistanceKm || !got.UpdatedAt.Equal(want.UpdatedAt) {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package repotest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// StockRepository is a location store that tracks station stock
+type StockRepository interface {
+	domain.LocationRepository
+	domain.StockUpdater
+	domain.StockedNearestFinder
+	domain.BudgetedStockedFinder
+}
+
+func litres(value float64) *float64 {
+	return &value
+}
+
+// RunStock checks that stock updates are validated against the stored
+// values, that concurrent adjustments all apply, and that stocked searches
+// pass over nearer stations holding too little, counting them against a
+// scan budget
+func RunStock(t *testing.T, repo StockRepository) {
+	t.Helper()
+	stations := []struct {
+		name                string
+		latitude, longitude float64
+	}{
+		{"Leeta Yaba", 6.5095, 3.3711},
+		{"Leeta Unknown", 6.5100, 3.3720},
+		{"Leeta Ikeja", 6.6018, 3.3515},
+	}
+	for _, s := range stations {
+		location, _ := domain.NewLocation(s.name, s.latitude, s.longitude)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", s.name, err)
+		}
+	}
+
+	updated, err := repo.UpdateStock("Leeta Yaba", domain.StockUpdate{CapacityLitres: litres(30000), StockLitres: litres(1200)})
+	if err != nil || *updated.CapacityLitres != 30000 || *updated.CurrentStockLitres != 1200 {
+		t.Fatalf("Expected the stock set, got %+v, %v", updated, err)
+	}
+	if _, err := repo.UpdateStock("Leeta Ikeja", domain.StockUpdate{CapacityLitres: litres(33000), StockLitres: litres(9000)}); err != nil {
+		t.Fatalf("Failed to set the Ikeja stock: %v", err)
+	}
+
+	for name, update := range map[string]domain.StockUpdate{
+		"above capacity":    {StockLitres: litres(30001)},
+		"below zero":        {AdjustLitres: -1201},
+		"capacity too low":  {CapacityLitres: litres(1000)},
+		"negative capacity": {CapacityLitres: litres(-1)},
+	} {
+		if _, err := repo.UpdateStock("Leeta Yaba", update); !errors.Is(err, domain.ErrInvalidStock) {
+			t.Errorf("Expected %s refused, got %v", name, err)
+		}
+	}
+	if _, err := repo.UpdateStock("Leeta Unknown", domain.StockUpdate{AdjustLitres: 500}); !errors.Is(err, domain.ErrInvalidStock) {
+		t.Errorf("Expected adjusting an unknown stock refused, got %v", err)
+	}
+	if _, err := repo.UpdateStock("Leeta Nowhere", domain.StockUpdate{StockLitres: litres(1)}); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected an unknown location not found, got %v", err)
+	}
+	if location, _ := repo.FindByName("Leeta Yaba"); *location.CurrentStockLitres != 1200 || *location.CapacityLitres != 30000 {
+		t.Errorf("Expected refused updates to change nothing, got %+v", location)
+	}
+
+	// Yaba and the station of unknown stock are both nearer than Ikeja
+	result, err := repo.FindNearestStocked("", 5000, 6.5, 3.37)
+	if err != nil || result.Location.Name != "Leeta Ikeja" {
+		t.Fatalf("Expected the stocked search to pass over nearer stations, got %+v, %v", result, err)
+	}
+	if result, err := repo.FindNearestStocked("", 1000, 6.5, 3.37); err != nil || result.Location.Name != "Leeta Yaba" {
+		t.Errorf("Expected Yaba to hold enough for a lower minimum, got %+v, %v", result, err)
+	}
+	if _, err := repo.FindNearestStocked("", 10000, 6.5, 3.37); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected no station to hold 10000 litres, got %v", err)
+	}
+	if _, err := repo.FindNearestStocked("", 5000, 6.5, 3.37, "Leeta Ikeja"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected excluded stations passed over too, got %v", err)
+	}
+
+	// The stations holding too little count against the budget
+	if _, err := repo.FindNearestStockedWithin(domain.ScanBudget{Hard: 2}, "", 5000, 6.5, 3.37); !errors.Is(err, domain.ErrScanBudgetExceeded) {
+		t.Errorf("Expected a stocked search past the hard limit to stop, got %v", err)
+	}
+	if result, err := repo.FindNearestStockedWithin(domain.ScanBudget{Hard: 3}, "", 5000, 6.5, 3.37); err != nil || result.Location.Name != "Leeta Ikeja" {
+		t.Errorf("Expected Ikeja within a budget of every station, got %+v, %v", result, err)
+	}
+	if _, err := repo.FindNearestStockedWithin(domain.ScanBudget{Hard: 3}, "", 10000, 6.5, 3.37); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected no station to hold 10000 litres within the budget, got %v", err)
+	}
+
+	// Sales reported at once must all be counted
+	const sales = 20
+	var wg sync.WaitGroup
+	for range sales {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.UpdateStock("Leeta Ikeja", domain.StockUpdate{AdjustLitres: -100}); err != nil {
+				t.Errorf("Failed to adjust the stock: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if location, _ := repo.FindByName("Leeta Ikeja"); *location.CurrentStockLitres != 9000-sales*100 {
+		t.Errorf("Expected %d litres after the sales, got %v", 9000-sales*100, *location.CurrentStockLitres)
+	}
+}
@@ -0,0 +1,119 @@
+package repotest
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// SuggestRepository is a location store that suggests names
+type SuggestRepository interface {
+	domain.LocationRepository
+	domain.LocationSuggester
+}
+
+func suggestedNames(suggestions []domain.Suggestion) []string {
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = s.Location.Name
+	}
+	return names
+}
+
+// RunSuggest checks how suggestions are matched and ranked
+func RunSuggest(t *testing.T, repo SuggestRepository) {
+	t.Helper()
+	// Victoria Island is the query point; Ikeja is about 17 km away
+	victoriaIsland := geospatial.Coordinate{Latitude: 6.4281, Longitude: 3.4219}
+	for _, l := range []struct {
+		name      string
+		latitude  float64
+		longitude float64
+	}{
+		{"Leeta Lekki", 6.4474, 3.4723},
+		{"Leeta Ikeja", 6.6018, 3.3515},
+		{"Lekki Phase 1", 6.4478, 3.4737},
+		{"Lekky Road", 6.4698, 3.5852},
+		{"Yaba", 6.5095, 3.3711},
+	} {
+		if err := repo.Save(&domain.Location{Name: l.name, Latitude: l.latitude, Longitude: l.longitude}); err != nil {
+			t.Fatalf("Failed to save %s: %v", l.name, err)
+		}
+	}
+
+	query := domain.SuggestQuery{Query: "leeta", Near: &victoriaIsland, Limit: 10, DistanceWeight: 0.3}
+	suggestions, err := repo.SuggestLocations(query)
+	if err != nil {
+		t.Fatalf("Failed to suggest: %v", err)
+	}
+	// Both names start with the query, so the nearer ranks first
+	if got, want := suggestedNames(suggestions), []string{"Leeta Lekki", "Leeta Ikeja"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	if d := suggestions[1].Distance.Kilometers(); d < 15 || d > 25 {
+		t.Errorf("Expected Leeta Ikeja about 20 km away, got %v", suggestions[1].Distance)
+	}
+	if !(suggestions[0].Score > suggestions[1].Score) || suggestions[0].Score > 1 {
+		t.Errorf("Expected scores falling from at most 1, got %g and %g", suggestions[0].Score, suggestions[1].Score)
+	}
+
+	// A name starting with the query beats a word starting with it, which
+	// beats a name that only resembles it, when distance does not count
+	query = domain.SuggestQuery{Query: "lekki", Near: &victoriaIsland, Limit: 10}
+	suggestions, err = repo.SuggestLocations(query)
+	if err != nil {
+		t.Fatalf("Failed to suggest: %v", err)
+	}
+	if got, want := suggestedNames(suggestions), []string{"Lekki Phase 1", "Leeta Lekki", "Lekky Road"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	if suggestions[0].Score != domain.SuggestPrefixScore || suggestions[1].Score != domain.SuggestWordPrefixScore {
+		t.Errorf("Expected prefix and word scores, got %g and %g", suggestions[0].Score, suggestions[1].Score)
+	}
+
+	// With all the weight on distance, the nearest match leads
+	query.DistanceWeight = 1
+	suggestions, _ = repo.SuggestLocations(query)
+	if got := suggestedNames(suggestions); len(got) != 3 || got[0] != "Leeta Lekki" {
+		t.Errorf("Expected Leeta Lekki nearest first, got %v", got)
+	}
+
+	query = domain.SuggestQuery{Query: "LEKKI", Near: &victoriaIsland, Limit: 1}
+	if suggestions, _ := repo.SuggestLocations(query); len(suggestions) != 1 || suggestions[0].Location.Name != "Lekki Phase 1" {
+		t.Errorf("Expected the limit and case to be ignored, got %v", suggestedNames(suggestions))
+	}
+
+	// LIKE wildcards match literally
+	for _, q := range []string{"%", "_aba", "zzz"} {
+		suggestions, err := repo.SuggestLocations(domain.SuggestQuery{Query: q, Near: &victoriaIsland, Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to suggest %q: %v", q, err)
+		}
+		if len(suggestions) != 0 {
+			t.Errorf("Expected no suggestions for %q, got %v", q, suggestedNames(suggestions))
+		}
+	}
+}
+
+// SeedSuggestions saves n locations around Lagos named from a small
+// vocabulary, so names share words the way station names do
+func SeedSuggestions(repo domain.LocationRepository, n int) error {
+	areas := []string{"Lekki", "Ikeja", "Yaba", "Surulere", "Ajah", "Ikoyi", "Festac", "Apapa", "Magodo", "Gbagada"}
+	kinds := []string{"Station", "Depot", "Hub", "Express", "Junction"}
+	rng := rand.New(rand.NewSource(1))
+	for i := range n {
+		location := &domain.Location{
+			Name:      fmt.Sprintf("%s %s %d", areas[rng.Intn(len(areas))], kinds[rng.Intn(len(kinds))], i),
+			Latitude:  6.4 + rng.Float64()*0.4,
+			Longitude: 3.2 + rng.Float64()*0.5,
+		}
+		if err := repo.Save(location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
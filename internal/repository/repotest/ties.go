@@ -0,0 +1,91 @@
+package repotest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// TiedStations put four stations at one point and a fifth just beyond it.
+// They are saved in an order that matches neither their names nor, for
+// the lowercase name, a case-insensitive order, so only the documented
+// tie-break of name in byte order, then ID, gives TiedOrder.
+var TiedStations = []domain.Location{
+	{Name: "Yaba Depot", Latitude: 6.5095, Longitude: 3.3711, Region: "lagos"},
+	{Name: "leeta yaba", Latitude: 6.5095, Longitude: 3.3711, Region: "lagos"},
+	{Name: "Sabo", Latitude: 6.5101, Longitude: 3.3711, Region: "lagos"},
+	{Name: "Yaba Central", Latitude: 6.5095, Longitude: 3.3711, Region: "lagos"},
+	{Name: "Yaba Annex", Latitude: 6.5095, Longitude: 3.3711, Region: "lagos"},
+}
+
+// TiedOrder is the order nearest searches from south of TiedStations find
+// them in, each excluding those found before
+var TiedOrder = []string{"Yaba Annex", "Yaba Central", "Yaba Depot", "leeta yaba", "Sabo"}
+
+// RunNearestTieBreak saves TiedStations into repo and walks the nearest
+// searches from a point south of them several times, checking every walk
+// finds TiedOrder, and within the region too when repo searches regions.
+// A radius search, when repo has one, must list them in TiedOrder as well.
+func RunNearestTieBreak(t *testing.T, repo domain.LocationRepository) {
+	t.Helper()
+	SaveStations(t, repo, TiedStations)
+
+	walk := func(find NearestFunc) []string {
+		t.Helper()
+		var found []string
+		for range TiedStations {
+			location, _, err := find(6.5, 3.3711, found...)
+			if err != nil {
+				t.Fatalf("Failed nearest search excluding %v: %v", found, err)
+			}
+			found = append(found, location.Name)
+		}
+		return found
+	}
+
+	for range 5 {
+		if got := walk(repo.FindNearest); !slices.Equal(got, TiedOrder) {
+			t.Fatalf("Expected ties in the order %v, got %v", TiedOrder, got)
+		}
+	}
+
+	if regional, ok := repo.(domain.RegionalNearestFinder); ok {
+		inRegion := func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+			return regional.FindNearestInRegion("lagos", latitude, longitude, exclude...)
+		}
+		if got := walk(inRegion); !slices.Equal(got, TiedOrder) {
+			t.Fatalf("Expected ties in the region in the order %v, got %v", TiedOrder, got)
+		}
+	}
+
+	finder, ok := repo.(domain.RadiusFinder)
+	if !ok {
+		return
+	}
+	// The tied stations are about 1056 m from the query point and Sabo
+	// about 1123 m
+	for _, tt := range []struct {
+		radiusM float64
+		limit   int
+		want    []string
+	}{
+		{2000, 0, TiedOrder},
+		{1100, 0, TiedOrder[:4]},
+		{2000, 2, TiedOrder[:2]},
+		{1000, 0, nil},
+	} {
+		results, err := finder.FindWithinRadius(6.5, 3.3711, geospatial.Meters(tt.radiusM), tt.limit)
+		if err != nil {
+			t.Fatalf("Failed radius search: %v", err)
+		}
+		var got []string
+		for _, result := range results {
+			got = append(got, result.Location.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Expected %v within %v m, limit %d, got %v", tt.want, tt.radiusM, tt.limit, got)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// TransactionRepository is a location store that applies operations
+// atomically and logs its changes
+type TransactionRepository interface {
+	domain.LocationRepository
+	domain.LocationAliaser
+	domain.LocationTransactor
+	domain.ChangeLog
+}
+
+func createOp(name string, latitude, longitude float64) domain.LocationOperation {
+	return domain.LocationOperation{Type: domain.OperationCreate, Location: &domain.Location{Name: name, Latitude: latitude, Longitude: longitude}}
+}
+
+func strPtr(s string) *string { return &s }
+
+func floatPtr(f float64) *float64 { return &f }
+
+// RunTransactions checks that mixed operations apply in order, each seeing
+// the ones before it, and that a failure part way through keeps nothing
+func RunTransactions(t *testing.T, repo TransactionRepository) {
+	t.Helper()
+	for _, name := range []string{"Depot Ikeja Old", "Yaba"} {
+		location, _ := domain.NewLocation(name, 6.5095, 3.3711)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+	if _, err := repo.AddAlias("Yaba", "Sabo"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	yaba, _ := repo.FindByName("Yaba")
+	before, _ := repo.ChangesSince(0, 1)
+
+	results, err := repo.ApplyOperations([]domain.LocationOperation{
+		createOp("Depot Ikeja", 6.6018, 3.3515),
+		createOp("Ikeja Satellite 1", 6.6059, 3.3491),
+		{Type: domain.OperationUpdate, Name: "Sabo", Changes: domain.LocationChanges{Name: strPtr("Yaba Central"), Region: strPtr("lagos"), Description: strPtr("Moved")}},
+		{Type: domain.OperationDelete, Name: "Depot Ikeja Old"},
+		{Type: domain.OperationUpdate, Name: "Ikeja Satellite 1", Changes: domain.LocationChanges{Latitude: floatPtr(6.61)}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	types := make([]domain.OperationType, len(results))
+	for i, result := range results {
+		types[i] = result.Type
+	}
+	wantTypes := []domain.OperationType{domain.OperationCreate, domain.OperationCreate, domain.OperationUpdate, domain.OperationDelete, domain.OperationUpdate}
+	if !slices.Equal(types, wantTypes) {
+		t.Fatalf("Expected results %v in order, got %+v", wantTypes, results)
+	}
+	if results[0].Location.ID == "" || results[0].Location.CreatedAt.IsZero() {
+		t.Errorf("Expected the created location with its ID and creation time, got %+v", results[0].Location)
+	}
+	if results[3].Location.Name != "Depot Ikeja Old" {
+		t.Errorf("Expected the deleted location as it was, got %+v", results[3].Location)
+	}
+	if satellite := results[4].Location; satellite.ID != results[1].Location.ID || satellite.Latitude != 6.61 || satellite.Longitude != 3.3491 {
+		t.Errorf("Expected the satellite created earlier to be updated, got %+v", satellite)
+	}
+
+	renamed, err := repo.FindByName("Yaba Central")
+	if err != nil {
+		t.Fatalf("Failed to find the renamed location: %v", err)
+	}
+	if renamed.ID != yaba.ID || renamed.Region != "lagos" || renamed.Description != "Moved" || !slices.Equal(renamed.Aliases, []string{"Sabo"}) {
+		t.Errorf("Expected Yaba renamed in place with its alias kept, got %+v", renamed)
+	}
+	if _, err := repo.FindByName("Yaba"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected the old name to be free, got %v", err)
+	}
+	if _, err := repo.FindByName("Depot Ikeja Old"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected Depot Ikeja Old deleted, got %v", err)
+	}
+	if count, _ := repo.Count(); count != 3 {
+		t.Errorf("Expected 3 locations, got %d", count)
+	}
+
+	feed, err := repo.ChangesSince(before.Latest, 100)
+	if err != nil {
+		t.Fatalf("Failed to read changes: %v", err)
+	}
+	var logged []string
+	for _, change := range feed.Changes {
+		logged = append(logged, fmt.Sprintf("%s %s", change.Action, change.Name))
+	}
+	wantLogged := []string{
+		"created Depot Ikeja", "created Ikeja Satellite 1",
+		"deleted Yaba", "updated Yaba Central",
+		"deleted Depot Ikeja Old", "updated Ikeja Satellite 1",
+	}
+	if !slices.Equal(logged, wantLogged) {
+		t.Errorf("Expected changes %v, got %v", wantLogged, logged)
+	}
+
+	// The last operation fails, so neither the create nor the update
+	// before it is kept
+	_, err = repo.ApplyOperations([]domain.LocationOperation{
+		createOp("Ikeja Satellite 2", 6.5966, 3.3421),
+		{Type: domain.OperationUpdate, Name: "Yaba Central", Changes: domain.LocationChanges{Description: strPtr("Changed again")}},
+		createOp("Depot Ikeja", 6.6, 3.35),
+	})
+	var opErr *domain.OperationError
+	if !errors.As(err, &opErr) || opErr.Index != 2 || !errors.Is(err, domain.ErrLocationExists) {
+		t.Fatalf("Expected operation 2 to fail with ErrLocationExists, got %v", err)
+	}
+	_, err = repo.ApplyOperations([]domain.LocationOperation{
+		{Type: domain.OperationDelete, Name: "Ikeja Satellite 1"},
+		{Type: domain.OperationUpdate, Name: "Depot Ikeja", Changes: domain.LocationChanges{Name: strPtr("Sabo")}},
+	})
+	var taken *domain.NameTakenError
+	if !errors.As(err, &opErr) || opErr.Index != 1 || !errors.As(err, &taken) || taken.Owner != "Yaba Central" {
+		t.Fatalf("Expected operation 1 to fail renaming onto an alias, got %v", err)
+	}
+	_, err = repo.ApplyOperations([]domain.LocationOperation{
+		{Type: domain.OperationDelete, Name: "Ikeja Satellite 1"},
+		{Type: domain.OperationDelete, Name: "Ikeja Satellite 1"},
+	})
+	if !errors.As(err, &opErr) || opErr.Index != 1 || !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Fatalf("Expected deleting twice to fail at operation 1, got %v", err)
+	}
+
+	if _, err := repo.FindByName("Ikeja Satellite 2"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected the rolled back create to be gone, got %v", err)
+	}
+	if _, err := repo.FindByName("Ikeja Satellite 1"); err != nil {
+		t.Errorf("Expected the rolled back delete to keep Ikeja Satellite 1, got %v", err)
+	}
+	if central, _ := repo.FindByName("Yaba Central"); central == nil || central.Description != "Moved" {
+		t.Errorf("Expected the rolled back update to keep the description, got %+v", central)
+	}
+	if count, _ := repo.Count(); count != 3 {
+		t.Errorf("Expected 3 locations after the failures, got %d", count)
+	}
+	if after, _ := repo.ChangesSince(0, 1); after.Latest != feed.Latest {
+		t.Errorf("Expected failed transactions to log nothing, latest went from %d to %d", feed.Latest, after.Latest)
+	}
+
+	// Aliases and attachments are replaced as a whole, and an alias
+	// another location holds is refused
+	_, err = repo.ApplyOperations([]domain.LocationOperation{
+		{Type: domain.OperationUpdate, Name: "Yaba Central", Changes: domain.LocationChanges{Aliases: &[]string{"Depot Ikeja"}}},
+	})
+	if !errors.As(err, &taken) || taken.Owner != "Depot Ikeja" {
+		t.Fatalf("Expected an alias naming another location to be refused, got %v", err)
+	}
+	attachments := []domain.Attachment{{URL: "https://cdn.leeta.ng/yaba.jpg", Kind: "photo"}}
+	results, err = repo.ApplyOperations([]domain.LocationOperation{
+		{Type: domain.OperationUpdate, Name: "Sabo", Changes: domain.LocationChanges{
+			Aliases: &[]string{"Yaba Market", "Yaba"}, Attachments: &attachments,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to replace aliases: %v", err)
+	}
+	if central := results[0].Location; central.ID != yaba.ID || !slices.Equal(central.Aliases, []string{"Yaba", "Yaba Market"}) ||
+		!slices.Equal(central.Attachments, attachments) {
+		t.Errorf("Expected the aliases and attachments replaced in place, got %+v", central)
+	}
+	if _, err := repo.FindByName("Sabo"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected the dropped alias to be free, got %v", err)
+	}
+	if found, err := repo.FindByName("Yaba"); err != nil || found.ID != yaba.ID || !slices.Equal(found.Attachments, attachments) {
+		t.Errorf("Expected the new alias to find Yaba Central, got %+v, %v", found, err)
+	}
+}
+
+// RunTransactionRace creates a depot with its satellites in a transaction
+// while a single create competes for the depot's name. Exactly one gets
+// the name, and a losing transaction leaves no satellite behind.
+func RunTransactionRace(t *testing.T, repo TransactionRepository) {
+	t.Helper()
+	const rounds = 20
+	for round := range rounds {
+		depot := fmt.Sprintf("Depot %d", round)
+		ops := []domain.LocationOperation{createOp(depot, 6.45, 3.39)}
+		for i := 1; i <= 3; i++ {
+			ops = append(ops, createOp(fmt.Sprintf("%s Satellite %d", depot, i), 6.45+float64(i)/100, 3.39))
+		}
+
+		var wg sync.WaitGroup
+		var txErr, saveErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, txErr = repo.ApplyOperations(ops)
+		}()
+		go func() {
+			defer wg.Done()
+			location, _ := domain.NewLocation(depot, 6.46, 3.4)
+			saveErr = repo.Save(location)
+		}()
+		wg.Wait()
+
+		if (txErr == nil) == (saveErr == nil) {
+			t.Fatalf("Round %d: expected exactly one create of %s to succeed, got %v and %v", round, depot, txErr, saveErr)
+		}
+		if txErr != nil && !errors.Is(txErr, domain.ErrLocationExists) {
+			t.Fatalf("Round %d: expected the transaction to lose with ErrLocationExists, got %v", round, txErr)
+		}
+		for _, op := range ops[1:] {
+			_, err := repo.FindByName(op.Location.Name)
+			if (err == nil) != (txErr == nil) {
+				t.Errorf("Round %d: expected %s to exist only if the transaction won, got %v", round, op.Location.Name, err)
+			}
+		}
+	}
+}
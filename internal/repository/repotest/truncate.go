@@ -0,0 +1,98 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// TruncateRepository is a location store that can be emptied at once and
+// keeps an audit log of it
+type TruncateRepository interface {
+	domain.LocationRepository
+	domain.LocationAliaser
+	domain.ChangeLog
+	domain.LocationTruncator
+	Truncations() ([]domain.TruncateAudit, error)
+}
+
+// RunTruncate checks that truncating removes every location and alias,
+// logs a deletion of each after the earlier changes, is audited with the
+// count, reports the locations removed and never reuses their IDs
+func RunTruncate(t *testing.T, repo TruncateRepository) {
+	t.Helper()
+	var ids []string
+	for _, name := range []string{"Leeta Ikeja", "Leeta Yaba", "Leeta Lekki"} {
+		location, _ := domain.NewLocation(name, 6.5, 3.4)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+		ids = append(ids, location.ID)
+	}
+	if _, err := repo.AddAlias("Leeta Yaba", "Tejuosho"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	before, err := repo.ChangesSince(0, 1)
+	if err != nil {
+		t.Fatalf("Failed to read changes: %v", err)
+	}
+
+	audit, err := repo.TruncateLocations("qa")
+	if err != nil {
+		t.Fatalf("Failed to truncate: %v", err)
+	}
+	if audit.ID == "" || audit.Count != 3 || audit.Actor != "qa" || audit.TruncatedAt.IsZero() {
+		t.Errorf("Unexpected audit entry: %+v", audit)
+	}
+	if removed := audit.Removed; len(removed) != 3 || removed[0].ID != ids[0] || removed[1].Name != "Leeta Yaba" {
+		t.Errorf("Expected the removed locations in ID order, got %+v", removed)
+	}
+	if count, err := repo.Count(); err != nil || count != 0 {
+		t.Fatalf("Expected no locations left, got %d (%v)", count, err)
+	}
+	for _, name := range []string{"Leeta Yaba", "Tejuosho"} {
+		if _, err := repo.FindByName(name); !errors.Is(err, domain.ErrLocationNotFound) {
+			t.Errorf("Expected %s gone, got %v", name, err)
+		}
+	}
+
+	feed, err := repo.ChangesSince(before.Latest, 100)
+	if err != nil {
+		t.Fatalf("Failed to read changes: %v", err)
+	}
+	var names []string
+	for _, change := range feed.Changes {
+		if change.Action != domain.ChangeDeleted {
+			t.Errorf("Expected only deletions, got %+v", change)
+		}
+		names = append(names, change.Name)
+	}
+	if want := []string{"Leeta Ikeja", "Leeta Yaba", "Leeta Lekki"}; !slices.Equal(names, want) {
+		t.Fatalf("Expected deletions of %v after sequence %d, got %v", want, before.Latest, names)
+	}
+	if aliases := feed.Changes[1].Location.Aliases; !slices.Equal(aliases, []string{"Tejuosho"}) {
+		t.Errorf("Expected the deletion to carry the last state, got aliases %v", aliases)
+	}
+
+	// The freed names and alias may be taken again, under a new ID
+	location, _ := domain.NewLocation("Tejuosho", 6.5, 3.36)
+	if err := repo.Save(location); err != nil {
+		t.Fatalf("Failed to save after truncating: %v", err)
+	}
+	if slices.Contains(ids, location.ID) {
+		t.Errorf("Expected a new ID, got %s which was removed", location.ID)
+	}
+	if feed, _ := repo.ChangesSince(0, 1); feed.Latest <= before.Latest+3 {
+		t.Errorf("Expected the change sequence to keep rising, latest is %d", feed.Latest)
+	}
+
+	truncations, err := repo.Truncations()
+	if err != nil {
+		t.Fatalf("Failed to list truncations: %v", err)
+	}
+	if len(truncations) != 1 || truncations[0].ID != audit.ID || truncations[0].Count != 3 {
+		t.Errorf("Expected the truncation audited, got %+v", truncations)
+	}
+}
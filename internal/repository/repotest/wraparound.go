@@ -0,0 +1,86 @@
+// Package repotest holds behaviour suites shared by the location repository
+// backends, so each backend is held to the same expectations.
+package repotest
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// WraparoundStations straddle the antimeridian and sit on or near the poles
+var WraparoundStations = []domain.Location{
+	{Name: "Suva", Latitude: -18.1416, Longitude: 178.4419},
+	{Name: "Taveuni East", Latitude: -17.0, Longitude: 179.9},
+	{Name: "Taveuni West", Latitude: -17.0, Longitude: -179.9},
+	{Name: "Apia", Latitude: -13.8333, Longitude: -171.7667},
+	{Name: "Bering East", Latitude: 50, Longitude: 180},
+	{Name: "Bering West", Latitude: 55, Longitude: -180},
+	{Name: "North Pole", Latitude: 90, Longitude: 0},
+	{Name: "Alert", Latitude: 82.5018, Longitude: -62.3481},
+	{Name: "South Pole", Latitude: -90, Longitude: 0},
+	{Name: "McMurdo", Latitude: -77.8419, Longitude: 166.6863},
+}
+
+// WraparoundQuery is a nearest search and the station it must return
+type WraparoundQuery struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Exclude   []string
+	Expected  string
+}
+
+// WraparoundQueries are chosen so a longitude comparison that ignores the
+// antimeridian, or a pole treated as a line, picks the wrong station
+var WraparoundQueries = []WraparoundQuery{
+	{Name: "west of the line finds the east station", Latitude: -17.0, Longitude: 179.99, Expected: "Taveuni East"},
+	{Name: "east of the line finds the west station", Latitude: -17.0, Longitude: -179.99, Expected: "Taveuni West"},
+	{Name: "exactly on the line", Latitude: -17.0, Longitude: 180, Exclude: []string{"Taveuni East"}, Expected: "Taveuni West"},
+	{Name: "exclusion reaches across the line", Latitude: -17.0, Longitude: 179.95, Exclude: []string{"Taveuni East"}, Expected: "Taveuni West"},
+	{Name: "station stored at 180", Latitude: 50.1, Longitude: -179.95, Expected: "Bering East"},
+	{Name: "station stored at -180", Latitude: 55, Longitude: 179.99, Expected: "Bering West"},
+	{Name: "north pole at any longitude", Latitude: 90, Longitude: 123.4, Expected: "North Pole"},
+	{Name: "near the north pole", Latitude: 89.9, Longitude: -100, Expected: "North Pole"},
+	{Name: "south pole exactly", Latitude: -90, Longitude: -180, Expected: "South Pole"},
+	{Name: "near the south pole", Latitude: -89.5, Longitude: 45, Expected: "South Pole"},
+}
+
+// RunNearestWraparound saves WraparoundStations into repo and checks every
+// WraparoundQuery returns the expected station at its great-circle distance
+func RunNearestWraparound(t *testing.T, repo domain.LocationRepository) {
+	t.Helper()
+	stations := map[string]domain.Location{}
+	for _, station := range WraparoundStations {
+		location := station
+		if err := repo.Save(&location); err != nil {
+			t.Fatalf("Failed to save %s: %v", station.Name, err)
+		}
+		stations[station.Name] = station
+	}
+
+	for _, query := range WraparoundQueries {
+		t.Run(query.Name, func(t *testing.T) {
+			nearest, reported, err := repo.FindNearest(query.Latitude, query.Longitude, query.Exclude...)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if nearest.Name != query.Expected {
+				t.Fatalf("Expected %s, got %s", query.Expected, nearest.Name)
+			}
+
+			station := stations[query.Expected]
+			expected := geospatial.HaversineDistance(
+				geospatial.Coordinate{Latitude: query.Latitude, Longitude: query.Longitude},
+				geospatial.Coordinate{Latitude: station.Latitude, Longitude: station.Longitude},
+			).Kilometers()
+			distance := reported.Kilometers()
+			// Backends may use a slightly different mean radius
+			if math.Abs(distance-expected) > 0.001+expected*1e-4 {
+				t.Errorf("Expected %s at %.4f km, got %.4f km", query.Expected, expected, distance)
+			}
+		})
+	}
+}
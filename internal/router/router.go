@@ -0,0 +1,81 @@
+// Package router adapts HTTP routers for the Huma API, so its routes can
+// be registered on the standard library's ServeMux or on a chi router
+// without anything above this package knowing which.
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/go-chi/chi/v5"
+)
+
+// Names of the supported routers, as SERVER_ROUTER takes them
+const (
+	Stdlib = "stdlib"
+	Chi    = "chi"
+)
+
+// Router is an HTTP router that the API's operations, and the plain
+// handlers served beside them, are registered on
+type Router interface {
+	http.Handler
+	// API wraps the router in a Huma API that registers operations on it
+	API(config huma.Config) huma.API
+	// Handle serves handler at exactly path, for any method
+	Handle(path string, handler http.Handler)
+	// HandlePrefix serves handler at every path under prefix, which ends
+	// in a slash, for any method
+	HandlePrefix(prefix string, handler http.Handler)
+}
+
+// New returns a fresh router of the named kind; an empty name is Stdlib
+func New(name string) (Router, error) {
+	switch name {
+	case "", Stdlib:
+		return NewStdlib(http.NewServeMux()), nil
+	case Chi:
+		return NewChi(chi.NewRouter()), nil
+	default:
+		return nil, fmt.Errorf("unsupported router: %s", name)
+	}
+}
+
+type stdlib struct {
+	*http.ServeMux
+}
+
+// NewStdlib registers on mux through Huma's humago adapter
+func NewStdlib(mux *http.ServeMux) Router {
+	return stdlib{mux}
+}
+
+func (s stdlib) API(config huma.Config) huma.API {
+	return humago.New(s.ServeMux, config)
+}
+
+func (s stdlib) HandlePrefix(prefix string, handler http.Handler) {
+	s.ServeMux.Handle(prefix, handler)
+}
+
+type chiRouter struct {
+	chi.Router
+}
+
+// NewChi registers on r through Huma's humachi adapter. Middleware added
+// to r with Use before the API is built wraps every operation.
+func NewChi(r chi.Router) Router {
+	return chiRouter{r}
+}
+
+func (c chiRouter) API(config huma.Config) huma.API {
+	return humachi.New(c.Router, config)
+}
+
+func (c chiRouter) HandlePrefix(prefix string, handler http.Handler) {
+	c.Router.Handle(strings.TrimSuffix(prefix, "/")+"/*", handler)
+}
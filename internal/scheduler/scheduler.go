@@ -0,0 +1,250 @@
+// Package scheduler runs periodic background jobs alongside the server.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+// ErrJobTimeout is recorded when a run exceeds its job's timeout
+var ErrJobTimeout = errors.New("job timed out")
+
+// Clock is the time source of a Scheduler, replaceable in tests
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Job is a unit of periodic work
+type Job struct {
+	Name string
+	// Interval is the pause between the end of one run and the start of the next
+	Interval time.Duration
+	// Jitter adds a random delay of up to this much to every pause, so
+	// replicas started together do not run in lockstep
+	Jitter time.Duration
+	// Timeout cancels the run's context after this long; 0 means no timeout
+	Timeout time.Duration
+	// Immediate runs the job once at Start instead of after the first pause
+	Immediate bool
+	Run       func(ctx context.Context) error
+}
+
+// JobStatus reports a job's most recent run
+type JobStatus struct {
+	Name         string
+	Interval     time.Duration
+	Running      bool
+	Runs         int
+	Failures     int
+	LastStart    time.Time
+	LastDuration time.Duration
+	LastError    string
+	LastSuccess  time.Time
+}
+
+// Option configures optional Scheduler behaviour
+type Option func(*Scheduler)
+
+// WithClock replaces the wall clock, for tests
+func WithClock(clock Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+// Scheduler runs registered jobs on their intervals. Each job runs in its
+// own goroutine and never overlaps its previous run: the next pause starts
+// only once the run has returned, even if it overran its timeout.
+type Scheduler struct {
+	clock  Clock
+	jitter func(max time.Duration) time.Duration
+
+	mu       sync.Mutex
+	jobs     []Job
+	statuses map[string]*JobStatus
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New creates a scheduler with no jobs
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		clock: realClock{},
+		jitter: func(max time.Duration) time.Duration {
+			return rand.N(max + 1)
+		},
+		statuses: map[string]*JobStatus{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job needs a name and a run function")
+	}
+	if job.Interval <= 0 {
+		return fmt.Errorf("job %s needs a positive interval", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return fmt.Errorf("job %s registered after the scheduler started", job.Name)
+	}
+	if _, exists := s.statuses[job.Name]; exists {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	s.jobs = append(s.jobs, job)
+	s.statuses[job.Name] = &JobStatus{Name: job.Name, Interval: job.Interval}
+	return nil
+}
+
+// Start runs every registered job until ctx is cancelled or Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		s.wg.Wait()
+	}
+}
+
+// Statuses returns the status of every job, by name
+func (s *Scheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	if !job.Immediate && !s.pause(ctx, job) {
+		return
+	}
+	for {
+		s.run(ctx, job)
+		if !s.pause(ctx, job) {
+			return
+		}
+	}
+}
+
+// pause waits out the job's interval plus jitter, reporting false on shutdown
+func (s *Scheduler) pause(ctx context.Context, job Job) bool {
+	delay := job.Interval
+	if job.Jitter > 0 {
+		delay += s.jitter(job.Jitter)
+	}
+	select {
+	case <-s.clock.After(delay):
+		return ctx.Err() == nil
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	finished := make(chan struct{})
+	if job.Timeout > 0 {
+		go func() {
+			select {
+			case <-s.clock.After(job.Timeout):
+				cancel(ErrJobTimeout)
+			case <-finished:
+			}
+		}()
+	}
+
+	start := s.clock.Now()
+	s.update(job.Name, func(status *JobStatus) {
+		status.Running = true
+		status.LastStart = start
+	})
+
+	err := job.Run(runCtx)
+	close(finished)
+	if errors.Is(context.Cause(runCtx), ErrJobTimeout) {
+		err = ErrJobTimeout
+	}
+
+	duration := s.clock.Now().Sub(start)
+	metrics.SchedulerJobDuration.WithLabelValues(job.Name).Observe(duration.Seconds())
+	result := "success"
+	switch {
+	case errors.Is(err, ErrJobTimeout):
+		result = "timeout"
+	case err != nil:
+		result = "failure"
+	}
+	metrics.SchedulerJobRuns.WithLabelValues(job.Name, result).Inc()
+	if err != nil {
+		log.Printf("Job %s failed: %v", job.Name, err)
+	} else {
+		metrics.SchedulerJobLastSuccess.WithLabelValues(job.Name).Set(float64(s.clock.Now().Unix()))
+	}
+
+	s.update(job.Name, func(status *JobStatus) {
+		status.Running = false
+		status.Runs++
+		status.LastDuration = duration
+		status.LastError = ""
+		if err != nil {
+			status.Failures++
+			status.LastError = err.Error()
+		} else {
+			status.LastSuccess = start.Add(duration)
+		}
+	})
+}
+
+func (s *Scheduler) update(name string, fn func(*JobStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.statuses[name])
+}
@@ -0,0 +1,273 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock fires After channels only when the test advances it
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 8, 18, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward and fires every waiter that is now due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// BlockUntil waits for n goroutines to be waiting on the clock
+func (c *fakeClock) BlockUntil(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		waiting := len(c.waiters)
+		c.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d clock waiters", n)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerRunsOnInterval(t *testing.T) {
+	clock := newFakeClock()
+	s := New(WithClock(clock))
+	var runs atomic.Int32
+	if err := s.Register(Job{Name: "tick", Interval: time.Minute, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}}); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	s.Start(context.Background())
+	defer s.Stop()
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(59 * time.Second)
+	if runs.Load() != 0 {
+		t.Fatalf("Expected no run before the interval, got %d", runs.Load())
+	}
+
+	for want := int32(1); want <= 3; want++ {
+		clock.Advance(time.Second)
+		waitFor(t, "the next run", func() bool { return runs.Load() == want })
+		clock.BlockUntil(t, 1)
+		clock.Advance(59 * time.Second)
+	}
+
+	status := s.Statuses()[0]
+	if status.Name != "tick" || status.Runs != 3 || status.Failures != 0 || status.Running {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if status.LastSuccess.IsZero() {
+		t.Error("Expected the last success to be recorded")
+	}
+}
+
+func TestSchedulerImmediateAndJitter(t *testing.T) {
+	clock := newFakeClock()
+	s := New(WithClock(clock))
+	s.jitter = func(max time.Duration) time.Duration { return max }
+	var runs atomic.Int32
+	s.Register(Job{Name: "eager", Interval: time.Minute, Jitter: 10 * time.Second, Immediate: true, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	s.Start(context.Background())
+	defer s.Stop()
+
+	waitFor(t, "the immediate run", func() bool { return runs.Load() == 1 })
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	if runs.Load() != 1 {
+		t.Fatalf("Expected jitter to delay the second run, got %d runs", runs.Load())
+	}
+	clock.Advance(10 * time.Second)
+	waitFor(t, "the jittered run", func() bool { return runs.Load() == 2 })
+}
+
+func TestSchedulerDoesNotOverlapRuns(t *testing.T) {
+	clock := newFakeClock()
+	s := New(WithClock(clock))
+	release := make(chan struct{})
+	var running, maxRunning, runs atomic.Int32
+	s.Register(Job{Name: "slow", Interval: time.Minute, Run: func(ctx context.Context) error {
+		n := running.Add(1)
+		if n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		runs.Add(1)
+		<-release
+		running.Add(-1)
+		return nil
+	}})
+	s.Start(context.Background())
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Minute)
+	waitFor(t, "the first run", func() bool { return runs.Load() == 1 })
+
+	// Several intervals pass while the run is stuck
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Minute)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if runs.Load() != 1 {
+		t.Fatalf("Expected no run to start while the previous one is running, got %d", runs.Load())
+	}
+	if status := s.Statuses()[0]; !status.Running {
+		t.Errorf("Expected the job to be reported as running")
+	}
+
+	// The next pause starts only when the run returns
+	release <- struct{}{}
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Minute)
+	waitFor(t, "the second run", func() bool { return runs.Load() == 2 })
+	release <- struct{}{}
+
+	s.Stop()
+	if maxRunning.Load() != 1 {
+		t.Errorf("Expected at most one concurrent run, got %d", maxRunning.Load())
+	}
+}
+
+func TestSchedulerTimeout(t *testing.T) {
+	clock := newFakeClock()
+	s := New(WithClock(clock))
+	started := make(chan struct{})
+	s.Register(Job{Name: "hung", Interval: time.Minute, Timeout: 5 * time.Second, Immediate: true, Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	s.Start(context.Background())
+	defer s.Stop()
+
+	<-started
+	clock.BlockUntil(t, 1)
+	clock.Advance(5 * time.Second)
+	waitFor(t, "the timeout", func() bool { return s.Statuses()[0].Runs == 1 })
+
+	status := s.Statuses()[0]
+	if status.Failures != 1 || status.LastError != ErrJobTimeout.Error() || status.Running {
+		t.Errorf("Expected a timed-out run, got %+v", status)
+	}
+	if status.LastDuration != 5*time.Second {
+		t.Errorf("Expected the run to last 5s on the fake clock, got %v", status.LastDuration)
+	}
+}
+
+func TestSchedulerStopCancelsRuns(t *testing.T) {
+	clock := newFakeClock()
+	s := New(WithClock(clock))
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+	s.Register(Job{Name: "long", Interval: time.Hour, Immediate: true, Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return ctx.Err()
+	}})
+	s.Register(Job{Name: "idle", Interval: time.Hour, Run: func(ctx context.Context) error {
+		t.Error("Expected the idle job never to run")
+		return nil
+	}})
+	s.Start(context.Background())
+
+	<-started
+	s.Stop()
+	if !cancelled.Load() {
+		t.Error("Expected Stop to cancel the running job and wait for it")
+	}
+	if err := s.Register(Job{Name: "late", Interval: time.Minute, Run: func(context.Context) error { return nil }}); err == nil {
+		t.Error("Expected registration after Start to fail")
+	}
+}
+
+func TestSchedulerRecordsFailures(t *testing.T) {
+	clock := newFakeClock()
+	s := New(WithClock(clock))
+	var fail atomic.Bool
+	fail.Store(true)
+	var runs atomic.Int32
+	s.Register(Job{Name: "flaky", Interval: time.Minute, Immediate: true, Run: func(ctx context.Context) error {
+		defer runs.Add(1)
+		if fail.Load() {
+			return errors.New("database unavailable")
+		}
+		return nil
+	}})
+	if err := s.Register(Job{Name: "flaky", Interval: time.Minute, Run: func(context.Context) error { return nil }}); err == nil {
+		t.Error("Expected a duplicate job name to be rejected")
+	}
+	s.Start(context.Background())
+	defer s.Stop()
+
+	waitFor(t, "the failed run", func() bool { return s.Statuses()[0].Runs == 1 })
+	if status := s.Statuses()[0]; status.Failures != 1 || status.LastError != "database unavailable" || !status.LastSuccess.IsZero() {
+		t.Errorf("Expected a recorded failure, got %+v", status)
+	}
+
+	fail.Store(false)
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Minute)
+	waitFor(t, "the recovery run", func() bool { return s.Statuses()[0].Runs == 2 })
+	if status := s.Statuses()[0]; status.Failures != 1 || status.LastError != "" || status.LastSuccess.IsZero() {
+		t.Errorf("Expected the failure to clear, got %+v", status)
+	}
+}
@@ -0,0 +1,110 @@
+// Package selfcheck validates a release without serving traffic: it runs
+// a list of checks under one deadline and reports every result.
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultTimeout bounds a run when no timeout is given
+const DefaultTimeout = 30 * time.Second
+
+// Status is the outcome of one check
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// ErrSkipped marks a check that does not apply to this configuration
+var ErrSkipped = errors.New("skipped")
+
+// Skip returns an error that reports the check as skipped for reason
+func Skip(reason string) error {
+	return fmt.Errorf("%w: %s", ErrSkipped, reason)
+}
+
+// Check is one step of the self-test. Run returns a short description of
+// what it found, or an error.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report lists the result of every check; OK is false if any failed
+type Report struct {
+	OK     bool     `json:"ok"`
+	Checks []Result `json:"checks"`
+}
+
+// Failed returns the names of the failed checks
+func (r Report) Failed() []string {
+	var names []string
+	for _, result := range r.Checks {
+		if result.Status == StatusFailed {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+// Run runs checks in order, all under one deadline timeout from now. A
+// failed check does not stop the rest, so the report lists every failure;
+// checks left when the deadline passes fail with the context's error. A
+// non-positive timeout means DefaultTimeout.
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) Report {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := Report{OK: true, Checks: make([]Result, 0, len(checks))}
+	for _, check := range checks {
+		result := runCheck(ctx, check)
+		if result.Status == StatusFailed {
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) Result {
+	result := Result{Name: check.Name}
+	start := time.Now()
+	var detail string
+	err := ctx.Err()
+	if err == nil {
+		detail, err = check.Run(ctx)
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	switch {
+	case errors.Is(err, ErrSkipped):
+		result.Status = StatusSkipped
+		result.Detail = err.Error()
+	case err != nil:
+		result.Status = StatusFailed
+		result.Detail = detail
+		result.Error = err.Error()
+	default:
+		result.Status = StatusOK
+		result.Detail = detail
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunReportsEveryFailure(t *testing.T) {
+	var ran []string
+	check := func(name string, err error) Check {
+		return Check{Name: name, Run: func(ctx context.Context) (string, error) {
+			ran = append(ran, name)
+			return name + " detail", err
+		}}
+	}
+
+	report := Run(context.Background(), time.Second,
+		check("config", nil),
+		check("database", errors.New("connection refused")),
+		check("postgis", Skip("storage is memory")),
+		check("migrations", errors.New("no migrations")),
+	)
+	if report.OK {
+		t.Error("Expected the report to fail")
+	}
+	if want := []string{"config", "database", "postgis", "migrations"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("Expected every check to run, ran %v", ran)
+	}
+	if want := []string{"database", "migrations"}; !reflect.DeepEqual(report.Failed(), want) {
+		t.Errorf("Expected %v failed, got %v", want, report.Failed())
+	}
+
+	statuses := make([]Status, len(report.Checks))
+	for i, result := range report.Checks {
+		statuses[i] = result.Status
+	}
+	if want := []Status{StatusOK, StatusFailed, StatusSkipped, StatusFailed}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("Expected statuses %v, got %v", want, statuses)
+	}
+	if got := report.Checks[1]; got.Error != "connection refused" || got.Detail != "database detail" {
+		t.Errorf("Expected the failure and its detail, got %+v", got)
+	}
+	if got := report.Checks[2]; got.Detail != "skipped: storage is memory" || got.Error != "" {
+		t.Errorf("Expected the skip reason as detail, got %+v", got)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	hang := Check{Name: "hang", Run: func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+	after := Check{Name: "after", Run: func(ctx context.Context) (string, error) {
+		t.Error("Expected no check to start after the deadline")
+		return "", nil
+	}}
+
+	start := time.Now()
+	report := Run(context.Background(), 50*time.Millisecond, hang, after)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the deadline to bound the run, took %v", elapsed)
+	}
+	if want := []string{"hang", "after"}; !reflect.DeepEqual(report.Failed(), want) {
+		t.Errorf("Expected both checks failed, got %+v", report.Checks)
+	}
+	if report.Checks[1].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected the deadline as the error, got %q", report.Checks[1].Error)
+	}
+}
@@ -0,0 +1,115 @@
+package selfcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/repository"
+	"github.com/jesuloba-world/leeta-task/internal/repository/postgres"
+)
+
+// errNoDatabase fails the checks that need the database when it could
+// not be reached; the database check carries the reason
+var errNoDatabase = errors.New("database unavailable")
+
+// ServiceChecks returns the checks a release must pass before it serves
+// traffic: a valid configuration, a reachable database with PostGIS, and
+// migrations goose can apply. configErr is the error config.ReadConfig
+// returned. Call cleanup once the checks have run.
+func ServiceChecks(cfg config.Config, configErr error) (checks []Check, cleanup func()) {
+	var db *sql.DB
+	usesPostgres := cfg.Storage == repository.PostgresRepository
+
+	checks = []Check{
+		{Name: "config", Run: func(ctx context.Context) (string, error) {
+			if configErr != nil {
+				return "", configErr
+			}
+			return "storage " + cfg.Storage, nil
+		}},
+		{Name: "database", Run: func(ctx context.Context) (string, error) {
+			if !usesPostgres {
+				return "", Skip("storage is " + cfg.Storage)
+			}
+			pgConfig := repository.PostgresConfig(cfg.Database)
+			if deadline, ok := ctx.Deadline(); ok {
+				pgConfig.ConnectTimeout = time.Until(deadline)
+			}
+			conn, err := postgres.Open(pgConfig)
+			if err != nil {
+				return "", err
+			}
+			var version string
+			if err := conn.QueryRowContext(ctx, `SHOW server_version`).Scan(&version); err != nil {
+				conn.Close()
+				return fmt.Sprintf("%s:%d", cfg.Database.Host, cfg.Database.Port), err
+			}
+			db = conn
+			return "PostgreSQL " + version, nil
+		}},
+		{Name: "postgis", Run: func(ctx context.Context) (string, error) {
+			if !usesPostgres {
+				return "", Skip("storage is " + cfg.Storage)
+			}
+			if db == nil {
+				return "", errNoDatabase
+			}
+			version, err := postgres.PostGISVersion(ctx, db)
+			if err != nil {
+				return "", err
+			}
+			return "PostGIS " + version, nil
+		}},
+		{Name: "migrations", Run: func(ctx context.Context) (string, error) {
+			if !usesPostgres {
+				return "", Skip("storage is " + cfg.Storage)
+			}
+			// The files are checked even when the database is down
+			migrations, err := postgres.LoadMigrations(cfg.Database.MigrationsDir)
+			if err != nil {
+				return "", err
+			}
+			if db == nil {
+				return fmt.Sprintf("%d migrations", len(migrations)), errNoDatabase
+			}
+			plan, err := postgres.PlanMigrations(ctx, db, migrations)
+			if err != nil {
+				return "", err
+			}
+			return describePlan(plan)
+		}},
+	}
+
+	cleanup = func() {
+		if db != nil {
+			db.Close()
+		}
+	}
+	return checks, cleanup
+}
+
+// describePlan summarizes what goose up would do. Pending migrations are
+// expected for a new release; applied versions it does not know are not.
+func describePlan(plan postgres.MigrationPlan) (string, error) {
+	detail := fmt.Sprintf("%d applied, %d pending", plan.Applied, len(plan.Pending))
+	if len(plan.Pending) > 0 {
+		names := make([]string, len(plan.Pending))
+		for i, migration := range plan.Pending {
+			names[i] = migration.Name
+		}
+		detail += ": " + strings.Join(names, ", ")
+	}
+	if len(plan.Unknown) > 0 {
+		versions := make([]string, len(plan.Unknown))
+		for i, version := range plan.Unknown {
+			versions[i] = fmt.Sprint(version)
+		}
+		return detail, fmt.Errorf("database has migrations this release does not know: %s", strings.Join(versions, ", "))
+	}
+	return detail, nil
+}
@@ -0,0 +1,167 @@
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/repository"
+	pgrepo "github.com/jesuloba-world/leeta-task/internal/repository/postgres"
+)
+
+const migrationsDir = "../../scripts/migrations"
+
+func resultsByName(report Report) map[string]Result {
+	results := map[string]Result{}
+	for _, result := range report.Checks {
+		results[result.Name] = result
+	}
+	return results
+}
+
+func TestServiceChecksMemory(t *testing.T) {
+	checks, cleanup := ServiceChecks(config.Config{Storage: "memory"}, nil)
+	defer cleanup()
+
+	report := Run(context.Background(), time.Second, checks...)
+	if !report.OK {
+		t.Fatalf("Expected memory storage to pass, got %+v", report.Checks)
+	}
+	for _, name := range []string{"database", "postgis", "migrations"} {
+		if got := resultsByName(report)[name].Status; got != StatusSkipped {
+			t.Errorf("Expected %s skipped, got %s", name, got)
+		}
+	}
+}
+
+func TestServiceChecksUnreachableDatabase(t *testing.T) {
+	// A server that accepts connections and never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cfg := config.Config{
+		Storage: "postgres",
+		Database: config.DatabaseConfig{
+			Host:          "127.0.0.1",
+			Port:          listener.Addr().(*net.TCPAddr).Port,
+			User:          "leeta",
+			DBName:        "leeta",
+			SSLMode:       "disable",
+			MigrationsDir: migrationsDir,
+		},
+	}
+	checks, cleanup := ServiceChecks(cfg, fmt.Errorf("JWT_JWKS_URL is required when AUTH_MODE=jwt"))
+	defer cleanup()
+
+	start := time.Now()
+	// lib/pq times out the handshake in whole seconds, leaving time for
+	// the checks after it
+	report := Run(context.Background(), 1500*time.Millisecond, checks...)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the timeout to bound the check, took %v", elapsed)
+	}
+	if got := strings.Join(report.Failed(), ","); got != "config,database,postgis,migrations" {
+		t.Fatalf("Expected every check failed, got %+v", report.Checks)
+	}
+	results := resultsByName(report)
+	if !strings.Contains(results["config"].Error, "JWT_JWKS_URL") {
+		t.Errorf("Expected the config error reported, got %+v", results["config"])
+	}
+	if results["postgis"].Error != errNoDatabase.Error() {
+		t.Errorf("Expected postgis to fail for the database, got %+v", results["postgis"])
+	}
+	// The migration files were still read
+	if got := results["migrations"]; !strings.HasSuffix(got.Detail, " migrations") || got.Error != errNoDatabase.Error() {
+		t.Errorf("Expected the migrations counted without a database, got %+v", got)
+	}
+}
+
+func TestServiceChecksPostgres(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	container, err := postgres.Run(ctx,
+		"postgis/postgis:17-3.5-alpine",
+		postgres.WithDatabase("testdb"),
+		postgres.WithUsername("testuser"),
+		postgres.WithPassword("testpass"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+		),
+	)
+	if err != nil {
+		t.Fatalf("Failed to start PostgreSQL container: %v", err)
+	}
+	defer container.Terminate(ctx)
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		t.Fatalf("Failed to get port: %v", err)
+	}
+
+	cfg := config.Config{
+		Storage: "postgres",
+		Database: config.DatabaseConfig{
+			Host:          host,
+			Port:          port.Int(),
+			User:          "testuser",
+			Password:      "testpass",
+			DBName:        "testdb",
+			SSLMode:       "disable",
+			MigrationsDir: migrationsDir,
+		},
+	}
+	run := func() Report {
+		checks, cleanup := ServiceChecks(cfg, nil)
+		defer cleanup()
+		return Run(ctx, 30*time.Second, checks...)
+	}
+
+	// A database goose has not touched has every migration pending
+	report := run()
+	if !report.OK {
+		t.Fatalf("Expected the checks to pass, got %+v", report.Checks)
+	}
+	if got := resultsByName(report)["migrations"].Detail; !strings.HasPrefix(got, "0 applied, ") {
+		t.Errorf("Expected every migration pending, got %q", got)
+	}
+
+	// A version this release does not know, as after a rollback, fails
+	db, err := pgrepo.Open(repository.PostgresConfig(cfg.Database))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE goose_db_version (id serial PRIMARY KEY, version_id bigint NOT NULL, is_applied boolean NOT NULL, tstamp timestamp DEFAULT now());
+		INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, true), (20250728210121, true), (99990101000000, true)`); err != nil {
+		t.Fatalf("Failed to record migrations: %v", err)
+	}
+	report = run()
+	migrations := resultsByName(report)["migrations"]
+	if migrations.Status != StatusFailed || !strings.Contains(migrations.Error, "99990101000000") || !strings.HasPrefix(migrations.Detail, "1 applied, ") {
+		t.Errorf("Expected the unknown version to fail the check, got %+v", migrations)
+	}
+}
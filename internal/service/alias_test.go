@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+)
+
+func TestAddAlias(t *testing.T) {
+	t.Parallel()
+	blocklist, err := text.NewBlocklist([]string{"asdf"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to compile blocklist: %v", err)
+	}
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(),
+		service.WithNamePolicy(blocklist, []string{"internal-"}))
+	if _, err := svc.CreateLocation("Leeta Lekki Phase 1", 6.4474, 3.4720); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	location, err := svc.AddAlias("Leeta Lekki Phase 1", "  Leeta Admiralty Way ", false)
+	if err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	if !slices.Equal(location.Aliases, []string{"Leeta Admiralty Way"}) {
+		t.Errorf("Expected the trimmed alias, got %q", location.Aliases)
+	}
+
+	for _, alias := range []string{"ASDF", "internal-lekki"} {
+		if _, err := svc.AddAlias("Leeta Lekki Phase 1", alias, false); !errors.Is(err, domain.ErrNameNotAllowed) {
+			t.Errorf("%q: expected ErrNameNotAllowed, got %v", alias, err)
+		}
+	}
+	if _, err := svc.AddAlias("Leeta Lekki Phase 1", "internal-lekki", true); err != nil {
+		t.Errorf("Expected a privileged caller to use the reserved prefix, got %v", err)
+	}
+	if _, err := svc.AddAlias("Leeta Lekki Phase 1", " ", false); !errors.Is(err, domain.ErrEmptyName) {
+		t.Errorf("Expected ErrEmptyName for a blank alias, got %v", err)
+	}
+
+	_, err = svc.CreateLocation("Leeta Admiralty Way", 6.5, 3.5)
+	var taken *domain.NameTakenError
+	if !errors.As(err, &taken) || taken.Owner != "Leeta Lekki Phase 1" {
+		t.Errorf("Expected creating over an alias to name its owner, got %v", err)
+	}
+	if _, err := svc.CreateLocation("Leeta Lekki Phase 1", 6.5, 3.5); !errors.Is(err, domain.ErrLocationExists) || errors.As(err, &taken) {
+		t.Errorf("Expected a plain ErrLocationExists for a repeated name, got %v", err)
+	}
+
+	location, err = svc.RemoveAlias("Leeta Admiralty Way", "Leeta Admiralty Way")
+	if err != nil {
+		t.Fatalf("Failed to remove an alias named by itself: %v", err)
+	}
+	if !slices.Equal(location.Aliases, []string{"internal-lekki"}) {
+		t.Errorf("Expected one alias left, got %q", location.Aliases)
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HTTPAttachmentChecker checks that attachment URLs resolve with a HEAD
+// request. Redirects are not followed, so a check never leaves the
+// allowed hosts; any status below 400 passes.
+type HTTPAttachmentChecker struct {
+	client *http.Client
+}
+
+// NewHTTPAttachmentChecker checks with client, whose Timeout bounds each
+// check; nil means http.DefaultClient
+func NewHTTPAttachmentChecker(client *http.Client) *HTTPAttachmentChecker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &HTTPAttachmentChecker{client: &noRedirects}
+}
+
+func (c *HTTPAttachmentChecker) CheckAttachment(url string) error {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("url could not be reached: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("url answered %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// primeState is what a run saves for the next one to prime
+type primeState struct {
+	SavedAt time.Time `json:"saved_at"`
+	Names   []string  `json:"names"`
+}
+
+// CachePrimingService fills the location cache at startup so the first
+// requests after a deploy do not all miss. It primes the listing and the
+// names read most by the previous run, which it saves to stateFile on
+// shutdown; without a stateFile only the listing is primed.
+type CachePrimingService struct {
+	cache     domain.CachePrimer
+	stateFile string
+	names     int
+	now       func() time.Time
+
+	mu     sync.Mutex
+	status domain.CachePrimeStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewCachePrimingService creates a priming service that remembers up to
+// names of the most read names between runs
+func NewCachePrimingService(cache domain.CachePrimer, stateFile string, names int) *CachePrimingService {
+	return &CachePrimingService{
+		cache:     cache,
+		stateFile: stateFile,
+		names:     names,
+		now:       time.Now,
+	}
+}
+
+// Start primes the cache in the background and waits up to budget for it
+// to finish, so a slow or failing store delays startup by at most budget.
+// Priming that fails is logged and reported by Status; the cache then
+// fills from requests as usual.
+func (s *CachePrimingService) Start(budget time.Duration) {
+	names, err := s.loadNames()
+	if err != nil {
+		log.Printf("Cache priming: failed to read %s: %v", s.stateFile, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.cancel, s.done = cancel, done
+	s.status = domain.CachePrimeStatus{State: domain.CachePrimeWarming, Names: len(names), StartedAt: s.now()}
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		read, err := s.cache.Prime(ctx, names)
+		s.finish(read, err)
+	}()
+
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		log.Printf("Cache priming still running after %s; serving while it finishes", budget)
+	}
+}
+
+// Stop cancels priming that is still running and saves the names read
+// most for the next run
+func (s *CachePrimingService) Stop() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return s.saveNames(s.cache.HotNames(s.names))
+}
+
+// Status returns the state of the latest priming
+func (s *CachePrimingService) Status() domain.CachePrimeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *CachePrimingService) finish(read int, err error) {
+	finishedAt := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status.Locations = read
+	s.status.FinishedAt = &finishedAt
+	if err != nil {
+		s.status.State = domain.CachePrimeFailed
+		s.status.Error = err.Error()
+		log.Printf("Cache priming failed after %d locations: %v", read, err)
+		return
+	}
+	s.status.State = domain.CachePrimePrimed
+	log.Printf("Cache primed with %d locations and %d saved names in %s", read, s.status.Names, finishedAt.Sub(s.status.StartedAt))
+}
+
+// loadNames reads the names the previous run saved; a missing file is a
+// first run, not an error
+func (s *CachePrimingService) loadNames() ([]string, error) {
+	if s.stateFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(s.stateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state primeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if len(state.Names) > s.names {
+		state.Names = state.Names[:s.names]
+	}
+	return state.Names, nil
+}
+
+// saveNames replaces the state file through a rename, so a crash while
+// writing leaves the previous names in place
+func (s *CachePrimingService) saveNames(names []string) error {
+	if s.stateFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(primeState{SavedAt: s.now().UTC(), Names: names})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.stateFile), filepath.Base(s.stateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.stateFile)
+}
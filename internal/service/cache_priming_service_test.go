@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// fakePrimer records the names it is asked to prime and runs prime
+type fakePrimer struct {
+	prime  func(ctx context.Context) (int, error)
+	hot    []string
+	primed chan []string
+}
+
+func (p *fakePrimer) Prime(ctx context.Context, names []string) (int, error) {
+	p.primed <- names
+	return p.prime(ctx)
+}
+
+func (p *fakePrimer) HotNames(n int) []string {
+	return p.hot[:min(n, len(p.hot))]
+}
+
+func newFakePrimer(prime func(ctx context.Context) (int, error)) *fakePrimer {
+	return &fakePrimer{prime: prime, primed: make(chan []string, 1)}
+}
+
+func TestCachePrimingSavesNamesForNextRun(t *testing.T) {
+	t.Parallel()
+	stateFile := filepath.Join(t.TempDir(), "cache-prime.json")
+
+	first := newFakePrimer(func(context.Context) (int, error) { return 3, nil })
+	first.hot = []string{"Ikeja", "Yaba", "Lekki"}
+	priming := service.NewCachePrimingService(first, stateFile, 2)
+	priming.Start(time.Second)
+	if names := <-first.primed; len(names) != 0 {
+		t.Errorf("Expected no names on the first run, got %v", names)
+	}
+	if status := priming.Status(); status.State != domain.CachePrimePrimed || status.Locations != 3 || status.FinishedAt == nil {
+		t.Errorf("Expected priming done within the budget, got %+v", status)
+	}
+	if err := priming.Stop(); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+
+	next := newFakePrimer(func(context.Context) (int, error) { return 3, nil })
+	priming = service.NewCachePrimingService(next, stateFile, 2)
+	priming.Start(time.Second)
+	if names := <-next.primed; !slices.Equal(names, []string{"Ikeja", "Yaba"}) {
+		t.Errorf("Expected the names the last run read most, got %v", names)
+	}
+	if status := priming.Status(); status.Names != 2 {
+		t.Errorf("Expected 2 names reported, got %+v", status)
+	}
+}
+
+func TestCachePrimingFailureDoesNotStopStartup(t *testing.T) {
+	t.Parallel()
+	primer := newFakePrimer(func(context.Context) (int, error) { return 1, errors.New("connection refused") })
+	priming := service.NewCachePrimingService(primer, "", 10)
+
+	priming.Start(time.Second)
+	status := priming.Status()
+	if status.State != domain.CachePrimeFailed || status.Error != "connection refused" || status.Locations != 1 {
+		t.Errorf("Expected failed priming reported, got %+v", status)
+	}
+	if err := priming.Stop(); err != nil {
+		t.Errorf("Expected stopping without a state file to succeed, got %v", err)
+	}
+}
+
+func TestCachePrimingWaitsOnlyForBudget(t *testing.T) {
+	t.Parallel()
+	primer := newFakePrimer(func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	priming := service.NewCachePrimingService(primer, "", 10)
+
+	started := time.Now()
+	priming.Start(20 * time.Millisecond)
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected startup to stop waiting after the budget, waited %s", elapsed)
+	}
+	if status := priming.Status(); status.State != domain.CachePrimeWarming {
+		t.Errorf("Expected warming while priming runs, got %+v", status)
+	}
+
+	// Stopping cancels priming still running
+	if err := priming.Stop(); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+	if status := priming.Status(); status.State != domain.CachePrimeFailed || status.Error != context.Canceled.Error() {
+		t.Errorf("Expected priming cancelled on stop, got %+v", status)
+	}
+}
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestCreateLocationDescription(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{"plain", "Entrance on the service road", "Entrance on the service road"},
+		{"control characters stripped", "Closes\x00 early\x1b[31m on Sundays\u0085", "Closes early[31m on Sundays"},
+		{"line breaks kept", " Gate 2\r\n\tpump 4 \n", "Gate 2\n\tpump 4"},
+		{"unicode kept", "Pompe à essence — ouvert 24h ⛽", "Pompe à essence — ouvert 24h ⛽"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+			location, err := svc.CreateLocation("Yaba", 6.5095, 3.3711, domain.WithDescription(tt.description))
+			if err != nil {
+				t.Fatalf("Failed to create: %v", err)
+			}
+			if location.Description != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, location.Description)
+			}
+		})
+	}
+}
+
+func TestCreateLocationDescriptionLength(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+
+	// The limit counts characters, not bytes: 2000 two-byte letters fit
+	if _, err := svc.CreateLocation("Accents", 6.5, 3.4, domain.WithDescription(strings.Repeat("é", domain.DefaultDescriptionMaxLength))); err != nil {
+		t.Errorf("Expected a description at the limit to be accepted, got %v", err)
+	}
+	_, err := svc.CreateLocation("Long", 6.5, 3.4, domain.WithDescription(strings.Repeat("a", domain.DefaultDescriptionMaxLength+1)))
+	var tooLong *domain.DescriptionTooLongError
+	if !errors.As(err, &tooLong) || tooLong.Max != domain.DefaultDescriptionMaxLength || !errors.Is(err, domain.ErrDescriptionTooLong) {
+		t.Errorf("Expected DescriptionTooLongError, got %v", err)
+	}
+	// Stripped characters do not count towards the limit
+	padded := strings.Repeat("a", domain.DefaultDescriptionMaxLength) + strings.Repeat("\x00", 10)
+	if _, err := svc.CreateLocation("Padded", 6.5, 3.4, domain.WithDescription(padded)); err != nil {
+		t.Errorf("Expected control characters to be stripped before the check, got %v", err)
+	}
+
+	short := service.NewLocationService(memory.NewInMemoryLocationRepository(), service.WithDescriptionMaxLength(10))
+	if _, err := short.CreateLocation("Short", 6.5, 3.4, domain.WithDescription("eleven char")); !errors.Is(err, domain.ErrDescriptionTooLong) {
+		t.Errorf("Expected the configured limit to apply, got %v", err)
+	}
+}
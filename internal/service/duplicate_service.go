@@ -0,0 +1,132 @@
+package service
+
+import (
+	"log"
+	"sort"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// DuplicateCriteria decides which pairs of locations are suspected duplicates
+type DuplicateCriteria struct {
+	// Radius matches locations at most this far apart; 0 disables it
+	Radius geospatial.Distance
+	// NameSimilarity matches names scoring at least this on text.Similarity; 0 disables it
+	NameSimilarity float64
+	// RequireBoth matches only pairs meeting both criteria, instead of either
+	RequireBoth bool
+}
+
+func (c DuplicateCriteria) matches(distance geospatial.Distance, similarity float64) bool {
+	near := c.Radius > 0 && distance <= c.Radius
+	similar := c.NameSimilarity > 0 && similarity >= c.NameSimilarity
+	if c.RequireBoth {
+		return near && similar
+	}
+	return near || similar
+}
+
+// DuplicateService reports suspected duplicate locations and merges them
+type DuplicateService struct {
+	repo      domain.LocationRepository
+	merger    domain.LocationMerger
+	publisher domain.EventPublisher
+}
+
+// NewDuplicateService creates a duplicate service. publisher may be nil when
+// the merger records deletion events itself.
+func NewDuplicateService(repo domain.LocationRepository, merger domain.LocationMerger, publisher domain.EventPublisher) *DuplicateService {
+	return &DuplicateService{repo: repo, merger: merger, publisher: publisher}
+}
+
+// FindDuplicates compares every pair of locations and returns clusters of
+// locations connected by matching pairs. The scan is quadratic, which suits
+// an occasional cleanup report rather than a hot path.
+func (s *DuplicateService) FindDuplicates(criteria DuplicateCriteria) ([]domain.DuplicateCluster, error) {
+	locations, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Name < locations[j].Name })
+
+	// Union-find over location indexes
+	parent := make([]int, len(locations))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	var pairs []domain.DuplicatePair
+	paired := make([]bool, len(locations))
+	for i := 0; i < len(locations); i++ {
+		a := locations[i]
+		for j := i + 1; j < len(locations); j++ {
+			b := locations[j]
+			distance := geospatial.HaversineDistance(
+				geospatial.Coordinate{Latitude: a.Latitude, Longitude: a.Longitude},
+				geospatial.Coordinate{Latitude: b.Latitude, Longitude: b.Longitude},
+			)
+			similarity := text.Similarity(a.Name, b.Name)
+			if !criteria.matches(distance, similarity) {
+				continue
+			}
+			pairs = append(pairs, domain.DuplicatePair{A: a.Name, B: b.Name, Distance: distance, NameSimilarity: similarity})
+			paired[i], paired[j] = true, true
+			parent[find(j)] = find(i)
+		}
+	}
+
+	// Group members and pairs by their root, keeping name order
+	index := map[string]int{}
+	clusterOf := map[int]int{}
+	var clusters []domain.DuplicateCluster
+	for i, location := range locations {
+		index[location.Name] = i
+		if !paired[i] {
+			continue
+		}
+		root := find(i)
+		c, ok := clusterOf[root]
+		if !ok {
+			c = len(clusters)
+			clusterOf[root] = c
+			clusters = append(clusters, domain.DuplicateCluster{})
+		}
+		clusters[c].Locations = append(clusters[c].Locations, location)
+	}
+	for _, pair := range pairs {
+		c := clusterOf[find(index[pair.A])]
+		clusters[c].Pairs = append(clusters[c].Pairs, pair)
+	}
+
+	return clusters, nil
+}
+
+// MergeLocations deletes the losers in favour of the winner and records the
+// merge. actor identifies the caller in the audit entry.
+func (s *DuplicateService) MergeLocations(winner string, losers []string, actor string) (*domain.MergeAudit, error) {
+	log.Printf("Merging %v into location %s", losers, winner)
+
+	audit, err := s.merger.MergeLocations(winner, losers, actor)
+	if err != nil {
+		log.Printf("Failed to merge into location %s: %v", winner, err)
+		return nil, err
+	}
+
+	if s.publisher != nil {
+		for _, location := range audit.Losers {
+			if err := s.publisher.Publish(domain.NewEvent(domain.EventLocationDeleted, location)); err != nil {
+				log.Printf("Failed to publish %s event for %s: %v", domain.EventLocationDeleted, location.Name, err)
+			}
+		}
+	}
+	return audit, nil
+}
@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func seedDuplicates(t *testing.T, repo *memory.InMemoryLocationRepository) {
+	t.Helper()
+	seeds := []struct {
+		name     string
+		lat, lng float64
+	}{
+		// Same station entered twice, about 15 m apart
+		{"Leeta Lekki Phase 1", 6.4474, 3.4720},
+		{"Leeta Lekki Phase I", 6.4475, 3.4721},
+		// A chain 40 m apart: the ends are 80 m apart but share a cluster
+		{"North Gate", 6.60000, 3.3},
+		{"Main Hall", 6.60036, 3.3},
+		{"Rear Yard", 6.60072, 3.3},
+		// Similar names several kilometres apart
+		{"Ajah Terminal", 6.47, 3.57},
+		{"Ajah Terminus", 6.52, 3.60},
+		// Unrelated
+		{"Ikeja Station", 6.6018, 3.3515},
+	}
+	for _, seed := range seeds {
+		location, _ := domain.NewLocation(seed.name, seed.lat, seed.lng)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", seed.name, err)
+		}
+	}
+}
+
+func clusterNames(clusters []domain.DuplicateCluster) [][]string {
+	var names [][]string
+	for _, cluster := range clusters {
+		var members []string
+		for _, location := range cluster.Locations {
+			members = append(members, location.Name)
+		}
+		names = append(names, members)
+	}
+	return names
+}
+
+func TestFindDuplicates(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	seedDuplicates(t, repo)
+	svc := service.NewDuplicateService(repo, repo, nil)
+
+	tests := []struct {
+		name     string
+		criteria service.DuplicateCriteria
+		want     [][]string
+		pairs    []int
+	}{
+		{
+			name:     "either criterion",
+			criteria: service.DuplicateCriteria{Radius: 50 * geospatial.Meter, NameSimilarity: 0.8},
+			want: [][]string{
+				{"Ajah Terminal", "Ajah Terminus"},
+				{"Leeta Lekki Phase 1", "Leeta Lekki Phase I"},
+				{"Main Hall", "North Gate", "Rear Yard"},
+			},
+			pairs: []int{1, 1, 2},
+		},
+		{
+			name:     "both criteria",
+			criteria: service.DuplicateCriteria{Radius: 50 * geospatial.Meter, NameSimilarity: 0.8, RequireBoth: true},
+			want:     [][]string{{"Leeta Lekki Phase 1", "Leeta Lekki Phase I"}},
+			pairs:    []int{1},
+		},
+		{
+			name:     "proximity only",
+			criteria: service.DuplicateCriteria{Radius: 50 * geospatial.Meter},
+			want: [][]string{
+				{"Leeta Lekki Phase 1", "Leeta Lekki Phase I"},
+				{"Main Hall", "North Gate", "Rear Yard"},
+			},
+			pairs: []int{1, 2},
+		},
+		{
+			name:     "wider radius links the chain ends",
+			criteria: service.DuplicateCriteria{Radius: 100 * geospatial.Meter},
+			want: [][]string{
+				{"Leeta Lekki Phase 1", "Leeta Lekki Phase I"},
+				{"Main Hall", "North Gate", "Rear Yard"},
+			},
+			pairs: []int{1, 3},
+		},
+		{
+			name:     "nothing enabled",
+			criteria: service.DuplicateCriteria{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, err := svc.FindDuplicates(tt.criteria)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := clusterNames(clusters); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Expected clusters %v, got %v", tt.want, got)
+			}
+			for i, cluster := range clusters {
+				if len(cluster.Pairs) != tt.pairs[i] {
+					t.Errorf("Cluster %d: expected %d pairs, got %+v", i, tt.pairs[i], cluster.Pairs)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeDuplicatesPublishesDeletions(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	seedDuplicates(t, repo)
+	bus := events.NewBus()
+	var published []domain.Event
+	bus.Subscribe(func(e domain.Event) error {
+		published = append(published, e)
+		return nil
+	})
+	svc := service.NewDuplicateService(repo, repo, bus)
+
+	audit, err := svc.MergeLocations("North Gate", []string{"Main Hall", "Rear Yard"}, "ops")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if audit.Winner != "North Gate" || audit.Actor != "ops" || len(audit.Losers) != 2 {
+		t.Errorf("Unexpected audit entry: %+v", audit)
+	}
+	if len(published) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(published))
+	}
+	for i, name := range []string{"Main Hall", "Rear Yard"} {
+		if published[i].Type != domain.EventLocationDeleted || published[i].Location.Name != name {
+			t.Errorf("Unexpected event %d: %+v", i, published[i])
+		}
+	}
+
+	// A failed merge publishes nothing
+	_, err = svc.MergeLocations("North Gate", []string{"Main Hall"}, "ops")
+	if !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound, got %v", err)
+	}
+	if len(published) != 2 {
+		t.Errorf("Expected no further events, got %d", len(published))
+	}
+}
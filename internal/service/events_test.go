@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestUpdatesPublishEvents(t *testing.T) {
+	t.Parallel()
+	var received []domain.Event
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		received = append(received, e)
+		return nil
+	})
+	repo := memory.NewInMemoryLocationRepository()
+	svc := service.NewLocationService(repo, service.WithEventPublisher(bus))
+	if _, err := svc.CreateLocation("Yaba", 6.5095, 3.3711); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	capacity := 33000.0
+	steps := []struct {
+		name string
+		do   func() error
+	}{
+		{"add alias", func() error { _, err := svc.AddAlias("Yaba", "Tejuosho", false); return err }},
+		{"remove alias", func() error { _, err := svc.RemoveAlias("Yaba", "Tejuosho"); return err }},
+		{"stock", func() error {
+			_, err := svc.UpdateStock("Yaba", domain.StockUpdate{CapacityLitres: &capacity})
+			return err
+		}},
+	}
+	for _, step := range steps {
+		received = nil
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if len(received) != 1 || received[0].Type != domain.EventLocationUpdated || received[0].Location.Name != "Yaba" {
+			t.Errorf("%s: expected one location.updated event, got %+v", step.name, received)
+		}
+	}
+	if received[0].Location.CapacityLitres == nil || *received[0].Location.CapacityLitres != capacity {
+		t.Errorf("Expected the event to carry the new stock, got %+v", received[0].Location)
+	}
+
+	// A rename by the integrity fix is an update too
+	if err := repo.Save(&domain.Location{Name: " Ikeja ", Latitude: 6.6018, Longitude: 3.3515}); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	received = nil
+	integrity := service.NewIntegrityService(repo, 0, service.WithIntegrityEventPublisher(bus))
+	if _, err := integrity.Run(context.Background(), domain.IntegrityFixTrim); err != nil {
+		t.Fatalf("Failed to run the integrity fix: %v", err)
+	}
+	if len(received) != 1 || received[0].Type != domain.EventLocationUpdated || received[0].Location.Name != "Ikeja" {
+		t.Errorf("Expected one location.updated event for the rename, got %+v", received)
+	}
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// maxImportErrors caps the row errors a job lists; counts stay exact
+const maxImportErrors = 1000
+
+// importPollInterval is how often the worker looks for jobs submitted to
+// other instances and for jobs whose worker has stopped
+var importPollInterval = 5 * time.Second
+
+// errImportWorkerStopped is why a job running when the worker stops fails
+var errImportWorkerStopped = errors.New("the server shut down")
+
+// ImportService imports locations from CSV files, in the foreground or as
+// jobs a background worker runs in batches
+type ImportService struct {
+	jobs       domain.JobRepository
+	locations  domain.LocationService
+	batchSize  int
+	staleAfter time.Duration
+	now        func() time.Time
+
+	// wake starts the worker on a job submitted here without waiting for
+	// the next poll
+	wake chan struct{}
+
+	mu     sync.Mutex
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// ImportServiceOption configures an ImportService
+type ImportServiceOption func(*ImportService)
+
+// WithImportClock sets the clock that stamps jobs and measures their
+// staleness
+func WithImportClock(now func() time.Time) ImportServiceOption {
+	return func(s *ImportService) {
+		s.now = now
+	}
+}
+
+// NewImportService creates an import service that creates batchSize rows
+// between progress updates and fails running jobs that have not reported
+// progress for staleAfter
+func NewImportService(jobs domain.JobRepository, locations domain.LocationService, batchSize int, staleAfter time.Duration, opts ...ImportServiceOption) *ImportService {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if staleAfter <= 0 {
+		staleAfter = 5 * time.Minute
+	}
+	s := &ImportService{
+		jobs:       jobs,
+		locations:  locations,
+		batchSize:  batchSize,
+		staleAfter: staleAfter,
+		now:        time.Now,
+		wake:       make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Import imports data in the foreground and returns the finished report,
+// which is not stored. It stops between batches once ctx is done.
+func (s *ImportService) Import(ctx context.Context, data []byte) (*domain.ImportJob, error) {
+	total, err := countImportRows(data)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	job := &domain.ImportJob{Status: domain.ImportRunning, Total: total, CreatedAt: now, UpdatedAt: now, StartedAt: &now}
+	s.run(ctx, job, data, nil)
+	return job, nil
+}
+
+// Submit stores data as a pending job for a worker and returns the job.
+// A file without the required columns is rejected at once.
+func (s *ImportService) Submit(data []byte) (*domain.ImportJob, error) {
+	total, err := countImportRows(data)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	job := &domain.ImportJob{ID: domain.NewEventID(), Status: domain.ImportPending, Total: total, CreatedAt: now, UpdatedAt: now}
+	if err := s.jobs.CreateImport(job, data); err != nil {
+		return nil, err
+	}
+	log.Printf("Import %s submitted: %d rows", job.ID, total)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns a job with its progress so far
+func (s *ImportService) Get(id string) (*domain.ImportJob, error) {
+	return s.jobs.FindImport(id)
+}
+
+// Cancel cancels a pending job, or stops a running one after its current
+// batch. A finished job is returned unchanged.
+func (s *ImportService) Cancel(id string) (*domain.ImportJob, error) {
+	job, err := s.jobs.CancelImport(id, s.now())
+	if err == nil {
+		log.Printf("Import %s cancel requested (status %s)", id, job.Status)
+	}
+	return job, err
+}
+
+// Start runs the worker in the background until Stop is called
+func (s *ImportService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		s.work(ctx)
+	}(s.done)
+}
+
+// Stop stops the worker. A job it is running is marked failed after its
+// current batch, as no other worker will resume it. Stop returns when
+// the worker has, or when ctx is done.
+func (s *ImportService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel(errImportWorkerStopped)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work claims and runs pending jobs one at a time, and fails running jobs
+// whose worker has stopped
+func (s *ImportService) work(ctx context.Context) {
+	ticker := time.NewTicker(importPollInterval)
+	defer ticker.Stop()
+	for {
+		if failed, err := s.jobs.FailStaleImports(s.now().Add(-s.staleAfter), "no progress for "+s.staleAfter.String()+"; the instance running it stopped"); err != nil {
+			log.Printf("Failed to check for stale imports: %v", err)
+		} else if failed > 0 {
+			log.Printf("Marked %d stale imports failed", failed)
+		}
+		for ctx.Err() == nil && s.runNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims the oldest pending job and runs it, reporting whether
+// there was one
+func (s *ImportService) runNext(ctx context.Context) bool {
+	job, data, err := s.jobs.ClaimImport(s.now())
+	if err != nil {
+		log.Printf("Failed to claim an import: %v", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+	log.Printf("Import %s started: %d rows", job.ID, job.Total)
+	s.run(ctx, job, data, s.jobs.UpdateImport)
+	log.Printf("Import %s %s: %d imported, %d failed of %d", job.ID, job.Status, job.Imported, job.Failed, job.Total)
+	return true
+}
+
+// run imports data batch by batch into job. save, when set, records the
+// job after every batch and learns of cancel requests. The job stops
+// between batches when cancelled or when ctx is done.
+func (s *ImportService) run(ctx context.Context, job *domain.ImportJob, data []byte, save func(*domain.ImportJob) error) {
+	finish := func(status domain.ImportStatus, reason string) {
+		now := s.now()
+		job.Status, job.Error, job.UpdatedAt, job.FinishedAt = status, reason, now, &now
+		if save != nil {
+			if err := save(job); err != nil {
+				log.Printf("Failed to record import %s as %s: %v", job.ID, status, err)
+			}
+		}
+	}
+
+	reader, err := newImportReader(data)
+	if err != nil {
+		finish(domain.ImportFailed, err.Error())
+		return
+	}
+	for {
+		for range s.batchSize {
+			row, err := reader.next()
+			if errors.Is(err, io.EOF) {
+				finish(domain.ImportCompleted, "")
+				return
+			}
+			if err != nil {
+				finish(domain.ImportFailed, err.Error())
+				return
+			}
+			job.Processed++
+			if row.err == nil {
+				_, row.err = s.locations.CreateLocation(row.name, row.latitude, row.longitude,
+					domain.WithRegion(row.region), domain.WithDescription(row.description))
+			}
+			if row.err != nil {
+				job.Failed++
+				if len(job.Errors) < maxImportErrors {
+					job.Errors = append(job.Errors, domain.ImportRowError{Row: row.line, Name: row.name, Error: row.err.Error()})
+				}
+				continue
+			}
+			job.Imported++
+		}
+
+		if save != nil {
+			job.UpdatedAt = s.now()
+			if err := save(job); err != nil {
+				log.Printf("Failed to record progress of import %s: %v", job.ID, err)
+			}
+		}
+		switch {
+		case job.CancelRequested:
+			finish(domain.ImportCancelled, "")
+			return
+		case ctx.Err() != nil:
+			finish(domain.ImportFailed, "interrupted after "+strconv.Itoa(job.Processed)+" rows: "+context.Cause(ctx).Error())
+			return
+		}
+	}
+}
+
+// importRow is one CSV row parsed for import; err is set when the row
+// cannot be imported as it is
+type importRow struct {
+	line                int
+	name                string
+	latitude, longitude float64
+	region, description string
+	err                 error
+}
+
+// importReader reads the rows of a CSV file with a header naming its
+// columns. name, latitude (or lat) and longitude (lng or lon) are
+// required; region and description are optional and other columns are
+// ignored.
+type importReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newImportReader(data []byte) (*importReader, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", domain.ErrInvalidImportFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidImportFile, err)
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		switch column {
+		case "lat":
+			column = "latitude"
+		case "lng", "lon":
+			column = "longitude"
+		}
+		if _, seen := columns[column]; !seen {
+			columns[column] = i
+		}
+	}
+	for _, required := range []string{"name", "latitude", "longitude"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: the header has no %s column", domain.ErrInvalidImportFile, required)
+		}
+	}
+	return &importReader{reader: reader, columns: columns}, nil
+}
+
+// next reads the next row, or returns io.EOF after the last
+func (r *importReader) next() (importRow, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return importRow{}, err
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return importRow{line: parseErr.StartLine, err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return importRow{}, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	field := func(column string) string {
+		if i, ok := r.columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	row := importRow{line: line, name: field("name"), region: field("region"), description: field("description")}
+	for _, c := range []struct {
+		column string
+		value  *float64
+	}{{"latitude", &row.latitude}, {"longitude", &row.longitude}} {
+		text := field(c.column)
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			row.err = fmt.Errorf("invalid %s %q", c.column, text)
+			return row, nil
+		}
+		*c.value = value
+	}
+	return row, nil
+}
+
+// countImportRows checks the header and counts the rows to import
+func countImportRows(data []byte) (int, error) {
+	reader, err := newImportReader(data)
+	if err != nil {
+		return 0, err
+	}
+	rows := 0
+	for {
+		if _, err := reader.next(); errors.Is(err, io.EOF) {
+			return rows, nil
+		} else if err != nil {
+			return 0, fmt.Errorf("%w: %v", domain.ErrInvalidImportFile, err)
+		}
+		rows++
+	}
+}
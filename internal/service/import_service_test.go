@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// recordingJobs keeps a copy of every job update, as a poller would see it
+type recordingJobs struct {
+	*memory.InMemoryJobRepository
+	mu        sync.Mutex
+	snapshots []domain.ImportJob
+}
+
+func (r *recordingJobs) UpdateImport(job *domain.ImportJob) error {
+	err := r.InMemoryJobRepository.UpdateImport(job)
+	r.mu.Lock()
+	r.snapshots = append(r.snapshots, *job)
+	r.mu.Unlock()
+	return err
+}
+
+// gatedLocations holds the creation of the named row until released
+type gatedLocations struct {
+	domain.LocationService
+	gate    string
+	reached chan struct{}
+	release chan struct{}
+}
+
+func (g *gatedLocations) CreateLocation(name string, latitude, longitude float64, opts ...domain.CreateOption) (*domain.Location, error) {
+	if name == g.gate {
+		close(g.reached)
+		<-g.release
+	}
+	return g.LocationService.CreateLocation(name, latitude, longitude, opts...)
+}
+
+func newGatedLocations(gate string) *gatedLocations {
+	return &gatedLocations{
+		LocationService: service.NewLocationService(memory.NewInMemoryLocationRepository()),
+		gate:            gate,
+		reached:         make(chan struct{}),
+		release:         make(chan struct{}),
+	}
+}
+
+// waitForImport polls a job until it finishes
+func waitForImport(t *testing.T, svc *service.ImportService, id string) *domain.ImportJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.Get(id)
+		if err != nil {
+			t.Fatalf("Failed to get import %s: %v", id, err)
+		}
+		if job.Status.Finished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Import %s did not finish", id)
+	return nil
+}
+
+const importCSV = "name,lat,lng,region\n" +
+	"Leeta Ikeja,6.6018,3.3515,Lagos\n" +
+	"Leeta Yaba,north,3.3711,Lagos\n" +
+	"Leeta Lekki,6.4474,3.472,Lagos\n" +
+	"Leeta Ikeja,6.6,3.35,Lagos\n" +
+	"Leeta Wuse,9.0765,7.3986,Abuja\n"
+
+func TestImportJobRunsInBatches(t *testing.T) {
+	t.Parallel()
+	jobs := &recordingJobs{InMemoryJobRepository: memory.NewInMemoryJobRepository()}
+	locations := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	svc := service.NewImportService(jobs, locations, 2, time.Minute)
+
+	submitted, err := svc.Submit([]byte(importCSV))
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if submitted.Status != domain.ImportPending || submitted.Total != 5 || submitted.ID == "" {
+		t.Fatalf("Expected a pending job of 5 rows, got %+v", submitted)
+	}
+	svc.Start()
+	defer svc.Stop(context.Background())
+	job := waitForImport(t, svc, submitted.ID)
+
+	// One update per batch of two, then the finished job
+	jobs.mu.Lock()
+	var progress []int
+	for _, snapshot := range jobs.snapshots {
+		progress = append(progress, snapshot.Processed)
+		if snapshot.Imported+snapshot.Failed != snapshot.Processed {
+			t.Errorf("Expected imported and failed to add up to processed, got %+v", snapshot)
+		}
+	}
+	last := jobs.snapshots[len(jobs.snapshots)-1]
+	jobs.mu.Unlock()
+	if want := []int{2, 4, 5}; !slices.Equal(progress, want) || last.Status != domain.ImportCompleted {
+		t.Errorf("Expected progress %v ending completed, got %v ending %s", want, progress, last.Status)
+	}
+
+	if job.Status != domain.ImportCompleted || job.Imported != 3 || job.Failed != 2 || job.FinishedAt == nil {
+		t.Fatalf("Expected 3 imported and 2 failed, got %+v", job)
+	}
+	if len(job.Errors) != 2 || job.Errors[0].Row != 3 || !strings.Contains(job.Errors[0].Error, "latitude") ||
+		job.Errors[1].Row != 5 || job.Errors[1].Name != "Leeta Ikeja" {
+		t.Errorf("Expected errors on rows 3 and 5, got %+v", job.Errors)
+	}
+	if location, err := locations.GetLocation("Leeta Wuse"); err != nil || location.Region != "Abuja" {
+		t.Errorf("Expected Leeta Wuse imported in Abuja, got %+v (%v)", location, err)
+	}
+}
+
+func TestImportJobCancelledBetweenBatches(t *testing.T) {
+	t.Parallel()
+	locations := newGatedLocations("Leeta Lekki")
+	svc := service.NewImportService(memory.NewInMemoryJobRepository(), locations, 2, time.Minute)
+	submitted, err := svc.Submit([]byte(importCSV))
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	svc.Start()
+	defer svc.Stop(context.Background())
+
+	// The first row of the second batch is being created
+	<-locations.reached
+	running, err := svc.Cancel(submitted.ID)
+	if err != nil || running.Status != domain.ImportRunning || !running.CancelRequested || running.Processed != 2 {
+		t.Fatalf("Expected the running job asked to stop after its first batch, got %+v (%v)", running, err)
+	}
+	close(locations.release)
+
+	job := waitForImport(t, svc, submitted.ID)
+	if job.Status != domain.ImportCancelled || job.Processed != 4 || job.Imported != 2 {
+		t.Errorf("Expected the job cancelled once its second batch finished, got %+v", job)
+	}
+	if _, err := locations.GetLocation("Leeta Wuse"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected the rows after the cancel not imported, got %v", err)
+	}
+	if again, err := svc.Cancel(submitted.ID); err != nil || again.Status != domain.ImportCancelled {
+		t.Errorf("Expected a finished job unchanged by cancel, got %+v (%v)", again, err)
+	}
+	if _, err := svc.Cancel("missing"); !errors.Is(err, domain.ErrImportNotFound) {
+		t.Errorf("Expected ErrImportNotFound, got %v", err)
+	}
+}
+
+func TestImportJobFailsWhenWorkerStops(t *testing.T) {
+	t.Parallel()
+	locations := newGatedLocations("Leeta Lekki")
+	svc := service.NewImportService(memory.NewInMemoryJobRepository(), locations, 2, time.Minute)
+	submitted, err := svc.Submit([]byte(importCSV))
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	svc.Start()
+
+	// Stop signals the worker before it waits, so an expired context
+	// returns at once with the worker told to stop
+	<-locations.reached
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := svc.Stop(expired); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected Stop to give up waiting, got %v", err)
+	}
+	close(locations.release)
+
+	job := waitForImport(t, svc, submitted.ID)
+	if job.Status != domain.ImportFailed || job.Processed != 4 || !strings.Contains(job.Error, "shut down") {
+		t.Errorf("Expected the job failed after its batch on shutdown, got %+v", job)
+	}
+}
+
+func TestImportRejectsFileWithoutRequiredColumns(t *testing.T) {
+	t.Parallel()
+	svc := service.NewImportService(memory.NewInMemoryJobRepository(), service.NewLocationService(memory.NewInMemoryLocationRepository()), 0, 0)
+	for _, data := range []string{"", "name,latitude\nIkeja,6.6\n"} {
+		if _, err := svc.Submit([]byte(data)); !errors.Is(err, domain.ErrInvalidImportFile) {
+			t.Errorf("Expected ErrInvalidImportFile for %q, got %v", data, err)
+		}
+	}
+
+	job, err := svc.Import(context.Background(), []byte("Name,Latitude,Longitude,notes\n\"Leeta, Ikeja\",6.6018,3.3515,ignored\n"))
+	if err != nil || job.Status != domain.ImportCompleted || job.Imported != 1 {
+		t.Errorf("Expected a synchronous import of one row, got %+v (%v)", job, err)
+	}
+}
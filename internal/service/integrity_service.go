@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// maxIntegrityIssues caps the issues a report lists; counts stay exact
+const maxIntegrityIssues = 1000
+
+// IntegrityService scans stored locations against the current domain rules
+// and keeps a report of the latest scan
+type IntegrityService struct {
+	store     domain.IntegrityStore
+	batchSize int
+	now       func() time.Time
+	publisher domain.EventPublisher
+
+	mu     sync.Mutex
+	report domain.IntegrityReport
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// IntegrityServiceOption configures an IntegrityService
+type IntegrityServiceOption func(*IntegrityService)
+
+// WithIntegrityEventPublisher publishes a location.updated event for each
+// location a fix renames, for stores without an outbox
+func WithIntegrityEventPublisher(publisher domain.EventPublisher) IntegrityServiceOption {
+	return func(s *IntegrityService) {
+		s.publisher = publisher
+	}
+}
+
+// NewIntegrityService creates an integrity service that reads batchSize
+// locations at a time
+func NewIntegrityService(store domain.IntegrityStore, batchSize int, opts ...IntegrityServiceOption) *IntegrityService {
+	s := &IntegrityService{
+		store:     store,
+		batchSize: batchSize,
+		now:       time.Now,
+		report: domain.IntegrityReport{
+			Status: domain.IntegrityIdle,
+			Counts: map[string]int{},
+			Issues: []domain.IntegrityIssue{},
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start begins a scan in the background and returns at once. fix is empty
+// or domain.IntegrityFixTrim. It returns domain.ErrIntegrityCheckRunning
+// when a scan is already running.
+func (s *IntegrityService) Start(fix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.report.Status == domain.IntegrityRunning {
+		return domain.ErrIntegrityCheckRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.begin(fix)
+
+	go func(done chan struct{}) {
+		defer close(done)
+		defer cancel()
+		s.scan(ctx, fix)
+	}(s.done)
+	return nil
+}
+
+// Run scans in the foreground and returns the finished report
+func (s *IntegrityService) Run(ctx context.Context, fix string) (domain.IntegrityReport, error) {
+	s.mu.Lock()
+	if s.report.Status == domain.IntegrityRunning {
+		s.mu.Unlock()
+		return domain.IntegrityReport{}, domain.ErrIntegrityCheckRunning
+	}
+	s.begin(fix)
+	s.mu.Unlock()
+
+	s.scan(ctx, fix)
+	return s.Report(), nil
+}
+
+// Stop cancels a background scan and waits for it to record its result
+func (s *IntegrityService) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Report returns a copy of the latest report, which is partial while a
+// scan is running
+func (s *IntegrityService) Report() domain.IntegrityReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := s.report
+	report.Counts = maps.Clone(s.report.Counts)
+	report.Issues = append([]domain.IntegrityIssue{}, s.report.Issues...)
+	return report
+}
+
+// begin resets the report for a new scan; the caller holds mu
+func (s *IntegrityService) begin(fix string) {
+	startedAt := s.now()
+	s.report = domain.IntegrityReport{
+		Status:    domain.IntegrityRunning,
+		Fix:       fix,
+		StartedAt: &startedAt,
+		Counts:    map[string]int{},
+		Issues:    []domain.IntegrityIssue{},
+	}
+}
+
+func (s *IntegrityService) scan(ctx context.Context, fix string) {
+	log.Printf("Integrity check started (fix: %q)", fix)
+	for location, err := range s.store.StreamLocations(ctx, s.batchSize) {
+		if err != nil {
+			s.finish(err)
+			return
+		}
+
+		var issues []domain.IntegrityIssue
+		for _, problem := range domain.IntegrityProblems(location) {
+			issue := domain.IntegrityIssue{ID: location.ID, Name: location.Name, Problem: problem}
+			if problem == domain.IntegrityUntrimmedName && fix == domain.IntegrityFixTrim {
+				renamed := *location
+				renamed.Name = strings.TrimSpace(location.Name)
+				err := s.store.RenameLocation(location.ID, renamed.Name)
+				switch {
+				case err == nil:
+					issue.Fixed = true
+					s.publishRename(renamed)
+				case errors.Is(err, domain.ErrLocationExists), errors.Is(err, domain.ErrLocationNotFound):
+					// The trimmed name is taken, or the location was deleted
+					// since it was read; leave it for an operator
+				default:
+					s.finish(err)
+					return
+				}
+			}
+			log.Printf("Integrity check: location %s (%q) has %s (fixed: %t)", issue.ID, issue.Name, issue.Problem, issue.Fixed)
+			issues = append(issues, issue)
+		}
+		s.record(issues)
+	}
+	s.finish(nil)
+}
+
+// record adds one scanned location and its issues to the report
+func (s *IntegrityService) record(issues []domain.IntegrityIssue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.report.Scanned++
+	for _, issue := range issues {
+		s.report.Counts[issue.Problem]++
+		if len(s.report.Issues) < maxIntegrityIssues {
+			s.report.Issues = append(s.report.Issues, issue)
+		} else {
+			s.report.Truncated = true
+		}
+	}
+}
+
+func (s *IntegrityService) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finishedAt := s.now()
+	s.report.FinishedAt = &finishedAt
+	s.report.Status = domain.IntegrityComplete
+	if err != nil {
+		s.report.Status = domain.IntegrityFailed
+		s.report.Error = err.Error()
+		log.Printf("Integrity check failed after %d locations: %v", s.report.Scanned, err)
+		return
+	}
+	log.Printf("Integrity check complete: %d locations scanned, problems %v", s.report.Scanned, s.report.Counts)
+}
+
+// publishRename sends the event for a renamed location on the direct
+// in-process path, if configured
+func (s *IntegrityService) publishRename(location domain.Location) {
+	if s.publisher == nil {
+		return
+	}
+	if err := s.publisher.Publish(domain.NewEvent(domain.EventLocationUpdated, location)); err != nil {
+		log.Printf("Failed to publish %s event for %s: %v", domain.EventLocationUpdated, location.Name, err)
+	}
+}
@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// seedBadImport stores rows the domain rules reject, as an old import did
+func seedBadImport(t *testing.T) *memory.InMemoryLocationRepository {
+	t.Helper()
+	repo := memory.NewInMemoryLocationRepository()
+	for _, location := range []*domain.Location{
+		{Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515},
+		{Name: " Yaba\t", Latitude: 6.5095, Longitude: 3.3711},
+		{Name: "Gulf", Latitude: 0, Longitude: 0},
+		{Name: "   ", Latitude: 6.5, Longitude: 3.4},
+		{Name: "Lekki", Latitude: 6.4474, Longitude: 3.472},
+		{Name: "Lekki ", Latitude: 100, Longitude: 3.472},
+	} {
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to seed %q: %v", location.Name, err)
+		}
+	}
+	return repo
+}
+
+func TestIntegrityCheckReports(t *testing.T) {
+	t.Parallel()
+	repo := seedBadImport(t)
+	svc := service.NewIntegrityService(repo, 2)
+
+	if report := svc.Report(); report.Status != domain.IntegrityIdle {
+		t.Errorf("Expected an idle report before any scan, got %+v", report)
+	}
+
+	report, err := svc.Run(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	if report.Status != domain.IntegrityComplete || report.Scanned != 6 || report.StartedAt == nil || report.FinishedAt == nil {
+		t.Errorf("Expected a complete scan of 6 locations, got %+v", report)
+	}
+	wantCounts := map[string]int{
+		domain.IntegrityUntrimmedName:      2,
+		domain.IntegrityNullIsland:         1,
+		domain.IntegrityBlankName:          1,
+		domain.IntegrityInvalidCoordinates: 1,
+	}
+	if !reflect.DeepEqual(report.Counts, wantCounts) {
+		t.Errorf("Expected counts %v, got %v", wantCounts, report.Counts)
+	}
+	if len(report.Issues) != 5 || report.Issues[0].Name != " Yaba\t" || report.Issues[0].Fixed {
+		t.Errorf("Expected the offending locations in id order, unfixed, got %+v", report.Issues)
+	}
+
+	// Without a fix nothing changes
+	if _, err := repo.FindByName(" Yaba\t"); err != nil {
+		t.Errorf("Expected the scan to leave names alone, got %v", err)
+	}
+}
+
+func TestIntegrityCheckTrims(t *testing.T) {
+	t.Parallel()
+	repo := seedBadImport(t)
+	svc := service.NewIntegrityService(repo, 2)
+
+	report, err := svc.Run(context.Background(), domain.IntegrityFixTrim)
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	fixed := map[string]bool{}
+	for _, issue := range report.Issues {
+		fixed[issue.Name+"/"+issue.Problem] = issue.Fixed
+	}
+	want := map[string]bool{
+		" Yaba\t/" + domain.IntegrityUntrimmedName:     true,
+		"Gulf/" + domain.IntegrityNullIsland:           false,
+		"   /" + domain.IntegrityBlankName:             false,
+		"Lekki /" + domain.IntegrityUntrimmedName:      false,
+		"Lekki /" + domain.IntegrityInvalidCoordinates: false,
+	}
+	if !reflect.DeepEqual(fixed, want) {
+		t.Errorf("Expected only the safe trim applied, got %v", fixed)
+	}
+
+	if location, err := repo.FindByName("Yaba"); err != nil || location.ID != "2" {
+		t.Errorf("Expected Yaba trimmed in place, got %+v, %v", location, err)
+	}
+	if _, err := repo.FindByName("Lekki "); err != nil {
+		t.Errorf("Expected the trim onto a taken name to be skipped, got %v", err)
+	}
+
+	// A second scan finds only what the fix could not repair
+	report, _ = svc.Run(context.Background(), domain.IntegrityFixTrim)
+	if report.Counts[domain.IntegrityUntrimmedName] != 1 {
+		t.Errorf("Expected one untrimmed name left, got %v", report.Counts)
+	}
+}
+
+// stallingStore blocks its stream until released
+type stallingStore struct {
+	*memory.InMemoryLocationRepository
+	release chan struct{}
+}
+
+func (s *stallingStore) StreamLocations(ctx context.Context, batchSize int) iter.Seq2[*domain.Location, error] {
+	return func(yield func(*domain.Location, error) bool) {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			yield(nil, ctx.Err())
+			return
+		}
+		for location, err := range s.InMemoryLocationRepository.StreamLocations(ctx, batchSize) {
+			if !yield(location, err) {
+				return
+			}
+		}
+	}
+}
+
+func TestIntegrityCheckRunsInBackground(t *testing.T) {
+	t.Parallel()
+	store := &stallingStore{InMemoryLocationRepository: seedBadImport(t), release: make(chan struct{})}
+	svc := service.NewIntegrityService(store, 0)
+
+	if err := svc.Start(""); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	if report := svc.Report(); report.Status != domain.IntegrityRunning {
+		t.Errorf("Expected a running report while the scan is blocked, got %+v", report)
+	}
+	if err := svc.Start(""); !errors.Is(err, domain.ErrIntegrityCheckRunning) {
+		t.Errorf("Expected ErrIntegrityCheckRunning, got %v", err)
+	}
+
+	close(store.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for svc.Report().Status == domain.IntegrityRunning && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if report := svc.Report(); report.Status != domain.IntegrityComplete || report.Scanned != 6 {
+		t.Errorf("Expected the background scan to complete, got %+v", report)
+	}
+
+	// Stop cancels a running scan, which then reports the failure
+	store.release = make(chan struct{})
+	if err := svc.Start(""); err != nil {
+		t.Fatalf("Failed to restart: %v", err)
+	}
+	svc.Stop()
+	if report := svc.Report(); report.Status != domain.IntegrityFailed || report.Error == "" {
+		t.Errorf("Expected a cancelled scan to fail, got %+v", report)
+	}
+}
+
+func TestIntegrityCheckReportsMissingCreatedAt(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	if err := repo.Restore([]domain.Location{
+		{ID: "1", Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515, CreatedAt: time.Now()},
+		{ID: "2", Name: "Legacy", Latitude: 6.5, Longitude: 3.3},
+	}); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+
+	report, err := service.NewIntegrityService(repo, 10).Run(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	want := []domain.IntegrityIssue{{ID: "2", Name: "Legacy", Problem: domain.IntegrityMissingCreatedAt}}
+	if !reflect.DeepEqual(report.Issues, want) || report.Counts[domain.IntegrityMissingCreatedAt] != 1 {
+		t.Errorf("Expected Legacy reported without a creation time, got %+v", report)
+	}
+}
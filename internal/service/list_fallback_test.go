@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// failingListRepository fails listings once err is set
+type failingListRepository struct {
+	*memory.InMemoryLocationRepository
+	err error
+}
+
+func (r *failingListRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.InMemoryLocationRepository.Find(filter, page, order)
+}
+
+func TestListLocationsFallsBackToLastKnownGood(t *testing.T) {
+	t.Parallel()
+	repo := &failingListRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	svc := service.NewLocationService(repo, service.WithClock(func() time.Time { return now }),
+		service.WithListFallback(cache.NewLastKnownGood(0), time.Minute))
+	for _, name := range []string{"Ikeja", "Yaba", "Lekki"} {
+		if _, err := svc.CreateLocation(name, 6.5, 3.35); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	ctx := context.Background()
+	remembered := now
+
+	fresh, err := svc.ListLocations(ctx, domain.ListOptions{})
+	if err != nil || fresh.Stale || fresh.Total != 3 {
+		t.Fatalf("Expected a fresh listing of 3, got %+v, %v", fresh, err)
+	}
+	// The repository cuts pages itself, so each page is remembered apart
+	if fresh, err := svc.ListLocations(ctx, domain.ListOptions{Offset: 1, Limit: 1}); err != nil || fresh.Total != 3 || !fresh.More {
+		t.Fatalf("Expected the second of 3 locations, got %+v, %v", fresh, err)
+	}
+
+	repo.err = errors.New("connection refused")
+	now = now.Add(30 * time.Second)
+	stale, err := svc.ListLocations(ctx, domain.ListOptions{Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatalf("Expected the last known good listing, got %v", err)
+	}
+	if !stale.Stale || !stale.AsOf.Equal(remembered) || stale.Total != 3 || len(stale.Items) != 1 || stale.Items[0].Name != "Yaba" {
+		t.Errorf("Expected the second of 3 remembered locations, stale as of %v, got %+v", remembered, stale)
+	}
+
+	if !stale.More {
+		t.Errorf("Expected the remembered page to have more after it, got %+v", stale)
+	}
+	// An open filter cuts its page from the whole listing remembered
+	open := domain.OpenAt{Time: now}
+	if page, err := svc.ListLocations(ctx, domain.ListOptions{Open: open, Offset: 2, Limit: 1}); err != nil || !page.Stale || len(page.Items) != 1 || page.Items[0].Name != "Lekki" {
+		t.Errorf("Expected the third remembered location, got %+v, %v", page, err)
+	}
+
+	// Another query or page was never listed, and a conditional request
+	// is never answered stale
+	if _, err := svc.ListLocations(ctx, domain.ListOptions{Filter: domain.LocationFilter{NameContains: "ik"}}); !errors.Is(err, repo.err) {
+		t.Errorf("Expected the repository error for an unseen query, got %v", err)
+	}
+	if _, err := svc.ListLocations(ctx, domain.ListOptions{Offset: 2, Limit: 1}); !errors.Is(err, repo.err) {
+		t.Errorf("Expected the repository error for an unseen page, got %v", err)
+	}
+	if _, err := svc.ListLocations(ctx, domain.ListOptions{Fresh: true}); !errors.Is(err, repo.err) {
+		t.Errorf("Expected the repository error for a fresh listing, got %v", err)
+	}
+
+	now = remembered.Add(time.Minute + time.Second)
+	if _, err := svc.ListLocations(ctx, domain.ListOptions{}); !errors.Is(err, repo.err) {
+		t.Errorf("Expected the repository error once the listing is over a minute old, got %v", err)
+	}
+
+	// A successful listing is remembered again
+	repo.err = nil
+	svc.ListLocations(ctx, domain.ListOptions{})
+	repo.err = errors.New("connection refused")
+	if page, err := svc.ListLocations(ctx, domain.ListOptions{}); err != nil || !page.AsOf.Equal(now) {
+		t.Errorf("Expected the listing remembered at %v, got %+v, %v", now, page, err)
+	}
+}
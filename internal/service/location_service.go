@@ -1,34 +1,239 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 type LocationService struct {
-	repo domain.LocationRepository
+	repo      domain.LocationRepository
+	publisher domain.EventPublisher
+	precision int
+
+	fallback      domain.NearestFallback
+	nearestBudget time.Duration
+
+	// lists answers listings the repository fails, if remembered within
+	// listMaxAge
+	lists      domain.ListFallback
+	listMaxAge time.Duration
+
+	// budgeted answers nearest searches in place of repo when set
+	budgeted domain.BudgetedNearestFinder
+	// scanBudget bounds stocked nearest searches when set
+	scanBudget domain.ScanBudget
+
+	now func() time.Time
+
+	// unknownHoursOpen decides whether stations without opening hours pass
+	// an open filter
+	unknownHoursOpen bool
+	// openCandidates caps the closed stations an open nearest search
+	// passes over
+	openCandidates int
+
+	maxDescriptionLength int
+
+	blocklist        *text.Blocklist
+	reservedPrefixes []string
+
+	regions        []string
+	regionRequired bool
+
+	attachments       domain.AttachmentPolicy
+	attachmentChecker domain.AttachmentChecker
+
+	// suggestWeight is the share of a suggestion's score given to nearness
+	suggestWeight float64
+}
+
+// LocationServiceOption configures optional LocationService behaviour
+type LocationServiceOption func(*LocationService)
+
+// WithEventPublisher publishes change events directly after each mutation.
+// Backends with a transactional outbox publish through the dispatcher instead.
+func WithEventPublisher(publisher domain.EventPublisher) LocationServiceOption {
+	return func(s *LocationService) {
+		s.publisher = publisher
+	}
+}
+
+// WithCoordinatePrecision sets the decimal places coordinates are rounded to
+// before they are stored
+func WithCoordinatePrecision(places int) LocationServiceOption {
+	return func(s *LocationService) {
+		if places > 0 {
+			s.precision = places
+		}
+	}
+}
+
+// WithNearestFallback answers nearest searches from fallback, marked stale,
+// when the repository fails or takes longer than budget. A zero budget
+// falls back on errors only.
+func WithNearestFallback(fallback domain.NearestFallback, budget time.Duration) LocationServiceOption {
+	return func(s *LocationService) {
+		s.fallback = fallback
+		s.nearestBudget = budget
+	}
+}
+
+// WithListFallback remembers every listing the repository returns in
+// lists and, when the repository fails a listing, answers from the one
+// remembered for the same query, marked stale, if it is at most maxAge old
+func WithListFallback(lists domain.ListFallback, maxAge time.Duration) LocationServiceOption {
+	return func(s *LocationService) {
+		s.lists = lists
+		s.listMaxAge = maxAge
+	}
+}
+
+// WithBudgetedNearest sends nearest searches to finder, normally the
+// repository itself, which may answer approximately or refuse to stay
+// within its scan budget
+func WithBudgetedNearest(finder domain.BudgetedNearestFinder) LocationServiceOption {
+	return func(s *LocationService) {
+		s.budgeted = finder
+	}
+}
+
+// WithScanBudget holds stocked nearest searches to budget. Stations
+// holding too little count against it, so the repository must implement
+// domain.BudgetedStockedFinder.
+func WithScanBudget(budget domain.ScanBudget) LocationServiceOption {
+	return func(s *LocationService) {
+		s.scanBudget = budget
+	}
+}
+
+// WithClock sets the clock that stamps created_at on new locations and
+// that open_now filters read. The postgres store stamps rows with database
+// time instead.
+func WithClock(now func() time.Time) LocationServiceOption {
+	return func(s *LocationService) {
+		s.now = now
+	}
+}
+
+// WithUnknownHoursOpen sets whether stations without opening hours count
+// as open; they do by default
+func WithUnknownHoursOpen(open bool) LocationServiceOption {
+	return func(s *LocationService) {
+		s.unknownHoursOpen = open
+	}
+}
+
+// WithOpenCandidates sets how many of the nearest stations an open
+// nearest search checks before answering domain.NoOpenWithinError; 0
+// keeps domain.DefaultOpenCandidates
+func WithOpenCandidates(candidates int) LocationServiceOption {
+	return func(s *LocationService) {
+		if candidates > 0 {
+			s.openCandidates = candidates
+		}
+	}
+}
+
+// WithDescriptionMaxLength sets the most characters a description may have
+func WithDescriptionMaxLength(characters int) LocationServiceOption {
+	return func(s *LocationService) {
+		if characters > 0 {
+			s.maxDescriptionLength = characters
+		}
+	}
+}
+
+// WithNamePolicy rejects names matching blocklist, and names starting with
+// any of reservedPrefixes unless the caller is privileged. Both checks
+// ignore case.
+func WithNamePolicy(blocklist *text.Blocklist, reservedPrefixes []string) LocationServiceOption {
+	return func(s *LocationService) {
+		s.blocklist = blocklist
+		s.reservedPrefixes = reservedPrefixes
+	}
+}
+
+// WithRegions limits regions to the listed names; an empty list accepts
+// any region. With required set, creates and nearest
+// searches must name a region.
+func WithRegions(regions []string, required bool) LocationServiceOption {
+	return func(s *LocationService) {
+		s.regions = regions
+		s.regionRequired = required
+	}
+}
+
+// WithAttachmentPolicy sets the rules attachment URLs must pass
+func WithAttachmentPolicy(policy domain.AttachmentPolicy) LocationServiceOption {
+	return func(s *LocationService) {
+		s.attachments = policy
+	}
+}
+
+// WithAttachmentChecker verifies that every attachment URL resolves before
+// a location is stored. Reads never check.
+func WithAttachmentChecker(checker domain.AttachmentChecker) LocationServiceOption {
+	return func(s *LocationService) {
+		s.attachmentChecker = checker
+	}
 }
 
-func NewLocationService(repo domain.LocationRepository) domain.LocationService {
-	return &LocationService{
-		repo: repo,
+// WithSuggestDistanceWeight sets the share of a suggestion's score given
+// to nearness, clamped to 0 to 1; the default is
+// domain.DefaultSuggestDistanceWeight
+func WithSuggestDistanceWeight(weight float64) LocationServiceOption {
+	return func(s *LocationService) {
+		s.suggestWeight = min(max(weight, 0), 1)
 	}
 }
 
-func (s *LocationService) CreateLocation(name string, latitude, longitude float64) (*domain.Location, error) {
+func NewLocationService(repo domain.LocationRepository, opts ...LocationServiceOption) domain.LocationService {
+	s := &LocationService{
+		repo:      repo,
+		precision: geospatial.DefaultCoordinatePrecision,
+		now:       time.Now,
+
+		unknownHoursOpen: true,
+		openCandidates:   domain.DefaultOpenCandidates,
+
+		maxDescriptionLength: domain.DefaultDescriptionMaxLength,
+		suggestWeight:        domain.DefaultSuggestDistanceWeight,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *LocationService) CreateLocation(name string, latitude, longitude float64, opts ...domain.CreateOption) (*domain.Location, error) {
 	log.Printf("Creating location: %s at (%.6f, %.6f)", name, latitude, longitude)
 
-	location, err := domain.NewLocation(name, latitude, longitude)
+	var options domain.CreateOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	location, err := s.prepare(name, latitude, longitude, options)
 	if err != nil {
-		log.Printf("Failed to create location %s: %v", name, err)
 		return nil, err
 	}
 
 	existing, _ := s.repo.FindByName(name)
 	if existing != nil {
 		log.Printf("Location %s already exists", name)
-		return nil, domain.ErrLocationExists
+		return nil, domain.NameConflict(name, existing)
 	}
 
 	err = s.repo.Save(location)
@@ -38,9 +243,107 @@ func (s *LocationService) CreateLocation(name string, latitude, longitude float6
 	}
 
 	log.Printf("Successfully created location: %s", name)
+	s.publish(domain.EventLocationCreated, location)
 	return location, nil
 }
 
+// prepare validates a new location against the service's policies and
+// canonicalizes it for storing
+func (s *LocationService) prepare(name string, latitude, longitude float64, options domain.CreateOptions) (*domain.Location, error) {
+	location, err := domain.NewLocation(name, latitude, longitude)
+	if err != nil {
+		log.Printf("Failed to create location %s: %v", name, err)
+		return nil, err
+	}
+	if !s.nameAllowed(location.Name, options.Privileged) {
+		log.Printf("Rejected location name %s", location.Name)
+		return nil, domain.ErrNameNotAllowed
+	}
+
+	if options.OpeningHours != nil {
+		if err := options.OpeningHours.Validate(); err != nil {
+			log.Printf("Rejected opening hours for %s: %v", name, err)
+			return nil, err
+		}
+		location.OpeningHours = options.OpeningHours
+	}
+
+	// Length is checked after cleaning, so stripped characters do not count
+	location.Description = domain.CleanDescription(options.Description)
+	if length := utf8.RuneCountInString(location.Description); length > s.maxDescriptionLength {
+		log.Printf("Rejected description for %s: %d characters", name, length)
+		return nil, &domain.DescriptionTooLongError{Length: length, Max: s.maxDescriptionLength}
+	}
+
+	region, err := s.region(options.Region, s.regionRequired)
+	if err != nil {
+		log.Printf("Rejected region %q for %s: %v", options.Region, name, err)
+		return nil, err
+	}
+	location.Region = region
+
+	if err := s.checkAttachments(options.Attachments); err != nil {
+		log.Printf("Rejected attachments for %s: %v", name, err)
+		return nil, err
+	}
+	location.Attachments = options.Attachments
+
+	location.CreatedBy = options.CreatedBy
+	location.CreatedAt = s.now()
+
+	// Canonicalize after validation, so out-of-range input is not rounded
+	// into range, and before storing so every read returns the same value
+	location.Latitude = geospatial.RoundCoordinate(location.Latitude, s.precision)
+	location.Longitude = geospatial.RoundCoordinate(location.Longitude, s.precision)
+	return location, nil
+}
+
+// nameAllowed applies the name policy to a trimmed name
+func (s *LocationService) nameAllowed(name string, privileged bool) bool {
+	if domain.IsRouteName(name) || s.blocklist.Blocked(name) {
+		return false
+	}
+	if privileged {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, prefix := range s.reservedPrefixes {
+		if prefix != "" && strings.HasPrefix(lower, strings.ToLower(prefix)) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAttachments applies the attachment policy, then the liveness check
+// when one is configured, so a bad URL is never fetched
+func (s *LocationService) checkAttachments(attachments []domain.Attachment) error {
+	if err := s.attachments.Validate(attachments); err != nil {
+		return err
+	}
+	if s.attachmentChecker == nil {
+		return nil
+	}
+	for i, attachment := range attachments {
+		if err := s.attachmentChecker.CheckAttachment(attachment.URL); err != nil {
+			return &domain.AttachmentError{Index: i, Reason: err.Error(), Err: domain.ErrAttachmentUnreachable}
+		}
+	}
+	return nil
+}
+
+// region trims a region and checks it against the configured list
+func (s *LocationService) region(region string, required bool) (string, error) {
+	region = strings.TrimSpace(region)
+	switch {
+	case region == "" && required:
+		return "", domain.ErrRegionRequired
+	case region != "" && len(s.regions) > 0 && !slices.Contains(s.regions, region):
+		return "", domain.ErrInvalidRegion
+	}
+	return region, nil
+}
+
 func (s *LocationService) GetLocation(name string) (*domain.Location, error) {
 	return s.repo.FindByName(name)
 }
@@ -53,17 +356,423 @@ func (s *LocationService) GetAllLocations() ([]*domain.Location, error) {
 	return s.repo.FindAll()
 }
 
-func (s *LocationService) DeleteLocation(name string) error {
+// ListLocations returns the page opts selects of the locations passing its
+// filter and open at the time its open filter selects. A repository that
+// is a domain.PagedFinder and can page the sort reads only the page and a
+// count of the matches. Otherwise, and always with an open filter, which
+// is applied after the repository's, every match is read and the page cut
+// from them.
+func (s *LocationService) ListLocations(ctx context.Context, opts domain.ListOptions) (domain.ListPage[*domain.Location], error) {
+	var page domain.ListPage[*domain.Location]
+	if err := opts.Validate(); err != nil {
+		return page, err
+	}
+	if err := ctx.Err(); err != nil {
+		return page, err
+	}
+	region, err := s.region(opts.Filter.Region, false)
+	if err != nil {
+		return page, err
+	}
+	filter := opts.Filter
+	filter.Region = region
+
+	finder, ok := s.repo.(domain.PagedFinder)
+	if ok && opts.Limit > 0 && opts.Open.IsZero() && finder.PagesSort(opts.Sort) {
+		window := domain.Page{Offset: opts.Offset, Limit: opts.Limit}
+		return s.readList(ctx, opts, listKey(filter, opts.Sort, window), func() (domain.ListPage[*domain.Location], error) {
+			locations, err := s.repo.Find(filter, window, opts.Sort)
+			if err != nil {
+				return page, err
+			}
+			total, err := finder.CountMatching(filter)
+			if err != nil {
+				return page, err
+			}
+			return domain.ListPage[*domain.Location]{Items: locations, Total: total, More: opts.Offset+len(locations) < total}, nil
+		})
+	}
+
+	page, err = s.readList(ctx, opts, listKey(filter, opts.Sort, domain.Page{}), func() (domain.ListPage[*domain.Location], error) {
+		locations, err := s.repo.Find(filter, domain.Page{}, opts.Sort)
+		return domain.ListPage[*domain.Location]{Items: locations, Total: len(locations)}, err
+	})
+	if err != nil {
+		return page, err
+	}
+	locations := page.Items
+	if !opts.Open.IsZero() {
+		at := s.openTime(opts.Open)
+		matching := make([]*domain.Location, 0, len(locations))
+		for _, location := range locations {
+			if s.isOpen(location, at) {
+				matching = append(matching, location)
+			}
+		}
+		locations = matching
+	}
+
+	page.Total = len(locations)
+	start := min(opts.Offset, page.Total)
+	end := page.Total
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, page.Total)
+	}
+	page.Items = locations[start:end]
+	page.More = end < page.Total
+	return page, nil
+}
+
+// readList reads a listing from the repository, remembering it under key
+// in the list fallback, or answers from the fallback when the read fails
+func (s *LocationService) readList(ctx context.Context, opts domain.ListOptions, key string, read func() (domain.ListPage[*domain.Location], error)) (domain.ListPage[*domain.Location], error) {
+	stop := telemetry.TrackRepo(ctx)
+	page, err := read()
+	stop()
+	if s.lists == nil {
+		return page, err
+	}
+	switch {
+	case err == nil:
+		s.lists.RememberList(key, page, s.now())
+	case !opts.Fresh && ctx.Err() == nil:
+		remembered, asOf, ok := s.lists.RecallList(key)
+		if !ok || s.now().Sub(asOf) > s.listMaxAge {
+			metrics.ListFallbacks.WithLabelValues("unavailable").Inc()
+			break
+		}
+		metrics.ListFallbacks.WithLabelValues("served").Inc()
+		log.Printf("Listing locations failed, serving the listing from %s: %v", asOf.Format(time.RFC3339), err)
+		page, err = remembered, nil
+		page.Stale, page.AsOf = true, asOf
+	}
+	return page, err
+}
+
+// listKey identifies a listing query for a ListFallback. The window is
+// part of it only for pages the repository cut; the zero window keys the
+// whole listing, which every page of the query is cut from.
+func listKey(filter domain.LocationFilter, order domain.LocationSort, window domain.Page) string {
+	bbox := ""
+	if filter.BBox != nil {
+		bbox = fmt.Sprint(*filter.BBox)
+	}
+	return fmt.Sprintf("%s|%s|%q|%t|%s|%q|%q|%s|%t|%d|%d",
+		filter.CreatedAfter.Format(time.RFC3339Nano), filter.CreatedBefore.Format(time.RFC3339Nano),
+		filter.NameContains, filter.SearchDescriptions, bbox, filter.Region, filter.AfterID, order.Field, order.Descending,
+		window.Offset, window.Limit)
+}
+
+// openTime resolves the time an open filter asks about
+func (s *LocationService) openTime(open domain.OpenAt) time.Time {
+	if open.Now {
+		return s.now()
+	}
+	return open.Time
+}
+
+func (s *LocationService) isOpen(location *domain.Location, at time.Time) bool {
+	if location.OpeningHours == nil {
+		return s.unknownHoursOpen
+	}
+	return location.OpeningHours.IsOpenAt(at)
+}
+
+// DeleteLocation deletes the location found by name if it passes checks,
+// which the repository runs in the same step as the delete
+func (s *LocationService) DeleteLocation(name string, checks ...domain.Precondition) error {
 	log.Printf("Deleting location: %s", name)
-	err := s.repo.Delete(name)
+
+	var location *domain.Location
+	if s.publisher != nil {
+		location, _ = s.repo.FindByName(name)
+	}
+
+	err := s.repo.Delete(name, checks...)
 	if err != nil {
 		log.Printf("Failed to delete location %s: %v", name, err)
 		return err
 	}
 	log.Printf("Successfully deleted location: %s", name)
+	s.publish(domain.EventLocationDeleted, location)
 	return nil
 }
 
-func (s *LocationService) FindNearest(latitude, longitude float64) (*domain.Location, float64, error) {
-	return s.repo.FindNearest(latitude, longitude)
+// publish sends a change event on the direct in-process path, if configured
+func (s *LocationService) publish(eventType domain.EventType, location *domain.Location) {
+	if s.publisher == nil || location == nil {
+		return
+	}
+	if err := s.publisher.Publish(domain.NewEvent(eventType, *location)); err != nil {
+		log.Printf("Failed to publish %s event for %s: %v", eventType, location.Name, err)
+	}
+}
+
+// findNearest searches one region, or every location when region is empty,
+// over the stations holding at least minStock litres. It gives up waiting
+// when ctx is done.
+func (s *LocationService) findNearest(ctx context.Context, region string, minStock, latitude, longitude float64, exclude []string) (*domain.NearestResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	find := s.repo.FindNearest
+	if region != "" {
+		finder, ok := s.repo.(domain.RegionalNearestFinder)
+		if !ok {
+			return nil, errRegionsUnsupported
+		}
+		find = func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+			return finder.FindNearestInRegion(region, latitude, longitude, exclude...)
+		}
+	}
+	search := func() (*domain.NearestResult, error) {
+		location, distance, err := find(latitude, longitude, exclude...)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.NearestResult{Location: location, Distance: distance}, nil
+	}
+	if s.budgeted != nil {
+		search = func() (*domain.NearestResult, error) {
+			return s.budgeted.FindNearestBudgeted(region, latitude, longitude, exclude...)
+		}
+	}
+	switch {
+	case minStock > 0 && s.scanBudget != (domain.ScanBudget{}):
+		finder, ok := s.repo.(domain.BudgetedStockedFinder)
+		if !ok {
+			return nil, errStockUnsupported
+		}
+		search = func() (*domain.NearestResult, error) {
+			return finder.FindNearestStockedWithin(s.scanBudget, region, minStock, latitude, longitude, exclude...)
+		}
+	case minStock > 0:
+		finder, ok := s.repo.(domain.StockedNearestFinder)
+		if !ok {
+			return nil, errStockUnsupported
+		}
+		search = func() (*domain.NearestResult, error) {
+			return finder.FindNearestStocked(region, minStock, latitude, longitude, exclude...)
+		}
+	}
+
+	repoSearch := search
+	search = func() (*domain.NearestResult, error) {
+		defer telemetry.TrackRepo(ctx)()
+		return repoSearch()
+	}
+
+	if s.fallback == nil {
+		return search()
+	}
+
+	type answer struct {
+		result *domain.NearestResult
+		err    error
+	}
+	// Buffered so an abandoned search can still finish and be collected
+	answers := make(chan answer, 1)
+	go func() {
+		result, err := search()
+		answers <- answer{result, err}
+	}()
+
+	var budget <-chan time.Time
+	if s.nearestBudget > 0 {
+		timer := time.NewTimer(s.nearestBudget)
+		defer timer.Stop()
+		budget = timer.C
+	}
+
+	var primaryErr error
+	select {
+	case a := <-answers:
+		if a.err == nil {
+			return a.result, nil
+		}
+		// No match is an answer, not a failure, and an over-budget search
+		// would only move the full scan to the snapshot
+		if errors.Is(a.err, domain.ErrLocationNotFound) || errors.Is(a.err, domain.ErrScanBudgetExceeded) {
+			return nil, a.err
+		}
+		primaryErr = a.err
+	case <-budget:
+		primaryErr = errNearestTooSlow
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// The snapshot's stock may be long out of date, and sending a driver
+	// to an empty station is worse than no answer
+	if minStock > 0 {
+		return nil, primaryErr
+	}
+	fallback := s.fallback.FindNearest
+	if region != "" {
+		regional, ok := s.fallback.(domain.RegionalNearestFallback)
+		if !ok {
+			return nil, primaryErr
+		}
+		fallback = func(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, time.Time, error) {
+			return regional.FindNearestInRegion(region, latitude, longitude, exclude...)
+		}
+	}
+	location, distance, asOf, err := fallback(latitude, longitude, exclude...)
+	if err != nil {
+		log.Printf("Nearest search failed (%v) and the fallback could not answer: %v", primaryErr, err)
+		return nil, primaryErr
+	}
+	log.Printf("Nearest search failed (%v); answered from the snapshot taken at %s", primaryErr, asOf.Format(time.RFC3339))
+	return &domain.NearestResult{Location: location, Distance: distance, Stale: true, AsOf: asOf}, nil
+}
+
+// FindNearest finds the nearest location in the query's region open at
+// the time its open filter selects, passing over closed ones nearest
+// first. Opening hours are read in each station's timezone, which the
+// repository cannot index, so each closed station costs a search; past
+// the configured number of candidates it gives up with a
+// *domain.NoOpenWithinError. It returns domain.ErrNoOpenLocation when
+// every candidate is closed, and domain.ErrLocationNotFound when there are
+// no candidates at all.
+func (s *LocationService) FindNearest(ctx context.Context, query domain.NearestQuery) (*domain.NearestResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	region, err := s.region(query.Filter.Region, s.regionRequired)
+	if err != nil {
+		return nil, err
+	}
+	latitude, longitude := query.Latitude, query.Longitude
+	minStock := query.Filter.MinStockLitres
+	open := query.Filter.Open
+	if open.IsZero() {
+		return s.findNearest(ctx, region, minStock, latitude, longitude, query.Exclude)
+	}
+
+	at := s.openTime(open)
+	exclude := append([]string{}, query.Exclude...)
+	closed := 0
+	for {
+		result, err := s.findNearest(ctx, region, minStock, latitude, longitude, exclude)
+		if errors.Is(err, domain.ErrLocationNotFound) && closed > 0 {
+			return nil, domain.ErrNoOpenLocation
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s.isOpen(result.Location, at) {
+			return result, nil
+		}
+		closed++
+		if closed >= s.openCandidates {
+			return nil, &domain.NoOpenWithinError{Candidates: closed}
+		}
+		exclude = append(exclude, result.Location.Name)
+	}
+}
+
+// FindNearestMatching is FindNearest with positional arguments.
+//
+// Deprecated: use FindNearest with a domain.NearestQuery.
+func (s *LocationService) FindNearestMatching(latitude, longitude float64, filter domain.NearestFilter, exclude ...string) (*domain.NearestResult, error) {
+	return s.FindNearest(context.Background(), domain.NearestQuery{Latitude: latitude, Longitude: longitude, Filter: filter, Exclude: exclude})
 }
+
+// maxLocationsAt caps the matches LocationsAt returns; more than one is
+// already ambiguous, the rest only help a caller clean up
+const maxLocationsAt = 10
+
+// LocationsAt returns the locations within toleranceM metres of the point,
+// nearest first, in the order FindNearest breaks ties in. The point is
+// rounded to the stored precision first, so a zero tolerance matches a
+// location stored at exactly these coordinates. It reads the repository
+// directly: an importer checking for duplicates must not be answered from
+// a stale fallback.
+func (s *LocationService) LocationsAt(latitude, longitude, toleranceM float64) ([]*domain.Location, error) {
+	finder, ok := s.repo.(domain.RadiusFinder)
+	if !ok {
+		return nil, errRadiusUnsupported
+	}
+	latitude = geospatial.RoundCoordinate(latitude, s.precision)
+	longitude = geospatial.RoundCoordinate(longitude, s.precision)
+
+	results, err := finder.FindWithinRadius(latitude, longitude, geospatial.Meters(toleranceM), maxLocationsAt)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*domain.Location, len(results))
+	for i, result := range results {
+		matches[i] = result.Location
+	}
+	return matches, nil
+}
+
+// AddAlias gives the location found by name an alternate name. The alias
+// is trimmed and must pass the same name policy as a location name. The
+// alias is added only if the location passes checks.
+func (s *LocationService) AddAlias(name, alias string, privileged bool, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := s.repo.(domain.LocationAliaser)
+	if !ok {
+		return nil, errAliasesUnsupported
+	}
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return nil, domain.ErrEmptyName
+	}
+	if !s.nameAllowed(alias, privileged) {
+		log.Printf("Rejected alias %s for %s", alias, name)
+		return nil, domain.ErrNameNotAllowed
+	}
+
+	location, err := aliaser.AddAlias(name, alias, checks...)
+	if err != nil {
+		log.Printf("Failed to add alias %s to %s: %v", alias, name, err)
+		return nil, err
+	}
+	log.Printf("Added alias %s to %s", alias, location.Name)
+	s.publish(domain.EventLocationUpdated, location)
+	return location, nil
+}
+
+// RemoveAlias drops an alternate name from the location found by name, if
+// it passes checks
+func (s *LocationService) RemoveAlias(name, alias string, checks ...domain.Precondition) (*domain.Location, error) {
+	aliaser, ok := s.repo.(domain.LocationAliaser)
+	if !ok {
+		return nil, errAliasesUnsupported
+	}
+
+	location, err := aliaser.RemoveAlias(name, alias, checks...)
+	if err != nil {
+		log.Printf("Failed to remove alias %s from %s: %v", alias, name, err)
+		return nil, err
+	}
+	log.Printf("Removed alias %s from %s", alias, location.Name)
+	s.publish(domain.EventLocationUpdated, location)
+	return location, nil
+}
+
+// UpdateStock changes the capacity and stock of the location found by
+// name, leaving the rest of it as it is, if it passes checks
+func (s *LocationService) UpdateStock(name string, update domain.StockUpdate, checks ...domain.Precondition) (*domain.Location, error) {
+	updater, ok := s.repo.(domain.StockUpdater)
+	if !ok {
+		return nil, errStockUnsupported
+	}
+
+	// Not logged: stations report stock far more often than anything else
+	location, err := updater.UpdateStock(name, update, checks...)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(domain.EventLocationUpdated, location)
+	return location, nil
+}
+
+var (
+	errNearestTooSlow     = errors.New("nearest search exceeded its latency budget")
+	errAliasesUnsupported = errors.New("repository does not support aliases")
+	errRegionsUnsupported = errors.New("repository does not support region searches")
+	errRadiusUnsupported  = errors.New("repository does not support radius searches")
+	errStockUnsupported   = errors.New("repository does not support stock")
+)
@@ -1,10 +1,15 @@
 package service_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/jesuloba-world/leeta-task/internal/domain"
 	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
 	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
 )
 
 func TestCreateLocation(t *testing.T) {
@@ -141,24 +146,27 @@ func TestFindNearest(t *testing.T) {
 	}
 
 	// Test finding nearest to a point near Chicago
-	nearest, distance, err := svc.FindNearest(42.0, -88.0)
+	nearest, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 42.0, Longitude: -88.0})
 	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	if nearest.Name != "Chicago" {
-		t.Errorf("Expected nearest location to be 'Chicago', got '%s'", nearest.Name)
+	if nearest.Location.Name != "Chicago" {
+		t.Errorf("Expected nearest location to be 'Chicago', got '%s'", nearest.Location.Name)
 	}
 
-	if distance <= 0 {
-		t.Errorf("Expected positive distance, got %f", distance)
+	if nearest.Distance <= 0 {
+		t.Errorf("Expected positive distance, got %f", nearest.Distance)
+	}
+	if nearest.Stale {
+		t.Error("Expected a fresh answer without a fallback")
 	}
 
 	// Test with empty repository
 	emptyRepo := memory.NewInMemoryLocationRepository()
 	emptySvc := service.NewLocationService(emptyRepo)
 
-	_, _, err = emptySvc.FindNearest(42.0, -88.0)
+	_, err = emptySvc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 42.0, Longitude: -88.0})
 	if err == nil {
 		t.Error("Expected error with empty repository, got nil")
 	}
@@ -228,4 +236,70 @@ func TestCreateLocationValidation(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestCreateLocationCanonicalizesCoordinates(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	svc := service.NewLocationService(repo)
+
+	created, err := svc.CreateLocation("Noisy", 40.712800000000004, -74.00600000000001)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.Latitude != 40.7128 || created.Longitude != -74.006 {
+		t.Errorf("Expected coordinates rounded to 6 places, got (%v, %v)", created.Latitude, created.Longitude)
+	}
+
+	stored, _ := repo.FindByName("Noisy")
+	if stored.Latitude != created.Latitude || stored.Longitude != created.Longitude {
+		t.Errorf("Expected stored coordinates to equal the created ones, got (%v, %v)", stored.Latitude, stored.Longitude)
+	}
+
+	coarse := service.NewLocationService(memory.NewInMemoryLocationRepository(), service.WithCoordinatePrecision(4))
+	created, _ = coarse.CreateLocation("Coarse", 6.44745, 3.47234)
+	if created.Latitude != 6.4475 || created.Longitude != 3.4723 {
+		t.Errorf("Expected coordinates rounded to 4 places, got (%v, %v)", created.Latitude, created.Longitude)
+	}
+}
+
+func TestFindNearestKeepsQueryPrecision(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	svc.CreateLocation("Origin", 6.5, 3.3)
+
+	// The query point is not rounded, so a sub-precision offset still
+	// yields a non-zero distance of about 4 cm
+	nearest, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5000004, Longitude: 3.3})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if distance := nearest.Distance; distance <= 0 || distance > geospatial.Meters(0.1) {
+		t.Errorf("Expected a distance of a few centimetres, got %v m", distance.Meters())
+	}
+}
+
+func TestCreateLocationNamePolicy(t *testing.T) {
+	t.Parallel()
+	blocklist, err := text.NewBlocklist([]string{"asdf"}, []string{`^test\d*$`})
+	if err != nil {
+		t.Fatalf("Failed to compile blocklist: %v", err)
+	}
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(),
+		service.WithNamePolicy(blocklist, []string{"internal-"}))
+
+	for _, name := range []string{"asdf", " ASDF ", "Test42", "Internal-Depot"} {
+		if _, err := svc.CreateLocation(name, 6.5, 3.4); !errors.Is(err, domain.ErrNameNotAllowed) {
+			t.Errorf("%q: expected ErrNameNotAllowed, got %v", name, err)
+		}
+	}
+
+	if _, err := svc.CreateLocation("internal-depot", 6.5, 3.4, domain.Privileged(true)); err != nil {
+		t.Errorf("Expected a privileged caller to use the reserved prefix, got %v", err)
+	}
+	if _, err := svc.CreateLocation("asdf", 6.5, 3.4, domain.Privileged(true)); !errors.Is(err, domain.ErrNameNotAllowed) {
+		t.Errorf("Expected the blocklist to apply to privileged callers too, got %v", err)
+	}
+	if _, err := svc.CreateLocation("Testing Ground", 6.5, 3.4); err != nil {
+		t.Errorf("Expected an unmatched name to be allowed, got %v", err)
+	}
+}
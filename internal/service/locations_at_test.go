@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestLocationsAt(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	// The annex is about 5.6 m north of Ikeja
+	if _, err := svc.CreateLocation("Ikeja", 6.6018, 3.3515); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if _, err := svc.CreateLocation("Ikeja Annex", 6.60185, 3.3515); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if _, err := svc.CreateLocation("Yaba", 6.5095, 3.3711); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		lat, lng   float64
+		toleranceM float64
+		want       []string
+	}{
+		{"exact", 6.6018, 3.3515, 0, []string{"Ikeja"}},
+		{"exact after rounding", 6.60180004, 3.3515, 0, []string{"Ikeja"}},
+		{"near, inside tolerance", 6.60181, 3.3515, 2, []string{"Ikeja"}},
+		{"near, outside tolerance", 6.60181, 3.3515, 1, nil},
+		{"several inside tolerance", 6.6018, 3.3515, 10, []string{"Ikeja", "Ikeja Annex"}},
+		{"nothing there", 0, 0, 100, nil},
+	}
+	for _, tt := range tests {
+		matches, err := svc.LocationsAt(tt.lat, tt.lng, tt.toleranceM)
+		if err != nil {
+			t.Fatalf("%s: failed to look up: %v", tt.name, err)
+		}
+		var names []string
+		for _, match := range matches {
+			names = append(names, match.Name)
+		}
+		if len(names) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.want[i] {
+				t.Errorf("%s: expected %v nearest first, got %v", tt.name, tt.want, names)
+				break
+			}
+		}
+	}
+}
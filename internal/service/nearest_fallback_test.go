@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/fallback"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// degradedRepository fails or stalls nearest searches on demand
+type degradedRepository struct {
+	*memory.InMemoryLocationRepository
+	err   error
+	stall chan struct{}
+}
+
+func (r *degradedRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	if r.stall != nil {
+		<-r.stall
+	}
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	return r.InMemoryLocationRepository.FindNearest(latitude, longitude, exclude...)
+}
+
+func setupFallback(t *testing.T) (*degradedRepository, *fallback.Snapshot, domain.LocationService) {
+	t.Helper()
+	primary := &degradedRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	snapshot := fallback.NewSnapshot(primary, time.Hour)
+	svc := service.NewLocationService(primary, service.WithNearestFallback(snapshot, 50*time.Millisecond))
+
+	svc.CreateLocation("Ikeja", 6.6018, 3.3515)
+	if err := snapshot.Refresh(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+	return primary, snapshot, svc
+}
+
+func TestFindNearestFallsBackOnError(t *testing.T) {
+	t.Parallel()
+	primary, snapshot, svc := setupFallback(t)
+
+	fresh, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.4})
+	if err != nil || fresh.Stale {
+		t.Fatalf("Expected a fresh answer from a healthy primary, got %+v, %v", fresh, err)
+	}
+
+	primary.err = errors.New("connection refused")
+	stale, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.4})
+	if err != nil {
+		t.Fatalf("Expected a fallback answer, got %v", err)
+	}
+	if !stale.Stale || stale.Location.Name != "Ikeja" || !stale.AsOf.Equal(snapshot.TakenAt()) {
+		t.Errorf("Expected a stale answer as of the snapshot, got %+v", stale)
+	}
+	if stale.Distance != fresh.Distance {
+		t.Errorf("Expected the same distance from the snapshot, got %v and %v", stale.Distance, fresh.Distance)
+	}
+
+	// Writes go only to the primary; the snapshot sees them after a refresh
+	if _, err := svc.CreateLocation("Yaba", 6.5095, 3.3711); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if _, err := primary.FindByName("Yaba"); err != nil {
+		t.Errorf("Expected the write in the primary, got %v", err)
+	}
+	if stale, _ := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5095, Longitude: 3.3711}); stale.Location.Name != "Ikeja" {
+		t.Errorf("Expected the snapshot not to see the write before a refresh, got %s", stale.Location.Name)
+	}
+}
+
+func TestFindNearestFallsBackWhenSlow(t *testing.T) {
+	t.Parallel()
+	primary, _, svc := setupFallback(t)
+	primary.stall = make(chan struct{})
+	defer close(primary.stall)
+
+	start := time.Now()
+	result, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.4})
+	if err != nil || !result.Stale {
+		t.Fatalf("Expected a stale answer once the budget ran out, got %+v, %v", result, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the fallback within the latency budget, took %v", elapsed)
+	}
+}
+
+func TestFindNearestFallbackLimits(t *testing.T) {
+	t.Parallel()
+
+	// No match is an answer and is not replaced by the snapshot
+	primary, _, svc := setupFallback(t)
+	if _, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.4, Exclude: []string{"Ikeja"}}); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound from the primary, got %v", err)
+	}
+
+	// Without a snapshot the primary's error is returned
+	primary = &degradedRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository(), err: errors.New("connection refused")}
+	svc = service.NewLocationService(primary, service.WithNearestFallback(fallback.NewSnapshot(primary, time.Hour), 0))
+	if _, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.4}); err != primary.err {
+		t.Errorf("Expected the primary error, got %v", err)
+	}
+}
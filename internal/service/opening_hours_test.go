@@ -0,0 +1,252 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func interval(opens, closes string) []domain.OpeningInterval {
+	return []domain.OpeningInterval{{Open: opens, Close: closes}}
+}
+
+func allWeek(opens, closes string) map[string][]domain.OpeningInterval {
+	days := map[string][]domain.OpeningInterval{}
+	for _, day := range []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"} {
+		days[day] = interval(opens, closes)
+	}
+	return days
+}
+
+// fakeClock is a settable clock for open_now filters
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestCreateLocationValidatesOpeningHours(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		hours domain.OpeningHours
+		valid bool
+	}{
+		{"24/7", domain.OpeningHours{Timezone: "Africa/Lagos", Days: allWeek("00:00", "24:00")}, true},
+		{"past midnight", domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{
+			"saturday": interval("22:00", "02:00"),
+			"sunday":   interval("02:00", "06:00"),
+		}}, true},
+		{"no days", domain.OpeningHours{Timezone: "Africa/Lagos"}, true},
+		{"unknown timezone", domain.OpeningHours{Timezone: "Africa/Atlantis", Days: allWeek("06:00", "22:00")}, false},
+		{"missing timezone", domain.OpeningHours{Days: allWeek("06:00", "22:00")}, false},
+		{"unknown day", domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{"funday": interval("06:00", "22:00")}}, false},
+		{"bad time", domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{"monday": interval("6am", "22:00")}}, false},
+		{"24:00 opening", domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{"monday": interval("24:00", "02:00")}}, false},
+		{"same day overlap", domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{
+			"monday": {{Open: "06:00", Close: "12:00"}, {Open: "11:00", Close: "18:00"}},
+		}}, false},
+		{"overlap past midnight", domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{
+			"saturday": interval("22:00", "02:00"),
+			"sunday":   interval("01:00", "06:00"),
+		}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+			hours := tt.hours
+			location, err := svc.CreateLocation("Yaba", 6.5095, 3.3711, domain.WithOpeningHours(&hours))
+			if tt.valid {
+				if err != nil || location.OpeningHours != &hours {
+					t.Errorf("Expected the hours stored, got %+v, %v", location, err)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrInvalidOpeningHours) {
+				t.Errorf("Expected ErrInvalidOpeningHours, got %v", err)
+			}
+		})
+	}
+}
+
+func TestListLocationsOpenNowAcrossDST(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	clock := &fakeClock{}
+	svc := service.NewLocationService(repo, service.WithClock(clock.Now))
+
+	// London keeps 09:00-17:00 on local time, so the UTC opening moves an
+	// hour earlier when British Summer Time starts on 30 March 2025
+	london := &domain.OpeningHours{Timezone: "Europe/London", Days: map[string][]domain.OpeningInterval{"monday": interval("09:00", "17:00")}}
+	if _, err := svc.CreateLocation("London", 51.5074, -0.1278, domain.WithOpeningHours(london)); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	// New York opens only 01:00-02:00 on Sunday, the hour that repeats when
+	// daylight saving ends on 2 November 2025
+	newYork := &domain.OpeningHours{Timezone: "America/New_York", Days: map[string][]domain.OpeningInterval{"sunday": interval("01:00", "02:00")}}
+	if _, err := svc.CreateLocation("New York", 40.7128, -74.006, domain.WithOpeningHours(newYork)); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	tests := []struct {
+		now  string
+		want []string
+	}{
+		{"2025-03-24T08:30:00Z", nil},                  // 08:30 GMT
+		{"2025-03-24T09:30:00Z", []string{"London"}},   // 09:30 GMT
+		{"2025-03-31T08:30:00Z", []string{"London"}},   // 09:30 BST
+		{"2025-03-31T16:30:00Z", nil},                  // 17:30 BST
+		{"2025-11-02T05:30:00Z", []string{"New York"}}, // 01:30 EDT
+		{"2025-11-02T06:30:00Z", []string{"New York"}}, // 01:30 EST
+		{"2025-11-02T07:30:00Z", nil},                  // 02:30 EST
+	}
+	for _, tt := range tests {
+		now, _ := time.Parse(time.RFC3339, tt.now)
+		clock.now = now
+		locations, err := svc.ListLocations(context.Background(), domain.ListOptions{Open: domain.OpenAt{Now: true}})
+		if err != nil {
+			t.Fatalf("%s: failed to list: %v", tt.now, err)
+		}
+		var names []string
+		for _, location := range locations.Items {
+			names = append(names, location.Name)
+		}
+		if len(names) != len(tt.want) || (len(names) > 0 && names[0] != tt.want[0]) {
+			t.Errorf("%s: expected %v open, got %v", tt.now, tt.want, names)
+		}
+	}
+}
+
+func TestListLocationsOpenAt(t *testing.T) {
+	t.Parallel()
+	lagos := "Africa/Lagos"
+	seed := func(t *testing.T, opts ...service.LocationServiceOption) domain.LocationService {
+		t.Helper()
+		svc := service.NewLocationService(memory.NewInMemoryLocationRepository(), opts...)
+		for name, hours := range map[string]*domain.OpeningHours{
+			"Allnight": {Timezone: lagos, Days: allWeek("00:00", "24:00")},
+			"Late":     {Timezone: lagos, Days: map[string][]domain.OpeningInterval{"saturday": interval("22:00", "02:00")}},
+			"Unknown":  nil,
+		} {
+			if _, err := svc.CreateLocation(name, 6.5, 3.4, domain.WithOpeningHours(hours)); err != nil {
+				t.Fatalf("Failed to create %s: %v", name, err)
+			}
+		}
+		return svc
+	}
+	openAt := func(t *testing.T, svc domain.LocationService, at string) map[string]bool {
+		t.Helper()
+		when, _ := time.Parse(time.RFC3339, at)
+		locations, err := svc.ListLocations(context.Background(), domain.ListOptions{Open: domain.OpenAt{Time: when}})
+		if err != nil {
+			t.Fatalf("Failed to list: %v", err)
+		}
+		open := map[string]bool{}
+		for _, location := range locations.Items {
+			open[location.Name] = true
+		}
+		return open
+	}
+
+	svc := seed(t)
+	// 16 August 2025 is a Saturday; Lagos is UTC+1 all year
+	for at, late := range map[string]bool{
+		"2025-08-16T21:59:00+01:00": false,
+		"2025-08-16T22:00:00+01:00": true,
+		"2025-08-16T23:00:00Z":      true, // Sunday 00:00 local
+		"2025-08-17T01:59:00+01:00": true,
+		"2025-08-17T02:00:00+01:00": false,
+	} {
+		open := openAt(t, svc, at)
+		if open["Late"] != late || !open["Allnight"] || !open["Unknown"] {
+			t.Errorf("%s: expected Late open %t with Allnight and Unknown always open, got %v", at, late, open)
+		}
+	}
+
+	strict := seed(t, service.WithUnknownHoursOpen(false))
+	if open := openAt(t, strict, "2025-08-18T12:00:00Z"); open["Unknown"] || !open["Allnight"] {
+		t.Errorf("Expected unknown hours closed when configured, got %v", open)
+	}
+}
+
+func TestFindNearestOpen(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	clock := &fakeClock{}
+	svc := service.NewLocationService(repo, service.WithClock(clock.Now))
+
+	closedSunday := &domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{"monday": interval("06:00", "22:00")}}
+	if _, err := svc.CreateLocation("Yaba", 6.5095, 3.3711, domain.WithOpeningHours(closedSunday)); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if _, err := svc.CreateLocation("Ikeja", 6.6018, 3.3515); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	// Monday midday Yaba is open and nearest
+	clock.now = time.Date(2025, 8, 18, 12, 0, 0, 0, time.UTC)
+	if result, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Open: domain.OpenAt{Now: true}}}); err != nil || result.Location.Name != "Yaba" {
+		t.Errorf("Expected Yaba while open, got %+v, %v", result, err)
+	}
+
+	// On Sunday the search passes over Yaba to Ikeja, whose hours are unknown
+	clock.now = time.Date(2025, 8, 17, 12, 0, 0, 0, time.UTC)
+	if result, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Open: domain.OpenAt{Now: true}}}); err != nil || result.Location.Name != "Ikeja" {
+		t.Errorf("Expected Ikeja while Yaba is closed, got %+v, %v", result, err)
+	}
+	if _, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Open: domain.OpenAt{Now: true}}, Exclude: []string{"Ikeja"}}); !errors.Is(err, domain.ErrNoOpenLocation) {
+		t.Errorf("Expected ErrNoOpenLocation when every candidate is closed, got %v", err)
+	}
+
+	// Without candidates at all the plain not found error stands
+	empty := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	if _, err := empty.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Open: domain.OpenAt{Now: true}}}); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound, got %v", err)
+	}
+}
+
+// countingNearestRepository counts nearest searches
+type countingNearestRepository struct {
+	*memory.InMemoryLocationRepository
+	searches int
+}
+
+func (r *countingNearestRepository) FindNearest(latitude, longitude float64, exclude ...string) (*domain.Location, geospatial.Distance, error) {
+	r.searches++
+	return r.InMemoryLocationRepository.FindNearest(latitude, longitude, exclude...)
+}
+
+func TestFindNearestOpenGivesUpAfterCandidates(t *testing.T) {
+	t.Parallel()
+	repo := &countingNearestRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	svc := service.NewLocationService(repo, service.WithOpenCandidates(3))
+
+	closed := &domain.OpeningHours{Timezone: "Africa/Lagos"}
+	for i := range 10 {
+		if _, err := svc.CreateLocation(fmt.Sprintf("Closed %d", i), 6.5+float64(i)/100, 3.37, domain.WithOpeningHours(closed)); err != nil {
+			t.Fatalf("Failed to create: %v", err)
+		}
+	}
+	if _, err := svc.CreateLocation("Open", 7.5, 3.37); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	_, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Open: domain.OpenAt{Now: true}}})
+	var within *domain.NoOpenWithinError
+	if !errors.As(err, &within) || within.Candidates != 3 || !errors.Is(err, domain.ErrNoOpenLocation) {
+		t.Fatalf("Expected no open location among the nearest 3, got %v", err)
+	}
+	if repo.searches != 3 {
+		t.Errorf("Expected 3 searches, got %d", repo.searches)
+	}
+}
@@ -0,0 +1,203 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestListOptionsValidate(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	tests := []struct {
+		name    string
+		opts    domain.ListOptions
+		wantErr error
+	}{
+		{"zero", domain.ListOptions{}, nil},
+		{"window", domain.ListOptions{Offset: 20, Limit: 10}, nil},
+		{"sort by name", domain.ListOptions{Sort: domain.LocationSort{Field: domain.SortByName, Descending: true}}, nil},
+		{"cursor", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "42"}, Limit: 10}, nil},
+		{"cursor in id order", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "42"}, Sort: domain.LocationSort{Field: domain.SortByID}}, nil},
+		{"negative offset", domain.ListOptions{Offset: -1}, domain.ErrInvalidListOptions},
+		{"negative limit", domain.ListOptions{Limit: -1}, domain.ErrInvalidListOptions},
+		{"unknown sort", domain.ListOptions{Sort: domain.LocationSort{Field: "distance"}}, domain.ErrInvalidListOptions},
+		{"cursor not a number", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "abc"}}, domain.ErrInvalidListOptions},
+		{"cursor and offset", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "42"}, Offset: 10}, domain.ErrInvalidListOptions},
+		{"cursor in name order", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "42"}, Sort: domain.LocationSort{Field: domain.SortByName}}, domain.ErrInvalidListOptions},
+		{"cursor descending", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "42"}, Sort: domain.LocationSort{Descending: true}}, domain.ErrInvalidListOptions},
+		{"created range", domain.ListOptions{Filter: domain.LocationFilter{CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)}}, domain.ErrInvalidCreatedRange},
+		{"open conflict", domain.ListOptions{Open: domain.OpenAt{Now: true, Time: now}}, domain.ErrOpenFilterConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Expected valid options, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNearestQueryValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		query   domain.NearestQuery
+		wantErr error
+	}{
+		{"point", domain.NearestQuery{Latitude: 6.5, Longitude: 3.37}, nil},
+		{"edges", domain.NearestQuery{Latitude: -90, Longitude: 180}, nil},
+		{"filtered", domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Region: "Lagos", Open: domain.OpenAt{Now: true}}}, nil},
+		{"latitude", domain.NearestQuery{Latitude: 90.5, Longitude: 3.37}, domain.ErrInvalidLatitude},
+		{"longitude", domain.NearestQuery{Latitude: 6.5, Longitude: -180.5}, domain.ErrInvalidLongitude},
+		{"open conflict", domain.NearestQuery{Filter: domain.NearestFilter{Open: domain.OpenAt{Now: true, Time: time.Now()}}}, domain.ErrOpenFilterConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.query.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestListLocationsPages(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	for _, name := range []string{"Yaba", "Ikeja", "Lekki", "Ajah", "Surulere"} {
+		if _, err := svc.CreateLocation(name, 6.5, 3.37); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	names := func(page domain.ListPage[*domain.Location]) string {
+		var names []string
+		for _, location := range page.Items {
+			names = append(names, location.Name)
+		}
+		return strings.Join(names, ",")
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		opts      domain.ListOptions
+		wantNames string
+		wantTotal int
+		wantMore  bool
+	}{
+		{"everything", domain.ListOptions{}, "Yaba,Ikeja,Lekki,Ajah,Surulere", 5, false},
+		{"first page", domain.ListOptions{Limit: 2}, "Yaba,Ikeja", 5, true},
+		{"last page", domain.ListOptions{Offset: 4, Limit: 2}, "Surulere", 5, false},
+		{"past the end", domain.ListOptions{Offset: 10, Limit: 2}, "", 5, false},
+		{"after a cursor", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "2"}, Limit: 2}, "Lekki,Ajah", 3, true},
+		{"sorted by name", domain.ListOptions{Sort: domain.LocationSort{Field: domain.SortByName}, Limit: 3}, "Ajah,Ikeja,Lekki", 5, true},
+		{"filtered", domain.ListOptions{Filter: domain.LocationFilter{NameContains: "e"}, Offset: 1}, "Lekki,Surulere", 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := svc.ListLocations(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to list: %v", err)
+			}
+			if got := names(page); got != tt.wantNames || page.Total != tt.wantTotal || page.More != tt.wantMore {
+				t.Errorf("Expected %q of %d (more %t), got %q of %d (more %t)", tt.wantNames, tt.wantTotal, tt.wantMore, got, page.Total, page.More)
+			}
+		})
+	}
+
+	if _, err := svc.ListLocations(ctx, domain.ListOptions{Limit: -1}); !errors.Is(err, domain.ErrInvalidListOptions) {
+		t.Errorf("Expected invalid options to be rejected, got %v", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := svc.ListLocations(cancelled, domain.ListOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled listing to stop, got %v", err)
+	}
+	if _, err := svc.FindNearest(cancelled, domain.NearestQuery{Latitude: 6.5, Longitude: 3.37}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled search to stop, got %v", err)
+	}
+}
+
+// pageRecordingRepository records the windows listings read and cannot
+// page a name order, as postgres without a collation for the language
+type pageRecordingRepository struct {
+	*memory.InMemoryLocationRepository
+	pages []domain.Page
+}
+
+func (r *pageRecordingRepository) Find(filter domain.LocationFilter, page domain.Page, order domain.LocationSort) ([]*domain.Location, error) {
+	r.pages = append(r.pages, page)
+	return r.InMemoryLocationRepository.Find(filter, page, order)
+}
+
+func (r *pageRecordingRepository) PagesSort(order domain.LocationSort) bool {
+	return order.Field != domain.SortByName
+}
+
+func TestListLocationsReadsOnlyThePage(t *testing.T) {
+	t.Parallel()
+	repo := &pageRecordingRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	svc := service.NewLocationService(repo)
+	for _, name := range []string{"Yaba", "Ikeja", "Lekki"} {
+		if _, err := svc.CreateLocation(name, 6.5, 3.37); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		opts     domain.ListOptions
+		wantPage domain.Page
+	}{
+		{"offset", domain.ListOptions{Offset: 1, Limit: 1}, domain.Page{Offset: 1, Limit: 1}},
+		{"cursor", domain.ListOptions{Filter: domain.LocationFilter{AfterID: "1"}, Limit: 1}, domain.Page{Limit: 1}},
+		{"order the store cannot index", domain.ListOptions{Sort: domain.LocationSort{Field: domain.SortByName}, Limit: 1}, domain.Page{}},
+		{"open filter", domain.ListOptions{Open: domain.OpenAt{Now: true}, Limit: 1}, domain.Page{}},
+	}
+	for _, tt := range tests {
+		repo.pages = nil
+		page, err := svc.ListLocations(ctx, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: failed to list: %v", tt.name, err)
+		}
+		if len(repo.pages) != 1 || repo.pages[0] != tt.wantPage {
+			t.Errorf("%s: expected the repository asked for %+v, got %+v", tt.name, tt.wantPage, repo.pages)
+		}
+		if len(page.Items) != 1 || !page.More {
+			t.Errorf("%s: expected one location with more after it, got %+v", tt.name, page)
+		}
+	}
+}
+
+func TestFindNearestMatchingWrapsFindNearest(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	svc.CreateLocation("Yaba", 6.5095, 3.3711)
+	svc.CreateLocation("Ikeja", 6.6018, 3.3515)
+
+	query := domain.NearestQuery{Latitude: 6.51, Longitude: 3.37, Exclude: []string{"Yaba"}}
+	want, err := svc.FindNearest(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	got, err := svc.FindNearestMatching(6.51, 3.37, domain.NearestFilter{}, "Yaba")
+	if err != nil || got.Location.Name != want.Location.Name || got.Distance != want.Distance {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+	if _, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 91}); !errors.Is(err, domain.ErrInvalidLatitude) {
+		t.Errorf("Expected the query validated, got %v", err)
+	}
+}
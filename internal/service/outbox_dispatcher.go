@@ -0,0 +1,116 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+// OutboxDispatcher polls the outbox and publishes pending events
+type OutboxDispatcher struct {
+	repo        domain.OutboxRepository
+	publisher   domain.EventPublisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewOutboxDispatcher(repo domain.OutboxRepository, publisher domain.EventPublisher, interval time.Duration, batchSize, maxAttempts int) *OutboxDispatcher {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+	return &OutboxDispatcher{
+		repo:        repo,
+		publisher:   publisher,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// RunOnce dispatches due events until a batch comes back short, then
+// refreshes the outbox metrics
+func (d *OutboxDispatcher) RunOnce() (int, error) {
+	total := 0
+	for {
+		n, err := d.repo.DispatchPending(d.batchSize, d.maxAttempts, d.publisher.Publish)
+		total += n
+		metrics.OutboxDelivered.Add(float64(n))
+		if err != nil {
+			return total, err
+		}
+		if n < d.batchSize {
+			break
+		}
+	}
+
+	d.refreshMetrics()
+	return total, nil
+}
+
+func (d *OutboxDispatcher) refreshMetrics() {
+	stats, err := d.repo.OutboxStats()
+	if err != nil {
+		log.Printf("Failed to read outbox stats: %v", err)
+		return
+	}
+	metrics.OutboxPending.Set(float64(stats.Pending))
+	metrics.OutboxFailed.Set(float64(stats.Failed))
+	metrics.OutboxLag.Set(stats.OldestPending.Seconds())
+}
+
+// Start polls the outbox every interval until Stop is called
+func (d *OutboxDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stop != nil {
+		return
+	}
+
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	stop, done := d.stop, d.done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := d.RunOnce(); err != nil {
+					log.Printf("Outbox dispatch failed: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for an in-progress dispatch to finish
+func (d *OutboxDispatcher) Stop() {
+	d.mu.Lock()
+	stop, done := d.stop, d.done
+	d.stop, d.done = nil, nil
+	d.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// fakeOutbox hands out queued events in batches, recording deliveries
+type fakeOutbox struct {
+	mu        sync.Mutex
+	queue     []domain.Event
+	delivered []domain.Event
+}
+
+func (f *fakeOutbox) DispatchPending(limit, maxAttempts int, publish func(domain.Event) error) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for len(f.queue) > 0 && n < limit {
+		event := f.queue[0]
+		if err := publish(event); err != nil {
+			return n, err
+		}
+		f.queue = f.queue[1:]
+		f.delivered = append(f.delivered, event)
+		n++
+	}
+	return n, nil
+}
+
+func (f *fakeOutbox) ListOutboxEvents(status domain.OutboxStatus, limit int) ([]domain.OutboxEvent, error) {
+	return nil, nil
+}
+
+func (f *fakeOutbox) RequeueOutboxEvent(sequence int64) error {
+	return domain.ErrOutboxEventNotFound
+}
+
+func (f *fakeOutbox) OutboxStats() (domain.OutboxStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return domain.OutboxStats{Pending: len(f.queue)}, nil
+}
+
+func TestOutboxDispatcherDrainsBacklog(t *testing.T) {
+	t.Parallel()
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	outbox := &fakeOutbox{}
+	for i := 0; i < 7; i++ {
+		outbox.queue = append(outbox.queue, domain.NewEvent(domain.EventLocationCreated, *location))
+	}
+
+	var received []string
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		received = append(received, e.ID)
+		return nil
+	})
+
+	dispatcher := service.NewOutboxDispatcher(outbox, bus, 0, 3, 0)
+	n, err := dispatcher.RunOnce()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 7 {
+		t.Errorf("Expected 7 events dispatched across batches, got %d", n)
+	}
+	if len(received) != 7 {
+		t.Errorf("Expected subscriber to receive 7 events, got %d", len(received))
+	}
+}
+
+func TestOutboxDispatcherStopsOnPublishError(t *testing.T) {
+	t.Parallel()
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	outbox := &fakeOutbox{queue: []domain.Event{domain.NewEvent(domain.EventLocationCreated, *location)}}
+
+	bus := events.NewBus()
+	bus.Subscribe(func(domain.Event) error { return errors.New("subscriber down") })
+
+	dispatcher := service.NewOutboxDispatcher(outbox, bus, 0, 10, 0)
+	if _, err := dispatcher.RunOnce(); err == nil {
+		t.Error("Expected publish error to be returned")
+	}
+	if len(outbox.queue) != 1 {
+		t.Errorf("Expected event to remain queued, got %d", len(outbox.queue))
+	}
+}
+
+func TestOutboxRedeliveryKeepsEventID(t *testing.T) {
+	t.Parallel()
+	location, _ := domain.NewLocation("Station", 6.5, 3.3)
+	stored := domain.NewEvent(domain.EventLocationCreated, *location)
+	outbox := &fakeOutbox{queue: []domain.Event{stored}}
+
+	// Two transports; the second fails the first delivery
+	var ids [2][]string
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		ids[0] = append(ids[0], e.ID)
+		return nil
+	})
+	bus.Subscribe(func(e domain.Event) error {
+		ids[1] = append(ids[1], e.ID)
+		if len(ids[1]) == 1 {
+			return errors.New("subscriber down")
+		}
+		return nil
+	})
+
+	dispatcher := service.NewOutboxDispatcher(outbox, bus, 0, 10, 0)
+	if _, err := dispatcher.RunOnce(); err == nil {
+		t.Fatal("Expected the first delivery to fail")
+	}
+	if n, err := dispatcher.RunOnce(); err != nil || n != 1 {
+		t.Fatalf("Expected the retry delivered, got %d (%v)", n, err)
+	}
+	for i, got := range ids {
+		if len(got) != 2 || got[0] != stored.ID || got[1] != stored.ID {
+			t.Errorf("Expected transport %d to see %s on both deliveries, got %v", i, stored.ID, got)
+		}
+	}
+}
+
+func TestLocationServicePublishesDirectly(t *testing.T) {
+	t.Parallel()
+	var received []domain.Event
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		received = append(received, e)
+		return nil
+	})
+
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(), service.WithEventPublisher(bus))
+	if _, err := svc.CreateLocation("Direct", 6.5, 3.3); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.DeleteLocation("Direct"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(received))
+	}
+	if received[0].Type != domain.EventLocationCreated || received[1].Type != domain.EventLocationDeleted {
+		t.Errorf("Expected created then deleted, got %s and %s", received[0].Type, received[1].Type)
+	}
+	if received[1].Location.Name != "Direct" {
+		t.Errorf("Expected deleted event to carry the location, got %q", received[1].Location.Name)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// Sizes of the readiness history kept for verbose health checks
+const (
+	readinessHistorySize     = 20
+	readinessTransitionsSize = 10
+)
+
+// ReadinessService decides whether the instance should receive traffic.
+// Check pings the store and is meant to run on a schedule; Status only
+// reads the outcome of past checks, so probes never wait on the store and a
+// burst of probes never reaches it. Readiness changes with hysteresis: it
+// is lost after failAfter consecutive failed checks and regained after
+// recoverAfter consecutive successes, so a brief blip does not pull the
+// instance out of rotation.
+type ReadinessService struct {
+	pinger       domain.Pinger
+	failAfter    int
+	recoverAfter int
+	timeout      time.Duration
+	now          func() time.Time
+
+	mu          sync.Mutex
+	ready       bool
+	failures    int
+	successes   int
+	history     []domain.ReadinessCheck
+	transitions []domain.ReadinessTransition
+}
+
+// NewReadinessService creates a readiness service that starts ready, as
+// the store was reached at startup, and gives each ping up to timeout. A
+// nil pinger is always ready.
+func NewReadinessService(pinger domain.Pinger, failAfter, recoverAfter int, timeout time.Duration) *ReadinessService {
+	return &ReadinessService{
+		pinger:       pinger,
+		failAfter:    max(failAfter, 1),
+		recoverAfter: max(recoverAfter, 1),
+		timeout:      timeout,
+		now:          time.Now,
+		ready:        true,
+	}
+}
+
+// Check pings the store once and updates readiness. It returns the ping's
+// error so the scheduler records failed checks.
+func (s *ReadinessService) Check(ctx context.Context) error {
+	if s.pinger == nil {
+		return nil
+	}
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	start := s.now()
+	err := s.pinger.PingContext(ctx)
+	s.record(start, s.now().Sub(start), err)
+	return err
+}
+
+func (s *ReadinessService) record(at time.Time, duration time.Duration, err error) {
+	check := domain.ReadinessCheck{At: at, Duration: duration}
+	if err != nil {
+		check.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = appendBounded(s.history, check, readinessHistorySize)
+	if err != nil {
+		s.failures++
+		s.successes = 0
+	} else {
+		s.successes++
+		s.failures = 0
+	}
+
+	switch {
+	case s.ready && s.failures >= s.failAfter:
+		s.ready = false
+		log.Printf("Readiness lost after %d failed checks: %v", s.failures, err)
+	case !s.ready && s.successes >= s.recoverAfter:
+		s.ready = true
+		log.Printf("Readiness regained after %d successful checks", s.successes)
+	default:
+		return
+	}
+	s.transitions = appendBounded(s.transitions, domain.ReadinessTransition{At: at, Ready: s.ready}, readinessTransitionsSize)
+}
+
+// Ready reports readiness as of the latest check
+func (s *ReadinessService) Ready() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ready
+}
+
+// Status returns readiness with the recent checks and transitions
+func (s *ReadinessService) Status() domain.ReadinessStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return domain.ReadinessStatus{
+		Ready:                s.ready,
+		ConsecutiveFailures:  s.failures,
+		ConsecutiveSuccesses: s.successes,
+		History:              slices.Clone(s.history),
+		Transitions:          slices.Clone(s.transitions),
+	}
+}
+
+// appendBounded appends to a ring of at most size items, dropping the oldest
+func appendBounded[T any](items []T, item T, size int) []T {
+	if len(items) >= size {
+		items = slices.Delete(items, 0, len(items)-size+1)
+	}
+	return append(items, item)
+}
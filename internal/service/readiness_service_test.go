@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// scriptedPinger fails the pings whose script entry is false and passes
+// the rest
+type scriptedPinger struct {
+	script []bool
+	pings  int
+}
+
+func (p *scriptedPinger) PingContext(context.Context) error {
+	ok := p.script[p.pings]
+	p.pings++
+	if !ok {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestReadinessHysteresis(t *testing.T) {
+	t.Parallel()
+	// A blip of two failures, an outage of three, a single success and then
+	// a recovery
+	script := []bool{false, false, true, false, false, false, true, false, true, true}
+	want := []bool{true, true, true, true, true, false, false, false, false, true}
+
+	pinger := &scriptedPinger{script: script}
+	readiness := service.NewReadinessService(pinger, 3, 2, time.Second)
+	if !readiness.Ready() {
+		t.Fatal("Expected the service to start ready")
+	}
+	for i, ready := range want {
+		readiness.Check(context.Background())
+		if got := readiness.Ready(); got != ready {
+			t.Fatalf("Check %d: expected ready %v, got %v", i+1, ready, got)
+		}
+	}
+
+	status := readiness.Status()
+	if len(status.History) != len(script) || status.History[0].Error == "" || status.History[2].Error != "" {
+		t.Errorf("Expected every check in the history, got %+v", status.History)
+	}
+	if len(status.Transitions) != 2 || status.Transitions[0].Ready || !status.Transitions[1].Ready {
+		t.Errorf("Expected a loss and a recovery, got %+v", status.Transitions)
+	}
+	if status.ConsecutiveSuccesses != 2 || status.ConsecutiveFailures != 0 {
+		t.Errorf("Expected 2 successes in a row, got %+v", status)
+	}
+}
+
+func TestReadinessHistoryIsBounded(t *testing.T) {
+	t.Parallel()
+	script := make([]bool, 50)
+	readiness := service.NewReadinessService(&scriptedPinger{script: script}, 1, 1, time.Second)
+	for range script {
+		readiness.Check(context.Background())
+	}
+	if status := readiness.Status(); len(status.History) != 20 || status.ConsecutiveFailures != 50 {
+		t.Errorf("Expected the last 20 of 50 failures, got %d checks and %d failures", len(status.History), status.ConsecutiveFailures)
+	}
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestRegionScopedNearest(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(),
+		service.WithRegions([]string{"Lagos", "Abuja", "PH"}, false))
+	for _, s := range []struct {
+		name, region        string
+		latitude, longitude float64
+	}{
+		{"Ikeja", "Lagos", 6.6018, 3.3515},
+		{"Border", "Abuja", 6.5010, 3.3700},
+	} {
+		if _, err := svc.CreateLocation(s.name, s.latitude, s.longitude, domain.WithRegion(" "+s.region+" ")); err != nil {
+			t.Fatalf("Failed to create %s: %v", s.name, err)
+		}
+	}
+
+	if result, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37}); err != nil || result.Location.Name != "Border" {
+		t.Fatalf("Expected the nearer Abuja station without a region, got %v, %v", result, err)
+	}
+	result, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Region: "Lagos"}})
+	if err != nil || result.Location.Name != "Ikeja" {
+		t.Fatalf("Expected the nearer station in another region to be skipped, got %v, %v", result, err)
+	}
+	if result.Location.Region != "Lagos" {
+		t.Errorf("Expected the trimmed region stored, got %q", result.Location.Region)
+	}
+	if _, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Region: "PH"}}); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound in an empty region, got %v", err)
+	}
+
+	if _, err := svc.CreateLocation("Kano", 12.0, 8.5, domain.WithRegion("Kano")); !errors.Is(err, domain.ErrInvalidRegion) {
+		t.Errorf("Expected ErrInvalidRegion creating outside the list, got %v", err)
+	}
+	if _, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{Region: "lagos"}}); !errors.Is(err, domain.ErrInvalidRegion) {
+		t.Errorf("Expected regions to match exactly, got %v", err)
+	}
+	if _, err := svc.ListLocations(context.Background(), domain.ListOptions{Filter: domain.LocationFilter{Region: "Kano"}}); !errors.Is(err, domain.ErrInvalidRegion) {
+		t.Errorf("Expected ErrInvalidRegion listing outside the list, got %v", err)
+	}
+	if listed, err := svc.ListLocations(context.Background(), domain.ListOptions{Filter: domain.LocationFilter{Region: "Abuja"}}); err != nil || len(listed.Items) != 1 || listed.Items[0].Name != "Border" {
+		t.Errorf("Expected only Border in Abuja, got %v, %v", listed.Items, err)
+	}
+}
+
+func TestRegionRequired(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(), service.WithRegions(nil, true))
+
+	if _, err := svc.CreateLocation("Ikeja", 6.6018, 3.3515); !errors.Is(err, domain.ErrRegionRequired) {
+		t.Errorf("Expected ErrRegionRequired creating without a region, got %v", err)
+	}
+	if _, err := svc.CreateLocation("Ikeja", 6.6018, 3.3515, domain.WithRegion("Lagos")); err != nil {
+		t.Fatalf("Expected any region accepted without a list, got %v", err)
+	}
+	if _, err := svc.FindNearest(context.Background(), domain.NearestQuery{Latitude: 6.5, Longitude: 3.37}); !errors.Is(err, domain.ErrRegionRequired) {
+		t.Errorf("Expected ErrRegionRequired searching without a region, got %v", err)
+	}
+	if listed, err := svc.ListLocations(context.Background(), domain.ListOptions{}); err != nil || len(listed.Items) != 1 {
+		t.Errorf("Expected listings to stay unscoped, got %v, %v", listed.Items, err)
+	}
+}
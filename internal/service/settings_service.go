@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// SettingsService serves the search settings operators change at runtime,
+// falling back to the configured defaults until some are stored
+type SettingsService struct {
+	repo     domain.SettingsRepository
+	defaults domain.SearchSettings
+	now      func() time.Time
+}
+
+// NewSettingsService creates a settings service with the configured
+// defaults
+func NewSettingsService(repo domain.SettingsRepository, defaults domain.SearchSettings) *SettingsService {
+	return &SettingsService{repo: repo, defaults: defaults, now: time.Now}
+}
+
+// SearchSettings returns the stored settings and true, or the configured
+// defaults and false when none are stored. Settings that cannot be read or
+// no longer validate are logged and the defaults used, so a bad row never
+// fails a search.
+func (s *SettingsService) SearchSettings() (domain.SearchSettings, bool) {
+	stored, err := s.repo.FindSearchSettings()
+	if err == nil {
+		err = stored.Validate()
+	}
+	if err != nil {
+		if !errors.Is(err, domain.ErrSettingsNotFound) {
+			log.Printf("Using configured search settings: %v", err)
+		}
+		return s.defaults, false
+	}
+	return *stored, true
+}
+
+// UpdateSearchSettings validates and stores settings, which apply to the
+// next search
+func (s *SettingsService) UpdateSearchSettings(settings domain.SearchSettings) (*domain.SearchSettings, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	settings.UpdatedAt = s.now().UTC()
+	if err := s.repo.SaveSearchSettings(&settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+)
+
+// StatsCollector exports dataset statistics as Prometheus gauges. It is run
+// periodically as a scheduler job.
+type StatsCollector struct {
+	repo domain.LocationRepository
+	now  func() time.Time
+}
+
+func NewStatsCollector(repo domain.LocationRepository) *StatsCollector {
+	return &StatsCollector{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Collect refreshes the dataset gauges once. On failure the previous values
+// are kept and the staleness gauge is raised.
+func (c *StatsCollector) Collect() error {
+	count, err := c.repo.Count()
+	if err != nil {
+		metrics.LocationStatsStale.Set(1)
+		return err
+	}
+
+	metrics.LocationsTotal.Set(float64(count))
+	metrics.LocationStatsStale.Set(0)
+	metrics.LocationStatsRefreshed.Set(float64(c.now().Unix()))
+	return nil
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// flakyCountRepository fails Count on demand
+type flakyCountRepository struct {
+	*memory.InMemoryLocationRepository
+	err error
+}
+
+func (r *flakyCountRepository) Count() (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.InMemoryLocationRepository.Count()
+}
+
+func TestStatsCollectorUpdatesGauges(t *testing.T) {
+	repo := &flakyCountRepository{InMemoryLocationRepository: memory.NewInMemoryLocationRepository()}
+	svc := service.NewLocationService(repo)
+	collector := service.NewStatsCollector(repo)
+
+	svc.CreateLocation("One", 6.5, 3.3)
+	svc.CreateLocation("Two", 7.5, 3.3)
+
+	if err := collector.Collect(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.LocationsTotal); got != 2 {
+		t.Errorf("Expected locations_total 2, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.LocationStatsStale); got != 0 {
+		t.Errorf("Expected stats not stale, got %v", got)
+	}
+
+	// A failing backend keeps the last value and marks it stale
+	repo.err = errors.New("database unavailable")
+	svc.CreateLocation("Three", 8.5, 3.3)
+	if err := collector.Collect(); err == nil {
+		t.Error("Expected collect error")
+	}
+	if got := testutil.ToFloat64(metrics.LocationsTotal); got != 2 {
+		t.Errorf("Expected locations_total to keep 2, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.LocationStatsStale); got != 1 {
+		t.Errorf("Expected stats marked stale, got %v", got)
+	}
+
+	// Recovery clears the staleness indicator
+	repo.err = nil
+	if err := collector.Collect(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.LocationsTotal); got != 3 {
+		t.Errorf("Expected locations_total 3, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.LocationStatsStale); got != 0 {
+		t.Errorf("Expected stats not stale after recovery, got %v", got)
+	}
+}
@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// TestStockedNearestStopsAtHardLimit keeps a stocked search from walking
+// the whole store when few stations hold enough: the empty stations it
+// passes over count against the scan budget
+func TestStockedNearestStopsAtHardLimit(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	for i := range 10 {
+		location, _ := domain.NewLocation(fmt.Sprintf("Empty %d", i), 6.5+float64(i)*0.001, 3.37)
+		if err := repo.Save(location); err != nil {
+			t.Fatalf("Failed to save %s: %v", location.Name, err)
+		}
+	}
+	stocked, _ := domain.NewLocation("Stocked", 6.6, 3.37)
+	if err := repo.Save(stocked); err != nil {
+		t.Fatalf("Failed to save the stocked station: %v", err)
+	}
+	stock := 9000.0
+	if _, err := repo.UpdateStock("Stocked", domain.StockUpdate{CapacityLitres: &stock, StockLitres: &stock}); err != nil {
+		t.Fatalf("Failed to set the stock: %v", err)
+	}
+	query := domain.NearestQuery{Latitude: 6.5, Longitude: 3.37, Filter: domain.NearestFilter{MinStockLitres: 5000}}
+
+	unbounded := service.NewLocationService(repo)
+	if result, err := unbounded.FindNearest(context.Background(), query); err != nil || result.Location.Name != "Stocked" {
+		t.Fatalf("Expected the stocked station without a budget, got %v, %v", result, err)
+	}
+	budgeted := service.NewLocationService(repo, service.WithScanBudget(domain.ScanBudget{Soft: 2, Hard: 5}))
+	if result, err := budgeted.FindNearest(context.Background(), query); !errors.Is(err, domain.ErrScanBudgetExceeded) {
+		t.Errorf("Expected the stocked search to stop at the hard limit, got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+const (
+	defaultMigrationBatchSize = 500
+	// migrationDistanceTolerance is how far, in meters, the nearest search
+	// from a migrated location's own coordinates may land
+	migrationDistanceTolerance = 1
+)
+
+// StorageMigrator copies every location from one storage backend to
+// another
+type StorageMigrator struct {
+	source domain.LocationStreamer
+	dest   domain.MigrationTarget
+}
+
+// NewStorageMigrator creates a migrator reading source and writing dest
+func NewStorageMigrator(source domain.LocationStreamer, dest domain.MigrationTarget) *StorageMigrator {
+	return &StorageMigrator{source: source, dest: dest}
+}
+
+// Migrate reads the whole source, plans the copy against the locations
+// dest already holds and, unless opts.DryRun, restores the planned
+// locations into dest a batch at a time, each batch in one transaction.
+// It then checks that dest grew by the number migrated and reads back
+// opts.SpotChecks locations, comparing their fields and finding each with
+// a nearest search from its own coordinates.
+//
+// Locations the source gains after it is read are not copied; running the
+// migration again with ContinueOnConflict copies them and skips the rest.
+// A conflict found while writing stops the migration unless allowed, but
+// batches already restored stay in dest.
+func (m *StorageMigrator) Migrate(ctx context.Context, opts domain.MigrationOptions) (*domain.MigrationResult, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultMigrationBatchSize
+	}
+
+	var locations []domain.Location
+	for location, err := range m.source.StreamLocations(ctx, batchSize) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the source: %w", err)
+		}
+		locations = append(locations, *location)
+	}
+
+	takenIDs := map[string]bool{}
+	takenNames := map[string]bool{}
+	largestID := 0
+	for location, err := range m.dest.StreamLocations(ctx, batchSize) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the destination: %w", err)
+		}
+		takenIDs[location.ID] = true
+		for _, name := range append([]string{location.Name}, location.Aliases...) {
+			takenNames[name] = true
+		}
+		if n, err := strconv.Atoi(location.ID); err == nil {
+			largestID = max(largestID, n)
+		}
+	}
+	if !opts.PreserveIDs {
+		for i := range locations {
+			locations[i].ID = strconv.Itoa(largestID + i + 1)
+		}
+	}
+
+	accepted, conflicts := domain.PlanRestore(locations,
+		func(id string) bool { return takenIDs[id] },
+		func(name string) bool { return takenNames[name] },
+	)
+	result := &domain.MigrationResult{
+		DryRun:    opts.DryRun,
+		Source:    len(locations),
+		Planned:   len(accepted),
+		Conflicts: conflicts,
+	}
+	if len(conflicts) > 0 && !opts.ContinueOnConflict {
+		return result, fmt.Errorf("%w: %d of %d locations", domain.ErrMigrationConflicts, len(conflicts), len(locations))
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	before, err := m.dest.Count()
+	if err != nil {
+		return result, err
+	}
+
+	// Conflicts from a batch index into the batch; map them back to the
+	// source position PlanRestore reported
+	positions := make([]int, 0, len(accepted))
+	skipped := make(map[int]bool, len(conflicts))
+	for _, c := range conflicts {
+		skipped[c.Index] = true
+	}
+	for i := range locations {
+		if !skipped[i] {
+			positions = append(positions, i)
+		}
+	}
+
+	log.Printf("Migrating %d of %d locations", len(accepted), len(locations))
+	migrated := make([]domain.Location, 0, len(accepted))
+	for start := 0; start < len(accepted); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		batch := accepted[start:min(start+batchSize, len(accepted))]
+		restored, err := m.dest.RestoreLocations(batch)
+		if err != nil {
+			return result, fmt.Errorf("failed to restore locations %d to %d: %w", start, start+len(batch), err)
+		}
+		result.Migrated += restored.Restored
+		rejected := make(map[int]bool, len(restored.Conflicts))
+		for _, c := range restored.Conflicts {
+			rejected[c.Index] = true
+			c.Index = positions[start+c.Index]
+			result.Conflicts = append(result.Conflicts, c)
+		}
+		for i, location := range batch {
+			if !rejected[i] {
+				migrated = append(migrated, location)
+			}
+		}
+		if len(restored.Conflicts) > 0 && !opts.ContinueOnConflict {
+			return result, fmt.Errorf("%w: %d locations were taken while migrating", domain.ErrMigrationConflicts, len(restored.Conflicts))
+		}
+	}
+	log.Printf("Migrated %d locations with %d conflicts", result.Migrated, len(result.Conflicts))
+
+	after, err := m.dest.Count()
+	if err != nil {
+		return result, err
+	}
+	if after-before != result.Migrated {
+		result.Mismatches = append(result.Mismatches,
+			fmt.Sprintf("destination grew by %d locations, expected %d", after-before, result.Migrated))
+	}
+	if err := m.spotCheck(migrated, opts.SpotChecks, result); err != nil {
+		return result, err
+	}
+	if len(result.Mismatches) > 0 {
+		return result, fmt.Errorf("%w: %d mismatches", domain.ErrMigrationVerification, len(result.Mismatches))
+	}
+	return result, nil
+}
+
+// spotCheck reads back n of the migrated locations, spread evenly over
+// them
+func (m *StorageMigrator) spotCheck(migrated []domain.Location, n int, result *domain.MigrationResult) error {
+	n = min(n, len(migrated))
+	for i := 0; i < n; i++ {
+		want := &migrated[i*len(migrated)/n]
+		got, err := m.dest.FindByID(want.ID)
+		if err != nil {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("location %s (%s): %v", want.ID, want.Name, err))
+			continue
+		}
+		if field := mismatchedField(want, got); field != "" {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("location %s (%s): %s differs", want.ID, want.Name, field))
+		}
+		_, distance, err := m.dest.FindNearest(want.Latitude, want.Longitude)
+		if err != nil {
+			return fmt.Errorf("failed to search near location %s: %w", want.ID, err)
+		}
+		if float64(distance) > migrationDistanceTolerance {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Sprintf("location %s (%s): nearest search from its coordinates is %.1f m away", want.ID, want.Name, float64(distance)))
+		}
+		result.SpotChecked++
+	}
+	return nil
+}
+
+// mismatchedField names the first field a stored copy does not keep.
+// Coordinates and creation times are compared to the precision PostgreSQL
+// stores them with.
+func mismatchedField(want, got *domain.Location) string {
+	switch {
+	case got.ID != want.ID:
+		return "id"
+	case got.Name != want.Name:
+		return "name"
+	case math.Abs(got.Latitude-want.Latitude) > 1e-9 || math.Abs(got.Longitude-want.Longitude) > 1e-9:
+		return "coordinates"
+	case !want.CreatedAt.IsZero() && got.CreatedAt.Sub(want.CreatedAt).Abs() >= time.Microsecond:
+		return "created_at"
+	case got.Description != want.Description:
+		return "description"
+	case got.Region != want.Region:
+		return "region"
+	case !slices.Equal(sorted(got.Aliases), sorted(want.Aliases)):
+		return "aliases"
+	case !sameJSON(got.OpeningHours, want.OpeningHours):
+		return "opening_hours"
+	case (len(got.Attachments) > 0 || len(want.Attachments) > 0) && !sameJSON(got.Attachments, want.Attachments):
+		return "attachments"
+	case !sameJSON(got.CapacityLitres, want.CapacityLitres) || !sameJSON(got.CurrentStockLitres, want.CurrentStockLitres):
+		return "stock"
+	case got.CreatedBy != want.CreatedBy:
+		return "created_by"
+	}
+	return ""
+}
+
+func sorted(values []string) []string {
+	return slices.Sorted(slices.Values(values))
+}
+
+func sameJSON(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && string(x) == string(y)
+}
@@ -0,0 +1,163 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+// migrationSeed is what the source holds, with gaps in its IDs
+func migrationSeed() []domain.Location {
+	created := time.Date(2025, 3, 1, 9, 30, 0, 123456000, time.UTC)
+	return []domain.Location{
+		{ID: "3", Name: "Yaba", Latitude: 6.5095, Longitude: 3.3711, CreatedAt: created, Region: "lagos", Aliases: []string{"Sabo"}},
+		{ID: "7", Name: "Ikeja", Latitude: 6.6018, Longitude: 3.3515, CreatedAt: created.Add(time.Hour), Description: "Behind the mall"},
+		{ID: "8", Name: "Lekki", Latitude: 6.4474, Longitude: 3.472, CreatedAt: created.Add(2 * time.Hour),
+			OpeningHours: &domain.OpeningHours{Timezone: "Africa/Lagos", Days: map[string][]domain.OpeningInterval{"monday": {{Open: "06:00", Close: "22:00"}}}}},
+		{ID: "12", Name: "Kano", Latitude: 12.0022, Longitude: 8.592, CreatedAt: created.Add(3 * time.Hour), Region: "kano"},
+		{ID: "20", Name: "Abuja", Latitude: 9.0579, Longitude: 7.4951, CreatedAt: created.Add(4 * time.Hour)},
+	}
+}
+
+func seededRepo(t *testing.T, locations []domain.Location) *memory.InMemoryLocationRepository {
+	t.Helper()
+	repo := memory.NewInMemoryLocationRepository()
+	result, err := repo.RestoreLocations(locations)
+	if err != nil || len(result.Conflicts) > 0 {
+		t.Fatalf("Failed to seed: %v %+v", err, result)
+	}
+	return repo
+}
+
+func TestMigrateStorage(t *testing.T) {
+	t.Parallel()
+	source := seededRepo(t, migrationSeed())
+	dest := memory.NewInMemoryLocationRepository()
+
+	result, err := service.NewStorageMigrator(source, dest).Migrate(context.Background(),
+		domain.MigrationOptions{PreserveIDs: true, BatchSize: 2, SpotChecks: 10})
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if result.Source != 5 || result.Planned != 5 || result.Migrated != 5 || result.SpotChecked != 5 || len(result.Conflicts) != 0 {
+		t.Errorf("Expected 5 locations migrated and checked, got %+v", result)
+	}
+	for _, want := range migrationSeed() {
+		got, err := dest.FindByID(want.ID)
+		if err != nil {
+			t.Fatalf("Failed to find %s: %v", want.ID, err)
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("Expected %+v, got %+v", want, *got)
+		}
+	}
+
+	// New locations are numbered after the migrated ones
+	created, _ := domain.NewLocation("Ajah", 6.4698, 3.5852)
+	if err := dest.Save(created); err != nil || created.ID != "21" {
+		t.Errorf("Expected the next ID 21, got %q (%v)", created.ID, err)
+	}
+}
+
+func TestMigrateStorageConflicts(t *testing.T) {
+	t.Parallel()
+	source := seededRepo(t, migrationSeed())
+	dest := seededRepo(t, []domain.Location{{ID: "40", Name: "Sabo", Latitude: 6.5, Longitude: 3.37}})
+	migrator := service.NewStorageMigrator(source, dest)
+
+	result, err := migrator.Migrate(context.Background(), domain.MigrationOptions{PreserveIDs: true})
+	if !errors.Is(err, domain.ErrMigrationConflicts) {
+		t.Fatalf("Expected ErrMigrationConflicts, got %v", err)
+	}
+	if count, _ := dest.Count(); count != 1 || result.Migrated != 0 {
+		t.Errorf("Expected nothing written, got %d locations and %+v", count, result)
+	}
+	wantConflicts := []domain.RestoreConflict{{Index: 0, ID: "3", Name: "Yaba", Reason: domain.RestoreNameExists}}
+	if !reflect.DeepEqual(result.Conflicts, wantConflicts) {
+		t.Errorf("Expected Yaba's alias to conflict, got %+v", result.Conflicts)
+	}
+
+	result, err = migrator.Migrate(context.Background(), domain.MigrationOptions{PreserveIDs: true, ContinueOnConflict: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed to plan: %v", err)
+	}
+	if count, _ := dest.Count(); count != 1 || !result.DryRun || result.Planned != 4 || result.Migrated != 0 {
+		t.Errorf("Expected a dry run planning 4, got %d locations and %+v", count, result)
+	}
+
+	result, err = migrator.Migrate(context.Background(), domain.MigrationOptions{PreserveIDs: true, ContinueOnConflict: true, SpotChecks: 2})
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if count, _ := dest.Count(); count != 5 || result.Migrated != 4 || result.SpotChecked != 2 || !reflect.DeepEqual(result.Conflicts, wantConflicts) {
+		t.Errorf("Expected 4 migrated around the conflict, got %d locations and %+v", count, result)
+	}
+
+	// A second run finds everything already there
+	result, err = migrator.Migrate(context.Background(), domain.MigrationOptions{PreserveIDs: true, ContinueOnConflict: true})
+	if err != nil || result.Migrated != 0 || len(result.Conflicts) != 5 {
+		t.Errorf("Expected a repeated run to skip every location, got %+v (%v)", result, err)
+	}
+}
+
+func TestMigrateStorageRenumbers(t *testing.T) {
+	t.Parallel()
+	source := seededRepo(t, migrationSeed())
+	dest := seededRepo(t, []domain.Location{{ID: "30", Name: "Ibadan", Latitude: 7.3775, Longitude: 3.947}})
+
+	result, err := service.NewStorageMigrator(source, dest).Migrate(context.Background(), domain.MigrationOptions{SpotChecks: 5})
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if result.Migrated != 5 || result.SpotChecked != 5 {
+		t.Errorf("Expected 5 migrated and checked, got %+v", result)
+	}
+	for i, name := range []string{"Yaba", "Ikeja", "Lekki", "Kano", "Abuja"} {
+		location, err := dest.FindByName(name)
+		if err != nil || location.ID != []string{"31", "32", "33", "34", "35"}[i] {
+			t.Errorf("Expected %s numbered after 30 in source order, got %+v (%v)", name, location, err)
+		}
+	}
+}
+
+// lossyDest drops regions when read back, as a broken backend might
+type lossyDest struct {
+	*memory.InMemoryLocationRepository
+}
+
+func (d lossyDest) FindByID(id string) (*domain.Location, error) {
+	location, err := d.InMemoryLocationRepository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *location
+	copied.Region = ""
+	return &copied, nil
+}
+
+func TestMigrateStorageVerifies(t *testing.T) {
+	t.Parallel()
+	source := seededRepo(t, migrationSeed())
+	dest := lossyDest{memory.NewInMemoryLocationRepository()}
+
+	result, err := service.NewStorageMigrator(source, dest).Migrate(context.Background(),
+		domain.MigrationOptions{PreserveIDs: true, SpotChecks: 5})
+	if !errors.Is(err, domain.ErrMigrationVerification) {
+		t.Fatalf("Expected ErrMigrationVerification, got %v", err)
+	}
+	if result.Migrated != 5 || len(result.Mismatches) != 2 {
+		t.Fatalf("Expected the two locations with regions to mismatch, got %+v", result)
+	}
+	for _, mismatch := range result.Mismatches {
+		if !strings.Contains(mismatch, "region differs") {
+			t.Errorf("Expected a region mismatch, got %q", mismatch)
+		}
+	}
+}
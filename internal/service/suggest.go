@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/telemetry"
+)
+
+// SuggestLocations returns the locations whose names best match the
+// partial name query.Query, favouring those near query.Near by the
+// configured distance weight. The limit defaults to
+// domain.DefaultSuggestLimit and is capped at domain.MaxSuggestLimit.
+func (s *LocationService) SuggestLocations(ctx context.Context, query domain.SuggestQuery) ([]domain.Suggestion, error) {
+	suggester, ok := s.repo.(domain.LocationSuggester)
+	if !ok {
+		return nil, domain.ErrSuggestionsUnsupported
+	}
+	query.Query = strings.TrimSpace(query.Query)
+	if query.Query == "" {
+		return nil, domain.ErrEmptyName
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query.DistanceWeight = s.suggestWeight
+	if query.Limit <= 0 {
+		query.Limit = domain.DefaultSuggestLimit
+	}
+	query.Limit = min(query.Limit, domain.MaxSuggestLimit)
+
+	defer telemetry.TrackRepo(ctx)()
+	return suggester.SuggestLocations(query)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// SyncService converges the local locations on another instance's
+type SyncService struct {
+	locations domain.LocationService
+
+	// mu keeps two syncs from interleaving their changes
+	mu sync.Mutex
+}
+
+// NewSyncService creates a sync service. Changes go through the
+// transactional path of locations, so they pass its policies and publish
+// events like any other.
+func NewSyncService(locations domain.LocationService) *SyncService {
+	return &SyncService{locations: locations}
+}
+
+// Sync plans the changes that make the local locations match source and,
+// unless dryRun, applies them. A change that fails is reported as a
+// conflict and the rest still run.
+//
+// Changes are applied in batches of at most domain.MaxTransactionOperations
+// operations, each all or none. An update changes the location in place,
+// so it keeps its ID, creation time, owner and stock. When an operation
+// fails, its change is reported and the batch is retried without it.
+func (s *SyncService) Sync(source []domain.Location, dryRun bool) (*domain.SyncResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	local, err := s.locations.GetAllLocations()
+	if err != nil {
+		return nil, err
+	}
+	changes, unchanged, conflicts := domain.PlanSync(local, source)
+	result := &domain.SyncResult{
+		DryRun:    dryRun,
+		Source:    len(source),
+		Unchanged: unchanged,
+		Changes:   changes,
+		Conflicts: conflicts,
+	}
+	if dryRun {
+		for _, change := range changes {
+			result.Record(change.Action)
+		}
+		return result, nil
+	}
+
+	log.Printf("Syncing %d locations: %d changes", len(source), len(changes))
+	var batch []domain.SyncChange
+	size := 0
+	for _, change := range changes {
+		ops := len(syncOperations(change))
+		if size+ops > domain.MaxTransactionOperations {
+			s.apply(batch, result)
+			batch, size = nil, 0
+		}
+		batch = append(batch, change)
+		size += ops
+	}
+	s.apply(batch, result)
+	log.Printf("Sync finished: %d created, %d updated, %d deleted, %d conflicts",
+		result.Created, result.Updated, result.Deleted, len(result.Conflicts))
+	return result, nil
+}
+
+// apply applies a batch of changes as one transaction. The change an
+// operation belongs to is dropped and reported when the operation fails;
+// any other failure reports the whole batch.
+func (s *SyncService) apply(batch []domain.SyncChange, result *domain.SyncResult) {
+	for len(batch) > 0 {
+		var ops []domain.LocationOperation
+		var owners []int
+		for i, change := range batch {
+			for _, op := range syncOperations(change) {
+				ops = append(ops, op)
+				owners = append(owners, i)
+			}
+		}
+
+		_, err := s.locations.ApplyOperations(ops, true)
+		if err == nil {
+			for _, change := range batch {
+				result.Record(change.Action)
+			}
+			return
+		}
+		var opErr *domain.OperationError
+		if !errors.As(err, &opErr) || opErr.Index < 0 || opErr.Index >= len(owners) {
+			for _, change := range batch {
+				result.Conflicts = append(result.Conflicts, syncConflict(change, err))
+			}
+			return
+		}
+		failed := owners[opErr.Index]
+		result.Conflicts = append(result.Conflicts, syncConflict(batch[failed], opErr.Err))
+		batch = slices.Delete(batch, failed, failed+1)
+	}
+}
+
+func syncConflict(change domain.SyncChange, err error) domain.SyncConflict {
+	return domain.SyncConflict{
+		Name: change.Name, Action: change.Action, Reason: domain.SyncRejected, Detail: err.Error(),
+	}
+}
+
+// syncOperations returns the operations that make one change. A create
+// stores the source's version, then its aliases, which a create cannot
+// set; an update sets every field a sync compares.
+func syncOperations(change domain.SyncChange) []domain.LocationOperation {
+	switch change.Action {
+	case domain.SyncDelete:
+		return []domain.LocationOperation{{Type: domain.OperationDelete, Name: change.Name}}
+	case domain.SyncCreate:
+		location := *change.Location
+		ops := []domain.LocationOperation{{Type: domain.OperationCreate, Location: &location}}
+		if len(location.Aliases) > 0 {
+			aliases := slices.Clone(location.Aliases)
+			ops = append(ops, domain.LocationOperation{
+				Type: domain.OperationUpdate, Name: location.Name, Changes: domain.LocationChanges{Aliases: &aliases},
+			})
+		}
+		return ops
+	}
+
+	location := change.Location
+	aliases := slices.Clone(location.Aliases)
+	if aliases == nil {
+		aliases = []string{}
+	}
+	attachments := slices.Clone(location.Attachments)
+	if attachments == nil {
+		attachments = []domain.Attachment{}
+	}
+	return []domain.LocationOperation{{
+		Type: domain.OperationUpdate,
+		Name: change.Name,
+		Changes: domain.LocationChanges{
+			Latitude:          &location.Latitude,
+			Longitude:         &location.Longitude,
+			Description:       &location.Description,
+			Region:            &location.Region,
+			OpeningHours:      location.OpeningHours,
+			ClearOpeningHours: location.OpeningHours == nil,
+			Aliases:           &aliases,
+			Attachments:       &attachments,
+		},
+	}}
+}
@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestSyncKeepsLocationWhenUpdateFails(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	locations := service.NewLocationService(repo, service.WithRegions([]string{"Lagos"}, true))
+	sync := service.NewSyncService(locations)
+
+	original, err := locations.CreateLocation("Leeta Lekki", 6.4474, 3.4723, domain.WithRegion("Lagos"))
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if _, err := locations.CreateLocation("Leeta Ikeja", 6.6018, 3.3515, domain.WithRegion("Lagos")); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	// The source's version has no region, which this server requires
+	source := []domain.Location{
+		{Name: "Leeta Lekki", Latitude: 6.44, Longitude: 3.47},
+		{Name: "Leeta Yaba", Latitude: 6.5095, Longitude: 3.3711, Region: "Lagos"},
+		{Name: "Leeta Yaba", Latitude: 6.5095, Longitude: 3.3711, Region: "Lagos"},
+	}
+	result, err := sync.Sync(source, false)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if result.Updated != 0 || result.Deleted != 1 || result.Created != 0 {
+		t.Errorf("Expected only the delete applied, got %+v", result)
+	}
+	if len(result.Conflicts) != 2 || result.Conflicts[0].Reason != domain.SyncDuplicateName ||
+		result.Conflicts[1].Action != domain.SyncUpdate || !strings.Contains(result.Conflicts[1].Detail, "region") {
+		t.Errorf("Expected the duplicate and the failed update reported, got %+v", result.Conflicts)
+	}
+
+	kept, err := locations.GetLocation("Leeta Lekki")
+	if err != nil {
+		t.Fatalf("Expected the previous version kept, got %v", err)
+	}
+	if kept.ID != original.ID || kept.Latitude != original.Latitude || !kept.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("Expected %+v left as it was, got %+v", original, kept)
+	}
+}
+
+func TestSyncUpdatesInPlace(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryLocationRepository()
+	locations := service.NewLocationService(repo)
+	sync := service.NewSyncService(locations)
+
+	hours := &domain.OpeningHours{Timezone: "Africa/Lagos"}
+	original, err := locations.CreateLocation("Leeta Lekki", 6.4474, 3.4723,
+		domain.WithOpeningHours(hours), domain.WithCreatedBy("ops"))
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if _, err := locations.AddAlias("Leeta Lekki", "Leeta Admiralty Way", false); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+
+	source := []domain.Location{{
+		Name: "Leeta Lekki", Latitude: 6.44, Longitude: 3.47, Description: "Moved",
+		Aliases: []string{"Leeta Lekki Phase 1"},
+	}}
+	result, err := sync.Sync(source, false)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if result.Updated != 1 || len(result.Conflicts) != 0 {
+		t.Fatalf("Expected one update, got %+v", result)
+	}
+
+	updated, err := locations.GetLocation("Leeta Lekki Phase 1")
+	if err != nil {
+		t.Fatalf("Expected the new alias to find the location, got %v", err)
+	}
+	if updated.ID != original.ID || !updated.CreatedAt.Equal(original.CreatedAt) || updated.CreatedBy != "ops" {
+		t.Errorf("Expected the ID, creation time and owner kept, got %+v", updated)
+	}
+	if updated.Latitude != 6.44 || updated.Description != "Moved" || updated.OpeningHours != nil {
+		t.Errorf("Expected the source's fields, got %+v", updated)
+	}
+	if _, err := locations.GetLocation("Leeta Admiralty Way"); err == nil {
+		t.Error("Expected the old alias dropped")
+	}
+}
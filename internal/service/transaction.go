@@ -0,0 +1,171 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// ApplyOperations checks every operation against the same policies as
+// CreateLocation before any is applied, then applies them all or none
+// through the repository. A failing operation, whether invalid or failing
+// against the store, is returned as a *domain.OperationError.
+//
+// Creates take the name, coordinates, opening hours, description, region
+// and attachments of op.Location; privileged callers may use reserved name
+// prefixes.
+func (s *LocationService) ApplyOperations(ops []domain.LocationOperation, privileged bool) ([]domain.OperationResult, error) {
+	transactor, ok := s.repo.(domain.LocationTransactor)
+	if !ok {
+		return nil, domain.ErrTransactionsUnsupported
+	}
+	if len(ops) == 0 {
+		return nil, domain.ErrEmptyTransaction
+	}
+
+	prepared := make([]domain.LocationOperation, len(ops))
+	for i, op := range ops {
+		p, err := s.prepareOperation(op, privileged)
+		if err != nil {
+			log.Printf("Rejected transaction at operation %d: %v", i, err)
+			return nil, &domain.OperationError{Index: i, Err: err}
+		}
+		prepared[i] = p
+	}
+
+	results, err := transactor.ApplyOperations(prepared)
+	if err != nil {
+		log.Printf("Transaction of %d operations rolled back: %v", len(ops), err)
+		return nil, err
+	}
+	log.Printf("Applied transaction of %d operations", len(ops))
+	for _, result := range results {
+		switch result.Type {
+		case domain.OperationCreate:
+			s.publish(domain.EventLocationCreated, &result.Location)
+		case domain.OperationUpdate:
+			s.publish(domain.EventLocationUpdated, &result.Location)
+		case domain.OperationDelete:
+			s.publish(domain.EventLocationDeleted, &result.Location)
+		}
+	}
+	return results, nil
+}
+
+// prepareOperation validates one operation and canonicalizes what it
+// stores
+func (s *LocationService) prepareOperation(op domain.LocationOperation, privileged bool) (domain.LocationOperation, error) {
+	switch op.Type {
+	case domain.OperationCreate:
+		if op.Location == nil {
+			return op, fmt.Errorf("create without a location")
+		}
+		location, err := s.prepare(op.Location.Name, op.Location.Latitude, op.Location.Longitude, domain.CreateOptions{
+			Privileged:   privileged,
+			OpeningHours: op.Location.OpeningHours,
+			Description:  op.Location.Description,
+			Region:       op.Location.Region,
+			Attachments:  op.Location.Attachments,
+			CreatedBy:    op.Location.CreatedBy,
+		})
+		if err != nil {
+			return op, err
+		}
+		op.Location = location
+		return op, nil
+	case domain.OperationUpdate:
+		if strings.TrimSpace(op.Name) == "" {
+			return op, domain.ErrEmptyName
+		}
+		changes, err := s.prepareChanges(op.Changes, privileged)
+		if err != nil {
+			return op, err
+		}
+		op.Changes = changes
+		return op, nil
+	case domain.OperationDelete:
+		if strings.TrimSpace(op.Name) == "" {
+			return op, domain.ErrEmptyName
+		}
+		return op, nil
+	}
+	return op, fmt.Errorf("unknown operation %q", op.Type)
+}
+
+// prepareChanges applies the create policies to the fields an update sets
+func (s *LocationService) prepareChanges(changes domain.LocationChanges, privileged bool) (domain.LocationChanges, error) {
+	if changes.IsEmpty() {
+		return changes, domain.ErrEmptyUpdate
+	}
+	// Every invalid field is reported together
+	var invalid []*domain.FieldError
+	if changes.Name != nil && strings.TrimSpace(*changes.Name) == "" {
+		invalid = append(invalid, domain.EmptyNameError(*changes.Name))
+	}
+	if changes.Latitude != nil {
+		invalid = append(invalid, domain.LatitudeError(*changes.Latitude))
+	}
+	if changes.Longitude != nil {
+		invalid = append(invalid, domain.LongitudeError(*changes.Longitude))
+	}
+	if err := domain.Invalid(invalid...); err != nil {
+		return changes, err
+	}
+	if changes.Name != nil {
+		name := strings.TrimSpace(*changes.Name)
+		if !s.nameAllowed(name, privileged) {
+			return changes, domain.ErrNameNotAllowed
+		}
+		changes.Name = &name
+	}
+	if changes.Latitude != nil {
+		latitude := geospatial.RoundCoordinate(*changes.Latitude, s.precision)
+		changes.Latitude = &latitude
+	}
+	if changes.Longitude != nil {
+		longitude := geospatial.RoundCoordinate(*changes.Longitude, s.precision)
+		changes.Longitude = &longitude
+	}
+	if changes.Description != nil {
+		description := domain.CleanDescription(*changes.Description)
+		if length := utf8.RuneCountInString(description); length > s.maxDescriptionLength {
+			return changes, &domain.DescriptionTooLongError{Length: length, Max: s.maxDescriptionLength}
+		}
+		changes.Description = &description
+	}
+	if changes.Region != nil {
+		region, err := s.region(*changes.Region, s.regionRequired)
+		if err != nil {
+			return changes, err
+		}
+		changes.Region = &region
+	}
+	if changes.OpeningHours != nil {
+		if err := changes.OpeningHours.Validate(); err != nil {
+			return changes, err
+		}
+	}
+	if changes.Aliases != nil {
+		aliases := make([]string, len(*changes.Aliases))
+		for i, alias := range *changes.Aliases {
+			aliases[i] = strings.TrimSpace(alias)
+			if aliases[i] == "" {
+				return changes, domain.ErrEmptyName
+			}
+			if !s.nameAllowed(aliases[i], privileged) {
+				return changes, domain.ErrNameNotAllowed
+			}
+		}
+		changes.Aliases = &aliases
+	}
+	if changes.Attachments != nil {
+		if err := s.checkAttachments(*changes.Attachments); err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/internal/text"
+)
+
+func TestApplyOperations(t *testing.T) {
+	t.Parallel()
+	var received []domain.Event
+	bus := events.NewBus()
+	bus.Subscribe(func(e domain.Event) error {
+		received = append(received, e)
+		return nil
+	})
+	blocklist, err := text.NewBlocklist([]string{"asdf"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to compile blocklist: %v", err)
+	}
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository(),
+		service.WithEventPublisher(bus), service.WithNamePolicy(blocklist, []string{"internal-"}),
+		service.WithRegions([]string{"Lagos"}, false), service.WithDescriptionMaxLength(10))
+	if _, err := svc.CreateLocation("Depot Ikeja Old", 6.6, 3.35); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	received = nil
+
+	depot := domain.LocationOperation{Type: domain.OperationCreate, Location: &domain.Location{Name: " Depot Ikeja ", Latitude: 6.60184321, Longitude: 3.3515}}
+	rename := func(name string) domain.LocationOperation {
+		return domain.LocationOperation{Type: domain.OperationUpdate, Name: "Depot Ikeja", Changes: domain.LocationChanges{Name: &name}}
+	}
+	region, description, latitude := "Abuja", "A long description", 91.0
+	invalid := []struct {
+		name string
+		op   domain.LocationOperation
+		want error
+	}{
+		{"blocked name", domain.LocationOperation{Type: domain.OperationCreate, Location: &domain.Location{Name: "asdf", Latitude: 6.6, Longitude: 3.35}}, domain.ErrNameNotAllowed},
+		{"reserved rename", rename("internal-depot"), domain.ErrNameNotAllowed},
+		{"unknown region", domain.LocationOperation{Type: domain.OperationUpdate, Name: "Depot Ikeja", Changes: domain.LocationChanges{Region: &region}}, domain.ErrInvalidRegion},
+		{"long description", domain.LocationOperation{Type: domain.OperationUpdate, Name: "Depot Ikeja", Changes: domain.LocationChanges{Description: &description}}, domain.ErrDescriptionTooLong},
+		{"latitude out of range", domain.LocationOperation{Type: domain.OperationUpdate, Name: "Depot Ikeja", Changes: domain.LocationChanges{Latitude: &latitude}}, domain.ErrInvalidLatitude},
+		{"empty update", domain.LocationOperation{Type: domain.OperationUpdate, Name: "Depot Ikeja"}, domain.ErrEmptyUpdate},
+		{"blank delete", domain.LocationOperation{Type: domain.OperationDelete, Name: " "}, domain.ErrEmptyName},
+	}
+	for _, tt := range invalid {
+		_, err := svc.ApplyOperations([]domain.LocationOperation{depot, tt.op}, false)
+		var opErr *domain.OperationError
+		if !errors.As(err, &opErr) || opErr.Index != 1 || !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected operation 1 to fail with %v, got %v", tt.name, tt.want, err)
+		}
+	}
+	if all, _ := svc.GetAllLocations(); len(all) != 1 || len(received) != 0 {
+		t.Fatalf("Expected invalid transactions to change nothing, got %d locations and %d events", len(all), len(received))
+	}
+
+	results, err := svc.ApplyOperations([]domain.LocationOperation{
+		depot,
+		rename("internal-depot"),
+		{Type: domain.OperationDelete, Name: "Depot Ikeja Old"},
+	}, true)
+	if err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if created := results[0].Location; created.Name != "Depot Ikeja" || created.Latitude != 6.601843 {
+		t.Errorf("Expected the create trimmed and rounded like CreateLocation, got %+v", created)
+	}
+	if results[1].Location.Name != "internal-depot" {
+		t.Errorf("Expected a privileged caller to rename to a reserved prefix, got %+v", results[1].Location)
+	}
+	if len(received) != 3 || received[0].Type != domain.EventLocationCreated || received[1].Type != domain.EventLocationUpdated ||
+		received[2].Type != domain.EventLocationDeleted || received[1].Location.Name != "internal-depot" {
+		t.Errorf("Expected created, updated and deleted events after commit, got %+v", received)
+	}
+}
+
+func TestInvalidFieldsReportedTogether(t *testing.T) {
+	t.Parallel()
+	svc := service.NewLocationService(memory.NewInMemoryLocationRepository())
+	if _, err := svc.CreateLocation("Depot Ikeja", 6.6, 3.35); err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+
+	name, latitude, longitude := " ", 91.0, -200.0
+	_, err := svc.ApplyOperations([]domain.LocationOperation{{
+		Type: domain.OperationUpdate, Name: "Depot Ikeja",
+		Changes: domain.LocationChanges{Name: &name, Latitude: &latitude, Longitude: &longitude},
+	}}, false)
+	assertFields(t, "update", err, []domain.FieldError{
+		{Field: "name", Rule: "required", Value: " "},
+		{Field: "latitude", Rule: "max", Param: "90", Value: 91.0},
+		{Field: "longitude", Rule: "min", Param: "-180", Value: -200.0},
+	})
+	for _, sentinel := range []error{domain.ErrEmptyName, domain.ErrInvalidLatitude, domain.ErrInvalidLongitude} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("Expected the update error to match %v", sentinel)
+		}
+	}
+
+	_, err = svc.CreateLocation("", -90.5, 180.5)
+	assertFields(t, "create", err, []domain.FieldError{
+		{Field: "name", Rule: "required", Value: ""},
+		{Field: "latitude", Rule: "min", Param: "-90", Value: -90.5},
+		{Field: "longitude", Rule: "max", Param: "180", Value: 180.5},
+	})
+}
+
+func assertFields(t *testing.T, name string, err error, want []domain.FieldError) {
+	t.Helper()
+	var invalid *domain.ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Fields) != len(want) {
+		t.Fatalf("%s: expected %d invalid fields, got %v", name, len(want), err)
+	}
+	for i, field := range invalid.Fields {
+		if field.Field != want[i].Field || field.Rule != want[i].Rule || field.Param != want[i].Param || field.Value != want[i].Value {
+			t.Errorf("%s: expected field %d to be %+v, got %+v", name, i, want[i], *field)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+)
+
+// UsageQuotas sets the monthly request quota per key. Zero means unlimited.
+type UsageQuotas struct {
+	Default int64
+	PerKey  map[string]int64
+}
+
+func (q UsageQuotas) limit(key string) int64 {
+	if limit, ok := q.PerKey[key]; ok {
+		return limit
+	}
+	return q.Default
+}
+
+type pendingUsage struct {
+	key       string
+	tenant    string
+	operation string
+	day       time.Time
+}
+
+type monthlyUsage struct {
+	month time.Time
+	count int64
+}
+
+// UsageService counts requests per key and operation. Counts are buffered in
+// memory and written to the repository in batches so recording stays off the
+// request's critical path.
+type UsageService struct {
+	repo          domain.UsageRepository
+	quotas        UsageQuotas
+	flushInterval time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	pending map[pendingUsage]int64
+	monthly map[string]*monthlyUsage
+
+	// flushMu serialises flushes so a failed batch is merged back before
+	// the next one, and keeps a monthly count from being read while a
+	// batch is neither buffered nor stored
+	flushMu sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewUsageService(repo domain.UsageRepository, quotas UsageQuotas, flushInterval time.Duration) *UsageService {
+	return &UsageService{
+		repo:          repo,
+		quotas:        quotas,
+		flushInterval: flushInterval,
+		now:           time.Now,
+		pending:       make(map[pendingUsage]int64),
+		monthly:       make(map[string]*monthlyUsage),
+	}
+}
+
+// Record counts one request, returning domain.ErrQuotaExceeded without
+// counting it when the key has used its monthly quota
+func (s *UsageService) Record(key, tenant, operation string) error {
+	now := s.now()
+	month, _ := domain.UsageMonth(now)
+
+	s.mu.Lock()
+	counter, ok := s.monthly[key]
+	s.mu.Unlock()
+
+	if !ok || !counter.month.Equal(month) {
+		if err := s.loadMonthly(key, month); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter = s.monthly[key]
+	if limit := s.quotas.limit(key); limit > 0 && counter.count >= limit {
+		return domain.ErrQuotaExceeded
+	}
+
+	counter.count++
+	s.pending[pendingUsage{key: key, tenant: tenant, operation: operation, day: domain.UsageDay(now)}]++
+	return nil
+}
+
+// loadMonthly seeds the in-memory monthly counter from the repository and
+// the counts still buffered. It waits for a flush in progress, whose batch
+// has left the buffer but may not be stored yet.
+func (s *UsageService) loadMonthly(key string, month time.Time) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	first, last := domain.UsageMonth(month)
+	stored, err := s.repo.TotalUsage(key, first, last)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if counter, ok := s.monthly[key]; ok && counter.month.Equal(month) {
+		return nil
+	}
+
+	// Include anything still buffered for this month
+	for k, count := range s.pending {
+		if k.key == key && !k.day.Before(first) && !k.day.After(last) {
+			stored += count
+		}
+	}
+	s.monthly[key] = &monthlyUsage{month: month, count: stored}
+	return nil
+}
+
+// Flush writes buffered counts to the repository. The buffer is swapped
+// for an empty one under the lock before the batch is written, so counts
+// recorded meanwhile wait for the next flush.
+func (s *UsageService) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[pendingUsage]int64)
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	records := make([]domain.UsageRecord, 0, len(batch))
+	for k, count := range batch {
+		records = append(records, domain.UsageRecord{
+			Key:       k.key,
+			Tenant:    k.tenant,
+			Operation: k.operation,
+			Day:       k.day,
+			Count:     count,
+		})
+	}
+
+	if err := s.repo.AddUsage(records); err != nil {
+		// Put the batch back so the counts are retried on the next flush
+		s.mu.Lock()
+		for k, count := range batch {
+			s.pending[k] += count
+		}
+		s.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Usage returns daily counters for a key, or for every key when key is empty
+func (s *UsageService) Usage(key string, from, to time.Time) ([]domain.UsageRecord, error) {
+	if err := s.Flush(); err != nil {
+		log.Printf("Failed to flush usage before reading: %v", err)
+	}
+	return s.repo.ListUsage(key, from, to)
+}
+
+// Start flushes buffered counts every flush interval until Stop is called
+func (s *UsageService) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					log.Printf("Failed to flush usage counters: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the flush loop and writes any remaining counts
+func (s *UsageService) Stop() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	return s.Flush()
+}
@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+)
+
+func TestUsageCountingUnderConcurrency(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryUsageRepository()
+	svc := service.NewUsageService(repo, service.UsageQuotas{}, time.Hour)
+
+	const workers = 50
+	const perWorker = 200
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			op := "get-locations"
+			if w%2 == 0 {
+				op = "find-nearest"
+			}
+			for i := 0; i < perWorker; i++ {
+				if err := svc.Record("partner", "acme", op); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				// Interleave flushes with recording
+				if i%50 == 0 {
+					svc.Flush()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	records, err := svc.Usage("partner", now, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var total int64
+	for _, r := range records {
+		total += r.Count
+		if r.Count != workers/2*perWorker {
+			t.Errorf("Expected %d requests for %s, got %d", workers/2*perWorker, r.Operation, r.Count)
+		}
+	}
+	if total != workers*perWorker {
+		t.Errorf("Expected %d requests in total, got %d", workers*perWorker, total)
+	}
+}
+
+func TestUsageQuotaBoundary(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryUsageRepository()
+	svc := service.NewUsageService(repo, service.UsageQuotas{
+		Default: 100,
+		PerKey:  map[string]int64{"trial": 3},
+	}, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if err := svc.Record("trial", "", "get-locations"); err != nil {
+			t.Fatalf("Expected request %d to be allowed, got %v", i+1, err)
+		}
+	}
+	if err := svc.Record("trial", "", "get-locations"); !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for request 4, got %v", err)
+	}
+
+	// Rejected requests are not counted
+	svc.Flush()
+	first, last := domain.UsageMonth(time.Now())
+	total, _ := repo.TotalUsage("trial", first, last)
+	if total != 3 {
+		t.Errorf("Expected 3 counted requests, got %d", total)
+	}
+
+	// Other keys fall back to the default quota
+	if err := svc.Record("partner", "", "get-locations"); err != nil {
+		t.Errorf("Expected default quota to allow request, got %v", err)
+	}
+}
+
+func TestUsageQuotaIncludesStoredCounts(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewInMemoryUsageRepository()
+	repo.AddUsage([]domain.UsageRecord{{Key: "partner", Operation: "get-locations", Day: time.Now(), Count: 5}})
+
+	svc := service.NewUsageService(repo, service.UsageQuotas{Default: 5}, time.Hour)
+	if err := svc.Record("partner", "", "get-locations"); !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Errorf("Expected stored usage to exhaust the quota, got %v", err)
+	}
+}
+
+type failingUsageRepository struct {
+	*memory.InMemoryUsageRepository
+	fail bool
+}
+
+func (r *failingUsageRepository) AddUsage(records []domain.UsageRecord) error {
+	if r.fail {
+		return errors.New("database unavailable")
+	}
+	return r.InMemoryUsageRepository.AddUsage(records)
+}
+
+func TestUsageFlushRetriesFailedBatch(t *testing.T) {
+	t.Parallel()
+	repo := &failingUsageRepository{InMemoryUsageRepository: memory.NewInMemoryUsageRepository(), fail: true}
+	svc := service.NewUsageService(repo, service.UsageQuotas{}, time.Hour)
+
+	svc.Record("partner", "", "get-locations")
+	svc.Record("partner", "", "get-locations")
+	if err := svc.Flush(); err == nil {
+		t.Fatal("Expected flush to fail")
+	}
+
+	repo.fail = false
+	svc.Record("partner", "", "get-locations")
+	if err := svc.Stop(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	now := time.Now()
+	total, _ := repo.TotalUsage("partner", now, now)
+	if total != 3 {
+		t.Errorf("Expected 3 requests after retry, got %d", total)
+	}
+}
+
+// slowUsageRepository holds every write up, widening the window in which
+// a flushed batch is neither buffered nor stored
+type slowUsageRepository struct {
+	*memory.InMemoryUsageRepository
+}
+
+func (r *slowUsageRepository) AddUsage(records []domain.UsageRecord) error {
+	time.Sleep(time.Millisecond)
+	return r.InMemoryUsageRepository.AddUsage(records)
+}
+
+func TestUsageQuotaHoldsAcrossFlushes(t *testing.T) {
+	t.Parallel()
+	repo := &slowUsageRepository{memory.NewInMemoryUsageRepository()}
+	const quota = 20
+	svc := service.NewUsageService(repo, service.UsageQuotas{Default: quota}, time.Hour)
+
+	// Keys are first seen while batches holding the others' counts are
+	// being written
+	keys := []string{"fleet-app", "partner", "ops", "mobile", "tooling", "kiosk"}
+	allowed := make([]int, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range quota * 2 {
+				if err := svc.Record(key, "", "get-locations"); err == nil {
+					allowed[i]++
+				}
+				svc.Flush()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, key := range keys {
+		if allowed[i] != quota {
+			t.Errorf("Expected %s allowed exactly %d requests, got %d", key, quota, allowed[i])
+		}
+	}
+}
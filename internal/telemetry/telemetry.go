@@ -0,0 +1,106 @@
+// Package telemetry carries request identity for tracing: the operation,
+// tenant and caller a request runs as, kept as OpenTelemetry baggage so it
+// follows the request into outgoing calls and SQL comments.
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Baggage keys set for each request
+const (
+	KeyOperation = "operation"
+	KeyTenant    = "tenant"
+	// KeyPrincipal is the API key name or token subject of the caller
+	KeyPrincipal = "principal"
+)
+
+// Keys lists the baggage keys this service sets. Callers cannot supply
+// them; see Extract.
+var Keys = []string{KeyOperation, KeyTenant, KeyPrincipal}
+
+// Propagator reads and writes W3C trace context and baggage headers
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Extract returns a copy of ctx carrying the trace context and baggage in
+// header. Incoming members under Keys are dropped so a caller cannot
+// attribute its requests to another tenant.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	ctx = Propagator.Extract(ctx, propagation.HeaderCarrier(header))
+	bag := baggage.FromContext(ctx)
+	for _, key := range Keys {
+		bag = bag.DeleteMember(key)
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// With returns a copy of ctx whose baggage carries value under key, and
+// records it on the current span. Empty values are left out.
+func With(ctx context.Context, key, value string) context.Context {
+	if value == "" {
+		return ctx
+	}
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("leeta."+key, value))
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// Value returns the baggage value under key, or ""
+func Value(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// SQLComment formats the request identity in ctx as a sqlcommenter
+// comment: the operation, tenant, principal and W3C traceparent, sorted
+// by key, with URL-encoded values. It returns "" when ctx carries none.
+func SQLComment(ctx context.Context) string {
+	fields := map[string]string{}
+	for _, key := range Keys {
+		if value := Value(ctx, key); value != "" {
+			fields[key] = value
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields["traceparent"] = "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-" + sc.TraceFlags().String()
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		// Encoding also removes any "*/" that could end the comment early
+		pairs[i] = url.QueryEscape(key) + "='" + strings.ReplaceAll(url.QueryEscape(fields[key]), "+", "%20") + "'"
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// AnnotateSQL appends the SQLComment for ctx to query
+func AnnotateSQL(ctx context.Context, query string) string {
+	comment := SQLComment(ctx)
+	if comment == "" {
+		return query
+	}
+	return query + " " + comment
+}
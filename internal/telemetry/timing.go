@@ -0,0 +1,116 @@
+package telemetry
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server-Timing metric names. They are part of the API: dashboards and
+// frontend tooling key on them.
+const (
+	// TimingRepo is time spent waiting on the repository
+	TimingRepo = "repo"
+	// TimingService is the rest of the time before the response is
+	// serialized: middleware, validation and service logic
+	TimingService = "service"
+	// TimingSerialize is time spent transforming and encoding the response
+	TimingSerialize = "serialize"
+	// TimingTotal is the whole request as the server saw it
+	TimingTotal = "total"
+)
+
+// Timings records where one request's time went. It is safe for use by
+// concurrent goroutines; a nil *Timings records nothing.
+type Timings struct {
+	mu      sync.Mutex
+	start   time.Time
+	handled time.Time
+	repo    time.Duration
+}
+
+type timingsKey struct{}
+
+// WithTimings returns a copy of ctx that records timings for a request
+// starting now
+func WithTimings(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{start: time.Now()}
+	return context.WithValue(ctx, timingsKey{}, t), t
+}
+
+// TimingsFromContext returns the timings ctx records, or nil
+func TimingsFromContext(ctx context.Context) *Timings {
+	t, _ := ctx.Value(timingsKey{}).(*Timings)
+	return t
+}
+
+// TrackRepo starts timing a repository call and returns the function that
+// ends it, for use as defer telemetry.TrackRepo(ctx)(). It reads the clock
+// only when ctx records timings.
+func TrackRepo(ctx context.Context) func() {
+	t := TimingsFromContext(ctx)
+	if t == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		t.mu.Lock()
+		t.repo += elapsed
+		t.mu.Unlock()
+	}
+}
+
+// MarkHandled records that the handler has returned and serialization is
+// starting. Only the first mark counts.
+func (t *Timings) MarkHandled() {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	if t.handled.IsZero() {
+		t.handled = now
+	}
+	t.mu.Unlock()
+}
+
+// Handled reports whether MarkHandled has been called
+func (t *Timings) Handled() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.handled.IsZero()
+}
+
+// Header formats the timings up to end as a Server-Timing header value,
+// in milliseconds: repo, service, serialize and total. Repo and service
+// time are counted up to the handled mark, so the first three add up to
+// the total.
+func (t *Timings) Header(end time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	handled := t.handled
+	if handled.IsZero() {
+		handled = end
+	}
+	repo := min(t.repo, handled.Sub(t.start))
+	metrics := []struct {
+		name string
+		dur  time.Duration
+	}{
+		{TimingRepo, repo},
+		{TimingService, handled.Sub(t.start) - repo},
+		{TimingSerialize, end.Sub(handled)},
+		{TimingTotal, end.Sub(t.start)},
+	}
+	parts := make([]string, len(metrics))
+	for i, m := range metrics {
+		parts[i] = m.name + ";dur=" + strconv.FormatFloat(float64(m.dur.Microseconds())/1000, 'f', -1, 64)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,74 @@
+package text
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Blocklist matches names that may not be used, either exactly or by
+// regular expression, ignoring case
+type Blocklist struct {
+	names    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// NewBlocklist compiles the exact names and patterns; an invalid pattern is
+// an error
+func NewBlocklist(names, patterns []string) (*Blocklist, error) {
+	b := &Blocklist{names: make(map[string]bool, len(names))}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			b.names[strings.ToLower(name)] = true
+		}
+	}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blocklist pattern %q: %w", pattern, err)
+		}
+		b.patterns = append(b.patterns, re)
+	}
+	return b, nil
+}
+
+// ParseBlocklist reads one rule per line: an exact name, or a regular
+// expression prefixed with "re:". Blank lines and lines starting with #
+// are skipped.
+func ParseBlocklist(r io.Reader) (names, patterns []string, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "re:"):
+			patterns = append(patterns, strings.TrimSpace(strings.TrimPrefix(line, "re:")))
+		default:
+			names = append(names, line)
+		}
+	}
+	return names, patterns, scanner.Err()
+}
+
+// Blocked reports whether name matches a rule. A nil Blocklist blocks
+// nothing.
+func (b *Blocklist) Blocked(name string) bool {
+	if b == nil {
+		return false
+	}
+	name = strings.TrimSpace(name)
+	if b.names[strings.ToLower(name)] {
+		return true
+	}
+	for _, re := range b.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package text
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlocklist(t *testing.T) {
+	t.Parallel()
+	b, err := NewBlocklist([]string{"asdf", " Test Station "}, []string{`^test[- ]?\d+$`, `f+u+c+k`})
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		blocked bool
+	}{
+		{"asdf", true},
+		{"ASDF", true},
+		{" asdf ", true},
+		{"test station", true},
+		{"asdf station", false},
+		{"Test-42", true},
+		{"test 7", true},
+		{"Test 7 Lekki", false},
+		{"FFUUCK yard", true},
+		{"Leeta Lekki Phase 1", false},
+	}
+	for _, tt := range tests {
+		if got := b.Blocked(tt.name); got != tt.blocked {
+			t.Errorf("Blocked(%q) = %v, expected %v", tt.name, got, tt.blocked)
+		}
+	}
+
+	var none *Blocklist
+	if none.Blocked("asdf") {
+		t.Error("Expected a nil blocklist to block nothing")
+	}
+}
+
+func TestNewBlocklistRejectsInvalidPattern(t *testing.T) {
+	t.Parallel()
+	if _, err := NewBlocklist(nil, []string{"("}); err == nil {
+		t.Fatal("Expected an invalid pattern to fail")
+	}
+}
+
+func TestParseBlocklist(t *testing.T) {
+	t.Parallel()
+	names, patterns, err := ParseBlocklist(strings.NewReader("# test data\nasdf\n\nre: ^qwer\n  Demo  \n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"asdf", "Demo"}) || !reflect.DeepEqual(patterns, []string{"^qwer"}) {
+		t.Errorf("Unexpected rules: names %v, patterns %v", names, patterns)
+	}
+}
@@ -0,0 +1,57 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// BinaryCollation orders names by their UTF-8 bytes
+const BinaryCollation = "binary"
+
+// Collation orders names by the rules of a language, so accented and
+// Yoruba letters sort next to their base letters instead of after z. A nil
+// Collation orders by bytes. It is safe for concurrent use.
+type Collation struct {
+	tag  language.Tag
+	pool sync.Pool
+}
+
+// NewCollation returns the collation for a BCP 47 tag such as "en", "yo" or
+// "und", the Unicode default. BinaryCollation and "" return nil.
+func NewCollation(tag string) (*Collation, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || tag == BinaryCollation {
+		return nil, nil
+	}
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collation %q: %w", tag, err)
+	}
+	c := &Collation{tag: parsed}
+	// A collator keeps buffers between comparisons, so each goroutine
+	// borrows its own
+	c.pool.New = func() any { return collate.New(parsed) }
+	return c, nil
+}
+
+// Name returns the collation's language tag, or BinaryCollation
+func (c *Collation) Name() string {
+	if c == nil {
+		return BinaryCollation
+	}
+	return c.tag.String()
+}
+
+// Compare returns -1, 0 or 1 as a sorts before, with or after b
+func (c *Collation) Compare(a, b string) int {
+	if c == nil {
+		return strings.Compare(a, b)
+	}
+	collator := c.pool.Get().(*collate.Collator)
+	defer c.pool.Put(collator)
+	return collator.CompareString(a, b)
+}
@@ -0,0 +1,38 @@
+package text
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCollation(t *testing.T) {
+	names := []string{"Zuba", "Ékó Station", "Eko", "Ẹgbẹ", "Gbagada", "Garki", "Ọ̀yọ́", "Ota", "Ṣagamu", "Sango", "Abuja"}
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"binary", []string{"Abuja", "Eko", "Garki", "Gbagada", "Ota", "Sango", "Zuba", "Ékó Station", "Ṣagamu", "Ẹgbẹ", "Ọ̀yọ́"}},
+		// Accented letters sort with their base letters
+		{"und", []string{"Abuja", "Ẹgbẹ", "Eko", "Ékó Station", "Garki", "Gbagada", "Ota", "Ọ̀yọ́", "Ṣagamu", "Sango", "Zuba"}},
+		// Yoruba orders ẹ, gb, ọ and ṣ as letters after e, g, o and s
+		{"yo", []string{"Abuja", "Eko", "Ékó Station", "Ẹgbẹ", "Garki", "Gbagada", "Ota", "Ọ̀yọ́", "Sango", "Ṣagamu", "Zuba"}},
+	}
+	for _, tt := range tests {
+		c, err := NewCollation(tt.tag)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.tag, err)
+		}
+		if c.Name() != tt.tag {
+			t.Errorf("Expected the name %s, got %s", tt.tag, c.Name())
+		}
+		got := slices.Clone(names)
+		slices.SortFunc(got, c.Compare)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.tag, tt.want, got)
+		}
+	}
+
+	if _, err := NewCollation("not a tag!"); err == nil {
+		t.Error("Expected an invalid tag to be rejected")
+	}
+}
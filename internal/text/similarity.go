@@ -0,0 +1,108 @@
+// Package text provides string comparison helpers for matching location names.
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize lowercases s, drops punctuation and collapses whitespace, so
+// "Leeta  Lekki-Phase 1" and "leeta lekki phase 1" compare equal
+func Normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// Levenshtein returns the number of single-rune insertions, deletions and
+// substitutions needed to turn a into b
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	// Two rows of the edit matrix, sized by the shorter string
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// Similarity scores two names from 0 (nothing in common) to 1 (identical
+// after normalization), as one minus the edit distance over the longer length
+func Similarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
+
+// Suggest returns the candidate word most likely meant by a mistyped or
+// abbreviated word, or "" when none is close. A candidate within one edit
+// per three runes, rounded up, wins, nearest first; failing that, one the word
+// abbreviates, sharing its first letter and keeping its letters in order,
+// so "lat" suggests "latitude" and "lng" "longitude".
+func Suggest(word string, candidates []string) string {
+	word = strings.ToLower(word)
+	best, bestDistance := "", (len([]rune(word))+2)/3+1
+	for _, candidate := range candidates {
+		if d := Levenshtein(word, strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best != "" || len([]rune(word)) < 2 {
+		return best
+	}
+	for _, candidate := range candidates {
+		if abbreviates(word, strings.ToLower(candidate)) && (best == "" || len(candidate) < len(best)) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// abbreviates reports whether word starts like long and the rest of its
+// runes appear in long in order
+func abbreviates(word, long string) bool {
+	w, l := []rune(word), []rune(long)
+	if len(w) == 0 || len(l) <= len(w) || w[0] != l[0] {
+		return false
+	}
+	i := 1
+	for _, r := range l[1:] {
+		if i < len(w) && r == w[i] {
+			i++
+		}
+	}
+	return i == len(w)
+}
@@ -0,0 +1,100 @@
+package text
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Leeta Lekki Phase 1", "leeta lekki phase 1"},
+		{"  Leeta  Lekki-Phase 1 ", "leeta lekki phase 1"},
+		{"Leeta (Lekki), Phase 1!", "leeta lekki phase 1"},
+		{"Île-de-France", "île de france"},
+		{"", ""},
+		{"---", ""},
+	}
+
+	for _, tt := range tests {
+		if result := Normalize(tt.input); result != tt.expected {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"lekki", "lekki", 0},
+		{"ikeja", "ikoyi", 3},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if result := Levenshtein(tt.a, tt.b); result != tt.expected {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, result, tt.expected)
+		}
+		if result := Levenshtein(tt.b, tt.a); result != tt.expected {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d (not symmetric)", tt.b, tt.a, result, tt.expected)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"Leeta Lekki", "leeta-lekki", 1},
+		{"Leeta Lekki Phase 1", "Leeta Lekki Phase 2", 1 - 1.0/19},
+		{"Ikeja", "Ikoyi", 0.4},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+		{"Yaba", "", 0},
+	}
+
+	for _, tt := range tests {
+		if result := Similarity(tt.a, tt.b); math.Abs(result-tt.expected) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+	fields := []string{"name", "latitude", "longitude", "description", "opening_hours"}
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"lat", "latitude"},
+		{"lng", "longitude"},
+		{"lon", "longitude"},
+		{"Latitude", "latitude"},
+		{"nme", "name"},
+		{"nmae", "name"},
+		{"descripton", "description"},
+		{"hours", ""},
+		{"colour", ""},
+		{"l", ""},
+	}
+
+	for _, tt := range tests {
+		if result := Suggest(tt.word, fields); result != tt.expected {
+			t.Errorf("Suggest(%q) = %q, want %q", tt.word, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package text
+
+import (
+	"slices"
+	"unicode"
+)
+
+// Trigrams returns the distinct trigrams of s the way PostgreSQL's pg_trgm
+// extracts them: s is lowercased and split into words of letters and
+// digits, each word is padded with two spaces in front and one behind, and
+// every run of three runes is a trigram. They are encoded as numbers and
+// sorted, for TrigramSimilarity.
+func Trigrams(s string) []uint64 {
+	var trigrams []uint64
+	word := make([]rune, 0, 32)
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		padded := append([]rune{' ', ' '}, word...)
+		padded = append(padded, ' ')
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams = append(trigrams, uint64(padded[i])<<42|uint64(padded[i+1])<<21|uint64(padded[i+2]))
+		}
+		word = word[:0]
+	}
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, unicode.ToLower(r))
+			continue
+		}
+		flush()
+	}
+	flush()
+	slices.Sort(trigrams)
+	return slices.Compact(trigrams)
+}
+
+// TrigramSimilarity scores two sets of Trigrams from 0 to 1 as pg_trgm's
+// similarity() does: the trigrams they share over the trigrams in either.
+// It is 0 when either set is empty.
+func TrigramSimilarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,52 @@
+package text
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigrams(t *testing.T) {
+	t.Parallel()
+	encode := func(s string) uint64 {
+		r := []rune(s)
+		return uint64(r[0])<<42 | uint64(r[1])<<21 | uint64(r[2])
+	}
+	// show_trgm('Cat!') = {"  c"," ca","at ","cat"}
+	want := []uint64{encode("  c"), encode(" ca"), encode("at "), encode("cat")}
+	got := Trigrams("Cat!")
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d trigrams, got %d", len(want), len(got))
+	}
+	for _, trigram := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == trigram
+		}
+		if !found {
+			t.Errorf("Expected trigram %x in %x", trigram, got)
+		}
+	}
+	if got := Trigrams(" -- "); len(got) != 0 {
+		t.Errorf("Expected no trigrams without words, got %x", got)
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		// The example in the pg_trgm documentation first
+		{"word", "two words", 4.0 / 11},
+		{"Leeta Lekki", "leeta lekki", 1},
+		{"lekki", "Leeta Lekki Phase 1", 6.0 / 18},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+	}
+	for _, tt := range tests {
+		if got := TrigramSimilarity(Trigrams(tt.a), Trigrams(tt.b)); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("TrigramSimilarity(%q, %q) = %g, want %g", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+)
+
+//go:embed static
+var static embed.FS
+
+// assetMaxAge is how long browsers may reuse scripts and stylesheets
+const assetMaxAge = time.Hour
+
+type asset struct {
+	content     []byte
+	contentType string
+	etag        string
+}
+
+// Handler serves the embedded single-page UI. Mount it with the /ui prefix
+// stripped.
+type Handler struct {
+	assets map[string]asset
+	config []byte
+}
+
+// NewHandler creates a UI handler that calls the JSON API at apiBasePath
+func NewHandler(apiBasePath string) *Handler {
+	h := &Handler{assets: make(map[string]asset)}
+
+	fs.WalkDir(static, "static", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := static.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		h.assets["/"+strings.TrimPrefix(name, "static/")] = newAsset(name, content)
+		return nil
+	})
+
+	config, _ := json.Marshal(map[string]string{
+		"api_base_path": strings.TrimSuffix(apiBasePath, "/"),
+	})
+	h.config = config
+	return h
+}
+
+// Mux is what Mount registers the UI on; every router in
+// internal/router is one
+type Mux interface {
+	Handle(path string, handler http.Handler)
+	HandlePrefix(prefix string, handler http.Handler)
+}
+
+// Mount serves the UI under /ui when it is enabled, and nothing otherwise
+func Mount(mux Mux, cfg config.UIConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	mux.Handle("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently))
+	mux.HandlePrefix("/ui/", http.StripPrefix("/ui", NewHandler(cfg.APIBasePath)))
+}
+
+func newAsset(name string, content []byte) asset {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	sum := sha256.Sum256(content)
+	return asset{
+		content:     content,
+		contentType: contentType,
+		etag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Path
+	switch name {
+	case "", "/":
+		name = "/index.html"
+	case "/config.json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(h.config)
+		return
+	}
+
+	a, ok := h.assets[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", a.contentType)
+	w.Header().Set("ETag", a.etag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if name == "/index.html" {
+		// The page must be revalidated so new asset versions are picked up
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(assetMaxAge.Seconds())))
+	}
+
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(a.content))
+}
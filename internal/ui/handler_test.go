@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/router"
+)
+
+func serve(mux http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUIServesAssets(t *testing.T) {
+	mux := router.NewStdlib(http.NewServeMux())
+	Mount(mux, config.UIConfig{Enabled: true, APIBasePath: "/v1/"})
+
+	tests := []struct {
+		path         string
+		contentType  string
+		cacheControl string
+		contains     string
+	}{
+		{"/ui/", "text/html", "no-cache", "leaflet"},
+		{"/ui/app.js", "text/javascript", "public, max-age=3600", "config.json"},
+		{"/ui/style.css", "text/css", "public, max-age=3600", "#map"},
+		{"/ui/config.json", "application/json", "no-cache", "api_base_path"},
+	}
+
+	for _, tt := range tests {
+		rec := serve(mux, http.MethodGet, tt.path, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected status %d, got %d", tt.path, http.StatusOK, rec.Code)
+			continue
+		}
+		if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+			t.Errorf("%s: expected content type %s, got %s", tt.path, tt.contentType, got)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != tt.cacheControl {
+			t.Errorf("%s: expected cache control %q, got %q", tt.path, tt.cacheControl, got)
+		}
+		if !strings.Contains(rec.Body.String(), tt.contains) {
+			t.Errorf("%s: expected body to contain %q", tt.path, tt.contains)
+		}
+	}
+}
+
+func TestUIConfigInjectsBasePath(t *testing.T) {
+	mux := router.NewStdlib(http.NewServeMux())
+	Mount(mux, config.UIConfig{Enabled: true, APIBasePath: "/v1/"})
+
+	rec := serve(mux, http.MethodGet, "/ui/config.json", nil)
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode config: %v", err)
+	}
+	if body["api_base_path"] != "/v1" {
+		t.Errorf("Expected api_base_path /v1, got %q", body["api_base_path"])
+	}
+}
+
+func TestUIRevalidatesWithETag(t *testing.T) {
+	mux := router.NewStdlib(http.NewServeMux())
+	Mount(mux, config.UIConfig{Enabled: true})
+
+	first := serve(mux, http.MethodGet, "/ui/app.js", nil)
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	second := serve(mux, http.MethodGet, "/ui/app.js", http.Header{"If-None-Match": {etag}})
+	if second.Code != http.StatusNotModified {
+		t.Errorf("Expected status %d, got %d", http.StatusNotModified, second.Code)
+	}
+}
+
+func TestUIRedirectsAndRejects(t *testing.T) {
+	mux := router.NewStdlib(http.NewServeMux())
+	Mount(mux, config.UIConfig{Enabled: true})
+
+	if rec := serve(mux, http.MethodGet, "/ui", nil); rec.Code != http.StatusMovedPermanently {
+		t.Errorf("Expected /ui to redirect, got %d", rec.Code)
+	}
+	if rec := serve(mux, http.MethodGet, "/ui/missing.js", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected missing asset to 404, got %d", rec.Code)
+	}
+	if rec := serve(mux, http.MethodPost, "/ui/", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestUIDisabled(t *testing.T) {
+	mux := router.NewStdlib(http.NewServeMux())
+	Mount(mux, config.UIConfig{Enabled: false})
+
+	for _, path := range []string{"/ui", "/ui/", "/ui/app.js", "/ui/config.json"} {
+		if rec := serve(mux, http.MethodGet, path, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status %d when disabled, got %d", path, http.StatusNotFound, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+(function () {
+  "use strict";
+
+  var map = L.map("map").setView([6.5244, 3.3792], 11);
+  L.tileLayer("https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png", {
+    maxZoom: 19,
+    attribution: "&copy; OpenStreetMap contributors"
+  }).addTo(map);
+
+  var markers = L.layerGroup().addTo(map);
+  var keyInput = document.getElementById("api-key");
+  var statusEl = document.getElementById("status");
+  var listEl = document.getElementById("locations");
+
+  keyInput.value = localStorage.getItem("leeta-api-key") || "";
+  keyInput.addEventListener("change", function () {
+    localStorage.setItem("leeta-api-key", keyInput.value);
+  });
+
+  // The server injects the API base path so the page works behind a prefix
+  var configPromise = fetch("config.json").then(function (res) {
+    return res.json();
+  });
+
+  function fetchAll(base) {
+    var headers = {};
+    if (keyInput.value) {
+      headers["X-API-Key"] = keyInput.value;
+    }
+    var all = [];
+
+    function page(url) {
+      return fetch(url, { headers: headers }).then(function (res) {
+        if (!res.ok) {
+          throw new Error("Request failed with status " + res.status);
+        }
+        return res.json();
+      }).then(function (body) {
+        all = all.concat(body.locations);
+        if (body.next_cursor) {
+          return page(base + "/locations?limit=100&cursor=" + encodeURIComponent(body.next_cursor));
+        }
+        return all;
+      });
+    }
+
+    return page(base + "/locations?limit=100");
+  }
+
+  function render(locations) {
+    markers.clearLayers();
+    listEl.textContent = "";
+
+    var bounds = [];
+    locations.forEach(function (location) {
+      var point = [location.latitude, location.longitude];
+      var marker = L.marker(point).bindPopup(
+        "<strong>" + escapeHTML(location.name) + "</strong><br>" +
+        location.latitude + ", " + location.longitude
+      );
+      markers.addLayer(marker);
+      bounds.push(point);
+
+      var item = document.createElement("li");
+      item.textContent = location.name;
+      var coords = document.createElement("small");
+      coords.textContent = location.latitude + ", " + location.longitude;
+      item.appendChild(coords);
+      item.addEventListener("click", function () {
+        map.setView(point, 15);
+        marker.openPopup();
+      });
+      listEl.appendChild(item);
+    });
+
+    statusEl.textContent = locations.length + " location" + (locations.length === 1 ? "" : "s");
+    if (bounds.length > 0) {
+      map.fitBounds(bounds, { padding: [40, 40], maxZoom: 15 });
+    }
+  }
+
+  function escapeHTML(value) {
+    var div = document.createElement("div");
+    div.textContent = value;
+    return div.innerHTML;
+  }
+
+  function load() {
+    statusEl.textContent = "Loading…";
+    configPromise.then(function (config) {
+      return fetchAll(config.api_base_path);
+    }).then(render).catch(function (err) {
+      statusEl.textContent = err.message;
+    });
+  }
+
+  document.getElementById("refresh").addEventListener("click", load);
+  load();
+})();
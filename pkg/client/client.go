@@ -0,0 +1,293 @@
+// Package client is a typed Go client for the location API. It depends only
+// on net/http and returns the server's own dto types.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+)
+
+// apiKeyHeader matches the header the server's API key authenticator reads
+const apiKeyHeader = "X-API-Key"
+
+// Client calls the location API
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	apiKey     string
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+}
+
+// Option configures optional Client behaviour
+type Option func(*Client)
+
+// WithAPIKey sends key in the X-API-Key header on every request
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithTimeout bounds each HTTP attempt, 30 seconds by default; a retried
+// call may take longer in total
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithRetries sets how many times a request answered 429 or 503 is retried,
+// and the wait used when the response carries no Retry-After header. The
+// wait doubles with each attempt.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// New creates a client for the API at baseURL
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		backoff:    500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.timeout > 0 {
+		// Copy the HTTP client rather than change one the caller may share
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+	return c, nil
+}
+
+// CreateLocation registers a new location
+func (c *Client) CreateLocation(ctx context.Context, name string, latitude, longitude float64) (*dto.LocationResponse, error) {
+	req := dto.LocationRequest{Name: name, Latitude: &latitude, Longitude: &longitude}
+	var location dto.LocationResponse
+	if err := c.do(ctx, http.MethodPost, "/locations", nil, req, &location); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// GetLocation returns the location with the given name or alias. It fails
+// with ErrLocationNotFound when there is none.
+func (c *Client) GetLocation(ctx context.Context, name string) (*dto.LocationResponse, error) {
+	var location dto.LocationResponse
+	if err := c.do(ctx, http.MethodGet, "/locations/"+url.PathEscape(name), nil, nil, &location); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// DeleteLocation deletes the location with the given name
+func (c *Client) DeleteLocation(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/locations/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// FindNearest returns the location closest to the given point, skipping
+// any named in exclude
+func (c *Client) FindNearest(ctx context.Context, latitude, longitude float64, exclude ...string) (*dto.NearestLocationResponse, error) {
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(latitude, 'f', -1, 64))
+	query.Set("lng", strconv.FormatFloat(longitude, 'f', -1, 64))
+	for _, name := range exclude {
+		query.Add("exclude", name)
+	}
+
+	var nearest dto.NearestLocationResponse
+	if err := c.do(ctx, http.MethodGet, "/nearest", query, nil, &nearest); err != nil {
+		return nil, err
+	}
+	return &nearest, nil
+}
+
+// LocationAt returns the location within toleranceM metres of the point.
+// It fails with ErrLocationNotFound when none matches and with
+// ErrAmbiguousLocation, whose Details name the candidates, when several do.
+func (c *Client) LocationAt(ctx context.Context, latitude, longitude, toleranceM float64) (*dto.LocationResponse, error) {
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(latitude, 'f', -1, 64))
+	query.Set("lng", strconv.FormatFloat(longitude, 'f', -1, 64))
+	query.Set("tolerance_m", strconv.FormatFloat(toleranceM, 'f', -1, 64))
+
+	var location dto.LocationResponse
+	if err := c.do(ctx, http.MethodGet, "/locations/at", query, nil, &location); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// ListLocations iterates over every location, fetching pageSize at a time
+// with cursor pagination; 0 uses the server's default page size. Iteration
+// stops after the first error, which is yielded with a zero location.
+func (c *Client) ListLocations(ctx context.Context, pageSize int) iter.Seq2[dto.LocationResponse, error] {
+	return func(yield func(dto.LocationResponse, error) bool) {
+		if pageSize <= 0 {
+			pageSize = 20
+		}
+		cursor := ""
+		for {
+			query := url.Values{}
+			query.Set("limit", strconv.Itoa(pageSize))
+			if cursor != "" {
+				query.Set("cursor", cursor)
+			}
+
+			var page dto.LocationListResponse
+			if err := c.do(ctx, http.MethodGet, "/locations", query, nil, &page); err != nil {
+				yield(dto.LocationResponse{}, err)
+				return
+			}
+			for _, location := range page.Locations {
+				if !yield(location, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
+
+// MaxPageSize returns the largest page the server advertises under
+// limits in GET /capabilities, or 0 when it advertises none or predates
+// the endpoint
+func (c *Client) MaxPageSize(ctx context.Context) (int, error) {
+	var capabilities struct {
+		Features struct {
+			Limits struct {
+				MaxPageSize int `json:"max_page_size"`
+			} `json:"limits"`
+		} `json:"features"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/capabilities", nil, nil, &capabilities); err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return capabilities.Features.Limits.MaxPageSize, nil
+}
+
+// do sends one request, retrying 429 and 503 responses, and decodes a
+// successful body into out when out is not nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.apiKey != "" {
+			req.Header.Set(apiKeyHeader, c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode < 300 {
+			if out == nil || len(body) == 0 {
+				return nil
+			}
+			return json.Unmarshal(body, out)
+		}
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if !retryable || attempt >= c.maxRetries {
+			return decodeError(resp, body)
+		}
+
+		wait := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if wait < 0 {
+			wait = c.backoff << attempt
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter parses a Retry-After value given in seconds or as an HTTP date,
+// returning -1 when it is absent or invalid
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return -1
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return -1
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is an error response from the API. Compare it with errors.Is against
+// the sentinels below, which match on Code alone.
+type Error struct {
+	StatusCode int
+	// Code is the stable code from the error catalog; it is empty for
+	// request validation failures reported by the framework
+	Code    string
+	Message string
+	// Details lists per-field problems, when the server reports them
+	Details []string
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if len(e.Details) > 0 {
+		msg += ": " + strings.Join(e.Details, "; ")
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("%s (%d %s)", msg, e.StatusCode, e.Code)
+	}
+	return fmt.Sprintf("%s (%d)", msg, e.StatusCode)
+}
+
+// Is reports whether target is an *Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// Sentinels for the codes in the server's error catalog
+var (
+	ErrBadRequest               = &Error{Code: "BAD_REQUEST"}
+	ErrNotFound                 = &Error{Code: "NOT_FOUND"}
+	ErrConflict                 = &Error{Code: "CONFLICT"}
+	ErrInternalServerError      = &Error{Code: "INTERNAL_SERVER_ERROR"}
+	ErrUnauthorized             = &Error{Code: "UNAUTHORIZED"}
+	ErrForbidden                = &Error{Code: "FORBIDDEN"}
+	ErrInsufficientScope        = &Error{Code: "INSUFFICIENT_SCOPE"}
+	ErrQuotaExceeded            = &Error{Code: "QUOTA_EXCEEDED"}
+	ErrRateLimited              = &Error{Code: "RATE_LIMITED"}
+	ErrValidation               = &Error{Code: "VALIDATION_ERROR"}
+	ErrLocationExists           = &Error{Code: "LOCATION_EXISTS"}
+	ErrLocationNotFound         = &Error{Code: "LOCATION_NOT_FOUND"}
+	ErrNoLocations              = &Error{Code: "NO_LOCATIONS"}
+	ErrInvalidCursor            = &Error{Code: "INVALID_CURSOR"}
+	ErrPaginationConflict       = &Error{Code: "PAGINATION_CONFLICT"}
+	ErrLimitExceeded            = &Error{Code: "LIMIT_EXCEEDED"}
+	ErrReferencePointIncomplete = &Error{Code: "REFERENCE_POINT_INCOMPLETE"}
+	ErrMergeLocationNotFound    = &Error{Code: "MERGE_LOCATION_NOT_FOUND"}
+	ErrMergeInvalid             = &Error{Code: "MERGE_INVALID"}
+	ErrEndpointDisabled         = &Error{Code: "ENDPOINT_DISABLED"}
+	ErrInvalidCreatedRange      = &Error{Code: "INVALID_CREATED_RANGE"}
+	ErrNameNotAllowed           = &Error{Code: "NAME_NOT_ALLOWED"}
+	ErrInvalidBBox              = &Error{Code: "INVALID_BBOX"}
+	ErrIntegrityCheckRunning    = &Error{Code: "INTEGRITY_CHECK_RUNNING"}
+	ErrInvalidOpeningHours      = &Error{Code: "INVALID_OPENING_HOURS"}
+	ErrOpenFilterConflict       = &Error{Code: "OPEN_FILTER_CONFLICT"}
+	ErrNoOpenLocation           = &Error{Code: "NO_OPEN_LOCATION"}
+	ErrAmbiguousLocation        = &Error{Code: "AMBIGUOUS_LOCATION"}
+	ErrDescriptionTooLong       = &Error{Code: "DESCRIPTION_TOO_LONG"}
+	ErrInvalidExportRange       = &Error{Code: "INVALID_EXPORT_RANGE"}
+	ErrNameTaken                = &Error{Code: "NAME_TAKEN"}
+	ErrAliasNotFound            = &Error{Code: "ALIAS_NOT_FOUND"}
+	ErrInvalidRegion            = &Error{Code: "INVALID_REGION"}
+	ErrRegionRequired           = &Error{Code: "REGION_REQUIRED"}
+	ErrInvalidMatrixPoint       = &Error{Code: "INVALID_MATRIX_POINT"}
+	ErrUnknownFields            = &Error{Code: "UNKNOWN_FIELDS"}
+	ErrQueryExists              = &Error{Code: "QUERY_EXISTS"}
+	ErrQueryNotFound            = &Error{Code: "QUERY_NOT_FOUND"}
+	ErrSavedQueryInvalid        = &Error{Code: "SAVED_QUERY_INVALID"}
+	ErrImportNotFound           = &Error{Code: "IMPORT_NOT_FOUND"}
+	ErrInvalidImportFile        = &Error{Code: "INVALID_IMPORT_FILE"}
+	ErrInvalidAttachment        = &Error{Code: "INVALID_ATTACHMENT"}
+	ErrAttachmentUnreachable    = &Error{Code: "ATTACHMENT_UNREACHABLE"}
+	ErrNoLocationInRange        = &Error{Code: "NO_LOCATION_IN_RANGE"}
+	ErrSyncSourceNotAllowed     = &Error{Code: "SYNC_SOURCE_NOT_ALLOWED"}
+	ErrSyncSourceFailed         = &Error{Code: "SYNC_SOURCE_FAILED"}
+	ErrNearestScanLimit         = &Error{Code: "NEAREST_SCAN_LIMIT"}
+	ErrPreconditionFailed       = &Error{Code: "PRECONDITION_FAILED"}
+	ErrLocationModified         = &Error{Code: "LOCATION_MODIFIED"}
+	ErrPreconditionRequired     = &Error{Code: "PRECONDITION_REQUIRED"}
+	ErrInvalidCRSCoordinates    = &Error{Code: "INVALID_CRS_COORDINATES"}
+	ErrTruncateNotConfirmed     = &Error{Code: "TRUNCATE_NOT_CONFIRMED"}
+	ErrInvalidStock             = &Error{Code: "INVALID_STOCK"}
+	ErrNoStockedLocation        = &Error{Code: "NO_STOCKED_LOCATION"}
+	ErrStorageTimeout           = &Error{Code: "STORAGE_TIMEOUT"}
+	ErrClassRateLimited         = &Error{Code: "CLASS_RATE_LIMITED"}
+	ErrNotOwner                 = &Error{Code: "NOT_OWNER"}
+)
+
+// decodeError reads either error envelope the server writes: the problem
+// document returned by handlers, or the {"error": {...}} object written by
+// middleware that rejects a request before it reaches a handler
+func decodeError(resp *http.Response, body []byte) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	var envelope struct {
+		// Problem document
+		Code   string `json:"code"`
+		Detail string `json:"detail"`
+		Errors []struct {
+			Message  string `json:"message"`
+			Location string `json:"location"`
+		} `json:"errors"`
+		// Middleware envelope
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+		return apiErr
+	}
+
+	if envelope.Error != nil {
+		apiErr.Code = envelope.Error.Code
+		apiErr.Message = envelope.Error.Message
+		return apiErr
+	}
+	apiErr.Code = envelope.Code
+	apiErr.Message = envelope.Detail
+	for _, detail := range envelope.Errors {
+		if detail.Location != "" {
+			apiErr.Details = append(apiErr.Details, detail.Location+": "+detail.Message)
+		} else {
+			apiErr.Details = append(apiErr.Details, detail.Message)
+		}
+	}
+	return apiErr
+}
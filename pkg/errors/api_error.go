@@ -1,23 +1,59 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"reflect"
 	"runtime/debug"
+	"sort"
 	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
 )
 
 type APIError struct {
 	StatusCode int    `json:"-"`
 	Code       string `json:"code"`
 	Message    string `json:"message"`
+	// Params fill placeholders in the translated message for Code
+	Params map[string]string `json:"-"`
 }
 
 func (e APIError) Error() string {
 	return e.Message
 }
 
+// With returns a copy of the error with a translation parameter set
+func (e APIError) With(name, value string) APIError {
+	params := make(map[string]string, len(e.Params)+1)
+	for k, v := range e.Params {
+		params[k] = v
+	}
+	params[name] = value
+	e.Params = params
+	return e
+}
+
+// Localize translates the message into the request's language by its code
+func Localize(ctx context.Context, apiErr APIError) APIError {
+	apiErr.Message = i18n.Translate(ctx, apiErr.Code, apiErr.Message, apiErr.Params)
+	return apiErr
+}
+
+func New(statusCode int, code, message string) APIError {
+	return APIError{
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    message,
+	}
+}
+
 func RespondWithError(w http.ResponseWriter, err error) {
 	switch e := err.(type) {
 	case ValidationError:
@@ -93,9 +129,46 @@ func Forbidden(message string) APIError {
 	}
 }
 
+func InsufficientScope(message string) APIError {
+	return APIError{
+		StatusCode: http.StatusForbidden,
+		Code:       "INSUFFICIENT_SCOPE",
+		Message:    message,
+	}
+}
+
+func QuotaExceeded(message string) APIError {
+	return APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Code:       "QUOTA_EXCEEDED",
+		Message:    message,
+	}
+}
+
+func RateLimited(message string) APIError {
+	return APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Code:       "RATE_LIMITED",
+		Message:    message,
+	}
+}
+
+// ClassRateLimited is returned when a client has used up the budget of
+// one class of operations; other classes keep their own
+func ClassRateLimited(message string) APIError {
+	return APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Code:       "CLASS_RATE_LIMITED",
+		Message:    message,
+	}
+}
+
 type ValidationError struct {
 	APIError
 	Fields map[string]string `json:"fields"`
+	// Values holds the offending value of each field in Fields, where
+	// known
+	Values map[string]any `json:"-"`
 }
 
 func NewValidationError(fields map[string]string) ValidationError {
@@ -125,6 +198,201 @@ func RespondWithValidationError(w http.ResponseWriter, validationErr ValidationE
 	})
 }
 
+// RespondWithHumaError writes an APIError from inside a Huma middleware
+func RespondWithHumaError(ctx huma.Context, apiErr APIError) {
+	apiErr = Localize(ctx.Context(), apiErr)
+	ctx.SetHeader("Content-Type", "application/json")
+	ctx.SetStatus(apiErr.StatusCode)
+	json.NewEncoder(ctx.BodyWriter()).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":    apiErr.Code,
+			"message": apiErr.Message,
+		},
+	})
+}
+
+// WriteHuma writes a Huma error from inside a Huma middleware in the
+// problem-details form handlers return, for errors that need per-field
+// details
+func WriteHuma(ctx huma.Context, err huma.StatusError) {
+	ctx.SetHeader("Content-Type", "application/problem+json")
+	ctx.SetStatus(err.GetStatus())
+	json.NewEncoder(ctx.BodyWriter()).Encode(err)
+}
+
+// FieldError describes one invalid field in the terms of a validation
+// rule, such as min with param -90, so its message can be translated
+type FieldError struct {
+	// Field is the field's JSON name
+	Field string
+	Rule  string
+	Param string
+	// Value is the value given, or nil when it was missing
+	Value any
+}
+
+// FromFields converts invalid fields into a ValidationError whose field
+// messages are translated into the request's language
+func FromFields(ctx context.Context, fieldErrs []FieldError) ValidationError {
+	fields := make(map[string]string, len(fieldErrs))
+	values := make(map[string]any, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		params := map[string]string{"field": fieldErr.Field, "param": fieldErr.Param}
+		key := "validation." + fieldErr.Rule
+		if _, ok := i18n.Default.Message(i18n.DefaultLanguage, key, nil); !ok {
+			key = "validation.invalid"
+		}
+		fields[fieldErr.Field] = i18n.Translate(ctx, key, "", params)
+		if value := detailValue(fieldErr.Value); value != nil {
+			values[fieldErr.Field] = value
+		}
+	}
+
+	validationErr := NewValidationError(fields)
+	validationErr.Values = values
+	validationErr.Message = i18n.Translate(ctx, validationErr.Code, "Validation failed", nil)
+	return validationErr
+}
+
+// FromValidator converts struct validation failures into a ValidationError
+// whose field messages are translated into the request's language
+func FromValidator(ctx context.Context, err error) (ValidationError, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return ValidationError{}, false
+	}
+
+	fieldErrs := make([]FieldError, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		fieldErrs[i] = FieldError{
+			Field: jsonFieldName(fieldErr.Field()),
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+			Value: fieldErr.Value(),
+		}
+	}
+	return FromFields(ctx, fieldErrs), true
+}
+
+// detailValue dereferences a pointer field's value, so a missing optional
+// field has no value rather than null
+func detailValue(value any) any {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// jsonFieldName maps a Go field name to the lower-camel JSON name used by the DTOs
+func jsonFieldName(field string) string {
+	if field == "" {
+		return field
+	}
+	return strings.ToLower(field[:1]) + field[1:]
+}
+
+// CodedError is a Huma problem-details error that also carries the stable
+// error code, so clients can match on it regardless of language
+type CodedError struct {
+	huma.ErrorModel
+	Code string `json:"code" doc:"Stable machine-readable error code"`
+}
+
+// Huma derives the error schema in the OpenAPI document from the type
+// NewError returns, so replacing it declares the code field and gives
+// Huma's own errors, such as request validation failures, a code too
+func init() {
+	huma.NewError = NewHumaError
+}
+
+// statusCodes maps the statuses Huma reports itself onto error codes
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            "BAD_REQUEST",
+	http.StatusUnauthorized:          "UNAUTHORIZED",
+	http.StatusForbidden:             "FORBIDDEN",
+	http.StatusNotFound:              "NOT_FOUND",
+	http.StatusConflict:              "CONFLICT",
+	http.StatusRequestEntityTooLarge: "LIMIT_EXCEEDED",
+	http.StatusUnprocessableEntity:   "VALIDATION_ERROR",
+	http.StatusTooManyRequests:       "QUOTA_EXCEEDED",
+}
+
+// NewHumaError is huma.NewError producing a CodedError. Statuses without
+// a specific code map to BAD_REQUEST or INTERNAL_SERVER_ERROR.
+func NewHumaError(status int, message string, errs ...error) huma.StatusError {
+	details := make([]*huma.ErrorDetail, 0, len(errs))
+	for _, err := range errs {
+		if detailer, ok := err.(huma.ErrorDetailer); ok {
+			details = append(details, detailer.ErrorDetail())
+		} else if err != nil {
+			details = append(details, &huma.ErrorDetail{Message: err.Error()})
+		}
+	}
+
+	code, ok := statusCodes[status]
+	if !ok {
+		code = "INTERNAL_SERVER_ERROR"
+		if status >= 400 && status < 500 {
+			code = "BAD_REQUEST"
+		}
+	}
+	return &CodedError{
+		ErrorModel: huma.ErrorModel{
+			Title:  http.StatusText(status),
+			Status: status,
+			Detail: message,
+			Errors: details,
+		},
+		Code: code,
+	}
+}
+
+// ToHuma converts an error into a localized Huma error for a handler to
+// return. Errors that are not APIErrors become internal server errors.
+func ToHuma(ctx context.Context, err error) huma.StatusError {
+	var validationErr ValidationError
+	if errors.As(err, &validationErr) {
+		coded := newCodedError(validationErr.APIError)
+		names := make([]string, 0, len(validationErr.Fields))
+		for name := range validationErr.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			coded.Errors = append(coded.Errors, &huma.ErrorDetail{
+				Message:  validationErr.Fields[name],
+				Location: "body." + name,
+				Value:    validationErr.Values[name],
+			})
+		}
+		return coded
+	}
+
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = InternalServerError("Internal server error")
+	}
+	return newCodedError(Localize(ctx, apiErr))
+}
+
+func newCodedError(apiErr APIError) *CodedError {
+	return &CodedError{
+		ErrorModel: huma.ErrorModel{
+			Title:  http.StatusText(apiErr.StatusCode),
+			Status: apiErr.StatusCode,
+			Detail: apiErr.Message,
+		},
+		Code: apiErr.Code,
+	}
+}
+
 func ErrorHandlingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -136,4 +404,4 @@ func ErrorHandlingMiddleware(next http.Handler) http.Handler {
 		}()
 		next.ServeHTTP(w, r)
 	})
-}
\ No newline at end of file
+}
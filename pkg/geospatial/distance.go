@@ -0,0 +1,94 @@
+package geospatial
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Distance is a length stored in meters. Build one with a constructor or a
+// unit constant and read it back with an accessor, so the unit is always
+// explicit at the call site:
+//
+//	d := Kilometers(2.5)
+//	d.Meters() // 2500
+//
+// Distances encode to JSON as a number of kilometers.
+type Distance float64
+
+// Common distance units
+const (
+	Meter        Distance = 1
+	Kilometer    Distance = 1000
+	Mile         Distance = Kilometer * MilesToKmRatio
+	NauticalMile Distance = Kilometer * NauticalMilesToKmRatio
+)
+
+// Meters returns a distance of m meters
+func Meters(m float64) Distance {
+	return Distance(m)
+}
+
+// Kilometers returns a distance of km kilometers
+func Kilometers(km float64) Distance {
+	return Distance(km) * Kilometer
+}
+
+// Miles returns a distance of mi statute miles
+func Miles(mi float64) Distance {
+	return Distance(mi) * Mile
+}
+
+// NauticalMiles returns a distance of nmi nautical miles
+func NauticalMiles(nmi float64) Distance {
+	return Distance(nmi) * NauticalMile
+}
+
+// Meters returns the distance in meters
+func (d Distance) Meters() float64 {
+	return float64(d)
+}
+
+// Kilometers returns the distance in kilometers
+func (d Distance) Kilometers() float64 {
+	return float64(d / Kilometer)
+}
+
+// Miles returns the distance in statute miles
+func (d Distance) Miles() float64 {
+	return float64(d / Mile)
+}
+
+// NauticalMiles returns the distance in nautical miles
+func (d Distance) NauticalMiles() float64 {
+	return float64(d / NauticalMile)
+}
+
+// TravelTime returns how long covering the distance takes at speedKmh, or
+// 0 when the speed is not positive
+func (d Distance) TravelTime(speedKmh float64) time.Duration {
+	if speedKmh <= 0 {
+		return 0
+	}
+	return time.Duration(d.Kilometers() / speedKmh * float64(time.Hour))
+}
+
+// String formats the distance in kilometers, e.g. "2.37km"
+func (d Distance) String() string {
+	return strconv.FormatFloat(d.Kilometers(), 'f', -1, 64) + "km"
+}
+
+// MarshalJSON encodes the distance as a number of kilometers
+func (d Distance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Kilometers())
+}
+
+// UnmarshalJSON decodes a number of kilometers
+func (d *Distance) UnmarshalJSON(data []byte) error {
+	var km float64
+	if err := json.Unmarshal(data, &km); err != nil {
+		return err
+	}
+	*d = Kilometers(km)
+	return nil
+}
@@ -0,0 +1,99 @@
+package geospatial
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDistanceConversions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		distance      Distance
+		meters        float64
+		kilometers    float64
+		miles         float64
+		nauticalMiles float64
+	}{
+		{"meters", Meters(1852), 1852, 1.852, 1.150779, 1},
+		{"kilometers", Kilometers(2.5), 2500, 2.5, 1.553428, 1.349892},
+		{"miles", Miles(1), 1609.344, 1.609344, 1, 0.868976},
+		{"nautical miles", NauticalMiles(2), 3704, 3.704, 2.301558, 2},
+		{"zero", 0, 0, 0, 0, 0},
+	}
+
+	const delta = 1e-6
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.distance
+			if math.Abs(d.Meters()-tt.meters) > delta {
+				t.Errorf("Meters() = %v, want %v", d.Meters(), tt.meters)
+			}
+			if math.Abs(d.Kilometers()-tt.kilometers) > delta {
+				t.Errorf("Kilometers() = %v, want %v", d.Kilometers(), tt.kilometers)
+			}
+			if math.Abs(d.Miles()-tt.miles) > delta {
+				t.Errorf("Miles() = %v, want %v", d.Miles(), tt.miles)
+			}
+			if math.Abs(d.NauticalMiles()-tt.nauticalMiles) > delta {
+				t.Errorf("NauticalMiles() = %v, want %v", d.NauticalMiles(), tt.nauticalMiles)
+			}
+		})
+	}
+}
+
+func TestDistanceUnitsAreDistinct(t *testing.T) {
+	t.Parallel()
+	if Kilometers(1) != 1000*Meter || Meters(1000) != Kilometer {
+		t.Errorf("Expected 1 km to equal 1000 m")
+	}
+	if 3*Kilometer != Kilometers(3) {
+		t.Errorf("Expected unit constants to scale like constructors")
+	}
+}
+
+func TestDistanceJSON(t *testing.T) {
+	t.Parallel()
+	payload, err := json.Marshal(struct {
+		Distance Distance  `json:"distance_km"`
+		Optional *Distance `json:"optional_km,omitempty"`
+	}{Distance: Meters(2370)})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if string(payload) != `{"distance_km":2.37}` {
+		t.Errorf("Expected the distance in kilometres, got %s", payload)
+	}
+
+	var decoded struct {
+		Distance Distance `json:"distance_km"`
+	}
+	if err := json.Unmarshal([]byte(`{"distance_km":2.37}`), &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if math.Abs(decoded.Distance.Meters()-2370) > 1e-9 {
+		t.Errorf("Expected 2370 m, got %v", decoded.Distance.Meters())
+	}
+	if err := json.Unmarshal([]byte(`{"distance_km":"far"}`), &decoded); err == nil {
+		t.Error("Expected an error for a non-numeric distance")
+	}
+}
+
+func TestDistanceTravelTime(t *testing.T) {
+	t.Parallel()
+	if got := Kilometers(15).TravelTime(30); got != 30*time.Minute {
+		t.Errorf("TravelTime(30) = %v, want 30m", got)
+	}
+	if got := Kilometers(15).TravelTime(0); got != 0 {
+		t.Errorf("Expected no travel time without a speed, got %v", got)
+	}
+}
+
+func TestDistanceString(t *testing.T) {
+	t.Parallel()
+	if got := Kilometers(2.5).String(); got != "2.5km" {
+		t.Errorf("String() = %q, want 2.5km", got)
+	}
+}
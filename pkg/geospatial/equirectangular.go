@@ -0,0 +1,43 @@
+package geospatial
+
+// DistanceFunc computes the distance between two coordinates
+type DistanceFunc func(p1, p2 Coordinate) Distance
+
+// DistanceStrategy selects how nearest-location scans measure distance
+type DistanceStrategy string
+
+const (
+	// DistanceExact measures every candidate with HaversineDistance
+	DistanceExact DistanceStrategy = "exact"
+	// DistanceFast measures every candidate with EquirectangularDistance,
+	// except for queries beyond FastDistanceMaxLatitude
+	DistanceFast DistanceStrategy = "fast"
+	// DistanceAuto ranks candidates with EquirectangularDistance and then
+	// re-ranks the closest ones with HaversineDistance
+	DistanceAuto DistanceStrategy = "auto"
+)
+
+// FastDistanceMaxKm is the separation below which EquirectangularDistance
+// stays within FastDistanceMaxError of HaversineDistance
+const FastDistanceMaxKm = 50.0
+
+// FastDistanceMaxLatitude is the latitude, in degrees, beyond which the
+// equirectangular error bound no longer holds for FastDistanceMaxKm
+const FastDistanceMaxLatitude = 70.0
+
+// FastDistanceMaxError is the relative error bound of EquirectangularDistance
+// for separations up to FastDistanceMaxKm at latitudes up to FastDistanceMaxLatitude
+const FastDistanceMaxError = 0.001
+
+// EquirectangularDistance approximates the distance between two coordinates
+// by projecting them onto a plane scaled at their mean latitude.
+//
+// It needs one cosine instead of Haversine's several trigonometric calls.
+// For separations up to FastDistanceMaxKm at latitudes up to
+// FastDistanceMaxLatitude the result is within FastDistanceMaxError
+// (0.1%) of HaversineDistance. The error grows with separation and
+// latitude, reaching several percent across continents, so use it for
+// ranking nearby points rather than reporting long distances.
+func EquirectangularDistance(p1, p2 Coordinate) Distance {
+	return Earth.EquirectangularDistance(p1, p2)
+}
@@ -0,0 +1,120 @@
+package geospatial
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// destination returns the point reached by travelling distanceKm from start
+// on the given bearing, in degrees clockwise from north
+func destination(start Coordinate, bearing, distanceKm float64) Coordinate {
+	lat1 := toRadians(start.Latitude)
+	lon1 := toRadians(start.Longitude)
+	theta := toRadians(bearing)
+	delta := distanceKm / EarthRadiusKm
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	lon2 := lon1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat1), math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+	return Coordinate{Latitude: lat2 * 180 / math.Pi, Longitude: lon2 * 180 / math.Pi}
+}
+
+func TestEquirectangularDistanceErrorBound(t *testing.T) {
+	t.Parallel()
+	worst := 0.0
+	for lat := -FastDistanceMaxLatitude; lat <= FastDistanceMaxLatitude; lat += 5 {
+		for bearing := 0.0; bearing < 360; bearing += 15 {
+			for _, distance := range []float64{0.5, 1, 5, 10, 25, FastDistanceMaxKm} {
+				start := Coordinate{Latitude: lat, Longitude: 3.38}
+				end := destination(start, bearing, distance)
+
+				exact := HaversineDistance(start, end).Kilometers()
+				fast := EquirectangularDistance(start, end).Kilometers()
+				relative := math.Abs(fast-exact) / exact
+				worst = math.Max(worst, relative)
+				if relative > FastDistanceMaxError {
+					t.Errorf("lat %.0f bearing %.0f %.1f km: fast %f vs exact %f (%.4f%%)",
+						lat, bearing, distance, fast, exact, relative*100)
+				}
+			}
+		}
+	}
+	t.Logf("worst relative error within bounds: %.5f%%", worst*100)
+}
+
+func TestEquirectangularDistance(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		p1    Coordinate
+		p2    Coordinate
+		delta float64
+	}{
+		{
+			name:  "Across Lagos",
+			p1:    Coordinate{Latitude: 6.6018, Longitude: 3.3515},
+			p2:    Coordinate{Latitude: 6.4474, Longitude: 3.4723},
+			delta: 0.01,
+		},
+		{
+			name:  "Across the antimeridian",
+			p1:    Coordinate{Latitude: -17.7, Longitude: 179.9},
+			p2:    Coordinate{Latitude: -17.8, Longitude: -179.9},
+			delta: 0.01,
+		},
+		{
+			name:  "Same point",
+			p1:    Coordinate{Latitude: 40.7128, Longitude: -74.0060},
+			p2:    Coordinate{Latitude: 40.7128, Longitude: -74.0060},
+			delta: 0.001,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := HaversineDistance(tt.p1, tt.p2).Kilometers()
+			result := EquirectangularDistance(tt.p1, tt.p2).Kilometers()
+			if math.Abs(result-expected) > tt.delta {
+				t.Errorf("EquirectangularDistance() = %v, want %v ± %v", result, expected, tt.delta)
+			}
+		})
+	}
+}
+
+func benchmarkPoints(n int) []Coordinate {
+	rng := rand.New(rand.NewSource(1))
+	points := make([]Coordinate, n)
+	for i := range points {
+		// A metro-sized area around Lagos
+		points[i] = Coordinate{Latitude: 6.4 + rng.Float64()*0.4, Longitude: 3.2 + rng.Float64()*0.5}
+	}
+	return points
+}
+
+func BenchmarkDistanceFunc(b *testing.B) {
+	query := Coordinate{Latitude: 6.5, Longitude: 3.4}
+	strategies := []struct {
+		name string
+		fn   DistanceFunc
+	}{
+		{"haversine", HaversineDistance},
+		{"equirectangular", EquirectangularDistance},
+	}
+
+	for _, size := range []int{100, 10000, 100000} {
+		points := benchmarkPoints(size)
+		for _, strategy := range strategies {
+			b.Run(fmt.Sprintf("%s/%d", strategy.name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					nearest := Distance(math.MaxFloat64)
+					for _, p := range points {
+						if d := strategy.fn(query, p); d < nearest {
+							nearest = d
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"math"
 )
 
-// EarthRadiusKm is the radius of the Earth in kilometers
+// EarthRadiusKm is the WGS-84 mean radius of the Earth in kilometers
 const EarthRadiusKm = 6371.0
 
 // Coordinate represents a geographic point with latitude and longitude
@@ -13,35 +13,33 @@ type Coordinate struct {
 	Longitude float64
 }
 
+const (
+	degreesToRadians     = math.Pi / 180
+	halfDegreesToRadians = math.Pi / 360
+)
+
 // toRadians converts degrees to radians
 func toRadians(degrees float64) float64 {
 	return degrees * math.Pi / 180
 }
 
 // HaversineDistance calculates the distance between two coordinates using the Haversine formula
-// Returns distance in kilometers
-func HaversineDistance(p1, p2 Coordinate) float64 {
-	// Convert latitude and longitude from degrees to radians
-	lat1 := toRadians(p1.Latitude)
-	lon1 := toRadians(p1.Longitude)
-	lat2 := toRadians(p2.Latitude)
-	lon2 := toRadians(p2.Longitude)
-
-	// Haversine formula
-	dLat := lat2 - lat1
-	dLon := lon2 - lon1
-	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	distance := EarthRadiusKm * c
+func HaversineDistance(p1, p2 Coordinate) Distance {
+	return Earth.Distance(p1, p2)
+}
 
-	return distance
+// HaversineDistanceKm calculates the Haversine distance in kilometers
+//
+// Deprecated: use HaversineDistance, which returns a Distance
+func HaversineDistanceKm(p1, p2 Coordinate) float64 {
+	return HaversineDistance(p1, p2).Kilometers()
 }
 
 // Conversion constants
 const (
-	KmToMilesRatio        = 0.621371
+	KmToMilesRatio         = 0.621371
 	KmToNauticalMilesRatio = 0.539957
-	MilesToKmRatio        = 1.609344
+	MilesToKmRatio         = 1.609344
 	NauticalMilesToKmRatio = 1.852
 )
 
@@ -67,10 +65,10 @@ func NauticalMilesToKm(nauticalMiles float64) float64 {
 
 // HaversineDistanceMiles calculates distance in miles
 func HaversineDistanceMiles(p1, p2 Coordinate) float64 {
-	return KmToMiles(HaversineDistance(p1, p2))
+	return Earth.DistanceMiles(p1, p2)
 }
 
 // HaversineDistanceNauticalMiles calculates distance in nautical miles
 func HaversineDistanceNauticalMiles(p1, p2 Coordinate) float64 {
-	return KmToNauticalMiles(HaversineDistance(p1, p2))
-}
\ No newline at end of file
+	return Earth.DistanceNauticalMiles(p1, p2)
+}
@@ -83,7 +83,7 @@ func TestHaversineDistance(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			distance := HaversineDistance(tt.p1, tt.p2)
+			distance := HaversineDistance(tt.p1, tt.p2).Kilometers()
 			if math.Abs(distance-tt.expected) > tt.delta {
 				t.Errorf("HaversineDistance() = %v, want %v (±%v)", distance, tt.expected, tt.delta)
 			}
@@ -298,7 +298,7 @@ func TestHaversineDistanceMiles(t *testing.T) {
 	p1 := Coordinate{Latitude: 40.7128, Longitude: -74.0060}
 	p2 := Coordinate{Latitude: 34.0522, Longitude: -118.2437}
 
-	distanceKm := HaversineDistance(p1, p2)
+	distanceKm := HaversineDistance(p1, p2).Kilometers()
 	distanceMiles := HaversineDistanceMiles(p1, p2)
 	expectedMiles := KmToMiles(distanceKm)
 
@@ -312,7 +312,7 @@ func TestHaversineDistanceNauticalMiles(t *testing.T) {
 	p1 := Coordinate{Latitude: 40.7128, Longitude: -74.0060}
 	p2 := Coordinate{Latitude: 34.0522, Longitude: -118.2437}
 
-	distanceKm := HaversineDistance(p1, p2)
+	distanceKm := HaversineDistance(p1, p2).Kilometers()
 	distanceNauticalMiles := HaversineDistanceNauticalMiles(p1, p2)
 	expectedNauticalMiles := KmToNauticalMiles(distanceKm)
 
@@ -320,3 +320,65 @@ func TestHaversineDistanceNauticalMiles(t *testing.T) {
 		t.Errorf("HaversineDistanceNauticalMiles() = %v, want %v", distanceNauticalMiles, expectedNauticalMiles)
 	}
 }
+
+// referenceHaversine is the formula Distance used before it was optimised,
+// kept to check the results did not change
+func referenceHaversine(p1, p2 Coordinate) float64 {
+	lat1 := toRadians(p1.Latitude)
+	lon1 := toRadians(p1.Longitude)
+	lat2 := toRadians(p2.Latitude)
+	lon2 := toRadians(p2.Longitude)
+
+	dLat := lat2 - lat1
+	dLon := lon2 - lon1
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return EarthRadiusKm * c
+}
+
+func TestHaversineMatchesReference(t *testing.T) {
+	t.Parallel()
+	var grid []Coordinate
+	for lat := -90.0; lat <= 90; lat += 7.5 {
+		for lng := -180.0; lng <= 180; lng += 11.25 {
+			grid = append(grid, Coordinate{Latitude: lat, Longitude: lng})
+		}
+	}
+	// Short hops, where rounding matters most relative to the distance
+	grid = append(grid,
+		Coordinate{Latitude: 6.5244, Longitude: 3.3792},
+		Coordinate{Latitude: 6.5244001, Longitude: 3.3792001},
+		Coordinate{Latitude: -33.8688, Longitude: 151.2093},
+		Coordinate{Latitude: 89.9999, Longitude: -179.9999},
+	)
+
+	for _, p1 := range grid {
+		for _, p2 := range grid {
+			want := referenceHaversine(p1, p2)
+			got := HaversineDistance(p1, p2).Kilometers()
+			// 1e-12 relative, or absolute below a kilometre
+			if math.Abs(got-want) > 1e-12*math.Max(want, 1) {
+				t.Fatalf("%+v to %+v: got %.15g km, reference %.15g km", p1, p2, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkHaversine(b *testing.B) {
+	points := benchmarkPoints(1000)
+	query := Coordinate{Latitude: 6.5, Longitude: 3.4}
+	b.Run("reference", func(b *testing.B) {
+		var sink float64
+		for i := 0; i < b.N; i++ {
+			sink += referenceHaversine(query, points[i%len(points)])
+		}
+		_ = sink
+	})
+	b.Run("optimised", func(b *testing.B) {
+		var sink Distance
+		for i := 0; i < b.N; i++ {
+			sink += HaversineDistance(query, points[i%len(points)])
+		}
+		_ = sink
+	})
+}
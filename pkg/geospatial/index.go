@@ -0,0 +1,443 @@
+package geospatial
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// indexCellDegrees is the side of an index cell, about 11 km at the
+	// equator
+	indexCellDegrees = 0.1
+	indexRows        = int(180 / indexCellDegrees)
+	indexColumns     = int(360 / indexCellDegrees)
+
+	// boundSlack shrinks the lower bounds searches prune cells with, so
+	// rounding in the distance formulas never prunes a cell holding a
+	// point at exactly the bound
+	boundSlack = 1e-9
+)
+
+// ErrScanLimit is returned by NearestApproximate when it examines more
+// points than its limit allows
+var ErrScanLimit = errors.New("scan limit exceeded")
+
+// Result is a point a search found and its distance from the query point
+type Result struct {
+	ID         string
+	Coordinate Coordinate
+	Distance   Distance
+}
+
+// Index finds the points nearest a coordinate, or within a radius of it,
+// without measuring the distance to every point. Points are bucketed in
+// cells of a tenth of a degree, and a search examines the cells around the
+// query point outward until no cell it has not examined could hold a
+// nearer point. Distances are great-circle distances on the index's
+// sphere. An Index is safe for concurrent use.
+type Index struct {
+	sphere Sphere
+
+	mu     sync.RWMutex
+	points map[string]Coordinate
+	cells  map[indexCell]map[string]Coordinate
+}
+
+// NewIndex returns an empty index measuring distances on sphere, or on
+// Earth when the sphere has no radius
+func NewIndex(sphere Sphere) *Index {
+	if sphere.RadiusKm <= 0 {
+		sphere = Earth
+	}
+	return &Index{
+		sphere: sphere,
+		points: make(map[string]Coordinate),
+		cells:  make(map[indexCell]map[string]Coordinate),
+	}
+}
+
+// Insert adds a point, or moves the point already stored under id
+func (x *Index) Insert(id string, c Coordinate) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+	x.points[id] = c
+	cell := indexCellOf(c)
+	if x.cells[cell] == nil {
+		x.cells[cell] = make(map[string]Coordinate)
+	}
+	x.cells[cell][id] = c
+}
+
+// Remove forgets the point stored under id, if any
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+}
+
+// remove forgets a point; the caller holds the write lock
+func (x *Index) remove(id string) {
+	c, ok := x.points[id]
+	if !ok {
+		return
+	}
+	delete(x.points, id)
+	cell := indexCellOf(c)
+	delete(x.cells[cell], id)
+	if len(x.cells[cell]) == 0 {
+		delete(x.cells, cell)
+	}
+}
+
+// Coordinate returns the point stored under id
+func (x *Index) Coordinate(id string) (Coordinate, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	c, ok := x.points[id]
+	return c, ok
+}
+
+// Len returns the number of points in the index
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return len(x.points)
+}
+
+// CellSizes returns the number of points in each occupied cell, in no
+// particular order
+func (x *Index) CellSizes() []int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	sizes := make([]int, 0, len(x.cells))
+	for _, bucket := range x.cells {
+		sizes = append(sizes, len(bucket))
+	}
+	return sizes
+}
+
+// Clone returns an independent copy of the index. Its maps are sized for
+// the points it holds, so cloning also gives back the memory of points
+// removed since the index was built.
+func (x *Index) Clone() *Index {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	clone := &Index{
+		sphere: x.sphere,
+		points: make(map[string]Coordinate, len(x.points)),
+		cells:  make(map[indexCell]map[string]Coordinate, len(x.cells)),
+	}
+	for id, c := range x.points {
+		clone.points[id] = c
+	}
+	for cell, bucket := range x.cells {
+		copied := make(map[string]Coordinate, len(bucket))
+		for id, c := range bucket {
+			copied[id] = c
+		}
+		clone.cells[cell] = copied
+	}
+	return clone
+}
+
+// Nearest returns the k points nearest c, nearest first. Points at the
+// same distance are ordered by ID.
+func (x *Index) Nearest(c Coordinate, k int) []Result {
+	return x.NearestMatching(c, k, nil)
+}
+
+// NearestMatching returns the k points nearest c for which match reports
+// true, nearest first; a nil match accepts every point. match is called
+// with the index locked for reading, so it must not change the index.
+// Points at the same distance are ordered by ID.
+func (x *Index) NearestMatching(c Coordinate, k int, match func(id string) bool) []Result {
+	return x.NearestMatchingFunc(c, k, match, strings.Compare)
+}
+
+// NearestMatchingFunc is NearestMatching with points at the same distance
+// ordered by tie, which compares their IDs and is called under the same
+// lock as match
+func (x *Index) NearestMatchingFunc(c Coordinate, k int, match func(id string) bool, tie func(a, b string) int) []Result {
+	if k <= 0 {
+		return nil
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	compare := func(a, b Result) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), tie(a.ID, b.ID))
+	}
+	best := make([]Result, 0, min(k, len(x.points)))
+	limit := func() Distance {
+		if len(best) < k {
+			return Distance(math.MaxFloat64)
+		}
+		return best[k-1].Distance
+	}
+	x.search(c, limit, func(id string, p Coordinate) {
+		if match != nil && !match(id) {
+			return
+		}
+		result := Result{ID: id, Coordinate: p, Distance: x.sphere.Distance(c, p)}
+		i, _ := slices.BinarySearchFunc(best, result, compare)
+		if i >= k {
+			return
+		}
+		if len(best) == k {
+			best = best[:k-1]
+		}
+		best = slices.Insert(best, i, result)
+	})
+	return best
+}
+
+// WithinRadius returns every point at most km kilometres from c, nearest
+// first. Points at the same distance are ordered by ID.
+func (x *Index) WithinRadius(c Coordinate, km float64) []Result {
+	return x.WithinRadiusFunc(c, km, strings.Compare)
+}
+
+// WithinRadiusFunc is WithinRadius with points at the same distance
+// ordered by tie, which compares their IDs and is called with the index
+// locked for reading
+func (x *Index) WithinRadiusFunc(c Coordinate, km float64, tie func(a, b string) int) []Result {
+	if km < 0 {
+		return nil
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	radius := Kilometers(km)
+	var found []Result
+	x.search(c, func() Distance { return radius }, func(id string, p Coordinate) {
+		if distance := x.sphere.Distance(c, p); distance <= radius {
+			found = append(found, Result{ID: id, Coordinate: p, Distance: distance})
+		}
+	})
+	slices.SortFunc(found, func(a, b Result) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), tie(a.ID, b.ID))
+	})
+	return found
+}
+
+// NearestApproximate examines the cells around c ring by ring until one
+// holds a point match accepts, then one ring more, and returns the nearest
+// accepted point it saw and whether there was one. The answer can miss a
+// nearer point just outside the last ring, but the search stops far
+// sooner than an exact one where points are dense. When limit is positive
+// it fails with ErrScanLimit once it has examined more than limit points,
+// accepted or not. A nil match accepts every point. Of points at the same
+// distance the one with the lowest ID wins.
+func (x *Index) NearestApproximate(c Coordinate, limit int, match func(id string) bool) (Result, bool, error) {
+	return x.NearestApproximateFunc(c, limit, match, strings.Compare)
+}
+
+// NearestApproximateFunc is NearestApproximate with points at the same
+// distance ordered by tie, the first winning
+func (x *Index) NearestApproximateFunc(c Coordinate, limit int, match func(id string) bool, tie func(a, b string) int) (Result, bool, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var nearest Result
+	found := false
+	examined := 0
+	lastRing := math.MaxInt
+	var err error
+
+	x.cellsByRing(indexCellOf(c), func(cell indexCell, ring int) bool {
+		if ring > lastRing {
+			return false
+		}
+		for id, p := range x.cells[cell] {
+			examined++
+			if limit > 0 && examined > limit {
+				err = ErrScanLimit
+				return false
+			}
+			if match != nil && !match(id) {
+				continue
+			}
+			distance := x.sphere.Distance(c, p)
+			if !found || cmp.Or(cmp.Compare(distance, nearest.Distance), tie(id, nearest.ID)) < 0 {
+				nearest = Result{ID: id, Coordinate: p, Distance: distance}
+				found = true
+			}
+		}
+		if found && lastRing == math.MaxInt {
+			lastRing = ring + 1
+		}
+		return true
+	})
+
+	if err != nil {
+		return Result{}, false, err
+	}
+	return nearest, found, nil
+}
+
+func compareResults(a, b Result) int {
+	return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.ID, b.ID))
+}
+
+// search visits the points in the cells around c, nearest cells first,
+// and stops once no cell left could hold a point within limit of c. The
+// caller holds the lock.
+func (x *Index) search(c Coordinate, limit func() Distance, visit func(id string, p Coordinate)) {
+	query := indexCellOf(c)
+	boundRing, bound := -1, Distance(0)
+	x.cellsByRing(query, func(cell indexCell, ring int) bool {
+		if ring != boundRing {
+			boundRing, bound = ring, x.ringBound(c, query, ring)
+		}
+		if bound > limit() {
+			return false
+		}
+		for id, p := range x.cells[cell] {
+			visit(id, p)
+		}
+		return true
+	})
+}
+
+// ringBound is a lower bound on the distance from c to any point in a cell
+// ring or more cells from query, the cell holding c: such a point lies
+// outside the block of cells fewer than ring cells away, so it is at least
+// as far as the nearest edge of that block. A point past an east or west
+// edge dLon degrees away is at least the distance to the meridian there,
+// and no point is further than a pole is across the far side of the
+// sphere.
+func (x *Index) ringBound(c Coordinate, query indexCell, ring int) Distance {
+	if ring == 0 {
+		return 0
+	}
+	inner := ring - 1
+	longitude := c.Longitude
+	if longitude >= 180 {
+		longitude -= 360
+	}
+
+	degrees := math.Inf(1)
+	if query.row-inner > 0 {
+		south := -90 + float64(query.row-inner)*indexCellDegrees
+		degrees = min(degrees, c.Latitude-south)
+	}
+	if query.row+inner+1 < indexRows {
+		north := -90 + float64(query.row+inner+1)*indexCellDegrees
+		degrees = min(degrees, north-c.Latitude)
+	}
+	if 2*inner+1 < indexColumns {
+		west := -180 + float64(query.column-inner)*indexCellDegrees
+		east := -180 + float64(query.column+inner+1)*indexCellDegrees
+		dLon := min(longitude-west, east-longitude, 90)
+		crossTrack := math.Asin(math.Cos(toRadians(c.Latitude))*math.Sin(toRadians(dLon))) / degreesToRadians
+		degrees = min(degrees, crossTrack)
+	}
+	if math.IsInf(degrees, 1) {
+		return Distance(math.MaxFloat64)
+	}
+	return Kilometers(x.sphere.RadiusKm * toRadians(max(degrees, 0)) * (1 - boundSlack))
+}
+
+// indexCell is a row and column of the index grid, counted from the south
+// pole and the antimeridian
+type indexCell struct {
+	row, column int
+}
+
+func indexCellOf(c Coordinate) indexCell {
+	row := min(int(math.Floor((c.Latitude+90)/indexCellDegrees)), indexRows-1)
+	column := int(math.Floor((c.Longitude+180)/indexCellDegrees)) % indexColumns
+	return indexCell{row: max(row, 0), column: max(column, 0)}
+}
+
+// ring is how many cells away c is from the query cell, counting diagonal
+// steps as one and wrapping around the antimeridian
+func (c indexCell) ring(query indexCell) int {
+	columns := abs(c.column - query.column)
+	return max(abs(c.row-query.row), min(columns, indexColumns-columns))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// cellsByRing visits the occupied cells in rings around query, nearest
+// ring first, until visit returns false. Rings are probed one at a time
+// while they are smaller than the number of occupied cells; the rest are
+// sorted by ring, so a sparse index is not walked cell by cell. The
+// caller holds the lock.
+func (x *Index) cellsByRing(query indexCell, visit func(cell indexCell, ring int) bool) {
+	probed := -1
+	for ring := 0; 2*ring+1 <= indexColumns && ringSize(ring) <= len(x.cells); ring++ {
+		for _, cell := range ringCells(query, ring) {
+			if x.cells[cell] != nil && !visit(cell, ring) {
+				return
+			}
+		}
+		probed = ring
+	}
+
+	type ringed struct {
+		cell indexCell
+		ring int
+	}
+	var rest []ringed
+	for cell := range x.cells {
+		if ring := cell.ring(query); ring > probed {
+			rest = append(rest, ringed{cell, ring})
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].ring < rest[j].ring })
+	for _, r := range rest {
+		if !visit(r.cell, r.ring) {
+			return
+		}
+	}
+}
+
+func ringSize(ring int) int {
+	if ring == 0 {
+		return 1
+	}
+	return 8 * ring
+}
+
+// ringCells lists the cells exactly ring cells from query, skipping rows
+// past the poles
+func ringCells(query indexCell, ring int) []indexCell {
+	if ring == 0 {
+		return []indexCell{query}
+	}
+	cells := make([]indexCell, 0, 8*ring)
+	wrap := func(column int) int {
+		return ((column % indexColumns) + indexColumns) % indexColumns
+	}
+	for row := query.row - ring; row <= query.row+ring; row++ {
+		if row < 0 || row >= indexRows {
+			continue
+		}
+		if row == query.row-ring || row == query.row+ring {
+			for column := query.column - ring; column <= query.column+ring; column++ {
+				cells = append(cells, indexCell{row, wrap(column)})
+			}
+			continue
+		}
+		cells = append(cells, indexCell{row, wrap(query.column - ring)}, indexCell{row, wrap(query.column + ring)})
+	}
+	return cells
+}
@@ -0,0 +1,231 @@
+package geospatial
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// bruteForce measures every point, the answer an index must match
+func bruteForce(points map[string]Coordinate, c Coordinate) []Result {
+	results := make([]Result, 0, len(points))
+	for id, p := range points {
+		results = append(results, Result{ID: id, Coordinate: p, Distance: Earth.Distance(c, p)})
+	}
+	slices.SortFunc(results, compareResults)
+	return results
+}
+
+// clusteredPoints scatters points around a few centres, including the
+// poles and both sides of the antimeridian, plus some spread worldwide
+func clusteredPoints(rng *rand.Rand, n int) map[string]Coordinate {
+	centres := []Coordinate{
+		{Latitude: 6.5244, Longitude: 3.3792},
+		{Latitude: 89.95, Longitude: 0},
+		{Latitude: -89.95, Longitude: 120},
+		{Latitude: -17.7, Longitude: 179.98},
+		{Latitude: -17.7, Longitude: -179.98},
+	}
+	points := make(map[string]Coordinate, n)
+	for i := range n {
+		var c Coordinate
+		if i%5 == 0 {
+			c = Coordinate{Latitude: rng.Float64()*180 - 90, Longitude: rng.Float64()*360 - 180}
+		} else {
+			centre := centres[i%len(centres)]
+			c = Coordinate{
+				Latitude:  min(max(centre.Latitude+rng.NormFloat64()*0.3, -90), 90),
+				Longitude: centre.Longitude + rng.NormFloat64()*0.3,
+			}
+			if c.Longitude >= 180 {
+				c.Longitude -= 360
+			} else if c.Longitude < -180 {
+				c.Longitude += 360
+			}
+		}
+		points[fmt.Sprintf("p%d", i)] = c
+	}
+	return points
+}
+
+func TestIndexMatchesBruteForce(t *testing.T) {
+	t.Parallel()
+	rng := rand.New(rand.NewSource(1))
+	points := clusteredPoints(rng, 2000)
+	index := NewIndex(Earth)
+	for id, c := range points {
+		index.Insert(id, c)
+	}
+
+	queries := []Coordinate{
+		{Latitude: 6.5, Longitude: 3.4},
+		{Latitude: 90, Longitude: 45},
+		{Latitude: -90, Longitude: -45},
+		{Latitude: -17.7, Longitude: 180},
+		{Latitude: -17.7, Longitude: -180},
+		{Latitude: 0, Longitude: 0},
+	}
+	for range 50 {
+		queries = append(queries, Coordinate{Latitude: rng.Float64()*180 - 90, Longitude: rng.Float64()*360 - 180})
+	}
+
+	for _, q := range queries {
+		want := bruteForce(points, q)
+		for _, k := range []int{1, 5, 50} {
+			if got := index.Nearest(q, k); !slices.Equal(got, want[:k]) {
+				t.Errorf("Nearest(%v, %d) = %v, want %v", q, k, got, want[:k])
+			}
+		}
+		for _, km := range []float64{0, 5, 40, 500} {
+			end := 0
+			for end < len(want) && want[end].Distance <= Kilometers(km) {
+				end++
+			}
+			if got := index.WithinRadius(q, km); !slices.Equal(got, want[:end]) {
+				t.Errorf("WithinRadius(%v, %v) returned %d points, want %d", q, km, len(got), end)
+			}
+		}
+	}
+}
+
+func TestIndexNearestMatching(t *testing.T) {
+	t.Parallel()
+	index := NewIndex(Earth)
+	index.Insert("ikeja", Coordinate{Latitude: 6.6018, Longitude: 3.3515})
+	index.Insert("lekki", Coordinate{Latitude: 6.4698, Longitude: 3.5852})
+	index.Insert("abuja", Coordinate{Latitude: 9.0765, Longitude: 7.3986})
+
+	q := Coordinate{Latitude: 6.6, Longitude: 3.35}
+	got := index.NearestMatching(q, 2, func(id string) bool { return id != "ikeja" })
+	if len(got) != 2 || got[0].ID != "lekki" || got[1].ID != "abuja" {
+		t.Errorf("Expected lekki then abuja without ikeja, got %v", got)
+	}
+	if got := index.Nearest(q, 10); len(got) != 3 {
+		t.Errorf("Expected every point when k exceeds the index, got %v", got)
+	}
+	if got := index.Nearest(q, 0); got != nil {
+		t.Errorf("Expected nothing for k = 0, got %v", got)
+	}
+}
+
+func TestIndexNearestTies(t *testing.T) {
+	t.Parallel()
+	index := NewIndex(Earth)
+	same := Coordinate{Latitude: 6.5095, Longitude: 3.3711}
+	for _, id := range []string{"10", "9", "2"} {
+		index.Insert(id, same)
+	}
+	byNumber := func(a, b string) int {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return cmp.Compare(x, y)
+	}
+
+	q := Coordinate{Latitude: 6.5, Longitude: 3.37}
+	for range 20 {
+		if got := index.Nearest(q, 3); got[0].ID != "10" || got[1].ID != "2" || got[2].ID != "9" {
+			t.Fatalf("Expected ties ordered by ID, got %v", got)
+		}
+		if got := index.NearestMatchingFunc(q, 2, nil, byNumber); got[0].ID != "2" || got[1].ID != "9" {
+			t.Fatalf("Expected ties ordered by tie, got %v", got)
+		}
+		if got, _, _ := index.NearestApproximate(q, 0, nil); got.ID != "10" {
+			t.Fatalf("Expected the lowest ID of a tie, got %v", got)
+		}
+		if got, _, _ := index.NearestApproximateFunc(q, 0, nil, byNumber); got.ID != "2" {
+			t.Fatalf("Expected the first of a tie by tie, got %v", got)
+		}
+	}
+}
+
+func TestIndexInsertReplacesAndRemoveForgets(t *testing.T) {
+	t.Parallel()
+	index := NewIndex(Sphere{})
+	index.Insert("a", Coordinate{Latitude: 10, Longitude: 10})
+	index.Insert("a", Coordinate{Latitude: -10, Longitude: -10})
+
+	if index.Len() != 1 {
+		t.Fatalf("Expected one point after moving it, got %d", index.Len())
+	}
+	if got := index.WithinRadius(Coordinate{Latitude: 10, Longitude: 10}, 100); len(got) != 0 {
+		t.Errorf("Expected nothing left at the old coordinate, got %v", got)
+	}
+	if c, ok := index.Coordinate("a"); !ok || c.Latitude != -10 {
+		t.Errorf("Expected the new coordinate, got %v %v", c, ok)
+	}
+
+	clone := index.Clone()
+	index.Remove("a")
+	index.Remove("missing")
+	if index.Len() != 0 || len(index.CellSizes()) != 0 || len(index.Nearest(Coordinate{}, 1)) != 0 {
+		t.Errorf("Expected an empty index after Remove")
+	}
+	if clone.Len() != 1 {
+		t.Errorf("Expected the clone to keep its point, got %d", clone.Len())
+	}
+}
+
+func TestIndexNearestApproximate(t *testing.T) {
+	t.Parallel()
+	index := NewIndex(Earth)
+	for i := range 100 {
+		index.Insert(fmt.Sprintf("p%d", i), Coordinate{Latitude: 6.51 + float64(i)*0.0001, Longitude: 3.31})
+	}
+	index.Insert("far", Coordinate{Latitude: 40, Longitude: 3.31})
+
+	q := Coordinate{Latitude: 6.51, Longitude: 3.31}
+	result, found, err := index.NearestApproximate(q, 0, nil)
+	if err != nil || !found || result.ID != "p0" {
+		t.Errorf("Expected p0, got %v %v %v", result, found, err)
+	}
+	// The only match is far beyond the ring after the first occupied one
+	result, found, err = index.NearestApproximate(q, 0, func(id string) bool { return id == "far" })
+	if err != nil || !found || result.ID != "far" {
+		t.Errorf("Expected the search to widen to far, got %v %v %v", result, found, err)
+	}
+	if _, _, err := index.NearestApproximate(q, 50, nil); !errors.Is(err, ErrScanLimit) {
+		t.Errorf("Expected ErrScanLimit past the limit, got %v", err)
+	}
+}
+
+func TestIndexConcurrentUse(t *testing.T) {
+	t.Parallel()
+	index := NewIndex(Earth)
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := range 500 {
+				id := fmt.Sprintf("w%d-%d", w, i%50)
+				index.Insert(id, Coordinate{Latitude: rng.Float64()*2 + 6, Longitude: rng.Float64()*2 + 3})
+				if i%3 == 0 {
+					index.Remove(id)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			q := Coordinate{Latitude: 7, Longitude: 4}
+			for range 500 {
+				results := index.Nearest(q, 5)
+				if !slices.IsSortedFunc(results, compareResults) {
+					t.Errorf("Expected results nearest first, got %v", results)
+					return
+				}
+				index.WithinRadius(q, 50)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if index.Len() > 200 {
+		t.Errorf("Expected at most 200 points, got %d", index.Len())
+	}
+}
@@ -0,0 +1,41 @@
+package geospatial
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DistanceMatrix measures the distance on Earth from every origin to every
+// destination; see Sphere.DistanceMatrix
+func DistanceMatrix(origins, destinations []Coordinate) [][]Distance {
+	return Earth.DistanceMatrix(origins, destinations)
+}
+
+// DistanceMatrix measures the distance on the sphere from every origin to
+// every destination. Row i holds the distances from origins[i], in the
+// order of destinations. Rows are shared out among a pool of one worker
+// per CPU.
+func (s Sphere) DistanceMatrix(origins, destinations []Coordinate) [][]Distance {
+	matrix := make([][]Distance, len(origins))
+	rows := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(origins)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rows {
+				row := make([]Distance, len(destinations))
+				for j, destination := range destinations {
+					row[j] = s.Distance(origins[i], destination)
+				}
+				matrix[i] = row
+			}
+		}()
+	}
+	for i := range origins {
+		rows <- i
+	}
+	close(rows)
+	wg.Wait()
+	return matrix
+}
@@ -0,0 +1,67 @@
+package geospatial
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomCoordinates(rng *rand.Rand, n int) []Coordinate {
+	points := make([]Coordinate, n)
+	for i := range points {
+		points[i] = Coordinate{Latitude: rng.Float64()*180 - 90, Longitude: rng.Float64()*360 - 180}
+	}
+	return points
+}
+
+func TestDistanceMatrix(t *testing.T) {
+	origins := []Coordinate{{Latitude: 6.4474, Longitude: 3.4720}, {Latitude: 9.0579, Longitude: 7.4951}}
+	destinations := []Coordinate{{Latitude: 6.6018, Longitude: 3.3515}, {Latitude: 4.8156, Longitude: 7.0498}, {Latitude: 6.4474, Longitude: 3.4720}}
+
+	matrix := DistanceMatrix(origins, destinations)
+	if len(matrix) != len(origins) {
+		t.Fatalf("Expected %d rows, got %d", len(origins), len(matrix))
+	}
+	for i, row := range matrix {
+		if len(row) != len(destinations) {
+			t.Fatalf("Expected %d columns in row %d, got %d", len(destinations), i, len(row))
+		}
+		for j, distance := range row {
+			if want := HaversineDistance(origins[i], destinations[j]); distance != want {
+				t.Errorf("[%d][%d]: expected %v, got %v", i, j, want, distance)
+			}
+		}
+	}
+	if matrix[0][2] != 0 {
+		t.Errorf("Expected zero from a point to itself, got %v", matrix[0][2])
+	}
+
+	if empty := DistanceMatrix(nil, destinations); len(empty) != 0 {
+		t.Errorf("Expected no rows without origins, got %v", empty)
+	}
+}
+
+func TestDistanceMatrixSymmetric(t *testing.T) {
+	points := randomCoordinates(rand.New(rand.NewSource(1)), 200)
+
+	matrix := NewSphere(3389.5).DistanceMatrix(points, points)
+	for i := range points {
+		if matrix[i][i] != 0 {
+			t.Errorf("Expected a zero diagonal, got [%d][%d] = %v", i, i, matrix[i][i])
+		}
+		for j := range i {
+			if matrix[i][j] != matrix[j][i] {
+				t.Fatalf("Expected a symmetric matrix, got [%d][%d] = %v and [%d][%d] = %v", i, j, matrix[i][j], j, i, matrix[j][i])
+			}
+		}
+	}
+}
+
+func BenchmarkDistanceMatrix(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	origins, destinations := randomCoordinates(rng, 100), randomCoordinates(rng, 100)
+
+	b.ResetTimer()
+	for range b.N {
+		DistanceMatrix(origins, destinations)
+	}
+}
@@ -0,0 +1,70 @@
+package geospatial
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// metersPerDegree is the length of one degree of latitude on Earth
+const metersPerDegree = EarthRadiusKm * 1000 * math.Pi / 180
+
+// minObscureCos keeps longitude offsets finite close to the poles
+const minObscureCos = 0.01
+
+// SnapToGrid moves a coordinate to the centre of its cell in a grid of
+// roughly cellMeters square cells. Rows are cellMeters of latitude apart
+// and each row is split into cells cellMeters wide at its centre, so every
+// point in a cell snaps to the same coordinate; the last row and column
+// are cut short at 90 and 180 degrees. A cellMeters of 0 or less returns c
+// unchanged.
+func SnapToGrid(c Coordinate, cellMeters float64) Coordinate {
+	if cellMeters <= 0 {
+		return c
+	}
+	latStep := math.Min(cellMeters/metersPerDegree, 180)
+	latitude := cellCentre(c.Latitude, -90, 90, latStep)
+	lonStep := math.Min(latStep/math.Max(math.Cos(toRadians(latitude)), minObscureCos), 360)
+	return Coordinate{Latitude: latitude, Longitude: cellCentre(c.Longitude, -180, 180, lonStep)}
+}
+
+// cellCentre returns the middle of the step-wide cell, counted from low,
+// that holds value
+func cellCentre(value, low, high, step float64) float64 {
+	start := low + math.Floor((value-low)/step)*step
+	if start >= high {
+		start = high - step
+	}
+	return (start + math.Min(start+step, high)) / 2
+}
+
+// Jitter moves a coordinate up to radiusMeters in a direction and by a
+// distance drawn from seed, spread evenly over the disc. The same seed
+// always gives the same offset, so repeated answers cannot be averaged
+// back to the true position. seed must hold at least 16 bytes and should
+// not be derivable by callers, such as an HMAC of an ID under a secret.
+func Jitter(c Coordinate, seed []byte, radiusMeters float64) Coordinate {
+	if radiusMeters <= 0 || len(seed) < 16 {
+		return c
+	}
+	u := float64(binary.BigEndian.Uint64(seed[:8])) / math.MaxUint64
+	v := float64(binary.BigEndian.Uint64(seed[8:16])) / math.MaxUint64
+	distance := radiusMeters * math.Sqrt(u)
+	bearing := 2 * math.Pi * v
+
+	latitude := c.Latitude + distance*math.Cos(bearing)/metersPerDegree
+	latitude = math.Max(-90, math.Min(90, latitude))
+	cos := math.Max(math.Cos(toRadians(c.Latitude)), minObscureCos)
+	longitude := c.Longitude + distance*math.Sin(bearing)/(metersPerDegree*cos)
+	return Coordinate{Latitude: latitude, Longitude: wrapLongitude(longitude)}
+}
+
+// wrapLongitude brings a longitude back into [-180, 180]
+func wrapLongitude(longitude float64) float64 {
+	if longitude > 180 {
+		return longitude - 360
+	}
+	if longitude < -180 {
+		return longitude + 360
+	}
+	return longitude
+}
@@ -0,0 +1,68 @@
+package geospatial
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSnapToGrid(t *testing.T) {
+	t.Parallel()
+	const cell = 500.0
+	points := []Coordinate{
+		{Latitude: 6.4474, Longitude: 3.4723},
+		{Latitude: -33.8688, Longitude: 151.2093},
+		{Latitude: 64.1466, Longitude: -21.9426},
+		{Latitude: 0, Longitude: 179.9999},
+		{Latitude: 89.9999, Longitude: 12},
+	}
+	for _, p := range points {
+		snapped := SnapToGrid(p, cell)
+		if again := SnapToGrid(p, cell); again != snapped {
+			t.Errorf("%+v: expected the same cell twice, got %+v and %+v", p, snapped, again)
+		}
+		// A cell centre snaps to itself, so every point in the cell shares it
+		if centre := SnapToGrid(snapped, cell); centre != snapped {
+			t.Errorf("%+v: expected the centre %+v to snap to itself, got %+v", p, snapped, centre)
+		}
+		if d := HaversineDistance(p, snapped).Meters(); d > cell {
+			t.Errorf("%+v: snapped %.0f m away to %+v, more than one cell", p, d, snapped)
+		}
+		if snapped.Latitude < -90 || snapped.Latitude > 90 || snapped.Longitude < -180 || snapped.Longitude > 180 {
+			t.Errorf("%+v: snapped out of range to %+v", p, snapped)
+		}
+	}
+
+	// Points a few metres apart inside one cell share its centre
+	a := SnapToGrid(Coordinate{Latitude: 6.44741, Longitude: 3.47231}, cell)
+	b := SnapToGrid(Coordinate{Latitude: 6.44742, Longitude: 3.47232}, cell)
+	if a != b {
+		t.Errorf("Expected neighbouring points to share a cell, got %+v and %+v", a, b)
+	}
+	if p := (Coordinate{Latitude: 6.4474, Longitude: 3.4723}); SnapToGrid(p, 0) != p {
+		t.Errorf("Expected a zero cell to leave the coordinate unchanged")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	t.Parallel()
+	const radius = 500.0
+	p := Coordinate{Latitude: 6.4474, Longitude: 3.4723}
+	seen := map[Coordinate]bool{}
+	for _, id := range []string{"1", "2", "3", "42", "1000"} {
+		seed := sha256.Sum256([]byte(id))
+		jittered := Jitter(p, seed[:], radius)
+		if again := Jitter(p, seed[:], radius); again != jittered {
+			t.Errorf("%s: expected the same offset twice, got %+v and %+v", id, jittered, again)
+		}
+		if d := HaversineDistance(p, jittered).Meters(); d > radius+1e-6 {
+			t.Errorf("%s: moved %.1f m, beyond the %v m radius", id, d, radius)
+		}
+		seen[jittered] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("Expected a different offset per seed, got %d distinct", len(seen))
+	}
+	if Jitter(p, []byte("short"), radius) != p {
+		t.Errorf("Expected a short seed to leave the coordinate unchanged")
+	}
+}
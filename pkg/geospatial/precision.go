@@ -0,0 +1,66 @@
+package geospatial
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCoordinatePrecision is the number of decimal places coordinates are
+// kept to by default; 6 places is about 0.11 m at the equator
+const DefaultCoordinatePrecision = 6
+
+// MinCoordinatePrecision and MaxCoordinatePrecision bound the configurable precision
+const (
+	MinCoordinatePrecision = 4
+	MaxCoordinatePrecision = 9
+)
+
+// RoundCoordinate rounds a coordinate to the given number of decimal places,
+// half away from zero.
+//
+// Rounding works on the shortest decimal representation of value rather
+// than its binary expansion, so 0.1234565 rounds to 0.123457 even though the
+// nearest float64 is slightly below it. The result is the float64 closest to
+// the rounded decimal, which encodes back to at most places decimals.
+func RoundCoordinate(value float64, places int) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) || places < 0 {
+		return value
+	}
+
+	s := strconv.FormatFloat(math.Abs(value), 'f', -1, 64)
+	dot := strings.IndexByte(s, '.')
+	if dot < 0 || len(s)-dot-1 <= places {
+		return value
+	}
+
+	digits := []byte(s[:dot] + s[dot+1:dot+1+places])
+	intLen := dot
+	if s[dot+1+places] >= '5' {
+		i := len(digits) - 1
+		for ; i >= 0 && digits[i] == '9'; i-- {
+			digits[i] = '0'
+		}
+		if i >= 0 {
+			digits[i]++
+		} else {
+			digits = append([]byte{'1'}, digits...)
+			intLen++
+		}
+	}
+
+	rounded, err := strconv.ParseFloat(string(digits[:intLen])+"."+string(digits[intLen:]), 64)
+	if err != nil || rounded == 0 {
+		// Avoid returning negative zero
+		return 0
+	}
+	return math.Copysign(rounded, value)
+}
+
+// RoundCoordinates rounds both components of a coordinate
+func RoundCoordinates(c Coordinate, places int) Coordinate {
+	return Coordinate{
+		Latitude:  RoundCoordinate(c.Latitude, places),
+		Longitude: RoundCoordinate(c.Longitude, places),
+	}
+}
@@ -0,0 +1,65 @@
+package geospatial
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestRoundCoordinate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		value    float64
+		places   int
+		expected float64
+	}{
+		{"float noise", -74.00600000000001, 6, -74.006},
+		{"half rounds up", 0.1234565, 6, 0.123457},
+		{"half rounds away from zero", -0.1234565, 6, -0.123457},
+		{"below half rounds down", 0.12345649, 6, 0.123456},
+		{"carries into the integer part", 89.9999995, 6, 90},
+		{"carries across the sign", -179.99999, 4, -180},
+		{"already short", 6.4474, 6, 6.4474},
+		{"integer", 3, 6, 3},
+		{"rounds to zero without a sign", -0.0000004, 6, 0},
+		{"nine places", 3.4723123456789, 9, 3.472312346},
+		{"four places", 3.47235, 4, 3.4724},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RoundCoordinate(tt.value, tt.places)
+			if result != tt.expected {
+				t.Errorf("RoundCoordinate(%v, %d) = %v, want %v", tt.value, tt.places, result, tt.expected)
+			}
+			if math.Signbit(result) && result == 0 {
+				t.Errorf("RoundCoordinate(%v, %d) returned negative zero", tt.value, tt.places)
+			}
+		})
+	}
+}
+
+func TestRoundCoordinateIsStable(t *testing.T) {
+	t.Parallel()
+	values := []float64{-74.00600000000001, 40.712776, 0.1 + 0.2, 6.447412345, -33.868820000000004}
+	for places := MinCoordinatePrecision; places <= MaxCoordinatePrecision; places++ {
+		for _, value := range values {
+			once := RoundCoordinate(value, places)
+			if twice := RoundCoordinate(once, places); twice != once {
+				t.Errorf("rounding %v to %d places is not idempotent: %v then %v", value, places, once, twice)
+			}
+
+			// A JSON round trip gives back the same value
+			encoded, _ := json.Marshal(once)
+			var decoded float64
+			json.Unmarshal(encoded, &decoded)
+			if decoded != once {
+				t.Errorf("%v did not survive a JSON round trip: %s decoded to %v", once, encoded, decoded)
+			}
+			if RoundCoordinate(decoded, places) != decoded {
+				t.Errorf("%s decoded to a value that rounds differently", encoded)
+			}
+		}
+	}
+}
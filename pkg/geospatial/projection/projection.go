@@ -0,0 +1,146 @@
+// Package projection converts coordinates in a few projected reference
+// systems to WGS-84. Only the systems our data sources use are supported,
+// each with a closed-form inverse, so no projection library is needed.
+package projection
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+// Supported EPSG codes
+const (
+	// WGS84 is latitude and longitude in degrees, as stored
+	WGS84 = 4326
+	// WebMercator is the spherical Mercator of web maps, in metres
+	WebMercator = 3857
+	// UTM31N, UTM32N and UTM33N are the UTM zones covering Nigeria, in
+	// metres; Lagos is in 31N
+	UTM31N = 32631
+	UTM32N = 32632
+	UTM33N = 32633
+)
+
+var (
+	// ErrUnsupported is returned for an EPSG code outside Supported
+	ErrUnsupported = errors.New("unsupported coordinate reference system")
+	// ErrOutsideArea is returned when a point does not transform to a
+	// location inside the system's area of use
+	ErrOutsideArea = errors.New("coordinates outside the reference system's area of use")
+)
+
+// Supported lists the EPSG codes ToWGS84 accepts
+func Supported() []int {
+	return []int{WGS84, WebMercator, UTM31N, UTM32N, UTM33N}
+}
+
+// IsSupported reports whether ToWGS84 accepts crs
+func IsSupported(crs int) bool {
+	return slices.Contains(Supported(), crs)
+}
+
+// ToWGS84 converts x and y in the system with EPSG code crs to WGS-84. x is
+// the easting, or the longitude for WGS84, and y the northing or latitude.
+// The result must fall inside the system's area of use.
+func ToWGS84(crs int, x, y float64) (geospatial.Coordinate, error) {
+	if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return geospatial.Coordinate{}, fmt.Errorf("%w: EPSG:%d", ErrOutsideArea, crs)
+	}
+	var c geospatial.Coordinate
+	var inside bool
+	switch crs {
+	case WGS84:
+		c = geospatial.Coordinate{Latitude: y, Longitude: x}
+		inside = y >= -90 && y <= 90 && x >= -180 && x <= 180
+	case WebMercator:
+		c, inside = fromWebMercator(x, y)
+	case UTM31N, UTM32N, UTM33N:
+		c, inside = fromUTMNorth(crs-32600, x, y)
+	default:
+		return geospatial.Coordinate{}, fmt.Errorf("%w: EPSG:%d", ErrUnsupported, crs)
+	}
+	if !inside {
+		return geospatial.Coordinate{}, fmt.Errorf("%w: EPSG:%d", ErrOutsideArea, crs)
+	}
+	return c, nil
+}
+
+const (
+	// wgs84A and wgs84F are the WGS-84 semi-major axis and flattening
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+
+	// webMercatorMax is half the Earth's circumference in web Mercator
+	// metres: the largest x, and y at the 85.06° cut-off of web maps
+	webMercatorMax = 20037508.342789244
+)
+
+// fromWebMercator inverts the spherical Mercator on the WGS-84 semi-major
+// axis
+func fromWebMercator(x, y float64) (geospatial.Coordinate, bool) {
+	c := geospatial.Coordinate{
+		Latitude:  toDegrees(math.Atan(math.Sinh(y / wgs84A))),
+		Longitude: toDegrees(x / wgs84A),
+	}
+	return c, math.Abs(x) <= webMercatorMax && math.Abs(y) <= webMercatorMax
+}
+
+const (
+	utmScale    = 0.9996
+	utmEasting  = 500000.0
+	utmMaxNorth = 84.0
+	// utmZoneMargin widens a zone's 6° band, as surveys near a boundary
+	// often stay in one zone; the error is still well under a metre there
+	utmZoneMargin = 1.0
+)
+
+// utmSeries holds the Krüger series for the transverse Mercator inverse to
+// third order in the third flattening, accurate to well under a
+// millimetre within a zone
+var utmSeries = func() (s struct {
+	a           float64
+	beta, delta [3]float64
+}) {
+	n := wgs84F / (2 - wgs84F)
+	n2, n3 := n*n, n*n*n
+	s.a = wgs84A / (1 + n) * (1 + n2/4 + n2*n2/64)
+	s.beta = [3]float64{n/2 - 2*n2/3 + 37*n3/96, n2/48 + n3/15, 17 * n3 / 480}
+	s.delta = [3]float64{2*n - 2*n2/3 - 2*n3, 7*n2/3 - 8*n3/5, 56 * n3 / 15}
+	return s
+}()
+
+// fromUTMNorth inverts a northern-hemisphere UTM zone
+func fromUTMNorth(zone int, easting, northing float64) (geospatial.Coordinate, bool) {
+	scale := utmScale * utmSeries.a
+	xi := northing / scale
+	eta := (easting - utmEasting) / scale
+
+	xiP, etaP := xi, eta
+	for j, beta := range utmSeries.beta {
+		k := 2 * float64(j+1)
+		xiP -= beta * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaP -= beta * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xiP) / math.Cosh(etaP))
+	phi := chi
+	for j, delta := range utmSeries.delta {
+		phi += delta * math.Sin(2*float64(j+1)*chi)
+	}
+
+	centralMeridian := float64(6*zone - 183)
+	c := geospatial.Coordinate{
+		Latitude:  toDegrees(phi),
+		Longitude: centralMeridian + toDegrees(math.Atan2(math.Sinh(etaP), math.Cos(xiP))),
+	}
+	inside := c.Latitude >= 0 && c.Latitude <= utmMaxNorth &&
+		math.Abs(c.Longitude-centralMeridian) <= 3+utmZoneMargin
+	return c, inside
+}
+
+func toDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
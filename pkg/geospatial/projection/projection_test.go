@@ -0,0 +1,72 @@
+package projection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+)
+
+func TestToWGS84ControlPoints(t *testing.T) {
+	t.Parallel()
+	// Projected coordinates computed independently with the forward Krüger
+	// series and the spherical Mercator
+	tests := []struct {
+		name string
+		crs  int
+		x, y float64
+		want geospatial.Coordinate
+	}{
+		{"wgs84", WGS84, 3.3792, 6.5244, geospatial.Coordinate{Latitude: 6.5244, Longitude: 3.3792}},
+		{"utm 31n central meridian", UTM31N, 500000, 0, geospatial.Coordinate{Latitude: 0, Longitude: 3}},
+		{"utm 31n lagos", UTM31N, 541924.3014, 721189.2172, geospatial.Coordinate{Latitude: 6.5244, Longitude: 3.3792}},
+		{"utm 31n yaba", UTM31N, 541029.9649, 719541.4063, geospatial.Coordinate{Latitude: 6.5095, Longitude: 3.3711}},
+		{"utm 31n badagry", UTM31N, 486873.8055, 709081.4436, geospatial.Coordinate{Latitude: 6.415, Longitude: 2.8813}},
+		{"utm 31n paris", UTM31N, 448251.8983, 5411943.7938, geospatial.Coordinate{Latitude: 48.8583, Longitude: 2.2945}},
+		{"utm 32n kano", UTM32N, 455585.5063, 1326829.7923, geospatial.Coordinate{Latitude: 12.0022, Longitude: 8.592}},
+		{"utm 32n abuja", UTM32N, 334598.6286, 1001595.4792, geospatial.Coordinate{Latitude: 9.0579, Longitude: 7.4951}},
+		{"utm 32n port harcourt", UTM32N, 283711.0411, 532590.1620, geospatial.Coordinate{Latitude: 4.8156, Longitude: 7.0498}},
+		{"utm 33n maiduguri", UTM33N, 298562.8583, 1308543.6888, geospatial.Coordinate{Latitude: 11.8311, Longitude: 13.151}},
+		{"web mercator lagos", WebMercator, 376170.8233, 727867.6202, geospatial.Coordinate{Latitude: 6.5244, Longitude: 3.3792}},
+		{"web mercator kano", WebMercator, 956457.0649, 1345958.7836, geospatial.Coordinate{Latitude: 12.0022, Longitude: 8.592}},
+		{"web mercator sydney", WebMercator, 16832542.2792, -4011198.6473, geospatial.Coordinate{Latitude: -33.8688, Longitude: 151.2093}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ToWGS84(tt.crs, tt.x, tt.y)
+			if err != nil {
+				t.Fatalf("Failed to transform: %v", err)
+			}
+			if d := geospatial.HaversineDistance(got, tt.want).Meters(); d > 0.01 {
+				t.Errorf("Expected %+v, got %+v, %.4f m away", tt.want, got, d)
+			}
+		})
+	}
+}
+
+func TestToWGS84Rejects(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		crs  int
+		x, y float64
+		want error
+	}{
+		{"unsupported", 27700, 530000, 180000, ErrUnsupported},
+		{"wgs84 latitude", WGS84, 3.4, 91, ErrOutsideArea},
+		{"web mercator past the cut-off", WebMercator, 0, 2.1e7, ErrOutsideArea},
+		// Kano's UTM 32N coordinates read as 31N land far east of the zone
+		{"utm 31n outside the zone", UTM31N, 1100000, 1326829, ErrOutsideArea},
+		{"utm 31n southern hemisphere", UTM31N, 500000, -1000, ErrOutsideArea},
+		{"utm 31n beyond 84 degrees north", UTM31N, 500000, 9400000, ErrOutsideArea},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got, err := ToWGS84(tt.crs, tt.x, tt.y); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %+v (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
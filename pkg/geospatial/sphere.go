@@ -0,0 +1,70 @@
+package geospatial
+
+import (
+	"math"
+)
+
+// Sphere is a reference sphere for distance calculations
+type Sphere struct {
+	// RadiusKm is the sphere's radius in kilometers
+	RadiusKm float64
+}
+
+// Earth is the default reference sphere, using the WGS-84 mean radius.
+// The package-level distance functions measure on Earth.
+var Earth = Sphere{RadiusKm: EarthRadiusKm}
+
+// NewSphere returns a sphere with the given radius, or Earth when the
+// radius is not positive
+func NewSphere(radiusKm float64) Sphere {
+	if radiusKm <= 0 {
+		return Earth
+	}
+	return Sphere{RadiusKm: radiusKm}
+}
+
+// Distance calculates the distance between two coordinates on the sphere
+// using the Haversine formula. It is the inner loop of nearest searches in
+// the memory store, so it squares by multiplication rather than math.Pow and
+// converts the half-angle differences to radians in one step.
+func (s Sphere) Distance(p1, p2 Coordinate) Distance {
+	lat1 := p1.Latitude * degreesToRadians
+	lat2 := p2.Latitude * degreesToRadians
+	sinDLat := math.Sin((p2.Latitude - p1.Latitude) * halfDegreesToRadians)
+	sinDLon := math.Sin((p2.Longitude - p1.Longitude) * halfDegreesToRadians)
+
+	a := sinDLat*sinDLat + math.Cos(lat1)*math.Cos(lat2)*sinDLon*sinDLon
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return Kilometers(s.RadiusKm * c)
+}
+
+// DistanceMiles calculates the Haversine distance on the sphere in miles
+func (s Sphere) DistanceMiles(p1, p2 Coordinate) float64 {
+	return KmToMiles(s.Distance(p1, p2).Kilometers())
+}
+
+// DistanceNauticalMiles calculates the Haversine distance on the sphere in nautical miles
+func (s Sphere) DistanceNauticalMiles(p1, p2 Coordinate) float64 {
+	return KmToNauticalMiles(s.Distance(p1, p2).Kilometers())
+}
+
+// EquirectangularDistance approximates the distance between two coordinates
+// on the sphere; see the package-level EquirectangularDistance for its
+// error bounds, which are relative and so hold for any radius
+func (s Sphere) EquirectangularDistance(p1, p2 Coordinate) Distance {
+	lat1 := toRadians(p1.Latitude)
+	lat2 := toRadians(p2.Latitude)
+
+	// Take the short way round across the antimeridian
+	dLon := toRadians(p2.Longitude - p1.Longitude)
+	if dLon > math.Pi {
+		dLon -= 2 * math.Pi
+	} else if dLon < -math.Pi {
+		dLon += 2 * math.Pi
+	}
+
+	x := dLon * math.Cos((lat1+lat2)/2)
+	y := lat2 - lat1
+	return Kilometers(s.RadiusKm * math.Sqrt(x*x+y*y))
+}
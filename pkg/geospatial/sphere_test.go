@@ -0,0 +1,55 @@
+package geospatial
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSphereDistanceScalesWithRadius(t *testing.T) {
+	t.Parallel()
+	p1 := Coordinate{Latitude: 40.7128, Longitude: -74.0060}
+	p2 := Coordinate{Latitude: 34.0522, Longitude: -118.2437}
+
+	base := Earth.Distance(p1, p2).Kilometers()
+	for _, radius := range []float64{1, 3389.5, 6378.137, 2 * EarthRadiusKm} {
+		sphere := NewSphere(radius)
+		scale := radius / EarthRadiusKm
+
+		if got := sphere.Distance(p1, p2).Kilometers(); math.Abs(got-base*scale) > 1e-9*base*scale {
+			t.Errorf("radius %v: Distance = %v, want %v", radius, got, base*scale)
+		}
+		fast := Earth.EquirectangularDistance(p1, p2).Kilometers() * scale
+		if got := sphere.EquirectangularDistance(p1, p2).Kilometers(); math.Abs(got-fast) > 1e-9*fast {
+			t.Errorf("radius %v: EquirectangularDistance = %v, want %v", radius, got, fast)
+		}
+	}
+}
+
+func TestEarthMatchesPackageFunctions(t *testing.T) {
+	t.Parallel()
+	p1 := Coordinate{Latitude: 51.5074, Longitude: -0.1278}
+	p2 := Coordinate{Latitude: 48.8566, Longitude: 2.3522}
+
+	// The package-level functions must be bit-for-bit unchanged
+	if Earth.Distance(p1, p2) != HaversineDistance(p1, p2) {
+		t.Errorf("Earth.Distance differs from HaversineDistance")
+	}
+	if Earth.DistanceMiles(p1, p2) != HaversineDistanceMiles(p1, p2) {
+		t.Errorf("Earth.DistanceMiles differs from HaversineDistanceMiles")
+	}
+	if Earth.DistanceNauticalMiles(p1, p2) != HaversineDistanceNauticalMiles(p1, p2) {
+		t.Errorf("Earth.DistanceNauticalMiles differs from HaversineDistanceNauticalMiles")
+	}
+	if Earth.EquirectangularDistance(p1, p2) != EquirectangularDistance(p1, p2) {
+		t.Errorf("Earth.EquirectangularDistance differs from EquirectangularDistance")
+	}
+}
+
+func TestNewSphereDefaultsToEarth(t *testing.T) {
+	t.Parallel()
+	for _, radius := range []float64{0, -1} {
+		if sphere := NewSphere(radius); sphere != Earth {
+			t.Errorf("NewSphere(%v) = %v, want Earth", radius, sphere)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// DefaultLanguage is used when no requested language is supported
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Catalog holds translated messages keyed by language and stable message key
+type Catalog struct {
+	messages map[string]map[string]string
+}
+
+// Default is the catalog built from the embedded locale files
+var Default = mustLoad(locales)
+
+func mustLoad(fsys fs.FS) *Catalog {
+	catalog, err := NewCatalog(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+}
+
+// NewCatalog loads every locales/<lang>.json file from fsys
+func NewCatalog(fsys fs.FS) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{messages: make(map[string]map[string]string)}
+	for _, file := range files {
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			return nil, err
+		}
+		lang := strings.TrimSuffix(path.Base(file), ".json")
+		catalog.messages[strings.ToLower(lang)] = messages
+	}
+	return catalog, nil
+}
+
+// Languages returns the supported language tags in sorted order
+func (c *Catalog) Languages() []string {
+	langs := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Negotiate picks the best supported language for an Accept-Language header.
+// Ranges are tried by descending quality; each falls back from a regional
+// tag to its primary language (pt-BR to pt). Unmatched headers yield English.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if value, ok := strings.CutPrefix(param, "q="); ok {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					q = 0
+				}
+				quality = q
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return DefaultLanguage
+		}
+		for tag := r.tag; tag != ""; tag = parentTag(tag) {
+			if _, ok := c.messages[tag]; ok {
+				return tag
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+func parentTag(tag string) string {
+	i := strings.LastIndex(tag, "-")
+	if i < 0 {
+		return ""
+	}
+	return tag[:i]
+}
+
+// Message returns the message for key in lang with {name} placeholders
+// replaced from params
+func (c *Catalog) Message(lang, key string, params map[string]string) (string, bool) {
+	message, ok := c.messages[lang][key]
+	if !ok {
+		return "", false
+	}
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message, true
+}
+
+// Translate returns the message for key in the request's language. English
+// requests keep fallback when it is set, so callers' existing messages are
+// unchanged; any language without the key also gets fallback, then the
+// English catalog entry.
+func Translate(ctx context.Context, key, fallback string, params map[string]string) string {
+	if lang := LanguageFromContext(ctx); lang != DefaultLanguage {
+		if message, ok := Default.Message(lang, key, params); ok {
+			return message
+		}
+	}
+	if fallback != "" {
+		return fallback
+	}
+	if message, ok := Default.Message(DefaultLanguage, key, params); ok {
+		return message
+	}
+	return key
+}
+
+type languageKey struct{}
+
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// LanguageFromContext returns the negotiated language, defaulting to English
+func LanguageFromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Middleware negotiates the response language from Accept-Language and
+// stores it in the request context
+func Middleware(ctx huma.Context, next func(huma.Context)) {
+	lang := Default.Negotiate(ctx.Header("Accept-Language"))
+	ctx.SetHeader("Content-Language", lang)
+	ctx.AppendHeader("Vary", "Accept-Language")
+	next(huma.WithContext(ctx, WithLanguage(ctx.Context(), lang)))
+}
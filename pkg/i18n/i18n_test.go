@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "empty header", header: "", expected: "en"},
+		{name: "exact match", header: "fr", expected: "fr"},
+		{name: "regional tag falls back to primary", header: "pt-BR", expected: "pt"},
+		{name: "case insensitive", header: "FR-ca", expected: "fr"},
+		{name: "highest quality wins", header: "fr;q=0.5, pt;q=0.9", expected: "pt"},
+		{name: "unsupported ranges are skipped", header: "de-DE, ja;q=0.9, fr;q=0.8", expected: "fr"},
+		{name: "zero quality is excluded", header: "fr;q=0, pt;q=0.1", expected: "pt"},
+		{name: "order breaks quality ties", header: "pt, fr", expected: "pt"},
+		{name: "wildcard means default", header: "de, *;q=0.5, fr;q=0.1", expected: "en"},
+		{name: "unknown language falls back to English", header: "zh-Hant", expected: "en"},
+		{name: "malformed quality is ignored", header: "fr;q=abc, pt;q=0.2", expected: "pt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Default.Negotiate(tt.header); got != tt.expected {
+				t.Errorf("Negotiate(%q) = %q, expected %q", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCatalogsShareKeys(t *testing.T) {
+	t.Parallel()
+	english := Default.messages[DefaultLanguage]
+	for _, lang := range Default.Languages() {
+		for key := range english {
+			if _, ok := Default.messages[lang][key]; !ok {
+				t.Errorf("Catalog %s is missing %s", lang, key)
+			}
+		}
+		for key := range Default.messages[lang] {
+			if _, ok := english[key]; !ok {
+				t.Errorf("Catalog %s has %s which English lacks", lang, key)
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+	fr := WithLanguage(context.Background(), "fr")
+	en := context.Background()
+
+	if got := Translate(fr, "INSUFFICIENT_SCOPE", "needs write", map[string]string{"scope": "write"}); got != "Cette opération nécessite la portée write" {
+		t.Errorf("Unexpected French message: %q", got)
+	}
+	if got := Translate(en, "LOCATION_NOT_FOUND", "Station missing", nil); got != "Station missing" {
+		t.Errorf("Expected English callers to keep their message, got %q", got)
+	}
+	if got := Translate(en, "validation.required", "", map[string]string{"field": "name"}); got != "name is required" {
+		t.Errorf("Expected English catalog entry, got %q", got)
+	}
+	if got := Translate(fr, "UNKNOWN_CODE", "Fallback", nil); got != "Fallback" {
+		t.Errorf("Expected fallback for unknown key, got %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jesuloba-world/leeta-task/pkg/server"
+)
+
+// runEndpointSuite checks the behaviour every router must share
+func runEndpointSuite(t *testing.T, handler http.Handler) {
+	t.Helper()
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	if resp := serve(http.MethodGet, "/health", ""); resp.Code != http.StatusOK {
+		t.Errorf("Expected health 200, got %d", resp.Code)
+	}
+
+	// Path parameters arrive unescaped, slashes and all
+	for _, name := range []string{"Leeta Yaba/Annex", "Café #2 & Co", "50% Off", "Ọ̀jọ́ Station"} {
+		body, _ := json.Marshal(map[string]any{"name": name, "latitude": 6.5, "longitude": 3.37})
+		if resp := serve(http.MethodPost, "/locations", string(body)); resp.Code != http.StatusCreated {
+			t.Fatalf("Expected %q created, got %d: %s", name, resp.Code, resp.Body.String())
+		}
+		resp := serve(http.MethodGet, "/locations/"+url.PathEscape(name), "")
+		var location struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(resp.Body.Bytes(), &location)
+		if resp.Code != http.StatusOK || location.Name != name {
+			t.Errorf("Expected %q found, got %d: %s", name, resp.Code, resp.Body.String())
+		}
+	}
+	if resp := serve(http.MethodGet, "/locations/"+url.PathEscape("Leeta Yaba"), ""); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected the slash kept in the name, got %d", resp.Code)
+	}
+	if resp := serve(http.MethodDelete, "/locations/"+url.PathEscape("Leeta Yaba/Annex"), ""); resp.Code != http.StatusNoContent {
+		t.Errorf("Expected delete 204, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp := serve(http.MethodGet, "/nearest?lat=6.5&lng=3.4", "")
+	if resp.Code != http.StatusOK {
+		t.Errorf("Expected nearest 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = serve(http.MethodPut, "/health", "")
+	if resp.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for an unsupported method, got %d", resp.Code)
+	}
+	if allow := resp.Header().Get("Allow"); !strings.Contains(allow, http.MethodGet) {
+		t.Errorf("Expected Allow to list GET, got %q", allow)
+	}
+	if resp := serve(http.MethodGet, "/no-such-route", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown path, got %d", resp.Code)
+	}
+
+	if resp := serve(http.MethodGet, "/openapi.json", ""); resp.Code != http.StatusOK {
+		t.Errorf("Expected the OpenAPI document, got %d", resp.Code)
+	}
+	if resp := serve(http.MethodGet, "/metrics", ""); resp.Code != http.StatusOK {
+		t.Errorf("Expected metrics, got %d", resp.Code)
+	}
+	if resp := serve(http.MethodGet, "/ui", ""); resp.Code != http.StatusMovedPermanently {
+		t.Errorf("Expected the UI redirect, got %d", resp.Code)
+	}
+	if resp := serve(http.MethodGet, "/ui/app.js", ""); resp.Code != http.StatusOK {
+		t.Errorf("Expected a UI asset, got %d", resp.Code)
+	}
+}
+
+func TestRoutersBehaveAlike(t *testing.T) {
+	t.Setenv("UI_ENABLED", "true")
+	for _, name := range []string{"stdlib", "chi"} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("SERVER_ROUTER", name)
+			handler, app, err := server.New(loadConfig(t), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+			if err != nil {
+				t.Fatalf("Failed to build: %v", err)
+			}
+			startApp(t, app)
+			runEndpointSuite(t, handler)
+		})
+	}
+}
+
+func TestCallerChiRouter(t *testing.T) {
+	t.Setenv("UI_ENABLED", "true")
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Platform", "leeta")
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Get("/platform/ping", func(w http.ResponseWriter, _ *http.Request) { w.Write([]byte("pong")) })
+
+	handler, app, err := server.New(loadConfig(t), server.WithRouter(server.ChiRouter(r)), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+	runEndpointSuite(t, handler)
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/locations", nil))
+	if resp.Code != http.StatusOK || resp.Header().Get("X-Platform") != "leeta" {
+		t.Errorf("Expected the caller's middleware on API routes, got %d %q", resp.Code, resp.Header().Get("X-Platform"))
+	}
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/platform/ping", nil))
+	if resp.Body.String() != "pong" {
+		t.Errorf("Expected the caller's own routes kept, got %d %q", resp.Code, resp.Body.String())
+	}
+}
+
+func TestUnknownRouterRejected(t *testing.T) {
+	t.Setenv("SERVER_ROUTER", "gorilla")
+	if _, err := server.LoadConfig(); err == nil {
+		t.Error("Expected an unsupported router to be rejected")
+	}
+}
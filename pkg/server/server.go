@@ -0,0 +1,859 @@
+// Package server builds the location API as an http.Handler, so it can run
+// on its own, as cmd/api does, or be mounted inside another Go service.
+//
+//	cfg, err := server.LoadConfig()
+//	handler, app, err := server.New(cfg, server.WithBasePath("/geo"))
+//	mux.Handle("/geo/", handler)
+//	err = app.Start(ctx)
+//	defer app.Shutdown(context.Background())
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/nats-io/nats.go"
+
+	"github.com/jesuloba-world/leeta-task/internal/app"
+	"github.com/jesuloba-world/leeta-task/internal/auth"
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/demo"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/events"
+	"github.com/jesuloba-world/leeta-task/internal/handlers"
+	"github.com/jesuloba-world/leeta-task/internal/metrics"
+	"github.com/jesuloba-world/leeta-task/internal/middleware"
+	"github.com/jesuloba-world/leeta-task/internal/repository"
+	"github.com/jesuloba-world/leeta-task/internal/repository/cache"
+	"github.com/jesuloba-world/leeta-task/internal/repository/fallback"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/router"
+	"github.com/jesuloba-world/leeta-task/internal/scheduler"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/internal/ui"
+	"github.com/jesuloba-world/leeta-task/pkg/geospatial"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+	"github.com/jesuloba-world/leeta-task/pkg/webhook"
+)
+
+// Config is the service configuration. LoadConfig reads it from the same
+// environment variables cmd/api uses; fields can be changed afterwards.
+type Config = config.Config
+
+// Repositories is the set of stores the API reads and writes
+type Repositories = repository.Repositories
+
+// App runs the API's background components: the event exporter, outbox
+// dispatcher, usage flushing, scheduled jobs and the startup integrity
+// check. Start them before serving and Shutdown after the last request.
+// More components, such as an HTTP server, can be added with Add; they
+// start after and stop before everything New added.
+type App = app.App
+
+// Component is a part of an App with a lifecycle
+type Component = app.Component
+
+// Router is the HTTP router the API's routes are registered on
+type Router = router.Router
+
+// StdlibRouter registers the API on mux through Huma's humago adapter.
+// It is the default, and what SERVER_ROUTER=stdlib selects.
+func StdlibRouter(mux *http.ServeMux) Router {
+	return router.NewStdlib(mux)
+}
+
+// ChiRouter registers the API on r through Huma's humachi adapter, so
+// middleware already added to r with Use wraps every operation.
+// SERVER_ROUTER=chi selects it with a fresh router.
+func ChiRouter(r chi.Router) Router {
+	return router.NewChi(r)
+}
+
+// LoadConfig reads and validates the configuration from the environment
+func LoadConfig() (Config, error) {
+	return config.ReadConfig()
+}
+
+// Option configures New
+type Option func(*options)
+
+type options struct {
+	repos    *Repositories
+	logger   *slog.Logger
+	basePath string
+	router   Router
+	now      func() time.Time
+}
+
+// WithRepositories serves from repos instead of the storage Config
+// selects. The caller owns them, so Shutdown does not close them.
+func WithRepositories(repos *Repositories) Option {
+	return func(o *options) {
+		o.repos = repos
+	}
+}
+
+// WithLogger sends the access log, change events and job messages to
+// logger instead of the default logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithRouter registers the API's routes on r instead of the router
+// SERVER_ROUTER selects. The handler New returns serves r wrapped in the
+// access log and client IP resolution, so serve it in r's place.
+func WithRouter(r Router) Option {
+	return func(o *options) {
+		o.router = r
+	}
+}
+
+// WithClock stamps created_at on new locations and import jobs and
+// evaluates open_now with now instead of the wall clock, for reproducible
+// responses in tests.
+// PostgreSQL storage stamps rows with database time regardless.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithBasePath serves the API under a path prefix such as "/geo". The
+// handler strips it before routing and keeps it in the OpenAPI document,
+// the docs page, the web UI and Link headers, so mount it with the
+// trailing slash: mux.Handle("/geo/", handler).
+func WithBasePath(path string) Option {
+	return func(o *options) {
+		o.basePath = strings.TrimRight(path, "/")
+	}
+}
+
+// webhookRetryBackoff is how long a queued webhook delivery waits before
+// its first retry; each later retry waits twice as long
+const webhookRetryBackoff = time.Second
+
+// New builds the repositories, services, Huma API and middleware stack for
+// cfg. It returns the handler serving every route and the App holding the
+// background components, which is not yet started. Nothing listens on a
+// port; cfg.Server's port and timeouts are left to the caller.
+func New(cfg Config, opts ...Option) (_ http.Handler, _ *App, err error) {
+	o := &options{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	logger := o.logger
+	if err := config.ValidateConfig(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	// Resolve the real client IP before anything downstream needs it
+	trustedProxies := make([]netip.Prefix, 0, len(cfg.Server.TrustedProxies))
+	for _, proxy := range cfg.Server.TrustedProxies {
+		prefix, err := config.ParseTrustedProxy(proxy)
+		if err != nil {
+			return nil, nil, err
+		}
+		trustedProxies = append(trustedProxies, prefix)
+	}
+	clientIP := middleware.NewClientIPResolver(trustedProxies)
+
+	// Components stop in reverse order: background work stops first and
+	// the repositories close last
+	application := app.New(time.Duration(cfg.Server.ShutdownTimeout) * time.Second)
+
+	repos := o.repos
+	if repos == nil {
+		var cleanup func() error
+		repos, cleanup, err = repository.NewRepositoryFromConfig(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize repository: %w", err)
+		}
+		defer func() {
+			if err != nil {
+				cleanup()
+			}
+		}()
+		logger.Info("Repository initialized", "type", cfg.Storage)
+		application.Add(app.Component{
+			Name: "repositories",
+			Stop: func(context.Context) error { return cleanup() },
+		})
+	}
+	if cfg.DemoMode {
+		target, ok := repos.Locations.(domain.MigrationTarget)
+		if !ok {
+			return nil, nil, errors.New("DEMO_MODE needs a location repository that can restore")
+		}
+		// Loaded before serving, so the first request already finds it
+		application.Add(app.Component{
+			Name: "demo-data",
+			Start: func(ctx context.Context) error {
+				result, err := demo.Load(ctx, target)
+				if err != nil {
+					return fmt.Errorf("failed to load the demo dataset: %w", err)
+				}
+				logger.Info("Demo dataset loaded", "loaded", result.Migrated, "already_present", len(result.Conflicts))
+				return nil
+			},
+		})
+	}
+
+	var cachePriming *service.CachePrimingService
+	if cfg.Cache.PrimeOnStart {
+		if repos.Cache == nil {
+			logger.Warn("CACHE_PRIME_ON_START has no effect without CACHE_TTL")
+		} else {
+			cachePriming = service.NewCachePrimingService(repos.Cache, cfg.Cache.PrimeStateFile, cfg.Cache.PrimeNames)
+			// Serving waits for priming up to the budget, so a slow or
+			// failing store cannot hold up startup
+			application.Add(app.Component{
+				Name: "cache-prime",
+				Start: func(context.Context) error {
+					cachePriming.Start(time.Duration(cfg.Cache.PrimeBudget) * time.Millisecond)
+					return nil
+				},
+				Stop: func(context.Context) error { return cachePriming.Stop() },
+			})
+		}
+	}
+
+	// Features describe themselves to clients as they are wired, from the
+	// configuration they read
+	capabilities := handlers.NewCapabilitiesHandler()
+
+	// Change events fan out through an in-process bus. With a transactional
+	// outbox the dispatcher publishes; otherwise the service publishes directly.
+	eventBus := events.NewBus()
+	eventBus.Subscribe(func(e domain.Event) error {
+		logger.Info("Location event", "type", e.Type, "event_id", e.ID, "name", e.Location.Name)
+		return nil
+	})
+
+	// Export change events to the company broker as another bus subscriber
+	var exporter *events.NATSPublisher
+	var webhookQueue *events.Queue
+	if cfg.Events.Backend == "nats" {
+		natsOpts := []nats.Option{nats.Name("leeta-location-api"), nats.MaxReconnects(-1)}
+		if cfg.Events.NATS.ConnectTimeout > 0 {
+			natsOpts = append(natsOpts, nats.Timeout(time.Duration(cfg.Events.NATS.ConnectTimeout)*time.Second))
+		}
+		exporter = events.NewNATSPublisher(cfg.Events.NATS.URL, cfg.Events.NATS.SubjectPrefix, natsOpts...)
+		eventBus.Subscribe(events.Exporter("nats", exporter))
+		capabilities.Register("events", struct {
+			Backend       string `json:"backend"`
+			SubjectPrefix string `json:"subject_prefix"`
+		}{cfg.Events.Backend, cfg.Events.NATS.SubjectPrefix})
+	}
+	if cfg.Events.Backend == "webhook" {
+		keys := make([]webhook.Key, 0, len(cfg.Events.Webhook.Keys))
+		keyIDs := make([]string, 0, len(cfg.Events.Webhook.Keys))
+		for _, key := range cfg.Events.Webhook.Keys {
+			keys = append(keys, webhook.Key{ID: key.ID, Secret: []byte(key.Secret)})
+			keyIDs = append(keyIDs, key.ID)
+		}
+		signer, err := webhook.NewSigner(keys...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid WEBHOOK_SECRETS: %w", err)
+		}
+		client := &http.Client{Timeout: time.Duration(cfg.Events.Webhook.Timeout) * time.Second}
+		deliver := events.Exporter("webhook", events.NewWebhookPublisher(cfg.Events.Webhook.URL, signer, client))
+		if repos.Outbox == nil {
+			// Without an outbox the service publishes on the request that
+			// made the change, and nothing else retries a failed delivery
+			webhookQueue = events.NewQueue(deliver, cfg.Events.Webhook.QueueSize, cfg.Events.Webhook.MaxAttempts, webhookRetryBackoff)
+			deliver = webhookQueue.Handle
+		}
+		eventBus.Subscribe(deliver)
+		capabilities.Register("events", struct {
+			Backend string   `json:"backend"`
+			KeyIDs  []string `json:"key_ids"`
+		}{cfg.Events.Backend, keyIDs})
+	}
+
+	var serviceOpts []service.LocationServiceOption
+	var dispatcher *service.OutboxDispatcher
+	var directPublisher domain.EventPublisher
+	if repos.Outbox != nil {
+		dispatcher = service.NewOutboxDispatcher(repos.Outbox, eventBus,
+			time.Duration(cfg.Outbox.PollInterval)*time.Millisecond,
+			cfg.Outbox.BatchSize,
+			cfg.Outbox.MaxAttempts,
+		)
+	} else {
+		// Without an outbox to number events, number them on the way out
+		directPublisher = events.NewSequencer(eventBus)
+		serviceOpts = append(serviceOpts, service.WithEventPublisher(directPublisher))
+	}
+
+	jobs := scheduler.New()
+
+	// Initialize service
+	serviceOpts = append(serviceOpts, service.WithCoordinatePrecision(cfg.CoordinatePrecision))
+	if cfg.Fallback.Enabled {
+		// The snapshot is searched the same way as the memory store
+		snapshot := fallback.NewSnapshot(repos.Locations, time.Duration(cfg.Fallback.MaxStaleness)*time.Second,
+			memory.WithDistanceStrategy(geospatial.DistanceStrategy(cfg.DistanceStrategy)),
+			memory.WithSphere(geospatial.NewSphere(cfg.EarthRadiusKm)),
+		)
+		refreshInterval := time.Duration(cfg.Fallback.RefreshInterval) * time.Second
+		if refreshInterval <= 0 {
+			refreshInterval = time.Minute
+		}
+		if err := jobs.Register(scheduler.Job{
+			Name:      "nearest-fallback-snapshot",
+			Interval:  refreshInterval,
+			Jitter:    refreshInterval / 10,
+			Immediate: true,
+			Run:       snapshot.Refresh,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to register job: %w", err)
+		}
+		serviceOpts = append(serviceOpts, service.WithNearestFallback(snapshot,
+			time.Duration(cfg.Fallback.LatencyBudget)*time.Millisecond))
+		capabilities.Register("nearest_fallback", cfg.Fallback)
+	}
+	blocklist, err := cfg.Names.CompileBlocklist()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compile name blocklist: %w", err)
+	}
+	serviceOpts = append(serviceOpts, service.WithNamePolicy(blocklist, cfg.Names.ReservedPrefixes),
+		service.WithUnknownHoursOpen(cfg.UnknownHoursOpen), service.WithOpenCandidates(cfg.NearestOpenCandidates), service.WithDescriptionMaxLength(cfg.Limits.MaxDescriptionLength),
+		service.WithRegions(cfg.Regions.Names, cfg.Regions.Required), service.WithSuggestDistanceWeight(cfg.SuggestDistanceWeight),
+		service.WithAttachmentPolicy(domain.AttachmentPolicy{AllowedHosts: cfg.Attachments.AllowedHosts, MaxURLLength: cfg.Attachments.MaxURLLength}))
+	if cfg.Cache.LastKnownGood > 0 {
+		serviceOpts = append(serviceOpts, service.WithListFallback(cache.NewLastKnownGood(0), time.Duration(cfg.Cache.LastKnownGood)*time.Second))
+	}
+	if cfg.Attachments.CheckReachable {
+		checker := service.NewHTTPAttachmentChecker(&http.Client{Timeout: time.Duration(cfg.Attachments.CheckTimeout) * time.Millisecond})
+		serviceOpts = append(serviceOpts, service.WithAttachmentChecker(checker))
+	}
+	if cfg.NearestScan != (config.NearestScanConfig{}) {
+		serviceOpts = append(serviceOpts, service.WithScanBudget(domain.ScanBudget{Soft: cfg.NearestScan.SoftLimit, Hard: cfg.NearestScan.HardLimit}))
+		if finder, ok := repos.Locations.(domain.BudgetedNearestFinder); ok {
+			serviceOpts = append(serviceOpts, service.WithBudgetedNearest(finder))
+		}
+	}
+	if o.now != nil {
+		serviceOpts = append(serviceOpts, service.WithClock(o.now))
+	}
+	locationService := service.NewLocationService(repos.Locations, serviceOpts...)
+	duplicateService := service.NewDuplicateService(repos.Locations, repos.Merger, directPublisher)
+	jobRepo := repos.Jobs
+	if jobRepo == nil {
+		// Hosts supplying their own repositories may have no job store;
+		// imports then last as long as the process
+		jobRepo = memory.NewInMemoryJobRepository()
+	}
+	var importOpts []service.ImportServiceOption
+	if o.now != nil {
+		importOpts = append(importOpts, service.WithImportClock(o.now))
+	}
+	importService := service.NewImportService(jobRepo, locationService, cfg.Imports.BatchSize,
+		time.Duration(cfg.Imports.StaleAfter)*time.Second, importOpts...)
+
+	quotas := service.UsageQuotas{Default: int64(cfg.Usage.MonthlyQuota), PerKey: map[string]int64{}}
+	for key, quota := range cfg.Usage.Quotas {
+		quotas.PerKey[key] = int64(quota)
+	}
+	flushInterval := time.Duration(cfg.Usage.FlushInterval) * time.Second
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+	usageService := service.NewUsageService(repos.Usage, quotas, flushInterval)
+	integrityService := service.NewIntegrityService(repos.Integrity, cfg.Limits.DefaultBatchSize,
+		service.WithIntegrityEventPublisher(directPublisher))
+	settingsService := service.NewSettingsService(repos.Settings, domain.SearchSettings{
+		SpeedKmh:      cfg.DefaultSpeedKmh,
+		MaxDistanceKm: cfg.NearestMaxDistanceKm,
+	})
+
+	// Initialize handlers
+	locationHandler := handlers.NewLocationHandler(locationService,
+		handlers.WithExternalBaseURL(cfg.Server.ExternalBaseURL),
+		handlers.WithLimits(cfg.Limits),
+		handlers.WithSphere(geospatial.NewSphere(cfg.EarthRadiusKm)),
+		handlers.WithSearchSettings(settingsService),
+		handlers.WithStrictBodies(cfg.StrictBodies),
+		handlers.WithConditionalWrites(cfg.RequireConditionalWrites),
+		handlers.WithOwnershipEnforcement(cfg.OwnershipEnforcement),
+		handlers.WithCoordinatePrecision(cfg.CoordinatePrecision),
+	)
+	healthOpts := []handlers.HealthHandlerOption{handlers.WithJobStatus(jobs)}
+	if cfg.Metrics.Enabled && repos.Stats != nil {
+		healthOpts = append(healthOpts, handlers.WithStoreStats(repos.Stats))
+	}
+	if cachePriming != nil {
+		healthOpts = append(healthOpts, handlers.WithCachePriming(cachePriming))
+	}
+	if repos.Pinger != nil {
+		readinessCfg := cfg.Readiness
+		if readinessCfg.FailureThreshold <= 0 {
+			readinessCfg.FailureThreshold = 3
+		}
+		if readinessCfg.SuccessThreshold <= 0 {
+			readinessCfg.SuccessThreshold = 2
+		}
+		if readinessCfg.CheckTimeout <= 0 {
+			readinessCfg.CheckTimeout = 1000
+		}
+		checkInterval := time.Duration(readinessCfg.CheckInterval) * time.Second
+		if checkInterval <= 0 {
+			checkInterval = 5 * time.Second
+		}
+		readiness := service.NewReadinessService(repos.Pinger, readinessCfg.FailureThreshold,
+			readinessCfg.SuccessThreshold, time.Duration(readinessCfg.CheckTimeout)*time.Millisecond)
+		// Probes read the outcome of these checks, so however often they
+		// come the database is pinged once per interval
+		if err := jobs.Register(scheduler.Job{
+			Name:     "readiness-check",
+			Interval: checkInterval,
+			Run:      readiness.Check,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to register job: %w", err)
+		}
+		healthOpts = append(healthOpts, handlers.WithReadiness(readiness))
+	}
+	healthHandler := handlers.NewHealthHandler(healthOpts...)
+	usageHandler := handlers.NewUsageHandler(usageService)
+
+	limits := cfg.Limits
+	if limits == (config.LimitsConfig{}) {
+		limits = config.DefaultLimits()
+	}
+	capabilities.Register("limits", limits)
+	capabilities.Register("distance_units", struct {
+		Default   string   `json:"default"`
+		Available []string `json:"available"`
+	}{dto.DistanceUnit(dto.WithDefaultUnit(context.Background(), cfg.DistanceUnit), ""), dto.DistanceUnits})
+	if cfg.Auth.Mode != "" && cfg.Auth.Mode != "none" {
+		capabilities.Register("auth", struct {
+			Mode string `json:"mode"`
+		}{cfg.Auth.Mode})
+	}
+	capabilities.Register("conditional_writes", struct {
+		Required bool `json:"required"`
+	}{cfg.RequireConditionalWrites})
+	if cfg.StrictBodies {
+		capabilities.Register("strict_bodies", struct{}{})
+	}
+	if cfg.OwnershipEnforcement && cfg.Auth.Mode != "" && cfg.Auth.Mode != "none" {
+		capabilities.Register("ownership_enforcement", struct{}{})
+	}
+	if cfg.Privacy.Mode != "" && cfg.Privacy.Mode != "off" {
+		capabilities.Register("coordinate_privacy", cfg.Privacy)
+	}
+	if cfg.ExternalIDs.Active() {
+		capabilities.Register("external_ids", cfg.ExternalIDs)
+	}
+	if len(cfg.Regions.Names) > 0 || cfg.Regions.Required {
+		capabilities.Register("regions", cfg.Regions)
+	}
+	capabilities.Register("attachments", cfg.Attachments)
+	capabilities.Register("imports", cfg.Imports)
+	capabilities.Register("export", struct {
+		SnapshotVersion int `json:"snapshot_version"`
+	}{dto.SnapshotVersion})
+	if cfg.Docs.Enabled || cfg.Docs.OpenAPIEnabled {
+		capabilities.Register("docs", cfg.Docs)
+	}
+	if cfg.UI.Enabled {
+		capabilities.Register("ui", cfg.UI)
+	}
+
+	routesOn := o.router
+	if routesOn == nil {
+		if routesOn, err = router.New(cfg.Server.Router); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Create Huma API configuration
+	humaConfig := huma.DefaultConfig("Leeta Location API", "1.0.0")
+	humaConfig.Info.Description = cfg.Docs.Description
+	if cfg.Docs.ContactName != "" || cfg.Docs.ContactEmail != "" {
+		humaConfig.Info.Contact = &huma.Contact{Name: cfg.Docs.ContactName, Email: cfg.Docs.ContactEmail}
+	}
+	humaConfig.Servers = nil
+	switch {
+	case cfg.Docs.PublicURL != "":
+		humaConfig.Servers = append(humaConfig.Servers, &huma.Server{URL: strings.TrimRight(cfg.Docs.PublicURL, "/") + o.basePath})
+	case o.basePath != "":
+		// Huma prefixes the docs page's OpenAPI link with the server path
+		humaConfig.Servers = append(humaConfig.Servers, &huma.Server{URL: o.basePath})
+	}
+	if cfg.DevMode {
+		humaConfig.Servers = append(humaConfig.Servers,
+			&huma.Server{URL: fmt.Sprintf("http://localhost:%d", cfg.Server.Port) + o.basePath, Description: "Development server"})
+	}
+	if !cfg.Docs.Enabled {
+		humaConfig.DocsPath = ""
+	}
+	if !cfg.Docs.OpenAPIEnabled {
+		humaConfig.OpenAPIPath = ""
+	}
+	// Serialization is timed from the first transformer on
+	if cfg.Server.ServerTiming {
+		humaConfig.Transformers = append([]huma.Transformer{middleware.MarkServerTiming}, humaConfig.Transformers...)
+	}
+	// Location coordinates are rounded to the configured precision, then
+	// obscured for callers CoordinatePrivacy marks
+	humaConfig.Transformers = append(humaConfig.Transformers, middleware.RoundCoordinates, middleware.ObscureCoordinates)
+	// IDs are encoded after, as the jitter is keyed by the real ones
+	humaConfig.Transformers = append(humaConfig.Transformers, middleware.EncodeIDs)
+
+	// Create the Huma API with the router's adapter
+	api := routesOn.API(humaConfig)
+
+	// Server-Timing goes first so its total covers every other middleware
+	if cfg.Server.ServerTiming {
+		api.UseMiddleware(middleware.ServerTiming)
+	}
+
+	// Cache-Control goes outermost so errors from every later middleware
+	// are marked no-store too
+	cachePolicies, err := cfg.CacheControl.Compile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cache policies: %w", err)
+	}
+	// Coordinates depend on the caller's scope under privacy, and
+	// credentials pick the profile and unit, so answers are per caller
+	privacy := dto.CoordinatePrivacy{Mode: cfg.Privacy.Mode, Meters: cfg.Privacy.Meters}
+	perCaller := privacy.Enabled() || (cfg.Auth.Mode != "" && cfg.Auth.Mode != "none")
+	api.UseMiddleware(middleware.CacheControl(cachePolicies, perCaller))
+
+	// Negotiate the response language first so every error can be localized
+	api.UseMiddleware(i18n.Middleware)
+	api.UseMiddleware(middleware.Telemetry)
+
+	// Authentication must be installed before routes are registered
+	authn := newAuthenticator(cfg.Auth)
+	api.UseMiddleware(auth.Middleware(authn))
+	// Clients are rate limited by key, so it must be known, and before usage
+	// accounting so refused requests do not count against the quota
+	rateLimits, err := cfg.RateLimits.Compile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid rate limits: %w", err)
+	}
+	if len(rateLimits.Classes) > 0 {
+		api.UseMiddleware(middleware.NewClassRateLimiter(rateLimits).Middleware)
+		capabilities.Register("rate_limits", rateLimits)
+	}
+	// Deprecated operations are counted by caller, so it must be known
+	deprecations, err := cfg.Deprecations.Compile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid deprecations: %w", err)
+	}
+	api.UseMiddleware(middleware.Deprecation(deprecations))
+	api.UseMiddleware(middleware.ResponseFormat(cfg.CoordinatePrecision, cfg.DistanceUnit))
+	api.UseMiddleware(middleware.PreferredUnit)
+	api.UseMiddleware(middleware.CoordinatePrivacy(dto.CoordinatePrivacy{
+		Mode:   cfg.Privacy.Mode,
+		Meters: cfg.Privacy.Meters,
+		Secret: []byte(cfg.Privacy.Secret),
+	}))
+	var externalIDs *dto.ExternalIDs
+	if cfg.ExternalIDs.Active() {
+		externalIDs = dto.NewExternalIDs([]byte(cfg.ExternalIDs.Key))
+	}
+	api.UseMiddleware(middleware.ExternalIDs(externalIDs))
+	api.UseMiddleware(usageHandler.Middleware)
+
+	// Disabled operations answer 403 and are left out of the OpenAPI document;
+	// deprecated ones are marked in it
+	filtered := handlers.DisableOperations(api, cfg.Server.EndpointsDisabled)
+	filtered = handlers.DeprecateOperations(filtered, slices.Collect(maps.Keys(deprecations)))
+	// Without authentication anyone could call the admin operations, so
+	// they are not registered and the features only they offer are off
+	admin := authn != nil
+	if !admin {
+		logger.Warn("AUTH_MODE is none; admin endpoints are not registered")
+		filtered = handlers.OmitAdminOperations(filtered)
+	}
+
+	// Register all routes with Huma
+	var routes handlers.Routes
+	routes.Add(
+		healthHandler,
+		locationHandler,
+		usageHandler,
+		handlers.NewSpatialHandler(repos.Spatial, cfg.Limits),
+		handlers.NewDuplicateHandler(duplicateService),
+		handlers.NewImportHandler(importService, cfg.Imports),
+		handlers.NewBackupHandler(repos.Locations, repos.Restorer),
+		handlers.NewIntegrityHandler(integrityService),
+		handlers.NewSettingsHandler(settingsService),
+	)
+	if cfg.Sync.Enabled && len(cfg.Sync.AllowedSources) == 0 {
+		logger.Warn("SYNC_ENABLED has no effect without SYNC_ALLOWED_SOURCES; POST /admin/sync is not registered")
+	} else if cfg.Sync.Enabled && admin {
+		syncService := service.NewSyncService(locationService)
+		routes.Add(handlers.NewSyncHandler(syncService, cfg.Sync))
+		capabilities.Register("sync", cfg.Sync)
+	}
+	if repos.Faults != nil && admin {
+		logger.Warn("Fault injection is enabled; admins can make location requests fail through /admin/faults")
+		routes.Add(handlers.NewFaultHandler(repos.Faults))
+		capabilities.Register("fault_injection", cfg.FaultInjection)
+	}
+	if repos.Timeouts != nil {
+		capabilities.Register("adaptive_timeouts", cfg.AdaptiveTimeout)
+	}
+	if cfg.EnvironmentName != "" && repos.Truncator != nil && admin {
+		routes.Add(handlers.NewTruncateHandler(repos.Locations, repos.Truncator, directPublisher, cfg.EnvironmentName))
+		capabilities.Register("truncate", struct{}{})
+	}
+	if repos.Outbox != nil {
+		routes.Add(handlers.NewOutboxHandler(repos.Outbox))
+	}
+	if repos.Events != nil && admin {
+		routes.Add(handlers.NewAuditHandler(repos.Events, cfg.Limits))
+		capabilities.Register("audit_export", struct {
+			MaxRows int `json:"max_rows"`
+		}{limits.MaxExportRows})
+	}
+	routes.Add(
+		handlers.NewChangeHandler(repos.Changes, cfg.Limits),
+		handlers.NewQueryHandler(repos.Queries, locationService, cfg.Limits, cfg.Server.ExternalBaseURL),
+		capabilities,
+	)
+	routes.RegisterAll(filtered)
+	if repos.ChangeCompactor != nil {
+		compactInterval := time.Duration(cfg.Changes.CompactInterval) * time.Second
+		if compactInterval <= 0 {
+			compactInterval = 5 * time.Minute
+		}
+		if err := jobs.Register(scheduler.Job{
+			Name:     "change-log-compaction",
+			Interval: compactInterval,
+			Jitter:   compactInterval / 10,
+			Run: func(ctx context.Context) error {
+				dropped, err := repos.ChangeCompactor.CompactChanges(cfg.Changes.Retain)
+				if dropped > 0 {
+					logger.Info("Compacted change log", "dropped", dropped)
+				}
+				return err
+			},
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to register job: %w", err)
+		}
+	}
+
+	if repos.StoreCompactor != nil && cfg.Compaction.Interval > 0 {
+		compactInterval := time.Duration(cfg.Compaction.Interval) * time.Second
+		if err := jobs.Register(scheduler.Job{
+			Name:     "memory-store-compaction",
+			Interval: compactInterval,
+			Jitter:   compactInterval / 10,
+			Run: func(ctx context.Context) error {
+				result, err := repos.StoreCompactor.CompactStore(cfg.Compaction.MinRemovedRatio)
+				if result.Compacted {
+					logger.Info("Compacted memory store", "reclaimed", result.Reclaimed, "duration", result.Duration)
+				}
+				return err
+			},
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to register job: %w", err)
+		}
+	}
+
+	if repos.Replica != nil {
+		checkInterval := time.Duration(cfg.Database.Replica.CheckInterval) * time.Second
+		if checkInterval <= 0 {
+			checkInterval = 5 * time.Second
+		}
+		if err := jobs.Register(scheduler.Job{
+			Name:      "replica-lag-check",
+			Interval:  checkInterval,
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				_, err := repos.Replica.CheckReplica(ctx)
+				return err
+			},
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to register job: %w", err)
+		}
+	}
+
+	uiConfig := cfg.UI
+	if uiConfig.APIBasePath == "" {
+		uiConfig.APIBasePath = o.basePath
+	}
+	ui.Mount(routesOn, uiConfig)
+
+	if cfg.Metrics.Enabled {
+		routesOn.Handle("/metrics", metrics.Handler())
+		if repos.Stats != nil {
+			metrics.RegisterStore(repos.Stats)
+		}
+		if repos.Cache != nil {
+			metrics.RegisterCache(repos.Cache)
+		}
+		if repos.Timeouts != nil {
+			metrics.RegisterTimeouts(repos.Timeouts)
+		}
+		statsCollector := service.NewStatsCollector(repos.Locations)
+		statsInterval := time.Duration(cfg.Metrics.StatsInterval) * time.Second
+		if statsInterval <= 0 {
+			statsInterval = time.Minute
+		}
+		if err := jobs.Register(scheduler.Job{
+			Name:      "location-stats",
+			Interval:  statsInterval,
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				return statsCollector.Collect()
+			},
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to register job: %w", err)
+		}
+	}
+
+	switch cfg.Auth.Mode {
+	case "apikey":
+		auth.DocumentSecurity(api, "apiKey", auth.APIKeySecurityScheme())
+	case "jwt":
+		auth.DocumentSecurity(api, "bearer", auth.BearerSecurityScheme())
+	}
+
+	if exporter != nil {
+		// Connected before anything can publish, drained after the last publisher stops
+		application.Add(app.Component{
+			Name:  "event-exporter",
+			Start: func(context.Context) error { return exporter.Connect() },
+			Stop:  exporter.Close,
+		})
+	}
+	if webhookQueue != nil {
+		// Drained after the last publisher stops
+		application.Add(app.Component{
+			Name: "webhook-queue",
+			Start: func(context.Context) error {
+				webhookQueue.Start()
+				return nil
+			},
+			Stop: webhookQueue.Close,
+		})
+	}
+	application.Add(app.Component{
+		Name: "usage",
+		Start: func(context.Context) error {
+			usageService.Start()
+			return nil
+		},
+		// Persist buffered usage counters before the database goes away
+		Stop: func(context.Context) error { return usageService.Stop() },
+	})
+	if dispatcher != nil {
+		application.Add(app.Component{
+			Name: "outbox-dispatcher",
+			Start: func(context.Context) error {
+				dispatcher.Start()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				dispatcher.Stop()
+				return nil
+			},
+		})
+	}
+	application.Add(app.Component{
+		Name: "import-worker",
+		Start: func(context.Context) error {
+			importService.Start()
+			return nil
+		},
+		// A job still running is marked failed after its current batch
+		Stop: importService.Stop,
+	})
+	application.Add(app.Component{
+		Name: "scheduler",
+		Start: func(ctx context.Context) error {
+			jobs.Start(ctx)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			jobs.Stop()
+			return nil
+		},
+	})
+	application.Add(app.Component{
+		Name: "integrity-check",
+		// The scan runs in the background and never delays serving
+		Start: func(context.Context) error {
+			if cfg.Integrity.CheckOnStart {
+				return integrityService.Start(cfg.Integrity.Fix)
+			}
+			return nil
+		},
+		Stop: func(context.Context) error {
+			integrityService.Stop()
+			return nil
+		},
+	})
+
+	var routed http.Handler = routesOn
+	if cfg.Docs.RateLimit > 0 {
+		// The documentation is served outside the API's operations, so it
+		// is limited before routing, with a bucket of its own
+		routed = middleware.NewRateLimiter(cfg.Docs.RateLimit).Limit(isDocsRequest, routesOn)
+	}
+	handler := clientIP.Middleware(middleware.AccessLogTo(logger, handlers.StripBasePath(o.basePath, routed)))
+	return handler, application, nil
+}
+
+// isDocsRequest reports whether r is for the documentation page or the
+// OpenAPI document, in any of its formats
+func isDocsRequest(r *http.Request) bool {
+	return r.URL.Path == "/docs" || strings.HasPrefix(r.URL.Path, "/openapi")
+}
+
+// newAuthenticator builds the authenticator for the configured auth mode,
+// returning nil when authentication is disabled
+func newAuthenticator(cfg config.AuthConfig) auth.Authenticator {
+	switch cfg.Mode {
+	case "apikey":
+		keys := make([]auth.APIKey, 0, len(cfg.APIKeys))
+		for _, k := range cfg.APIKeys {
+			scopes := make([]auth.Scope, 0, len(k.Scopes))
+			for _, s := range k.Scopes {
+				scopes = append(scopes, auth.Scope(s))
+			}
+			keys = append(keys, auth.APIKey{Name: k.Name, Key: k.Key, Tenant: k.Tenant, Scopes: scopes, Profile: profile(cfg.Profiles[k.Name])})
+		}
+		return auth.NewAPIKeyAuthenticator(keys)
+	case "jwt":
+		profiles := make(map[string]auth.Profile, len(cfg.Profiles))
+		for tenant, p := range cfg.Profiles {
+			profiles[tenant] = profile(p)
+		}
+		jwks := auth.NewJWKSCache(cfg.JWT.JWKSURL, time.Duration(cfg.JWT.JWKSRefresh)*time.Second)
+		return auth.NewJWTAuthenticator(jwks, auth.JWTConfig{
+			Issuer:      cfg.JWT.Issuer,
+			Audience:    cfg.JWT.Audience,
+			ScopesClaim: cfg.JWT.ScopesClaim,
+			TenantClaim: cfg.JWT.TenantClaim,
+			ClockSkew:   time.Duration(cfg.JWT.ClockSkew) * time.Second,
+			Profiles:    profiles,
+		})
+	default:
+		return nil
+	}
+}
+
+// profile converts a configured caller profile
+func profile(cfg config.ProfileConfig) auth.Profile {
+	return auth.Profile{Unit: cfg.Unit}
+}
@@ -0,0 +1,632 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/demo"
+	"github.com/jesuloba-world/leeta-task/internal/domain"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/pkg/server"
+)
+
+func loadConfig(t *testing.T) server.Config {
+	t.Helper()
+	t.Setenv("STORAGE_TYPE", "memory")
+	t.Setenv("AUTH_MODE", "none")
+	cfg, err := server.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func startApp(t *testing.T, app *server.App) {
+	t.Helper()
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := app.Shutdown(context.Background()); err != nil {
+			t.Errorf("Failed to shut down: %v", err)
+		}
+	})
+}
+
+func TestEmbeddedUnderSubPath(t *testing.T) {
+	handler, app, err := server.New(loadConfig(t), server.WithBasePath("/geo/"), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+
+	parent := http.NewServeMux()
+	parent.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("parent")) })
+	parent.Handle("/geo/", handler)
+	srv := httptest.NewServer(parent)
+	defer srv.Close()
+
+	for _, body := range []string{
+		`{"name":"Leeta Yaba","latitude":6.5095,"longitude":3.3711}`,
+		`{"name":"Leeta Ikeja","latitude":6.6018,"longitude":3.3515}`,
+	} {
+		resp, err := http.Post(srv.URL+"/geo/locations", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/geo/nearest?lat=6.51&lng=3.37")
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	var nearest struct {
+		Location struct {
+			Name string `json:"name"`
+		} `json:"location"`
+	}
+	json.NewDecoder(resp.Body).Decode(&nearest)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || nearest.Location.Name != "Leeta Yaba" {
+		t.Errorf("Expected Leeta Yaba nearest, got %d %+v", resp.StatusCode, nearest)
+	}
+
+	// Links point back under the mount point
+	resp, err = http.Get(srv.URL + "/geo/locations?page=1&page_size=1")
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	resp.Body.Close()
+	if link := resp.Header.Get("Link"); !strings.Contains(link, "/geo/locations?page=2&page_size=1") {
+		t.Errorf("Expected a next link under /geo, got %q", link)
+	}
+
+	resp, err = http.Get(srv.URL + "/geo/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch the document: %v", err)
+	}
+	var doc struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+	json.NewDecoder(resp.Body).Decode(&doc)
+	resp.Body.Close()
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "/geo" {
+		t.Errorf("Expected the document to name the /geo server, got %+v", doc.Servers)
+	}
+
+	// The parent keeps its own routes, and the API's are only under /geo
+	resp, _ = http.Get(srv.URL + "/healthz")
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(got) != "parent" {
+		t.Errorf("Expected the parent's own handler, got %q", got)
+	}
+	resp, _ = http.Get(srv.URL + "/locations")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected no API outside the mount point, got %d", resp.StatusCode)
+	}
+}
+
+func TestEmbeddedWithRepositories(t *testing.T) {
+	locations := memory.NewInMemoryLocationRepository()
+	yaba, _ := domain.NewLocation("Leeta Yaba", 6.5095, 3.3711)
+	if err := locations.Save(yaba); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	repos := &server.Repositories{
+		Locations: locations,
+		Usage:     memory.NewInMemoryUsageRepository(),
+		Queries:   memory.NewInMemoryQueryRepository(),
+		Settings:  memory.NewInMemorySettingsRepository(),
+		Changes:   locations,
+		Spatial:   locations,
+		Merger:    locations,
+		Restorer:  locations,
+		Integrity: locations,
+	}
+	var logs bytes.Buffer
+	handler, app, err := server.New(loadConfig(t), server.WithRepositories(repos), server.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/locations?name_contains=yaba", nil))
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "Leeta Yaba") {
+		t.Errorf("Expected the seeded location, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if !strings.Contains(logs.String(), `"msg":"Request completed"`) {
+		t.Errorf("Expected the access log on the given logger, got %q", logs.String())
+	}
+}
+
+// TestServersKeepTheirResponseFormat builds two servers on one store in
+// one process; each rounds coordinates and picks a distance unit by its
+// own configuration
+func TestServersKeepTheirResponseFormat(t *testing.T) {
+	locations := memory.NewInMemoryLocationRepository()
+	ikeja, _ := domain.NewLocation("Leeta Ikeja", 6.601812, 3.351534)
+	if err := locations.Save(ikeja); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	build := func(precision, unit string) http.Handler {
+		t.Setenv("COORDINATE_PRECISION", precision)
+		t.Setenv("DISTANCE_UNIT", unit)
+		handler, app, err := server.New(loadConfig(t), server.WithRepositories(&server.Repositories{
+			Locations: locations,
+			Usage:     memory.NewInMemoryUsageRepository(),
+			Queries:   memory.NewInMemoryQueryRepository(),
+			Settings:  memory.NewInMemorySettingsRepository(),
+		}), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		if err != nil {
+			t.Fatalf("Failed to build: %v", err)
+		}
+		startApp(t, app)
+		return handler
+	}
+	coarse := build("4", "mi")
+	fine := build("6", "km")
+
+	for _, tt := range []struct {
+		handler  http.Handler
+		latitude float64
+		unit     string
+	}{
+		{coarse, 6.6018, "mi"},
+		{fine, 6.601812, "km"},
+		{coarse, 6.6018, "mi"},
+	} {
+		resp := httptest.NewRecorder()
+		tt.handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/nearest?lat=6.5&lng=3.3", nil))
+		var nearest struct {
+			Location struct {
+				Latitude float64 `json:"latitude"`
+			} `json:"location"`
+			Unit string `json:"unit"`
+		}
+		json.Unmarshal(resp.Body.Bytes(), &nearest)
+		if resp.Code != http.StatusOK || nearest.Location.Latitude != tt.latitude || nearest.Unit != tt.unit {
+			t.Errorf("Expected latitude %v in %s, got %d: %s", tt.latitude, tt.unit, resp.Code, resp.Body.String())
+		}
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.Storage = "sqlite"
+	if _, _, err := server.New(cfg); err == nil {
+		t.Error("Expected an invalid storage type to fail")
+	}
+}
+
+func TestStoreStatsReported(t *testing.T) {
+	handler, app, err := server.New(loadConfig(t), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+
+	for _, body := range []string{
+		`{"name":"Leeta Yaba","latitude":6.5095,"longitude":3.3711}`,
+		`{"name":"Leeta Ikeja","latitude":6.6018,"longitude":3.3515}`,
+	} {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/locations", strings.NewReader(body)))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, resp.Code, resp.Body.String())
+		}
+	}
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		"memory_store_locations 2",
+		`memory_store_operation_duration_seconds_count{operation="save"} 2`,
+		`memory_store_index_bucket_size_count{index="region"} 0`,
+	} {
+		if !strings.Contains(resp.Body.String(), want) {
+			t.Errorf("Expected %q in the metrics", want)
+		}
+	}
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/health?verbose=true", nil))
+	var health struct {
+		Store *struct {
+			Locations      int   `json:"locations"`
+			EstimatedBytes int64 `json:"estimated_bytes"`
+			Saves          int   `json:"saves"`
+		} `json:"store"`
+	}
+	json.NewDecoder(resp.Body).Decode(&health)
+	if health.Store == nil || health.Store.Locations != 2 || health.Store.Saves != 2 || health.Store.EstimatedBytes <= 0 {
+		t.Errorf("Expected the store summary in the verbose health check, got %+v", health.Store)
+	}
+}
+
+func TestDemoMode(t *testing.T) {
+	t.Setenv("DEMO_MODE", "true")
+	cfg := loadConfig(t)
+	locations := memory.NewInMemoryLocationRepository()
+	repos := &server.Repositories{
+		Locations: locations,
+		Usage:     memory.NewInMemoryUsageRepository(),
+		Queries:   memory.NewInMemoryQueryRepository(),
+		Settings:  memory.NewInMemorySettingsRepository(),
+		Changes:   locations,
+		Spatial:   locations,
+		Merger:    locations,
+		Restorer:  locations,
+		Integrity: locations,
+	}
+	cities, _ := demo.Cities()
+
+	// Starting twice over the same store, as after a restart, loads once
+	var handler http.Handler
+	for range 2 {
+		h, app, err := server.New(cfg, server.WithRepositories(repos), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		if err != nil {
+			t.Fatalf("Failed to build: %v", err)
+		}
+		startApp(t, app)
+		handler = h
+		if count, _ := locations.Count(); count != len(cities) {
+			t.Fatalf("Expected %d demo locations, got %d", len(cities), count)
+		}
+	}
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/nearest?lat=6.5&lng=3.4", nil))
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "Lagos, Nigeria") {
+		t.Errorf("Expected Lagos nearest, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestCachePrimedOnStart(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "cache-prime.json")
+	t.Setenv("DEMO_MODE", "true")
+	t.Setenv("CACHE_TTL", "60")
+	t.Setenv("CACHE_PRIME_ON_START", "true")
+	t.Setenv("CACHE_PRIME_BUDGET_MS", "5000")
+	t.Setenv("CACHE_PRIME_STATE_FILE", stateFile)
+	cfg := loadConfig(t)
+	cities, _ := demo.Cities()
+
+	handler, app, err := server.New(cfg, server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/health?verbose=true", nil))
+	var health struct {
+		Cache *struct {
+			State     string `json:"state"`
+			Locations int    `json:"locations"`
+		} `json:"cache_priming"`
+	}
+	json.NewDecoder(resp.Body).Decode(&health)
+	if health.Cache == nil || health.Cache.State != "primed" || health.Cache.Locations != len(cities) {
+		t.Errorf("Expected the demo dataset primed, got %s", resp.Body.String())
+	}
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/locations/"+url.PathEscape(cities[0].Name), nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	data, err := os.ReadFile(stateFile)
+	if err != nil || !strings.Contains(string(data), cities[0].Name) {
+		t.Errorf("Expected the name read saved for the next run, got %s, %v", data, err)
+	}
+}
+
+func TestDocsConfigured(t *testing.T) {
+	t.Setenv("API_CONTACT_NAME", "Leeta Platform")
+	t.Setenv("API_CONTACT_EMAIL", "platform@leeta.ng")
+	t.Setenv("API_DESCRIPTION", "Leeta station locations")
+	t.Setenv("API_PUBLIC_URL", "https://geo.leeta.ng/")
+	t.Setenv("DOCS_ENABLED", "false")
+	t.Setenv("DOCS_RATE_LIMIT", "3")
+	handler, app, err := server.New(loadConfig(t), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+	get := func(path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		return resp
+	}
+
+	resp := get("/openapi.json")
+	var doc struct {
+		Info struct {
+			Description string `json:"description"`
+			Contact     struct {
+				Name  string `json:"name"`
+				Email string `json:"email"`
+			} `json:"contact"`
+		} `json:"info"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+	json.NewDecoder(resp.Body).Decode(&doc)
+	if resp.Code != http.StatusOK || doc.Info.Description != "Leeta station locations" ||
+		doc.Info.Contact.Name != "Leeta Platform" || doc.Info.Contact.Email != "platform@leeta.ng" {
+		t.Errorf("Expected the configured description and contact, got %d %+v", resp.Code, doc.Info)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "https://geo.leeta.ng" {
+		t.Errorf("Expected only the public server outside dev mode, got %+v", doc.Servers)
+	}
+	if resp := get("/docs"); resp.Code != http.StatusNotFound {
+		t.Errorf("Expected the docs page disabled, got %d", resp.Code)
+	}
+
+	// The documentation paths share a bucket, which the API does not use
+	if resp := get("/openapi.yaml"); resp.Code != http.StatusOK {
+		t.Errorf("Expected the third request served, got %d", resp.Code)
+	}
+	if resp := get("/openapi.json"); resp.Code != http.StatusTooManyRequests || resp.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 429 with Retry-After past the limit, got %d %q", resp.Code, resp.Header().Get("Retry-After"))
+	}
+	if resp := get("/locations"); resp.Code != http.StatusOK {
+		t.Errorf("Expected the API unaffected, got %d", resp.Code)
+	}
+}
+
+func TestDocsDefaults(t *testing.T) {
+	t.Setenv("DEV_MODE", "true")
+	t.Setenv("OPENAPI_ENABLED", "false")
+	if _, err := server.LoadConfig(); err == nil {
+		t.Errorf("Expected the docs page refused without the document")
+	}
+	t.Setenv("DOCS_ENABLED", "false")
+	handler, app, err := server.New(loadConfig(t), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("Expected the document disabled, got %d", resp.Code)
+	}
+
+	t.Setenv("OPENAPI_ENABLED", "true")
+	t.Setenv("DOCS_ENABLED", "true")
+	handler, app, err = server.New(loadConfig(t), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc struct {
+		Info struct {
+			Contact *struct{} `json:"contact"`
+		} `json:"info"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+	json.NewDecoder(resp.Body).Decode(&doc)
+	if doc.Info.Contact != nil {
+		t.Errorf("Expected no contact by default")
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "http://localhost:8080" {
+		t.Errorf("Expected the localhost server in dev mode, got %+v", doc.Servers)
+	}
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if resp.Code != http.StatusOK {
+		t.Errorf("Expected the docs page served, got %d", resp.Code)
+	}
+}
+
+func TestCapabilitiesFollowConfig(t *testing.T) {
+	authenticated := true
+	capabilities := func() (map[string]json.RawMessage, *httptest.ResponseRecorder) {
+		t.Helper()
+		cfg := loadConfig(t)
+		// Admin features need authentication
+		if authenticated {
+			cfg.Auth.Mode = "apikey"
+			cfg.Auth.APIKeys = []config.APIKeyConfig{{Name: "ops", Key: "ops-key", Scopes: []string{"read", "admin"}}}
+		}
+		handler, app, err := server.New(cfg, server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		if err != nil {
+			t.Fatalf("Failed to build: %v", err)
+		}
+		startApp(t, app)
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/capabilities", nil)
+		req.Header.Set("X-API-Key", "ops-key")
+		handler.ServeHTTP(resp, req)
+		var doc struct {
+			SchemaVersion int                        `json:"schema_version"`
+			Features      map[string]json.RawMessage `json:"features"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &doc); err != nil || doc.SchemaVersion != 1 {
+			t.Fatalf("Expected a version 1 document, got %d %s", resp.Code, resp.Body.String())
+		}
+		return doc.Features, resp
+	}
+
+	t.Setenv("LIMITS_MAX_PAGE_SIZE", "250")
+	features, resp := capabilities()
+	if got := resp.Header().Get("Cache-Control"); got != "private, max-age=86400" {
+		t.Errorf("Expected a long max-age, got %q", got)
+	}
+	for _, name := range []string{"sync", "fault_injection"} {
+		if _, ok := features[name]; ok {
+			t.Errorf("Expected %s left out while disabled, got %s", name, features[name])
+		}
+	}
+	var limits struct {
+		MaxPageSize int `json:"max_page_size"`
+	}
+	if err := json.Unmarshal(features["limits"], &limits); err != nil || limits.MaxPageSize != 250 {
+		t.Errorf("Expected the configured page size limit, got %s", features["limits"])
+	}
+	etag := resp.Header().Get("ETag")
+
+	t.Setenv("SYNC_ENABLED", "true")
+	t.Setenv("SYNC_ALLOWED_SOURCES", "https://locations.leeta.ng")
+	t.Setenv("SYNC_TIMEOUT_SECONDS", "45")
+	t.Setenv("FAULT_INJECTION_ENABLED", "true")
+	features, resp = capabilities()
+	var sync struct {
+		Timeout int `json:"timeout"`
+	}
+	if err := json.Unmarshal(features["sync"], &sync); err != nil || sync.Timeout != 45 {
+		t.Errorf("Expected the sync settings, got %s", features["sync"])
+	}
+	if _, ok := features["fault_injection"]; !ok {
+		t.Error("Expected fault injection listed once enabled")
+	}
+	if resp.Header().Get("ETag") == etag {
+		t.Error("Expected the ETag to change with the document")
+	}
+
+	authenticated = false
+	features, _ = capabilities()
+	for _, name := range []string{"sync", "fault_injection"} {
+		if _, ok := features[name]; ok {
+			t.Errorf("Expected %s left out without authentication, got %s", name, features[name])
+		}
+	}
+}
+
+func TestAdminRoutesNeedAuthentication(t *testing.T) {
+	handler, app, err := server.New(loadConfig(t), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+
+	for _, path := range []string{"/admin/export", "/admin/integrity-report"} {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("Expected %s not served without authentication, got %d", path, resp.Code)
+		}
+	}
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/locations", nil))
+	if resp.Code != http.StatusOK {
+		t.Errorf("Expected other routes served, got %d", resp.Code)
+	}
+}
+
+// TestPreferredUnitsStayPrivate keeps a shared cache from serving one
+// key's preferred unit to another
+func TestPreferredUnitsStayPrivate(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.Auth.Mode = "apikey"
+	cfg.Auth.APIKeys = []config.APIKeyConfig{
+		{Name: "us-partner", Key: "us-key", Scopes: []string{"read", "write"}},
+		{Name: "fleet", Key: "fleet-key", Scopes: []string{"read", "write"}},
+	}
+	cfg.Auth.Profiles = map[string]config.ProfileConfig{"us-partner": {Unit: "mi"}}
+	handler, app, err := server.New(cfg, server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	startApp(t, app)
+
+	create := httptest.NewRequest(http.MethodPost, "/locations", strings.NewReader(`{"name":"Ikeja","latitude":6.6018,"longitude":3.3515}`))
+	create.Header.Set("X-API-Key", "fleet-key")
+	create.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), create)
+
+	units := map[string]string{}
+	for _, key := range []string{"us-key", "fleet-key"} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/nearest?lat=6.5&lng=3.4", nil)
+		req.Header.Set("X-API-Key", key)
+		handler.ServeHTTP(resp, req)
+		var body struct {
+			Unit string `json:"unit"`
+		}
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		units[key] = body.Unit
+		if got := resp.Header().Get("Cache-Control"); !strings.HasPrefix(got, "private") {
+			t.Errorf("Expected %s's answer kept private, got %q", key, got)
+		}
+		if !slices.Contains(resp.Header().Values("Vary"), "Authorization, X-API-Key") {
+			t.Errorf("Expected %s's answer to vary on its credentials, got %q", key, resp.Header().Values("Vary"))
+		}
+	}
+	if units["us-key"] != "mi" || units["fleet-key"] != "km" {
+		t.Errorf("Expected each key its own unit, got %v", units)
+	}
+}
+
+func TestAPIKeyTenantRecorded(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.Auth.Mode = "apikey"
+	cfg.Auth.APIKeys = []config.APIKeyConfig{{Name: "ops", Key: "ops-key", Scopes: []string{"read"}, Tenant: "acme"}}
+	locations := memory.NewInMemoryLocationRepository()
+	usage := memory.NewInMemoryUsageRepository()
+	repos := &server.Repositories{
+		Locations: locations,
+		Usage:     usage,
+		Queries:   memory.NewInMemoryQueryRepository(),
+		Settings:  memory.NewInMemorySettingsRepository(),
+		Changes:   locations,
+		Spatial:   locations,
+		Merger:    locations,
+		Restorer:  locations,
+		Integrity: locations,
+	}
+	handler, app, err := server.New(cfg, server.WithRepositories(repos), server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/locations", nil)
+	req.Header.Set("X-API-Key", "ops-key")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	// Shutting down flushes the buffered usage
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+
+	now := time.Now()
+	records, err := usage.ListUsage("ops", now, now)
+	if err != nil || len(records) != 1 || records[0].Tenant != "acme" {
+		t.Errorf("Expected the key's request recorded under tenant acme, got %+v, %v", records, err)
+	}
+}
@@ -0,0 +1,170 @@
+// Package webhook signs the webhooks the service delivers and verifies them
+// for consumers.
+//
+// Each delivery carries a SignatureHeader of the form
+//
+//	t=1712345678,v1=2024-10:5d41402a...,v1=2024-04:7c211433...
+//
+// where t is the Unix time the delivery was signed and each v1 is a key ID
+// and the hex HMAC-SHA256, under that key's secret, of t, a '.' and the
+// body. While a secret is being rotated both secrets sign every delivery,
+// so consumers holding either one keep verifying.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the HTTP header deliveries are signed in
+const SignatureHeader = "Leeta-Signature"
+
+// MaxKeys is how many secrets may sign at once: the current one and the
+// one being rotated out
+const MaxKeys = 2
+
+// DefaultMaxSkew is the skew VerifySignature allows when given none
+const DefaultMaxSkew = 5 * time.Minute
+
+var (
+	// ErrMalformedHeader is returned for a header without a timestamp or
+	// any signature
+	ErrMalformedHeader = errors.New("webhook: malformed signature header")
+	// ErrSignatureMismatch is returned when no signature in the header was
+	// made with the secret over this body and timestamp
+	ErrSignatureMismatch = errors.New("webhook: signature does not match")
+	// ErrTimestampOutOfRange is returned when a correctly signed delivery
+	// was signed further from now than the allowed skew, as a replayed one
+	// would be
+	ErrTimestampOutOfRange = errors.New("webhook: timestamp outside the allowed skew")
+)
+
+// Key is a secret deliveries are signed with, named so consumers can tell
+// which secret a signature was made with
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Signer signs deliveries with every active key
+type Signer struct {
+	keys []Key
+}
+
+// NewSigner creates a signer for one or two keys. Key IDs must be non-empty
+// and free of ',', ':', '=' and spaces, so they read back from the header
+// unchanged.
+func NewSigner(keys ...Key) (*Signer, error) {
+	if len(keys) == 0 || len(keys) > MaxKeys {
+		return nil, fmt.Errorf("webhook: expected 1 to %d keys, got %d", MaxKeys, len(keys))
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, ",:= \t") {
+			return nil, fmt.Errorf("webhook: invalid key ID %q", key.ID)
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("webhook: duplicate key ID %q", key.ID)
+		}
+		if len(key.Secret) == 0 {
+			return nil, fmt.Errorf("webhook: key %q has no secret", key.ID)
+		}
+		seen[key.ID] = true
+	}
+	return &Signer{keys: append([]Key(nil), keys...)}, nil
+}
+
+// Sign returns the SignatureHeader value for a body signed at the given time
+func (s *Signer) Sign(body []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	var header strings.Builder
+	header.WriteString("t=" + timestamp)
+	for _, key := range s.keys {
+		header.WriteString(",v1=" + key.ID + ":" + hex.EncodeToString(mac(key.Secret, timestamp, body)))
+	}
+	return header.String()
+}
+
+// VerifySignature checks that header, a SignatureHeader value, holds a
+// signature of body made with secret, and that it was signed within
+// maxSkew of now; a maxSkew of 0 or less allows DefaultMaxSkew. Signatures
+// are compared in constant time. The skew bounds how long a captured
+// delivery can be replayed; consumers that must not process one twice
+// should also discard event IDs they have already seen.
+func VerifySignature(secret, header, body []byte, maxSkew time.Duration) error {
+	return verify(secret, header, body, maxSkew, time.Now())
+}
+
+func verify(secret, header, body []byte, maxSkew time.Duration, now time.Time) error {
+	timestamp, signatures, err := parseHeader(string(header))
+	if err != nil {
+		return err
+	}
+
+	expected := mac(secret, timestamp, body)
+	matched := false
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			matched = true
+		}
+	}
+	if !matched {
+		return ErrSignatureMismatch
+	}
+
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrMalformedHeader
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)).Abs(); skew > maxSkew {
+		return ErrTimestampOutOfRange
+	}
+	return nil
+}
+
+// parseHeader returns the timestamp as sent, since that is what was
+// signed, and the decoded signatures. Fields other than t and v1 are
+// skipped so later schemes can be added alongside.
+func parseHeader(header string) (string, [][]byte, error) {
+	var timestamp string
+	var signatures [][]byte
+	for _, field := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "t":
+			timestamp = value
+		case "v1":
+			_, digest, ok := strings.Cut(value, ":")
+			if !ok {
+				continue
+			}
+			if signature, err := hex.DecodeString(digest); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return "", nil, ErrMalformedHeader
+	}
+	return timestamp, signatures, nil
+}
+
+func mac(secret []byte, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(timestamp))
+	h.Write([]byte{'.'})
+	h.Write(body)
+	return h.Sum(nil)
+}
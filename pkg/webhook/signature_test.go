@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	oldKey = Key{ID: "2024-04", Secret: []byte("old-secret")}
+	newKey = Key{ID: "2024-10", Secret: []byte("new-secret")}
+	body   = []byte(`{"id":"01J9Z3","type":"location.created"}`)
+)
+
+func newSigner(t *testing.T, keys ...Key) *Signer {
+	t.Helper()
+	signer, err := NewSigner(keys...)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return signer
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Parallel()
+	signer := newSigner(t, newKey)
+	header := signer.Sign(body, time.Now())
+	if !strings.HasPrefix(header, "t=") || !strings.Contains(header, ",v1=2024-10:") {
+		t.Errorf("Expected a timestamp and the key ID in the header, got %q", header)
+	}
+
+	tests := []struct {
+		name   string
+		secret []byte
+		header string
+		body   []byte
+		want   error
+	}{
+		{"valid", newKey.Secret, header, body, nil},
+		{"tampered body", newKey.Secret, header, []byte(`{"id":"01J9Z3","type":"location.deleted"}`), ErrSignatureMismatch},
+		{"wrong secret", oldKey.Secret, header, body, ErrSignatureMismatch},
+		{"tampered timestamp", newKey.Secret, "t=1" + header[len("t="):], body, ErrSignatureMismatch},
+		{"expired", newKey.Secret, signer.Sign(body, time.Now().Add(-10*time.Minute)), body, ErrTimestampOutOfRange},
+		{"from the future", newKey.Secret, signer.Sign(body, time.Now().Add(10*time.Minute)), body, ErrTimestampOutOfRange},
+		{"no timestamp", newKey.Secret, header[strings.Index(header, ",")+1:], body, ErrMalformedHeader},
+		{"no signature", newKey.Secret, "t=1712345678", body, ErrMalformedHeader},
+		{"empty", newKey.Secret, "", body, ErrMalformedHeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := VerifySignature(tt.secret, []byte(tt.header), tt.body, 5*time.Minute); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureSkew(t *testing.T) {
+	t.Parallel()
+	signer := newSigner(t, newKey)
+	now := time.Unix(1712345678, 0)
+	header := []byte(signer.Sign(body, now))
+
+	if err := verify(newKey.Secret, header, body, time.Minute, now.Add(time.Minute)); err != nil {
+		t.Errorf("Expected a delivery exactly at the skew to verify, got %v", err)
+	}
+	if err := verify(newKey.Secret, header, body, time.Minute, now.Add(time.Minute+time.Second)); !errors.Is(err, ErrTimestampOutOfRange) {
+		t.Errorf("Expected a delivery past the skew to be rejected, got %v", err)
+	}
+	if err := verify(newKey.Secret, header, body, 0, now.Add(DefaultMaxSkew)); err != nil {
+		t.Errorf("Expected no skew to allow the default, got %v", err)
+	}
+}
+
+func TestVerifySignatureDuringRotation(t *testing.T) {
+	t.Parallel()
+	at := time.Now()
+
+	// Both secrets sign while consumers move to the new one
+	rotating := newSigner(t, newKey, oldKey)
+	header := []byte(rotating.Sign(body, at))
+	for _, key := range []Key{oldKey, newKey} {
+		if err := VerifySignature(key.Secret, header, body, time.Minute); err != nil {
+			t.Errorf("Expected a consumer holding %s to verify during rotation, got %v", key.ID, err)
+		}
+	}
+
+	// Once the old secret is retired only the new one verifies
+	rotated := newSigner(t, newKey)
+	header = []byte(rotated.Sign(body, at))
+	if err := VerifySignature(newKey.Secret, header, body, time.Minute); err != nil {
+		t.Errorf("Expected the new secret to verify, got %v", err)
+	}
+	if err := VerifySignature(oldKey.Secret, header, body, time.Minute); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("Expected the retired secret to be rejected, got %v", err)
+	}
+}
+
+func TestNewSignerRejectsInvalidKeys(t *testing.T) {
+	t.Parallel()
+	invalid := map[string][]Key{
+		"no keys":       nil,
+		"three keys":    {oldKey, newKey, {ID: "2025-04", Secret: []byte("next")}},
+		"empty ID":      {{Secret: []byte("secret")}},
+		"ID with colon": {{ID: "a:b", Secret: []byte("secret")}},
+		"no secret":     {{ID: "2024-10"}},
+		"duplicate ID":  {newKey, {ID: newKey.ID, Secret: []byte("other")}},
+	}
+	for name, keys := range invalid {
+		if _, err := NewSigner(keys...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
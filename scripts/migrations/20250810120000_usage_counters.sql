@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS usage_counters (
+    api_key VARCHAR(255) NOT NULL,
+    tenant VARCHAR(255) NOT NULL DEFAULT '',
+    operation VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key, operation, day)
+);
+
+-- Usage is queried by day range across all keys for the admin report
+CREATE INDEX IF NOT EXISTS idx_usage_counters_day ON usage_counters (day);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_usage_counters_day;
+
+DROP TABLE IF EXISTS usage_counters;
+
+-- +goose StatementEnd
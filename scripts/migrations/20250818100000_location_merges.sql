@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Audit log of duplicate locations merged into a winner
+CREATE TABLE IF NOT EXISTS location_merges (
+    id BIGSERIAL PRIMARY KEY,
+    winner VARCHAR(255) NOT NULL,
+    losers JSONB NOT NULL,
+    actor TEXT,
+    merged_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS location_merges;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Serves created_after and created_before filters on the location list
+CREATE INDEX IF NOT EXISTS idx_locations_created_at ON locations (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_locations_created_at;
+
+-- +goose StatementEnd
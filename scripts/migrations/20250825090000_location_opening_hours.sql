@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Weekly opening hours as written by the service; NULL when unknown
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS opening_hours JSONB;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE locations DROP COLUMN IF EXISTS opening_hours;
+
+-- +goose StatementEnd
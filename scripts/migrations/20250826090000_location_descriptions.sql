@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Free-text notes on each station; empty when there are none
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+
+-- Serves name_contains with search_descriptions, whose ILIKE patterns
+-- start with a wildcard and so cannot use a btree index
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_locations_description_trgm ON locations USING GIN (description gin_trgm_ops);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_locations_description_trgm;
+ALTER TABLE locations DROP COLUMN IF EXISTS description;
+
+-- +goose StatementEnd
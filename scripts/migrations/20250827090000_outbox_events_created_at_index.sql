@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Event log exports read a created_at range in (created_at, id) order
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at, id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_outbox_events_created_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Alternate names a location is also found by. The primary key keeps
+-- aliases unique among themselves; the repository checks them against
+-- location names under an advisory lock on the name being claimed.
+CREATE TABLE IF NOT EXISTS location_aliases (
+    alias VARCHAR(255) PRIMARY KEY,
+    location_id INTEGER NOT NULL REFERENCES locations (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_aliases_location_id ON location_aliases (location_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_location_aliases_location_id;
+
+DROP TABLE IF EXISTS location_aliases;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The operating region a station belongs to; empty when it has none
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '';
+
+-- Region-scoped nearest searches filter on region and order by distance;
+-- with both in one GiST index the KNN scan never visits other regions.
+-- btree_gist provides the GiST operator class for the text column.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+CREATE INDEX IF NOT EXISTS idx_locations_region_geom ON locations USING GIST (region, geom);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_locations_region_geom;
+ALTER TABLE locations DROP COLUMN IF EXISTS region;
+
+-- +goose StatementEnd
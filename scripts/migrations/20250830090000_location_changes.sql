@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Every location mutation, for clients syncing incrementally. seq comes
+-- from a sequence, so it never repeats across restarts; rolled back
+-- writes leave gaps.
+CREATE TABLE IF NOT EXISTS location_changes (
+    seq BIGSERIAL PRIMARY KEY,
+    action VARCHAR(16) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    snapshot JSONB NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Compaction looks for a later change to the same name
+CREATE INDEX IF NOT EXISTS idx_location_changes_name_seq ON location_changes (name, seq);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_location_changes_name_seq;
+
+DROP TABLE IF EXISTS location_changes;
+
+-- +goose StatementEnd
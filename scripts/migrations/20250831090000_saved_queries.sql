@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Named location filters. The filter is kept as the API accepted it and
+-- decoded on every run, so fields later removed from the filter schema
+-- fail the run instead of being ignored.
+CREATE TABLE IF NOT EXISTS saved_queries (
+    name VARCHAR(100) PRIMARY KEY,
+    filter JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS saved_queries;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- References to station photos and documents stored elsewhere, as a list
+-- of {url, kind} objects; only the URLs are kept
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS attachments JSONB NOT NULL DEFAULT '[]';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE locations DROP COLUMN IF EXISTS attachments;
+
+-- +goose StatementEnd
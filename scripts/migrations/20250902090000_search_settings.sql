@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Runtime defaults for nearest searches. The table holds at most one row;
+-- until it does, the configured defaults apply.
+CREATE TABLE IF NOT EXISTS search_settings (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    speed_kmh DOUBLE PRECISION NOT NULL CHECK (speed_kmh >= 0),
+    max_distance_km DOUBLE PRECISION NOT NULL CHECK (max_distance_km >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS search_settings;
+
+-- +goose StatementEnd
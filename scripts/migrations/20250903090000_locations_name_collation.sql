@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Index names under the Unicode default ICU collation, so name sorts with
+-- NAME_COLLATION=und are read in index order. Servers built without ICU
+-- have no such collation and sort those pages in the application instead.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_collation WHERE collname = 'und-x-icu' AND collprovider = 'i') THEN
+        CREATE INDEX IF NOT EXISTS idx_locations_name_und ON locations (name COLLATE "und-x-icu");
+    END IF;
+END
+$$;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_locations_name_und;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- New rows must have a creation time. The constraint is NOT VALID, so rows
+-- a legacy import left NULL stay readable and are listed by the integrity
+-- check as missing_created_at; backfill them and then run
+-- ALTER TABLE locations VALIDATE CONSTRAINT locations_created_at_not_null.
+ALTER TABLE locations ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE locations
+    ADD CONSTRAINT locations_created_at_not_null CHECK (created_at IS NOT NULL) NOT VALID;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS locations_created_at_not_null;
+
+-- +goose StatementEnd
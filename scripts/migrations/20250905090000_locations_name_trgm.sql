@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Serves /locations/suggest, whose prefix, word and similarity matches
+-- on name all use trigram indexes, and name_contains
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_locations_name_trgm ON locations USING GIN (name gin_trgm_ops);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_locations_name_trgm;
+
+-- +goose StatementEnd
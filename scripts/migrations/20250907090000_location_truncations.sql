@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Audit log of the store being emptied through the admin truncate
+CREATE TABLE IF NOT EXISTS location_truncations (
+    id BIGSERIAL PRIMARY KEY,
+    count INTEGER NOT NULL,
+    actor TEXT,
+    truncated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS location_truncations;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Fuel capacity and stock in litres, NULL when not known. Stock is updated
+-- often and on its own, through PATCH /locations/{name}/stock.
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS capacity_litres DOUBLE PRECISION;
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS current_stock_litres DOUBLE PRECISION;
+ALTER TABLE locations ADD CONSTRAINT locations_stock_valid CHECK (
+    capacity_litres >= 0
+    AND current_stock_litres >= 0
+    AND current_stock_litres <= capacity_litres
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS locations_stock_valid;
+ALTER TABLE locations DROP COLUMN IF EXISTS current_stock_litres;
+ALTER TABLE locations DROP COLUMN IF EXISTS capacity_litres;
+
+-- +goose StatementEnd
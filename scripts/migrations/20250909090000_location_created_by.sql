@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The principal that created the location, empty when it was created
+-- anonymously or before owners were recorded
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS created_by TEXT NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE locations DROP COLUMN IF EXISTS created_by;
+
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The sequence of the last event recorded for each location. Rows outlive
+-- their location, so a location restored under its old ID carries on from
+-- where its events stopped.
+CREATE TABLE IF NOT EXISTS location_event_sequences (
+    location_id BIGINT PRIMARY KEY,
+    last_sequence BIGINT NOT NULL
+);
+
+-- Every stored location has had its created event, numbered 1
+INSERT INTO location_event_sequences (location_id, last_sequence)
+SELECT id, 1 FROM locations
+ON CONFLICT (location_id) DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS location_event_sequences;
+
+-- +goose StatementEnd
@@ -3,8 +3,10 @@ package tests
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -39,6 +41,10 @@ func setupTestServer() http.Handler {
 	return mux
 }
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func TestCreateLocation(t *testing.T) {
 	t.Parallel()
 	server := setupTestServer()
@@ -46,8 +52,8 @@ func TestCreateLocation(t *testing.T) {
 	// Test valid location creation
 	locationReq := dto.LocationRequest{
 		Name:      "Test Location",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	locationJSON, _ := json.Marshal(locationReq)
@@ -72,8 +78,8 @@ func TestCreateLocation(t *testing.T) {
 	// Test invalid location (out of range latitude)
 	invalidLocation := dto.LocationRequest{
 		Name:      "Invalid Location",
-		Latitude:  100.0, // Invalid latitude
-		Longitude: -74.0060,
+		Latitude:  ptr(100.0), // Invalid latitude
+		Longitude: ptr(-74.0060),
 	}
 
 	invalidJSON, _ := json.Marshal(invalidLocation)
@@ -95,8 +101,8 @@ func TestGetAllLocations(t *testing.T) {
 	// Create a test location first
 	locationReq := dto.LocationRequest{
 		Name:      "Test Location",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	locationJSON, _ := json.Marshal(locationReq)
@@ -137,18 +143,18 @@ func TestFindNearest(t *testing.T) {
 	locationReqs := []dto.LocationRequest{
 		{
 			Name:      "New York",
-			Latitude:  40.7128,
-			Longitude: -74.0060,
+			Latitude:  ptr(40.7128),
+			Longitude: ptr(-74.0060),
 		},
 		{
 			Name:      "Los Angeles",
-			Latitude:  34.0522,
-			Longitude: -118.2437,
+			Latitude:  ptr(34.0522),
+			Longitude: ptr(-118.2437),
 		},
 		{
 			Name:      "Chicago",
-			Latitude:  41.8781,
-			Longitude: -87.6298,
+			Latitude:  ptr(41.8781),
+			Longitude: ptr(-87.6298),
 		},
 	}
 
@@ -188,8 +194,8 @@ func TestDeleteLocation(t *testing.T) {
 	// Create a test location first
 	locationReq := dto.LocationRequest{
 		Name:      "Test Location",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 
 	locationJSON, _ := json.Marshal(locationReq)
@@ -223,6 +229,72 @@ func TestDeleteLocation(t *testing.T) {
 	}
 }
 
+// TestLocationNamesInPath sends names that need escaping over a real
+// connection, each as one percent-encoded path segment, and expects GET and
+// DELETE to agree on them
+func TestLocationNamesInPath(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(setupTestServer())
+	t.Cleanup(server.Close)
+
+	do := func(method, path string, body any) (int, []byte) {
+		t.Helper()
+		var reader io.Reader
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reader = bytes.NewReader(encoded)
+		}
+		req, err := http.NewRequest(method, server.URL+path, reader)
+		if err != nil {
+			t.Fatalf("Failed to build %s %s: %v", method, path, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send %s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, data
+	}
+	expectNotFound := func(method, path string) {
+		t.Helper()
+		code, data := do(method, path, nil)
+		var body struct {
+			Code string `json:"code"`
+		}
+		if err := json.Unmarshal(data, &body); code != http.StatusNotFound || err != nil || body.Code != "LOCATION_NOT_FOUND" {
+			t.Errorf("Expected %s %s to answer 404 LOCATION_NOT_FOUND, got %d: %s", method, path, code, data)
+		}
+	}
+
+	names := []string{"Ikeja/Agege Depot", "Lekki Phase 1", "Depot #4", "Open?", "Ọ̀yọ́ Central", "Ajah+Sangotedo", "100%"}
+	for i, name := range names {
+		request := dto.LocationRequest{Name: name, Latitude: ptr(6.5 + float64(i)/100), Longitude: ptr(3.35)}
+		if code, data := do(http.MethodPost, "/locations", request); code != http.StatusCreated {
+			t.Fatalf("Failed to create %q: %d %s", name, code, data)
+		}
+	}
+
+	for _, name := range names {
+		path := "/locations/" + url.PathEscape(name)
+		code, data := do(http.MethodGet, path, nil)
+		var location dto.LocationResponse
+		if err := json.Unmarshal(data, &location); code != http.StatusOK || err != nil || location.Name != name {
+			t.Errorf("Expected GET %s to return %q, got %d: %s", path, name, code, data)
+		}
+		if code, data := do(http.MethodDelete, path, nil); code != http.StatusNoContent {
+			t.Errorf("Expected DELETE %s to answer 204, got %d: %s", path, code, data)
+		}
+		expectNotFound(http.MethodGet, path)
+		expectNotFound(http.MethodDelete, path)
+	}
+
+	// Names that were never stored answer the same way
+	expectNotFound(http.MethodGet, "/locations/"+url.PathEscape("Nowhere/Else"))
+	expectNotFound(http.MethodDelete, "/locations/"+url.PathEscape("Nowhere/Else"))
+}
+
 func TestAPIErrorHandling(t *testing.T) {
 	t.Parallel()
 	server := setupTestServer()
@@ -241,8 +313,8 @@ func TestAPIErrorHandling(t *testing.T) {
 	// Test missing Content-Type
 	locationReq := dto.LocationRequest{
 		Name:      "Test Location",
-		Latitude:  40.7128,
-		Longitude: -74.0060,
+		Latitude:  ptr(40.7128),
+		Longitude: ptr(-74.0060),
 	}
 	locationJSON, _ := json.Marshal(locationReq)
 	req = httptest.NewRequest("POST", "/locations", bytes.NewBuffer(locationJSON))
@@ -295,4 +367,4 @@ func TestMethodNotAllowed(t *testing.T) {
 	if rec.Code != http.StatusMethodNotAllowed {
 		t.Errorf("Expected status code %d for unsupported method, got %d", http.StatusMethodNotAllowed, rec.Code)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/pkg/client"
+	"github.com/jesuloba-world/leeta-task/pkg/i18n"
+)
+
+func setupTestClient(t *testing.T, handler http.Handler, opts ...client.Option) *client.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := client.New(server.URL, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c
+}
+
+func TestClientLocationLifecycle(t *testing.T) {
+	t.Parallel()
+	c := setupTestClient(t, setupTestServer())
+	ctx := context.Background()
+
+	created, err := c.CreateLocation(ctx, "Ikeja", 6.6018, 3.3515)
+	if err != nil {
+		t.Fatalf("Failed to create location: %v", err)
+	}
+	if created.Name != "Ikeja" || created.ID == "" {
+		t.Fatalf("Expected Ikeja with an ID, got %+v", created)
+	}
+
+	if _, err := c.CreateLocation(ctx, "Ikeja", 6.6018, 3.3515); !errors.Is(err, client.ErrLocationExists) {
+		t.Fatalf("Expected ErrLocationExists, got %v", err)
+	}
+
+	var apiErr *client.Error
+	if _, err := c.CreateLocation(ctx, "Nowhere", 100, 0); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected a 400 client.Error, got %v", err)
+	}
+
+	if _, err := c.CreateLocation(ctx, "Yaba", 6.5095, 3.3711); err != nil {
+		t.Fatalf("Failed to create location: %v", err)
+	}
+
+	nearest, err := c.FindNearest(ctx, 6.6, 3.35)
+	if err != nil {
+		t.Fatalf("Failed to find nearest: %v", err)
+	}
+	if nearest.Location.Name != "Ikeja" {
+		t.Errorf("Expected Ikeja, got %s", nearest.Location.Name)
+	}
+	nearest, err = c.FindNearest(ctx, 6.6, 3.35, "Ikeja")
+	if err != nil || nearest.Location.Name != "Yaba" {
+		t.Errorf("Expected Yaba when Ikeja is excluded, got %+v, %v", nearest, err)
+	}
+
+	at, err := c.LocationAt(ctx, 6.6018, 3.3515, 0)
+	if err != nil || at.Name != "Ikeja" {
+		t.Errorf("Expected Ikeja at its own coordinates, got %+v, %v", at, err)
+	}
+	if _, err := c.LocationAt(ctx, 6.6, 3.35, 1); !errors.Is(err, client.ErrLocationNotFound) {
+		t.Errorf("Expected ErrLocationNotFound away from every location, got %v", err)
+	}
+
+	if got, err := c.GetLocation(ctx, "Ikeja"); err != nil || got.ID != created.ID {
+		t.Errorf("Expected Ikeja by name, got %+v, %v", got, err)
+	}
+
+	if err := c.DeleteLocation(ctx, "Ikeja"); err != nil {
+		t.Fatalf("Failed to delete location: %v", err)
+	}
+	if err := c.DeleteLocation(ctx, "Ikeja"); !errors.Is(err, client.ErrLocationNotFound) {
+		t.Fatalf("Expected ErrLocationNotFound, got %v", err)
+	}
+	if _, err := c.GetLocation(ctx, "Ikeja"); !errors.Is(err, client.ErrLocationNotFound) {
+		t.Fatalf("Expected ErrLocationNotFound after deleting, got %v", err)
+	}
+}
+
+func TestClientListLocationsPaginates(t *testing.T) {
+	t.Parallel()
+	c := setupTestClient(t, setupTestServer())
+	ctx := context.Background()
+
+	want := []string{"A", "B", "C", "D", "E"}
+	for i, name := range want {
+		if _, err := c.CreateLocation(ctx, name, float64(i), float64(i)); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for location, err := range c.ListLocations(ctx, 2) {
+		if err != nil {
+			t.Fatalf("Failed to list locations: %v", err)
+		}
+		if seen[location.Name] {
+			t.Fatalf("Location %s listed twice", location.Name)
+		}
+		seen[location.Name] = true
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("Expected %d locations, got %v", len(want), seen)
+	}
+
+	for _, err := range c.ListLocations(ctx, 1000) {
+		if !errors.Is(err, client.ErrLimitExceeded) {
+			t.Fatalf("Expected ErrLimitExceeded, got %v", err)
+		}
+	}
+}
+
+func TestClientRetriesThrottledRequests(t *testing.T) {
+	t.Parallel()
+	server := setupTestServer()
+	var throttled atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if throttled.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		server.ServeHTTP(w, r)
+	})
+
+	c := setupTestClient(t, handler, client.WithRetries(2, time.Millisecond))
+	if _, err := c.CreateLocation(context.Background(), "Ikeja", 6.6, 3.35); err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+
+	throttled.Store(0)
+	c = setupTestClient(t, handler, client.WithRetries(1, time.Millisecond))
+	var apiErr *client.Error
+	if _, err := c.FindNearest(context.Background(), 6.6, 3.35); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected a 429 once retries run out, got %v", err)
+	}
+}
+
+// TestClientErrorsMatchCatalog keeps the client's sentinels in step with the
+// server's error catalog
+func TestClientErrorsMatchCatalog(t *testing.T) {
+	t.Parallel()
+	for _, sentinel := range []*client.Error{
+		client.ErrBadRequest, client.ErrNotFound, client.ErrConflict, client.ErrInternalServerError,
+		client.ErrUnauthorized, client.ErrForbidden, client.ErrInsufficientScope, client.ErrQuotaExceeded, client.ErrRateLimited,
+		client.ErrValidation, client.ErrLocationExists, client.ErrLocationNotFound, client.ErrNoLocations,
+		client.ErrInvalidCursor, client.ErrPaginationConflict, client.ErrLimitExceeded,
+		client.ErrReferencePointIncomplete, client.ErrMergeLocationNotFound, client.ErrMergeInvalid,
+		client.ErrEndpointDisabled, client.ErrInvalidCreatedRange, client.ErrNameNotAllowed,
+		client.ErrInvalidBBox, client.ErrIntegrityCheckRunning, client.ErrInvalidOpeningHours,
+		client.ErrOpenFilterConflict, client.ErrNoOpenLocation, client.ErrAmbiguousLocation,
+		client.ErrDescriptionTooLong, client.ErrInvalidExportRange, client.ErrNameTaken,
+		client.ErrAliasNotFound, client.ErrInvalidRegion, client.ErrRegionRequired, client.ErrInvalidMatrixPoint, client.ErrUnknownFields,
+		client.ErrQueryExists, client.ErrQueryNotFound, client.ErrSavedQueryInvalid,
+		client.ErrImportNotFound, client.ErrInvalidImportFile,
+		client.ErrInvalidAttachment, client.ErrAttachmentUnreachable, client.ErrNoLocationInRange,
+		client.ErrSyncSourceNotAllowed, client.ErrSyncSourceFailed, client.ErrNearestScanLimit,
+		client.ErrPreconditionFailed, client.ErrLocationModified, client.ErrPreconditionRequired,
+		client.ErrInvalidCRSCoordinates, client.ErrTruncateNotConfirmed, client.ErrInvalidStock, client.ErrNoStockedLocation,
+		client.ErrStorageTimeout, client.ErrClassRateLimited, client.ErrNotOwner,
+	} {
+		if _, ok := i18n.Default.Message("en", sentinel.Code, nil); !ok {
+			t.Errorf("Expected %s in the error catalog", sentinel.Code)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// exchange is one recorded request and the server's response to it
+type exchange struct {
+	name     string
+	request  *http.Request
+	body     []byte
+	response *httptest.ResponseRecorder
+}
+
+// recorder replays requests against a handler and keeps every exchange
+type recorder struct {
+	t         *testing.T
+	handler   http.Handler
+	exchanges []exchange
+
+	// header is sent with every request
+	header http.Header
+}
+
+func (r *recorder) do(name, method, target, contentType, body string) {
+	r.t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	r.handler.ServeHTTP(rec, req)
+
+	// The handler consumed the body, so keep a fresh copy for validation.
+	// A body without a Content-Type is read as JSON, as create-location
+	// documents, which OpenAPI itself cannot express.
+	replay := httptest.NewRequest(method, target, nil)
+	replay.Header = req.Header.Clone()
+	if body != "" && contentType == "" {
+		replay.Header.Set("Content-Type", "application/json")
+	}
+	r.exchanges = append(r.exchanges, exchange{name: name, request: replay, body: []byte(body), response: rec})
+}
+
+// contractCorpus replays the flows of the API tests: creating, listing,
+// paginating, searching and deleting, including the failure cases
+func contractCorpus(t *testing.T, handler http.Handler) []exchange {
+	r := &recorder{t: t, handler: handler}
+	const json = "application/json"
+
+	r.do("nearest with no locations", "GET", "/nearest?lat=6.5&lng=3.35", "", "")
+	r.do("create", "POST", "/locations", json, `{"name":"New York","latitude":40.7128,"longitude":-74.006}`)
+	r.do("create second", "POST", "/locations", json, `{"name":"Boston","latitude":42.3601,"longitude":-71.0589}`)
+	r.do("create third", "POST", "/locations", json, `{"name":"Chicago","latitude":41.8781,"longitude":-87.6298}`)
+	r.do("create duplicate", "POST", "/locations", json, `{"name":"New York","latitude":40.7128,"longitude":-74.006}`)
+	r.do("create out of range", "POST", "/locations", json, `{"name":"Invalid","latitude":100,"longitude":-74.006}`)
+	r.do("create missing coordinates", "POST", "/locations", json, `{"name":"Nowhere"}`)
+	r.do("create empty name", "POST", "/locations", json, `{"name":"","latitude":1,"longitude":1}`)
+	r.do("create malformed", "POST", "/locations", json, `{"name":`)
+	r.do("create without content type", "POST", "/locations", "", `{"name":"Untyped","latitude":1,"longitude":1}`)
+	r.do("list", "GET", "/locations", "", "")
+	r.do("list page", "GET", "/locations?page=1&page_size=2", "", "")
+	r.do("list cursor", "GET", "/locations?limit=2", "", "")
+	r.do("list bad cursor", "GET", "/locations?cursor=!!!", "", "")
+	r.do("list mixed pagination", "GET", "/locations?page=1&cursor=Mg", "", "")
+	r.do("list page size too large", "GET", "/locations?page_size=1000", "", "")
+	r.do("list with reference point", "GET", "/locations?ref_lat=40.7&ref_lng=-74", "", "")
+	r.do("list with half a reference point", "GET", "/locations?ref_lat=40.7", "", "")
+	r.do("list created range", "GET", "/locations?created_after=2020-01-01T00:00:00Z&created_before=2100-01-01T00:00:00Z", "", "")
+	r.do("list filtered by name and box", "GET", "/locations?name_contains=bos&bbox=-80,40,-70,45", "", "")
+	r.do("list invalid box", "GET", "/locations?bbox=-80,45,-70,40", "", "")
+	r.do("list inverted created range", "GET", "/locations?created_after=2100-01-01T00:00:00Z&created_before=2020-01-01T00:00:00Z", "", "")
+	r.do("nearest", "GET", "/nearest?lat=40.7589&lng=-73.9851", "", "")
+	r.do("nearest excluding", "GET", "/nearest?lat=40.7589&lng=-73.9851&exclude=New%20York&exclude=Unknown", "", "")
+	r.do("nearest with speed", "GET", "/nearest?lat=40.7589&lng=-73.9851&speed_kmh=30", "", "")
+	r.do("nearest with zero speed", "GET", "/nearest?lat=40.7589&lng=-73.9851&speed_kmh=0", "", "")
+	r.do("nearest missing lng", "GET", "/nearest?lat=40.7589", "", "")
+	r.do("nearest invalid lat", "GET", "/nearest?lat=invalid&lng=-88", "", "")
+	r.do("nearest out of range", "GET", "/nearest?lat=91&lng=-88", "", "")
+	r.do("delete", "DELETE", "/locations/Chicago", "", "")
+	r.do("delete missing", "DELETE", "/locations/Chicago", "", "")
+	return r.exchanges
+}
+
+// loadOpenAPI fetches the document the server publishes, in its OpenAPI 3.0
+// form, which the validator supports
+func loadOpenAPI(t *testing.T, handler http.Handler) (*openapi3.T, routers.Router) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi-3.0.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Failed to fetch the OpenAPI document: status %d", rec.Code)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to load the OpenAPI document: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("The OpenAPI document is invalid: %v", err)
+	}
+	// Route by path alone, whatever host the recorded requests used
+	doc.Servers = nil
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("Failed to build a router: %v", err)
+	}
+	return doc, router
+}
+
+// describe names the failing schema path when the validator reports one
+func describe(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return fmt.Sprintf("at %s: %s", "/"+strings.Join(schemaErr.JSONPointer(), "/"), schemaErr.Reason)
+	}
+	return err.Error()
+}
+
+// TestOpenAPIContract checks every recorded exchange against the published
+// document: the response must be declared for its status and match its
+// schema, a successful request must be valid per the document, and a
+// request the document rejects must not succeed
+func TestOpenAPIContract(t *testing.T) {
+	t.Parallel()
+	server := setupTestServer()
+	_, router := loadOpenAPI(t, server)
+
+	ctx := context.Background()
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	for _, ex := range contractCorpus(t, server) {
+		req := ex.request
+		req.Body = io.NopCloser(bytes.NewReader(ex.body))
+		route, params, err := router.FindRoute(req)
+		if err != nil {
+			t.Errorf("%s: %s %s is not in the document: %v", ex.name, req.Method, req.URL, err)
+			continue
+		}
+		operation := route.Operation.OperationID
+		status := ex.response.Code
+
+		input := &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: route, Options: options}
+		requestErr := openapi3filter.ValidateRequest(ctx, input)
+		if requestErr != nil && status < 400 {
+			t.Errorf("%s: %s accepted a request the document rejects (status %d) %s",
+				ex.name, operation, status, describe(requestErr))
+		}
+
+		responseErr := openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 status,
+			Header:                 ex.response.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(ex.response.Body.Bytes())),
+			Options:                options,
+		})
+		if responseErr != nil {
+			t.Errorf("%s: %s answered %d with a response the document does not allow %s\n  body: %s",
+				ex.name, operation, status, describe(responseErr), ex.response.Body.String())
+		}
+	}
+}
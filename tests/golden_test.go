@@ -0,0 +1,316 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesuloba-world/leeta-task/pkg/server"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata/golden from the current responses")
+
+const goldenDir = "testdata/golden"
+
+// goldenClock is the time every location is created at and open_now is
+// evaluated at: a Monday morning in Lagos
+var goldenClock = time.Date(2025, 9, 8, 9, 30, 0, 0, time.UTC)
+
+// goldenHeaders are the response headers the API sets itself; transport
+// headers such as Date and Content-Length are left out
+var goldenHeaders = []string{
+	"Allow", "Cache-Control", "Content-Disposition", "Content-Language", "Content-Type",
+	"Deprecation", "ETag", "Link", "Location", "Retry-After", "Sunset", "Vary",
+	"X-Total-Count", "X-RateLimit-Class", "X-RateLimit-Reset",
+}
+
+var (
+	// Import and event IDs are ULIDs minted from the wall clock
+	ulidPattern     = regexp.MustCompile(`\b[0-9A-HJKMNP-TV-Z]{26}\b`)
+	timePattern     = regexp.MustCompile(`"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})"`)
+	durationPattern = regexp.MustCompile(`"(duration_ms|latency_ms|uptime_seconds)":\s*-?[0-9.eE+-]+`)
+)
+
+// normalise replaces the values that change from run to run, keeping
+// their keys so a field that appears or disappears still shows. Some
+// times are stamped with the wall clock rather than the server's clock,
+// such as changed_at and merged_at; every time within a day of the run
+// is taken to be one, as the corpus and goldenClock are far from it.
+func normalise(body []byte, run time.Time) []byte {
+	ids := map[string]string{}
+	body = ulidPattern.ReplaceAllFunc(body, func(id []byte) []byte {
+		if _, ok := ids[string(id)]; !ok {
+			ids[string(id)] = fmt.Sprintf("<ulid:%d>", len(ids)+1)
+		}
+		return []byte(ids[string(id)])
+	})
+	body = timePattern.ReplaceAllFunc(body, func(quoted []byte) []byte {
+		at, err := time.Parse(time.RFC3339Nano, string(quoted[1:len(quoted)-1]))
+		if err != nil || at.Sub(run).Abs() > 24*time.Hour {
+			return quoted
+		}
+		return []byte(`"<now>"`)
+	})
+	return durationPattern.ReplaceAll(body, []byte(`"$1": "<duration>"`))
+}
+
+// render writes an exchange as its request line, status, owned headers and
+// body, with JSON indented in the order the server wrote it
+func render(ex exchange, run time.Time) []byte {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s %s\n", ex.request.Method, ex.request.URL.RequestURI())
+	if len(ex.body) > 0 {
+		fmt.Fprintf(&out, "%s\n", ex.body)
+	}
+	fmt.Fprintf(&out, "\n%d %s\n", ex.response.Code, http.StatusText(ex.response.Code))
+	header := ex.response.Header()
+	for _, name := range goldenHeaders {
+		for _, value := range header.Values(name) {
+			fmt.Fprintf(&out, "%s: %s\n", name, value)
+		}
+	}
+	body := normalise(ex.response.Body.Bytes(), run)
+	if len(body) > 0 {
+		out.WriteByte('\n')
+		var indented bytes.Buffer
+		if json.Indent(&indented, body, "", "  ") == nil {
+			body = indented.Bytes()
+		}
+		out.Write(bytes.TrimRight(body, "\n"))
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// goldenName turns an exchange name into its file name
+func goldenName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-") + ".golden"
+}
+
+// goldenKey is the API key the corpus calls with; admin endpoints are
+// served only with authentication on
+const goldenKey = "golden-key"
+
+// setupGoldenServer builds the whole server on memory storage, with every
+// optional endpoint the memory backend supports and a fixed clock
+func setupGoldenServer(t *testing.T) http.Handler {
+	t.Helper()
+	for name, value := range map[string]string{
+		"STORAGE_TYPE":            "memory",
+		"AUTH_MODE":               "apikey",
+		"API_KEYS":                "golden:" + goldenKey + ":read,write,admin,exact",
+		"FAULT_INJECTION_ENABLED": "true",
+		"ENVIRONMENT_NAME":        "golden",
+		"METRICS_ENABLED":         "false",
+		"EVENTS_BACKEND":          "none",
+		"SYNC_ENABLED":            "true",
+		"SYNC_ALLOWED_SOURCES":    "https://locations.leeta.ng",
+	} {
+		t.Setenv(name, value)
+	}
+	cfg, err := server.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	handler, app, err := server.New(cfg,
+		server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		server.WithClock(func() time.Time { return goldenClock }))
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := app.Shutdown(context.Background()); err != nil {
+			t.Errorf("Failed to shut down: %v", err)
+		}
+	})
+	return handler
+}
+
+// goldenCorpus calls every endpoint, with each of its failure modes, in an
+// order that makes the responses depend only on the corpus itself
+func goldenCorpus(t *testing.T, handler http.Handler) []exchange {
+	r := &recorder{t: t, handler: handler, header: http.Header{"X-Api-Key": {goldenKey}}}
+	const json = "application/json"
+
+	// Discovery and probes
+	r.do("health", "GET", "/health", "", "")
+	r.do("health verbose", "GET", "/health?verbose=true", "", "")
+	r.do("ready", "GET", "/ready", "", "")
+	r.do("capabilities", "GET", "/capabilities", "", "")
+
+	// Locations
+	r.do("nearest with no locations", "GET", "/nearest?lat=6.5&lng=3.35", "", "")
+	r.do("create", "POST", "/locations", json, `{"name":"Leeta Lekki Phase 1","latitude":6.4474,"longitude":3.4723,"region":"Lagos","description":"Admiralty Way"}`)
+	r.do("create second", "POST", "/locations", json, `{"name":"Leeta Ikeja","latitude":6.6018,"longitude":3.3515,"region":"Lagos"}`)
+	r.do("create with opening hours", "POST", "/locations", json, `{"name":"Leeta Wuse","latitude":9.0765,"longitude":7.4586,"region":"Abuja","opening_hours":{"timezone":"Africa/Lagos","days":{"monday":[{"open":"08:00","close":"20:00"}]}}}`)
+	r.do("create duplicate", "POST", "/locations", json, `{"name":"Leeta Ikeja","latitude":6.6018,"longitude":3.3515}`)
+	r.do("create out of range", "POST", "/locations", json, `{"name":"Invalid","latitude":100,"longitude":3.4}`)
+	r.do("create missing coordinates", "POST", "/locations", json, `{"name":"Nowhere"}`)
+	r.do("create malformed", "POST", "/locations", json, `{"name":`)
+	r.do("create unsupported media type", "POST", "/locations", "application/xml", `<location/>`)
+	r.do("get", "GET", "/locations/Leeta%20Ikeja", "", "")
+	r.do("get missing", "GET", "/locations/Leeta%20Ibadan", "", "")
+	r.do("list", "GET", "/locations", "", "")
+	r.do("list page", "GET", "/locations?page=1&page_size=2", "", "")
+	r.do("list cursor", "GET", "/locations?limit=2", "", "")
+	r.do("list bad cursor", "GET", "/locations?cursor=!!!", "", "")
+	r.do("list mixed pagination", "GET", "/locations?page=1&cursor=Mg", "", "")
+	r.do("list filtered", "GET", "/locations?name_contains=lekki&bbox=3,6,4,7", "", "")
+	r.do("list invalid box", "GET", "/locations?bbox=4,7,3,6", "", "")
+	r.do("list open now", "GET", "/locations?open_now=true", "", "")
+	r.do("add alias", "POST", "/locations/Leeta%20Lekki%20Phase%201/aliases", json, `{"alias":"Leeta Admiralty Way"}`)
+	r.do("add alias taken", "POST", "/locations/Leeta%20Ikeja/aliases", json, `{"alias":"Leeta Admiralty Way"}`)
+	r.do("add alias to missing", "POST", "/locations/Leeta%20Ibadan/aliases", json, `{"alias":"Leeta Dugbe"}`)
+	r.do("remove alias", "DELETE", "/locations/Leeta%20Lekki%20Phase%201/aliases/Leeta%20Admiralty%20Way", "", "")
+	r.do("remove missing alias", "DELETE", "/locations/Leeta%20Lekki%20Phase%201/aliases/Leeta%20Admiralty%20Way", "", "")
+	r.do("update stock", "PATCH", "/locations/Leeta%20Ikeja/stock", json, `{"capacity_litres":33000,"current_stock_litres":12500}`)
+	r.do("adjust stock", "PATCH", "/locations/Leeta%20Ikeja/stock", json, `{"adjust_litres":-500}`)
+	r.do("update stock over capacity", "PATCH", "/locations/Leeta%20Ikeja/stock", json, `{"current_stock_litres":50000}`)
+	r.do("update stock of missing", "PATCH", "/locations/Leeta%20Ibadan/stock", json, `{"adjust_litres":1}`)
+
+	// Searches
+	r.do("nearest", "GET", "/nearest?lat=6.5&lng=3.4", "", "")
+	r.do("nearest with speed", "GET", "/nearest?lat=6.5&lng=3.4&speed_kmh=30", "", "")
+	r.do("nearest missing lng", "GET", "/nearest?lat=6.5", "", "")
+	r.do("nearest out of range", "GET", "/nearest?lat=91&lng=3.4", "", "")
+	r.do("at", "GET", "/locations/at?lat=6.4474&lng=3.4723", "", "")
+	r.do("at nothing", "GET", "/locations/at?lat=0&lng=0", "", "")
+	r.do("suggest", "GET", "/locations/suggest?q=leeta&lat=6.5&lng=3.4", "", "")
+	r.do("suggest missing query", "GET", "/locations/suggest?lat=6.5&lng=3.4", "", "")
+	r.do("distance matrix", "POST", "/distance/matrix", json, `{"origins":[{"name":"Leeta Ikeja"}],"destinations":[{"name":"Leeta Lekki Phase 1"},{"latitude":6.5,"longitude":3.4},{"name":"Leeta Ibadan"}],"unit":"km"}`)
+	r.do("distance matrix empty", "POST", "/distance/matrix", json, `{"origins":[],"destinations":[]}`)
+
+	// Transactions, duplicates and merges
+	r.do("transaction", "POST", "/locations/transaction", json, `{"operations":[{"op":"create","location":{"name":"Leeta Yaba","latitude":6.5095,"longitude":3.3711,"region":"Lagos"}},{"op":"update","name":"Leeta Yaba","changes":{"description":"Herbert Macaulay Way"}}]}`)
+	r.do("transaction rolled back", "POST", "/locations/transaction", json, `{"operations":[{"op":"create","location":{"name":"Leeta Surulere","latitude":6.5,"longitude":3.35}},{"op":"delete","name":"Leeta Ibadan"}]}`)
+	r.do("create near duplicate", "POST", "/locations", json, `{"name":"Leeta Lekki Phase I","latitude":6.4475,"longitude":3.4724,"region":"Lagos"}`)
+	r.do("duplicates", "GET", "/locations/duplicates", "", "")
+	r.do("merge", "POST", "/locations/merge", json, `{"winner":"Leeta Lekki Phase 1","losers":["Leeta Lekki Phase I"]}`)
+	r.do("merge missing", "POST", "/locations/merge", json, `{"winner":"Leeta Lekki Phase 1","losers":["Leeta Ibadan"]}`)
+	r.do("changes", "GET", "/locations/changes?since=0", "", "")
+
+	// Imports
+	r.do("import", "POST", "/locations/import", "text/csv", "name,lat,lng,region\nLeeta Ajah,6.4698,3.5852,Lagos\nLeeta Ikeja,6.6018,3.3515,Lagos\nLeeta Bad,abc,3.4,Lagos\n")
+	r.do("import without header", "POST", "/locations/import", "text/csv", "name,region\nLeeta Ajah,Lagos\n")
+	r.do("import empty", "POST", "/locations/import", "text/csv", "")
+	r.do("import status missing", "GET", "/imports/01K4EJ2Z8S3Q9V6W0X1Y2Z3A4B", "", "")
+	r.do("import cancel missing", "DELETE", "/imports/01K4EJ2Z8S3Q9V6W0X1Y2Z3A4B", "", "")
+
+	// Saved queries and settings
+	r.do("save query", "POST", "/queries", json, `{"name":"lagos","filter":{"region":"Lagos","name_contains":"leeta"}}`)
+	r.do("save query taken", "POST", "/queries", json, `{"name":"lagos","filter":{"region":"Lagos"}}`)
+	r.do("save query invalid name", "POST", "/queries", json, `{"name":"-lagos","filter":{}}`)
+	r.do("save query conflicting filter", "POST", "/queries", json, `{"name":"open","filter":{"open_now":true,"open_at":"2025-09-08T10:00:00Z"}}`)
+	r.do("queries", "GET", "/queries", "", "")
+	r.do("query", "GET", "/queries/lagos", "", "")
+	r.do("query missing", "GET", "/queries/abuja", "", "")
+	r.do("update query", "PUT", "/queries/lagos", json, `{"filter":{"region":"Lagos","bbox":"3,6,4,7"}}`)
+	r.do("run query", "GET", "/queries/lagos/run", "", "")
+	r.do("run query missing", "GET", "/queries/abuja/run", "", "")
+	r.do("delete query", "DELETE", "/queries/lagos", "", "")
+	r.do("delete query missing", "DELETE", "/queries/lagos", "", "")
+	r.do("search settings", "GET", "/settings/search", "", "")
+	r.do("update search settings", "PUT", "/settings/search", json, `{"speed_kmh":30,"max_distance_km":25}`)
+	r.do("update search settings negative", "PUT", "/settings/search", json, `{"speed_kmh":-1,"max_distance_km":25}`)
+
+	// Administration
+	r.do("integrity report", "GET", "/admin/integrity-report", "", "")
+	r.do("run integrity report", "POST", "/admin/integrity-report", "", "")
+	r.do("verify spatial", "POST", "/admin/verify-spatial", "", "")
+	r.do("export", "GET", "/admin/export", "", "")
+	r.do("restore", "POST", "/admin/restore", json, `{"version":1,"locations":[{"id":"900","name":"Leeta Restored","latitude":6.45,"longitude":3.39,"created_at":"2025-09-01T08:00:00Z"}]}`)
+	r.do("restore conflicting", "POST", "/admin/restore", json, `{"version":1,"locations":[{"id":"901","name":"Leeta Ikeja","latitude":6.6,"longitude":3.35,"created_at":"2025-09-01T08:00:00Z"}]}`)
+	r.do("restore unknown version", "POST", "/admin/restore", json, `{"version":2,"locations":[]}`)
+	r.do("sync invalid source", "POST", "/admin/sync", json, `{"source_url":"not a url"}`)
+	r.do("faults", "GET", "/admin/faults", "", "")
+	r.do("inject faults", "PUT", "/admin/faults", json, `{"faults":[{"method":"FindByName","error_rate":1}],"duration_seconds":60}`)
+	r.do("get under an injected fault", "GET", "/locations/Leeta%20Ikeja", "", "")
+	r.do("inject unknown fault", "PUT", "/admin/faults", json, `{"faults":[{"method":"Teleport","error_rate":1}]}`)
+	r.do("clear faults", "DELETE", "/admin/faults", "", "")
+	r.do("usage", "GET", "/usage?from=2025-09-01&to=2025-09-30", "", "")
+	r.do("admin usage", "GET", "/admin/usage?from=2025-09-01&to=2025-09-30", "", "")
+	r.do("admin usage inverted range", "GET", "/admin/usage?from=2025-09-30&to=2025-09-01", "", "")
+
+	// Deletes and truncation
+	r.do("delete", "DELETE", "/locations/Leeta%20Yaba", "", "")
+	r.do("delete missing", "DELETE", "/locations/Leeta%20Yaba", "", "")
+	r.do("truncate wrong environment", "POST", "/admin/truncate", json, `{"confirm":"production"}`)
+	r.do("truncate dry run", "POST", "/admin/truncate?dry_run=true", json, `{"confirm":"golden"}`)
+	r.do("truncate", "POST", "/admin/truncate", json, `{"confirm":"golden"}`)
+	r.do("list after truncate", "GET", "/locations", "", "")
+	r.do("unknown route", "GET", "/stations", "", "")
+	return r.exchanges
+}
+
+// TestGoldenResponses replays the corpus against the whole server and
+// compares each response with its golden file. A change in any status,
+// owned header or body field fails until the files are regenerated with
+//
+//	go test ./tests -run TestGoldenResponses -update
+//
+// and the diff is reviewed.
+func TestGoldenResponses(t *testing.T) {
+	run := time.Now()
+	exchanges := goldenCorpus(t, setupGoldenServer(t))
+
+	if *update {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatalf("Failed to clear %s: %v", goldenDir, err)
+		}
+		if err := os.MkdirAll(goldenDir, 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", goldenDir, err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, ex := range exchanges {
+		name := goldenName(ex.name)
+		if seen[name] {
+			t.Fatalf("Two exchanges are named %q", ex.name)
+		}
+		seen[name] = true
+		path := filepath.Join(goldenDir, name)
+		got := render(ex, run)
+
+		if *update {
+			if err := os.WriteFile(path, got, 0o644); err != nil {
+				t.Fatalf("Failed to write %s: %v", path, err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: no golden file; run with -update to create it: %v", ex.name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: the response differs from %s; run with -update if the change is intended\n--- want\n%s--- got\n%s",
+				ex.name, path, want, got)
+		}
+	}
+
+	// A golden file left behind by a removed exchange no longer pins anything
+	files, err := filepath.Glob(filepath.Join(goldenDir, "*.golden"))
+	if err != nil {
+		t.Fatalf("Failed to list %s: %v", goldenDir, err)
+	}
+	for _, file := range files {
+		if !seen[filepath.Base(file)] {
+			t.Errorf("%s matches no exchange; run with -update to remove it", file)
+		}
+	}
+}
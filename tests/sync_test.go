@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+
+	"github.com/jesuloba-world/leeta-task/internal/config"
+	"github.com/jesuloba-world/leeta-task/internal/dto"
+	"github.com/jesuloba-world/leeta-task/internal/handlers"
+	"github.com/jesuloba-world/leeta-task/internal/repository/memory"
+	"github.com/jesuloba-world/leeta-task/internal/service"
+	"github.com/jesuloba-world/leeta-task/pkg/client"
+)
+
+// setupSyncServer starts an instance that can also sync from others
+func setupSyncServer(t *testing.T, cfg config.SyncConfig) *httptest.Server {
+	t.Helper()
+	repo := memory.NewInMemoryLocationRepository()
+	locationService := service.NewLocationService(repo)
+
+	mux := http.NewServeMux()
+	api := humago.New(mux, huma.DefaultConfig("Test API", "1.0.0"))
+	handlers.NewLocationHandler(locationService).RegisterRoutes(api)
+	handlers.NewSyncHandler(service.NewSyncService(locationService), cfg).RegisterRoutes(api)
+	// A small advertised page size makes a sync read several pages
+	capabilities := handlers.NewCapabilitiesHandler()
+	capabilities.Register("limits", config.LimitsConfig{DefaultPageSize: 2, MaxPageSize: 2})
+	capabilities.RegisterRoutes(api)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func postJSON(t *testing.T, url string, body any) *http.Response {
+	t.Helper()
+	payload, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("Failed to post to %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func syncFrom(t *testing.T, mirror *httptest.Server, source string, dryRun bool) dto.SyncResponse {
+	t.Helper()
+	resp := postJSON(t, mirror.URL+"/admin/sync", dto.SyncRequest{SourceURL: source, DryRun: dryRun})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var result dto.SyncResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode sync response: %v", err)
+	}
+	return result
+}
+
+// contents lists locations by name
+func contents(t *testing.T, server *httptest.Server) map[string]dto.LocationResponse {
+	t.Helper()
+	c, err := client.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locations := map[string]dto.LocationResponse{}
+	for location, err := range c.ListLocations(context.Background(), 2) {
+		if err != nil {
+			t.Fatalf("Failed to list locations: %v", err)
+		}
+		locations[location.Name] = location
+	}
+	return locations
+}
+
+func TestSyncConverges(t *testing.T) {
+	t.Parallel()
+	source := setupSyncServer(t, config.SyncConfig{})
+	mirror := setupSyncServer(t, config.SyncConfig{AllowedSources: []string{source.URL}})
+
+	for _, location := range []map[string]any{
+		{"name": "Leeta Ikeja", "latitude": 6.6018, "longitude": 3.3515},
+		{"name": "Leeta Lekki", "latitude": 6.4474, "longitude": 3.4723, "description": "Entrance on the service road"},
+		{"name": "Leeta Yaba", "latitude": 6.5095, "longitude": 3.3711},
+	} {
+		postJSON(t, source.URL+"/locations", location)
+	}
+	postJSON(t, source.URL+"/locations/Leeta%20Lekki/aliases", map[string]any{"alias": "Leeta Admiralty Way"})
+	for _, location := range []map[string]any{
+		{"name": "Leeta Ikeja", "latitude": 6.6018, "longitude": 3.3515},
+		{"name": "Leeta Lekki", "latitude": 6.4400, "longitude": 3.4700},
+		{"name": "Leeta Surulere", "latitude": 6.5000, "longitude": 3.3500},
+	} {
+		postJSON(t, mirror.URL+"/locations", location)
+	}
+
+	// A dry run plans without changing anything
+	plan := syncFrom(t, mirror, source.URL, true)
+	var changes []string
+	for _, change := range plan.Changes {
+		changes = append(changes, change.Action+" "+change.Name)
+	}
+	want := []string{"delete Leeta Surulere", "update Leeta Lekki", "create Leeta Yaba"}
+	if !slices.Equal(changes, want) || plan.Unchanged != 1 || plan.Source != 3 {
+		t.Errorf("Expected the plan %v with one unchanged, got %+v", want, plan)
+	}
+	if _, ok := contents(t, mirror)["Leeta Surulere"]; !ok {
+		t.Error("Expected a dry run to leave the mirror alone")
+	}
+
+	result := syncFrom(t, mirror, source.URL, false)
+	if result.Created != 1 || result.Updated != 1 || result.Deleted != 1 || len(result.Conflicts) != 0 {
+		t.Errorf("Expected one create, update and delete, got %+v", result)
+	}
+	sourceContents, mirrorContents := contents(t, source), contents(t, mirror)
+	if aliases := mirrorContents["Leeta Lekki"].Aliases; !slices.Equal(aliases, []string{"Leeta Admiralty Way"}) {
+		t.Errorf("Expected the alias copied, got %v", aliases)
+	}
+	if len(mirrorContents) != len(sourceContents) {
+		t.Fatalf("Expected the mirror to match the source, got %v", mirrorContents)
+	}
+	for name, want := range sourceContents {
+		got := mirrorContents[name]
+		if got.Latitude != want.Latitude || got.Longitude != want.Longitude || got.Description != want.Description ||
+			!slices.Equal(got.Aliases, want.Aliases) {
+			t.Errorf("Expected %s as %+v, got %+v", name, want, got)
+		}
+	}
+
+	// Once converged there is nothing to do
+	if again := syncFrom(t, mirror, source.URL, false); len(again.Changes) != 0 || again.Unchanged != 3 {
+		t.Errorf("Expected no changes on a second sync, got %+v", again)
+	}
+
+	// Deleting at the source deletes from the mirror
+	for _, name := range []string{"Leeta%20Ikeja", "Leeta%20Lekki", "Leeta%20Yaba"} {
+		req, _ := http.NewRequest(http.MethodDelete, source.URL+"/locations/"+name, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if result := syncFrom(t, mirror, source.URL, false); result.Deleted != 3 {
+		t.Errorf("Expected three deletes, got %+v", result)
+	}
+	if remaining := contents(t, mirror); len(remaining) != 0 {
+		t.Errorf("Expected an empty mirror, got %v", remaining)
+	}
+}
+
+func TestSyncSourceErrors(t *testing.T) {
+	t.Parallel()
+	source := setupSyncServer(t, config.SyncConfig{})
+	mirror := setupSyncServer(t, config.SyncConfig{AllowedSources: []string{source.URL + "/"}})
+
+	resp := postJSON(t, mirror.URL+"/admin/sync", dto.SyncRequest{SourceURL: "http://example.com"})
+	if code := decodeErrorCode(t, resp); resp.StatusCode != http.StatusUnprocessableEntity || code != "SYNC_SOURCE_NOT_ALLOWED" {
+		t.Errorf("Expected 422 SYNC_SOURCE_NOT_ALLOWED, got %d %s", resp.StatusCode, code)
+	}
+
+	// Without allowed sources nothing may be fetched
+	unconfigured := setupSyncServer(t, config.SyncConfig{})
+	resp = postJSON(t, unconfigured.URL+"/admin/sync", dto.SyncRequest{SourceURL: source.URL})
+	if code := decodeErrorCode(t, resp); resp.StatusCode != http.StatusUnprocessableEntity || code != "SYNC_SOURCE_NOT_ALLOWED" {
+		t.Errorf("Expected 422 SYNC_SOURCE_NOT_ALLOWED without allowed sources, got %d %s", resp.StatusCode, code)
+	}
+
+	// The allowed source, gone
+	source.Close()
+	resp = postJSON(t, mirror.URL+"/admin/sync", dto.SyncRequest{SourceURL: source.URL})
+	if code := decodeErrorCode(t, resp); resp.StatusCode != http.StatusBadGateway || code != "SYNC_SOURCE_FAILED" {
+		t.Errorf("Expected 502 SYNC_SOURCE_FAILED, got %d %s", resp.StatusCode, code)
+	}
+}
+
+func decodeErrorCode(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return body.Code
+}